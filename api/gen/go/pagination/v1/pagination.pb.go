@@ -1001,7 +1001,9 @@ type PaginationResponseMeta struct {
 	// 每页实际条数
 	PageSize *uint32 `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3,oneof" json:"page_size,omitempty"`
 	// 当前页实际返回条数
-	CurrentSize   *uint32 `protobuf:"varint,7,opt,name=current_size,json=currentSize,proto3,oneof" json:"current_size,omitempty"`
+	CurrentSize *uint32 `protobuf:"varint,7,opt,name=current_size,json=currentSize,proto3,oneof" json:"current_size,omitempty"`
+	// 上一页令牌（仅Token分页有效，首页时为空）
+	PrevToken     *string `protobuf:"bytes,8,opt,name=prev_token,proto3,oneof" json:"prev_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1085,6 +1087,13 @@ func (x *PaginationResponseMeta) GetCurrentSize() uint32 {
 	return 0
 }
 
+func (x *PaginationResponseMeta) GetPrevToken() string {
+	if x != nil && x.PrevToken != nil {
+		return *x.PrevToken
+	}
+	return ""
+}
+
 // ------------------------------
 // 分页通用结果
 // ------------------------------
@@ -1484,7 +1493,7 @@ const file_pagination_v1_pagination_proto_rawDesc = "" +
 	"\n" +
 	"_no_pagingB\v\n" +
 	"\t_order_byB\r\n" +
-	"\v_field_mask\"\xea\x06\n" +
+	"\v_field_mask\"\xe1\a\n" +
 	"\x16PaginationResponseMeta\x12\x8e\x01\n" +
 	"\x05total\x18\x01 \x01(\v2\x1c.google.protobuf.UInt64ValueBU\xbaGR\x92\x02O总记录数（仅Page/Offset分页有效，Token分页通常不返回总数）H\x00R\x05total\x88\x01\x01\x12l\n" +
 	"\vtotal_pages\x18\x02 \x01(\v2\x1c.google.protobuf.UInt32ValueB(\xbaG%\x92\x02\"总页数（仅Page分页有效）H\x01R\n" +
@@ -1495,7 +1504,10 @@ const file_pagination_v1_pagination_proto_rawDesc = "" +
 	"next_token\x18\x05 \x01(\tBJ\xbaGG\x92\x02D下一页令牌（仅Token分页有效，无更多数据时为空）H\x04R\n" +
 	"next_token\x88\x01\x01\x12:\n" +
 	"\tpage_size\x18\x06 \x01(\rB\x18\xbaG\x15\x92\x02\x12每页实际条数H\x05R\bpageSize\x88\x01\x01\x12I\n" +
-	"\fcurrent_size\x18\a \x01(\rB!\xbaG\x1e\x92\x02\x1b当前页实际返回条数H\x06R\vcurrentSize\x88\x01\x01B\b\n" +
+	"\fcurrent_size\x18\a \x01(\rB!\xbaG\x1e\x92\x02\x1b当前页实际返回条数H\x06R\vcurrentSize\x88\x01\x01\x12f\n" +
+	"\n" +
+	"prev_token\x18\b \x01(\tBA\xbaG>\x92\x02;上一页令牌（仅Token分页有效，首页时为空）H\aR\n" +
+	"prev_token\x88\x01\x01B\b\n" +
 	"\x06_totalB\x0e\n" +
 	"\f_total_pagesB\x0f\n" +
 	"\r_current_pageB\x11\n" +
@@ -1503,7 +1515,8 @@ const file_pagination_v1_pagination_proto_rawDesc = "" +
 	"\v_next_tokenB\f\n" +
 	"\n" +
 	"_page_sizeB\x0f\n" +
-	"\r_current_size\"\xc1\x01\n" +
+	"\r_current_sizeB\r\n" +
+	"\v_prev_token\"\xc1\x01\n" +
 	"\x0ePagingResponse\x12\x8e\x01\n" +
 	"\x05total\x18\x01 \x01(\v2\x1c.google.protobuf.UInt64ValueBU\xbaGR\x92\x02O总记录数（仅Page/Offset分页有效，Token分页通常不返回总数）H\x00R\x05total\x88\x01\x01\x12\x14\n" +
 	"\x05items\x18\x02 \x03(\fR\x05itemsB\b\n" +
@@ -0,0 +1,329 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var (
+	// ErrQueueFull 队列已满且溢出策略为 OverflowDrop 时，Record 返回该错误，日志被丢弃
+	ErrQueueFull = errors.New("audit queue is full")
+
+	// ErrAuditorClosed Auditor 已关闭
+	ErrAuditorClosed = errors.New("auditor is closed")
+)
+
+// OverflowPolicy 队列已满时的处理策略
+type OverflowPolicy int
+
+const (
+	// OverflowDrop 丢弃新日志并返回 ErrQueueFull（默认），不阻塞业务调用
+	OverflowDrop OverflowPolicy = iota
+
+	// OverflowBlock 阻塞等待队列有空位，直到 ctx 结束
+	OverflowBlock
+)
+
+const (
+	DefaultQueueSize     = 4096
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = time.Second
+	DefaultWriteTimeout  = 10 * time.Second
+)
+
+// BufferedOption BufferedAuditor 的选项
+type BufferedOption func(a *BufferedAuditor)
+
+// WithQueueSize 设置队列容量
+func WithQueueSize(n int) BufferedOption {
+	return func(a *BufferedAuditor) {
+		if n > 0 {
+			a.queueSize = n
+		}
+	}
+}
+
+// WithBatchSize 设置单次写入 Sink 的最大条数
+func WithBatchSize(n int) BufferedOption {
+	return func(a *BufferedAuditor) {
+		if n > 0 {
+			a.batchSize = n
+		}
+	}
+}
+
+// WithFlushInterval 设置定时写入的间隔，未满一批的日志最多延迟该时间写入
+func WithFlushInterval(d time.Duration) BufferedOption {
+	return func(a *BufferedAuditor) {
+		if d > 0 {
+			a.flushInterval = d
+		}
+	}
+}
+
+// WithWriteTimeout 设置后台写入 Sink 的超时时间
+func WithWriteTimeout(d time.Duration) BufferedOption {
+	return func(a *BufferedAuditor) {
+		if d > 0 {
+			a.writeTimeout = d
+		}
+	}
+}
+
+// WithOverflowPolicy 设置队列已满时的处理策略
+func WithOverflowPolicy(p OverflowPolicy) BufferedOption {
+	return func(a *BufferedAuditor) {
+		a.overflow = p
+	}
+}
+
+// WithErrorHandler 设置后台写入失败时的回调，默认记录错误日志
+func WithErrorHandler(h func(err error, entries []*Entry)) BufferedOption {
+	return func(a *BufferedAuditor) {
+		if h != nil {
+			a.onError = h
+		}
+	}
+}
+
+// WithAuditLogger 设置 BufferedAuditor 使用的日志
+func WithAuditLogger(logger log.Logger) BufferedOption {
+	return func(a *BufferedAuditor) {
+		if logger != nil {
+			a.log = log.NewHelper(log.With(logger, "module", "audit"))
+		}
+	}
+}
+
+// BufferedAuditor 带有界队列的异步 Auditor：
+//   - Record 将日志放入队列后立即返回，由后台协程按批次（BatchSize）或定时（FlushInterval）写入 Sink；
+//   - 队列已满时按 OverflowPolicy 丢弃或阻塞；
+//   - Flush 同步写出调用前已入队的全部日志；Close 停止接收新日志，写出剩余日志并关闭 Sink。
+type BufferedAuditor struct {
+	sink Sink
+
+	queueSize     int
+	batchSize     int
+	flushInterval time.Duration
+	writeTimeout  time.Duration
+	overflow      OverflowPolicy
+
+	onError func(err error, entries []*Entry)
+	log     *log.Helper
+
+	queue   chan *Entry
+	flushCh chan chan error
+	stop    chan struct{}
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+
+	dropped atomic.Uint64
+}
+
+var _ Auditor = (*BufferedAuditor)(nil)
+
+// NewBufferedAuditor 创建异步 Auditor 并启动后台写入协程
+func NewBufferedAuditor(sink Sink, opts ...BufferedOption) *BufferedAuditor {
+	a := &BufferedAuditor{
+		sink:          sink,
+		queueSize:     DefaultQueueSize,
+		batchSize:     DefaultBatchSize,
+		flushInterval: DefaultFlushInterval,
+		writeTimeout:  DefaultWriteTimeout,
+		overflow:      OverflowDrop,
+		log:           log.NewHelper(log.With(log.GetLogger(), "module", "audit")),
+		flushCh:       make(chan chan error),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(a)
+		}
+	}
+	if a.onError == nil {
+		a.onError = func(err error, entries []*Entry) {
+			a.log.Errorf("write %d audit entries failed: %v", len(entries), err)
+		}
+	}
+	a.queue = make(chan *Entry, a.queueSize)
+
+	go a.run()
+
+	return a
+}
+
+// Record 将日志放入队列，不等待写入完成
+func (a *BufferedAuditor) Record(ctx context.Context, entry *Entry) error {
+	if entry == nil {
+		return nil
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		return ErrAuditorClosed
+	}
+
+	if a.overflow == OverflowBlock {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		select {
+		case a.queue <- entry:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	select {
+	case a.queue <- entry:
+		return nil
+	default:
+		a.dropped.Add(1)
+		return ErrQueueFull
+	}
+}
+
+// Flush 写出调用前已入队的全部日志，返回写入 Sink 的错误
+func (a *BufferedAuditor) Flush(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ack := make(chan error, 1)
+	select {
+	case a.flushCh <- ack:
+	case <-a.done:
+		return ErrAuditorClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-ack:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close 停止接收新日志，写出剩余日志后关闭 Sink；ctx 结束时不再等待后台协程
+func (a *BufferedAuditor) Close(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	close(a.stop)
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if a.sink == nil {
+		return nil
+	}
+	return a.sink.Close()
+}
+
+// Dropped 返回因队列已满被丢弃的日志条数
+func (a *BufferedAuditor) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// run 后台写入协程
+func (a *BufferedAuditor) run() {
+	defer close(a.done)
+
+	ticker := time.NewTicker(a.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Entry, 0, a.batchSize)
+
+	for {
+		select {
+		case e := <-a.queue:
+			batch = append(batch, e)
+			if len(batch) >= a.batchSize {
+				_ = a.write(batch)
+				batch = batch[:0]
+			}
+
+		case <-ticker.C:
+			if len(batch) > 0 {
+				_ = a.write(batch)
+				batch = batch[:0]
+			}
+
+		case ack := <-a.flushCh:
+			var err error
+			batch, err = a.drain(batch)
+			ack <- errors.Join(err, a.write(batch))
+			batch = batch[:0]
+
+		case <-a.stop:
+			batch, _ = a.drain(batch)
+			_ = a.write(batch)
+			return
+		}
+	}
+}
+
+// drain 取出队列中已有的全部日志，满一批时先写出
+func (a *BufferedAuditor) drain(batch []*Entry) ([]*Entry, error) {
+	var errs []error
+	for {
+		select {
+		case e := <-a.queue:
+			batch = append(batch, e)
+			if len(batch) >= a.batchSize {
+				if err := a.write(batch); err != nil {
+					errs = append(errs, err)
+				}
+				batch = batch[:0]
+			}
+		default:
+			return batch, errors.Join(errs...)
+		}
+	}
+}
+
+// write 将一批日志写入 Sink，失败时调用错误回调
+func (a *BufferedAuditor) write(batch []*Entry) error {
+	if len(batch) == 0 || a.sink == nil {
+		return nil
+	}
+
+	entries := make([]*Entry, len(batch))
+	copy(entries, batch)
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.writeTimeout)
+	defer cancel()
+
+	if err := a.sink.Write(ctx, entries); err != nil {
+		a.onError(err, entries)
+		return err
+	}
+	return nil
+}
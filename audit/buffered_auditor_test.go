@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memorySink 记录每次写入的批次
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]*Entry
+	block   chan struct{}
+	err     error
+	closed  bool
+}
+
+func (s *memorySink) Write(_ context.Context, entries []*Entry) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, entries)
+	return s.err
+}
+
+func (s *memorySink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *memorySink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, b := range s.batches {
+		n += len(b)
+	}
+	return n
+}
+
+func TestBufferedAuditor_BatchAndFlush(t *testing.T) {
+	sink := &memorySink{}
+	a := NewBufferedAuditor(sink, WithBatchSize(3), WithFlushInterval(time.Hour))
+
+	ctx := context.Background()
+	for i := 0; i < 7; i++ {
+		if err := a.Record(ctx, &Entry{Action: "create"}); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	if err := a.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if got := sink.count(); got != 7 {
+		t.Fatalf("expected 7 entries after flush, got %d", got)
+	}
+	for _, b := range sink.batches {
+		if len(b) > 3 {
+			t.Fatalf("batch exceeds batch size: %d", len(b))
+		}
+	}
+
+	if err := a.Close(ctx); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if !sink.closed {
+		t.Fatal("sink should be closed")
+	}
+	if err := a.Record(ctx, &Entry{}); !errors.Is(err, ErrAuditorClosed) {
+		t.Fatalf("expected ErrAuditorClosed, got %v", err)
+	}
+}
+
+func TestBufferedAuditor_FlushInterval(t *testing.T) {
+	sink := &memorySink{}
+	a := NewBufferedAuditor(sink, WithBatchSize(100), WithFlushInterval(10*time.Millisecond))
+	defer func() { _ = a.Close(context.Background()) }()
+
+	entry := &Entry{Action: "update"}
+	if err := a.Record(context.Background(), entry); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if entry.Timestamp.IsZero() {
+		t.Fatal("timestamp should be filled")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for sink.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if sink.count() != 1 {
+		t.Fatalf("expected entry to be written by the flush interval, got %d", sink.count())
+	}
+}
+
+func TestBufferedAuditor_Overflow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("drop", func(t *testing.T) {
+		sink := &memorySink{block: make(chan struct{})}
+		a := NewBufferedAuditor(sink, WithQueueSize(1), WithBatchSize(1))
+
+		var dropped bool
+		for i := 0; i < 10; i++ {
+			if err := a.Record(ctx, &Entry{}); errors.Is(err, ErrQueueFull) {
+				dropped = true
+			}
+		}
+		if !dropped || a.Dropped() == 0 {
+			t.Fatal("expected entries to be dropped when the queue is full")
+		}
+
+		close(sink.block)
+		_ = a.Close(ctx)
+	})
+
+	t.Run("block", func(t *testing.T) {
+		sink := &memorySink{block: make(chan struct{})}
+		a := NewBufferedAuditor(sink, WithQueueSize(1), WithBatchSize(1), WithOverflowPolicy(OverflowBlock))
+
+		var err error
+		for i := 0; i < 10 && err == nil; i++ {
+			rctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			err = a.Record(rctx, &Entry{})
+			cancel()
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected Record to block until the context deadline, got %v", err)
+		}
+
+		close(sink.block)
+		if err = a.Close(ctx); err != nil {
+			t.Fatalf("close: %v", err)
+		}
+	})
+}
+
+func TestBufferedAuditor_WriteError(t *testing.T) {
+	sinkErr := errors.New("sink failed")
+	sink := &memorySink{err: sinkErr}
+
+	var handled int
+	a := NewBufferedAuditor(sink, WithErrorHandler(func(err error, entries []*Entry) {
+		handled += len(entries)
+	}))
+	defer func() { _ = a.Close(context.Background()) }()
+
+	_ = a.Record(context.Background(), &Entry{})
+	if err := a.Flush(context.Background()); !errors.Is(err, sinkErr) {
+		t.Fatalf("expected sink error, got %v", err)
+	}
+	if handled != 1 {
+		t.Fatalf("expected error handler to receive 1 entry, got %d", handled)
+	}
+}
@@ -27,3 +27,20 @@ func MustFromContext(ctx context.Context) Auditor {
 	}
 	return NewNoopAuditor() // 提供一个默认的空实现
 }
+
+type dispatcherContextKey struct{}
+
+// WithDispatcher 将 Dispatcher 实例注入 context，供 Hook 投递审计日志
+func WithDispatcher(ctx context.Context, d *Dispatcher) context.Context {
+	return context.WithValue(ctx, dispatcherContextKey{}, d)
+}
+
+// DispatcherFromContext 从 context 中提取 Dispatcher，若不存在则返回 DefaultDispatcher
+func DispatcherFromContext(ctx context.Context) *Dispatcher {
+	if ctx != nil {
+		if d, ok := ctx.Value(dispatcherContextKey{}).(*Dispatcher); ok && d != nil {
+			return d
+		}
+	}
+	return DefaultDispatcher()
+}
@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// ExtraKeyDiff 字段级差异在 Entry.Extra 中的键名
+const ExtraKeyDiff = "diff"
+
+// FieldChange 单个字段的变更前后值
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Diff 两个数据快照之间的字段级差异
+type Diff struct {
+	Changed map[string]FieldChange `json:"changed,omitempty"` // 两侧都存在且值不同的字段
+	Added   map[string]any         `json:"added,omitempty"`   // 仅在变更后存在（或变更前为 null）的字段
+	Removed map[string]any         `json:"removed,omitempty"` // 仅在变更前存在（或变更后为 null）的字段
+}
+
+// ComputeDiff 比较变更前后的数据快照，值相等时（按 JSON 编码比较）不计入差异
+func ComputeDiff(pre, post map[string]any) *Diff {
+	d := &Diff{}
+
+	for k, oldVal := range pre {
+		newVal, ok := post[k]
+		switch {
+		case !ok || newVal == nil:
+			if oldVal != nil {
+				d.remove(k, oldVal)
+			}
+		case oldVal == nil:
+			d.add(k, newVal)
+		case !equalValue(oldVal, newVal):
+			if d.Changed == nil {
+				d.Changed = make(map[string]FieldChange)
+			}
+			d.Changed[k] = FieldChange{Old: oldVal, New: newVal}
+		}
+	}
+
+	for k, newVal := range post {
+		if _, ok := pre[k]; ok || newVal == nil {
+			continue
+		}
+		d.add(k, newVal)
+	}
+
+	return d
+}
+
+// IsEmpty 是否没有任何差异
+func (d *Diff) IsEmpty() bool {
+	return d == nil || (len(d.Changed) == 0 && len(d.Added) == 0 && len(d.Removed) == 0)
+}
+
+// Fields 返回所有发生变化的字段名
+func (d *Diff) Fields() []string {
+	if d == nil {
+		return nil
+	}
+	fields := make([]string, 0, len(d.Changed)+len(d.Added)+len(d.Removed))
+	for k := range d.Changed {
+		fields = append(fields, k)
+	}
+	for k := range d.Added {
+		fields = append(fields, k)
+	}
+	for k := range d.Removed {
+		fields = append(fields, k)
+	}
+	return fields
+}
+
+// Mask 使用 mask 替换 match 命中字段的值，字段本身仍保留在差异中
+func (d *Diff) Mask(match func(field string) bool, mask any) {
+	if d == nil || match == nil {
+		return
+	}
+	for k := range d.Changed {
+		if match(k) {
+			d.Changed[k] = FieldChange{Old: mask, New: mask}
+		}
+	}
+	for k := range d.Added {
+		if match(k) {
+			d.Added[k] = mask
+		}
+	}
+	for k := range d.Removed {
+		if match(k) {
+			d.Removed[k] = mask
+		}
+	}
+}
+
+func (d *Diff) add(k string, v any) {
+	if d.Added == nil {
+		d.Added = make(map[string]any)
+	}
+	d.Added[k] = v
+}
+
+func (d *Diff) remove(k string, v any) {
+	if d.Removed == nil {
+		d.Removed = make(map[string]any)
+	}
+	d.Removed[k] = v
+}
+
+// equalValue 比较两个值；类型不同时（如 uint32 与 float64）按 JSON 编码比较
+func equalValue(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	ab, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ab, bb)
+}
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeDiff(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	pre := map[string]any{
+		"name":       "alice",
+		"age":        float64(20),
+		"remark":     "old",
+		"updated_at": now.Format(time.RFC3339Nano),
+		"deleted_at": nil,
+	}
+	post := map[string]any{
+		"name":       "alice",
+		"age":        uint32(21),
+		"updated_at": now,
+		"email":      "a@example.com",
+	}
+
+	d := ComputeDiff(pre, post)
+
+	if len(d.Changed) != 1 || d.Changed["age"].Old != float64(20) || d.Changed["age"].New != uint32(21) {
+		t.Fatalf("unexpected changed: %+v", d.Changed)
+	}
+	if len(d.Added) != 1 || d.Added["email"] != "a@example.com" {
+		t.Fatalf("unexpected added: %+v", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed["remark"] != "old" {
+		t.Fatalf("unexpected removed: %+v", d.Removed)
+	}
+	if d.IsEmpty() || len(d.Fields()) != 3 {
+		t.Fatalf("unexpected fields: %v", d.Fields())
+	}
+
+	d.Mask(func(f string) bool { return f == "age" }, "***")
+	if d.Changed["age"].Old != "***" || d.Changed["age"].New != "***" {
+		t.Fatalf("expected masked change, got %+v", d.Changed["age"])
+	}
+
+	if !ComputeDiff(map[string]any{"a": 1}, map[string]any{"a": float64(1)}).IsEmpty() {
+		t.Fatal("numerically equal values should not differ")
+	}
+}
@@ -0,0 +1,273 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// ErrDispatcherClosed Dispatcher 已关闭
+var ErrDispatcherClosed = errors.New("audit dispatcher is closed")
+
+const (
+	DefaultDispatcherWorkers   = 4
+	DefaultDispatcherQueueSize = 1024
+	DefaultRecordTimeout       = 5 * time.Second
+)
+
+// DispatcherOption Dispatcher 的选项
+type DispatcherOption func(d *Dispatcher)
+
+// WithDispatcherWorkers 设置工作协程数量
+func WithDispatcherWorkers(n int) DispatcherOption {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.workers = n
+		}
+	}
+}
+
+// WithDispatcherQueueSize 设置队列容量
+func WithDispatcherQueueSize(n int) DispatcherOption {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.queueSize = n
+		}
+	}
+}
+
+// WithDispatcherOverflowPolicy 设置队列已满时的处理策略
+func WithDispatcherOverflowPolicy(p OverflowPolicy) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.overflow = p
+	}
+}
+
+// WithDispatcherRecordTimeout 设置单次调用 Auditor.Record 的超时时间
+func WithDispatcherRecordTimeout(t time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		if t > 0 {
+			d.recordTimeout = t
+		}
+	}
+}
+
+// WithDispatcherLogger 设置 Dispatcher 使用的日志
+func WithDispatcherLogger(logger log.Logger) DispatcherOption {
+	return func(d *Dispatcher) {
+		if logger != nil {
+			d.log = log.NewHelper(log.With(logger, "module", "audit"))
+		}
+	}
+}
+
+// DispatcherStats Dispatcher 的运行指标
+type DispatcherStats struct {
+	Workers       int    // 工作协程数量
+	QueueDepth    int    // 当前排队的日志条数
+	QueueCapacity int    // 队列容量
+	Dispatched    uint64 // 已交给 Auditor 处理的条数
+	Failed        uint64 // Auditor.Record 返回错误的条数
+	Dropped       uint64 // 因队列已满被丢弃的条数
+}
+
+// dispatchTask 一条待投递的审计日志
+type dispatchTask struct {
+	ctx     context.Context
+	auditor Auditor
+	entry   *Entry
+}
+
+// Dispatcher 供各后端 Hook 共享的审计日志投递器：
+//   - 固定数量的工作协程调用 Auditor.Record，避免每次变更创建一个协程；
+//   - 队列已满时按 OverflowPolicy 丢弃或阻塞（背压）；
+//   - 投递时使用与调用方取消信号分离、但保留 trace/span 等上下文值的 context；
+//   - Close 停止接收新日志，并等待队列中的日志投递完毕。
+type Dispatcher struct {
+	workers       int
+	queueSize     int
+	overflow      OverflowPolicy
+	recordTimeout time.Duration
+
+	log *log.Helper
+
+	queue chan dispatchTask
+	wg    sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+
+	dispatched atomic.Uint64
+	failed     atomic.Uint64
+	dropped    atomic.Uint64
+}
+
+// NewDispatcher 创建 Dispatcher 并启动工作协程
+func NewDispatcher(opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{
+		workers:       DefaultDispatcherWorkers,
+		queueSize:     DefaultDispatcherQueueSize,
+		overflow:      OverflowDrop,
+		recordTimeout: DefaultRecordTimeout,
+		log:           log.NewHelper(log.With(log.GetLogger(), "module", "audit")),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(d)
+		}
+	}
+	d.queue = make(chan dispatchTask, d.queueSize)
+
+	d.wg.Add(d.workers)
+	for i := 0; i < d.workers; i++ {
+		go d.work()
+	}
+
+	return d
+}
+
+// Dispatch 将日志交给工作协程异步写入 a；ctx 仅用于阻塞等待与传递上下文值，其取消不影响写入
+func (d *Dispatcher) Dispatch(ctx context.Context, a Auditor, entry *Entry) error {
+	if a == nil || entry == nil {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	task := dispatchTask{
+		ctx:     context.WithoutCancel(ctx),
+		auditor: a,
+		entry:   entry,
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+
+	if d.overflow == OverflowBlock {
+		select {
+		case d.queue <- task:
+			return nil
+		case <-ctx.Done():
+			d.dropped.Add(1)
+			return ctx.Err()
+		}
+	}
+
+	select {
+	case d.queue <- task:
+		return nil
+	default:
+		d.dropped.Add(1)
+		return ErrQueueFull
+	}
+}
+
+// Stats 返回当前运行指标
+func (d *Dispatcher) Stats() DispatcherStats {
+	return DispatcherStats{
+		Workers:       d.workers,
+		QueueDepth:    len(d.queue),
+		QueueCapacity: cap(d.queue),
+		Dispatched:    d.dispatched.Load(),
+		Failed:        d.failed.Load(),
+		Dropped:       d.dropped.Load(),
+	}
+}
+
+// QueueDepth 返回当前排队的日志条数
+func (d *Dispatcher) QueueDepth() int {
+	return len(d.queue)
+}
+
+// Dropped 返回因队列已满被丢弃的日志条数
+func (d *Dispatcher) Dropped() uint64 {
+	return d.dropped.Load()
+}
+
+// Close 停止接收新日志并等待队列中的日志投递完毕；ctx 结束时不再等待
+func (d *Dispatcher) Close(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// work 工作协程，队列关闭且取空后退出
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+
+	for task := range d.queue {
+		d.record(task)
+	}
+}
+
+func (d *Dispatcher) record(task dispatchTask) {
+	ctx, cancel := context.WithTimeout(task.ctx, d.recordTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			d.failed.Add(1)
+			d.log.Errorf("audit record panic: %v", r)
+		}
+	}()
+
+	d.dispatched.Add(1)
+	if err := task.auditor.Record(ctx, task.entry); err != nil {
+		d.failed.Add(1)
+		d.log.Errorf("audit record failed: %v", err)
+	}
+}
+
+var (
+	defaultDispatcherMu sync.Mutex
+	defaultDispatcher   *Dispatcher
+)
+
+// DefaultDispatcher 返回进程级共享的 Dispatcher，首次调用时按默认选项创建；
+// 应用退出时应调用 DefaultDispatcher().Close(ctx) 写出剩余日志
+func DefaultDispatcher() *Dispatcher {
+	defaultDispatcherMu.Lock()
+	defer defaultDispatcherMu.Unlock()
+
+	if defaultDispatcher == nil {
+		defaultDispatcher = NewDispatcher()
+	}
+	return defaultDispatcher
+}
+
+// SetDefaultDispatcher 替换进程级共享的 Dispatcher，原 Dispatcher 需由调用方自行关闭
+func SetDefaultDispatcher(d *Dispatcher) {
+	defaultDispatcherMu.Lock()
+	defer defaultDispatcherMu.Unlock()
+
+	defaultDispatcher = d
+}
@@ -0,0 +1,127 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type traceKey struct{}
+
+// recordingAuditor 记录 Record 调用，可选阻塞
+type recordingAuditor struct {
+	mu      sync.Mutex
+	entries []*Entry
+	traces  []any
+	block   chan struct{}
+}
+
+func (a *recordingAuditor) Record(ctx context.Context, e *Entry) error {
+	if a.block != nil {
+		<-a.block
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, e)
+	a.traces = append(a.traces, ctx.Value(traceKey{}))
+	return nil
+}
+
+func (a *recordingAuditor) Flush(context.Context) error { return nil }
+
+func TestDispatcher_DispatchAndClose(t *testing.T) {
+	d := NewDispatcher(WithDispatcherWorkers(2))
+	a := &recordingAuditor{}
+
+	// 调用方 context 已取消，但 trace 值需保留且写入不受影响
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), traceKey{}, "trace-1"))
+	cancel()
+
+	for i := 0; i < 20; i++ {
+		if err := d.Dispatch(ctx, a, &Entry{}); err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if len(a.entries) != 20 {
+		t.Fatalf("expected 20 entries after close, got %d", len(a.entries))
+	}
+	for _, v := range a.traces {
+		if v != "trace-1" {
+			t.Fatalf("trace value lost: %v", v)
+		}
+	}
+
+	st := d.Stats()
+	if st.Dispatched != 20 || st.Failed != 0 || st.QueueDepth != 0 || st.Workers != 2 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+
+	if err := d.Dispatch(context.Background(), a, &Entry{}); !errors.Is(err, ErrDispatcherClosed) {
+		t.Fatalf("expected ErrDispatcherClosed, got %v", err)
+	}
+}
+
+func TestDispatcher_Backpressure(t *testing.T) {
+	t.Run("drop", func(t *testing.T) {
+		a := &recordingAuditor{block: make(chan struct{})}
+		d := NewDispatcher(WithDispatcherWorkers(1), WithDispatcherQueueSize(1))
+
+		var dropped bool
+		for i := 0; i < 10; i++ {
+			if err := d.Dispatch(context.Background(), a, &Entry{}); errors.Is(err, ErrQueueFull) {
+				dropped = true
+			}
+		}
+		if !dropped || d.Dropped() == 0 || d.QueueDepth() != 1 {
+			t.Fatalf("expected drops with a full queue, stats %+v", d.Stats())
+		}
+
+		close(a.block)
+		_ = d.Close(context.Background())
+	})
+
+	t.Run("block", func(t *testing.T) {
+		a := &recordingAuditor{block: make(chan struct{})}
+		d := NewDispatcher(WithDispatcherWorkers(1), WithDispatcherQueueSize(1), WithDispatcherOverflowPolicy(OverflowBlock))
+
+		var err error
+		for i := 0; i < 10 && err == nil; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			err = d.Dispatch(ctx, a, &Entry{})
+			cancel()
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected Dispatch to block until the deadline, got %v", err)
+		}
+
+		// 工作协程阻塞时 Close 在 ctx 结束后返回
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err = d.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected close to time out, got %v", err)
+		}
+
+		close(a.block)
+		if err = d.Close(context.Background()); err != nil {
+			t.Fatalf("close: %v", err)
+		}
+	})
+}
+
+func TestDispatcherFromContext(t *testing.T) {
+	if DispatcherFromContext(context.Background()) != DefaultDispatcher() {
+		t.Fatal("expected default dispatcher")
+	}
+
+	d := NewDispatcher()
+	defer func() { _ = d.Close(context.Background()) }()
+	if DispatcherFromContext(WithDispatcher(context.Background(), d)) != d {
+		t.Fatal("expected dispatcher from context")
+	}
+}
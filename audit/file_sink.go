@@ -0,0 +1,223 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFileMaxSize 单个审计日志文件的默认大小上限（100 MiB）
+	DefaultFileMaxSize int64 = 100 << 20
+
+	backupTimeFormat = "20060102T150405.000"
+)
+
+// FileSinkOption FileSink 的选项
+type FileSinkOption func(s *FileSink)
+
+// WithFileMaxSize 设置单个文件的大小上限（字节），超过后轮转，<= 0 表示不轮转
+func WithFileMaxSize(n int64) FileSinkOption {
+	return func(s *FileSink) {
+		s.maxSize = n
+	}
+}
+
+// WithFileMaxBackups 设置保留的历史文件个数，<= 0 表示全部保留
+func WithFileMaxBackups(n int) FileSinkOption {
+	return func(s *FileSink) {
+		s.maxBackups = n
+	}
+}
+
+// FileSink 以 JSON Lines 格式写入文件，文件超过大小上限时轮转。
+// 轮转后的文件名为 "<name>-<时间戳><ext>"，如 audit-20240101T120000.000.log。
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+var _ Sink = (*FileSink)(nil)
+
+// NewFileSink 创建文件 Sink，目录不存在时自动创建
+func NewFileSink(path string, opts ...FileSinkOption) (*FileSink, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, errors.New("audit file path is empty")
+	}
+
+	s := &FileSink{
+		path:    path,
+		maxSize: DefaultFileMaxSize,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) Write(_ context.Context, entries []*Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return errors.New("audit file sink is closed")
+	}
+
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+
+		b, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("marshal audit entry: %w", err)
+		}
+		b = append(b, '\n')
+
+		if s.maxSize > 0 && s.size > 0 && s.size+int64(len(b)) > s.maxSize {
+			if err = s.rotate(); err != nil {
+				return err
+			}
+		}
+
+		n, err := s.file.Write(b)
+		s.size += int64(n)
+		if err != nil {
+			return fmt.Errorf("write audit file: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// open 以追加方式打开当前文件
+func (s *FileSink) open() error {
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create audit log dir: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open audit file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("stat audit file: %w", err)
+	}
+
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+// rotate 关闭当前文件并重命名为带时间戳的历史文件，然后重新打开
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("close audit file: %w", err)
+	}
+	s.file = nil
+
+	if err := os.Rename(s.path, s.backupName(time.Now())); err != nil {
+		return fmt.Errorf("rotate audit file: %w", err)
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	s.removeOldBackups()
+	return nil
+}
+
+func (s *FileSink) backupName(t time.Time) string {
+	ext := filepath.Ext(s.path)
+	prefix := strings.TrimSuffix(s.path, ext)
+	return fmt.Sprintf("%s-%s%s", prefix, t.Format(backupTimeFormat), ext)
+}
+
+// removeOldBackups 删除超过保留个数的历史文件
+func (s *FileSink) removeOldBackups() {
+	if s.maxBackups <= 0 {
+		return
+	}
+
+	ext := filepath.Ext(s.path)
+	prefix := strings.TrimSuffix(s.path, ext) + "-"
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return
+	}
+
+	backups := matches[:0]
+	for _, m := range matches {
+		ts := strings.TrimSuffix(strings.TrimPrefix(m, prefix), ext)
+		if _, err = time.Parse(backupTimeFormat, ts); err == nil {
+			backups = append(backups, m)
+		}
+	}
+	if len(backups) <= s.maxBackups {
+		return
+	}
+
+	sort.Strings(backups)
+	for _, m := range backups[:len(backups)-s.maxBackups] {
+		_ = os.Remove(m)
+	}
+}
+
+// ReadFileEntries 读取 JSON Lines 格式的审计日志文件
+func ReadFileEntries(path string) ([]*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var e Entry
+		if err = json.Unmarshal(line, &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSink_WriteAndRotate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+
+	s, err := NewFileSink(path, WithFileMaxSize(200), WithFileMaxBackups(2))
+	if err != nil {
+		t.Fatalf("new file sink: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		entries := []*Entry{{TraceID: "trace", Action: "create", Timestamp: time.Unix(int64(i), 0).UTC()}}
+		if err = s.Write(ctx, entries); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		// 保证轮转文件名中的时间戳不同
+		time.Sleep(2 * time.Millisecond)
+	}
+	if err = s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	current, err := ReadFileEntries(path)
+	if err != nil {
+		t.Fatalf("read current file: %v", err)
+	}
+	if len(current) == 0 {
+		t.Fatal("expected entries in the current file")
+	}
+	if current[0].TraceID != "trace" || current[0].Action != "create" {
+		t.Fatalf("unexpected entry: %+v", current[0])
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(dir, "audit-*.log"))
+	if len(backups) == 0 || len(backups) > 2 {
+		t.Fatalf("expected 1..2 rotated files, got %d", len(backups))
+	}
+}
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// LogSink 将审计日志以 JSON 形式输出到 kratos 日志
+type LogSink struct {
+	log   *log.Helper
+	level log.Level
+}
+
+var _ Sink = (*LogSink)(nil)
+
+// NewLogSink 创建日志 Sink，默认使用 INFO 级别
+func NewLogSink(logger log.Logger) *LogSink {
+	if logger == nil {
+		logger = log.GetLogger()
+	}
+	return &LogSink{
+		log:   log.NewHelper(log.With(logger, "module", "audit")),
+		level: log.LevelInfo,
+	}
+}
+
+// WithLevel 设置输出的日志级别
+func (s *LogSink) WithLevel(level log.Level) *LogSink {
+	s.level = level
+	return s
+}
+
+func (s *LogSink) Write(_ context.Context, entries []*Entry) error {
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		s.log.Log(s.level, "audit", string(b))
+	}
+	return nil
+}
+
+func (s *LogSink) Close() error { return nil }
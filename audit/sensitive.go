@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"strings"
+	"sync"
+)
+
+// MaskedValue 敏感字段脱敏后的占位值
+const MaskedValue = "********"
+
+var (
+	sensitiveMu         sync.RWMutex
+	sensitiveFieldNames = map[string]struct{}{
+		// 凭据类
+		"password":    {},
+		"secret":      {},
+		"token":       {},
+		"api_key":     {},
+		"access_key":  {},
+		"secret_key":  {},
+		"private_key": {},
+		"salt":        {},
+		"session_id":  {},
+		"auth_code":   {},
+
+		// 个人隐私类 (PII)
+		"id_card":        {},
+		"id_number":      {},
+		"phone":          {},
+		"mobile":         {},
+		"bank_card":      {},
+		"card_number":    {},
+		"cvv":            {},
+		"address_detail": {},
+	}
+)
+
+// AddSensitiveField 添加全局敏感字段名
+func AddSensitiveField(f string) {
+	sensitiveMu.Lock()
+	defer sensitiveMu.Unlock()
+	sensitiveFieldNames[strings.ToLower(f)] = struct{}{}
+}
+
+// IsSensitiveField 检查字段名是否包含全局敏感字段名（如 "user_password", "app_secret"）
+func IsSensitiveField(f string) bool {
+	f = strings.ToLower(f)
+
+	sensitiveMu.RLock()
+	defer sensitiveMu.RUnlock()
+
+	for name := range sensitiveFieldNames {
+		if strings.Contains(f, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SensitiveMatcher 在全局敏感字段之外追加或豁免字段，供各后端的审计 Hook 按 schema/表配置脱敏
+type SensitiveMatcher struct {
+	Fields    []string // 额外的敏感字段（按包含关系匹配）
+	NonFields []string // 不需要脱敏的字段（精确匹配，优先级最高）
+}
+
+// Match 判断字段是否需要脱敏
+func (m SensitiveMatcher) Match(f string) bool {
+	for _, name := range m.NonFields {
+		if strings.EqualFold(f, name) {
+			return false
+		}
+	}
+	lower := strings.ToLower(f)
+	for _, name := range m.Fields {
+		if strings.Contains(lower, strings.ToLower(name)) {
+			return true
+		}
+	}
+	return IsSensitiveField(f)
+}
+
+// Mask 返回敏感字段替换为 MaskedValue 后的副本
+func (m SensitiveMatcher) Mask(values map[string]any) map[string]any {
+	if values == nil {
+		return nil
+	}
+	masked := make(map[string]any, len(values))
+	for k, v := range values {
+		if m.Match(k) {
+			masked[k] = MaskedValue
+		} else {
+			masked[k] = v
+		}
+	}
+	return masked
+}
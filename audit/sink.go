@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"context"
+	"errors"
+)
+
+// Sink 审计日志的最终存储，由 BufferedAuditor 按批次调用
+type Sink interface {
+	// Write 写入一批审计日志，entries 不为空
+	Write(ctx context.Context, entries []*Entry) error
+
+	// Close 释放底层资源（文件句柄、批量插入器等）
+	Close() error
+}
+
+// SinkFunc 将函数适配为 Sink，Close 为空操作
+type SinkFunc func(ctx context.Context, entries []*Entry) error
+
+func (f SinkFunc) Write(ctx context.Context, entries []*Entry) error { return f(ctx, entries) }
+func (f SinkFunc) Close() error                                      { return nil }
+
+// multiSink 将同一批日志依次写入多个 Sink
+type multiSink struct {
+	sinks []Sink
+}
+
+// NewMultiSink 组合多个 Sink，写入时依次调用，任一失败不影响其余 Sink，返回合并后的错误
+func NewMultiSink(sinks ...Sink) Sink {
+	ss := make([]Sink, 0, len(sinks))
+	for _, s := range sinks {
+		if s != nil {
+			ss = append(ss, s)
+		}
+	}
+	return &multiSink{sinks: ss}
+}
+
+func (m *multiSink) Write(ctx context.Context, entries []*Entry) error {
+	var errs []error
+	for _, s := range m.sinks {
+		if err := s.Write(ctx, entries); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *multiSink) Close() error {
+	var errs []error
+	for _, s := range m.sinks {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Cache 缓存存储，内置进程内 LRU（NewLRU）与 Redis 兼容适配器（NewRedisCache）
+type Cache interface {
+	// Get 读取缓存，未命中时 found 为 false
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+
+	// Set 写入缓存，ttl <= 0 表示不过期
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete 删除缓存
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Codec DTO 的序列化方式
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// defaultCodec DTO 为 proto.Message 时使用 protojson，否则使用 encoding/json
+type defaultCodec struct{}
+
+func (defaultCodec) Marshal(v any) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		return protojson.Marshal(m)
+	}
+	return json.Marshal(v)
+}
+
+func (defaultCodec) Unmarshal(data []byte, v any) error {
+	if m, ok := v.(proto.Message); ok {
+		return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, m)
+	}
+	return json.Unmarshal(data, v)
+}
+
+type skipKey struct{}
+
+// Skip 返回跳过缓存读取的 ctx，Get 直接查询数据库（写操作仍会使缓存失效），
+// 如在事务中读取未提交的数据时使用
+func Skip(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipKey{}, true)
+}
+
+// IsSkipped 判断 ctx 是否跳过缓存读取
+func IsSkipped(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	v, _ := ctx.Value(skipKey{}).(bool)
+	return v
+}
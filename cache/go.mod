@@ -0,0 +1,27 @@
+module github.com/tx7do/go-crud/cache
+
+go 1.24.11
+
+replace github.com/tx7do/go-crud => ../
+
+replace github.com/tx7do/go-crud/api => ../api
+
+replace github.com/tx7do/go-crud/pagination => ../pagination
+
+replace github.com/tx7do/go-crud/viewer => ../viewer
+
+require (
+	github.com/go-kratos/kratos/v2 v2.9.2
+	github.com/tx7do/go-crud v0.0.1
+	github.com/tx7do/go-crud/api v0.0.7
+	github.com/tx7do/go-crud/pagination v0.0.11
+	github.com/tx7do/go-crud/viewer v0.0.5
+	golang.org/x/sync v0.19.0
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	github.com/google/gnostic v0.7.1 // indirect
+	github.com/google/gnostic-models v0.7.1 // indirect
+	go.yaml.in/yaml/v3 v3.0.4 // indirect
+)
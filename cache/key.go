@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination/softdelete"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+// QueryKey 计算查询的规范化哈希：FilterExpr 按确定性序列化，FieldMask 路径排序去重，
+// 并包含 ctx 中 Viewer 的租户、用户、平台/系统视图标记与数据权限范围，软删除查询模式以及 scope（WithKeyScope 的返回值）。
+// 数据权限等影响查询结果的身份信息都参与计算，不同权限的用户不会共享缓存项。
+func QueryKey(ctx context.Context, filter *paginationV1.FilterExpr, mask *fieldmaskpb.FieldMask, scope string) (string, error) {
+	h := sha256.New()
+
+	if filter != nil {
+		b, err := proto.MarshalOptions{Deterministic: true}.Marshal(filter)
+		if err != nil {
+			return "", err
+		}
+		h.Write(b)
+	}
+	h.Write([]byte{0})
+
+	paths := append([]string(nil), mask.GetPaths()...)
+	sort.Strings(paths)
+	for i, p := range paths {
+		if i > 0 && p == paths[i-1] {
+			continue
+		}
+		h.Write([]byte(p))
+		h.Write([]byte{','})
+	}
+	h.Write([]byte{0})
+
+	if vc, ok := viewer.FromContext(ctx); ok && vc != nil {
+		h.Write([]byte(viewerKey(vc)))
+	}
+	h.Write([]byte{0})
+	h.Write([]byte("mode=" + strconv.Itoa(int(softdelete.ModeFromContext(ctx)))))
+	h.Write([]byte{0})
+	h.Write([]byte(scope))
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// viewerKey 将 Viewer 中影响查询结果的身份信息序列化为规范化字符串，数据权限范围按类型与 ID 排序
+func viewerKey(vc viewer.Context) string {
+	var sb strings.Builder
+	sb.WriteString("tenant=" + strconv.FormatUint(vc.TenantID(), 10))
+	sb.WriteString(";user=" + strconv.FormatUint(vc.UserID(), 10))
+	if vc.IsPlatformContext() {
+		sb.WriteString(";platform")
+	}
+	if vc.IsSystemContext() {
+		sb.WriteString(";system")
+	}
+
+	scopes := make([]string, 0, len(vc.DataScope()))
+	for _, ds := range vc.DataScope() {
+		ids := slices.Clone(ds.TargetIDs)
+		slices.Sort(ids)
+		ids = slices.Compact(ids)
+
+		var b strings.Builder
+		b.WriteString(string(ds.ScopeType))
+		for i, id := range ids {
+			if i == 0 {
+				b.WriteByte(':')
+			} else {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.FormatUint(id, 10))
+		}
+		scopes = append(scopes, b.String())
+	}
+	sort.Strings(scopes)
+	sb.WriteString(";scope=" + strings.Join(slices.Compact(scopes), "|"))
+
+	return sb.String()
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+	now := time.Unix(1000, 0)
+	c.now = func() time.Time { return now }
+
+	_ = c.Set(ctx, "a", []byte("1"), 0)
+	_ = c.Set(ctx, "b", []byte("2"), 0)
+	_, _, _ = c.Get(ctx, "a")
+	_ = c.Set(ctx, "c", []byte("3"), 0)
+
+	// b 最久未使用，被淘汰
+	if _, found, _ := c.Get(ctx, "b"); found {
+		t.Fatal("b should be evicted")
+	}
+	if v, found, _ := c.Get(ctx, "a"); !found || string(v) != "1" {
+		t.Fatalf("a should be kept: %s", v)
+	}
+
+	_ = c.Set(ctx, "a", []byte("1"), time.Second)
+	now = now.Add(time.Second)
+	if _, found, _ := c.Get(ctx, "a"); found {
+		t.Fatal("a should be expired")
+	}
+
+	_ = c.Delete(ctx, "c")
+	if c.Len() != 0 {
+		t.Fatalf("expected empty cache, got %d", c.Len())
+	}
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RedisDoFunc 执行一条 Redis 命令并返回结果，键不存在时返回 (nil, nil) 或 IsNil 可识别的错误。
+// go-redis 可以这样包装：
+//
+//	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+//	c := cache.NewRedisCache(func(ctx context.Context, args ...any) (any, error) {
+//		return rdb.Do(ctx, args...).Result()
+//	}, "app:")
+type RedisDoFunc func(ctx context.Context, args ...any) (any, error)
+
+// RedisOption RedisCache 的选项
+type RedisOption func(c *RedisCache)
+
+// WithRedisNilError 设置识别"键不存在"错误的函数，默认按错误信息 "redis: nil"（go-redis 的 redis.Nil）判断
+func WithRedisNilError(isNil func(err error) bool) RedisOption {
+	return func(c *RedisCache) {
+		if isNil != nil {
+			c.isNil = isNil
+		}
+	}
+}
+
+var _ Cache = (*RedisCache)(nil)
+
+// RedisCache 基于 GET / SET PX / DEL 命令的缓存适配器，适用于 Redis 及兼容协议的存储，不依赖具体的客户端库
+type RedisCache struct {
+	do     RedisDoFunc
+	prefix string
+	isNil  func(err error) bool
+}
+
+// NewRedisCache 创建 Redis 缓存适配器，prefix 会加在所有键之前
+func NewRedisCache(do RedisDoFunc, prefix string, opts ...RedisOption) *RedisCache {
+	c := &RedisCache{
+		do:     do,
+		prefix: prefix,
+		isNil:  func(err error) bool { return err.Error() == "redis: nil" },
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	return c
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := c.do(ctx, "GET", c.prefix+key)
+	if err != nil {
+		if c.isNil(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	switch v := reply.(type) {
+	case nil:
+		return nil, false, nil
+	case []byte:
+		return v, true, nil
+	case string:
+		return []byte(v), true, nil
+	default:
+		return nil, false, fmt.Errorf("unexpected redis reply type %T", reply)
+	}
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []any{"SET", c.prefix + key, value}
+	if ttl > 0 {
+		args = append(args, "PX", max(ttl.Milliseconds(), 1))
+	}
+	_, err := c.do(ctx, args...)
+	return err
+}
+
+func (c *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(keys)+1)
+	args = append(args, "DEL")
+	for _, key := range keys {
+		args = append(args, c.prefix+key)
+	}
+	_, err := c.do(ctx, args...)
+	return err
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+	store := map[string][]byte{}
+	var lastSet []any
+
+	c := NewRedisCache(func(_ context.Context, args ...any) (any, error) {
+		switch args[0] {
+		case "GET":
+			v, ok := store[args[1].(string)]
+			if !ok {
+				return nil, errors.New("redis: nil")
+			}
+			return string(v), nil
+		case "SET":
+			lastSet = args
+			store[args[1].(string)] = args[2].([]byte)
+			return "OK", nil
+		case "DEL":
+			for _, k := range args[1:] {
+				delete(store, k.(string))
+			}
+			return int64(len(args) - 1), nil
+		}
+		return nil, errors.New("unknown command")
+	}, "app:")
+
+	if _, found, err := c.Get(ctx, "k"); found || err != nil {
+		t.Fatalf("expected miss: %v, %v", found, err)
+	}
+	if err := c.Set(ctx, "k", []byte("v"), 1500*time.Millisecond); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if len(lastSet) != 5 || lastSet[1] != "app:k" || lastSet[3] != "PX" || lastSet[4] != int64(1500) {
+		t.Fatalf("unexpected SET args: %v", lastSet)
+	}
+	if v, found, err := c.Get(ctx, "k"); !found || err != nil || string(v) != "v" {
+		t.Fatalf("get: %s, %v, %v", v, found, err)
+	}
+	if err := c.Delete(ctx, "k"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, found, _ := c.Get(ctx, "k"); found {
+		t.Fatal("key should be deleted")
+	}
+}
@@ -0,0 +1,334 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+const (
+	DefaultTTL         = 5 * time.Minute
+	DefaultNegativeTTL = 30 * time.Second
+)
+
+// 缓存值的首字节：记录或不存在标记（负缓存）
+const (
+	entryValue    byte = 'v'
+	entryNotFound byte = 'n'
+)
+
+// Option Repository 的选项
+type Option func(o *options)
+
+type options struct {
+	ttl         time.Duration
+	negativeTTL time.Duration
+	codec       Codec
+	keyScope    func(ctx context.Context) string
+	log         *log.Helper
+}
+
+// WithTTL 设置记录的缓存时间，默认 DefaultTTL
+func WithTTL(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.ttl = d
+		}
+	}
+}
+
+// WithNegativeTTL 设置"记录不存在"的缓存时间，默认 DefaultNegativeTTL；d <= 0 时关闭负缓存
+func WithNegativeTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.negativeTTL = d
+	}
+}
+
+// WithCodec 设置 DTO 的序列化方式，默认 proto.Message 使用 protojson，其它类型使用 encoding/json
+func WithCodec(c Codec) Option {
+	return func(o *options) {
+		if c != nil {
+			o.codec = c
+		}
+	}
+}
+
+// WithKeyScope 设置额外参与缓存键计算的 ctx 信息。缓存键默认已区分 Viewer 的租户、用户、
+// 平台/系统视图与数据权限范围，查询结果还受其它 ctx 信息影响时（如自定义的查询条件注入）应返回其标识
+func WithKeyScope(fn func(ctx context.Context) string) Option {
+	return func(o *options) {
+		o.keyScope = fn
+	}
+}
+
+// WithLogger 设置缓存读写失败时使用的日志
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.log = log.NewHelper(log.With(logger, "module", "cache"))
+		}
+	}
+}
+
+var _ crud.Repository[struct{}] = (*Repository[struct{}])(nil)
+var _ crud.SoftDeleteRepository = (*Repository[struct{}])(nil)
+
+// Repository 为 crud.Repository 增加读穿透缓存的装饰器，可包装 gorm / entgo 等模块的 RepositoryAdapter：
+//   - Get 按 FilterExpr、FieldMask、Viewer 身份（租户、用户、视图类型、数据权限）与软删除查询模式的规范化哈希缓存结果，
+//     ErrNotFound 按 NegativeTTL 缓存；
+//   - 并发的相同未命中查询通过 singleflight 合并为一次数据库查询；
+//   - Create/Update/Upsert/Delete/Restore/Purge 成功后递增命名空间的版本号，使该命名空间下的全部缓存失效；
+//   - 缓存读写失败时回退为直接查询数据库；List/Count/Exists 不缓存。
+//
+// 事务中的写入在提交前即使缓存失效，提交前的并发读取可能回填旧数据：事务中的读取使用 Skip(ctx)，
+// 需要强一致时在事务提交后再调用 Invalidate。
+//
+// 示例：
+//
+//	users := cache.NewRepository[userV1.User](gorm.NewRepositoryAdapter(repo, db), cache.NewLRU(0), "users")
+type Repository[DTO any] struct {
+	inner     crud.Repository[DTO]
+	cache     Cache
+	namespace string
+	opts      options
+
+	group singleflight.Group
+	seq   atomic.Uint64
+}
+
+// NewRepository 创建缓存装饰器，namespace 用于区分不同仓库的缓存键，通常为表名
+func NewRepository[DTO any](inner crud.Repository[DTO], c Cache, namespace string, opts ...Option) *Repository[DTO] {
+	r := &Repository[DTO]{
+		inner:     inner,
+		cache:     c,
+		namespace: namespace,
+		opts: options{
+			ttl:         DefaultTTL,
+			negativeTTL: DefaultNegativeTTL,
+			codec:       defaultCodec{},
+			log:         log.NewHelper(log.With(log.GetLogger(), "module", "cache")),
+		},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&r.opts)
+		}
+	}
+	return r
+}
+
+func (r *Repository[DTO]) List(ctx context.Context, req *paginationV1.PaginationRequest) (*crud.PagingResult[DTO], error) {
+	return r.inner.List(ctx, req)
+}
+
+// Get 先读缓存，未命中时查询数据库并写入缓存
+func (r *Repository[DTO]) Get(ctx context.Context, filter *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	if r.cache == nil || IsSkipped(ctx) {
+		return r.inner.Get(ctx, filter, viewMask)
+	}
+
+	key, err := r.key(ctx, filter, viewMask)
+	if err != nil {
+		r.opts.log.Warnf("build cache key failed: %v", err)
+		return r.inner.Get(ctx, filter, viewMask)
+	}
+
+	if data, found, err := r.cache.Get(ctx, key); err != nil {
+		r.opts.log.Warnf("read cache %s failed: %v", key, err)
+	} else if found {
+		if dto, err := r.decode(data); err == nil {
+			return dto, nil
+		} else if errors.Is(err, crud.ErrNotFound) {
+			return nil, err
+		} else {
+			r.opts.log.Warnf("decode cache %s failed: %v", key, err)
+		}
+	}
+
+	// 合并并发的未命中查询；查询不受单个调用方取消的影响
+	v, err, _ := r.group.Do(key, func() (any, error) {
+		return r.load(context.WithoutCancel(ctx), key, filter, viewMask)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 每个调用方各自解码，避免共享同一个 DTO
+	return r.decode(v.([]byte))
+}
+
+// load 查询数据库并写入缓存，返回编码后的缓存值
+func (r *Repository[DTO]) load(ctx context.Context, key string, filter *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) ([]byte, error) {
+	dto, err := r.inner.Get(ctx, filter, viewMask)
+	if errors.Is(err, crud.ErrNotFound) {
+		if r.opts.negativeTTL > 0 {
+			r.set(ctx, key, []byte{entryNotFound}, r.opts.negativeTTL)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := r.opts.codec.Marshal(dto)
+	if err != nil {
+		return nil, err
+	}
+	data = append([]byte{entryValue}, data...)
+	r.set(ctx, key, data, r.opts.ttl)
+	return data, nil
+}
+
+func (r *Repository[DTO]) set(ctx context.Context, key string, data []byte, ttl time.Duration) {
+	if err := r.cache.Set(ctx, key, data, ttl); err != nil {
+		r.opts.log.Warnf("write cache %s failed: %v", key, err)
+	}
+}
+
+// decode 解码缓存值，负缓存返回 crud.ErrNotFound
+func (r *Repository[DTO]) decode(data []byte) (*DTO, error) {
+	if len(data) == 0 {
+		return nil, errors.New("empty cache entry")
+	}
+	switch data[0] {
+	case entryNotFound:
+		return nil, crud.ErrNotFound
+	case entryValue:
+		dto := new(DTO)
+		if err := r.opts.codec.Unmarshal(data[1:], dto); err != nil {
+			return nil, err
+		}
+		return dto, nil
+	default:
+		return nil, errors.New("unknown cache entry")
+	}
+}
+
+func (r *Repository[DTO]) Create(ctx context.Context, dto *DTO, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	res, err := r.inner.Create(ctx, dto, viewMask)
+	if err == nil {
+		r.Invalidate(ctx)
+	}
+	return res, err
+}
+
+func (r *Repository[DTO]) Update(ctx context.Context, filter *paginationV1.FilterExpr, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	res, err := r.inner.Update(ctx, filter, dto, updateMask)
+	if err == nil {
+		r.Invalidate(ctx)
+	}
+	return res, err
+}
+
+func (r *Repository[DTO]) Upsert(ctx context.Context, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	res, err := r.inner.Upsert(ctx, dto, updateMask)
+	if err == nil {
+		r.Invalidate(ctx)
+	}
+	return res, err
+}
+
+func (r *Repository[DTO]) Delete(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	n, err := r.inner.Delete(ctx, filter)
+	if err == nil {
+		r.Invalidate(ctx)
+	}
+	return n, err
+}
+
+func (r *Repository[DTO]) Count(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	return r.inner.Count(ctx, filter)
+}
+
+func (r *Repository[DTO]) Exists(ctx context.Context, filter *paginationV1.FilterExpr) (bool, error) {
+	return r.inner.Exists(ctx, filter)
+}
+
+// Restore 被包装的仓库实现 crud.SoftDeleteRepository 时恢复记录并使缓存失效，否则返回 crud.ErrNotSupported
+func (r *Repository[DTO]) Restore(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	sd, ok := r.inner.(crud.SoftDeleteRepository)
+	if !ok {
+		return 0, crud.ErrNotSupported
+	}
+	n, err := sd.Restore(ctx, filter)
+	if err == nil {
+		r.Invalidate(ctx)
+	}
+	return n, err
+}
+
+// Purge 被包装的仓库实现 crud.SoftDeleteRepository 时清理记录并使缓存失效，否则返回 crud.ErrNotSupported
+func (r *Repository[DTO]) Purge(ctx context.Context, filter *paginationV1.FilterExpr, olderThan time.Duration) (int64, error) {
+	sd, ok := r.inner.(crud.SoftDeleteRepository)
+	if !ok {
+		return 0, crud.ErrNotSupported
+	}
+	n, err := sd.Purge(ctx, filter, olderThan)
+	if err == nil {
+		r.Invalidate(ctx)
+	}
+	return n, err
+}
+
+// Invalidate 递增命名空间的版本号，使该命名空间下的全部缓存失效；
+// 绕过装饰器直接写库（如批量导入、其它服务写入）后可手动调用
+func (r *Repository[DTO]) Invalidate(ctx context.Context) {
+	if r.cache == nil {
+		return
+	}
+	gen := strconv.FormatInt(time.Now().UnixNano(), 36) + "." + strconv.FormatUint(r.seq.Add(1), 36)
+	if err := r.cache.Set(ctx, r.generationKey(), []byte(gen), 0); err != nil {
+		r.opts.log.Errorf("invalidate cache namespace %s failed: %v", r.namespace, err)
+	}
+}
+
+// key 返回 <namespace>:<版本号>:<查询哈希>
+func (r *Repository[DTO]) key(ctx context.Context, filter *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (string, error) {
+	gen, err := r.generation(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var scope string
+	if r.opts.keyScope != nil {
+		scope = r.opts.keyScope(ctx)
+	}
+	hash, err := QueryKey(ctx, filter, viewMask, scope)
+	if err != nil {
+		return "", err
+	}
+	return r.namespace + ":" + gen + ":" + hash, nil
+}
+
+// generation 读取命名空间的版本号，不存在（首次使用或已被淘汰）时生成新的版本号
+func (r *Repository[DTO]) generation(ctx context.Context) (string, error) {
+	data, found, err := r.cache.Get(ctx, r.generationKey())
+	if err != nil {
+		return "", err
+	}
+	if found && len(data) > 0 {
+		return string(data), nil
+	}
+
+	r.Invalidate(ctx)
+	data, found, err = r.cache.Get(ctx, r.generationKey())
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", errors.New("cache generation is not available")
+	}
+	return string(data), nil
+}
+
+func (r *Repository[DTO]) generationKey() string {
+	return r.namespace + ":gen"
+}
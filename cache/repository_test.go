@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+type testUser struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+}
+
+// memoryRepo 统计 Get 调用次数的内存仓库，name 过滤条件为空时返回 ErrNotFound
+type memoryRepo struct {
+	crud.Repository[testUser]
+	gets  atomic.Int32
+	block chan struct{}
+
+	mu   sync.Mutex
+	name string
+}
+
+func (r *memoryRepo) Get(_ context.Context, filter *paginationV1.FilterExpr, _ *fieldmaskpb.FieldMask) (*testUser, error) {
+	r.gets.Add(1)
+	if r.block != nil {
+		<-r.block
+	}
+	if filter.GetConditions()[0].GetValue() != "1" {
+		return nil, crud.ErrNotFound
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &testUser{ID: 1, Name: r.name}, nil
+}
+
+func (r *memoryRepo) Update(_ context.Context, _ *paginationV1.FilterExpr, dto *testUser, _ *fieldmaskpb.FieldMask) (*testUser, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.name = dto.Name
+	return dto, nil
+}
+
+func idFilter(id string) *paginationV1.FilterExpr {
+	return &paginationV1.FilterExpr{
+		Type: paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{
+			{Field: "id", Op: paginationV1.Operator_EQ, ValueOneof: &paginationV1.FilterCondition_Value{Value: id}},
+		},
+	}
+}
+
+// tenantViewer 指定租户的 Viewer
+type tenantViewer struct {
+	viewer.Context
+	tenant uint64
+}
+
+func (v tenantViewer) TenantID() uint64 { return v.tenant }
+
+// scopedViewer 指定用户、数据权限与系统视图的 Viewer
+type scopedViewer struct {
+	viewer.Context
+	user   uint64
+	scopes []viewer.DataScope
+	system bool
+}
+
+func (v scopedViewer) TenantID() uint64              { return 1 }
+func (v scopedViewer) UserID() uint64                { return v.user }
+func (v scopedViewer) DataScope() []viewer.DataScope { return v.scopes }
+func (v scopedViewer) IsSystemContext() bool         { return v.system }
+
+func TestRepository_ReadThrough(t *testing.T) {
+	inner := &memoryRepo{name: "alice"}
+	repo := NewRepository[testUser](inner, NewLRU(0), "users")
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		u, err := repo.Get(ctx, idFilter("1"), nil)
+		if err != nil || u.Name != "alice" {
+			t.Fatalf("get: %+v, %v", u, err)
+		}
+		u.Name = "mutated"
+	}
+	if n := inner.gets.Load(); n != 1 {
+		t.Fatalf("expected 1 db query, got %d", n)
+	}
+
+	// FieldMask 路径顺序不影响缓存键
+	mask1 := &fieldmaskpb.FieldMask{Paths: []string{"id", "name"}}
+	mask2 := &fieldmaskpb.FieldMask{Paths: []string{"name", "id"}}
+	_, _ = repo.Get(ctx, idFilter("1"), mask1)
+	_, _ = repo.Get(ctx, idFilter("1"), mask2)
+	if n := inner.gets.Load(); n != 2 {
+		t.Fatalf("equivalent masks should share a key, got %d queries", n)
+	}
+
+	// 不同租户使用不同的缓存键
+	tenantCtx := viewer.WithContext(ctx, tenantViewer{Context: viewer.NewNoopContext(), tenant: 9})
+	_, _ = repo.Get(tenantCtx, idFilter("1"), nil)
+	if n := inner.gets.Load(); n != 3 {
+		t.Fatalf("tenant should be part of the key, got %d queries", n)
+	}
+
+	// 写入后失效
+	if _, err := repo.Update(ctx, idFilter("1"), &testUser{ID: 1, Name: "bob"}, nil); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if u, err := repo.Get(ctx, idFilter("1"), nil); err != nil || u.Name != "bob" {
+		t.Fatalf("stale read after update: %+v, %v", u, err)
+	}
+
+	// 跳过缓存
+	before := inner.gets.Load()
+	_, _ = repo.Get(Skip(ctx), idFilter("1"), nil)
+	if inner.gets.Load() != before+1 {
+		t.Fatal("skip should bypass cache")
+	}
+}
+
+func TestQueryKey_Viewer(t *testing.T) {
+	key := func(v scopedViewer) string {
+		v.Context = viewer.NewNoopContext()
+		k, err := QueryKey(viewer.WithContext(context.Background(), v), idFilter("1"), nil, "")
+		if err != nil {
+			t.Fatalf("key: %v", err)
+		}
+		return k
+	}
+
+	self := key(scopedViewer{user: 1, scopes: []viewer.DataScope{{ScopeType: viewer.ScopeTypeSelf}}})
+	all := key(scopedViewer{user: 2, scopes: []viewer.DataScope{{ScopeType: viewer.ScopeTypeAll}}})
+	if self == all {
+		t.Fatal("users with different data scopes must not share a key")
+	}
+	if key(scopedViewer{user: 1, scopes: []viewer.DataScope{{ScopeType: viewer.ScopeTypeAll}}}) == all {
+		t.Fatal("user should be part of the key")
+	}
+	if key(scopedViewer{user: 2, system: true}) == key(scopedViewer{user: 2}) {
+		t.Fatal("system context should be part of the key")
+	}
+
+	// 数据权限范围与 ID 的顺序不影响缓存键
+	unit1 := key(scopedViewer{user: 3, scopes: []viewer.DataScope{
+		{ScopeType: viewer.ScopeTypeUnit, TargetIDs: []uint64{2, 1}},
+		{ScopeType: viewer.ScopeTypeSelf},
+	}})
+	unit2 := key(scopedViewer{user: 3, scopes: []viewer.DataScope{
+		{ScopeType: viewer.ScopeTypeSelf},
+		{ScopeType: viewer.ScopeTypeUnit, TargetIDs: []uint64{1, 2}},
+	}})
+	if unit1 != unit2 {
+		t.Fatal("equivalent data scopes should share a key")
+	}
+}
+
+func TestRepository_NegativeCache(t *testing.T) {
+	inner := &memoryRepo{}
+	lru := NewLRU(0)
+	now := time.Unix(1000, 0)
+	lru.now = func() time.Time { return now }
+	repo := NewRepository[testUser](inner, lru, "users", WithNegativeTTL(time.Second))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.Get(ctx, idFilter("2"), nil); err != crud.ErrNotFound {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if n := inner.gets.Load(); n != 1 {
+		t.Fatalf("not found should be cached, got %d queries", n)
+	}
+
+	now = now.Add(time.Second)
+	_, _ = repo.Get(ctx, idFilter("2"), nil)
+	if n := inner.gets.Load(); n != 2 {
+		t.Fatalf("negative entry should expire, got %d queries", n)
+	}
+}
+
+func TestRepository_Singleflight(t *testing.T) {
+	inner := &memoryRepo{name: "alice", block: make(chan struct{})}
+	repo := NewRepository[testUser](inner, NewLRU(0), "users")
+	ctx := context.Background()
+
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Get(ctx, idFilter("1"), nil)
+			errs <- err
+		}()
+	}
+
+	// 等待第一个查询进入数据库后放行
+	for inner.gets.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(inner.block)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+	}
+	if got := inner.gets.Load(); got != 1 {
+		t.Fatalf("concurrent misses should be collapsed, got %d queries", got)
+	}
+}
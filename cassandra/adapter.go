@@ -0,0 +1,75 @@
+package cassandra
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+var _ crud.Repository[struct{}] = (*RepositoryAdapter[struct{}, struct{}])(nil)
+
+// RepositoryAdapter 将 Cassandra Repository 适配为通用的 crud.Repository 接口。
+// CQL 只能按主键更新，因此按过滤条件更新暂不支持，Upsert 使用按主键的 UPDATE（CQL 的 UPDATE 本身具有 upsert 语义）。
+type RepositoryAdapter[DTO any, ENTITY any] struct {
+	repo *Repository[DTO, ENTITY]
+}
+
+// NewRepositoryAdapter 创建 Cassandra 仓库适配器
+func NewRepositoryAdapter[DTO any, ENTITY any](repo *Repository[DTO, ENTITY]) *RepositoryAdapter[DTO, ENTITY] {
+	return &RepositoryAdapter[DTO, ENTITY]{
+		repo: repo,
+	}
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) List(ctx context.Context, req *paginationV1.PaginationRequest) (*crud.PagingResult[DTO], error) {
+	return a.repo.ListWithPagination(ctx, req)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Get(ctx context.Context, filter *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	dto, err := a.repo.Get(ctx, filter, viewMask)
+	if err != nil {
+		return nil, err
+	}
+	if dto == nil {
+		return nil, crud.ErrNotFound
+	}
+	return dto, nil
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Create(ctx context.Context, dto *DTO, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	return a.repo.Create(ctx, dto, viewMask)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Update(_ context.Context, filter *paginationV1.FilterExpr, _ *DTO, _ *fieldmaskpb.FieldMask) (*DTO, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return nil, err
+	}
+	return nil, crud.ErrNotSupported
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Upsert(ctx context.Context, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	return a.repo.Update(ctx, dto, updateMask)
+}
+
+// Delete 删除符合过滤条件的记录，过滤条件必须限定到主键。
+// Cassandra 不返回受影响行数，成功时返回 1。
+func (a *RepositoryAdapter[DTO, ENTITY]) Delete(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return 0, err
+	}
+	if err := a.repo.Delete(ctx, filter); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Count(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	return a.repo.Count(ctx, filter)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Exists(ctx context.Context, filter *paginationV1.FilterExpr) (bool, error) {
+	return a.repo.Exists(ctx, filter)
+}
@@ -1,43 +1,177 @@
 package cassandra
 
 import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
 	"github.com/gocql/gocql"
 )
 
-func NewCassandraClient(opts ...Option) *gocql.Session {
-	var o options
+type Client struct {
+	session *gocql.Session
+
+	options *options
+
+	logger *log.Helper
+}
+
+func NewClient(opts ...Option) (*Client, error) {
+	o := &options{}
 	for _, opt := range opts {
-		opt(&o)
+		opt(o)
+	}
+
+	if o.Logger == nil {
+		o.Logger = log.NewHelper(log.With(log.DefaultLogger, "module", "cassandra-client"))
+	}
+
+	c := &Client{
+		options: o,
+		logger:  o.Logger,
+	}
+
+	if err := c.createCassandraClient(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// NewCassandraClient 创建 Cassandra 会话，失败时返回 nil。
+//
+// Deprecated: 使用 NewClient，它会返回错误而不是 nil。
+func NewCassandraClient(opts ...Option) *gocql.Session {
+	c, err := NewClient(opts...)
+	if err != nil {
+		return nil
 	}
+	return c.session
+}
 
+// newClusterConfig 根据选项构建集群配置
+func newClusterConfig(o *options) *gocql.ClusterConfig {
 	clusterConfig := gocql.NewCluster(o.Hosts...)
 
 	// 设置用户名密码
-	clusterConfig.Authenticator = gocql.PasswordAuthenticator{
-		Username: o.Username,
-		Password: o.Password,
+	if o.Username != "" {
+		clusterConfig.Authenticator = gocql.PasswordAuthenticator{
+			Username: o.Username,
+			Password: o.Password,
+		}
 	}
 
 	clusterConfig.Keyspace = o.Keyspace
 
 	// 设置ssl
 	if o.TLSConfig != nil {
+		clusterConfig.SslOpts = &gocql.SslOptions{
+			Config:                 o.TLSConfig,
+			EnableHostVerification: !o.TLSConfig.InsecureSkipVerify,
+		}
 	}
 
 	// 设置超时时间
-	clusterConfig.ConnectTimeout = o.ConnectTimeout
-	clusterConfig.Timeout = o.Timeout
+	if o.ConnectTimeout > 0 {
+		clusterConfig.ConnectTimeout = o.ConnectTimeout
+	}
+	if o.Timeout > 0 {
+		clusterConfig.Timeout = o.Timeout
+	}
 
-	clusterConfig.Consistency = gocql.Consistency(o.Consistency)
+	if o.Consistency != 0 {
+		clusterConfig.Consistency = gocql.Consistency(o.Consistency)
+	}
 
 	// 禁止主机查找
 	clusterConfig.DisableInitialHostLookup = o.DisableInitialHostLookup
+	clusterConfig.IgnorePeerAddr = o.IgnorePeerAddr
+
+	return clusterConfig
+}
 
-	session, err := clusterConfig.CreateSession()
+// createCassandraClient 创建Cassandra会话
+func (c *Client) createCassandraClient() error {
+	session, err := newClusterConfig(c.options).CreateSession()
 	if err != nil {
-		o.Logger.Fatalf("failed opening connection to cassandra: %v", err)
+		c.logger.Errorf("failed opening connection to cassandra: %v", err)
+		return ErrConnectionFailed
+	}
+
+	c.session = session
+
+	return nil
+}
+
+// Session 返回底层的 gocql 会话
+func (c *Client) Session() *gocql.Session {
+	return c.session
+}
+
+// Close 关闭Cassandra会话
+func (c *Client) Close() {
+	if c.session == nil {
+		c.logger.Warn("cassandra client is already closed or not initialized")
+		return
+	}
+
+	c.session.Close()
+	c.logger.Info("cassandra client closed successfully")
+}
+
+// CheckConnection 检查Cassandra会话是否正常
+func (c *Client) CheckConnection(ctx context.Context) error {
+	if c.session == nil {
+		c.logger.Error("cassandra client is not initialized")
+		return ErrClientNotInitialized
+	}
+
+	if err := c.session.Query("SELECT release_version FROM system.local").WithContext(ctx).Exec(); err != nil {
+		c.logger.Errorf("ping failed: %v", err)
+		return ErrPingFailed
+	}
+
+	return nil
+}
+
+// Query 创建绑定上下文的查询
+func (c *Client) Query(ctx context.Context, stmt string, args ...any) *gocql.Query {
+	return c.session.Query(stmt, args...).WithContext(ctx)
+}
+
+// Exec 执行不返回结果的语句
+func (c *Client) Exec(ctx context.Context, stmt string, args ...any) error {
+	if c.session == nil {
+		c.logger.Error("cassandra client is not initialized")
+		return ErrClientNotInitialized
+	}
+
+	if err := c.Query(ctx, stmt, args...).Exec(); err != nil {
+		c.logger.Errorf("exec failed: %v", err)
+		return ErrExecutionFailed
+	}
+
+	return nil
+}
+
+// ExecBatch 以 UNLOGGED BATCH 执行同一语句的多组参数
+func (c *Client) ExecBatch(ctx context.Context, stmt string, argsList [][]any) error {
+	if c.session == nil {
+		c.logger.Error("cassandra client is not initialized")
+		return ErrClientNotInitialized
+	}
+	if len(argsList) == 0 {
 		return nil
 	}
 
-	return session
+	batch := c.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+	for _, args := range argsList {
+		batch.Query(stmt, args...)
+	}
+
+	if err := c.session.ExecuteBatch(batch); err != nil {
+		c.logger.Errorf("batch exec failed: %v", err)
+		return ErrBatchExecutionFailed
+	}
+
+	return nil
 }
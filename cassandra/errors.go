@@ -0,0 +1,26 @@
+package cassandra
+
+import "github.com/go-kratos/kratos/v2/errors"
+
+var (
+	// ErrClientNotInitialized is returned when the Cassandra client is not initialized.
+	ErrClientNotInitialized = errors.InternalServer("CLIENT_NOT_INITIALIZED", "cassandra client not initialized")
+
+	// ErrConnectionFailed is returned when the connection to Cassandra fails.
+	ErrConnectionFailed = errors.InternalServer("CONNECTION_FAILED", "failed to connect to Cassandra")
+
+	// ErrPingFailed is returned when a ping to Cassandra fails.
+	ErrPingFailed = errors.InternalServer("PING_FAILED", "ping to Cassandra server failed")
+
+	// ErrQueryExecutionFailed is returned when a query execution fails.
+	ErrQueryExecutionFailed = errors.InternalServer("QUERY_EXECUTION_FAILED", "query execution failed")
+
+	// ErrExecutionFailed is returned when a general execution fails.
+	ErrExecutionFailed = errors.InternalServer("EXECUTION_FAILED", "execution failed")
+
+	// ErrBatchExecutionFailed is returned when a batch execution fails.
+	ErrBatchExecutionFailed = errors.InternalServer("BATCH_EXECUTION_FAILED", "batch execution failed")
+
+	// ErrRowScanFailed is returned when scanning rows from a query result fails.
+	ErrRowScanFailed = errors.InternalServer("ROW_SCAN_FAILED", "row scan failed")
+)
@@ -0,0 +1,37 @@
+package field
+
+import (
+	"github.com/tx7do/go-crud/cassandra/query"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+)
+
+// Selector 字段选择器，用于构建 CQL 查询中的 SELECT 子句。
+type Selector struct {
+	mapping *fieldmap.Mapping
+}
+
+// NewFieldSelector 返回一个新的 Selector。
+func NewFieldSelector() *Selector { return &Selector{} }
+
+// WithFieldMapping 设置字段映射，字段路径按映射替换为列名。
+func (fs *Selector) WithFieldMapping(mapping *fieldmap.Mapping) *Selector {
+	fs.mapping = mapping
+	return fs
+}
+
+// BuildSelector 将 fields 追加到 builder 的 SELECT 列中。
+// 当 fields 为空时 builder 保持不变（即 SELECT *）。
+func (fs Selector) BuildSelector(builder *query.Builder, fields []string) (*query.Builder, error) {
+	if len(fields) == 0 {
+		return builder, nil
+	}
+
+	fields = NormalizePaths(fs.mapping.Paths(fields))
+	if len(fields) == 0 {
+		return builder, nil
+	}
+
+	builder.Select(fields...)
+
+	return builder, nil
+}
@@ -0,0 +1,35 @@
+package field
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tx7do/go-utils/stringcase"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+var identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// NormalizeFieldMaskPaths normalizes the paths in the given FieldMask to snake_case
+func NormalizeFieldMaskPaths(fm *fieldmaskpb.FieldMask) {
+	if fm == nil || len(fm.GetPaths()) == 0 {
+		return
+	}
+
+	fm.Normalize()
+
+	fm.Paths = NormalizePaths(fm.Paths)
+}
+
+// NormalizePaths 将字段路径标准化为 snake_case 列名，丢弃非法路径（CQL 不支持嵌套路径）。
+func NormalizePaths(fields []string) []string {
+	res := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if f == "" || !identifierRegexp.MatchString(f) {
+			continue
+		}
+		res = append(res, stringcase.ToSnakeCase(f))
+	}
+	return res
+}
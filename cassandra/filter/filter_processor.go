@@ -0,0 +1,205 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tx7do/go-utils/stringcase"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/cassandra/query"
+)
+
+// restrictionKind 条件对列的限制类型，用于校验 CQL 的主键限制
+type restrictionKind int
+
+const (
+	restrictionEQ restrictionKind = iota
+	restrictionIN
+	restrictionRange
+	restrictionContains
+	restrictionLike
+)
+
+// Processor 将单个 FilterCondition 转换为 CQL 条件片段
+type Processor struct{}
+
+func NewProcessor() *Processor {
+	return &Processor{}
+}
+
+// Process 根据 operator 生成对应的 CQL 条件片段、参数与限制类型。
+// CQL 不支持的操作符（如 NEQ、NOT IN、IS NULL、正则等）返回 ErrUnsupportedOperator。
+func (poc Processor) Process(schema *query.TableSchema, op paginationV1.Operator, field, value string, values []string) (string, []interface{}, restrictionKind, error) {
+	col, err := poc.column(field)
+	if err != nil {
+		return "", nil, 0, err
+	}
+
+	switch op {
+	case paginationV1.Operator_EQ, paginationV1.Operator_EXACT:
+		return poc.compare(schema, col, "=", value, restrictionEQ)
+	case paginationV1.Operator_GT:
+		return poc.compare(schema, col, ">", value, restrictionRange)
+	case paginationV1.Operator_GTE:
+		return poc.compare(schema, col, ">=", value, restrictionRange)
+	case paginationV1.Operator_LT:
+		return poc.compare(schema, col, "<", value, restrictionRange)
+	case paginationV1.Operator_LTE:
+		return poc.compare(schema, col, "<=", value, restrictionRange)
+	case paginationV1.Operator_IN:
+		return poc.In(schema, col, value, values)
+	case paginationV1.Operator_BETWEEN:
+		return poc.Between(schema, col, value, values)
+	case paginationV1.Operator_ARRAY_CONTAINS:
+		// 集合列包含某元素：col CONTAINS ?
+		return fmt.Sprintf("%s CONTAINS ?", col), []interface{}{value}, restrictionContains, nil
+	case paginationV1.Operator_LIKE:
+		return fmt.Sprintf("%s LIKE ?", col), []interface{}{value}, restrictionLike, nil
+	case paginationV1.Operator_CONTAINS:
+		return fmt.Sprintf("%s LIKE ?", col), []interface{}{"%" + value + "%"}, restrictionLike, nil
+	case paginationV1.Operator_STARTS_WITH:
+		return fmt.Sprintf("%s LIKE ?", col), []interface{}{value + "%"}, restrictionLike, nil
+	case paginationV1.Operator_ENDS_WITH:
+		return fmt.Sprintf("%s LIKE ?", col), []interface{}{"%" + value}, restrictionLike, nil
+	default:
+		return "", nil, 0, fmt.Errorf("%w: %s", ErrUnsupportedOperator, op.String())
+	}
+}
+
+// column 校验并规范化列名（CQL 不支持 JSON 路径）
+func (poc Processor) column(field string) (string, error) {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return "", ErrInvalidField
+	}
+	if !fieldNameRegexp.MatchString(field) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidField, field)
+	}
+	return stringcase.ToSnakeCase(field), nil
+}
+
+func (poc Processor) compare(schema *query.TableSchema, col, op, value string, kind restrictionKind) (string, []interface{}, restrictionKind, error) {
+	v, err := ConvertValue(schema.ColumnType(col), value)
+	if err != nil {
+		return "", nil, 0, fmt.Errorf("%w: %s: %v", ErrInvalidValue, col, err)
+	}
+	return fmt.Sprintf("%s %s ?", col, op), []interface{}{v}, kind, nil
+}
+
+// In 生成 col IN (?, ?, ...)，values 为空时按逗号分隔 value
+func (poc Processor) In(schema *query.TableSchema, col, value string, values []string) (string, []interface{}, restrictionKind, error) {
+	items := values
+	if len(items) == 0 && value != "" {
+		for _, p := range strings.Split(value, ",") {
+			items = append(items, strings.TrimSpace(p))
+		}
+	}
+	if len(items) == 0 {
+		return "", nil, 0, fmt.Errorf("%w: %s: empty IN list", ErrInvalidValue, col)
+	}
+
+	args := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		v, err := ConvertValue(schema.ColumnType(col), item)
+		if err != nil {
+			return "", nil, 0, fmt.Errorf("%w: %s: %v", ErrInvalidValue, col, err)
+		}
+		args = append(args, v)
+	}
+
+	ps := strings.TrimRight(strings.Repeat("?,", len(args)), ",")
+	return fmt.Sprintf("%s IN (%s)", col, ps), args, restrictionIN, nil
+}
+
+// Between 生成 col >= ? AND col <= ?（CQL 不支持 BETWEEN）
+func (poc Processor) Between(schema *query.TableSchema, col, value string, values []string) (string, []interface{}, restrictionKind, error) {
+	bounds := values
+	if len(bounds) < 2 {
+		bounds = strings.Split(value, ",")
+	}
+	if len(bounds) < 2 {
+		return "", nil, 0, fmt.Errorf("%w: %s: BETWEEN requires two values", ErrInvalidValue, col)
+	}
+
+	lo, err := ConvertValue(schema.ColumnType(col), strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return "", nil, 0, fmt.Errorf("%w: %s: %v", ErrInvalidValue, col, err)
+	}
+	hi, err := ConvertValue(schema.ColumnType(col), strings.TrimSpace(bounds[1]))
+	if err != nil {
+		return "", nil, 0, fmt.Errorf("%w: %s: %v", ErrInvalidValue, col, err)
+	}
+
+	return fmt.Sprintf("%s >= ? AND %s <= ?", col, col), []interface{}{lo, hi}, restrictionRange, nil
+}
+
+// ConvertValue 将字符串形式的过滤值转换为列对应的 Go 类型；类型未知时原样返回字符串。
+// gocql 对 timestamp/boolean/double 等类型不接受字符串，因此需要在绑定前转换。
+func ConvertValue(t reflect.Type, value string) (interface{}, error) {
+	if t == nil {
+		return value, nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return parseTime(value)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(b).Convert(t).Interface(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(i).Convert(t).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(u).Convert(t).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(f).Convert(t).Interface(), nil
+	case reflect.String:
+		return reflect.ValueOf(value).Convert(t).Interface(), nil
+	default:
+		// uuid、inet 等类型 gocql 可直接接受字符串
+		return value, nil
+	}
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseTime 解析时间字符串，支持 RFC3339、常见日期时间格式与 Unix 毫秒时间戳
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time value %q", value)
+}
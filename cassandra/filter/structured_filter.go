@@ -0,0 +1,252 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/cassandra/query"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
+)
+
+var (
+	// ErrUnsupportedOperator CQL 不支持的操作符
+	ErrUnsupportedOperator = errors.New("operator is not supported by cql")
+	// ErrOrNotSupported CQL 的 WHERE 子句不支持 OR
+	ErrOrNotSupported = errors.New("or expression is not supported by cql")
+	// ErrInvalidField 非法的字段名
+	ErrInvalidField = errors.New("invalid field name")
+	// ErrInvalidValue 无法转换为列类型的过滤值
+	ErrInvalidValue = errors.New("invalid filter value")
+	// ErrFilteringRequired 条件不满足主键限制，需要 ALLOW FILTERING
+	ErrFilteringRequired = errors.New("query requires ALLOW FILTERING")
+)
+
+// fieldNameRegexp 允许的字段名：以字母或下划线开头，后续允许字母数字下划线（CQL 不支持 JSON 路径）
+var fieldNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// StructuredFilter 基于 FilterExpr 的 Cassandra 过滤器
+type StructuredFilter struct {
+	processor *Processor
+	policy    *paginationFilter.FilterPolicy
+	mapping   *fieldmap.Mapping
+}
+
+func NewStructuredFilter() *StructuredFilter {
+	return &StructuredFilter{
+		processor: NewProcessor(),
+	}
+}
+
+// WithPolicy 设置字段策略，过滤条件需通过策略校验，字段按策略映射为列名
+func (sf *StructuredFilter) WithPolicy(policy *paginationFilter.FilterPolicy) *StructuredFilter {
+	sf.policy = policy
+	return sf
+}
+
+// WithFieldMapping 设置字段映射，条件字段按映射替换为列名
+func (sf *StructuredFilter) WithFieldMapping(mapping *fieldmap.Mapping) *StructuredFilter {
+	sf.mapping = mapping
+	return sf
+}
+
+type restriction struct {
+	column string
+	kind   restrictionKind
+}
+
+// BuildSelectors 将 FilterExpr 转为 CQL WHERE 条件并应用于 *query.Builder。
+// 若 builder 带有 TableSchema，会按分区键/聚簇键规则校验条件：
+// 不满足时在 schema.AllowFiltering 为 true 时追加 ALLOW FILTERING，否则返回 ErrFilteringRequired。
+func (sf StructuredFilter) BuildSelectors(builder *query.Builder, expr *paginationV1.FilterExpr) (*query.Builder, error) {
+	if builder == nil {
+		return nil, fmt.Errorf("builder is nil")
+	}
+	if expr == nil {
+		return builder, nil
+	}
+
+	expr, err := sf.policy.CheckFilter(expr)
+	if err != nil {
+		return builder, err
+	}
+	expr = sf.mapping.Filter(expr)
+	if expr.GetType() == paginationV1.ExprType_EXPR_TYPE_UNSPECIFIED {
+		log.Warn("Skipping unspecified FilterExpr")
+		return builder, nil
+	}
+
+	conditions, err := sf.flatten(expr)
+	if err != nil {
+		return builder, err
+	}
+
+	schema := builder.Schema()
+
+	restrictions := make([]restriction, 0, len(conditions))
+	for _, cond := range conditions {
+		clause, args, kind, err := sf.processor.Process(schema, cond.GetOp(), cond.GetField(), conditionValue(cond), cond.GetValues())
+		if err != nil {
+			return builder, err
+		}
+		builder.Where(clause, args...)
+		restrictions = append(restrictions, restriction{column: columnOf(clause), kind: kind})
+	}
+
+	if reasons := checkRestrictions(schema, restrictions); len(reasons) > 0 {
+		if !schema.AllowFiltering {
+			return builder, fmt.Errorf("%w: %s", ErrFilteringRequired, strings.Join(reasons, "; "))
+		}
+		builder.AllowFiltering()
+	}
+
+	return builder, nil
+}
+
+// flatten 将 FilterExpr 展开为 AND 连接的条件列表。
+// CQL 不支持 OR；仅当 OR 节点只有一个子项时等价于 AND，否则返回 ErrOrNotSupported。
+func (sf StructuredFilter) flatten(expr *paginationV1.FilterExpr) ([]*paginationV1.FilterCondition, error) {
+	if expr == nil || expr.GetType() == paginationV1.ExprType_EXPR_TYPE_UNSPECIFIED {
+		return nil, nil
+	}
+
+	if expr.GetType() == paginationV1.ExprType_OR && len(expr.GetConditions())+len(expr.GetGroups()) > 1 {
+		return nil, ErrOrNotSupported
+	}
+
+	var out []*paginationV1.FilterCondition
+	for _, cond := range expr.GetConditions() {
+		if cond == nil {
+			continue
+		}
+		out = append(out, cond)
+	}
+	for _, g := range expr.GetGroups() {
+		sub, err := sf.flatten(g)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sub...)
+	}
+	return out, nil
+}
+
+// conditionValue 读取条件的单值（支持 value 与 json_value）
+func conditionValue(cond *paginationV1.FilterCondition) string {
+	switch cond.GetValueOneof().(type) {
+	case *paginationV1.FilterCondition_Value:
+		return cond.GetValue()
+	case *paginationV1.FilterCondition_JsonValue:
+		return pagination.StructValueToString(cond.GetJsonValue())
+	default:
+		return ""
+	}
+}
+
+// columnOf 从条件片段中取出列名
+func columnOf(clause string) string {
+	if idx := strings.IndexByte(clause, ' '); idx > 0 {
+		return clause[:idx]
+	}
+	return clause
+}
+
+// checkRestrictions 按 CQL 规则校验条件，返回需要 ALLOW FILTERING 的原因列表：
+//   - 分区键要么全部以 = / IN 限定，要么都不限定；
+//   - 聚簇键只能在分区键完整限定后按顺序限定，且范围条件之后不能再限定后续聚簇键；
+//   - 普通列只能是单个索引列上的 = / CONTAINS / LIKE 条件。
+func checkRestrictions(schema *query.TableSchema, restrictions []restriction) []string {
+	if !schema.HasPrimaryKey() || len(restrictions) == 0 {
+		return nil
+	}
+
+	byColumn := make(map[string][]restrictionKind)
+	for _, r := range restrictions {
+		byColumn[r.column] = append(byColumn[r.column], r.kind)
+	}
+
+	var reasons []string
+
+	// 分区键
+	restrictedPartitionKeys := 0
+	for _, col := range schema.PartitionKeys {
+		kinds, ok := byColumn[col]
+		if !ok {
+			continue
+		}
+		if !onlyKinds(kinds, restrictionEQ, restrictionIN) {
+			reasons = append(reasons, fmt.Sprintf("partition key %s only supports = or IN", col))
+			continue
+		}
+		restrictedPartitionKeys++
+	}
+	partitionComplete := restrictedPartitionKeys == len(schema.PartitionKeys)
+	if restrictedPartitionKeys > 0 && !partitionComplete {
+		reasons = append(reasons, "partition key is partially restricted")
+	}
+
+	// 聚簇键
+	gap, ranged := false, false
+	for _, col := range schema.ClusteringKeys {
+		kinds, ok := byColumn[col]
+		if !ok {
+			gap = true
+			continue
+		}
+		switch {
+		case !partitionComplete:
+			reasons = append(reasons, fmt.Sprintf("clustering key %s is restricted without a complete partition key", col))
+		case gap || ranged:
+			reasons = append(reasons, fmt.Sprintf("clustering key %s is restricted after an unrestricted or range-restricted key", col))
+		case !onlyKinds(kinds, restrictionEQ, restrictionIN, restrictionRange):
+			reasons = append(reasons, fmt.Sprintf("clustering key %s does not support this operator", col))
+		}
+		if !onlyKinds(kinds, restrictionEQ, restrictionIN) {
+			ranged = true
+		}
+	}
+
+	// 普通列
+	indexed := 0
+	for _, r := range restrictions {
+		if schema.IsPrimaryKey(r.column) {
+			continue
+		}
+		if !schema.IsIndexed(r.column) {
+			reasons = append(reasons, fmt.Sprintf("column %s is neither a key nor indexed", r.column))
+			continue
+		}
+		if r.kind == restrictionIN || r.kind == restrictionRange {
+			reasons = append(reasons, fmt.Sprintf("indexed column %s only supports =, CONTAINS or LIKE", r.column))
+			continue
+		}
+		indexed++
+	}
+	if indexed > 1 {
+		reasons = append(reasons, "more than one indexed column is restricted")
+	}
+
+	return reasons
+}
+
+func onlyKinds(kinds []restrictionKind, allowed ...restrictionKind) bool {
+	for _, k := range kinds {
+		ok := false
+		for _, a := range allowed {
+			if k == a {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,255 @@
+package filter
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/cassandra/query"
+)
+
+func cond(field string, op paginationV1.Operator, value string, values ...string) *paginationV1.FilterCondition {
+	return &paginationV1.FilterCondition{
+		Field:      field,
+		Op:         op,
+		ValueOneof: &paginationV1.FilterCondition_Value{Value: value},
+		Values:     values,
+	}
+}
+
+func and(conds ...*paginationV1.FilterCondition) *paginationV1.FilterExpr {
+	return &paginationV1.FilterExpr{Type: paginationV1.ExprType_AND, Conditions: conds}
+}
+
+func testSchema(allowFiltering bool) *query.TableSchema {
+	return &query.TableSchema{
+		PartitionKeys:  []string{"tenant_id", "bucket"},
+		ClusteringKeys: []string{"created_at", "id"},
+		IndexedColumns: []string{"email"},
+		AllowFiltering: allowFiltering,
+		ColumnTypes: map[string]reflect.Type{
+			"tenant_id":  reflect.TypeOf(uint32(0)),
+			"bucket":     reflect.TypeOf(0),
+			"created_at": reflect.TypeOf(time.Time{}),
+			"id":         reflect.TypeOf(int64(0)),
+			"active":     reflect.TypeOf(false),
+			"email":      reflect.TypeOf(""),
+		},
+	}
+}
+
+func TestBuildSelectors_NilAndUnspecified(t *testing.T) {
+	sf := NewStructuredFilter()
+
+	qb := query.NewQueryBuilder("users", nil)
+	if _, err := sf.BuildSelectors(qb, nil); err != nil {
+		t.Fatalf("unexpected error for nil expr: %v", err)
+	}
+	if _, err := sf.BuildSelectors(qb, &paginationV1.FilterExpr{}); err != nil {
+		t.Fatalf("unexpected error for unspecified expr: %v", err)
+	}
+	if qb.HasConditions() {
+		t.Fatalf("expected no conditions")
+	}
+
+	if _, err := sf.BuildSelectors(nil, and()); err == nil {
+		t.Fatalf("expected error for nil builder")
+	}
+}
+
+func TestBuildSelectors_Operators(t *testing.T) {
+	sf := NewStructuredFilter()
+	qb := query.NewQueryBuilder("users", nil)
+
+	expr := and(
+		cond("tenantId", paginationV1.Operator_EQ, "1"),
+		cond("id", paginationV1.Operator_IN, "", "1", "2"),
+		cond("age", paginationV1.Operator_BETWEEN, "18,30"),
+		cond("tags", paginationV1.Operator_ARRAY_CONTAINS, "go"),
+		cond("name", paginationV1.Operator_STARTS_WITH, "al"),
+	)
+	if _, err := sf.BuildSelectors(qb, expr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sql, args := qb.Build()
+	want := "SELECT * FROM users WHERE tenant_id = ? AND id IN (?,?) AND age >= ? AND age <= ? AND tags CONTAINS ? AND name LIKE ?"
+	if sql != want {
+		t.Fatalf("unexpected sql:\n got: %s\nwant: %s", sql, want)
+	}
+	wantArgs := []interface{}{"1", "1", "2", "18", "30", "go", "al%"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+}
+
+func TestBuildSelectors_UnsupportedOperator(t *testing.T) {
+	sf := NewStructuredFilter()
+
+	for _, op := range []paginationV1.Operator{
+		paginationV1.Operator_NEQ,
+		paginationV1.Operator_NIN,
+		paginationV1.Operator_IS_NULL,
+		paginationV1.Operator_REGEXP,
+	} {
+		qb := query.NewQueryBuilder("users", nil)
+		_, err := sf.BuildSelectors(qb, and(cond("name", op, "x")))
+		if !errors.Is(err, ErrUnsupportedOperator) {
+			t.Fatalf("expected ErrUnsupportedOperator for %s, got %v", op, err)
+		}
+	}
+}
+
+func TestBuildSelectors_Or(t *testing.T) {
+	sf := NewStructuredFilter()
+
+	// 多个子项的 OR 不被 CQL 支持
+	qb := query.NewQueryBuilder("users", nil)
+	expr := &paginationV1.FilterExpr{
+		Type:       paginationV1.ExprType_OR,
+		Conditions: []*paginationV1.FilterCondition{cond("a", paginationV1.Operator_EQ, "1"), cond("b", paginationV1.Operator_EQ, "2")},
+	}
+	if _, err := sf.BuildSelectors(qb, expr); !errors.Is(err, ErrOrNotSupported) {
+		t.Fatalf("expected ErrOrNotSupported, got %v", err)
+	}
+
+	// 单个子项的 OR 等价于 AND，嵌套 AND 组被展开
+	qb = query.NewQueryBuilder("users", nil)
+	expr = &paginationV1.FilterExpr{
+		Type:       paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{cond("a", paginationV1.Operator_EQ, "1")},
+		Groups: []*paginationV1.FilterExpr{
+			{Type: paginationV1.ExprType_OR, Conditions: []*paginationV1.FilterCondition{cond("b", paginationV1.Operator_EQ, "2")}},
+		},
+	}
+	if _, err := sf.BuildSelectors(qb, expr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	where, _ := qb.BuildWhereParam()
+	if where != "a = ? AND b = ?" {
+		t.Fatalf("unexpected where: %s", where)
+	}
+}
+
+func TestBuildSelectors_ValueConversion(t *testing.T) {
+	sf := NewStructuredFilter()
+	qb := query.NewQueryBuilder("users", nil).WithSchema(testSchema(true))
+
+	expr := and(
+		cond("tenant_id", paginationV1.Operator_EQ, "7"),
+		cond("active", paginationV1.Operator_EQ, "true"),
+		cond("created_at", paginationV1.Operator_GTE, "2024-01-02T03:04:05Z"),
+	)
+	if _, err := sf.BuildSelectors(qb, expr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, args := qb.Build()
+	if args[0] != uint32(7) {
+		t.Fatalf("expected uint32 tenant_id, got %#v", args[0])
+	}
+	if args[1] != true {
+		t.Fatalf("expected bool active, got %#v", args[1])
+	}
+	if ts, ok := args[2].(time.Time); !ok || !ts.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Fatalf("expected time created_at, got %#v", args[2])
+	}
+
+	qb = query.NewQueryBuilder("users", nil).WithSchema(testSchema(true))
+	if _, err := sf.BuildSelectors(qb, and(cond("tenant_id", paginationV1.Operator_EQ, "abc"))); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("expected ErrInvalidValue, got %v", err)
+	}
+}
+
+func TestBuildSelectors_KeyRestrictions(t *testing.T) {
+	sf := NewStructuredFilter()
+
+	tests := []struct {
+		name          string
+		expr          *paginationV1.FilterExpr
+		needFiltering bool
+	}{
+		{
+			name: "full partition key",
+			expr: and(cond("tenant_id", paginationV1.Operator_EQ, "1"), cond("bucket", paginationV1.Operator_IN, "", "1", "2")),
+		},
+		{
+			name: "partition key with clustering prefix and range",
+			expr: and(
+				cond("tenant_id", paginationV1.Operator_EQ, "1"),
+				cond("bucket", paginationV1.Operator_EQ, "1"),
+				cond("created_at", paginationV1.Operator_GT, "2024-01-01"),
+			),
+		},
+		{
+			name: "single indexed column",
+			expr: and(cond("email", paginationV1.Operator_EQ, "a@b.c")),
+		},
+		{
+			name:          "partial partition key",
+			expr:          and(cond("tenant_id", paginationV1.Operator_EQ, "1")),
+			needFiltering: true,
+		},
+		{
+			name:          "range on partition key",
+			expr:          and(cond("tenant_id", paginationV1.Operator_GT, "1"), cond("bucket", paginationV1.Operator_EQ, "1")),
+			needFiltering: true,
+		},
+		{
+			name: "clustering key skipped",
+			expr: and(
+				cond("tenant_id", paginationV1.Operator_EQ, "1"),
+				cond("bucket", paginationV1.Operator_EQ, "1"),
+				cond("id", paginationV1.Operator_EQ, "1"),
+			),
+			needFiltering: true,
+		},
+		{
+			name: "clustering key after range",
+			expr: and(
+				cond("tenant_id", paginationV1.Operator_EQ, "1"),
+				cond("bucket", paginationV1.Operator_EQ, "1"),
+				cond("created_at", paginationV1.Operator_GT, "2024-01-01"),
+				cond("id", paginationV1.Operator_EQ, "1"),
+			),
+			needFiltering: true,
+		},
+		{
+			name:          "clustering key without partition key",
+			expr:          and(cond("created_at", paginationV1.Operator_GT, "2024-01-01")),
+			needFiltering: true,
+		},
+		{
+			name:          "non-indexed column",
+			expr:          and(cond("active", paginationV1.Operator_EQ, "true")),
+			needFiltering: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 不允许 ALLOW FILTERING 时应返回错误
+			qb := query.NewQueryBuilder("users", nil).WithSchema(testSchema(false))
+			_, err := sf.BuildSelectors(qb, tt.expr)
+			if tt.needFiltering != errors.Is(err, ErrFilteringRequired) {
+				t.Fatalf("needFiltering=%v, got err %v", tt.needFiltering, err)
+			}
+			if err != nil && !tt.needFiltering {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// 允许时应追加 ALLOW FILTERING
+			qb = query.NewQueryBuilder("users", nil).WithSchema(testSchema(true))
+			if _, err = sf.BuildSelectors(qb, tt.expr); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			sql, _ := qb.Build()
+			if tt.needFiltering != strings.HasSuffix(sql, "ALLOW FILTERING") {
+				t.Fatalf("needFiltering=%v, got sql %s", tt.needFiltering, sql)
+			}
+		})
+	}
+}
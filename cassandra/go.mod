@@ -1,17 +1,39 @@
 module github.com/tx7do/go-crud/cassandra
 
-go 1.24.6
+go 1.24.11
 
 replace github.com/tx7do/go-crud => ../
 
+replace github.com/tx7do/go-crud/api => ../api
+
+replace github.com/tx7do/go-crud/pagination => ../pagination
+
 require (
 	github.com/go-kratos/kratos/v2 v2.9.2
 	github.com/gocql/gocql v1.7.0
+	github.com/stretchr/testify v1.11.1
+	github.com/tx7do/go-crud v0.0.1
+	github.com/tx7do/go-crud/api v0.0.7
+	github.com/tx7do/go-crud/pagination v0.0.11
+	github.com/tx7do/go-utils v1.1.34
+	github.com/tx7do/go-utils/mapper v0.0.3
+	google.golang.org/protobuf v1.36.11
 )
 
 require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/golang/snappy v1.0.0 // indirect
+	github.com/google/gnostic v0.7.1 // indirect
+	github.com/google/gnostic-models v0.7.1 // indirect
 	github.com/hailocab/go-hostpool v0.0.0-20160125115350-e80d13ce29ed // indirect
-	github.com/kr/text v0.2.0 // indirect
+	github.com/jinzhu/copier v0.4.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.einride.tech/aip v0.79.0 // indirect
+	go.yaml.in/yaml/v3 v3.0.4 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20260114163908-3f89685c29c3 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260114163908-3f89685c29c3 // indirect
+	google.golang.org/grpc v1.74.2 // indirect
 	gopkg.in/inf.v0 v0.9.1 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,6 @@
+package pagination
+
+import "errors"
+
+// ErrInvalidToken 分页 token 无法解码为 paging state
+var ErrInvalidToken = errors.New("invalid paging token")
@@ -0,0 +1,35 @@
+package pagination
+
+import (
+	"github.com/tx7do/go-crud/cassandra/query"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/paginator"
+)
+
+// OffsetPaginator 基于 Offset 的分页器（Cassandra 版）
+// CQL 不支持 OFFSET，查询会以 LIMIT offset+limit 执行，由仓库在读取时跳过前 offset 行。
+type OffsetPaginator struct {
+	impl pagination.Paginator
+}
+
+func NewOffsetPaginator() *OffsetPaginator {
+	return &OffsetPaginator{
+		impl: paginator.NewOffsetPaginatorWithDefault(),
+	}
+}
+
+// BuildClause 根据传入的 offset/limit 更新内部状态并设置 builder 的 LIMIT 与客户端偏移。
+func (p *OffsetPaginator) BuildClause(builder *query.Builder, offset, limit int) *query.Builder {
+	p.impl.
+		WithOffset(offset).
+		WithLimit(limit)
+
+	lim := p.impl.Limit()
+	off := p.impl.Offset()
+
+	if lim <= 0 {
+		return builder
+	}
+
+	return builder.Offset(off).Limit(lim)
+}
@@ -0,0 +1,35 @@
+package pagination
+
+import (
+	"github.com/tx7do/go-crud/cassandra/query"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/paginator"
+)
+
+// PagePaginator 基于页码的分页器（Cassandra 版）
+// CQL 不支持 OFFSET，查询会以 LIMIT offset+size 执行，由仓库在读取时跳过前 offset 行。
+type PagePaginator struct {
+	impl pagination.Paginator
+}
+
+func NewPagePaginator() *PagePaginator {
+	return &PagePaginator{
+		impl: paginator.NewPagePaginatorWithDefault(),
+	}
+}
+
+// BuildClause 根据传入的 page/size 更新内部状态并设置 builder 的 LIMIT 与客户端偏移。
+func (p *PagePaginator) BuildClause(builder *query.Builder, page, size int) *query.Builder {
+	p.impl.
+		WithPage(page).
+		WithSize(size)
+
+	lim := p.impl.Limit()
+	off := p.impl.Offset()
+
+	if lim <= 0 {
+		return builder
+	}
+
+	return builder.Offset(off).Limit(lim)
+}
@@ -0,0 +1,72 @@
+package pagination
+
+import (
+	"github.com/tx7do/go-crud/cassandra/query"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/paginator"
+)
+
+// TokenPaginator 基于 Token 的分页器（Cassandra 版）
+// token 为 gocql 原生 paging state 经 CursorCodec 编码的结果，由上一页查询返回。
+type TokenPaginator struct {
+	impl  pagination.Paginator
+	codec pagination.CursorCodec
+}
+
+func NewTokenPaginator() *TokenPaginator {
+	return &TokenPaginator{
+		impl:  paginator.NewTokenPaginatorWithDefault(),
+		codec: pagination.DefaultCursorCodec(),
+	}
+}
+
+// WithCursorCodec 设置 token 的编解码器（签名/加密），为 nil 时使用默认编解码器
+func (p *TokenPaginator) WithCursorCodec(codec pagination.CursorCodec) *TokenPaginator {
+	if codec == nil {
+		codec = pagination.DefaultCursorCodec()
+	}
+	p.codec = codec
+	return p
+}
+
+// BuildClause 根据传入 token/pageSize 设置 builder 的页大小与 paging state。
+// token 为空时从第一页开始；scope 为 token 绑定的查询范围（见 pagination.CursorScope）。
+func (p *TokenPaginator) BuildClause(builder *query.Builder, token string, pageSize int, scope []byte) (*query.Builder, error) {
+	p.impl.
+		WithToken(token).
+		WithSize(pageSize)
+
+	builder.PageSize(p.impl.Size())
+
+	if token == "" {
+		return builder, nil
+	}
+
+	state, err := p.DecodeToken(token, scope)
+	if err != nil {
+		return builder, err
+	}
+
+	return builder.PageState(state), nil
+}
+
+// EncodeToken 将 paging state 编码为 token，state 为空（已无下一页）时返回空字符串
+func (p *TokenPaginator) EncodeToken(state []byte, scope []byte) (string, error) {
+	if len(state) == 0 {
+		return "", nil
+	}
+	return p.codec.Encode(state, scope)
+}
+
+// DecodeToken 将 token 解码为 paging state。
+// 签名、过期与 scope 校验失败时返回 pagination 包中对应的错误，内容为空时返回 ErrInvalidToken。
+func (p *TokenPaginator) DecodeToken(token string, scope []byte) ([]byte, error) {
+	state, err := p.codec.Decode(token, scope)
+	if err != nil {
+		return nil, err
+	}
+	if len(state) == 0 {
+		return nil, ErrInvalidToken
+	}
+	return state, nil
+}
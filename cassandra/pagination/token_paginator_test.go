@@ -0,0 +1,54 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tx7do/go-crud/cassandra/query"
+	"github.com/tx7do/go-crud/pagination"
+)
+
+func TestTokenPaginator_BuildClause(t *testing.T) {
+	p := NewTokenPaginator()
+
+	token, err := p.EncodeToken(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", token)
+
+	qb := query.NewQueryBuilder("users", nil)
+	_, err = p.BuildClause(qb, "", 10, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, qb.GetPageSize())
+	assert.Empty(t, qb.GetPageState())
+
+	scope := pagination.CursorScope(nil, nil, nil)
+	token, err = p.EncodeToken([]byte{0x01, 0x02}, scope)
+	assert.NoError(t, err)
+
+	qb = query.NewQueryBuilder("users", nil)
+	_, err = p.BuildClause(qb, token, 5, scope)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x02}, qb.GetPageState())
+
+	_, err = p.BuildClause(query.NewQueryBuilder("users", nil), token, 5, []byte("other"))
+	assert.ErrorIs(t, err, pagination.ErrCursorScopeMismatch)
+}
+
+func TestTokenPaginator_SignedCursor(t *testing.T) {
+	codec, err := pagination.NewSignedCursorCodec([]pagination.CursorKey{{ID: "k1", Secret: []byte("0123456789abcdef0123456789abcdef")}})
+	assert.NoError(t, err)
+
+	p := NewTokenPaginator().WithCursorCodec(codec)
+
+	token, err := p.EncodeToken([]byte{0x0a}, nil)
+	assert.NoError(t, err)
+	state, err := p.DecodeToken(token, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x0a}, state)
+
+	// 未签名的 token 不被接受
+	plain, _ := NewTokenPaginator().EncodeToken([]byte{0x0a}, nil)
+	_, err = p.DecodeToken(plain, nil)
+	assert.ErrorIs(t, err, pagination.ErrCursorMalformed)
+}
@@ -0,0 +1,276 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/tx7do/go-utils/stringcase"
+)
+
+// TableSchema 描述 Cassandra 表的主键结构与列类型，用于校验 WHERE / ORDER BY 是否满足 CQL 的限制
+type TableSchema struct {
+	// PartitionKeys 分区键（按建表顺序）
+	PartitionKeys []string
+	// ClusteringKeys 聚簇键（按建表顺序）
+	ClusteringKeys []string
+	// IndexedColumns 建有二级索引（或 SASI/SAI 索引）的普通列
+	IndexedColumns []string
+
+	// AllowFiltering 当条件不满足主键限制时，是否允许追加 ALLOW FILTERING；为 false 时直接返回错误
+	AllowFiltering bool
+
+	// ColumnTypes 列名到 Go 类型的映射，用于将字符串形式的过滤值转换为驱动可编码的类型
+	ColumnTypes map[string]reflect.Type
+}
+
+// IsPartitionKey 判断列是否为分区键
+func (s *TableSchema) IsPartitionKey(column string) bool {
+	return s != nil && containsString(s.PartitionKeys, column)
+}
+
+// IsClusteringKey 判断列是否为聚簇键
+func (s *TableSchema) IsClusteringKey(column string) bool {
+	return s != nil && containsString(s.ClusteringKeys, column)
+}
+
+// IsPrimaryKey 判断列是否为主键的一部分（分区键或聚簇键）
+func (s *TableSchema) IsPrimaryKey(column string) bool {
+	return s.IsPartitionKey(column) || s.IsClusteringKey(column)
+}
+
+// IsIndexed 判断列是否建有索引
+func (s *TableSchema) IsIndexed(column string) bool {
+	return s != nil && containsString(s.IndexedColumns, column)
+}
+
+// HasPrimaryKey 是否声明了主键结构
+func (s *TableSchema) HasPrimaryKey() bool {
+	return s != nil && len(s.PartitionKeys) > 0
+}
+
+// ColumnType 返回列的 Go 类型，未知时返回 nil
+func (s *TableSchema) ColumnType(column string) reflect.Type {
+	if s == nil || s.ColumnTypes == nil {
+		return nil
+	}
+	return s.ColumnTypes[column]
+}
+
+// Builder 用于构建 Cassandra CQL 查询
+type Builder struct {
+	table  string
+	schema *TableSchema
+
+	columns    []string
+	conditions []string
+	params     []interface{}
+	orderBy    []string
+
+	limit  int
+	offset int // CQL 不支持 OFFSET，由调用方在读取结果时跳过
+
+	pageSize  int
+	pageState []byte
+
+	allowFiltering bool
+
+	log *log.Helper
+}
+
+// NewQueryBuilder 创建一个新的 Builder 实例
+func NewQueryBuilder(table string, log *log.Helper) *Builder {
+	return &Builder{
+		log:    log,
+		table:  table,
+		params: []interface{}{},
+	}
+}
+
+// WithSchema 设置表结构，用于过滤与排序时的主键限制校验
+func (qb *Builder) WithSchema(schema *TableSchema) *Builder {
+	qb.schema = schema
+	return qb
+}
+
+// Schema 返回表结构，可能为 nil
+func (qb *Builder) Schema() *TableSchema {
+	return qb.schema
+}
+
+// TableName 返回查询的表名
+func (qb *Builder) TableName() string {
+	return qb.table
+}
+
+func (qb *Builder) Logger() *log.Helper {
+	return qb.log
+}
+
+// Select 设置查询的列
+func (qb *Builder) Select(columns ...string) *Builder {
+	for _, column := range columns {
+		if !isValidIdentifier(column) {
+			panic("Invalid column name")
+		}
+		qb.columns = append(qb.columns, column)
+	}
+	return qb
+}
+
+// Columns 返回已选择的列
+func (qb *Builder) Columns() []string {
+	return qb.columns
+}
+
+// Where 添加查询条件并支持参数化
+func (qb *Builder) Where(condition string, args ...interface{}) *Builder {
+	if !isValidCondition(condition) {
+		panic("Invalid condition")
+	}
+
+	qb.conditions = append(qb.conditions, condition)
+	qb.params = append(qb.params, args...)
+	return qb
+}
+
+// HasConditions 是否已有 WHERE 条件
+func (qb *Builder) HasConditions() bool {
+	return len(qb.conditions) > 0
+}
+
+// OrderBy 设置排序条件（CQL 仅允许按聚簇键排序）
+func (qb *Builder) OrderBy(order string, desc bool) *Builder {
+	order = strings.TrimSpace(order)
+	if order == "" {
+		return qb
+	}
+
+	col := stringcase.ToSnakeCase(order)
+	if !isValidIdentifier(col) {
+		panic("Invalid order expression")
+	}
+
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+
+	qb.orderBy = append(qb.orderBy, fmt.Sprintf("%s %s", col, dir))
+	return qb
+}
+
+// Limit 设置查询结果的限制数量
+func (qb *Builder) Limit(limit int) *Builder {
+	qb.limit = limit
+	return qb
+}
+
+// Offset 设置查询结果的偏移量。
+// CQL 不支持 OFFSET，构建时会将 LIMIT 扩大为 offset+limit，由调用方跳过前 offset 行。
+func (qb *Builder) Offset(offset int) *Builder {
+	qb.offset = offset
+	return qb
+}
+
+// GetOffset 返回需要在客户端跳过的行数
+func (qb *Builder) GetOffset() int {
+	return qb.offset
+}
+
+// PageSize 设置驱动分页的页大小
+func (qb *Builder) PageSize(size int) *Builder {
+	qb.pageSize = size
+	return qb
+}
+
+// GetPageSize 返回驱动分页的页大小
+func (qb *Builder) GetPageSize() int {
+	return qb.pageSize
+}
+
+// PageState 设置驱动分页的分页状态（上一页返回的 paging state）
+func (qb *Builder) PageState(state []byte) *Builder {
+	qb.pageState = state
+	return qb
+}
+
+// GetPageState 返回驱动分页的分页状态
+func (qb *Builder) GetPageState() []byte {
+	return qb.pageState
+}
+
+// AllowFiltering 追加 ALLOW FILTERING
+func (qb *Builder) AllowFiltering() *Builder {
+	qb.allowFiltering = true
+	return qb
+}
+
+// IsAllowFiltering 是否追加了 ALLOW FILTERING
+func (qb *Builder) IsAllowFiltering() bool {
+	return qb.allowFiltering
+}
+
+// Build 构建最终的 CQL 查询
+func (qb *Builder) Build() (string, []interface{}) {
+	query := "SELECT " + qb.buildColumns()
+	query += fmt.Sprintf(" FROM %s", qb.table)
+	query += qb.buildWhere()
+
+	if len(qb.orderBy) > 0 {
+		query += fmt.Sprintf(" ORDER BY %s", strings.Join(qb.orderBy, ", "))
+	}
+
+	if qb.limit > 0 {
+		limit := qb.limit
+		if qb.offset > 0 {
+			limit += qb.offset
+		}
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	if qb.allowFiltering {
+		query += " ALLOW FILTERING"
+	}
+
+	return query, qb.params
+}
+
+// BuildCount 构建 COUNT 查询
+func (qb *Builder) BuildCount() (string, []interface{}) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", qb.table)
+	query += qb.buildWhere()
+
+	if qb.allowFiltering {
+		query += " ALLOW FILTERING"
+	}
+
+	return query, qb.params
+}
+
+// BuildDelete 构建 DELETE 语句
+func (qb *Builder) BuildDelete() (string, []interface{}) {
+	query := fmt.Sprintf("DELETE FROM %s", qb.table)
+	query += qb.buildWhere()
+	return query, qb.params
+}
+
+func (qb *Builder) buildColumns() string {
+	if len(qb.columns) == 0 {
+		return "*"
+	}
+	return strings.Join(qb.columns, ", ")
+}
+
+func (qb *Builder) buildWhere() string {
+	if len(qb.conditions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" WHERE %s", strings.Join(qb.conditions, " AND "))
+}
+
+// BuildWhereParam 构建 WHERE 子句和参数列表
+func (qb *Builder) BuildWhereParam() (string, []interface{}) {
+	return strings.Join(qb.conditions, " AND "), qb.params
+}
@@ -0,0 +1,102 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	logger := log.NewHelper(log.DefaultLogger)
+	qb := NewQueryBuilder("users", logger)
+
+	// 测试 Select 方法
+	qb.Select("id", "name")
+	query, params := qb.Build()
+	assert.Equal(t, "SELECT id, name FROM users", query)
+	assert.Empty(t, params)
+
+	// 测试 Where 方法
+	qb.Where("tenant_id = ?", 1).Where("id IN (?,?)", 2, 3)
+	query, params = qb.Build()
+	assert.Contains(t, query, "WHERE tenant_id = ? AND id IN (?,?)")
+	assert.Equal(t, []interface{}{1, 2, 3}, params)
+
+	// 测试 OrderBy 方法
+	qb.OrderBy("createdAt", true)
+	query, _ = qb.Build()
+	assert.Contains(t, query, "ORDER BY created_at DESC")
+
+	// 测试 ALLOW FILTERING
+	qb.AllowFiltering()
+	query, _ = qb.Build()
+	assert.True(t, qb.IsAllowFiltering())
+	assert.Contains(t, query, " ALLOW FILTERING")
+}
+
+func TestQueryBuilder_LimitOffset(t *testing.T) {
+	qb := NewQueryBuilder("users", nil)
+
+	// CQL 不支持 OFFSET，LIMIT 应扩大为 offset+limit
+	qb.Offset(20).Limit(10)
+	query, _ := qb.Build()
+	assert.Equal(t, "SELECT * FROM users LIMIT 30", query)
+	assert.NotContains(t, query, "OFFSET")
+	assert.Equal(t, 20, qb.GetOffset())
+}
+
+func TestQueryBuilder_BuildCountAndDelete(t *testing.T) {
+	qb := NewQueryBuilder("users", nil)
+	qb.Where("id = ?", 1).Limit(10).OrderBy("name", false)
+
+	query, params := qb.BuildCount()
+	assert.Equal(t, "SELECT COUNT(*) FROM users WHERE id = ?", query)
+	assert.Equal(t, []interface{}{1}, params)
+
+	query, params = qb.BuildDelete()
+	assert.Equal(t, "DELETE FROM users WHERE id = ?", query)
+	assert.Equal(t, []interface{}{1}, params)
+
+	qb.AllowFiltering()
+	query, _ = qb.BuildCount()
+	assert.Equal(t, "SELECT COUNT(*) FROM users WHERE id = ? ALLOW FILTERING", query)
+}
+
+func TestQueryBuilder_Paging(t *testing.T) {
+	qb := NewQueryBuilder("users", nil)
+	qb.PageSize(50).PageState([]byte{1, 2, 3})
+
+	assert.Equal(t, 50, qb.GetPageSize())
+	assert.Equal(t, []byte{1, 2, 3}, qb.GetPageState())
+
+	query, _ := qb.Build()
+	assert.NotContains(t, query, "LIMIT")
+}
+
+func TestQueryBuilder_InvalidInput(t *testing.T) {
+	qb := NewQueryBuilder("users", nil)
+
+	assert.Panics(t, func() { qb.Select("id; DROP TABLE users") })
+	assert.Panics(t, func() { qb.Where("id = 1; --") })
+	assert.NotPanics(t, func() { qb.Select(`"Name"`) })
+}
+
+func TestTableSchema(t *testing.T) {
+	var nilSchema *TableSchema
+	assert.False(t, nilSchema.HasPrimaryKey())
+	assert.False(t, nilSchema.IsPrimaryKey("id"))
+	assert.Nil(t, nilSchema.ColumnType("id"))
+
+	schema := &TableSchema{
+		PartitionKeys:  []string{"tenant_id"},
+		ClusteringKeys: []string{"created_at", "id"},
+		IndexedColumns: []string{"email"},
+	}
+	assert.True(t, schema.HasPrimaryKey())
+	assert.True(t, schema.IsPartitionKey("tenant_id"))
+	assert.True(t, schema.IsClusteringKey("id"))
+	assert.True(t, schema.IsPrimaryKey("created_at"))
+	assert.False(t, schema.IsPrimaryKey("email"))
+	assert.True(t, schema.IsIndexed("email"))
+}
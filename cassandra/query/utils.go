@@ -0,0 +1,38 @@
+package query
+
+import (
+	"regexp"
+	"strings"
+)
+
+var identifierRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// isValidIdentifier 验证表名或列名是否合法
+func isValidIdentifier(identifier string) bool {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return false
+	}
+
+	// 支持用双引号包裹的大小写敏感标识符："Name"
+	if strings.HasPrefix(identifier, `"`) && strings.HasSuffix(identifier, `"`) && len(identifier) >= 2 {
+		return identifierRegexp.MatchString(identifier[1 : len(identifier)-1])
+	}
+
+	return identifierRegexp.MatchString(identifier)
+}
+
+// isValidCondition 验证条件语句是否合法
+func isValidCondition(condition string) bool {
+	// 简单验证条件中是否包含危险字符
+	return !strings.Contains(condition, ";") && !strings.Contains(condition, "--")
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
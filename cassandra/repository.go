@@ -0,0 +1,656 @@
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/tx7do/go-utils/mapper"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/cassandra/field"
+	"github.com/tx7do/go-crud/cassandra/filter"
+	paging "github.com/tx7do/go-crud/cassandra/pagination"
+	"github.com/tx7do/go-crud/cassandra/query"
+	"github.com/tx7do/go-crud/cassandra/sorting"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
+	"github.com/tx7do/go-crud/pagination/paginator"
+	paginationSorting "github.com/tx7do/go-crud/pagination/sorting"
+)
+
+// PagingResult 通用分页返回（含完整的分页元数据），见 pagination.PagingResult
+type PagingResult[E any] = pagination.PagingResult[E]
+
+// Repository Cassandra 仓库，包含常用的 CRUD 方法
+type Repository[DTO any, ENTITY any] struct {
+	mapper *mapper.CopierMapper[DTO, ENTITY]
+
+	offsetPaginator *paging.OffsetPaginator
+	pagePaginator   *paging.PagePaginator
+	tokenPaginator  *paging.TokenPaginator
+
+	structuredFilter *filter.StructuredFilter
+
+	structuredSorting      *sorting.StructuredSorting
+	orderByStringConverter *paginationSorting.OrderByStringConverter
+
+	fieldSelector *field.Selector
+
+	client *Client
+	log    *log.Helper
+
+	table   string
+	schema  *query.TableSchema
+	columns []columnField
+}
+
+func NewRepository[DTO any, ENTITY any](client *Client, mapper *mapper.CopierMapper[DTO, ENTITY], table string, logger *log.Helper) *Repository[DTO, ENTITY] {
+	columns := entityColumns(reflect.TypeOf((*ENTITY)(nil)).Elem())
+
+	if logger == nil {
+		logger = log.NewHelper(log.With(log.DefaultLogger, "module", "cassandra-repository"))
+	}
+
+	return &Repository[DTO, ENTITY]{
+		client: client,
+		mapper: mapper,
+
+		table: table,
+		log:   logger,
+
+		schema:  &query.TableSchema{ColumnTypes: columnTypes(columns)},
+		columns: columns,
+
+		offsetPaginator: paging.NewOffsetPaginator(),
+		pagePaginator:   paging.NewPagePaginator(),
+		tokenPaginator:  paging.NewTokenPaginator(),
+
+		structuredFilter: filter.NewStructuredFilter(),
+
+		structuredSorting:      sorting.NewStructuredSorting(),
+		orderByStringConverter: paginationSorting.NewOrderByStringConverter(),
+
+		fieldSelector: field.NewFieldSelector(),
+	}
+}
+
+// WithCursorCodec 设置 token 分页使用的游标编解码器，生产环境建议使用 pagination.SignedCursorCodec 防止 token 被伪造
+func (r *Repository[DTO, ENTITY]) WithCursorCodec(codec pagination.CursorCodec) *Repository[DTO, ENTITY] {
+	r.tokenPaginator.WithCursorCodec(codec)
+	return r
+}
+
+// WithFilterPolicy 设置过滤与排序的字段策略（白名单、列名映射、操作符限制等），违反策略的查询返回 paginationFilter.ErrPolicyViolation
+func (r *Repository[DTO, ENTITY]) WithFilterPolicy(policy *paginationFilter.FilterPolicy) *Repository[DTO, ENTITY] {
+	r.structuredFilter.WithPolicy(policy)
+	r.structuredSorting.WithPolicy(policy)
+	return r
+}
+
+// WithFieldMapping 设置 API 字段名到列名的映射，过滤、排序与字段选择均按映射解析列名
+func (r *Repository[DTO, ENTITY]) WithFieldMapping(mapping *fieldmap.Mapping) *Repository[DTO, ENTITY] {
+	r.structuredFilter.WithFieldMapping(mapping)
+	r.structuredSorting.WithFieldMapping(mapping)
+	r.fieldSelector.WithFieldMapping(mapping)
+	return r
+}
+
+// WithSchema 设置表的主键结构，用于校验过滤/排序条件以及确定 Update 的 WHERE 子句。
+// 列类型始终由 ENTITY 反射得到。
+func (r *Repository[DTO, ENTITY]) WithSchema(schema query.TableSchema) *Repository[DTO, ENTITY] {
+	schema.ColumnTypes = columnTypes(r.columns)
+	r.schema = &schema
+	return r
+}
+
+// newQueryBuilder 创建带表结构的查询构建器
+func (r *Repository[DTO, ENTITY]) newQueryBuilder() *query.Builder {
+	return query.NewQueryBuilder(r.table, r.log).WithSchema(r.schema)
+}
+
+// check 校验仓库是否可用
+func (r *Repository[DTO, ENTITY]) check() error {
+	if r.client == nil || r.client.session == nil {
+		return errors.New("cassandra client is nil")
+	}
+	if r.table == "" {
+		return errors.New("table is empty")
+	}
+	if len(r.columns) == 0 {
+		return errors.New("entity has no columns")
+	}
+	return nil
+}
+
+// Count 统计符合 FilterExpr 的记录数
+func (r *Repository[DTO, ENTITY]) Count(ctx context.Context, expr *paginationV1.FilterExpr) (int64, error) {
+	if err := r.check(); err != nil {
+		return 0, err
+	}
+
+	qb := r.newQueryBuilder()
+	if _, err := r.structuredFilter.BuildSelectors(qb, expr); err != nil {
+		r.log.Errorf("build structured filter selectors failed: %s", err.Error())
+		return 0, err
+	}
+
+	return r.count(ctx, qb)
+}
+
+// count 使用 builder 上的条件执行 COUNT 查询
+func (r *Repository[DTO, ENTITY]) count(ctx context.Context, qb *query.Builder) (int64, error) {
+	aSql, args := qb.BuildCount()
+
+	var cnt int64
+	if err := r.client.Query(ctx, aSql, args...).Scan(&cnt); err != nil {
+		r.log.Errorf("cassandra count query failed: %v", err)
+		return 0, ErrQueryExecutionFailed
+	}
+
+	return cnt, nil
+}
+
+// ListWithPaging 使用 PagingRequest 查询列表
+func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, req *paginationV1.PagingRequest) (*PagingResult[DTO], error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, errors.New("paging request is nil")
+	}
+
+	qb := r.newQueryBuilder()
+
+	var err error
+
+	// filters
+	var filterExpr *paginationV1.FilterExpr
+	filterExpr, err = paginationFilter.ConvertFilterByPagingRequest(req)
+	if err != nil {
+		r.log.Errorf("convert filter string to filter expr failed: %s", err.Error())
+		return nil, err
+	}
+	req.FilteringType = &paginationV1.PagingRequest_FilterExpr{FilterExpr: filterExpr}
+
+	if _, err = r.structuredFilter.BuildSelectors(qb, req.GetFilterExpr()); err != nil {
+		r.log.Errorf("build structured filter selectors failed: %s", err.Error())
+		return nil, err
+	}
+
+	// 计数
+	total, err := r.count(ctx, qb)
+	if err != nil {
+		return nil, err
+	}
+
+	// select fields
+	cols := r.selectColumns(qb, req.GetFieldMask().GetPaths())
+
+	// order by
+	var sortings []*paginationV1.Sorting
+	if len(req.GetSorting()) > 0 {
+		sortings = req.GetSorting()
+	} else if len(req.GetOrderBy()) > 0 {
+		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
+		if err != nil {
+			r.log.Errorf("convert order by string to sorting failed: %s", err.Error())
+			return nil, err
+		}
+	}
+	if _, err = r.structuredSorting.Validate(sortings); err != nil {
+		r.log.Errorf("validate sorting failed: %s", err.Error())
+		return nil, err
+	}
+	if len(sortings) > 0 {
+		_ = r.structuredSorting.BuildOrderClause(qb, sortings)
+	}
+
+	// pagination
+	var pager pagination.Paginator
+	scope := pagination.CursorScopeOf(req)
+	if !req.GetNoPaging() {
+		if req.Page != nil && req.PageSize != nil {
+			pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPage())).WithSize(int(req.GetPageSize()))
+			_ = r.pagePaginator.BuildClause(qb, int(req.GetPage()), int(req.GetPageSize()))
+		} else if req.Offset != nil && req.Limit != nil {
+			pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffset())).WithLimit(int(req.GetLimit()))
+			_ = r.offsetPaginator.BuildClause(qb, int(req.GetOffset()), int(req.GetLimit()))
+		} else if req.Token != nil {
+			size := int(req.GetPageSize())
+			if req.PageSize == nil {
+				size = int(req.GetLimit())
+			}
+			pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetToken()).WithSize(size)
+			if _, err = r.tokenPaginator.BuildClause(qb, req.GetToken(), size, scope); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	entities, nextToken, err := r.query(ctx, qb, cols, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if pager != nil {
+		pager.SetNextToken(nextToken)
+	}
+
+	return pagination.NewPagingResult(r.toDTOs(entities), total, pager), nil
+}
+
+// ListWithPagination 使用 PaginationRequest 查询列表
+func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, req *paginationV1.PaginationRequest) (*PagingResult[DTO], error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, errors.New("pagination request is nil")
+	}
+
+	qb := r.newQueryBuilder()
+
+	var err error
+
+	// filters
+	var filterExpr *paginationV1.FilterExpr
+	filterExpr, err = paginationFilter.ConvertFilterByPaginationRequest(req)
+	if err != nil {
+		r.log.Errorf("convert filter string to filter expr failed: %s", err.Error())
+		return nil, err
+	}
+	req.FilteringType = &paginationV1.PaginationRequest_FilterExpr{FilterExpr: filterExpr}
+
+	if _, err = r.structuredFilter.BuildSelectors(qb, req.GetFilterExpr()); err != nil {
+		r.log.Errorf("build structured filter selectors failed: %s", err.Error())
+		return nil, err
+	}
+
+	// 计数
+	total, err := r.count(ctx, qb)
+	if err != nil {
+		return nil, err
+	}
+
+	// select fields
+	cols := r.selectColumns(qb, req.GetFieldMask().GetPaths())
+
+	// order by
+	var sortings []*paginationV1.Sorting
+	if len(req.GetSorting()) > 0 {
+		sortings = req.GetSorting()
+	} else if len(req.GetOrderBy()) > 0 {
+		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
+		if err != nil {
+			r.log.Errorf("convert order by string to sorting failed: %s", err.Error())
+			return nil, err
+		}
+	}
+	if _, err = r.structuredSorting.Validate(sortings); err != nil {
+		r.log.Errorf("validate sorting failed: %s", err.Error())
+		return nil, err
+	}
+	if len(sortings) > 0 {
+		_ = r.structuredSorting.BuildOrderClause(qb, sortings)
+	}
+
+	// pagination
+	var pager pagination.Paginator
+	scope := pagination.CursorScopeOf(req)
+	switch req.GetPaginationType().(type) {
+	case *paginationV1.PaginationRequest_OffsetBased:
+		pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffsetBased().GetOffset())).WithLimit(int(req.GetOffsetBased().GetLimit()))
+		_ = r.offsetPaginator.BuildClause(qb, int(req.GetOffsetBased().GetOffset()), int(req.GetOffsetBased().GetLimit()))
+	case *paginationV1.PaginationRequest_PageBased:
+		pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPageBased().GetPage())).WithSize(int(req.GetPageBased().GetPageSize()))
+		_ = r.pagePaginator.BuildClause(qb, int(req.GetPageBased().GetPage()), int(req.GetPageBased().GetPageSize()))
+	case *paginationV1.PaginationRequest_TokenBased:
+		pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetTokenBased().GetToken()).WithSize(int(req.GetTokenBased().GetPageSize()))
+		if _, err = r.tokenPaginator.BuildClause(qb, req.GetTokenBased().GetToken(), int(req.GetTokenBased().GetPageSize()), scope); err != nil {
+			return nil, err
+		}
+	}
+
+	entities, nextToken, err := r.query(ctx, qb, cols, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if pager != nil {
+		pager.SetNextToken(nextToken)
+	}
+
+	return pagination.NewPagingResult(r.toDTOs(entities), total, pager), nil
+}
+
+// Get 根据 FilterExpr 获取单条记录，未找到时返回 (nil, nil)
+func (r *Repository[DTO, ENTITY]) Get(ctx context.Context, expr *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+
+	qb := r.newQueryBuilder()
+	if _, err := r.structuredFilter.BuildSelectors(qb, expr); err != nil {
+		r.log.Errorf("build structured filter selectors failed: %s", err.Error())
+		return nil, err
+	}
+
+	cols := r.selectColumns(qb, viewMask.GetPaths())
+	qb.Limit(1)
+
+	entities, _, err := r.query(ctx, qb, cols, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 {
+		return nil, nil
+	}
+
+	return r.mapper.ToDTO(entities[0]), nil
+}
+
+// Only alias
+func (r *Repository[DTO, ENTITY]) Only(ctx context.Context, expr *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	return r.Get(ctx, expr, viewMask)
+}
+
+// Create 插入一条记录，返回创建后的 DTO；viewMask 指定插入的列
+func (r *Repository[DTO, ENTITY]) Create(ctx context.Context, dto *DTO, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	if dto == nil {
+		return nil, errors.New("dto is nil")
+	}
+
+	field.NormalizeFieldMaskPaths(viewMask)
+	cols := filterColumns(r.columns, viewMask.GetPaths())
+	if len(cols) == 0 {
+		return nil, errors.New("no columns to insert")
+	}
+
+	ent := r.mapper.ToEntity(dto)
+	if ent == nil {
+		return nil, errors.New("entity is nil")
+	}
+
+	if err := r.client.Exec(ctx, r.insertStatement(cols), columnValues(reflect.ValueOf(ent).Elem(), cols)...); err != nil {
+		r.log.Errorf("create failed: %v", err)
+		return nil, err
+	}
+
+	return r.mapper.ToDTO(ent), nil
+}
+
+// BatchCreate 以 UNLOGGED BATCH 批量插入记录，返回创建后的 DTO 列表
+func (r *Repository[DTO, ENTITY]) BatchCreate(ctx context.Context, dtos []*DTO, viewMask *fieldmaskpb.FieldMask) ([]*DTO, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+
+	field.NormalizeFieldMaskPaths(viewMask)
+	cols := filterColumns(r.columns, viewMask.GetPaths())
+	if len(cols) == 0 {
+		return nil, errors.New("no columns to insert")
+	}
+
+	ents := make([]*ENTITY, 0, len(dtos))
+	argsList := make([][]any, 0, len(dtos))
+	for _, dto := range dtos {
+		if dto == nil {
+			continue
+		}
+		ent := r.mapper.ToEntity(dto)
+		if ent == nil {
+			continue
+		}
+		ents = append(ents, ent)
+		argsList = append(argsList, columnValues(reflect.ValueOf(ent).Elem(), cols))
+	}
+	if len(ents) == 0 {
+		return nil, nil
+	}
+
+	if err := r.client.ExecBatch(ctx, r.insertStatement(cols), argsList); err != nil {
+		r.log.Errorf("batch create failed: %v", err)
+		return nil, err
+	}
+
+	return r.toDTOs(ents), nil
+}
+
+// Update 按主键更新记录，updateMask 指定更新的列（为空时更新全部非主键列）。
+// 主键取自 WithSchema 声明的分区键与聚簇键；未声明时使用 `pk:"true"` 标记的字段或 id 列。
+// 注意：CQL 的 UPDATE 具有 upsert 语义，记录不存在时会被创建。
+func (r *Repository[DTO, ENTITY]) Update(ctx context.Context, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	if dto == nil {
+		return nil, errors.New("dto is nil")
+	}
+
+	pkCols := r.primaryKeyColumns()
+	if len(pkCols) == 0 {
+		return nil, errors.New("primary key field not found; cannot determine WHERE clause")
+	}
+
+	field.NormalizeFieldMaskPaths(updateMask)
+
+	setCols := make([]columnField, 0, len(r.columns))
+	for _, c := range filterColumns(r.columns, updateMask.GetPaths()) {
+		if !containsColumn(pkCols, c.name) {
+			setCols = append(setCols, c)
+		}
+	}
+	if len(setCols) == 0 {
+		return nil, errors.New("no columns to update")
+	}
+
+	ent := r.mapper.ToEntity(dto)
+	if ent == nil {
+		return nil, errors.New("entity is nil")
+	}
+	v := reflect.ValueOf(ent).Elem()
+
+	setExprs := make([]string, 0, len(setCols))
+	for _, c := range setCols {
+		setExprs = append(setExprs, c.name+" = ?")
+	}
+	whereExprs := make([]string, 0, len(pkCols))
+	for _, c := range pkCols {
+		whereExprs = append(whereExprs, c.name+" = ?")
+	}
+
+	aSql := fmt.Sprintf("UPDATE %s SET %s WHERE %s", r.table, strings.Join(setExprs, ", "), strings.Join(whereExprs, " AND "))
+	args := append(columnValues(v, setCols), columnValues(v, pkCols)...)
+
+	if err := r.client.Exec(ctx, aSql, args...); err != nil {
+		r.log.Errorf("update failed: %v", err)
+		return nil, err
+	}
+
+	return r.mapper.ToDTO(ent), nil
+}
+
+// Delete 删除符合 FilterExpr 的记录。
+// CQL 的 DELETE 只能按主键限定，因此 expr 不能为空，且不能依赖 ALLOW FILTERING。
+func (r *Repository[DTO, ENTITY]) Delete(ctx context.Context, expr *paginationV1.FilterExpr) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+
+	qb := r.newQueryBuilder()
+	if _, err := r.structuredFilter.BuildSelectors(qb, expr); err != nil {
+		r.log.Errorf("build structured filter selectors failed: %s", err.Error())
+		return err
+	}
+	if !qb.HasConditions() {
+		return errors.New("delete requires a filter")
+	}
+	if qb.IsAllowFiltering() {
+		return errors.New("delete must be restricted by primary key")
+	}
+
+	aSql, args := qb.BuildDelete()
+	if err := r.client.Exec(ctx, aSql, args...); err != nil {
+		r.log.Errorf("delete failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Exists 检查是否存在符合 FilterExpr 的记录
+func (r *Repository[DTO, ENTITY]) Exists(ctx context.Context, expr *paginationV1.FilterExpr) (bool, error) {
+	if err := r.check(); err != nil {
+		return false, err
+	}
+
+	qb := r.newQueryBuilder()
+	if _, err := r.structuredFilter.BuildSelectors(qb, expr); err != nil {
+		r.log.Errorf("build structured filter selectors failed: %s", err.Error())
+		return false, err
+	}
+	qb.Select(r.columns[0].name).Limit(1)
+
+	aSql, args := qb.Build()
+	iter := r.client.Query(ctx, aSql, args...).Iter()
+	exists := iter.NumRows() > 0
+	if err := iter.Close(); err != nil {
+		r.log.Errorf("exists query failed: %v", err)
+		return false, ErrQueryExecutionFailed
+	}
+
+	return exists, nil
+}
+
+// selectColumns 根据字段掩码确定查询的列并写入 builder；掩码为空或无匹配列时选择实体的全部列
+func (r *Repository[DTO, ENTITY]) selectColumns(qb *query.Builder, paths []string) []columnField {
+	cols := filterColumns(r.columns, field.NormalizePaths(paths))
+	if len(cols) == 0 {
+		cols = r.columns
+	}
+
+	if _, err := r.fieldSelector.BuildSelector(qb, columnNames(cols)); err != nil {
+		r.log.Errorf("build field select selector failed: %s", err.Error())
+	}
+
+	return cols
+}
+
+// query 执行查询并将结果扫描为实体列表。
+// 设置了 PageSize 时只读取当前页，并返回由 paging state 编码的下一页 token；
+// 设置了 Offset 时跳过前 offset 行（CQL 不支持 OFFSET）。
+// 下一页 token 绑定 scope，在其他查询中复用时将被拒绝。
+func (r *Repository[DTO, ENTITY]) query(ctx context.Context, qb *query.Builder, cols []columnField, scope []byte) ([]*ENTITY, string, error) {
+	aSql, args := qb.Build()
+
+	q := r.client.Query(ctx, aSql, args...)
+	paged := qb.GetPageSize() > 0
+	if paged {
+		q = q.PageSize(qb.GetPageSize()).PageState(qb.GetPageState()).Prefetch(0)
+	}
+
+	iter := q.Iter()
+
+	skip := qb.GetOffset()
+	entities := make([]*ENTITY, 0)
+	for {
+		// 只读取当前页，避免驱动自动拉取下一页
+		if paged && iter.WillSwitchPage() {
+			break
+		}
+
+		var ent ENTITY
+		if !iter.Scan(scanDest(reflect.ValueOf(&ent).Elem(), cols)...) {
+			break
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		entities = append(entities, &ent)
+	}
+
+	pageState := iter.PageState()
+
+	if err := iter.Close(); err != nil {
+		r.log.Errorf("list query failed: %v", err)
+		return nil, "", ErrQueryExecutionFailed
+	}
+
+	var nextToken string
+	if paged {
+		var err error
+		if nextToken, err = r.tokenPaginator.EncodeToken(pageState, scope); err != nil {
+			r.log.Errorf("encode next token failed: %v", err)
+			return nil, "", err
+		}
+	}
+
+	return entities, nextToken, nil
+}
+
+// primaryKeyColumns 返回用于定位单行的主键列
+func (r *Repository[DTO, ENTITY]) primaryKeyColumns() []columnField {
+	var names []string
+	if r.schema.HasPrimaryKey() {
+		names = append(append(names, r.schema.PartitionKeys...), r.schema.ClusteringKeys...)
+	} else {
+		for _, c := range r.columns {
+			if c.pk {
+				names = append(names, c.name)
+			}
+		}
+		if len(names) == 0 {
+			names = []string{"id"}
+		}
+	}
+
+	cols := make([]columnField, 0, len(names))
+	for _, name := range names {
+		for _, c := range r.columns {
+			if c.name == name {
+				cols = append(cols, c)
+				break
+			}
+		}
+	}
+	if len(cols) != len(names) {
+		return nil
+	}
+	return cols
+}
+
+// insertStatement 构建 INSERT 语句
+func (r *Repository[DTO, ENTITY]) insertStatement(cols []columnField) string {
+	placeholders := strings.TrimRight(strings.Repeat("?,", len(cols)), ",")
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", r.table, strings.Join(columnNames(cols), ","), placeholders)
+}
+
+func (r *Repository[DTO, ENTITY]) toDTOs(entities []*ENTITY) []*DTO {
+	dtos := make([]*DTO, 0, len(entities))
+	for _, ent := range entities {
+		dtos = append(dtos, r.mapper.ToDTO(ent))
+	}
+	return dtos
+}
+
+func containsColumn(cols []columnField, name string) bool {
+	for _, c := range cols {
+		if c.name == name {
+			return true
+		}
+	}
+	return false
+}
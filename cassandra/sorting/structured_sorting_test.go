@@ -0,0 +1,56 @@
+package sorting
+
+import (
+	"testing"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/cassandra/query"
+)
+
+func TestStructuredSorting_BuildOrderClause(t *testing.T) {
+	ss := NewStructuredSorting()
+
+	qb := query.NewQueryBuilder("events", nil)
+	ss.BuildOrderClause(qb, nil)
+	if sql, _ := qb.Build(); sql != "SELECT * FROM events" {
+		t.Fatalf("did not expect ORDER BY for nil orders, got: %s", sql)
+	}
+
+	qb = query.NewQueryBuilder("events", nil)
+	ss.BuildOrderClause(qb, []*paginationV1.Sorting{
+		{Field: "createdAt", Direction: paginationV1.Sorting_DESC},
+		nil,
+		{Field: "", Direction: paginationV1.Sorting_ASC},
+		{Field: "bad;field", Direction: paginationV1.Sorting_ASC},
+		{Field: "id", Direction: paginationV1.Sorting_ASC},
+	})
+	if sql, _ := qb.Build(); sql != "SELECT * FROM events ORDER BY created_at DESC, id ASC" {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+}
+
+func TestStructuredSorting_OnlyClusteringKeys(t *testing.T) {
+	ss := NewStructuredSorting()
+
+	qb := query.NewQueryBuilder("events", nil).WithSchema(&query.TableSchema{
+		PartitionKeys:  []string{"tenant_id"},
+		ClusteringKeys: []string{"created_at", "id"},
+	})
+	ss.BuildOrderClause(qb, []*paginationV1.Sorting{
+		{Field: "name", Direction: paginationV1.Sorting_ASC},
+		{Field: "created_at", Direction: paginationV1.Sorting_DESC},
+	})
+	if sql, _ := qb.Build(); sql != "SELECT * FROM events ORDER BY created_at DESC" {
+		t.Fatalf("expected only clustering keys in ORDER BY, got: %s", sql)
+	}
+}
+
+func TestStructuredSorting_BuildOrderClauseWithDefaultField(t *testing.T) {
+	ss := NewStructuredSorting()
+
+	qb := query.NewQueryBuilder("events", nil)
+	ss.BuildOrderClauseWithDefaultField(qb, nil, "created_at", true)
+	if sql, _ := qb.Build(); sql != "SELECT * FROM events ORDER BY created_at DESC" {
+		t.Fatalf("expected ORDER BY created_at DESC, got: %s", sql)
+	}
+}
@@ -0,0 +1,128 @@
+package cassandra
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/tx7do/go-utils/stringcase"
+)
+
+// columnField 实体字段与列的映射
+type columnField struct {
+	name  string
+	field string
+	index []int
+	typ   reflect.Type
+	pk    bool
+}
+
+// entityColumns 通过反射收集实体的列映射（优先使用 struct tag: cql -> db -> json，否则使用 snake_case 字段名），
+// 匿名嵌入的结构体（如 mixin）会被展开。
+func entityColumns(t reflect.Type) []columnField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var cols []columnField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct && sf.Tag.Get("cql") == "" {
+			for _, c := range entityColumns(sf.Type) {
+				c.index = append([]int{i}, c.index...)
+				cols = append(cols, c)
+			}
+			continue
+		}
+		// skip unexported
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		col := columnName(sf)
+		if col == "-" {
+			continue
+		}
+
+		cols = append(cols, columnField{
+			name:  col,
+			field: sf.Name,
+			index: []int{i},
+			typ:   sf.Type,
+			pk:    sf.Tag.Get("pk") == "true",
+		})
+	}
+	return cols
+}
+
+// columnName 根据 struct tag 决定列名
+func columnName(sf reflect.StructField) string {
+	for _, key := range []string{"cql", "db", "json"} {
+		tag := sf.Tag.Get(key)
+		if idx := strings.Index(tag, ","); idx != -1 {
+			tag = tag[:idx]
+		}
+		if tag != "" {
+			return tag
+		}
+	}
+	return stringcase.ToSnakeCase(sf.Name)
+}
+
+// columnTypes 返回列名到 Go 类型的映射
+func columnTypes(cols []columnField) map[string]reflect.Type {
+	m := make(map[string]reflect.Type, len(cols))
+	for _, c := range cols {
+		m[c.name] = c.typ
+	}
+	return m
+}
+
+// filterColumns 按 mask 过滤列（支持按字段名或列名匹配），mask 为空时返回全部列
+func filterColumns(cols []columnField, paths []string) []columnField {
+	if len(paths) == 0 {
+		return cols
+	}
+
+	mask := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		mask[p] = true
+	}
+
+	out := make([]columnField, 0, len(cols))
+	for _, c := range cols {
+		if mask[c.name] || mask[c.field] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// scanDest 为实体构建与列顺序对应的扫描目标
+func scanDest(v reflect.Value, cols []columnField) []any {
+	dest := make([]any, len(cols))
+	for i, c := range cols {
+		dest[i] = v.FieldByIndex(c.index).Addr().Interface()
+	}
+	return dest
+}
+
+// columnValues 取出实体在各列上的值
+func columnValues(v reflect.Value, cols []columnField) []any {
+	vals := make([]any, len(cols))
+	for i, c := range cols {
+		vals[i] = v.FieldByIndex(c.index).Interface()
+	}
+	return vals
+}
+
+// columnNames 返回列名列表
+func columnNames(cols []columnField) []string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.name
+	}
+	return names
+}
@@ -0,0 +1,53 @@
+package cassandra
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMixin struct {
+	CreatedAt time.Time
+}
+
+type testEntity struct {
+	testMixin
+	ID       int64   `cql:"id" pk:"true"`
+	UserName string  `json:"user_name,omitempty"`
+	Email    *string `db:"mail"`
+	Ignored  string  `cql:"-"`
+	internal string
+}
+
+func TestEntityColumns(t *testing.T) {
+	cols := entityColumns(reflect.TypeOf(&testEntity{}))
+
+	assert.Equal(t, []string{"created_at", "id", "user_name", "mail"}, columnNames(cols))
+	assert.Equal(t, []int{0, 0}, cols[0].index)
+	assert.True(t, cols[1].pk)
+	assert.Equal(t, reflect.TypeOf(int64(0)), columnTypes(cols)["id"])
+}
+
+func TestFilterColumns(t *testing.T) {
+	cols := entityColumns(reflect.TypeOf(testEntity{}))
+
+	assert.Len(t, filterColumns(cols, nil), len(cols))
+	assert.Equal(t, []string{"id", "user_name"}, columnNames(filterColumns(cols, []string{"user_name", "ID"})))
+}
+
+func TestScanDestAndValues(t *testing.T) {
+	cols := entityColumns(reflect.TypeOf(testEntity{}))
+
+	var e testEntity
+	dest := scanDest(reflect.ValueOf(&e).Elem(), cols)
+	*dest[1].(*int64) = 42
+	*dest[2].(*string) = "alice"
+	assert.Equal(t, int64(42), e.ID)
+	assert.Equal(t, "alice", e.UserName)
+
+	vals := columnValues(reflect.ValueOf(e), cols)
+	assert.Equal(t, int64(42), vals[1])
+	assert.Equal(t, "alice", vals[2])
+}
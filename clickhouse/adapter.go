@@ -0,0 +1,112 @@
+package clickhouse
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/clickhouse/query"
+)
+
+var _ crud.Repository[struct{}] = (*RepositoryAdapter[struct{}, struct{}])(nil)
+var _ crud.SoftDeleteRepository = (*RepositoryAdapter[struct{}, struct{}])(nil)
+
+// RepositoryAdapter 将 ClickHouse Repository 适配为通用的 crud.Repository 接口。
+// 按过滤条件的 Update/Delete 以 mutation 执行（ALTER TABLE ... UPDATE / DELETE FROM），并等待 mutation 完成后返回；
+// 实体包含 deleted_at 字段时 Delete 为软删除，物理删除使用 softdelete.HardDelete(ctx) 或 Purge。
+type RepositoryAdapter[DTO any, ENTITY any] struct {
+	repo *Repository[DTO, ENTITY]
+}
+
+// NewRepositoryAdapter 创建 ClickHouse 仓库适配器
+func NewRepositoryAdapter[DTO any, ENTITY any](repo *Repository[DTO, ENTITY]) *RepositoryAdapter[DTO, ENTITY] {
+	return &RepositoryAdapter[DTO, ENTITY]{
+		repo: repo,
+	}
+}
+
+// newQuery 将 FilterExpr 编译为查询构建器
+func (a *RepositoryAdapter[DTO, ENTITY]) newQuery(filter *paginationV1.FilterExpr) (*query.Builder, error) {
+	qb := query.NewQueryBuilder(a.repo.table, a.repo.log)
+	if filter == nil {
+		return qb, nil
+	}
+	if _, err := a.repo.structuredFilter.BuildSelectors(qb, filter); err != nil {
+		return nil, err
+	}
+	return qb, nil
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) List(ctx context.Context, req *paginationV1.PaginationRequest) (*crud.PagingResult[DTO], error) {
+	return a.repo.ListWithPagination(ctx, req)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Get(ctx context.Context, filter *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	qb, err := a.newQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	dto, err := a.repo.get(ctx, qb, viewMask)
+	if err != nil {
+		return nil, err
+	}
+	if dto == nil {
+		return nil, crud.ErrNotFound
+	}
+	return dto, nil
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Create(ctx context.Context, dto *DTO, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	return a.repo.Create(ctx, dto, viewMask)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Update(ctx context.Context, filter *paginationV1.FilterExpr, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return nil, err
+	}
+
+	rows, err := a.repo.UpdateByFilter(ctx, filter, dto, updateMask, WithWaitMutation(0))
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, crud.ErrNotFound
+	}
+
+	return a.Get(ctx, filter, nil)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Upsert(ctx context.Context, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	return a.repo.Upsert(ctx, dto, updateMask)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Delete(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return 0, err
+	}
+	return a.repo.DeleteByFilter(ctx, filter, a.repo.isHardDelete(ctx), WithWaitMutation(0))
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Count(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	total, err := a.repo.CountByFilter(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return int64(total), nil
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Exists(ctx context.Context, filter *paginationV1.FilterExpr) (bool, error) {
+	return a.repo.ExistsByFilter(ctx, filter)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Restore(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	return a.repo.Restore(ctx, filter, WithWaitMutation(0))
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Purge(ctx context.Context, filter *paginationV1.FilterExpr, olderThan time.Duration) (int64, error) {
+	return a.repo.Purge(ctx, filter, olderThan, WithWaitMutation(0))
+}
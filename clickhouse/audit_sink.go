@@ -0,0 +1,161 @@
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tx7do/go-crud/audit"
+)
+
+// DefaultAuditTable 审计日志表的默认表名
+const DefaultAuditTable = "audit_logs"
+
+// auditColumns 审计日志表的列，与 AuditLog 的 ch 标签一致
+var auditColumns = []string{
+	"trace_id", "timestamp",
+	"user_id", "tenant_id", "username", "user_ip", "user_agent",
+	"service", "module", "action", "resource",
+	"operation", "target_id", "pre_value", "post_value",
+	"status", "error_message", "cost_ms",
+	"extra",
+}
+
+// AuditLog 审计日志表结构
+type AuditLog struct {
+	TraceID   string    `ch:"trace_id"`
+	Timestamp time.Time `ch:"timestamp"`
+
+	UserID    uint64 `ch:"user_id"`
+	TenantID  uint64 `ch:"tenant_id"`
+	Username  string `ch:"username"`
+	UserIP    string `ch:"user_ip"`
+	UserAgent string `ch:"user_agent"`
+
+	Service  string `ch:"service"`
+	Module   string `ch:"module"`
+	Action   string `ch:"action"`
+	Resource string `ch:"resource"`
+
+	Operation string `ch:"operation"`
+	TargetID  string `ch:"target_id"`
+	PreValue  string `ch:"pre_value"`
+	PostValue string `ch:"post_value"`
+
+	Status       uint8  `ch:"status"`
+	ErrorMessage string `ch:"error_message"`
+	CostMS       int64  `ch:"cost_ms"`
+
+	Extra string `ch:"extra"`
+}
+
+// NewAuditLog 将审计日志条目转换为表记录
+func NewAuditLog(e *audit.Entry) *AuditLog {
+	row := &AuditLog{
+		TraceID:      e.TraceID,
+		Timestamp:    e.Timestamp,
+		UserID:       e.UserID,
+		TenantID:     e.TenantID,
+		Username:     e.Username,
+		UserIP:       e.UserIP,
+		UserAgent:    e.UserAgent,
+		Service:      e.Service,
+		Module:       e.Module,
+		Action:       e.Action,
+		Resource:     e.Resource,
+		Operation:    string(e.Operation),
+		TargetID:     e.TargetID,
+		PreValue:     string(e.PreValue),
+		PostValue:    string(e.PostValue),
+		Status:       uint8(e.Status),
+		ErrorMessage: e.ErrorMessage,
+		CostMS:       e.CostMS,
+	}
+	if len(e.Extra) > 0 {
+		if b, err := json.Marshal(e.Extra); err == nil {
+			row.Extra = string(b)
+		}
+	}
+	return row
+}
+
+// AuditSink 通过 BatchInserter 将审计日志批量写入 ClickHouse 表，可与 audit.NewBufferedAuditor 配合使用
+type AuditSink struct {
+	client   *Client
+	table    string
+	inserter *BatchInserter
+}
+
+var _ audit.Sink = (*AuditSink)(nil)
+
+// NewAuditSink 创建 ClickHouse 审计 Sink，table 为空时使用 DefaultAuditTable，batchSize 为 BatchInserter 的自动提交条数
+func NewAuditSink(ctx context.Context, client *Client, table string, batchSize int) (*AuditSink, error) {
+	if client == nil || client.conn == nil {
+		return nil, ErrClientNotInitialized
+	}
+	if table == "" {
+		table = DefaultAuditTable
+	}
+
+	inserter, err := NewBatchInserter(ctx, client.conn, table, batchSize, auditColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditSink{
+		client:   client,
+		table:    table,
+		inserter: inserter,
+	}, nil
+}
+
+// Migrate 创建审计日志表（MergeTree，按月分区）
+func (s *AuditSink) Migrate(ctx context.Context) error {
+	aSql := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	trace_id String,
+	timestamp DateTime64(3),
+	user_id UInt64,
+	tenant_id UInt64,
+	username String,
+	user_ip String,
+	user_agent String,
+	service LowCardinality(String),
+	module LowCardinality(String),
+	action LowCardinality(String),
+	resource String,
+	operation LowCardinality(String),
+	target_id String,
+	pre_value String,
+	post_value String,
+	status UInt8,
+	error_message String,
+	cost_ms Int64,
+	extra String
+) ENGINE = MergeTree
+PARTITION BY toYYYYMM(timestamp)
+ORDER BY (tenant_id, timestamp)`, s.table)
+
+	return s.client.Exec(ctx, aSql)
+}
+
+func (s *AuditSink) Write(_ context.Context, entries []*audit.Entry) error {
+	var errs []error
+	for _, e := range entries {
+		if e == nil {
+			continue
+		}
+		if err := s.inserter.Add(NewAuditLog(e)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := s.inserter.Flush(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (s *AuditSink) Close() error {
+	return s.inserter.Close()
+}
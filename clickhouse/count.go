@@ -0,0 +1,186 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/tx7do/go-crud/pagination"
+)
+
+// countByStrategy 按 context 中的统计策略计算列表总数
+func (r *Repository[DTO, ENTITY]) countByStrategy(ctx context.Context, baseWhere string, whereArgs ...any) (pagination.Count, error) {
+	strategy := pagination.CountStrategyFromContext(ctx)
+
+	switch strategy.Mode {
+	case pagination.CountModeSkip:
+		return pagination.SkippedCount(), nil
+
+	case pagination.CountModeCapped:
+		limit := strategy.Limit()
+		n, err := r.CountCapped(ctx, limit, baseWhere, whereArgs...)
+		if err != nil {
+			return pagination.Count{}, err
+		}
+		return pagination.CappedCount(int64(n), limit), nil
+
+	case pagination.CountModeEstimated:
+		n, ok, err := r.CountEstimated(ctx, baseWhere, whereArgs...)
+		if err != nil {
+			return pagination.Count{}, err
+		}
+		if ok {
+			return pagination.EstimatedCount(int64(n)), nil
+		}
+	}
+
+	total, err := r.Count(ctx, baseWhere, whereArgs...)
+	if err != nil {
+		return pagination.Count{}, err
+	}
+	return pagination.ExactCount(int64(total)), nil
+}
+
+// CountCapped 最多统计到 limit+1 条记录：SELECT count() FROM (SELECT 1 FROM table WHERE ... LIMIT limit+1)
+func (r *Repository[DTO, ENTITY]) CountCapped(ctx context.Context, limit int64, baseWhere string, whereArgs ...any) (uint64, error) {
+	if r.client == nil {
+		return 0, errors.New("clickhouse client is nil")
+	}
+	if r.table == "" {
+		return 0, errors.New("table is empty")
+	}
+	if limit <= 0 {
+		limit = pagination.DefaultCountCap
+	}
+
+	// 追加数据权限条件（同时展开单个切片参数）
+	baseWhere, whereArgs, err := r.scopedWhere(ctx, baseWhere, whereArgs)
+	if err != nil {
+		return 0, err
+	}
+	aSql := "SELECT count() FROM (SELECT 1 FROM " + r.table + whereClause(baseWhere) + " LIMIT ?)"
+	whereArgs = append(whereArgs, limit+1)
+
+	var cnt uint64
+	if err = r.client.conn.QueryRow(ctx, aSql, whereArgs...).Scan(&cnt); err != nil {
+		r.log.Errorf("clickhouse capped count query failed: %v", err)
+		return 0, errors.New("capped count query failed")
+	}
+	return cnt, nil
+}
+
+// CountEstimated 使用 ClickHouse 的统计信息估算记录数。
+// 无过滤条件时汇总 system.parts 中活跃分片的行数；
+// 有过滤条件时使用 EXPLAIN ESTIMATE，其结果为按索引裁剪后需要读取的行数，是匹配行数的上界。
+func (r *Repository[DTO, ENTITY]) CountEstimated(ctx context.Context, baseWhere string, whereArgs ...any) (total uint64, ok bool, err error) {
+	if r.client == nil {
+		return 0, false, errors.New("clickhouse client is nil")
+	}
+	if r.table == "" {
+		return 0, false, errors.New("table is empty")
+	}
+
+	// 追加数据权限条件（同时展开单个切片参数）
+	if baseWhere, whereArgs, err = r.scopedWhere(ctx, baseWhere, whereArgs); err != nil {
+		return 0, false, err
+	}
+
+	if strings.TrimSpace(baseWhere) == "" {
+		return r.partsRows(ctx)
+	}
+	return r.explainEstimateRows(ctx, baseWhere, whereArgs...)
+}
+
+// partsRows 汇总 system.parts 中当前表活跃分片的行数，表不是 MergeTree 系列时 ok 为 false
+func (r *Repository[DTO, ENTITY]) partsRows(ctx context.Context) (uint64, bool, error) {
+	database, table := "", r.table
+	if i := strings.LastIndex(r.table, "."); i >= 0 {
+		database, table = r.table[:i], r.table[i+1:]
+	}
+
+	aSql := "SELECT count(), sum(rows) FROM system.parts WHERE active AND table = ?"
+	args := []any{table}
+	if database != "" {
+		aSql += " AND database = ?"
+		args = append(args, database)
+	} else {
+		aSql += " AND database = currentDatabase()"
+	}
+
+	var parts, rows uint64
+	if err := r.client.conn.QueryRow(ctx, aSql, args...).Scan(&parts, &rows); err != nil {
+		r.log.Errorf("clickhouse estimated count query failed: %v", err)
+		return 0, false, errors.New("estimated count query failed")
+	}
+	if parts == 0 {
+		return 0, false, nil
+	}
+	return rows, true, nil
+}
+
+// explainEstimateRows 读取 EXPLAIN ESTIMATE 的 rows 列
+func (r *Repository[DTO, ENTITY]) explainEstimateRows(ctx context.Context, baseWhere string, whereArgs ...any) (uint64, bool, error) {
+	aSql := "EXPLAIN ESTIMATE SELECT 1 FROM " + r.table + whereClause(baseWhere)
+
+	rows, err := r.client.conn.Query(ctx, aSql, whereArgs...)
+	if err != nil {
+		r.log.Errorf("clickhouse explain estimate query failed: %v", err)
+		return 0, false, errors.New("estimated count query failed")
+	}
+	defer func() {
+		if cerr := rows.Close(); cerr != nil {
+			r.log.Errorf("failed to close rows: %v", cerr)
+		}
+	}()
+
+	var (
+		total uint64
+		found bool
+	)
+	for rows.Next() {
+		var (
+			database, table             string
+			partsCnt, rowsCnt, marksCnt uint64
+		)
+		if err = rows.Scan(&database, &table, &partsCnt, &rowsCnt, &marksCnt); err != nil {
+			r.log.Errorf("scan explain estimate failed: %v", err)
+			return 0, false, errors.New("scan estimated count failed")
+		}
+		total += rowsCnt
+		found = true
+	}
+	if err = rows.Err(); err != nil {
+		r.log.Errorf("rows iteration error: %v", err)
+		return 0, false, errors.New("rows iteration error")
+	}
+	return total, found, nil
+}
+
+// whereClause 将条件表达式转换为 " WHERE ..." 子句，条件已包含 WHERE 前缀时原样拼接
+func whereClause(baseWhere string) string {
+	bw := strings.TrimSpace(baseWhere)
+	if bw == "" {
+		return ""
+	}
+	if strings.HasPrefix(strings.ToUpper(bw), "WHERE") {
+		return " " + bw
+	}
+	return " WHERE " + bw
+}
+
+// expandWhereArgs 只传入一个切片参数时将其展开为独立参数
+func expandWhereArgs(whereArgs []any) []any {
+	if len(whereArgs) != 1 {
+		return whereArgs
+	}
+	v := reflect.ValueOf(whereArgs[0])
+	if !v.IsValid() || v.Kind() != reflect.Slice {
+		return whereArgs
+	}
+	expanded := make([]any, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		expanded[i] = v.Index(i).Interface()
+	}
+	return expanded
+}
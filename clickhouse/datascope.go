@@ -0,0 +1,75 @@
+package clickhouse
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tx7do/go-crud/clickhouse/filter"
+	"github.com/tx7do/go-crud/clickhouse/query"
+	"github.com/tx7do/go-crud/pagination/datascope"
+)
+
+// WithDataScope 设置数据权限（行级权限）策略，查询、计数、更新、删除均按 ctx 中的 Viewer 追加数据权限条件；
+// 数据权限条件不受 WithFilterPolicy 与 WithFieldMapping 的影响，列名以策略配置为准。
+// 创建与 Upsert 不做数据权限校验。
+func (r *Repository[DTO, ENTITY]) WithDataScope(policy *datascope.Policy) *Repository[DTO, ENTITY] {
+	r.dataScope = policy
+	if policy != nil {
+		r.dataScopeFilter = filter.NewStructuredFilter().WithFieldMapping(policy.Mapping())
+	} else {
+		r.dataScopeFilter = nil
+	}
+	return r
+}
+
+// applyDataScope 将数据权限条件追加到查询构建器
+func (r *Repository[DTO, ENTITY]) applyDataScope(ctx context.Context, qb *query.Builder) error {
+	if r.dataScope == nil {
+		return nil
+	}
+
+	expr, err := r.dataScope.Build(ctx)
+	if err != nil {
+		r.log.Errorf("build data scope failed: %v", err)
+		return err
+	}
+	if expr == nil {
+		return nil
+	}
+
+	if _, err = r.dataScopeFilter.BuildSelectors(qb, expr); err != nil {
+		r.log.Errorf("build data scope selectors failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// scopedWhere 以 AND 组合 baseWhere（可包含 WHERE 前缀）与软删除、数据权限条件，返回不含 WHERE 前缀的条件与参数
+func (r *Repository[DTO, ENTITY]) scopedWhere(ctx context.Context, baseWhere string, whereArgs []any) (string, []any, error) {
+	whereArgs = expandWhereArgs(whereArgs)
+	if r.dataScope == nil && r.softDeleteCondition(ctx) == "" {
+		return baseWhere, whereArgs, nil
+	}
+
+	qb := query.NewQueryBuilder(r.table, r.log)
+	if err := r.applyScopes(ctx, qb); err != nil {
+		return "", nil, err
+	}
+	scopeWhere, scopeArgs := qb.BuildWhereParam()
+	if scopeWhere == "" {
+		return baseWhere, whereArgs, nil
+	}
+
+	bw := strings.TrimSpace(baseWhere)
+	if strings.HasPrefix(strings.ToUpper(bw), "WHERE") {
+		bw = strings.TrimSpace(bw[len("WHERE"):])
+	}
+	if bw == "" {
+		return scopeWhere, scopeArgs, nil
+	}
+
+	args := make([]any, 0, len(whereArgs)+len(scopeArgs))
+	args = append(args, whereArgs...)
+	args = append(args, scopeArgs...)
+	return "(" + bw + ") AND (" + scopeWhere + ")", args, nil
+}
@@ -2,14 +2,28 @@ package field
 
 import (
 	"github.com/tx7do/go-crud/clickhouse/query"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
 )
 
 // Selector 字段选择器，用于构建 ClickHouse 查询中的 SELECT 子句。
-type Selector struct{}
+type Selector struct {
+	mapping *fieldmap.Mapping
+}
 
 // NewFieldSelector 返回一个新的 Selector。
 func NewFieldSelector() *Selector { return &Selector{} }
 
+// WithFieldMapping 设置字段映射，字段路径按映射替换为列名。
+func (fs *Selector) WithFieldMapping(mapping *fieldmap.Mapping) *Selector {
+	fs.mapping = mapping
+	return fs
+}
+
+// Resolve 返回字段路径按映射替换后的列名，未设置映射时原样返回。
+func (fs Selector) Resolve(field string) string {
+	return fs.mapping.Resolve(field)
+}
+
 // BuildSelector 返回一个用于将 SELECT 子句拼接到给定基础 SQL 的函数。
 // 当 fields 为空时返回 (nil, nil)。
 // 返回的函数接收一个 baseSQL（例如 "FROM table WHERE ..."）并返回完整 SQL。
@@ -18,7 +32,7 @@ func (fs Selector) BuildSelector(builder *query.Builder, fields []string) (*quer
 		return builder, nil
 	}
 
-	fields = NormalizePaths(fields)
+	fields = NormalizePaths(fs.mapping.Paths(fields))
 	if len(fields) == 0 {
 		return builder, nil
 	}
@@ -11,12 +11,16 @@ import (
 
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
 	"github.com/tx7do/go-crud/clickhouse/query"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
 )
 
 // StructuredFilter 基于 FilterExpr 的 ClickHouse 过滤器（不依赖 GORM）
 type StructuredFilter struct {
 	codec     encoding.Codec
 	processor *Processor
+	policy    *paginationFilter.FilterPolicy
+	mapping   *fieldmap.Mapping
 }
 
 func NewStructuredFilter() *StructuredFilter {
@@ -26,6 +30,18 @@ func NewStructuredFilter() *StructuredFilter {
 	}
 }
 
+// WithPolicy 设置字段策略，过滤条件需通过策略校验，字段按策略映射为列名
+func (sf *StructuredFilter) WithPolicy(policy *paginationFilter.FilterPolicy) *StructuredFilter {
+	sf.policy = policy
+	return sf
+}
+
+// WithFieldMapping 设置字段映射，已登记的字段直接使用映射的列名（可为关联表的列，如 org.name）
+func (sf *StructuredFilter) WithFieldMapping(mapping *fieldmap.Mapping) *StructuredFilter {
+	sf.mapping = mapping
+	return sf
+}
+
 // BuildSelectors 将 FilterExpr 转为并直接应用于 *query.Builder 的 WHERE/ARGS
 func (sf StructuredFilter) BuildSelectors(builder *query.Builder, expr *paginationV1.FilterExpr) (*query.Builder, error) {
 	if builder == nil {
@@ -34,6 +50,11 @@ func (sf StructuredFilter) BuildSelectors(builder *query.Builder, expr *paginati
 	if expr == nil {
 		return builder, nil
 	}
+
+	expr, err := sf.policy.CheckFilter(expr)
+	if err != nil {
+		return builder, err
+	}
 	if expr.GetType() == paginationV1.ExprType_EXPR_TYPE_UNSPECIFIED {
 		log.Warn("Skipping unspecified FilterExpr")
 		return builder, nil
@@ -84,9 +105,11 @@ func (sf StructuredFilter) buildParts(expr *paginationV1.FilterExpr) ([]string,
 		// 支持 JSON 字段 (e.g. preferences.daily_email) -> JSONExtractString(col, 'key')
 		isJSON := strings.Contains(field, ".")
 		var colExpr string
-		if isJSON {
+		if col, ok := sf.mapping.Lookup(field); ok {
+			colExpr = col
+		} else if isJSON {
 			parts := strings.SplitN(field, ".", 2)
-			col := stringcase.ToSnakeCase(parts[0])
+			col := sf.mapping.Column(parts[0])
 			jsonKey := parts[1]
 			colExpr = fmt.Sprintf("JSONExtractString(%s, '%s')", col, jsonKey)
 		} else {
@@ -2,16 +2,25 @@ module github.com/tx7do/go-crud/clickhouse
 
 go 1.25.3
 
+replace github.com/tx7do/go-crud => ../
+
 replace github.com/tx7do/go-crud/api => ../api
 
 replace github.com/tx7do/go-crud/pagination => ../pagination
 
+replace github.com/tx7do/go-crud/audit => ../audit
+
+replace github.com/tx7do/go-crud/viewer => ../viewer
+
 require (
 	github.com/ClickHouse/clickhouse-go/v2 v2.42.0
 	github.com/go-kratos/kratos/v2 v2.9.2
 	github.com/stretchr/testify v1.11.1
+	github.com/tx7do/go-crud v0.0.1
 	github.com/tx7do/go-crud/api v0.0.7
+	github.com/tx7do/go-crud/audit v0.0.2
 	github.com/tx7do/go-crud/pagination v0.0.11
+	github.com/tx7do/go-crud/viewer v0.0.5
 	github.com/tx7do/go-utils v1.1.34
 	github.com/tx7do/go-utils/mapper v0.0.3
 	google.golang.org/protobuf v1.36.11
@@ -34,10 +43,12 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/segmentio/asm v1.2.1 // indirect
 	github.com/shopspring/decimal v1.4.0 // indirect
+	go.einride.tech/aip v0.79.0 // indirect
 	go.opentelemetry.io/otel v1.39.0 // indirect
 	go.opentelemetry.io/otel/trace v1.39.0 // indirect
 	go.yaml.in/yaml/v3 v3.0.4 // indirect
 	golang.org/x/sys v0.40.0 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20260114163908-3f89685c29c3 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20260114163908-3f89685c29c3 // indirect
 	google.golang.org/grpc v1.78.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
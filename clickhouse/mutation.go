@@ -0,0 +1,356 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/clickhouse/field"
+	"github.com/tx7do/go-crud/clickhouse/query"
+	"github.com/tx7do/go-crud/pagination/softdelete"
+)
+
+// DefaultMutationPollInterval 等待 mutation 完成时轮询 system.mutations 的默认间隔
+const DefaultMutationPollInterval = 200 * time.Millisecond
+
+// ErrMutationFailed mutation 执行失败（system.mutations 中记录了失败原因）
+var ErrMutationFailed = errors.New("clickhouse mutation failed")
+
+type mutationOptions struct {
+	allowEmptyFilter bool
+
+	wait         bool
+	pollInterval time.Duration
+	timeout      time.Duration
+}
+
+// MutationOption 按过滤条件更新、删除的选项
+type MutationOption func(o *mutationOptions)
+
+// WithAllowEmptyFilter 允许过滤条件为空，此时更新、删除作用于全表；默认过滤条件为空时返回 crud.ErrEmptyFilter
+func WithAllowEmptyFilter() MutationOption {
+	return func(o *mutationOptions) {
+		o.allowEmptyFilter = true
+	}
+}
+
+// WithWaitMutation 提交 mutation 后轮询 system.mutations 等待其执行完成，timeout <= 0 时只受 ctx 控制
+func WithWaitMutation(timeout time.Duration) MutationOption {
+	return func(o *mutationOptions) {
+		o.wait = true
+		o.timeout = timeout
+	}
+}
+
+// WithMutationPollInterval 设置轮询 system.mutations 的间隔
+func WithMutationPollInterval(interval time.Duration) MutationOption {
+	return func(o *mutationOptions) {
+		o.pollInterval = interval
+	}
+}
+
+func newMutationOptions(opts []MutationOption) *mutationOptions {
+	o := &mutationOptions{pollInterval: DefaultMutationPollInterval}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	if o.pollInterval <= 0 {
+		o.pollInterval = DefaultMutationPollInterval
+	}
+	return o
+}
+
+// buildFilterWhere 将 FilterExpr 编译为 WHERE 条件表达式（不含 WHERE 前缀）与参数
+func (r *Repository[DTO, ENTITY]) buildFilterWhere(expr *paginationV1.FilterExpr) (string, []any, error) {
+	qb := query.NewQueryBuilder(r.table, r.log)
+	if expr == nil {
+		return "", nil, nil
+	}
+	if _, err := r.structuredFilter.BuildSelectors(qb, expr); err != nil {
+		return "", nil, err
+	}
+	where, args := qb.BuildWhereParam()
+	return where, args, nil
+}
+
+// mutationWhere 编译更新、删除使用的过滤条件；条件为空且未允许时返回 crud.ErrEmptyFilter，允许时返回 "1"
+func (r *Repository[DTO, ENTITY]) mutationWhere(expr *paginationV1.FilterExpr, o *mutationOptions) (string, []any, error) {
+	where, args, err := r.buildFilterWhere(expr)
+	if err != nil {
+		return "", nil, err
+	}
+	if strings.TrimSpace(where) == "" {
+		if !o.allowEmptyFilter {
+			return "", nil, crud.ErrEmptyFilter
+		}
+		return "1", nil, nil
+	}
+	return where, args, nil
+}
+
+// CountByFilter 统计符合 FilterExpr 的记录数
+func (r *Repository[DTO, ENTITY]) CountByFilter(ctx context.Context, expr *paginationV1.FilterExpr) (uint64, error) {
+	where, args, err := r.buildFilterWhere(expr)
+	if err != nil {
+		return 0, err
+	}
+	return r.Count(ctx, where, args...)
+}
+
+// ExistsByFilter 检查是否存在符合 FilterExpr 的记录
+func (r *Repository[DTO, ENTITY]) ExistsByFilter(ctx context.Context, expr *paginationV1.FilterExpr) (bool, error) {
+	where, args, err := r.buildFilterWhere(expr)
+	if err != nil {
+		return false, err
+	}
+	return r.Exists(ctx, where, args...)
+}
+
+// DeleteByFilter 删除符合 FilterExpr 的记录，返回提交删除前统计的匹配行数。
+// notSoftDelete 为 true 时执行轻量删除 DELETE FROM ... WHERE ...（包含已软删除的记录），
+// 否则执行 ALTER TABLE ... UPDATE deleted_at = now() WHERE ...，实体包含 deleted_by 字段时同时写入 ctx 中 Viewer 的用户 ID。
+// 过滤条件为空时返回 crud.ErrEmptyFilter，除非传入 WithAllowEmptyFilter。
+func (r *Repository[DTO, ENTITY]) DeleteByFilter(ctx context.Context, expr *paginationV1.FilterExpr, notSoftDelete bool, opts ...MutationOption) (int64, error) {
+	if r.client == nil {
+		return 0, errors.New("clickhouse client is nil")
+	}
+	if r.table == "" {
+		return 0, errors.New("table is empty")
+	}
+
+	o := newMutationOptions(opts)
+	where, args, err := r.mutationWhere(expr, o)
+	if err != nil {
+		return 0, err
+	}
+
+	// 硬删除不区分是否已软删除
+	if notSoftDelete {
+		if where, args, err = r.scopedWhere(softdelete.WithDeleted(ctx), where, args); err != nil {
+			return 0, err
+		}
+		aSql := fmt.Sprintf("DELETE FROM %s WHERE %s", r.table, where)
+		return r.execMutation(ctx, o, aSql, where, nil, args)
+	}
+
+	set, setVals, err := r.softDeleteAssignments(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if where, args, err = r.scopedWhere(ctx, where, args); err != nil {
+		return 0, err
+	}
+	aSql := fmt.Sprintf("ALTER TABLE %s UPDATE %s WHERE %s", r.table, set, where)
+
+	return r.execMutation(ctx, o, aSql, where, setVals, args)
+}
+
+// UpdateByFilter 按 FilterExpr 批量更新记录（ALTER TABLE ... UPDATE ... WHERE ...），返回提交更新前统计的匹配行数。
+// 更新字段由 updateMask 指定，未指定时更新 dto 中的非零值字段；主键字段不会被更新。
+// 过滤条件为空时返回 crud.ErrEmptyFilter，除非传入 WithAllowEmptyFilter。
+func (r *Repository[DTO, ENTITY]) UpdateByFilter(ctx context.Context, expr *paginationV1.FilterExpr, dto *DTO, updateMask *fieldmaskpb.FieldMask, opts ...MutationOption) (int64, error) {
+	if r.client == nil {
+		return 0, errors.New("clickhouse client is nil")
+	}
+	if r.table == "" {
+		return 0, errors.New("table is empty")
+	}
+	if dto == nil {
+		return 0, errors.New("dto is nil")
+	}
+
+	o := newMutationOptions(opts)
+	where, args, err := r.mutationWhere(expr, o)
+	if err != nil {
+		return 0, err
+	}
+	if where, args, err = r.scopedWhere(ctx, where, args); err != nil {
+		return 0, err
+	}
+
+	setExprs, setVals, err := r.updateAssignments(r.mapper.ToEntity(dto), updateMask)
+	if err != nil {
+		return 0, err
+	}
+
+	aSql := fmt.Sprintf("ALTER TABLE %s UPDATE %s WHERE %s", r.table, strings.Join(setExprs, ", "), where)
+
+	return r.execMutation(ctx, o, aSql, where, setVals, args)
+}
+
+// execMutation 统计匹配行数后提交 mutation，按需等待其完成；where 应已包含数据权限条件
+func (r *Repository[DTO, ENTITY]) execMutation(ctx context.Context, o *mutationOptions, aSql, where string, setVals, whereArgs []any) (int64, error) {
+	total, err := r.count(ctx, where, whereArgs...)
+	if err != nil {
+		return 0, err
+	}
+	if total == 0 {
+		return 0, nil
+	}
+
+	args := make([]any, 0, len(setVals)+len(whereArgs))
+	args = append(args, setVals...)
+	args = append(args, whereArgs...)
+	if err = r.client.conn.Exec(ctx, aSql, args...); err != nil {
+		r.log.Errorf("mutation failed: %v", err)
+		return 0, errors.New("mutation failed")
+	}
+
+	if o.wait {
+		if err = r.WaitForMutations(ctx, o.pollInterval, o.timeout); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(total), nil
+}
+
+// WaitForMutations 轮询 system.mutations，直到当前表没有未完成的 mutation。
+// mutation 记录了失败原因时返回 ErrMutationFailed；timeout <= 0 时只受 ctx 控制。
+func (r *Repository[DTO, ENTITY]) WaitForMutations(ctx context.Context, pollInterval, timeout time.Duration) error {
+	if r.client == nil {
+		return errors.New("clickhouse client is nil")
+	}
+	if r.table == "" {
+		return errors.New("table is empty")
+	}
+	if pollInterval <= 0 {
+		pollInterval = DefaultMutationPollInterval
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	database, table := "", r.table
+	if i := strings.LastIndex(r.table, "."); i >= 0 {
+		database, table = r.table[:i], r.table[i+1:]
+	}
+
+	aSql := "SELECT count(), anyIf(latest_fail_reason, latest_fail_reason != '') FROM system.mutations WHERE NOT is_done AND table = ?"
+	args := []any{table}
+	if database != "" {
+		aSql += " AND database = ?"
+		args = append(args, database)
+	} else {
+		aSql += " AND database = currentDatabase()"
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		var (
+			pending    uint64
+			failReason string
+		)
+		if err := r.client.conn.QueryRow(ctx, aSql, args...).Scan(&pending, &failReason); err != nil {
+			r.log.Errorf("query system.mutations failed: %v", err)
+			return errors.New("query mutations failed")
+		}
+		if failReason != "" {
+			r.log.Errorf("mutation on %s failed: %s", r.table, failReason)
+			return fmt.Errorf("%w: %s", ErrMutationFailed, failReason)
+		}
+		if pending == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// entityColumn 返回实体字段对应的列名（依次取 db、ch、json 标签，缺省为小写字段名）
+func entityColumn(sf reflect.StructField) string {
+	col := sf.Tag.Get("db")
+	if col == "" {
+		col = sf.Tag.Get("ch")
+	}
+	if col == "" {
+		col = sf.Tag.Get("json")
+		if idx := strings.Index(col, ","); idx != -1 {
+			col = col[:idx]
+		}
+	}
+	if col == "" {
+		col = strings.ToLower(sf.Name)
+	}
+	return col
+}
+
+// deletedAtColumn 查找实体中的 deleted_at 列
+func (r *Repository[DTO, ENTITY]) deletedAtColumn() (string, error) {
+	t := reflect.TypeOf((*ENTITY)(nil)).Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return "", errors.New("entity must be a struct type")
+	}
+
+	if col := r.entityColumnOf(softdelete.DefaultDeletedAtColumn); col != "" {
+		return col, nil
+	}
+	return "", errors.New("soft delete not supported: deleted_at field not found on entity")
+}
+
+// updateAssignments 根据实体与 updateMask 生成 "col = ?" 赋值列表，跳过主键字段（pk 标签或 id 列）
+func (r *Repository[DTO, ENTITY]) updateAssignments(ent *ENTITY, updateMask *fieldmaskpb.FieldMask) ([]string, []any, error) {
+	field.NormalizeFieldMaskPaths(updateMask)
+	mask := map[string]bool{}
+	for _, p := range updateMask.GetPaths() {
+		mask[strings.Trim(p, "`")] = true
+	}
+
+	v := reflect.ValueOf(ent)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return nil, nil, errors.New("entity must be a struct or pointer to struct")
+	}
+	t := v.Type()
+
+	setExprs := make([]string, 0)
+	setVals := make([]any, 0)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		col := entityColumn(sf)
+		if sf.Tag.Get("pk") == "true" || strings.ToLower(col) == "id" || strings.ToLower(sf.Name) == "id" {
+			continue
+		}
+
+		if len(mask) > 0 {
+			if !mask[sf.Name] && !mask[col] {
+				continue
+			}
+		} else if v.Field(i).IsZero() {
+			continue
+		}
+
+		setExprs = append(setExprs, fmt.Sprintf("%s = ?", col))
+		setVals = append(setVals, v.Field(i).Interface())
+	}
+
+	if len(setExprs) == 0 {
+		return nil, nil, errors.New("no columns to update")
+	}
+	return setExprs, setVals, nil
+}
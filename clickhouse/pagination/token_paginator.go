@@ -1,60 +1,75 @@
 package pagination
 
 import (
-	"encoding/base64"
+	"github.com/tx7do/go-utils/stringcase"
 
-	"github.com/go-kratos/kratos/v2/encoding"
-	_ "github.com/go-kratos/kratos/v2/encoding/json"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
 	"github.com/tx7do/go-crud/clickhouse/query"
 	"github.com/tx7do/go-crud/pagination"
-
+	"github.com/tx7do/go-crud/pagination/keyset"
 	"github.com/tx7do/go-crud/pagination/paginator"
 )
 
-// TokenPaginator 基于 Token 的分页器（ClickHouse 版）
-// BuildClause 返回可直接拼接到 ClickHouse 查询的子句，形式为:
-// - 当 token 为空或无效时: "LIMIT <n>"
-// - 当 token 有效且包含 last_id 时: "WHERE id > <last_id> LIMIT <n>"
+// TokenPaginator 基于 Token 的 keyset（seek）分页器（ClickHouse 版）
+// token 中记录了上一页边界行在所有排序列（含 tiebreaker）上的值，BuildClause 会追加：
+// - WHERE (a, b) > (?, ?) 或展开的 OR 条件（排序方向不一致时）
+// - ORDER BY 全部排序列
+// - LIMIT pageSize+1（多取一条用于判断是否存在下一页）
 type TokenPaginator struct {
-	impl  pagination.Paginator
-	codec encoding.Codec
+	impl       pagination.Paginator
+	tiebreaker string
+	codec      pagination.CursorCodec
 }
 
 func NewTokenPaginator() *TokenPaginator {
 	return &TokenPaginator{
-		impl:  paginator.NewTokenPaginatorWithDefault(),
-		codec: encoding.GetCodec("json"),
+		impl:       paginator.NewTokenPaginatorWithDefault(),
+		tiebreaker: keyset.DefaultTiebreaker,
+		codec:      pagination.DefaultCursorCodec(),
+	}
+}
+
+// WithTiebreaker 设置保证排序唯一性的列（默认 id）
+func (p *TokenPaginator) WithTiebreaker(column string) *TokenPaginator {
+	p.tiebreaker = column
+	return p
+}
+
+// WithCursorCodec 设置 token 的编解码器（签名/加密），为 nil 时使用默认编解码器
+func (p *TokenPaginator) WithCursorCodec(codec pagination.CursorCodec) *TokenPaginator {
+	if codec == nil {
+		codec = pagination.DefaultCursorCodec()
 	}
+	p.codec = codec
+	return p
 }
 
-// BuildClause 根据传入 token/pageSize 更新状态并返回 ClickHouse 的 WHERE/LIMIT 子句。
-// 若 pageSize <= 0 或解析失败则返回空字符串或仅 LIMIT。
-func (p *TokenPaginator) BuildClause(builder *query.Builder, token string, pageSize int) *query.Builder {
+// BuildSeek 根据传入 token/pageSize/排序更新状态并解析出本次查询的 keyset 状态。
+// scope 为 token 绑定的查询范围（见 pagination.CursorScope），在其他查询中复用 token 将返回错误。
+func (p *TokenPaginator) BuildSeek(token string, pageSize int, sortings []*paginationV1.Sorting, scope []byte) (*keyset.Seek, error) {
 	p.impl.
 		WithToken(token).
-		WithPage(pageSize)
+		WithSize(pageSize)
 
-	size := p.impl.Size()
-	if size <= 0 {
-		return builder
-	}
+	return keyset.NewSeek(token, p.impl.Size(), sortings, p.tiebreaker,
+		keyset.WithCodec(p.codec),
+		keyset.WithScope(scope),
+	)
+}
 
-	// 无 token 或无法解码时仅返回 LIMIT
-	if token == "" {
-		return builder.Limit(size)
+// BuildClause 将 keyset 条件、排序与 limit 应用到 query.Builder
+func (p *TokenPaginator) BuildClause(builder *query.Builder, seek *keyset.Seek) *query.Builder {
+	if builder == nil || seek == nil {
+		return builder
 	}
 
-	b, err := base64.StdEncoding.DecodeString(token)
-	if err != nil {
-		return builder.Limit(size)
+	if cond, args := seek.SQL(stringcase.ToSnakeCase); cond != "" {
+		builder.Where(cond, args...)
 	}
 
-	var c struct {
-		LastID int64 `json:"last_id"`
-	}
-	if err = p.codec.Unmarshal(b, &c); err != nil {
-		return builder.Limit(size)
+	for _, c := range seek.OrderBy() {
+		builder.OrderBy(c.Field, c.Desc)
 	}
 
-	return builder.Limit(size).Where("id > ?", c.LastID)
+	return builder.Limit(seek.Limit())
 }
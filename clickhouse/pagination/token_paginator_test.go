@@ -0,0 +1,77 @@
+package pagination
+
+import (
+	"testing"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/clickhouse/query"
+	"github.com/tx7do/go-crud/pagination/keyset"
+)
+
+func TestTokenPaginator_FirstPage(t *testing.T) {
+	p := NewTokenPaginator()
+	seek, err := p.BuildSeek("", 20, []*paginationV1.Sorting{{Field: "createdAt", Direction: paginationV1.Sorting_DESC}}, nil)
+	if err != nil {
+		t.Fatalf("build seek: %v", err)
+	}
+
+	qb := query.NewQueryBuilder("events", nil)
+	sql, args := p.BuildClause(qb, seek).Build()
+
+	want := "SELECT * FROM events ORDER BY created_at DESC, id ASC LIMIT 21"
+	if sql != want {
+		t.Fatalf("unexpected sql:\n got %s\nwant %s", sql, want)
+	}
+	if len(args) != 0 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestTokenPaginator_MixedDirections(t *testing.T) {
+	sortings := []*paginationV1.Sorting{
+		{Field: "createdAt", Direction: paginationV1.Sorting_DESC},
+		{Field: "name", Direction: paginationV1.Sorting_ASC},
+	}
+	cols, _ := keyset.Columns(sortings, "id")
+	token, _ := keyset.Encode(cols, []any{"2025-01-01 00:00:00", "bob", 42}, false)
+
+	p := NewTokenPaginator()
+	seek, err := p.BuildSeek(token, 10, sortings, nil)
+	if err != nil {
+		t.Fatalf("build seek: %v", err)
+	}
+
+	qb := query.NewQueryBuilder("events", nil).Where("tenant_id = ?", 1)
+	sql, args := p.BuildClause(qb, seek).Build()
+
+	want := "SELECT * FROM events WHERE tenant_id = ? AND " +
+		"((created_at < ?) OR (created_at = ? AND name > ?) OR (created_at = ? AND name = ? AND id > ?)) " +
+		"ORDER BY created_at DESC, name ASC, id ASC LIMIT 11"
+	if sql != want {
+		t.Fatalf("unexpected sql:\n got %s\nwant %s", sql, want)
+	}
+	if len(args) != 7 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestTokenPaginator_Backward(t *testing.T) {
+	cols, _ := keyset.Columns(nil, "id")
+	token, _ := keyset.Encode(cols, []any{100}, true)
+
+	p := NewTokenPaginator()
+	seek, err := p.BuildSeek(token, 10, nil, nil)
+	if err != nil {
+		t.Fatalf("build seek: %v", err)
+	}
+
+	sql, args := p.BuildClause(query.NewQueryBuilder("events", nil), seek).Build()
+
+	want := "SELECT * FROM events WHERE id < ? ORDER BY id DESC LIMIT 11"
+	if sql != want {
+		t.Fatalf("unexpected sql:\n got %s\nwant %s", sql, want)
+	}
+	if len(args) != 1 || args[0] != int64(100) {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
@@ -18,15 +18,18 @@ import (
 	paging "github.com/tx7do/go-crud/clickhouse/pagination"
 	"github.com/tx7do/go-crud/clickhouse/query"
 	"github.com/tx7do/go-crud/clickhouse/sorting"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/datascope"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
 	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
+	"github.com/tx7do/go-crud/pagination/keyset"
+	"github.com/tx7do/go-crud/pagination/paginator"
+	"github.com/tx7do/go-crud/pagination/softdelete"
 	paginationSorting "github.com/tx7do/go-crud/pagination/sorting"
 )
 
-// PagingResult 是通用的分页返回结构，包含 items 和 total 字段
-type PagingResult[E any] struct {
-	Items []*E   `json:"items"`
-	Total uint64 `json:"total"`
-}
+// PagingResult 通用分页返回（含完整的分页元数据），见 pagination.PagingResult
+type PagingResult[E any] = pagination.PagingResult[E]
 
 // Repository GORM 仓库，包含常用的 CRUD 方法
 type Repository[DTO any, ENTITY any] struct {
@@ -43,6 +46,9 @@ type Repository[DTO any, ENTITY any] struct {
 
 	fieldSelector *field.Selector
 
+	dataScope       *datascope.Policy
+	dataScopeFilter *filter.StructuredFilter
+
 	client *Client
 	log    *log.Helper
 
@@ -70,6 +76,27 @@ func NewRepository[DTO any, ENTITY any](client *Client, mapper *mapper.CopierMap
 	}
 }
 
+// WithCursorCodec 设置 token 分页使用的游标编解码器，生产环境建议使用 pagination.SignedCursorCodec 防止 token 被伪造
+func (r *Repository[DTO, ENTITY]) WithCursorCodec(codec pagination.CursorCodec) *Repository[DTO, ENTITY] {
+	r.tokenPaginator.WithCursorCodec(codec)
+	return r
+}
+
+// WithFilterPolicy 设置过滤与排序的字段策略（白名单、列名映射、操作符限制等），违反策略的查询返回 paginationFilter.ErrPolicyViolation
+func (r *Repository[DTO, ENTITY]) WithFilterPolicy(policy *paginationFilter.FilterPolicy) *Repository[DTO, ENTITY] {
+	r.structuredFilter.WithPolicy(policy)
+	r.structuredSorting.WithPolicy(policy)
+	return r
+}
+
+// WithFieldMapping 设置 API 字段名到列名的映射，过滤、排序与字段选择均按映射解析列名
+func (r *Repository[DTO, ENTITY]) WithFieldMapping(mapping *fieldmap.Mapping) *Repository[DTO, ENTITY] {
+	r.structuredFilter.WithFieldMapping(mapping)
+	r.structuredSorting.WithFieldMapping(mapping)
+	r.fieldSelector.WithFieldMapping(mapping)
+	return r
+}
+
 // Count 使用 ClickHouse client 计算符合 baseWhere 的记录数
 // baseWhere: 可以包含 "WHERE ..." 前缀或只写条件表达式（函数会自动拼接）
 // 示例调用： total, err := q.Count(ctx, "id = ?", id)
@@ -82,18 +109,17 @@ func (r *Repository[DTO, ENTITY]) Count(ctx context.Context, baseWhere string, w
 		return 0, errors.New("table is empty")
 	}
 
-	// 展开单个切片参数为独立参数
-	if len(whereArgs) == 1 {
-		v := reflect.ValueOf(whereArgs[0])
-		if v.IsValid() && v.Kind() == reflect.Slice {
-			expanded := make([]any, v.Len())
-			for i := 0; i < v.Len(); i++ {
-				expanded[i] = v.Index(i).Interface()
-			}
-			whereArgs = expanded
-		}
+	// 追加数据权限条件（同时展开单个切片参数）
+	baseWhere, whereArgs, err := r.scopedWhere(ctx, baseWhere, whereArgs)
+	if err != nil {
+		return 0, err
 	}
 
+	return r.count(ctx, baseWhere, whereArgs...)
+}
+
+// count 执行计数查询，不追加数据权限条件
+func (r *Repository[DTO, ENTITY]) count(ctx context.Context, baseWhere string, whereArgs ...any) (uint64, error) {
 	aSql := "SELECT COUNT(1) FROM " + r.table
 	bw := strings.TrimSpace(baseWhere)
 	if bw != "" {
@@ -160,45 +186,76 @@ func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, req *pagin
 	_, err = r.structuredFilter.BuildSelectors(queryBuilder, req.GetFilterExpr())
 	if err != nil {
 		log.Errorf("build structured filter selectors failed: %s", err.Error())
+		if errors.Is(err, paginationFilter.ErrPolicyViolation) {
+			return nil, err
+		}
 	}
 
-	// 计数
+	// 计数（统计方式由 pagination.WithCountStrategy 指定，数据权限条件由 Count 等方法追加）
 	aSql, args := queryBuilder.BuildWhereParam()
-	total, err := r.Count(ctx, aSql, args...)
+	count, err := r.countByStrategy(ctx, aSql, args...)
 	if err != nil {
 		r.log.Errorf("count query failed: %v", err)
 		return nil, err
 	}
 
-	// select fields
-	if req.FieldMask != nil && len(req.GetFieldMask().Paths) > 0 {
-		_, err = r.fieldSelector.BuildSelector(queryBuilder, req.GetFieldMask().GetPaths())
-		if err != nil {
-			log.Errorf("build field select selector failed: %s", err.Error())
-		}
+	// 软删除与数据权限条件（计数时已单独追加）
+	if err = r.applyScopes(ctx, queryBuilder); err != nil {
+		return nil, err
 	}
 
 	// order by
+	var sortings []*paginationV1.Sorting
 	if len(req.GetSorting()) > 0 {
-		_ = r.structuredSorting.BuildOrderClause(queryBuilder, req.GetSorting())
+		sortings = req.GetSorting()
 	} else if len(req.GetOrderBy()) > 0 {
-		var sortings []*paginationV1.Sorting
 		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
 		if err != nil {
 			log.Errorf("convert order by string to sorting failed: %s", err.Error())
 			return nil, err
 		}
-		_ = r.structuredSorting.BuildOrderClause(queryBuilder, sortings)
+	}
+
+	// 按字段策略校验排序字段，keyset 分页使用映射后的列名
+	var sortColumns []*paginationV1.Sorting
+	if sortColumns, err = r.structuredSorting.Validate(sortings); err != nil {
+		r.log.Errorf("validate sorting failed: %v", err)
+		return nil, err
 	}
 
 	// pagination
+	var seek *keyset.Seek
+	var pager pagination.Paginator
 	if !req.GetNoPaging() {
 		if req.Page != nil && req.PageSize != nil {
+			pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPage())).WithSize(int(req.GetPageSize()))
 			_ = r.pagePaginator.BuildClause(queryBuilder, int(req.GetPage()), int(req.GetPageSize()))
 		} else if req.Offset != nil && req.Limit != nil {
+			pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffset())).WithLimit(int(req.GetLimit()))
 			_ = r.offsetPaginator.BuildClause(queryBuilder, int(req.GetOffset()), int(req.GetLimit()))
 		} else if req.Token != nil && req.Offset != nil {
-			_ = r.tokenPaginator.BuildClause(queryBuilder, req.GetToken(), int(req.GetOffset()))
+			if seek, err = r.tokenPaginator.BuildSeek(req.GetToken(), int(req.GetOffset()), sortColumns, pagination.CursorScopeOf(req)); err != nil {
+				r.log.Errorf("build keyset seek failed: %v", err)
+				return nil, err
+			}
+			pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetToken()).WithSize(seek.Size)
+			_ = r.tokenPaginator.BuildClause(queryBuilder, seek)
+		}
+	}
+
+	// keyset 分页自行处理排序（含 tiebreaker 以及向前翻页时的反转）
+	if seek == nil && len(sortings) > 0 {
+		_ = r.structuredSorting.BuildOrderClause(queryBuilder, sortings)
+	}
+
+	// select fields（keyset 分页需要排序列的值生成游标，未请求的排序列在返回前清除）
+	var seekExtra []keyset.Column
+	if req.FieldMask != nil && len(req.GetFieldMask().Paths) > 0 {
+		var paths []string
+		paths, seekExtra = seek.Select(req.GetFieldMask().GetPaths(), r.fieldSelector.Resolve)
+		_, err = r.fieldSelector.BuildSelector(queryBuilder, paths)
+		if err != nil {
+			log.Errorf("build field select selector failed: %s", err.Error())
 		}
 	}
 
@@ -214,18 +271,31 @@ func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, req *pagin
 		return nil, errors.New("list query failed")
 	}
 
-	// 转换为 DTOs
-	dtos := make([]*DTO, 0, len(rawResults))
+	entities := make([]*ENTITY, 0, len(rawResults))
 	for _, res := range rawResults {
 		if ptr, ok := res.(*ENTITY); ok {
-			dtos = append(dtos, r.mapper.ToDTO(ptr))
+			entities = append(entities, ptr)
 		}
 	}
 
-	res := &PagingResult[DTO]{
-		Items: dtos,
-		Total: uint64(total),
+	if seek != nil {
+		var nextToken, prevToken string
+		if entities, nextToken, prevToken, err = keyset.Page(seek, entities); err != nil {
+			r.log.Errorf("build keyset token failed: %v", err)
+			return nil, err
+		}
+		pager.SetNextToken(nextToken)
+		pager.SetPrevToken(prevToken)
+		keyset.Clear(entities, seekExtra)
 	}
+
+	// 转换为 DTOs
+	dtos := make([]*DTO, 0, len(entities))
+	for _, ent := range entities {
+		dtos = append(dtos, r.mapper.ToDTO(ent))
+	}
+
+	res := pagination.NewPagingResultWithCount(dtos, count, pager)
 	return res, nil
 }
 
@@ -254,45 +324,76 @@ func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, req *p
 	_, err = r.structuredFilter.BuildSelectors(queryBuilder, req.GetFilterExpr())
 	if err != nil {
 		log.Errorf("build structured filter selectors failed: %s", err.Error())
+		if errors.Is(err, paginationFilter.ErrPolicyViolation) {
+			return nil, err
+		}
 	}
 
-	// 计数
+	// 计数（统计方式由 pagination.WithCountStrategy 指定，数据权限条件由 Count 等方法追加）
 	aSql, args := queryBuilder.BuildWhereParam()
-	total, err := r.Count(ctx, aSql, args...)
+	count, err := r.countByStrategy(ctx, aSql, args...)
 	if err != nil {
 		r.log.Errorf("count query failed: %v", err)
 		return nil, err
 	}
 
-	// select fields
-	if req.FieldMask != nil && len(req.GetFieldMask().Paths) > 0 {
-		_, err = r.fieldSelector.BuildSelector(queryBuilder, req.GetFieldMask().GetPaths())
-		if err != nil {
-			log.Errorf("build field select selector failed: %s", err.Error())
-		}
+	// 软删除与数据权限条件（计数时已单独追加）
+	if err = r.applyScopes(ctx, queryBuilder); err != nil {
+		return nil, err
 	}
 
 	// order by
+	var sortings []*paginationV1.Sorting
 	if len(req.GetSorting()) > 0 {
-		_ = r.structuredSorting.BuildOrderClause(queryBuilder, req.GetSorting())
+		sortings = req.GetSorting()
 	} else if len(req.GetOrderBy()) > 0 {
-		var sortings []*paginationV1.Sorting
 		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
 		if err != nil {
 			log.Errorf("convert order by string to sorting failed: %s", err.Error())
 			return nil, err
 		}
-		_ = r.structuredSorting.BuildOrderClause(queryBuilder, sortings)
+	}
+
+	// 按字段策略校验排序字段，keyset 分页使用映射后的列名
+	var sortColumns []*paginationV1.Sorting
+	if sortColumns, err = r.structuredSorting.Validate(sortings); err != nil {
+		r.log.Errorf("validate sorting failed: %v", err)
+		return nil, err
 	}
 
 	// pagination
+	var seek *keyset.Seek
+	var pager pagination.Paginator
 	switch req.GetPaginationType().(type) {
 	case *paginationV1.PaginationRequest_OffsetBased:
+		pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffsetBased().GetOffset())).WithLimit(int(req.GetOffsetBased().GetLimit()))
 		_ = r.offsetPaginator.BuildClause(queryBuilder, int(req.GetOffsetBased().GetOffset()), int(req.GetOffsetBased().GetLimit()))
 	case *paginationV1.PaginationRequest_PageBased:
+		pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPageBased().GetPage())).WithSize(int(req.GetPageBased().GetPageSize()))
 		_ = r.pagePaginator.BuildClause(queryBuilder, int(req.GetPageBased().GetPage()), int(req.GetPageBased().GetPageSize()))
 	case *paginationV1.PaginationRequest_TokenBased:
-		_ = r.tokenPaginator.BuildClause(queryBuilder, req.GetTokenBased().GetToken(), int(req.GetTokenBased().GetPageSize()))
+		if seek, err = r.tokenPaginator.BuildSeek(req.GetTokenBased().GetToken(), int(req.GetTokenBased().GetPageSize()), sortColumns, pagination.CursorScopeOf(req)); err != nil {
+			r.log.Errorf("build keyset seek failed: %v", err)
+			return nil, err
+		}
+		pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetTokenBased().GetToken()).WithSize(seek.Size)
+		_ = r.tokenPaginator.BuildClause(queryBuilder, seek)
+	}
+
+	// keyset 分页自行处理排序（含 tiebreaker 以及向前翻页时的反转）
+	if seek == nil && len(sortings) > 0 {
+		_ = r.structuredSorting.BuildOrderClause(queryBuilder, sortings)
+	}
+
+	// select fields（keyset 分页需要排序列的值生成游标，未请求的排序列在返回前清除）
+	var seekExtra []keyset.Column
+	if req.FieldMask != nil && len(req.GetFieldMask().Paths) > 0 {
+		var paths []string
+		paths, seekExtra = seek.Select(req.GetFieldMask().GetPaths(), r.fieldSelector.Resolve)
+		_, err = r.fieldSelector.BuildSelector(queryBuilder, paths)
+		if err != nil {
+			log.Errorf("build field select selector failed: %s", err.Error())
+		}
 	}
 
 	// 使用 client.Query（creator + results slice）
@@ -307,18 +408,31 @@ func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, req *p
 		return nil, errors.New("list query failed")
 	}
 
-	// 转换为 DTOs
-	dtos := make([]*DTO, 0, len(rawResults))
+	entities := make([]*ENTITY, 0, len(rawResults))
 	for _, res := range rawResults {
 		if ptr, ok := res.(*ENTITY); ok {
-			dtos = append(dtos, r.mapper.ToDTO(ptr))
+			entities = append(entities, ptr)
 		}
 	}
 
-	res := &PagingResult[DTO]{
-		Items: dtos,
-		Total: uint64(total),
+	if seek != nil {
+		var nextToken, prevToken string
+		if entities, nextToken, prevToken, err = keyset.Page(seek, entities); err != nil {
+			r.log.Errorf("build keyset token failed: %v", err)
+			return nil, err
+		}
+		pager.SetNextToken(nextToken)
+		pager.SetPrevToken(prevToken)
+		keyset.Clear(entities, seekExtra)
 	}
+
+	// 转换为 DTOs
+	dtos := make([]*DTO, 0, len(entities))
+	for _, ent := range entities {
+		dtos = append(dtos, r.mapper.ToDTO(ent))
+	}
+
+	res := pagination.NewPagingResultWithCount(dtos, count, pager)
 	return res, nil
 }
 
@@ -331,12 +445,19 @@ func (r *Repository[DTO, ENTITY]) Get(ctx context.Context, viewMask *fieldmaskpb
 		return nil, errors.New("table is empty")
 	}
 
+	return r.get(ctx, query.NewQueryBuilder(r.table, r.log), viewMask)
+}
+
+// get 使用给定的查询构建器（可已包含 where 条件）获取单条记录，未找到时返回 nil
+func (r *Repository[DTO, ENTITY]) get(ctx context.Context, qb *query.Builder, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	// 软删除与数据权限条件
+	if err := r.applyScopes(ctx, qb); err != nil {
+		return nil, err
+	}
+
 	// 规范 viewMask 路径
 	field.NormalizeFieldMaskPaths(viewMask)
 
-	// 构建查询
-	qb := query.NewQueryBuilder(r.table, r.log)
-
 	// 如果提供了 viewMask，则构建 select 子句（日志记录错误但继续）
 	if viewMask != nil && len(viewMask.Paths) > 0 {
 		if _, err := r.fieldSelector.BuildSelector(qb, viewMask.GetPaths()); err != nil {
@@ -799,13 +920,16 @@ func (r *Repository[DTO, ENTITY]) Update(ctx context.Context, dto *DTO, updateMa
 	// 主键值
 	pkVal := v.Field(pkIdx).Interface()
 
-	// 构造 ALTER TABLE ... UPDATE ... WHERE ... （ClickHouse mutation）
-	whereClause := fmt.Sprintf("%s = ?", pkCol)
+	// 构造 ALTER TABLE ... UPDATE ... WHERE ... （ClickHouse mutation），并追加数据权限条件
+	whereClause, whereArgs, err := r.scopedWhere(ctx, fmt.Sprintf("%s = ?", pkCol), []any{pkVal})
+	if err != nil {
+		return nil, err
+	}
 	aSql := fmt.Sprintf("ALTER TABLE %s UPDATE %s WHERE %s", r.table, strings.Join(setExprs, ", "), whereClause)
 
 	// 执行更新
-	args := append(setVals, pkVal)
-	if err := r.client.conn.Exec(ctx, aSql, args...); err != nil {
+	args := append(setVals, whereArgs...)
+	if err = r.client.conn.Exec(ctx, aSql, args...); err != nil {
 		r.log.Errorf("update failed: %v", err)
 		return nil, errors.New("update failed")
 	}
@@ -817,7 +941,7 @@ func (r *Repository[DTO, ENTITY]) Update(ctx context.Context, dto *DTO, updateMa
 		return &e
 	}
 	selectSQL := fmt.Sprintf("SELECT * FROM %s WHERE %s LIMIT 1", r.table, whereClause)
-	if err := r.client.Query(ctx, creator, &rawResults, selectSQL, pkVal); err != nil {
+	if err = r.client.Query(ctx, creator, &rawResults, selectSQL, whereArgs...); err != nil {
 		r.log.Errorf("read updated record failed: %v", err)
 		return nil, errors.New("read updated record failed")
 	}
@@ -952,13 +1076,16 @@ func (r *Repository[DTO, ENTITY]) UpdateX(ctx context.Context, dto *DTO, updateM
 	// 主键值
 	pkVal := v.Field(pkIdx).Interface()
 
-	// 构造 ALTER TABLE ... UPDATE ... WHERE ... （ClickHouse mutation）
-	whereClause := fmt.Sprintf("%s = ?", pkCol)
+	// 构造 ALTER TABLE ... UPDATE ... WHERE ... （ClickHouse mutation），并追加数据权限条件
+	whereClause, whereArgs, err := r.scopedWhere(ctx, fmt.Sprintf("%s = ?", pkCol), []any{pkVal})
+	if err != nil {
+		return 0, err
+	}
 	aSql := fmt.Sprintf("ALTER TABLE %s UPDATE %s WHERE %s", r.table, strings.Join(setExprs, ", "), whereClause)
 
 	// 执行更新
-	args := append(setVals, pkVal)
-	if err := r.client.conn.Exec(ctx, aSql, args...); err != nil {
+	args := append(setVals, whereArgs...)
+	if err = r.client.conn.Exec(ctx, aSql, args...); err != nil {
 		r.log.Errorf("update failed: %v", err)
 		return 0, errors.New("update failed")
 	}
@@ -1330,7 +1457,10 @@ func (r *Repository[DTO, ENTITY]) UpsertX(ctx context.Context, dto *DTO, updateM
 	return 1, nil
 }
 
-// Delete 使用传入的 db（可包含 Where）删除记录
+// Delete 删除全表记录：notSoftDelete 为 true 时清空表，否则将未删除记录的 deleted_at 置为当前时间，
+// 实体包含 deleted_by 字段时同时写入 ctx 中 Viewer 的用户 ID。
+// 设置了数据权限时只删除权限范围内的记录（硬删除改为 DELETE FROM ... WHERE ...）。
+// 按条件删除请使用 DeleteByFilter。
 func (r *Repository[DTO, ENTITY]) Delete(ctx context.Context, notSoftDelete bool) (int64, error) {
 	if r.client == nil {
 		return 0, errors.New("clickhouse client is nil")
@@ -1339,61 +1469,42 @@ func (r *Repository[DTO, ENTITY]) Delete(ctx context.Context, notSoftDelete bool
 		return 0, errors.New("table is empty")
 	}
 
-	// 硬删除：清空表
+	// 硬删除：清空表（包含已软删除的记录）
 	if notSoftDelete {
+		where, args, err := r.scopedWhere(softdelete.WithDeleted(ctx), "", nil)
+		if err != nil {
+			return 0, err
+		}
+
 		aSql := fmt.Sprintf("TRUNCATE TABLE %s", r.table)
-		if err := r.client.conn.Exec(ctx, aSql); err != nil {
-			r.log.Errorf("TRUNCATE TABLE failed: %v", err)
+		if where != "" {
+			aSql = fmt.Sprintf("DELETE FROM %s WHERE %s", r.table, where)
+		}
+		if err = r.client.conn.Exec(ctx, aSql, args...); err != nil {
+			r.log.Errorf("delete all failed: %v", err)
 			return 0, errors.New("delete failed")
 		}
 		// ClickHouse Exec 通常不提供 RowsAffected，成功则返回 1
 		return 1, nil
 	}
 
-	// 软删除：通过反射获取 ENTITY 类型（避免 var e ENTITY 导致的 nil Type 问题）
-	t := reflect.TypeOf((*ENTITY)(nil)).Elem()
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
-	}
-	if t == nil || t.Kind() != reflect.Struct {
-		return 0, errors.New("entity must be a struct type")
+	// 软删除：写入删除时间与删除者
+	set, setVals, err := r.softDeleteAssignments(ctx)
+	if err != nil {
+		return 0, err
 	}
 
-	// 查找可能的 deleted_at 字段（支持 tag: db/ch/json 或 字段名）
-	deletedCol := ""
-	for i := 0; i < t.NumField(); i++ {
-		sf := t.Field(i)
-		// skip unexported
-		if sf.PkgPath != "" {
-			continue
-		}
-		col := sf.Tag.Get("db")
-		if col == "" {
-			col = sf.Tag.Get("ch")
-		}
-		if col == "" {
-			col = sf.Tag.Get("json")
-			if idx := strings.Index(col, ","); idx != -1 {
-				col = col[:idx]
-			}
-		}
-		if col == "" {
-			col = strings.ToLower(sf.Name)
-		}
-		lc := strings.ToLower(col)
-		nameLc := strings.ToLower(sf.Name)
-		if lc == "deleted_at" || lc == "deletedat" || nameLc == "deleted_at" || nameLc == "deletedat" {
-			deletedCol = col
-			break
-		}
+	// 软删除与数据权限条件
+	where, args, err := r.scopedWhere(ctx, "", nil)
+	if err != nil {
+		return 0, err
 	}
-
-	if deletedCol == "" {
-		return 0, errors.New("soft delete not supported: deleted_at field not found on entity")
+	if where == "" {
+		where = "1"
 	}
 
-	aSql := fmt.Sprintf("ALTER TABLE %s UPDATE %s = now() WHERE 1", r.table, deletedCol)
-	if err := r.client.conn.Exec(ctx, aSql); err != nil {
+	aSql := fmt.Sprintf("ALTER TABLE %s UPDATE %s WHERE %s", r.table, set, where)
+	if err = r.client.conn.Exec(ctx, aSql, append(setVals, args...)...); err != nil {
 		r.log.Errorf("soft delete (update deleted_at) failed: %v", err)
 		return 0, errors.New("delete failed")
 	}
@@ -1417,16 +1528,10 @@ func (r *Repository[DTO, ENTITY]) Exists(ctx context.Context, baseWhere string,
 		return false, errors.New("table is empty")
 	}
 
-	// 展开单个切片参数为独立参数
-	if len(whereArgs) == 1 {
-		v := reflect.ValueOf(whereArgs[0])
-		if v.IsValid() && v.Kind() == reflect.Slice {
-			expanded := make([]any, v.Len())
-			for i := 0; i < v.Len(); i++ {
-				expanded[i] = v.Index(i).Interface()
-			}
-			whereArgs = expanded
-		}
+	// 追加数据权限条件（同时展开单个切片参数）
+	baseWhere, whereArgs, err := r.scopedWhere(ctx, baseWhere, whereArgs)
+	if err != nil {
+		return false, err
 	}
 
 	sqlStr := fmt.Sprintf("SELECT 1 FROM %s", r.table)
@@ -1442,7 +1547,7 @@ func (r *Repository[DTO, ENTITY]) Exists(ctx context.Context, baseWhere string,
 
 	row := r.client.conn.QueryRow(ctx, sqlStr, whereArgs...)
 	var dummy uint8
-	if err := row.Scan(&dummy); err != nil {
+	if err = row.Scan(&dummy); err != nil {
 		// 没有行时部分驱动返回 sql.ErrNoRows
 		if errors.Is(err, sql.ErrNoRows) {
 			return false, nil
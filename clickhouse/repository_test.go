@@ -12,7 +12,11 @@ import (
 	"github.com/tx7do/go-utils/trans"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
+	crud "github.com/tx7do/go-crud"
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination/datascope"
+	"github.com/tx7do/go-crud/pagination/softdelete"
+	"github.com/tx7do/go-crud/viewer"
 )
 
 // 为测试定义简单实体类型（没有 deleted_at 字段）
@@ -258,3 +262,157 @@ func TestRepository_Candle_ListWithPaging(t *testing.T) {
 	}
 	assert.Equal(t, 10.5, *first.Close)
 }
+
+func TestRepository_MutationByFilter_Guards(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewHelper(log.DefaultLogger)
+
+	type Row struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+		Age  int    `db:"age"`
+	}
+	repo := NewRepository[Row, Row](&Client{}, mapper.NewCopierMapper[Row, Row](), "rows", logger)
+
+	if _, err := repo.DeleteByFilter(ctx, nil, true); !errors.Is(err, crud.ErrEmptyFilter) {
+		t.Fatalf("expected ErrEmptyFilter, got %v", err)
+	}
+	if _, err := repo.UpdateByFilter(ctx, &paginationV1.FilterExpr{Type: paginationV1.ExprType_AND}, &Row{Name: "x"}, nil); !errors.Is(err, crud.ErrEmptyFilter) {
+		t.Fatalf("expected ErrEmptyFilter, got %v", err)
+	}
+	if _, err := repo.DeleteByFilter(ctx, nil, false, WithAllowEmptyFilter()); err == nil || err.Error() != "soft delete not supported: deleted_at field not found on entity" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	where, args, err := repo.mutationWhere(nil, newMutationOptions([]MutationOption{WithAllowEmptyFilter()}))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", where)
+	assert.Empty(t, args)
+
+	expr := &paginationV1.FilterExpr{
+		Type: paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{
+			{Field: "name", Op: paginationV1.Operator_EQ, ValueOneof: &paginationV1.FilterCondition_Value{Value: "tom"}},
+		},
+	}
+	where, args, err = repo.mutationWhere(expr, newMutationOptions(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "name = ?", where)
+	assert.Equal(t, []any{"tom"}, args)
+
+	setExprs, setVals, err := repo.updateAssignments(&Row{ID: 1, Name: "jerry"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name = ?"}, setExprs)
+	assert.Equal(t, []any{"jerry"}, setVals)
+
+	setExprs, setVals, err = repo.updateAssignments(&Row{ID: 1, Name: "jerry"}, &fieldmaskpb.FieldMask{Paths: []string{"age"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"age = ?"}, setExprs)
+	assert.Equal(t, []any{0}, setVals)
+}
+
+// scopeViewer 指定数据权限范围的 Viewer
+type scopeViewer struct {
+	viewer.Context
+	uid    uint64
+	scopes []viewer.DataScope
+}
+
+func (v scopeViewer) UserID() uint64                { return v.uid }
+func (v scopeViewer) DataScope() []viewer.DataScope { return v.scopes }
+
+func TestRepository_DataScope_Where(t *testing.T) {
+	logger := log.NewHelper(log.DefaultLogger)
+
+	type Row struct {
+		ID        int    `db:"id"`
+		Name      string `db:"name"`
+		OwnerID   uint64 `db:"owner_id"`
+		OrgUnitID uint64 `db:"org_unit_id"`
+	}
+	repo := NewRepository[Row, Row](&Client{}, mapper.NewCopierMapper[Row, Row](), "rows", logger).
+		WithDataScope(datascope.NewPolicy().WithOwnerColumn("owner_id"))
+
+	ctx := viewer.WithContext(context.Background(), scopeViewer{
+		Context: viewer.NewNoopContext(),
+		uid:     7,
+		scopes: []viewer.DataScope{
+			{ScopeType: viewer.ScopeTypeSelf},
+			{ScopeType: viewer.ScopeTypeUnit, TargetIDs: []uint64{3, 4}},
+		},
+	})
+
+	where, args, err := repo.scopedWhere(ctx, "WHERE name = ?", []any{"tom"})
+	assert.NoError(t, err)
+	assert.Equal(t, "(name = ?) AND ((owner_id IN (?) OR org_unit_id IN (?,?)))", where)
+	assert.Len(t, args, 4)
+	assert.Equal(t, "tom", args[0])
+
+	where, args, err = repo.scopedWhere(ctx, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "(owner_id IN (?) OR org_unit_id IN (?,?))", where)
+	assert.Len(t, args, 3)
+
+	// 缺少 Viewer 时拒绝访问，未设置策略时原样返回
+	_, _, err = repo.scopedWhere(context.Background(), "id = ?", []any{1})
+	assert.ErrorIs(t, err, datascope.ErrMissingViewer)
+
+	repo.WithDataScope(nil)
+	where, args, err = repo.scopedWhere(context.Background(), "id IN (?)", []any{[]int{1, 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, "id IN (?)", where)
+	assert.Equal(t, []any{1, 2}, args)
+}
+
+func TestRepository_SoftDelete_Where(t *testing.T) {
+	logger := log.NewHelper(log.DefaultLogger)
+
+	type Row struct {
+		ID        int        `db:"id"`
+		Name      string     `db:"name"`
+		DeletedAt *time.Time `db:"deleted_at"`
+		DeletedBy *uint64    `db:"deleted_by"`
+	}
+	repo := NewRepository[Row, Row](&Client{}, mapper.NewCopierMapper[Row, Row](), "rows", logger)
+	ctx := viewer.WithContext(context.Background(), scopeViewer{Context: viewer.NewNoopContext(), uid: 7})
+
+	// 默认排除已软删除的记录
+	where, args, err := repo.scopedWhere(ctx, "WHERE name = ?", []any{"tom"})
+	assert.NoError(t, err)
+	assert.Equal(t, "(name = ?) AND (deleted_at IS NULL)", where)
+	assert.Equal(t, []any{"tom"}, args)
+
+	where, _, err = repo.scopedWhere(softdelete.WithDeleted(ctx), "name = ?", []any{"tom"})
+	assert.NoError(t, err)
+	assert.Equal(t, "name = ?", where)
+
+	where, _, err = repo.scopedWhere(softdelete.OnlyDeleted(ctx), "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted_at IS NOT NULL", where)
+
+	// 包含 deleted_at 字段时默认软删除
+	assert.False(t, repo.isHardDelete(ctx))
+	assert.True(t, repo.isHardDelete(softdelete.HardDelete(ctx)))
+	type PlainRow struct {
+		ID int `db:"id"`
+	}
+	assert.True(t, NewRepository[PlainRow, PlainRow](&Client{}, mapper.NewCopierMapper[PlainRow, PlainRow](), "plain_rows", logger).isHardDelete(ctx))
+
+	// 软删除写入删除者
+	set, setVals, err := repo.softDeleteAssignments(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted_at = now(), deleted_by = ?", set)
+	assert.Equal(t, []any{uint64(7)}, setVals)
+
+	set, setVals, err = repo.softDeleteAssignments(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted_at = now()", set)
+	assert.Empty(t, setVals)
+
+	// 清理条件只作用于删除时间早于截止时间的已软删除记录
+	where, args, err = repo.purgeWhere(ctx, "deleted_at", nil, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, "(deleted_at < ?) AND (deleted_at IS NOT NULL)", where)
+	assert.Len(t, args, 1)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), args[0].(time.Time), time.Minute)
+}
@@ -0,0 +1,164 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/clickhouse/query"
+	"github.com/tx7do/go-crud/pagination/softdelete"
+)
+
+// entityColumnOf 按列名或字段名（忽略大小写与下划线）查找实体中的列，未找到时返回空
+func (r *Repository[DTO, ENTITY]) entityColumnOf(name string) string {
+	t := reflect.TypeOf((*ENTITY)(nil)).Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+
+	want := strings.ReplaceAll(strings.ToLower(name), "_", "")
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		col := entityColumn(sf)
+		if strings.ReplaceAll(strings.ToLower(col), "_", "") == want ||
+			strings.ReplaceAll(strings.ToLower(sf.Name), "_", "") == want {
+			return col
+		}
+	}
+	return ""
+}
+
+// softDeleteCondition 返回 ctx 中软删除模式对应的条件；实体不包含 deleted_at 字段或模式为 WithDeleted 时返回空。
+// deleted_at 应为 Nullable 列，NULL 表示未删除。
+func (r *Repository[DTO, ENTITY]) softDeleteCondition(ctx context.Context) string {
+	col, err := r.deletedAtColumn()
+	if err != nil {
+		return ""
+	}
+
+	switch softdelete.ModeFromContext(ctx) {
+	case softdelete.ModeWithDeleted:
+		return ""
+	case softdelete.ModeOnlyDeleted:
+		return col + " IS NOT NULL"
+	default:
+		return col + " IS NULL"
+	}
+}
+
+// isHardDelete 实体不包含 deleted_at 字段，或 ctx 经 softdelete.HardDelete 标记时执行物理删除
+func (r *Repository[DTO, ENTITY]) isHardDelete(ctx context.Context) bool {
+	if softdelete.IsHardDelete(ctx) {
+		return true
+	}
+	_, err := r.deletedAtColumn()
+	return err != nil
+}
+
+// applyScopes 将软删除与数据权限条件追加到查询构建器
+func (r *Repository[DTO, ENTITY]) applyScopes(ctx context.Context, qb *query.Builder) error {
+	if cond := r.softDeleteCondition(ctx); cond != "" {
+		qb.Where(cond)
+	}
+	return r.applyDataScope(ctx, qb)
+}
+
+// softDeleteAssignments 返回软删除的赋值表达式：deleted_at = now()，实体包含 deleted_by 字段且 ctx 中存在 Viewer 时同时写入删除者
+func (r *Repository[DTO, ENTITY]) softDeleteAssignments(ctx context.Context) (string, []any, error) {
+	deletedCol, err := r.deletedAtColumn()
+	if err != nil {
+		return "", nil, err
+	}
+
+	set := deletedCol + " = now()"
+	var args []any
+	if byCol := r.entityColumnOf(softdelete.DefaultDeletedByColumn); byCol != "" {
+		if uid, ok := softdelete.DeletedBy(ctx); ok {
+			set += ", " + byCol + " = ?"
+			args = append(args, uid)
+		}
+	}
+	return set, args, nil
+}
+
+// Restore 恢复符合 FilterExpr 的已软删除记录（ALTER TABLE ... UPDATE deleted_at = NULL ...），返回提交前统计的匹配行数；
+// 过滤条件为空时恢复全部已软删除的记录。deleted_at 与 deleted_by 需为 Nullable 列。
+func (r *Repository[DTO, ENTITY]) Restore(ctx context.Context, expr *paginationV1.FilterExpr, opts ...MutationOption) (int64, error) {
+	if r.client == nil {
+		return 0, errors.New("clickhouse client is nil")
+	}
+	if r.table == "" {
+		return 0, errors.New("table is empty")
+	}
+
+	deletedCol, err := r.deletedAtColumn()
+	if err != nil {
+		return 0, err
+	}
+
+	where, args, err := r.buildFilterWhere(expr)
+	if err != nil {
+		return 0, err
+	}
+	if where, args, err = r.scopedWhere(softdelete.OnlyDeleted(ctx), where, args); err != nil {
+		return 0, err
+	}
+
+	set := deletedCol + " = NULL"
+	if byCol := r.entityColumnOf(softdelete.DefaultDeletedByColumn); byCol != "" {
+		set += ", " + byCol + " = NULL"
+	}
+	aSql := fmt.Sprintf("ALTER TABLE %s UPDATE %s WHERE %s", r.table, set, where)
+
+	return r.execMutation(ctx, newMutationOptions(opts), aSql, where, nil, args)
+}
+
+// Purge 物理删除符合 FilterExpr、且删除时间早于 olderThan 之前的已软删除记录（DELETE FROM ... WHERE ...），
+// olderThan <= 0 时清理全部已软删除记录；返回提交前统计的匹配行数。
+func (r *Repository[DTO, ENTITY]) Purge(ctx context.Context, expr *paginationV1.FilterExpr, olderThan time.Duration, opts ...MutationOption) (int64, error) {
+	if r.client == nil {
+		return 0, errors.New("clickhouse client is nil")
+	}
+	if r.table == "" {
+		return 0, errors.New("table is empty")
+	}
+
+	deletedCol, err := r.deletedAtColumn()
+	if err != nil {
+		return 0, err
+	}
+
+	where, args, err := r.purgeWhere(ctx, deletedCol, expr, olderThan)
+	if err != nil {
+		return 0, err
+	}
+	aSql := fmt.Sprintf("DELETE FROM %s WHERE %s", r.table, where)
+
+	return r.execMutation(ctx, newMutationOptions(opts), aSql, where, nil, args)
+}
+
+// purgeWhere 编译清理条件：过滤条件、删除时间早于截止时间，以及仅已软删除与数据权限条件
+func (r *Repository[DTO, ENTITY]) purgeWhere(ctx context.Context, deletedCol string, expr *paginationV1.FilterExpr, olderThan time.Duration) (string, []any, error) {
+	where, args, err := r.buildFilterWhere(expr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	cutoff := deletedCol + " < ?"
+	if strings.TrimSpace(where) != "" {
+		cutoff = "(" + where + ") AND " + cutoff
+	}
+	args = append(args, softdelete.Cutoff(olderThan))
+
+	return r.scopedWhere(softdelete.OnlyDeleted(ctx), cutoff, args)
+}
@@ -3,20 +3,55 @@ package sorting
 import (
 	"strings"
 
+	"github.com/go-kratos/kratos/v2/log"
+
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
 	"github.com/tx7do/go-crud/clickhouse/query"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
 )
 
 // StructuredSorting 将结构化排序指令转换为 ClickHouse 的 ORDER BY 子句
-type StructuredSorting struct{}
+type StructuredSorting struct {
+	policy  *paginationFilter.FilterPolicy
+	mapping *fieldmap.Mapping
+}
 
 // NewStructuredSorting 创建实例
 func NewStructuredSorting() *StructuredSorting {
 	return &StructuredSorting{}
 }
 
-// BuildOrderClause 根据传入的排序指令构造 ORDER BY 子句
+// WithPolicy 设置字段策略，排序字段需通过策略校验，字段按策略映射为列名
+func (ss *StructuredSorting) WithPolicy(policy *paginationFilter.FilterPolicy) *StructuredSorting {
+	ss.policy = policy
+	return ss
+}
+
+// WithFieldMapping 设置字段映射，排序字段按映射替换为列名
+func (ss *StructuredSorting) WithFieldMapping(mapping *fieldmap.Mapping) *StructuredSorting {
+	ss.mapping = mapping
+	return ss
+}
+
+// Validate 按字段策略校验排序字段，返回按策略与字段映射替换为列名后的排序；未设置策略与映射时原样返回
+func (ss StructuredSorting) Validate(orders []*paginationV1.Sorting) ([]*paginationV1.Sorting, error) {
+	orders, err := ss.policy.CheckSorting(orders)
+	return ss.mapping.Sorting(orders), err
+}
+
+// BuildOrderClause 根据传入的排序指令构造 ORDER BY 子句。
+// 设置了字段策略时，违反策略的排序字段会被跳过，可先通过 Validate 获取违规详情。
 func (ss StructuredSorting) BuildOrderClause(builder *query.Builder, orders []*paginationV1.Sorting) *query.Builder {
+	orders, err := ss.Validate(orders)
+	if err != nil {
+		log.Warnf("skip sorting fields violating filter policy: %v", err)
+	}
+	return ss.buildOrderClause(builder, orders)
+}
+
+// buildOrderClause 构造排序子句，不做字段策略校验
+func (ss StructuredSorting) buildOrderClause(builder *query.Builder, orders []*paginationV1.Sorting) *query.Builder {
 	if len(orders) == 0 {
 		return builder
 	}
@@ -48,7 +83,8 @@ func (ss StructuredSorting) BuildOrderClauseWithDefaultField(builder *query.Buil
 		if !defaultDesc {
 			order = paginationV1.Sorting_ASC
 		}
-		return ss.BuildOrderClause(builder, []*paginationV1.Sorting{
+		// 默认排序字段由服务端指定，不受字段策略约束
+		return ss.buildOrderClause(builder, []*paginationV1.Sorting{
 			{
 				Field:     defaultOrderField,
 				Direction: order,
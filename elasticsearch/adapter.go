@@ -0,0 +1,79 @@
+package elasticsearch
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+var _ crud.Repository[struct{}] = (*RepositoryAdapter[struct{}, struct{}])(nil)
+
+// RepositoryAdapter 将 Elasticsearch Repository 适配为通用的 crud.Repository 接口。
+// 按过滤条件更新、删除暂不支持；Upsert 按文档 ID 整体覆盖写入，忽略 updateMask。
+type RepositoryAdapter[DTO any, ENTITY any] struct {
+	repo *Repository[DTO, ENTITY]
+	idOf func(dto *DTO) string
+}
+
+// NewRepositoryAdapter 创建 Elasticsearch 仓库适配器，idOf 用于从 DTO 中取得文档 ID，为 nil 时由 Elasticsearch 自动生成
+func NewRepositoryAdapter[DTO any, ENTITY any](repo *Repository[DTO, ENTITY], idOf func(dto *DTO) string) *RepositoryAdapter[DTO, ENTITY] {
+	return &RepositoryAdapter[DTO, ENTITY]{
+		repo: repo,
+		idOf: idOf,
+	}
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) documentID(dto *DTO) string {
+	if a.idOf == nil || dto == nil {
+		return ""
+	}
+	return a.idOf(dto)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) List(ctx context.Context, req *paginationV1.PaginationRequest) (*crud.PagingResult[DTO], error) {
+	return a.repo.ListWithPagination(ctx, req)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Get(ctx context.Context, filter *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	dto, err := a.repo.Get(ctx, filter, viewMask)
+	if err != nil {
+		return nil, err
+	}
+	if dto == nil {
+		return nil, crud.ErrNotFound
+	}
+	return dto, nil
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Create(ctx context.Context, dto *DTO, _ *fieldmaskpb.FieldMask) (*DTO, error) {
+	return a.repo.Create(ctx, a.documentID(dto), dto)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Update(_ context.Context, filter *paginationV1.FilterExpr, _ *DTO, _ *fieldmaskpb.FieldMask) (*DTO, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return nil, err
+	}
+	return nil, crud.ErrNotSupported
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Upsert(ctx context.Context, dto *DTO, _ *fieldmaskpb.FieldMask) (*DTO, error) {
+	return a.repo.Create(ctx, a.documentID(dto), dto)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Delete(_ context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return 0, err
+	}
+	return 0, crud.ErrNotSupported
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Count(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	return a.repo.Count(ctx, filter)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Exists(ctx context.Context, filter *paginationV1.FilterExpr) (bool, error) {
+	return a.repo.Exists(ctx, filter)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"strings"
 
 	"github.com/go-kratos/kratos/v2/log"
 
@@ -359,17 +360,125 @@ func (c *Client) Search(
 	indexName string,
 	req *paginationV1.PagingRequest,
 ) (*SearchResult, error) {
-	var query string
-	ParseQueryString(req.GetQuery())
+	query := strings.Join(ParseQueryString(req.GetQuery()), " AND ")
 
 	sortBy := make(map[string]bool)
+	for _, s := range req.GetSorting() {
+		if s == nil || s.GetField() == "" {
+			continue
+		}
+		sortBy[s.GetField()] = s.GetDirection() != paginationV1.Sorting_DESC
+	}
 
 	pageSize := req.GetPageSize()
 	if pageSize <= 0 {
 		pageSize = 20 // Default page size
 	}
 
-	return c.search(ctx, indexName, query, nil, sortBy, int(req.GetPage()), int(pageSize))
+	page := req.GetPage()
+	if page < 1 {
+		page = 1
+	}
+
+	return c.search(ctx, indexName, query, nil, sortBy, int((page-1)*pageSize), int(pageSize))
+}
+
+// SearchWithBody 使用 Query DSL 请求体查询数据
+func (c *Client) SearchWithBody(ctx context.Context, indexName string, body map[string]any) (*SearchResult, error) {
+	if c.Client == nil {
+		return nil, ErrClientNotInitialized
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.log.Errorf("failed to marshal search body: %v", err)
+		return nil, ErrInvalidQuery
+	}
+
+	resp, err := c.Client.Search(
+		c.Client.Search.WithContext(ctx),
+		c.Client.Search.WithIndex(indexName),
+		c.Client.Search.WithBody(bytes.NewReader(data)),
+	)
+	if err != nil {
+		c.log.Errorf("failed to search documents: %v", err)
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			c.log.Errorf("failed to close response body: %v", err)
+		}
+	}(resp.Body)
+
+	if resp.IsError() {
+		var errResp *ErrorResponse
+		if errResp, err = ParseErrorMessage(resp.Body); err != nil {
+			return nil, err
+		}
+
+		c.log.Errorf("search document failed: %s", errResp.Error.Reason)
+
+		return nil, ErrSearchDocument
+	}
+
+	// 使用 json.Number 保留 sort 中 long 类型值的精度（用于 search_after）
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+
+	var searchResult SearchResult
+	if err = dec.Decode(&searchResult); err != nil {
+		c.log.Errorf("failed to decode search result: %v", err)
+		return nil, ErrUnmarshalResponse
+	}
+
+	return &searchResult, nil
+}
+
+// Count 使用 Query DSL 请求体统计文档数量
+func (c *Client) Count(ctx context.Context, indexName string, body map[string]any) (int64, error) {
+	if c.Client == nil {
+		return 0, ErrClientNotInitialized
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.log.Errorf("failed to marshal count body: %v", err)
+		return 0, ErrInvalidQuery
+	}
+
+	resp, err := c.Client.Count(
+		c.Client.Count.WithContext(ctx),
+		c.Client.Count.WithIndex(indexName),
+		c.Client.Count.WithBody(bytes.NewReader(data)),
+	)
+	if err != nil {
+		c.log.Errorf("failed to count documents: %v", err)
+		return 0, err
+	}
+	defer func(Body io.ReadCloser) {
+		if err := Body.Close(); err != nil {
+			c.log.Errorf("failed to close response body: %v", err)
+		}
+	}(resp.Body)
+
+	if resp.IsError() {
+		var errResp *ErrorResponse
+		if errResp, err = ParseErrorMessage(resp.Body); err != nil {
+			return 0, err
+		}
+
+		c.log.Errorf("count document failed: %s", errResp.Error.Reason)
+
+		return 0, ErrCountDocument
+	}
+
+	var countResult CountResult
+	if err = json.NewDecoder(resp.Body).Decode(&countResult); err != nil {
+		c.log.Errorf("failed to decode count result: %v", err)
+		return 0, ErrUnmarshalResponse
+	}
+
+	return countResult.Count, nil
 }
 
 // search 查询数据
@@ -35,4 +35,9 @@ var (
 	ErrGetDocument = errors.InternalServer("GET_DOCUMENT_FAILED", "failed to get document")
 
 	ErrSearchDocument = errors.InternalServer("SEARCH_DOCUMENT_FAILED", "failed to search document")
+
+	ErrCountDocument = errors.InternalServer("COUNT_DOCUMENT_FAILED", "failed to count document")
+
+	// ErrClientNotInitialized is returned when the Elasticsearch client is not initialized.
+	ErrClientNotInitialized = errors.InternalServer("CLIENT_NOT_INITIALIZED", "elasticsearch client not initialized")
 )
@@ -0,0 +1,40 @@
+package field
+
+import (
+	"github.com/tx7do/go-crud/elasticsearch/query"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+)
+
+// Selector 字段选择器，用于构建 Elasticsearch 查询中的 _source 过滤。
+type Selector struct {
+	mapping *fieldmap.Mapping
+}
+
+// NewFieldSelector 返回一个新的 Selector。
+func NewFieldSelector() *Selector { return &Selector{} }
+
+// WithFieldMapping 设置字段映射，字段路径按映射替换为列名。
+func (fs *Selector) WithFieldMapping(mapping *fieldmap.Mapping) *Selector {
+	fs.mapping = mapping
+	return fs
+}
+
+// BuildSelector 将 fields 设置为 builder 的 _source 字段。
+// 当 fields 为空或无有效字段时 builder 保持不变（即返回完整 _source）。
+func (fs Selector) BuildSelector(builder *query.Builder, fields []string) (*query.Builder, error) {
+	if builder == nil {
+		return nil, nil
+	}
+	if len(fields) == 0 {
+		return builder, nil
+	}
+
+	fields = NormalizePaths(fs.mapping.Paths(fields))
+	if len(fields) == 0 {
+		return builder, nil
+	}
+
+	builder.Source(fields...)
+
+	return builder, nil
+}
@@ -0,0 +1,39 @@
+package field
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tx7do/go-utils/stringcase"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+var fieldNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*\*?$`)
+
+// NormalizeFieldMaskPaths normalizes the paths in the given FieldMask to snake_case
+func NormalizeFieldMaskPaths(fm *fieldmaskpb.FieldMask) {
+	if fm == nil || len(fm.GetPaths()) == 0 {
+		return
+	}
+
+	fm.Normalize()
+
+	fm.Paths = NormalizePaths(fm.Paths)
+}
+
+// NormalizePaths 将字段路径的第一段标准化为 snake_case（子字段保持原样），丢弃非法路径。
+func NormalizePaths(fields []string) []string {
+	res := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if f == "" || !fieldNameRegexp.MatchString(f) {
+			continue
+		}
+		if idx := strings.Index(f, "."); idx > 0 {
+			res = append(res, stringcase.ToSnakeCase(f[:idx])+f[idx:])
+		} else {
+			res = append(res, stringcase.ToSnakeCase(f))
+		}
+	}
+	return res
+}
@@ -0,0 +1,277 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/json"
+	"github.com/tx7do/go-utils/stringcase"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+// Processor 将单个 FilterCondition 转换为 Elasticsearch Query DSL 子句
+type Processor struct {
+	codec encoding.Codec
+}
+
+func NewProcessor() *Processor {
+	return &Processor{
+		codec: encoding.GetCodec("json"),
+	}
+}
+
+// Process 根据 operator 生成对应的 Query DSL 子句。
+// field 为字段路径（可包含点号），value 为单值，values 为多值（如 IN/BETWEEN）。
+func (poc Processor) Process(op paginationV1.Operator, field, value string, values []string) (map[string]any, error) {
+	key, err := poc.makeKey(field)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case paginationV1.Operator_EQ, paginationV1.Operator_EXACT:
+		return poc.Term(key, value, false), nil
+	case paginationV1.Operator_IEXACT:
+		return poc.Term(key, value, true), nil
+	case paginationV1.Operator_NEQ:
+		return mustNot(poc.Term(key, value, false)), nil
+	case paginationV1.Operator_GT:
+		return poc.Range(key, map[string]any{"gt": value}), nil
+	case paginationV1.Operator_GTE:
+		return poc.Range(key, map[string]any{"gte": value}), nil
+	case paginationV1.Operator_LT:
+		return poc.Range(key, map[string]any{"lt": value}), nil
+	case paginationV1.Operator_LTE:
+		return poc.Range(key, map[string]any{"lte": value}), nil
+	case paginationV1.Operator_BETWEEN:
+		return poc.Between(key, value, values)
+	case paginationV1.Operator_IN:
+		return poc.Terms(key, value, values)
+	case paginationV1.Operator_NIN:
+		q, err := poc.Terms(key, value, values)
+		if err != nil {
+			return nil, err
+		}
+		return mustNot(q), nil
+	case paginationV1.Operator_IS_NULL:
+		return mustNot(poc.Exists(key)), nil
+	case paginationV1.Operator_IS_NOT_NULL, paginationV1.Operator_EXISTS:
+		return poc.Exists(key), nil
+	case paginationV1.Operator_LIKE:
+		return poc.Wildcard(key, likeToWildcard(value), false), nil
+	case paginationV1.Operator_ILIKE:
+		return poc.Wildcard(key, likeToWildcard(value), true), nil
+	case paginationV1.Operator_NOT_LIKE:
+		return mustNot(poc.Wildcard(key, likeToWildcard(value), false)), nil
+	case paginationV1.Operator_CONTAINS:
+		return poc.Wildcard(key, "*"+escapeWildcard(value)+"*", false), nil
+	case paginationV1.Operator_ICONTAINS:
+		return poc.Wildcard(key, "*"+escapeWildcard(value)+"*", true), nil
+	case paginationV1.Operator_STARTS_WITH:
+		return poc.Prefix(key, value, false), nil
+	case paginationV1.Operator_ISTARTS_WITH:
+		return poc.Prefix(key, value, true), nil
+	case paginationV1.Operator_ENDS_WITH:
+		return poc.Wildcard(key, "*"+escapeWildcard(value), false), nil
+	case paginationV1.Operator_IENDS_WITH:
+		return poc.Wildcard(key, "*"+escapeWildcard(value), true), nil
+	case paginationV1.Operator_REGEXP:
+		return poc.Regexp(key, value, false), nil
+	case paginationV1.Operator_IREGEXP:
+		return poc.Regexp(key, value, true), nil
+	case paginationV1.Operator_SEARCH:
+		return poc.Match(key, value), nil
+	case paginationV1.Operator_ARRAY_CONTAINS:
+		return poc.ArrayContains(key, value, values), nil
+	case paginationV1.Operator_JSON_CONTAINS:
+		return poc.JsonContains(key, value)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedOperator, op.String())
+	}
+}
+
+// makeKey 校验字段名并将第一段转换为 snake_case，其余子路径保持原样
+func (poc Processor) makeKey(field string) (string, error) {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return "", ErrInvalidField
+	}
+	if !fieldNameRegexp.MatchString(field) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidField, field)
+	}
+
+	if idx := strings.Index(field, "."); idx > 0 {
+		return stringcase.ToSnakeCase(field[:idx]) + field[idx:], nil
+	}
+	return stringcase.ToSnakeCase(field), nil
+}
+
+// Term 精确匹配：{"term": {key: {"value": v}}}
+func (poc Processor) Term(key, value string, caseInsensitive bool) map[string]any {
+	body := map[string]any{"value": value}
+	if caseInsensitive {
+		body["case_insensitive"] = true
+	}
+	return map[string]any{"term": map[string]any{key: body}}
+}
+
+// Terms 多值匹配：{"terms": {key: [...]}}，values 为空时按 JSON 数组或逗号分隔解析 value
+func (poc Processor) Terms(key, value string, values []string) (map[string]any, error) {
+	items := poc.splitValues(value, values)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: %s: empty IN list", ErrInvalidValue, key)
+	}
+	return map[string]any{"terms": map[string]any{key: items}}, nil
+}
+
+// Range 范围查询：{"range": {key: {...}}}
+func (poc Processor) Range(key string, bounds map[string]any) map[string]any {
+	return map[string]any{"range": map[string]any{key: bounds}}
+}
+
+// Between 生成 gte/lte 的范围查询
+func (poc Processor) Between(key, value string, values []string) (map[string]any, error) {
+	bounds := poc.splitValues(value, values)
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("%w: %s: BETWEEN requires two values", ErrInvalidValue, key)
+	}
+	return poc.Range(key, map[string]any{"gte": bounds[0], "lte": bounds[1]}), nil
+}
+
+// Exists 字段存在：{"exists": {"field": key}}
+func (poc Processor) Exists(key string) map[string]any {
+	return map[string]any{"exists": map[string]any{"field": key}}
+}
+
+// Wildcard 通配符匹配（* 与 ?）
+func (poc Processor) Wildcard(key, pattern string, caseInsensitive bool) map[string]any {
+	body := map[string]any{"value": pattern}
+	if caseInsensitive {
+		body["case_insensitive"] = true
+	}
+	return map[string]any{"wildcard": map[string]any{key: body}}
+}
+
+// Prefix 前缀匹配
+func (poc Processor) Prefix(key, value string, caseInsensitive bool) map[string]any {
+	body := map[string]any{"value": value}
+	if caseInsensitive {
+		body["case_insensitive"] = true
+	}
+	return map[string]any{"prefix": map[string]any{key: body}}
+}
+
+// Regexp 正则匹配（Lucene 正则语法）
+func (poc Processor) Regexp(key, pattern string, caseInsensitive bool) map[string]any {
+	body := map[string]any{"value": pattern}
+	if caseInsensitive {
+		body["case_insensitive"] = true
+	}
+	return map[string]any{"regexp": map[string]any{key: body}}
+}
+
+// Match 全文检索
+func (poc Processor) Match(key, value string) map[string]any {
+	return map[string]any{"match": map[string]any{key: map[string]any{"query": value}}}
+}
+
+// ArrayContains 数组字段包含元素；多值时要求全部包含
+func (poc Processor) ArrayContains(key, value string, values []string) map[string]any {
+	items := values
+	if len(items) == 0 {
+		return poc.Term(key, value, false)
+	}
+	if len(items) == 1 {
+		return poc.Term(key, items[0], false)
+	}
+
+	must := make([]any, 0, len(items))
+	for _, item := range items {
+		must = append(must, poc.Term(key, item, false))
+	}
+	return map[string]any{"bool": map[string]any{"must": must}}
+}
+
+// JsonContains 对象字段包含给定的键值：value 为 JSON 对象时逐个生成 key.sub 的 term 条件，否则退化为 term
+func (poc Processor) JsonContains(key, value string) (map[string]any, error) {
+	var obj map[string]any
+	if err := poc.codec.Unmarshal([]byte(value), &obj); err != nil {
+		return poc.Term(key, value, false), nil
+	}
+	if len(obj) == 0 {
+		return nil, fmt.Errorf("%w: %s: empty JSON object", ErrInvalidValue, key)
+	}
+
+	must := make([]any, 0, len(obj))
+	for k, v := range obj {
+		if !jsonKeyRegexp.MatchString(k) {
+			return nil, fmt.Errorf("%w: %s.%s", ErrInvalidField, key, k)
+		}
+		must = append(must, map[string]any{"term": map[string]any{key + "." + k: map[string]any{"value": v}}})
+	}
+	return map[string]any{"bool": map[string]any{"must": must}}, nil
+}
+
+// splitValues 多值参数：优先使用 values，否则将 value 解析为 JSON 数组或按逗号分隔
+func (poc Processor) splitValues(value string, values []string) []any {
+	out := make([]any, 0, len(values))
+	if len(values) > 0 {
+		for _, v := range values {
+			out = append(out, v)
+		}
+		return out
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	var arr []any
+	if err := poc.codec.Unmarshal([]byte(value), &arr); err == nil {
+		return arr
+	}
+
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// mustNot 对子句取反
+func mustNot(q map[string]any) map[string]any {
+	return map[string]any{"bool": map[string]any{"must_not": []any{q}}}
+}
+
+// escapeWildcard 转义通配符查询中的特殊字符
+func escapeWildcard(s string) string {
+	return wildcardEscaper.Replace(s)
+}
+
+var wildcardEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`)
+
+// likeToWildcard 将 SQL LIKE 模式（% 与 _）转换为通配符模式（* 与 ?）
+func likeToWildcard(s string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			sb.WriteString(escapeWildcard(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			sb.WriteRune('*')
+		case r == '_':
+			sb.WriteRune('?')
+		default:
+			sb.WriteString(escapeWildcard(string(r)))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,150 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/elasticsearch/query"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
+)
+
+var (
+	// ErrUnsupportedOperator 不支持的操作符
+	ErrUnsupportedOperator = errors.New("unsupported operator")
+	// ErrInvalidField 非法的字段名
+	ErrInvalidField = errors.New("invalid field name")
+	// ErrInvalidValue 非法的过滤值
+	ErrInvalidValue = errors.New("invalid filter value")
+)
+
+// fieldNameRegexp 允许的字段名：以字母或下划线开头，后续允许字母数字下划线和点（点用于对象子字段）
+var fieldNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// jsonKeyRegexp JSON_CONTAINS 中允许的对象键
+var jsonKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+
+// StructuredFilter 将 FilterExpr 转为 Elasticsearch bool 查询并应用到 *query.Builder
+type StructuredFilter struct {
+	processor *Processor
+	policy    *paginationFilter.FilterPolicy
+	mapping   *fieldmap.Mapping
+}
+
+func NewStructuredFilter() *StructuredFilter {
+	return &StructuredFilter{
+		processor: NewProcessor(),
+	}
+}
+
+// WithPolicy 设置字段策略，过滤条件需通过策略校验，字段按策略映射为列名
+func (sf *StructuredFilter) WithPolicy(policy *paginationFilter.FilterPolicy) *StructuredFilter {
+	sf.policy = policy
+	return sf
+}
+
+// WithFieldMapping 设置字段映射，条件字段按映射替换为列名
+func (sf *StructuredFilter) WithFieldMapping(mapping *fieldmap.Mapping) *StructuredFilter {
+	sf.mapping = mapping
+	return sf
+}
+
+// BuildSelectors 将 expr 递归转换为 bool 查询（AND -> must，OR -> should）并通过 builder.Where 应用
+func (sf StructuredFilter) BuildSelectors(builder *query.Builder, expr *paginationV1.FilterExpr) (*query.Builder, error) {
+	if builder == nil {
+		return nil, fmt.Errorf("builder is nil")
+	}
+	if expr == nil {
+		return builder, nil
+	}
+
+	expr, err := sf.policy.CheckFilter(expr)
+	if err != nil {
+		return builder, err
+	}
+	expr = sf.mapping.Filter(expr)
+
+	q, err := sf.buildExpr(expr)
+	if err != nil {
+		return builder, err
+	}
+	if q != nil {
+		builder.Where(q)
+	}
+
+	return builder, nil
+}
+
+// BuildQuery 将 expr 转换为 Query DSL 子句，expr 为空时返回 nil
+func (sf StructuredFilter) BuildQuery(expr *paginationV1.FilterExpr) (map[string]any, error) {
+	expr, err := sf.policy.CheckFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	return sf.buildExpr(sf.mapping.Filter(expr))
+}
+
+func (sf StructuredFilter) buildExpr(expr *paginationV1.FilterExpr) (map[string]any, error) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	var occur string
+	switch expr.GetType() {
+	case paginationV1.ExprType_AND:
+		occur = "must"
+	case paginationV1.ExprType_OR:
+		occur = "should"
+	default:
+		return nil, nil
+	}
+
+	var parts []any
+	for _, cond := range expr.GetConditions() {
+		if cond == nil {
+			continue
+		}
+		q, err := sf.processor.Process(cond.GetOp(), cond.GetField(), conditionValue(cond), cond.GetValues())
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, q)
+	}
+	for _, g := range expr.GetGroups() {
+		sub, err := sf.buildExpr(g)
+		if err != nil {
+			return nil, err
+		}
+		if sub != nil {
+			parts = append(parts, sub)
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return nil, nil
+	case 1:
+		return parts[0].(map[string]any), nil
+	}
+
+	body := map[string]any{occur: parts}
+	if occur == "should" {
+		body["minimum_should_match"] = 1
+	}
+	return map[string]any{"bool": body}, nil
+}
+
+// conditionValue 读取条件的单值（支持 value 与 json_value）
+func conditionValue(cond *paginationV1.FilterCondition) string {
+	switch cond.GetValueOneof().(type) {
+	case *paginationV1.FilterCondition_Value:
+		return cond.GetValue()
+	case *paginationV1.FilterCondition_JsonValue:
+		return pagination.StructValueToString(cond.GetJsonValue())
+	default:
+		return ""
+	}
+}
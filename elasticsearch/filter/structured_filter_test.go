@@ -0,0 +1,187 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/elasticsearch/query"
+)
+
+func toJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	return string(b)
+}
+
+func cond(field string, op paginationV1.Operator, value string, values ...string) *paginationV1.FilterCondition {
+	return &paginationV1.FilterCondition{
+		Field:      field,
+		Op:         op,
+		ValueOneof: &paginationV1.FilterCondition_Value{Value: value},
+		Values:     values,
+	}
+}
+
+func TestStructuredFilter_NilAndUnspecified(t *testing.T) {
+	sf := NewStructuredFilter()
+
+	qb := query.NewQueryBuilder("users", nil)
+	got, err := sf.BuildSelectors(qb, nil)
+	assert.NoError(t, err)
+	assert.Same(t, qb, got)
+	assert.False(t, qb.HasConditions())
+
+	got, err = sf.BuildSelectors(qb, &paginationV1.FilterExpr{Type: paginationV1.ExprType_EXPR_TYPE_UNSPECIFIED})
+	assert.NoError(t, err)
+	assert.Same(t, qb, got)
+	assert.False(t, qb.HasConditions())
+
+	_, err = sf.BuildSelectors(nil, nil)
+	assert.Error(t, err)
+}
+
+func TestStructuredFilter_Operators(t *testing.T) {
+	sf := NewStructuredFilter()
+
+	cases := []struct {
+		name     string
+		cond     *paginationV1.FilterCondition
+		expected string
+	}{
+		{"EQ", cond("UserName", paginationV1.Operator_EQ, "alice"), `{"term":{"user_name":{"value":"alice"}}}`},
+		{"EXACT", cond("name", paginationV1.Operator_EXACT, "alice"), `{"term":{"name":{"value":"alice"}}}`},
+		{"IEXACT", cond("name", paginationV1.Operator_IEXACT, "Alice"), `{"term":{"name":{"value":"Alice","case_insensitive":true}}}`},
+		{"NEQ", cond("name", paginationV1.Operator_NEQ, "bob"), `{"bool":{"must_not":[{"term":{"name":{"value":"bob"}}}]}}`},
+		{"GT", cond("age", paginationV1.Operator_GT, "18"), `{"range":{"age":{"gt":"18"}}}`},
+		{"GTE", cond("age", paginationV1.Operator_GTE, "18"), `{"range":{"age":{"gte":"18"}}}`},
+		{"LT", cond("age", paginationV1.Operator_LT, "60"), `{"range":{"age":{"lt":"60"}}}`},
+		{"LTE", cond("age", paginationV1.Operator_LTE, "60"), `{"range":{"age":{"lte":"60"}}}`},
+		{"BETWEEN values", cond("age", paginationV1.Operator_BETWEEN, "", "18", "60"), `{"range":{"age":{"gte":"18","lte":"60"}}}`},
+		{"BETWEEN json", cond("age", paginationV1.Operator_BETWEEN, "[18,60]"), `{"range":{"age":{"gte":18,"lte":60}}}`},
+		{"IN values", cond("status", paginationV1.Operator_IN, "", "ON", "OFF"), `{"terms":{"status":["ON","OFF"]}}`},
+		{"IN csv", cond("status", paginationV1.Operator_IN, "ON, OFF"), `{"terms":{"status":["ON","OFF"]}}`},
+		{"NIN", cond("status", paginationV1.Operator_NIN, `["ON"]`), `{"bool":{"must_not":[{"terms":{"status":["ON"]}}]}}`},
+		{"IS_NULL", cond("deletedAt", paginationV1.Operator_IS_NULL, ""), `{"bool":{"must_not":[{"exists":{"field":"deleted_at"}}]}}`},
+		{"IS_NOT_NULL", cond("deletedAt", paginationV1.Operator_IS_NOT_NULL, ""), `{"exists":{"field":"deleted_at"}}`},
+		{"EXISTS", cond("tags", paginationV1.Operator_EXISTS, ""), `{"exists":{"field":"tags"}}`},
+		{"LIKE", cond("name", paginationV1.Operator_LIKE, "a%c_*"), `{"wildcard":{"name":{"value":"a*c?\\*"}}}`},
+		{"ILIKE", cond("name", paginationV1.Operator_ILIKE, "A%"), `{"wildcard":{"name":{"value":"A*","case_insensitive":true}}}`},
+		{"NOT_LIKE", cond("name", paginationV1.Operator_NOT_LIKE, "%x"), `{"bool":{"must_not":[{"wildcard":{"name":{"value":"*x"}}}]}}`},
+		{"CONTAINS", cond("name", paginationV1.Operator_CONTAINS, "li?"), `{"wildcard":{"name":{"value":"*li\\?*"}}}`},
+		{"ICONTAINS", cond("name", paginationV1.Operator_ICONTAINS, "LI"), `{"wildcard":{"name":{"value":"*LI*","case_insensitive":true}}}`},
+		{"STARTS_WITH", cond("name", paginationV1.Operator_STARTS_WITH, "al"), `{"prefix":{"name":{"value":"al"}}}`},
+		{"ISTARTS_WITH", cond("name", paginationV1.Operator_ISTARTS_WITH, "AL"), `{"prefix":{"name":{"value":"AL","case_insensitive":true}}}`},
+		{"ENDS_WITH", cond("name", paginationV1.Operator_ENDS_WITH, "ce"), `{"wildcard":{"name":{"value":"*ce"}}}`},
+		{"IENDS_WITH", cond("name", paginationV1.Operator_IENDS_WITH, "CE"), `{"wildcard":{"name":{"value":"*CE","case_insensitive":true}}}`},
+		{"REGEXP", cond("name", paginationV1.Operator_REGEXP, "a.*e"), `{"regexp":{"name":{"value":"a.*e"}}}`},
+		{"IREGEXP", cond("name", paginationV1.Operator_IREGEXP, "A.*E"), `{"regexp":{"name":{"value":"A.*E","case_insensitive":true}}}`},
+		{"SEARCH", cond("message", paginationV1.Operator_SEARCH, "hello world"), `{"match":{"message":{"query":"hello world"}}}`},
+		{"ARRAY_CONTAINS", cond("tags", paginationV1.Operator_ARRAY_CONTAINS, "go"), `{"term":{"tags":{"value":"go"}}}`},
+		{"ARRAY_CONTAINS multi", cond("tags", paginationV1.Operator_ARRAY_CONTAINS, "", "go", "es"), `{"bool":{"must":[{"term":{"tags":{"value":"go"}}},{"term":{"tags":{"value":"es"}}}]}}`},
+		{"JSON_CONTAINS", cond("profile", paginationV1.Operator_JSON_CONTAINS, `{"city":"Beijing"}`), `{"bool":{"must":[{"term":{"profile.city":{"value":"Beijing"}}}]}}`},
+		{"nested field", cond("Profile.city", paginationV1.Operator_EQ, "Beijing"), `{"term":{"profile.city":{"value":"Beijing"}}}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			qb := query.NewQueryBuilder("users", nil)
+			_, err := sf.BuildSelectors(qb, &paginationV1.FilterExpr{
+				Type:       paginationV1.ExprType_AND,
+				Conditions: []*paginationV1.FilterCondition{tc.cond},
+			})
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.expected, toJSON(t, qb.BuildQuery()))
+		})
+	}
+}
+
+func TestStructuredFilter_JsonValue(t *testing.T) {
+	sf := NewStructuredFilter()
+	qb := query.NewQueryBuilder("users", nil)
+
+	_, err := sf.BuildSelectors(qb, &paginationV1.FilterExpr{
+		Type: paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{
+			{Field: "age", Op: paginationV1.Operator_GTE, ValueOneof: &paginationV1.FilterCondition_JsonValue{JsonValue: structpb.NewNumberValue(18)}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"range":{"age":{"gte":"18"}}}`, toJSON(t, qb.BuildQuery()))
+}
+
+func TestStructuredFilter_NestedGroups(t *testing.T) {
+	sf := NewStructuredFilter()
+	qb := query.NewQueryBuilder("users", nil)
+
+	expr := &paginationV1.FilterExpr{
+		Type: paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{
+			cond("status", paginationV1.Operator_EQ, "ON"),
+		},
+		Groups: []*paginationV1.FilterExpr{
+			{
+				Type: paginationV1.ExprType_OR,
+				Conditions: []*paginationV1.FilterCondition{
+					cond("name", paginationV1.Operator_STARTS_WITH, "al"),
+					cond("age", paginationV1.Operator_LT, "18"),
+				},
+			},
+			{
+				// 只有一个子项的 OR 直接展开
+				Type: paginationV1.ExprType_OR,
+				Conditions: []*paginationV1.FilterCondition{
+					cond("message", paginationV1.Operator_SEARCH, "hello"),
+				},
+			},
+		},
+	}
+
+	_, err := sf.BuildSelectors(qb, expr)
+	assert.NoError(t, err)
+
+	expected := `{"bool":{"must":[
+		{"term":{"status":{"value":"ON"}}},
+		{"bool":{"should":[
+			{"prefix":{"name":{"value":"al"}}},
+			{"range":{"age":{"lt":"18"}}}
+		],"minimum_should_match":1}},
+		{"match":{"message":{"query":"hello"}}}
+	]}}`
+	assert.JSONEq(t, expected, toJSON(t, qb.BuildQuery()))
+}
+
+func TestStructuredFilter_Errors(t *testing.T) {
+	sf := NewStructuredFilter()
+
+	cases := []struct {
+		name string
+		cond *paginationV1.FilterCondition
+		err  error
+	}{
+		{"unspecified operator", cond("name", paginationV1.Operator_OPERATOR_UNSPECIFIED, "x"), ErrUnsupportedOperator},
+		{"empty field", cond("", paginationV1.Operator_EQ, "x"), ErrInvalidField},
+		{"injected field", cond(`name"}}`, paginationV1.Operator_EQ, "x"), ErrInvalidField},
+		{"empty IN", cond("status", paginationV1.Operator_IN, ""), ErrInvalidValue},
+		{"BETWEEN one value", cond("age", paginationV1.Operator_BETWEEN, "18"), ErrInvalidValue},
+		{"JSON_CONTAINS bad key", cond("profile", paginationV1.Operator_JSON_CONTAINS, `{"a b":"x"}`), ErrInvalidField},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			qb := query.NewQueryBuilder("users", nil)
+			_, err := sf.BuildSelectors(qb, &paginationV1.FilterExpr{
+				Type:       paginationV1.ExprType_AND,
+				Conditions: []*paginationV1.FilterCondition{tc.cond},
+			})
+			assert.True(t, errors.Is(err, tc.err), "got %v", err)
+		})
+	}
+}
@@ -2,6 +2,8 @@ module github.com/tx7do/go-crud/elasticsearch
 
 go 1.24.11
 
+replace github.com/tx7do/go-crud => ../
+
 replace github.com/tx7do/go-crud/api => ../api
 
 replace github.com/tx7do/go-crud/pagination => ../pagination
@@ -10,7 +12,12 @@ require (
 	github.com/elastic/go-elasticsearch/v9 v9.2.1
 	github.com/go-kratos/kratos/v2 v2.9.2
 	github.com/stretchr/testify v1.11.1
+	github.com/tx7do/go-crud v0.0.1
 	github.com/tx7do/go-crud/api v0.0.7
+	github.com/tx7do/go-crud/pagination v0.0.11
+	github.com/tx7do/go-utils v1.1.34
+	github.com/tx7do/go-utils/mapper v0.0.3
+	google.golang.org/protobuf v1.36.11
 )
 
 require (
@@ -21,15 +28,17 @@ require (
 	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/google/gnostic v0.7.1 // indirect
 	github.com/google/gnostic-models v0.7.1 // indirect
+	github.com/jinzhu/copier v0.4.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.einride.tech/aip v0.79.0 // indirect
 	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
 	go.opentelemetry.io/otel v1.39.0 // indirect
 	go.opentelemetry.io/otel/metric v1.39.0 // indirect
 	go.opentelemetry.io/otel/trace v1.39.0 // indirect
 	go.yaml.in/yaml/v3 v3.0.4 // indirect
 	golang.org/x/sys v0.40.0 // indirect
-	google.golang.org/genproto/googleapis/rpc v0.0.0-20260112192933-99fd39fd28a9 // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20260114163908-3f89685c29c3 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260114163908-3f89685c29c3 // indirect
 	google.golang.org/grpc v1.78.0 // indirect
-	google.golang.org/protobuf v1.36.11 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,6 @@
+package pagination
+
+import "errors"
+
+// ErrInvalidToken 分页 token 无法解码为 search_after 游标
+var ErrInvalidToken = errors.New("invalid paging token")
@@ -0,0 +1,29 @@
+package pagination
+
+import (
+	"github.com/tx7do/go-crud/elasticsearch/query"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/paginator"
+)
+
+// OffsetPaginator 基于 Offset 的分页器（Elasticsearch 版）
+type OffsetPaginator struct {
+	impl pagination.Paginator
+}
+
+func NewOffsetPaginator() *OffsetPaginator {
+	return &OffsetPaginator{
+		impl: paginator.NewOffsetPaginatorWithDefault(),
+	}
+}
+
+// BuildClause 根据传入的 offset/limit 更新内部状态并将 from/size 设置到 query.Builder。
+func (p *OffsetPaginator) BuildClause(builder *query.Builder, offset, limit int) *query.Builder {
+	p.impl.
+		WithOffset(offset).
+		WithLimit(limit)
+
+	return builder.
+		From(p.impl.Offset()).
+		Size(p.impl.Limit())
+}
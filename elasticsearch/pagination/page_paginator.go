@@ -0,0 +1,29 @@
+package pagination
+
+import (
+	"github.com/tx7do/go-crud/elasticsearch/query"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/paginator"
+)
+
+// PagePaginator 基于页码的分页器（Elasticsearch 版）
+type PagePaginator struct {
+	impl pagination.Paginator
+}
+
+func NewPagePaginator() *PagePaginator {
+	return &PagePaginator{
+		impl: paginator.NewPagePaginatorWithDefault(),
+	}
+}
+
+// BuildClause 根据传入的 page/size 更新内部状态并将 from/size 设置到 query.Builder。
+func (p *PagePaginator) BuildClause(builder *query.Builder, page, size int) *query.Builder {
+	p.impl.
+		WithPage(page).
+		WithSize(size)
+
+	return builder.
+		From(p.impl.Offset()).
+		Size(p.impl.Limit())
+}
@@ -0,0 +1,104 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/tx7do/go-crud/elasticsearch/query"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/paginator"
+)
+
+// DefaultTiebreaker 默认使用 _id 保证排序唯一性
+const DefaultTiebreaker = "_id"
+
+// TokenPaginator 基于 Token 的分页器（Elasticsearch 版）
+// token 为上一页最后一条命中记录的 sort 值（search_after）经 JSON 序列化后由 CursorCodec 编码的结果。
+type TokenPaginator struct {
+	impl       pagination.Paginator
+	codec      pagination.CursorCodec
+	tiebreaker string
+}
+
+func NewTokenPaginator() *TokenPaginator {
+	return &TokenPaginator{
+		impl:       paginator.NewTokenPaginatorWithDefault(),
+		codec:      pagination.DefaultCursorCodec(),
+		tiebreaker: DefaultTiebreaker,
+	}
+}
+
+// WithTiebreaker 设置保证排序唯一性的字段（默认 _id）。
+// Elasticsearch 8 默认禁止对 _id 排序（indices.id_field_data.enabled），此时应改用文档中唯一的 keyword 字段。
+func (p *TokenPaginator) WithTiebreaker(field string) *TokenPaginator {
+	if field == "" {
+		field = DefaultTiebreaker
+	}
+	p.tiebreaker = field
+	return p
+}
+
+// WithCursorCodec 设置 token 的编解码器（签名/加密），为 nil 时使用默认编解码器
+func (p *TokenPaginator) WithCursorCodec(codec pagination.CursorCodec) *TokenPaginator {
+	if codec == nil {
+		codec = pagination.DefaultCursorCodec()
+	}
+	p.codec = codec
+	return p
+}
+
+// BuildClause 根据传入 token/pageSize 设置 builder 的 size 与 search_after。
+// token 为空时从第一页开始；scope 为 token 绑定的查询范围（见 pagination.CursorScope）。
+// search_after 依赖确定的全序排序，调用方应在此之前设置排序，未包含 tiebreaker 时以升序追加到末尾。
+func (p *TokenPaginator) BuildClause(builder *query.Builder, token string, pageSize int, scope []byte) (*query.Builder, error) {
+	p.impl.
+		WithToken(token).
+		WithSize(pageSize)
+
+	builder.Size(p.impl.Size())
+	if !builder.HasSortField(p.tiebreaker) {
+		builder.OrderBy(p.tiebreaker, false)
+	}
+
+	if token == "" {
+		return builder, nil
+	}
+
+	values, err := p.DecodeToken(token, scope)
+	if err != nil {
+		return builder, err
+	}
+
+	return builder.SearchAfter(values...), nil
+}
+
+// EncodeToken 将 sort 值编码为 token，values 为空（已无下一页）时返回空字符串
+func (p *TokenPaginator) EncodeToken(values []any, scope []byte) (string, error) {
+	if len(values) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	return p.codec.Encode(b, scope)
+}
+
+// DecodeToken 将 token 解码为 sort 值。
+// 签名、过期与 scope 校验失败时返回 pagination 包中对应的错误，内容无法解析时返回 ErrInvalidToken。
+func (p *TokenPaginator) DecodeToken(token string, scope []byte) ([]any, error) {
+	b, err := p.codec.Decode(token, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// 使用 json.Number 保留 long 类型排序值的精度
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var values []any
+	if err = dec.Decode(&values); err != nil || len(values) == 0 {
+		return nil, ErrInvalidToken
+	}
+	return values, nil
+}
@@ -0,0 +1,98 @@
+package pagination
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tx7do/go-crud/elasticsearch/query"
+	"github.com/tx7do/go-crud/pagination"
+)
+
+func TestToken_RoundTrip(t *testing.T) {
+	p := NewTokenPaginator()
+
+	token, err := p.EncodeToken(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", token)
+
+	token, err = p.EncodeToken([]any{json.Number("1700000000000123456"), "abc"}, nil)
+	assert.NoError(t, err)
+	values, err := p.DecodeToken(token, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{json.Number("1700000000000123456"), "abc"}, values)
+
+	_, err = p.DecodeToken("not base64!", nil)
+	assert.ErrorIs(t, err, pagination.ErrCursorMalformed)
+}
+
+func TestToken_SignedAndScoped(t *testing.T) {
+	codec, err := pagination.NewSignedCursorCodec([]pagination.CursorKey{{ID: "k1", Secret: []byte("0123456789abcdef0123456789abcdef")}})
+	assert.NoError(t, err)
+
+	p := NewTokenPaginator().WithCursorCodec(codec)
+	scope := pagination.CursorScope(nil, nil, nil)
+
+	token, err := p.EncodeToken([]any{json.Number("42")}, scope)
+	assert.NoError(t, err)
+
+	values, err := p.DecodeToken(token, scope)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{json.Number("42")}, values)
+
+	_, err = p.DecodeToken(token, []byte("other"))
+	assert.ErrorIs(t, err, pagination.ErrCursorScopeMismatch)
+
+	// 未签名的 token 不被接受
+	plain, _ := NewTokenPaginator().EncodeToken([]any{json.Number("42")}, scope)
+	_, err = p.DecodeToken(plain, scope)
+	assert.ErrorIs(t, err, pagination.ErrCursorMalformed)
+}
+
+func TestTokenPaginator_BuildClause(t *testing.T) {
+	p := NewTokenPaginator()
+
+	qb := query.NewQueryBuilder("users", nil)
+	_, err := p.BuildClause(qb, "", 10, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, qb.GetSize())
+	assert.Empty(t, qb.GetSearchAfter())
+	// 未排序时以 tiebreaker 排序，保证第一页就能生成 next token
+	assert.Equal(t, []any{map[string]any{"_id": map[string]any{"order": "asc"}}}, qb.Build()["sort"])
+
+	// 已有排序时追加 tiebreaker，已包含时不重复追加
+	qb = query.NewQueryBuilder("users", nil).OrderBy("score", true)
+	_, _ = p.BuildClause(qb, "", 10, nil)
+	assert.Equal(t, []any{
+		map[string]any{"score": map[string]any{"order": "desc"}},
+		map[string]any{"_id": map[string]any{"order": "asc"}},
+	}, qb.Build()["sort"])
+
+	qb = query.NewQueryBuilder("users", nil).OrderBy("userId", true)
+	_, _ = NewTokenPaginator().WithTiebreaker("user_id").BuildClause(qb, "", 10, nil)
+	assert.Equal(t, []any{map[string]any{"user_id": map[string]any{"order": "desc"}}}, qb.Build()["sort"])
+
+	token, _ := p.EncodeToken([]any{json.Number("42")}, nil)
+	qb = query.NewQueryBuilder("users", nil)
+	_, err = p.BuildClause(qb, token, 5, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, qb.GetSize())
+	assert.Equal(t, []any{json.Number("42")}, qb.GetSearchAfter())
+
+	_, err = p.BuildClause(query.NewQueryBuilder("users", nil), token, 5, []byte("other"))
+	assert.ErrorIs(t, err, pagination.ErrCursorScopeMismatch)
+
+	_, err = p.BuildClause(query.NewQueryBuilder("users", nil), "bad", 5, nil)
+	assert.ErrorIs(t, err, pagination.ErrCursorMalformed)
+}
+
+func TestOffsetAndPagePaginator_BuildClause(t *testing.T) {
+	qb := NewOffsetPaginator().BuildClause(query.NewQueryBuilder("users", nil), 20, 10)
+	assert.Equal(t, 20, qb.GetFrom())
+	assert.Equal(t, 10, qb.GetSize())
+
+	qb = NewPagePaginator().BuildClause(query.NewQueryBuilder("users", nil), 3, 10)
+	assert.Equal(t, 20, qb.GetFrom())
+	assert.Equal(t, 10, qb.GetSize())
+}
@@ -0,0 +1,208 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/tx7do/go-utils/stringcase"
+)
+
+// Builder 用于构建 Elasticsearch 的 Search/Count 请求体（Query DSL）
+type Builder struct {
+	index string
+
+	must    []map[string]any
+	sorts   []map[string]any
+	sources []string
+
+	from        *int
+	size        *int
+	searchAfter []any
+
+	log *log.Helper
+}
+
+// NewQueryBuilder 创建一个新的 Builder
+func NewQueryBuilder(index string, log *log.Helper) *Builder {
+	return &Builder{
+		index: index,
+		log:   log,
+	}
+}
+
+// IndexName 返回索引名
+func (qb *Builder) IndexName() string {
+	return qb.index
+}
+
+// Where 追加一个查询子句，多个子句之间为 AND（bool.must）关系
+func (qb *Builder) Where(clauses ...map[string]any) *Builder {
+	for _, c := range clauses {
+		if len(c) == 0 {
+			continue
+		}
+		qb.must = append(qb.must, c)
+	}
+	return qb
+}
+
+// HasConditions 是否存在查询子句
+func (qb *Builder) HasConditions() bool {
+	return len(qb.must) > 0
+}
+
+// OrderBy 追加排序字段，字段名会转换为 snake_case（点号分隔的子字段保持原样）
+func (qb *Builder) OrderBy(field string, desc bool) *Builder {
+	field = sortField(field)
+	if field == "" {
+		return qb
+	}
+
+	order := "asc"
+	if desc {
+		order = "desc"
+	}
+	qb.sorts = append(qb.sorts, map[string]any{field: map[string]any{"order": order}})
+	return qb
+}
+
+// HasSort 是否设置了排序
+func (qb *Builder) HasSort() bool {
+	return len(qb.sorts) > 0
+}
+
+// HasSortField 是否已按指定字段排序，字段名按 OrderBy 的规则转换后比较
+func (qb *Builder) HasSortField(field string) bool {
+	field = sortField(field)
+	for _, s := range qb.sorts {
+		if _, ok := s[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sortField 规范化排序字段名：_score、_doc 等元字段保持原样，其余转换为 snake_case
+func sortField(field string) string {
+	field = strings.TrimSpace(field)
+	if field == "" || strings.HasPrefix(field, "_") {
+		return field
+	}
+	if idx := strings.Index(field, "."); idx > 0 {
+		return stringcase.ToSnakeCase(field[:idx]) + field[idx:]
+	}
+	return stringcase.ToSnakeCase(field)
+}
+
+// Source 设置返回的 _source 字段
+func (qb *Builder) Source(fields ...string) *Builder {
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		qb.sources = append(qb.sources, f)
+	}
+	return qb
+}
+
+// From 设置跳过的文档数
+func (qb *Builder) From(from int) *Builder {
+	if from < 0 {
+		from = 0
+	}
+	qb.from = &from
+	return qb
+}
+
+// GetFrom 返回跳过的文档数
+func (qb *Builder) GetFrom() int {
+	if qb.from == nil {
+		return 0
+	}
+	return *qb.from
+}
+
+// Size 设置返回的文档数
+func (qb *Builder) Size(size int) *Builder {
+	if size < 0 {
+		size = 0
+	}
+	qb.size = &size
+	return qb
+}
+
+// GetSize 返回文档数，未设置时返回 0
+func (qb *Builder) GetSize() int {
+	if qb.size == nil {
+		return 0
+	}
+	return *qb.size
+}
+
+// SearchAfter 设置 search_after 游标（上一页最后一条记录的排序值）
+func (qb *Builder) SearchAfter(values ...any) *Builder {
+	qb.searchAfter = values
+	return qb
+}
+
+// GetSearchAfter 返回 search_after 游标
+func (qb *Builder) GetSearchAfter() []any {
+	return qb.searchAfter
+}
+
+// BuildQuery 返回 query 部分，无条件时为 match_all
+func (qb *Builder) BuildQuery() map[string]any {
+	switch len(qb.must) {
+	case 0:
+		return map[string]any{"match_all": map[string]any{}}
+	case 1:
+		return qb.must[0]
+	default:
+		must := make([]any, 0, len(qb.must))
+		for _, c := range qb.must {
+			must = append(must, c)
+		}
+		return map[string]any{"bool": map[string]any{"must": must}}
+	}
+}
+
+// Build 构建 Search 请求体
+func (qb *Builder) Build() map[string]any {
+	body := map[string]any{
+		"query":            qb.BuildQuery(),
+		"track_total_hits": true,
+	}
+
+	if len(qb.sorts) > 0 {
+		sorts := make([]any, 0, len(qb.sorts))
+		for _, s := range qb.sorts {
+			sorts = append(sorts, s)
+		}
+		body["sort"] = sorts
+	}
+
+	if len(qb.sources) > 0 {
+		body["_source"] = append([]string(nil), qb.sources...)
+	}
+
+	if len(qb.searchAfter) > 0 {
+		// search_after 与 from 不能同时使用
+		body["search_after"] = qb.searchAfter
+	} else if qb.from != nil && *qb.from > 0 {
+		body["from"] = *qb.from
+	}
+
+	if qb.size != nil {
+		body["size"] = *qb.size
+	}
+
+	return body
+}
+
+// BuildCount 构建 Count 请求体（仅包含 query）
+func (qb *Builder) BuildCount() map[string]any {
+	return map[string]any{
+		"query": qb.BuildQuery(),
+	}
+}
@@ -0,0 +1,79 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func toJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	return string(b)
+}
+
+func TestBuilder_EmptyQuery(t *testing.T) {
+	qb := NewQueryBuilder("users", nil)
+
+	assert.Equal(t, "users", qb.IndexName())
+	assert.False(t, qb.HasConditions())
+	assert.JSONEq(t, `{"query":{"match_all":{}},"track_total_hits":true}`, toJSON(t, qb.Build()))
+	assert.JSONEq(t, `{"query":{"match_all":{}}}`, toJSON(t, qb.BuildCount()))
+}
+
+func TestBuilder_WhereSortSourcePaging(t *testing.T) {
+	qb := NewQueryBuilder("users", nil)
+
+	qb.Where(map[string]any{"term": map[string]any{"name": "alice"}})
+	assert.JSONEq(t, `{"term":{"name":"alice"}}`, toJSON(t, qb.BuildQuery()))
+
+	qb.Where(nil, map[string]any{"range": map[string]any{"age": map[string]any{"gt": "18"}}})
+	qb.OrderBy("CreatedAt", true).OrderBy("profile.nickName", false).OrderBy("_score", true)
+	qb.Source("id", " ", "name")
+	qb.From(20).Size(10)
+
+	assert.True(t, qb.HasConditions())
+	assert.True(t, qb.HasSort())
+	assert.Equal(t, 20, qb.GetFrom())
+	assert.Equal(t, 10, qb.GetSize())
+
+	expected := `{
+		"query": {"bool": {"must": [
+			{"term": {"name": "alice"}},
+			{"range": {"age": {"gt": "18"}}}
+		]}},
+		"sort": [
+			{"created_at": {"order": "desc"}},
+			{"profile.nickName": {"order": "asc"}},
+			{"_score": {"order": "desc"}}
+		],
+		"_source": ["id", "name"],
+		"from": 20,
+		"size": 10,
+		"track_total_hits": true
+	}`
+	assert.JSONEq(t, expected, toJSON(t, qb.Build()))
+
+	assert.JSONEq(t, `{"query": {"bool": {"must": [
+		{"term": {"name": "alice"}},
+		{"range": {"age": {"gt": "18"}}}
+	]}}}`, toJSON(t, qb.BuildCount()))
+}
+
+func TestBuilder_SearchAfterOverridesFrom(t *testing.T) {
+	qb := NewQueryBuilder("users", nil).
+		OrderBy("id", false).
+		From(30).
+		Size(5).
+		SearchAfter(json.Number("42"))
+
+	body := qb.Build()
+	_, hasFrom := body["from"]
+	assert.False(t, hasFrom)
+	assert.JSONEq(t, `[42]`, toJSON(t, body["search_after"]))
+	assert.Equal(t, 5, body["size"])
+}
@@ -0,0 +1,434 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/tx7do/go-utils/mapper"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/elasticsearch/field"
+	"github.com/tx7do/go-crud/elasticsearch/filter"
+	paging "github.com/tx7do/go-crud/elasticsearch/pagination"
+	"github.com/tx7do/go-crud/elasticsearch/query"
+	"github.com/tx7do/go-crud/elasticsearch/sorting"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
+	"github.com/tx7do/go-crud/pagination/paginator"
+	paginationSorting "github.com/tx7do/go-crud/pagination/sorting"
+)
+
+// PagingResult 通用分页返回（含完整的分页元数据），见 pagination.PagingResult
+type PagingResult[E any] = pagination.PagingResult[E]
+
+// Repository Elasticsearch 仓库，包含常用的 CRUD 方法
+type Repository[DTO any, ENTITY any] struct {
+	mapper *mapper.CopierMapper[DTO, ENTITY]
+
+	offsetPaginator *paging.OffsetPaginator
+	pagePaginator   *paging.PagePaginator
+	tokenPaginator  *paging.TokenPaginator
+
+	structuredFilter *filter.StructuredFilter
+
+	structuredSorting      *sorting.StructuredSorting
+	orderByStringConverter *paginationSorting.OrderByStringConverter
+
+	fieldSelector *field.Selector
+
+	client *Client
+	index  string
+	log    *log.Helper
+}
+
+func NewRepository[DTO any, ENTITY any](client *Client, index string, mapper *mapper.CopierMapper[DTO, ENTITY], logger *log.Helper) *Repository[DTO, ENTITY] {
+	if logger == nil {
+		logger = log.NewHelper(log.With(log.DefaultLogger, "module", "elasticsearch-repository"))
+	}
+
+	return &Repository[DTO, ENTITY]{
+		client: client,
+		index:  index,
+
+		mapper: mapper,
+		log:    logger,
+
+		offsetPaginator: paging.NewOffsetPaginator(),
+		pagePaginator:   paging.NewPagePaginator(),
+		tokenPaginator:  paging.NewTokenPaginator(),
+
+		structuredFilter: filter.NewStructuredFilter(),
+
+		structuredSorting:      sorting.NewStructuredSorting(),
+		orderByStringConverter: paginationSorting.NewOrderByStringConverter(),
+
+		fieldSelector: field.NewFieldSelector(),
+	}
+}
+
+// WithCursorCodec 设置 token 分页使用的游标编解码器，生产环境建议使用 pagination.SignedCursorCodec 防止 token 被伪造
+func (r *Repository[DTO, ENTITY]) WithCursorCodec(codec pagination.CursorCodec) *Repository[DTO, ENTITY] {
+	r.tokenPaginator.WithCursorCodec(codec)
+	return r
+}
+
+// WithTiebreaker 设置 token 分页保证排序唯一性的字段（默认 _id），见 paging.TokenPaginator.WithTiebreaker
+func (r *Repository[DTO, ENTITY]) WithTiebreaker(field string) *Repository[DTO, ENTITY] {
+	r.tokenPaginator.WithTiebreaker(field)
+	return r
+}
+
+// WithFilterPolicy 设置过滤与排序的字段策略（白名单、列名映射、操作符限制等），违反策略的查询返回 paginationFilter.ErrPolicyViolation
+func (r *Repository[DTO, ENTITY]) WithFilterPolicy(policy *paginationFilter.FilterPolicy) *Repository[DTO, ENTITY] {
+	r.structuredFilter.WithPolicy(policy)
+	r.structuredSorting.WithPolicy(policy)
+	return r
+}
+
+// WithFieldMapping 设置 API 字段名到列名的映射，过滤、排序与字段选择均按映射解析列名
+func (r *Repository[DTO, ENTITY]) WithFieldMapping(mapping *fieldmap.Mapping) *Repository[DTO, ENTITY] {
+	r.structuredFilter.WithFieldMapping(mapping)
+	r.structuredSorting.WithFieldMapping(mapping)
+	r.fieldSelector.WithFieldMapping(mapping)
+	return r
+}
+
+// check 校验仓库是否可用
+func (r *Repository[DTO, ENTITY]) check() error {
+	if r.client == nil || r.client.Client == nil {
+		return errors.New("elasticsearch client is nil")
+	}
+	if r.index == "" {
+		return errors.New("index is empty")
+	}
+	return nil
+}
+
+// Count 统计符合 FilterExpr 的文档数
+func (r *Repository[DTO, ENTITY]) Count(ctx context.Context, expr *paginationV1.FilterExpr) (int64, error) {
+	if err := r.check(); err != nil {
+		return 0, err
+	}
+
+	qb := query.NewQueryBuilder(r.index, r.log)
+	if _, err := r.structuredFilter.BuildSelectors(qb, expr); err != nil {
+		r.log.Errorf("build structured filter selectors failed: %s", err.Error())
+		return 0, err
+	}
+
+	return r.client.Count(ctx, r.index, qb.BuildCount())
+}
+
+// ListWithPaging 使用 PagingRequest 查询列表
+func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, req *paginationV1.PagingRequest) (*PagingResult[DTO], error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, errors.New("paging request is nil")
+	}
+
+	qb := query.NewQueryBuilder(r.index, r.log)
+
+	var err error
+
+	// filters
+	var filterExpr *paginationV1.FilterExpr
+	filterExpr, err = paginationFilter.ConvertFilterByPagingRequest(req)
+	if err != nil {
+		r.log.Errorf("convert filter string to filter expr failed: %s", err.Error())
+		return nil, err
+	}
+	req.FilteringType = &paginationV1.PagingRequest_FilterExpr{FilterExpr: filterExpr}
+
+	if _, err = r.structuredFilter.BuildSelectors(qb, req.GetFilterExpr()); err != nil {
+		r.log.Errorf("build structured filter selectors failed: %s", err.Error())
+		return nil, err
+	}
+
+	// select fields
+	if req.GetFieldMask() != nil && len(req.GetFieldMask().GetPaths()) > 0 {
+		if _, err = r.fieldSelector.BuildSelector(qb, req.GetFieldMask().GetPaths()); err != nil {
+			r.log.Errorf("build field select selector failed: %s", err.Error())
+		}
+	}
+
+	// order by
+	var sortings []*paginationV1.Sorting
+	if len(req.GetSorting()) > 0 {
+		sortings = req.GetSorting()
+	} else if len(req.GetOrderBy()) > 0 {
+		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
+		if err != nil {
+			r.log.Errorf("convert order by string to sorting failed: %s", err.Error())
+			return nil, err
+		}
+	}
+	if _, err = r.structuredSorting.Validate(sortings); err != nil {
+		r.log.Errorf("validate sorting failed: %s", err.Error())
+		return nil, err
+	}
+	if len(sortings) > 0 {
+		_ = r.structuredSorting.BuildOrderClause(qb, sortings)
+	}
+
+	// pagination
+	var pager pagination.Paginator
+	scope := pagination.CursorScopeOf(req)
+	if !req.GetNoPaging() {
+		if req.Page != nil && req.PageSize != nil {
+			pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPage())).WithSize(int(req.GetPageSize()))
+			_ = r.pagePaginator.BuildClause(qb, int(req.GetPage()), int(req.GetPageSize()))
+		} else if req.Offset != nil && req.Limit != nil {
+			pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffset())).WithLimit(int(req.GetLimit()))
+			_ = r.offsetPaginator.BuildClause(qb, int(req.GetOffset()), int(req.GetLimit()))
+		} else if req.Token != nil {
+			size := int(req.GetPageSize())
+			if req.PageSize == nil {
+				size = int(req.GetLimit())
+			}
+			pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetToken()).WithSize(size)
+			if _, err = r.tokenPaginator.BuildClause(qb, req.GetToken(), size, scope); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return r.search(ctx, qb, pager, scope)
+}
+
+// ListWithPagination 使用 PaginationRequest 查询列表
+func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, req *paginationV1.PaginationRequest) (*PagingResult[DTO], error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, errors.New("pagination request is nil")
+	}
+
+	qb := query.NewQueryBuilder(r.index, r.log)
+
+	var err error
+
+	// filters
+	var filterExpr *paginationV1.FilterExpr
+	filterExpr, err = paginationFilter.ConvertFilterByPaginationRequest(req)
+	if err != nil {
+		r.log.Errorf("convert filter string to filter expr failed: %s", err.Error())
+		return nil, err
+	}
+	req.FilteringType = &paginationV1.PaginationRequest_FilterExpr{FilterExpr: filterExpr}
+
+	if _, err = r.structuredFilter.BuildSelectors(qb, req.GetFilterExpr()); err != nil {
+		r.log.Errorf("build structured filter selectors failed: %s", err.Error())
+		return nil, err
+	}
+
+	// select fields
+	if req.GetFieldMask() != nil && len(req.GetFieldMask().GetPaths()) > 0 {
+		if _, err = r.fieldSelector.BuildSelector(qb, req.GetFieldMask().GetPaths()); err != nil {
+			r.log.Errorf("build field select selector failed: %s", err.Error())
+		}
+	}
+
+	// order by
+	var sortings []*paginationV1.Sorting
+	if len(req.GetSorting()) > 0 {
+		sortings = req.GetSorting()
+	} else if len(req.GetOrderBy()) > 0 {
+		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
+		if err != nil {
+			r.log.Errorf("convert order by string to sorting failed: %s", err.Error())
+			return nil, err
+		}
+	}
+	if _, err = r.structuredSorting.Validate(sortings); err != nil {
+		r.log.Errorf("validate sorting failed: %s", err.Error())
+		return nil, err
+	}
+	if len(sortings) > 0 {
+		_ = r.structuredSorting.BuildOrderClause(qb, sortings)
+	}
+
+	// pagination
+	var pager pagination.Paginator
+	scope := pagination.CursorScopeOf(req)
+	switch req.GetPaginationType().(type) {
+	case *paginationV1.PaginationRequest_OffsetBased:
+		pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffsetBased().GetOffset())).WithLimit(int(req.GetOffsetBased().GetLimit()))
+		_ = r.offsetPaginator.BuildClause(qb, int(req.GetOffsetBased().GetOffset()), int(req.GetOffsetBased().GetLimit()))
+	case *paginationV1.PaginationRequest_PageBased:
+		pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPageBased().GetPage())).WithSize(int(req.GetPageBased().GetPageSize()))
+		_ = r.pagePaginator.BuildClause(qb, int(req.GetPageBased().GetPage()), int(req.GetPageBased().GetPageSize()))
+	case *paginationV1.PaginationRequest_TokenBased:
+		pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetTokenBased().GetToken()).WithSize(int(req.GetTokenBased().GetPageSize()))
+		if _, err = r.tokenPaginator.BuildClause(qb, req.GetTokenBased().GetToken(), int(req.GetTokenBased().GetPageSize()), scope); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.search(ctx, qb, pager, scope)
+}
+
+// Get 根据 FilterExpr 获取单条记录，未找到时返回 (nil, nil)
+func (r *Repository[DTO, ENTITY]) Get(ctx context.Context, expr *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+
+	qb := query.NewQueryBuilder(r.index, r.log)
+	if _, err := r.structuredFilter.BuildSelectors(qb, expr); err != nil {
+		r.log.Errorf("build structured filter selectors failed: %s", err.Error())
+		return nil, err
+	}
+	if _, err := r.fieldSelector.BuildSelector(qb, viewMask.GetPaths()); err != nil {
+		r.log.Errorf("build field select selector failed: %s", err.Error())
+	}
+	qb.Size(1)
+
+	res, err := r.search(ctx, qb, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Items) == 0 {
+		return nil, nil
+	}
+
+	return res.Items[0], nil
+}
+
+// Only alias
+func (r *Repository[DTO, ENTITY]) Only(ctx context.Context, expr *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	return r.Get(ctx, expr, viewMask)
+}
+
+// Create 写入一条文档，id 为空时由 Elasticsearch 自动生成
+func (r *Repository[DTO, ENTITY]) Create(ctx context.Context, id string, dto *DTO) (*DTO, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	if dto == nil {
+		return nil, errors.New("dto is nil")
+	}
+
+	ent := r.mapper.ToEntity(dto)
+	if ent == nil {
+		return nil, errors.New("entity is nil")
+	}
+
+	if err := r.client.InsertDocument(ctx, r.index, id, ent); err != nil {
+		r.log.Errorf("create failed: %v", err)
+		return nil, err
+	}
+
+	return r.mapper.ToDTO(ent), nil
+}
+
+// BatchCreate 通过 Bulk API 批量写入文档
+func (r *Repository[DTO, ENTITY]) BatchCreate(ctx context.Context, dtos []*DTO) ([]*DTO, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	if len(dtos) == 0 {
+		return nil, nil
+	}
+
+	ents := make([]*ENTITY, 0, len(dtos))
+	docs := make([]interface{}, 0, len(dtos))
+	for _, dto := range dtos {
+		if dto == nil {
+			continue
+		}
+		ent := r.mapper.ToEntity(dto)
+		if ent == nil {
+			continue
+		}
+		ents = append(ents, ent)
+		docs = append(docs, ent)
+	}
+	if len(ents) == 0 {
+		return nil, nil
+	}
+
+	if err := r.client.BatchInsertDocument(ctx, r.index, docs); err != nil {
+		r.log.Errorf("batch create failed: %v", err)
+		return nil, err
+	}
+
+	return r.toDTOs(ents), nil
+}
+
+// Delete 根据文档 ID 删除
+func (r *Repository[DTO, ENTITY]) Delete(ctx context.Context, id string) error {
+	if err := r.check(); err != nil {
+		return err
+	}
+	if id == "" {
+		return errors.New("id is empty")
+	}
+
+	if err := r.client.DeleteDocument(ctx, r.index, id); err != nil {
+		r.log.Errorf("delete failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Exists 检查是否存在符合 FilterExpr 的文档
+func (r *Repository[DTO, ENTITY]) Exists(ctx context.Context, expr *paginationV1.FilterExpr) (bool, error) {
+	cnt, err := r.Count(ctx, expr)
+	if err != nil {
+		return false, err
+	}
+	return cnt > 0, nil
+}
+
+// search 执行查询并将命中文档的 _source 解码为 DTO。
+// 当 builder 设置了 size 且本页已满时，以最后一条命中记录的 sort 值生成绑定 scope 的下一页 token（未排序时命中记录不含 sort 值，不生成 token）。
+// pager 为本次请求的分页器（未分页时为 nil），用于生成分页元数据。
+func (r *Repository[DTO, ENTITY]) search(ctx context.Context, qb *query.Builder, pager pagination.Paginator, scope []byte) (*PagingResult[DTO], error) {
+	res, err := r.client.SearchWithBody(ctx, r.index, qb.Build())
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*ENTITY, 0, len(res.Hits.Hits))
+	for _, hit := range res.Hits.Hits {
+		var ent ENTITY
+		if len(hit.Source) > 0 {
+			if err = json.Unmarshal(hit.Source, &ent); err != nil {
+				r.log.Errorf("decode document %s failed: %v", hit.ID, err)
+				return nil, ErrUnmarshalResponse
+			}
+		}
+		entities = append(entities, &ent)
+	}
+
+	var nextToken string
+	if n := len(res.Hits.Hits); n > 0 && n == qb.GetSize() {
+		if nextToken, err = r.tokenPaginator.EncodeToken(res.Hits.Hits[n-1].Sort, scope); err != nil {
+			r.log.Errorf("encode next token failed: %v", err)
+			return nil, err
+		}
+	}
+
+	if pager != nil {
+		pager.SetNextToken(nextToken)
+	}
+
+	return pagination.NewPagingResult(r.toDTOs(entities), int64(res.Hits.Total.Value), pager), nil
+}
+
+// toDTOs 将实体列表转换为 DTO 列表
+func (r *Repository[DTO, ENTITY]) toDTOs(entities []*ENTITY) []*DTO {
+	dtos := make([]*DTO, 0, len(entities))
+	for _, ent := range entities {
+		dtos = append(dtos, r.mapper.ToDTO(ent))
+	}
+	return dtos
+}
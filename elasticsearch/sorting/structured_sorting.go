@@ -0,0 +1,98 @@
+package sorting
+
+import (
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/elasticsearch/query"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
+)
+
+// StructuredSorting 将结构化排序指令转换为 Elasticsearch 的 sort
+type StructuredSorting struct {
+	policy  *paginationFilter.FilterPolicy
+	mapping *fieldmap.Mapping
+}
+
+// NewStructuredSorting 创建实例
+func NewStructuredSorting() *StructuredSorting {
+	return &StructuredSorting{}
+}
+
+// WithPolicy 设置字段策略，排序字段需通过策略校验，字段按策略映射为列名
+func (ss *StructuredSorting) WithPolicy(policy *paginationFilter.FilterPolicy) *StructuredSorting {
+	ss.policy = policy
+	return ss
+}
+
+// WithFieldMapping 设置字段映射，排序字段按映射替换为列名
+func (ss *StructuredSorting) WithFieldMapping(mapping *fieldmap.Mapping) *StructuredSorting {
+	ss.mapping = mapping
+	return ss
+}
+
+// Validate 按字段策略校验排序字段，返回按策略与字段映射替换为列名后的排序；未设置策略与映射时原样返回
+func (ss StructuredSorting) Validate(orders []*paginationV1.Sorting) ([]*paginationV1.Sorting, error) {
+	orders, err := ss.policy.CheckSorting(orders)
+	return ss.mapping.Sorting(orders), err
+}
+
+// BuildOrderClause 根据传入的排序指令构造 sort。
+// 设置了字段策略时，违反策略的排序字段会被跳过，可先通过 Validate 获取违规详情。
+func (ss StructuredSorting) BuildOrderClause(builder *query.Builder, orders []*paginationV1.Sorting) *query.Builder {
+	orders, err := ss.Validate(orders)
+	if err != nil {
+		log.Warnf("skip sorting fields violating filter policy: %v", err)
+	}
+	return ss.buildOrderClause(builder, orders)
+}
+
+// buildOrderClause 构造排序子句，不做字段策略校验
+func (ss StructuredSorting) buildOrderClause(builder *query.Builder, orders []*paginationV1.Sorting) *query.Builder {
+	if builder == nil || len(orders) == 0 {
+		return builder
+	}
+
+	for _, o := range orders {
+		if o == nil {
+			continue
+		}
+
+		field := strings.TrimSpace(o.GetField())
+		if field == "" {
+			continue
+		}
+		if !fieldNameRegexp.MatchString(field) {
+			continue
+		}
+
+		builder.OrderBy(field, o.GetDirection() == paginationV1.Sorting_DESC)
+	}
+
+	return builder
+}
+
+// BuildOrderClauseWithDefaultField 当 orders 为空时使用默认排序字段
+func (ss StructuredSorting) BuildOrderClauseWithDefaultField(builder *query.Builder, orders []*paginationV1.Sorting, defaultOrderField string, defaultDesc bool) *query.Builder {
+	if len(orders) == 0 {
+		if strings.TrimSpace(defaultOrderField) == "" {
+			return builder
+		}
+		order := paginationV1.Sorting_ASC
+		if defaultDesc {
+			order = paginationV1.Sorting_DESC
+		}
+		// 默认排序字段由服务端指定，不受字段策略约束
+		return ss.buildOrderClause(builder, []*paginationV1.Sorting{
+			{
+				Field:     defaultOrderField,
+				Direction: order,
+			},
+		})
+	}
+
+	return ss.BuildOrderClause(builder, orders)
+}
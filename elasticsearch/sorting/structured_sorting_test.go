@@ -0,0 +1,60 @@
+package sorting
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/elasticsearch/query"
+)
+
+func sortJSON(t *testing.T, qb *query.Builder) string {
+	t.Helper()
+	b, err := json.Marshal(qb.Build()["sort"])
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	return string(b)
+}
+
+func TestStructuredSorting_BuildOrderClause(t *testing.T) {
+	ss := NewStructuredSorting()
+
+	qb := query.NewQueryBuilder("users", nil)
+	ss.BuildOrderClause(qb, nil)
+	assert.False(t, qb.HasSort())
+
+	qb = query.NewQueryBuilder("users", nil)
+	ss.BuildOrderClause(qb, []*paginationV1.Sorting{
+		{Field: "name", Direction: paginationV1.Sorting_ASC},
+		{Field: "CreatedAt", Direction: paginationV1.Sorting_DESC},
+		nil,
+		{Field: "", Direction: paginationV1.Sorting_ASC},
+		{Field: "bad;field", Direction: paginationV1.Sorting_ASC},
+		{Field: "Profile.age", Direction: paginationV1.Sorting_DESC},
+	})
+
+	assert.JSONEq(t, `[
+		{"name":{"order":"asc"}},
+		{"created_at":{"order":"desc"}},
+		{"profile.age":{"order":"desc"}}
+	]`, sortJSON(t, qb))
+}
+
+func TestStructuredSorting_BuildOrderClauseWithDefaultField(t *testing.T) {
+	ss := NewStructuredSorting()
+
+	qb := query.NewQueryBuilder("users", nil)
+	ss.BuildOrderClauseWithDefaultField(qb, nil, "created_at", true)
+	assert.JSONEq(t, `[{"created_at":{"order":"desc"}}]`, sortJSON(t, qb))
+
+	qb = query.NewQueryBuilder("users", nil)
+	ss.BuildOrderClauseWithDefaultField(qb, []*paginationV1.Sorting{{Field: "score", Direction: paginationV1.Sorting_ASC}}, "created_at", true)
+	assert.JSONEq(t, `[{"score":{"order":"asc"}}]`, sortJSON(t, qb))
+
+	qb = query.NewQueryBuilder("users", nil)
+	ss.BuildOrderClauseWithDefaultField(qb, nil, "", true)
+	assert.False(t, qb.HasSort())
+}
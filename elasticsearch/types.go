@@ -33,6 +33,12 @@ type SearchResult struct {
 			ID     string          `json:"_id"`
 			Score  float64         `json:"_score"`
 			Source json.RawMessage `json:"_source"`
+			Sort   []any           `json:"sort,omitempty"`
 		} `json:"hits"`
 	} `json:"hits"`
 }
+
+// CountResult 表示 Count API 的返回结构
+type CountResult struct {
+	Count int64 `json:"count"`
+}
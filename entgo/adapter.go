@@ -0,0 +1,364 @@
+package entgo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/entgo/field"
+)
+
+// QueryListBuilder 同时满足 QueryBuilder 与 ListBuilder 的查询 builder，Ent 生成的 XxxQuery 均满足该接口
+type QueryListBuilder[ENT_QUERY any, ENT_SELECT any, ENTITY any] interface {
+	QueryBuilder[ENT_QUERY, ENT_SELECT, ENTITY]
+	ListBuilder[ENT_QUERY, ENT_SELECT, ENTITY]
+}
+
+// AdapterBuilders Ent 仓库适配器使用的 builder 工厂。
+// Ent 的 builder 由代码生成，泛型代码无法直接构造，需由调用方基于生成的 client 提供；
+// 未提供的工厂对应的操作返回 crud.ErrNotSupported。
+type AdapterBuilders[
+	ENT_QUERY any, ENT_SELECT any,
+	ENT_UPDATE any, ENT_DELETE any,
+	PREDICATE any, DTO any, ENTITY any,
+] struct {
+	// Query 返回新的查询 builder，例如 client.User.Query()
+	Query func() QueryListBuilder[ENT_QUERY, ENT_SELECT, ENTITY]
+
+	// Create 返回新的创建 builder，以及将 DTO 字段写入该 builder 的函数
+	Create func() (CreateBuilder[ENTITY], func(dto *DTO))
+
+	// Update 返回新的批量更新 builder，以及将 DTO 字段写入该 builder 的函数
+	Update func() (UpdateBuilder[ENT_UPDATE, PREDICATE], func(dto *DTO))
+
+	// Delete 返回新的删除 builder，例如 client.User.Delete()
+	Delete func() DeleteBuilder[ENT_DELETE, PREDICATE]
+
+	// Upsert 执行插入或冲突更新，Ent 需开启 sql/upsert 特性后由调用方基于 OnConflict 实现
+	Upsert func(ctx context.Context, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error)
+}
+
+// RepositoryAdapter 将 Ent Repository 适配为通用的 crud.Repository 接口
+type RepositoryAdapter[
+	ENT_QUERY any, ENT_SELECT any,
+	ENT_CREATE any, ENT_CREATE_BULK any,
+	ENT_UPDATE any, ENT_UPDATE_ONE any,
+	ENT_DELETE any,
+	PREDICATE ~func(s *sql.Selector), DTO any, ENTITY any,
+] struct {
+	repo *Repository[
+		ENT_QUERY, ENT_SELECT,
+		ENT_CREATE, ENT_CREATE_BULK,
+		ENT_UPDATE, ENT_UPDATE_ONE,
+		ENT_DELETE,
+		PREDICATE, DTO, ENTITY,
+	]
+	builders AdapterBuilders[ENT_QUERY, ENT_SELECT, ENT_UPDATE, ENT_DELETE, PREDICATE, DTO, ENTITY]
+}
+
+// NewRepositoryAdapter 创建 Ent 仓库适配器
+func NewRepositoryAdapter[
+	ENT_QUERY any, ENT_SELECT any,
+	ENT_CREATE any, ENT_CREATE_BULK any,
+	ENT_UPDATE any, ENT_UPDATE_ONE any,
+	ENT_DELETE any,
+	PREDICATE ~func(s *sql.Selector), DTO any, ENTITY any,
+](
+	repo *Repository[
+		ENT_QUERY, ENT_SELECT,
+		ENT_CREATE, ENT_CREATE_BULK,
+		ENT_UPDATE, ENT_UPDATE_ONE,
+		ENT_DELETE,
+		PREDICATE, DTO, ENTITY,
+	],
+	builders AdapterBuilders[ENT_QUERY, ENT_SELECT, ENT_UPDATE, ENT_DELETE, PREDICATE, DTO, ENTITY],
+) *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+] {
+	return &RepositoryAdapter[
+		ENT_QUERY, ENT_SELECT,
+		ENT_CREATE, ENT_CREATE_BULK,
+		ENT_UPDATE, ENT_UPDATE_ONE,
+		ENT_DELETE,
+		PREDICATE, DTO, ENTITY,
+	]{
+		repo:     repo,
+		builders: builders,
+	}
+}
+
+// selectors 将 FilterExpr 编译为 sql.Selector 修饰函数
+func (a *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) selectors(filter *paginationV1.FilterExpr) ([]func(s *sql.Selector), error) {
+	if filter == nil {
+		return nil, nil
+	}
+	return a.repo.structuredFilter.BuildSelectors(filter)
+}
+
+// predicates 将 FilterExpr 编译为 Ent 的谓词
+func (a *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) predicates(filter *paginationV1.FilterExpr) ([]PREDICATE, error) {
+	selectors, err := a.selectors(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	predicates := make([]PREDICATE, 0, len(selectors))
+	for _, s := range selectors {
+		if s != nil {
+			predicates = append(predicates, PREDICATE(s))
+		}
+	}
+	return predicates, nil
+}
+
+func (a *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) List(ctx context.Context, req *paginationV1.PaginationRequest) (*crud.PagingResult[DTO], error) {
+	if a.builders.Query == nil {
+		return nil, crud.ErrNotSupported
+	}
+	return a.repo.ListWithPagination(ctx, a.builders.Query(), a.builders.Query(), req)
+}
+
+func (a *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) Get(ctx context.Context, filter *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	if a.builders.Query == nil {
+		return nil, crud.ErrNotSupported
+	}
+
+	selectors, err := a.selectors(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	builder := a.builders.Query()
+	if len(selectors) > 0 {
+		builder.Modify(selectors...)
+	}
+
+	field.NormalizeFieldMaskPaths(viewMask)
+	if viewMask != nil && len(viewMask.Paths) > 0 {
+		builder.Select(viewMask.GetPaths()...)
+	}
+	builder.Limit(1)
+
+	// 使用 All 代替 Only，避免依赖生成代码中的 NotFoundError 判断未找到
+	entities, err := builder.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 {
+		return nil, crud.ErrNotFound
+	}
+	return a.repo.mapper.ToDTO(entities[0]), nil
+}
+
+func (a *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) Create(ctx context.Context, dto *DTO, _ *fieldmaskpb.FieldMask) (*DTO, error) {
+	if a.builders.Create == nil {
+		return nil, crud.ErrNotSupported
+	}
+
+	builder, setFields := a.builders.Create()
+	return a.repo.Create(ctx, builder, dto, nil, setFields)
+}
+
+func (a *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) Update(ctx context.Context, filter *paginationV1.FilterExpr, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return nil, err
+	}
+	if a.builders.Update == nil {
+		return nil, crud.ErrNotSupported
+	}
+
+	predicates, err := a.predicates(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for retries := 0; ; retries++ {
+		builder, setFields := a.builders.Update()
+		err = a.repo.UpdateX(ctx, builder, dto, updateMask, setFields, predicates...)
+		if err == nil {
+			break
+		}
+
+		// 乐观锁冲突：重新读取最新记录并交给回调重新应用变更
+		if !errors.Is(err, crud.ErrVersionConflict) || a.repo.conflictResolver == nil || retries >= a.repo.conflictRetries {
+			return nil, err
+		}
+		latest, getErr := a.Get(ctx, filter, nil)
+		if getErr != nil {
+			return nil, getErr
+		}
+		if dto, err = a.repo.conflictResolver(ctx, latest, dto); err != nil {
+			return nil, err
+		}
+		if dto == nil {
+			return nil, crud.ErrVersionConflict
+		}
+	}
+
+	// 读取并返回更新后的记录
+	return a.Get(ctx, filter, nil)
+}
+
+func (a *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) Upsert(ctx context.Context, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	if a.builders.Upsert == nil {
+		return nil, crud.ErrNotSupported
+	}
+	if dto == nil {
+		return nil, errors.New("dto is nil")
+	}
+	return a.builders.Upsert(ctx, dto, updateMask)
+}
+
+func (a *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) Delete(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return 0, err
+	}
+	if a.builders.Delete == nil {
+		return 0, crud.ErrNotSupported
+	}
+
+	predicates, err := a.predicates(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := a.repo.Delete(ctx, a.builders.Delete(), predicates...)
+	return int64(affected), err
+}
+
+func (a *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) Count(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	if a.builders.Query == nil {
+		return 0, crud.ErrNotSupported
+	}
+
+	selectors, err := a.selectors(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := a.repo.Count(ctx, a.builders.Query(), selectors...)
+	return int64(count), err
+}
+
+func (a *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) Exists(ctx context.Context, filter *paginationV1.FilterExpr) (bool, error) {
+	if a.builders.Query == nil {
+		return false, crud.ErrNotSupported
+	}
+
+	selectors, err := a.selectors(filter)
+	if err != nil {
+		return false, err
+	}
+
+	return a.repo.Exists(ctx, a.builders.Query(), selectors...)
+}
+
+func (a *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) Restore(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	if a.builders.Update == nil {
+		return 0, crud.ErrNotSupported
+	}
+
+	predicates, err := a.predicates(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	builder, _ := a.builders.Update()
+	affected, err := a.repo.Restore(ctx, builder, predicates...)
+	return int64(affected), err
+}
+
+func (a *RepositoryAdapter[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) Purge(ctx context.Context, filter *paginationV1.FilterExpr, olderThan time.Duration) (int64, error) {
+	if a.builders.Delete == nil {
+		return 0, crud.ErrNotSupported
+	}
+
+	predicates, err := a.predicates(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := a.repo.Purge(ctx, a.builders.Delete(), olderThan, predicates...)
+	return int64(affected), err
+}
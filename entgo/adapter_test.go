@@ -0,0 +1,84 @@
+package entgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tx7do/go-utils/mapper"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/entgo/ent"
+	"github.com/tx7do/go-crud/entgo/ent/predicate"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+func TestRepositoryAdapter(t *testing.T) {
+	cli := createTestEntClient(t)
+	defer cli.Close()
+
+	ctx := viewer.WithContext(t.Context(), testContext{})
+	for _, name := range []string{"adapter_a", "adapter_b", "adapter_c"} {
+		if _, err := cli.Client().User.Create().SetName(name).Save(ctx); err != nil {
+			t.Fatalf("failed creating user: %v", err)
+		}
+	}
+
+	repo := NewRepository[
+		ent.UserQuery, ent.UserSelect,
+		ent.UserCreate, ent.UserCreateBulk,
+		ent.UserUpdate, ent.UserUpdateOne,
+		ent.UserDelete,
+		predicate.User, ent.User, ent.User,
+	](mapper.NewCopierMapper[ent.User, ent.User]())
+
+	var adapter crud.Repository[ent.User] = NewRepositoryAdapter(repo,
+		AdapterBuilders[ent.UserQuery, ent.UserSelect, ent.UserUpdate, ent.UserDelete, predicate.User, ent.User, ent.User]{
+			Query:  func() QueryListBuilder[ent.UserQuery, ent.UserSelect, ent.User] { return cli.Client().User.Query() },
+			Delete: func() DeleteBuilder[ent.UserDelete, predicate.User] { return cli.Client().User.Delete() },
+		},
+	)
+
+	nameExpr := func(op paginationV1.Operator, value string) *paginationV1.FilterExpr {
+		return &paginationV1.FilterExpr{
+			Type:       paginationV1.ExprType_AND,
+			Conditions: []*paginationV1.FilterCondition{{Field: "name", Op: op, ValueOneof: &paginationV1.FilterCondition_Value{Value: value}}},
+		}
+	}
+
+	cnt, err := adapter.Count(ctx, nameExpr(paginationV1.Operator_STARTS_WITH, "adapter_"))
+	if err != nil || cnt != 3 {
+		t.Fatalf("count: %d, %v", cnt, err)
+	}
+
+	got, err := adapter.Get(ctx, nameExpr(paginationV1.Operator_EQ, "adapter_b"), nil)
+	if err != nil || got.Name != "adapter_b" {
+		t.Fatalf("get: %+v, %v", got, err)
+	}
+	if _, err = adapter.Get(ctx, nameExpr(paginationV1.Operator_EQ, "nobody"), nil); !errors.Is(err, crud.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if _, err = adapter.Delete(ctx, nil); !errors.Is(err, crud.ErrEmptyFilter) {
+		t.Fatalf("expected ErrEmptyFilter, got %v", err)
+	}
+	untyped := nameExpr(paginationV1.Operator_EQ, "adapter_a")
+	untyped.Type = paginationV1.ExprType_EXPR_TYPE_UNSPECIFIED
+	if _, err = adapter.Delete(ctx, untyped); !errors.Is(err, crud.ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter, got %v", err)
+	}
+	if cnt, _ = adapter.Count(ctx, nil); cnt != 3 {
+		t.Fatalf("untyped filter should not touch any row, count: %d", cnt)
+	}
+	rows, err := adapter.Delete(ctx, nameExpr(paginationV1.Operator_EQ, "adapter_a"))
+	if err != nil || rows != 1 {
+		t.Fatalf("delete: %d, %v", rows, err)
+	}
+	if ok, _ := adapter.Exists(ctx, nameExpr(paginationV1.Operator_EQ, "adapter_a")); ok {
+		t.Fatal("adapter_a should be deleted")
+	}
+
+	if _, err = adapter.Create(ctx, &ent.User{Name: "x"}, nil); !errors.Is(err, crud.ErrNotSupported) {
+		t.Fatalf("expected ErrNotSupported, got %v", err)
+	}
+}
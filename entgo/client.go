@@ -12,12 +12,12 @@ import (
 
 	"entgo.io/ent/dialect"
 	"github.com/go-kratos/kratos/v2/log"
-	"go.opentelemetry.io/otel/attribute"
-	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
 
 	"github.com/XSAM/otelsql"
 
 	entSql "entgo.io/ent/dialect/sql"
+
+	"github.com/tx7do/go-crud/telemetry"
 )
 
 type EntTx interface {
@@ -81,23 +81,7 @@ func (c *EntClient[T]) SetConnectionOption(maxIdleConnections, maxOpenConnection
 	c.DB().SetConnMaxLifetime(connMaxLifetime)
 }
 
-// driverNameToSemConvKeyValue 将数据库驱动名称映射到 OpenTelemetry 语义约定的 KeyValue
-func driverNameToSemConvKeyValue(driverName string) attribute.KeyValue {
-	switch driverName {
-	case "mariadb":
-		return semconv.DBSystemMariaDB
-	case "mysql":
-		return semconv.DBSystemMySQL
-	case "postgresql":
-		return semconv.DBSystemPostgreSQL
-	case "sqlite":
-		return semconv.DBSystemSqlite
-	default:
-		return semconv.DBSystemKey.String(driverName)
-	}
-}
-
-// CreateDriver 创建数据库驱动
+// CreateDriver 创建数据库驱动，启用追踪与指标时使用与 telemetry.Repository 一致的 db.system.name 属性
 func CreateDriver(driverName, dsn string, enableTrace, enableMetrics bool) (*entSql.Driver, error) {
 	var db *sql.DB
 	var drv *entSql.Driver
@@ -106,7 +90,7 @@ func CreateDriver(driverName, dsn string, enableTrace, enableMetrics bool) (*ent
 	if enableTrace {
 		// Connect to database with otel tracing
 		if db, err = otelsql.Open(driverName, dsn, otelsql.WithAttributes(
-			driverNameToSemConvKeyValue(driverName),
+			telemetry.DBSystem(driverName),
 		)); err != nil {
 			return nil, errors.New(fmt.Sprintf("failed opening connection to db: %v", err))
 		}
@@ -124,7 +108,7 @@ func CreateDriver(driverName, dsn string, enableTrace, enableMetrics bool) (*ent
 	// Register DB stats to meter
 	if enableMetrics {
 		_, err = otelsql.RegisterDBStatsMetrics(db, otelsql.WithAttributes(
-			driverNameToSemConvKeyValue(driverName),
+			telemetry.DBSystem(driverName),
 		))
 		if err != nil {
 			return nil, errors.New(fmt.Sprintf("failed register otel meter: %v", err))
@@ -2,17 +2,32 @@ package field
 
 import (
 	"entgo.io/ent/dialect/sql"
+
+	"github.com/tx7do/go-crud/pagination/fieldmap"
 )
 
 // Selector 字段选择器，用于构建SELECT语句中的字段列表。
-type Selector struct{}
+type Selector struct {
+	mapping *fieldmap.Mapping
+}
 
 func NewFieldSelector() *Selector { return &Selector{} }
 
+// WithFieldMapping 设置字段映射，字段路径按映射替换为列名。
+func (fs *Selector) WithFieldMapping(mapping *fieldmap.Mapping) *Selector {
+	fs.mapping = mapping
+	return fs
+}
+
+// Resolve 返回字段路径按映射替换后的列名，未设置映射时原样返回。
+func (fs Selector) Resolve(field string) string {
+	return fs.mapping.Resolve(field)
+}
+
 // BuildSelect 构建字段选择
 func (fs Selector) BuildSelect(s *sql.Selector, fields []string) {
 	if len(fields) > 0 {
-		fields = NormalizePaths(fields)
+		fields = NormalizePaths(fs.mapping.Paths(fields))
 		s.Select(fields...)
 	}
 }
@@ -11,12 +11,15 @@ import (
 	"github.com/go-kratos/kratos/v2/log"
 
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
 	"github.com/tx7do/go-crud/pagination/filter"
 )
 
 // StructuredFilter 基于 FilterExpr 的过滤器
 type StructuredFilter struct {
-	codec encoding.Codec
+	codec   encoding.Codec
+	policy  *filter.FilterPolicy
+	mapping *fieldmap.Mapping
 }
 
 func NewStructuredFilter() *StructuredFilter {
@@ -25,12 +28,30 @@ func NewStructuredFilter() *StructuredFilter {
 	}
 }
 
+// WithPolicy 设置字段策略，过滤条件需通过策略校验，字段按策略映射为列名
+func (sf *StructuredFilter) WithPolicy(policy *filter.FilterPolicy) *StructuredFilter {
+	sf.policy = policy
+	return sf
+}
+
+// WithFieldMapping 设置字段映射，条件字段按映射替换为列名
+func (sf *StructuredFilter) WithFieldMapping(mapping *fieldmap.Mapping) *StructuredFilter {
+	sf.mapping = mapping
+	return sf
+}
+
 // BuildSelectors 构建过滤选择器
 func (sf StructuredFilter) BuildSelectors(expr *paginationV1.FilterExpr) ([]func(s *sql.Selector), error) {
 	if expr == nil {
 		return nil, nil
 	}
 
+	expr, err := sf.policy.CheckFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	expr = sf.mapping.Filter(expr)
+
 	// Skip unspecified expressions
 	if expr.GetType() == paginationV1.ExprType_EXPR_TYPE_UNSPECIFIED {
 		log.Warn("Skipping unspecified FilterExpr")
@@ -2,12 +2,18 @@ module github.com/tx7do/go-crud/entgo
 
 go 1.24.11
 
+replace github.com/tx7do/go-crud => ../
+
 replace github.com/tx7do/go-crud/api => ../api
 
 replace github.com/tx7do/go-crud/pagination => ../pagination
 
 replace github.com/tx7do/go-crud/audit => ../audit
 
+replace github.com/tx7do/go-crud/outbox => ../outbox
+
+replace github.com/tx7do/go-crud/telemetry => ../telemetry
+
 replace github.com/tx7do/go-crud/viewer => ../viewer
 
 require (
@@ -16,15 +22,17 @@ require (
 	github.com/go-kratos/kratos/v2 v2.9.2
 	github.com/google/uuid v1.6.0
 	github.com/stretchr/testify v1.11.1
+	github.com/tx7do/go-crud v0.0.1
 	github.com/tx7do/go-crud/api v0.0.7
 	github.com/tx7do/go-crud/audit v0.0.2
+	github.com/tx7do/go-crud/outbox v0.0.1
 	github.com/tx7do/go-crud/pagination v0.0.11
+	github.com/tx7do/go-crud/telemetry v0.0.1
 	github.com/tx7do/go-crud/viewer v0.0.5
 	github.com/tx7do/go-utils v1.1.34
 	github.com/tx7do/go-utils/id v0.0.2
 	github.com/tx7do/go-utils/mapper v0.0.3
 	github.com/xiaoqidun/entps v1.44.2
-	go.opentelemetry.io/otel v1.39.0
 	google.golang.org/protobuf v1.36.11
 )
 
@@ -59,6 +67,7 @@ require (
 	go.einride.tech/aip v0.80.0 // indirect
 	go.mongodb.org/mongo-driver v1.17.6 // indirect
 	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.opentelemetry.io/otel v1.39.0 // indirect
 	go.opentelemetry.io/otel/metric v1.39.0 // indirect
 	go.opentelemetry.io/otel/trace v1.39.0 // indirect
 	go.yaml.in/yaml/v3 v3.0.4 // indirect
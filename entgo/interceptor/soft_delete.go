@@ -6,13 +6,22 @@ import (
 
 	"entgo.io/ent"
 	"entgo.io/ent/dialect/sql"
+
+	"github.com/tx7do/go-crud/pagination/softdelete"
 )
 
 // SoftDeleteInterceptor implements a soft delete interceptor
+// 按 ctx 中的 softdelete.Mode 过滤 deleted_at：默认排除已软删除的记录，
+// softdelete.WithDeleted 不过滤，softdelete.OnlyDeleted 仅返回已软删除的记录。
 func SoftDeleteInterceptor() ent.Interceptor {
 	return ent.InterceptFunc(func(next ent.Querier) ent.Querier {
 		return ent.QuerierFunc(func(ctx context.Context, query ent.Query) (ent.Value, error) {
-			if err := injectSoftDeleteWhere(query); err != nil {
+			p := SoftDeletePredicate(softdelete.ModeFromContext(ctx), softdelete.DefaultDeletedAtColumn)
+			if p == nil {
+				return next.Query(ctx, query)
+			}
+
+			if err := injectSoftDeleteWhere(query, p); err != nil {
 				return nil, err
 			}
 
@@ -21,7 +30,23 @@ func SoftDeleteInterceptor() ent.Interceptor {
 	})
 }
 
-func injectSoftDeleteWhere(query ent.Query) error {
+// SoftDeletePredicate 返回软删除模式对应的 selector 谓词，ModeWithDeleted 返回 nil
+func SoftDeletePredicate(mode softdelete.Mode, column string) func(*sql.Selector) {
+	switch mode {
+	case softdelete.ModeWithDeleted:
+		return nil
+	case softdelete.ModeOnlyDeleted:
+		return func(s *sql.Selector) {
+			s.Where(sql.NotNull(s.C(column)))
+		}
+	default:
+		return func(s *sql.Selector) {
+			s.Where(sql.IsNull(s.C(column)))
+		}
+	}
+}
+
+func injectSoftDeleteWhere(query ent.Query, fn func(*sql.Selector)) error {
 	rv := reflect.ValueOf(query)
 	mf := rv.MethodByName("Where")
 	if !mf.IsValid() || mf.Kind() != reflect.Func {
@@ -39,9 +64,6 @@ func injectSoftDeleteWhere(query ent.Query) error {
 		return nil
 	}
 
-	fn := func(s *sql.Selector) {
-		s.Where(sql.IsNull(s.C("deleted_at")))
-	}
 	valFn := reflect.ValueOf(fn)
 
 	if valFn.Type() != elem {
@@ -3,10 +3,10 @@ package mixin
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
-	"strings"
 	"time"
 
 	"entgo.io/ent"
@@ -15,12 +15,31 @@ import (
 	"github.com/tx7do/go-crud/viewer"
 )
 
+// DefaultAuditMaxRows 批量更新/删除时默认逐行加载快照的最大行数
+const DefaultAuditMaxRows = 1000
+
+// Audit 审计日志 Mixin。
+// 对 UPDATE/DELETE 会在变更前通过 m.IDs 与生成代码中的 Client().<Type>.Get 加载受影响记录，
+// 填充 Entry.PreValue，并将字段级差异写入 Entry.Extra[audit.ExtraKeyDiff]；批量操作按行生成审计日志。
 type Audit struct {
 	ent.Schema
+
+	// SensitiveFields 当前 schema 额外的敏感字段（与全局敏感字段一样按包含关系匹配）
+	SensitiveFields []string
+
+	// NonSensitiveFields 当前 schema 中不需要脱敏的字段（精确匹配，优先级高于敏感字段）
+	NonSensitiveFields []string
+
+	// DisablePreValue 关闭变更前数据的加载（不再产生额外查询，也不再计算差异）
+	DisablePreValue bool
+
+	// MaxRows 批量更新/删除时逐行加载快照的最大行数，<=0 时使用 DefaultAuditMaxRows；
+	// 超出部分仍会逐行生成审计日志，但不包含 PreValue 与差异
+	MaxRows int
 }
 
 // Hooks 审计日志核心逻辑
-func (Audit) Hooks() []ent.Hook {
+func (a Audit) Hooks() []ent.Hook {
 	return []ent.Hook{
 		func(next ent.Mutator) ent.Mutator {
 			return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
@@ -37,7 +56,19 @@ func (Audit) Hooks() []ent.Hook {
 					return next.Mutate(ctx, m)
 				}
 
-				// TODO: 若需记录 PreValue（旧值），在这里使用 ent client 从 DB 查询并保存
+				ac, ok := audit.FromContext(ctx)
+				if !ok || ac == nil {
+					log.Printf("[Audit][WARN] missing AuditContext, Trace=%s, User=%d, Resource=%s", vc.TraceID(), vc.UserID(), m.Type())
+					return next.Mutate(ctx, m)
+				}
+
+				start := time.Now()
+
+				// 变更前加载受影响记录（ID 与旧值）
+				var snapshot *auditSnapshot
+				if !op.Is(ent.OpCreate) {
+					snapshot = a.loadSnapshot(ctx, m)
+				}
 
 				// 执行数据库变更
 				value, err := next.Mutate(ctx, m)
@@ -45,56 +76,134 @@ func (Audit) Hooks() []ent.Hook {
 					return nil, err
 				}
 
-				entry := &audit.Entry{
-					TraceID:  vc.TraceID(),
-					TenantID: vc.TenantID(),
-					UserID:   vc.UserID(),
-
-					Timestamp: time.Now(),
+				costMS := time.Since(start).Milliseconds()
+				dispatcher := audit.DispatcherFromContext(ctx)
+				for _, entry := range a.buildEntries(ctx, m, value, snapshot) {
+					entry.TraceID = vc.TraceID()
+					entry.TenantID = vc.TenantID()
+					entry.UserID = vc.UserID()
+					entry.Timestamp = time.Now()
+					entry.Resource = m.Type()
+					entry.Operation = audit.Operation(op.String())
+					entry.Status = audit.StatusOK
+					entry.CostMS = costMS
 
-					Service:   "",
-					Module:    "",
-					Action:    "",
-					Resource:  m.Type(),
-					Operation: audit.Operation(m.Op().String()),
-					Status:    audit.StatusOK,
-					CostMS:    0,
+					// 交给共享的 Dispatcher 异步写入日志
+					if dErr := dispatcher.Dispatch(ctx, ac, entry); dErr != nil {
+						log.Printf("[Audit][ERROR] dispatch failed: %v", dErr)
+					}
 				}
 
-				entry.TargetID = extractTargetID(value)
+				return value, nil
+			})
+		},
+	}
+}
 
-				postData := buildPostDataFromValue(value)
-				if len(postData) == 0 {
-					postData = getPostValue(m)
-				}
-				if len(postData) > 0 {
-					if b, err := json.Marshal(postData); err == nil {
-						entry.PostValue = append([]byte(nil), b...)
-					}
-				}
+// auditSnapshot 变更前加载的受影响记录，rows 与 ids 按下标对应，未加载的行为 nil
+type auditSnapshot struct {
+	ids  []any
+	rows []map[string]any
+}
 
-				ac, ok := audit.FromContext(ctx)
-				if !ok || ac == nil {
-					log.Printf("[Audit][WARN] missing AuditContext, Trace=%s, User=%d, Resource=%s", vc.TraceID(), vc.UserID(), m.Type())
-					return value, nil
-				}
+func (a Audit) maxRows() int {
+	if a.MaxRows <= 0 {
+		return DefaultAuditMaxRows
+	}
+	return a.MaxRows
+}
 
-				eCopy := *entry
-				if eCopy.PostValue != nil {
-					eCopy.PostValue = append([]byte(nil), eCopy.PostValue...)
-				}
+// loadSnapshot 通过 m.IDs 获取受影响记录，并逐行加载旧值；UpdateOne 加载失败时回退到 OldField
+func (a Audit) loadSnapshot(ctx context.Context, m ent.Mutation) *auditSnapshot {
+	ids, err := mutationIDs(ctx, m)
+	if err != nil {
+		log.Printf("[Audit][WARN] load affected ids failed, Resource=%s: %v", m.Type(), err)
+		return nil
+	}
 
-				// 异步写入日志
-				go func(a audit.Auditor, e audit.Entry) {
-					if recErr := a.Record(context.Background(), &e); recErr != nil {
-						log.Printf("[Audit][ERROR] record failed: %v", recErr)
-					}
-				}(ac, eCopy)
+	s := &auditSnapshot{ids: ids, rows: make([]map[string]any, len(ids))}
+	if a.DisablePreValue {
+		return s
+	}
 
-				return value, nil
-			})
-		},
+	for i, id := range ids {
+		if i >= a.maxRows() {
+			break
+		}
+		row, err := loadEntity(ctx, m, id)
+		if err != nil && m.Op().Is(ent.OpUpdateOne) {
+			row = oldFieldValues(ctx, m)
+		}
+		s.rows[i] = row
 	}
+	return s
+}
+
+// buildEntries 根据变更结果生成审计日志：创建与 UpdateOne 为一条，批量更新/删除按受影响的行各一条
+func (a Audit) buildEntries(ctx context.Context, m ent.Mutation, value ent.Value, s *auditSnapshot) []*audit.Entry {
+	op := m.Op()
+
+	// 创建，或未能获取受影响 ID 的更新/删除
+	if op.Is(ent.OpCreate) || s == nil {
+		entry := &audit.Entry{TargetID: extractTargetID(value)}
+		if !op.Is(ent.OpDelete | ent.OpDeleteOne) {
+			post := entityToMap(value)
+			if len(post) == 0 {
+				post = getPostValue(m)
+			}
+			a.setValues(entry, nil, post, false)
+		}
+		return []*audit.Entry{entry}
+	}
+
+	entries := make([]*audit.Entry, 0, len(s.ids))
+	for i, id := range s.ids {
+		entry := &audit.Entry{TargetID: fmt.Sprintf("%v", id)}
+		pre := s.rows[i]
+
+		var post map[string]any
+		if op.Is(ent.OpUpdate | ent.OpUpdateOne) {
+			if op.Is(ent.OpUpdateOne) {
+				post = entityToMap(value)
+			}
+			// 已加载旧值的行重新加载一次，得到完整的变更后数据
+			if len(post) == 0 && pre != nil {
+				post, _ = loadEntity(ctx, m, id)
+			}
+			if len(post) == 0 {
+				post = mergeValues(pre, getPostValue(m))
+			}
+		}
+
+		a.setValues(entry, pre, post, op.Is(ent.OpUpdate|ent.OpUpdateOne))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// setValues 计算差异并对敏感字段脱敏后写入 PreValue、PostValue 与 Extra
+func (a Audit) setValues(entry *audit.Entry, pre, post map[string]any, withDiff bool) {
+	if withDiff && pre != nil && post != nil {
+		diff := audit.ComputeDiff(pre, post)
+		diff.Mask(a.sensitive().Match, audit.MaskedValue)
+		entry.Extra = map[string]any{audit.ExtraKeyDiff: diff}
+	}
+
+	if len(pre) > 0 {
+		if b, err := json.Marshal(a.sensitive().Mask(pre)); err == nil {
+			entry.PreValue = b
+		}
+	}
+	if len(post) > 0 {
+		if b, err := json.Marshal(a.sensitive().Mask(post)); err == nil {
+			entry.PostValue = b
+		}
+	}
+}
+
+// sensitive 返回结合 schema 配置与全局敏感字段的匹配器
+func (a Audit) sensitive() audit.SensitiveMatcher {
+	return audit.SensitiveMatcher{Fields: a.SensitiveFields, NonFields: a.NonSensitiveFields}
 }
 
 // getPostValue 提取变更后的字段值
@@ -103,19 +212,104 @@ func getPostValue(m ent.Mutation) map[string]any {
 	fields := m.Fields()
 	for _, f := range fields {
 		if val, ok := m.Field(f); ok {
-			// 脱敏逻辑：敏感字段不记录明文
-			if isSensitiveField(f) {
-				changes[f] = "********"
-			} else {
-				changes[f] = val
-			}
+			changes[f] = val
 		}
 	}
+	for _, f := range m.ClearedFields() {
+		changes[f] = nil
+	}
 	return changes
 }
 
-// buildPostDataFromValue 尝试将返回实体序列化为 map[string]any，供 PostValue 使用
-func buildPostDataFromValue(value any) map[string]any {
+// oldFieldValues 通过 OldField 读取本次变更涉及字段的旧值（仅 UpdateOne 支持）
+func oldFieldValues(ctx context.Context, m ent.Mutation) map[string]any {
+	values := make(map[string]any)
+	fields := append(m.Fields(), m.ClearedFields()...)
+	for _, f := range fields {
+		if val, err := m.OldField(ctx, f); err == nil {
+			values[f] = val
+		}
+	}
+	return values
+}
+
+// mergeValues 将变更字段覆盖到旧值上，作为无法重新加载时的变更后数据
+func mergeValues(pre, changes map[string]any) map[string]any {
+	merged := make(map[string]any, len(pre)+len(changes))
+	for k, v := range pre {
+		merged[k] = v
+	}
+	for k, v := range changes {
+		merged[k] = v
+	}
+	return merged
+}
+
+// mutationIDs 调用生成代码中的 IDs(ctx) 获取受影响记录的 ID
+func mutationIDs(ctx context.Context, m ent.Mutation) ([]any, error) {
+	method := reflect.ValueOf(m).MethodByName("IDs")
+	if !method.IsValid() {
+		return nil, fmt.Errorf("mutation %T has no IDs method", m)
+	}
+
+	out := method.Call([]reflect.Value{reflect.ValueOf(ctx)})
+	if len(out) != 2 {
+		return nil, fmt.Errorf("unexpected IDs signature on %T", m)
+	}
+	if err, _ := out[1].Interface().(error); err != nil {
+		return nil, err
+	}
+
+	ids := out[0]
+	if ids.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("unexpected IDs result %s on %T", ids.Type(), m)
+	}
+	res := make([]any, ids.Len())
+	for i := range res {
+		res[i] = ids.Index(i).Interface()
+	}
+	return res, nil
+}
+
+// loadEntity 通过生成代码中的 m.Client().<Type>.Get(ctx, id) 加载记录，事务中的变更会使用同一事务
+func loadEntity(ctx context.Context, m ent.Mutation, id any) (map[string]any, error) {
+	clientFn := reflect.ValueOf(m).MethodByName("Client")
+	if !clientFn.IsValid() || clientFn.Type().NumIn() != 0 || clientFn.Type().NumOut() != 1 {
+		return nil, fmt.Errorf("mutation %T has no Client method", m)
+	}
+
+	client := reflect.Indirect(clientFn.Call(nil)[0])
+	if client.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unexpected client type %s", client.Type())
+	}
+	typeClient := client.FieldByName(m.Type())
+	if !typeClient.IsValid() {
+		return nil, fmt.Errorf("client has no %s field", m.Type())
+	}
+
+	get := typeClient.MethodByName("Get")
+	if !get.IsValid() || get.Type().NumIn() != 2 || get.Type().NumOut() != 2 {
+		return nil, fmt.Errorf("%s client has no Get method", m.Type())
+	}
+	idv := reflect.ValueOf(id)
+	if !idv.IsValid() || !idv.Type().AssignableTo(get.Type().In(1)) {
+		return nil, fmt.Errorf("unexpected id type %T", id)
+	}
+
+	out := get.Call([]reflect.Value{reflect.ValueOf(ctx), idv})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return nil, err
+	}
+
+	row := entityToMap(out[0].Interface())
+	if row == nil {
+		return nil, errors.New("entity is not serializable")
+	}
+	return row, nil
+}
+
+// entityToMap 尝试将返回实体序列化为 map[string]any，供 PreValue/PostValue 使用
+func entityToMap(value any) map[string]any {
 	if value == nil {
 		return nil
 	}
@@ -141,13 +335,6 @@ func buildPostDataFromValue(value any) map[string]any {
 	if err := json.Unmarshal(b, &m); err != nil {
 		return nil
 	}
-
-	// 脱敏敏感字段
-	for k := range m {
-		if isSensitiveField(k) {
-			m[k] = "********"
-		}
-	}
 	return m
 }
 
@@ -211,42 +398,7 @@ func extractTargetID(value any) string {
 	return fmt.Sprintf("%v", value)
 }
 
-var sensitiveFieldNames = map[string]struct{}{
-	// 凭据类
-	"password":    {},
-	"secret":      {},
-	"token":       {},
-	"api_key":     {},
-	"access_key":  {},
-	"secret_key":  {},
-	"private_key": {},
-	"salt":        {},
-	"session_id":  {},
-	"auth_code":   {},
-
-	// 个人隐私类 (PII)
-	"id_card":        {},
-	"id_number":      {},
-	"phone":          {},
-	"mobile":         {},
-	"bank_card":      {},
-	"card_number":    {},
-	"cvv":            {},
-	"address_detail": {},
-}
-
-// AddSensitiveField 添加敏感字段名
+// AddSensitiveField 添加全局敏感字段名
 func AddSensitiveField(f string) {
-	sensitiveFieldNames[f] = struct{}{}
-}
-
-// isSensitiveField 检查字段名是否为敏感字段
-func isSensitiveField(f string) bool {
-	f = strings.ToLower(f)
-	for name := range sensitiveFieldNames {
-		if strings.Contains(f, name) { // 匹配如 "user_password", "app_secret"
-			return true
-		}
-	}
-	return false
+	audit.AddSensitiveField(f)
 }
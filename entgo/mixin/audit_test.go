@@ -0,0 +1,147 @@
+package mixin_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/xiaoqidun/entps"
+
+	"github.com/tx7do/go-crud/audit"
+	"github.com/tx7do/go-crud/entgo/ent"
+	_ "github.com/tx7do/go-crud/entgo/ent/runtime"
+	"github.com/tx7do/go-crud/entgo/ent/user"
+	"github.com/tx7do/go-crud/entgo/mixin"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+// auditViewer 平台视图下需要审计的 Viewer
+type auditViewer struct{ viewer.Context }
+
+func (auditViewer) UserID() uint64          { return 7 }
+func (auditViewer) TraceID() string         { return "trace-audit" }
+func (auditViewer) IsPlatformContext() bool { return true }
+func (auditViewer) ShouldAudit() bool       { return true }
+
+// memoryAuditor 收集异步写入的审计日志
+type memoryAuditor struct {
+	mu      sync.Mutex
+	entries []*audit.Entry
+}
+
+func (a *memoryAuditor) Record(_ context.Context, e *audit.Entry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, e)
+	return nil
+}
+
+func (a *memoryAuditor) Flush(context.Context) error { return nil }
+
+// wait 等待收到 n 条审计日志并清空缓存
+func (a *memoryAuditor) wait(t *testing.T, n int) []*audit.Entry {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		a.mu.Lock()
+		if len(a.entries) >= n {
+			entries := a.entries
+			a.entries = nil
+			a.mu.Unlock()
+			if len(entries) != n {
+				t.Fatalf("expected %d audit entries, got %d", n, len(entries))
+			}
+			return entries
+		}
+		a.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d audit entries", n)
+	return nil
+}
+
+func decodeDiff(t *testing.T, e *audit.Entry) audit.Diff {
+	t.Helper()
+	var d audit.Diff
+	b, err := json.Marshal(e.Extra[audit.ExtraKeyDiff])
+	if err != nil {
+		t.Fatalf("marshal diff: %v", err)
+	}
+	if err = json.Unmarshal(b, &d); err != nil {
+		t.Fatalf("unmarshal diff: %v", err)
+	}
+	return d
+}
+
+func TestAudit_PreValueAndDiff(t *testing.T) {
+	client, err := ent.Open("sqlite3", "file:audit_mixin?mode=memory&cache=shared&_fk=1")
+	if err != nil {
+		t.Fatalf("open client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := viewer.WithContext(context.Background(), auditViewer{Context: viewer.NewNoopContext()})
+	if err = client.Schema.Create(ctx); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	client.User.Use(mixin.Audit{SensitiveFields: []string{"name"}}.Hooks()...)
+
+	auditor := &memoryAuditor{}
+	ctx = audit.WithAuditor(ctx, auditor)
+
+	// 创建：只有 PostValue
+	for _, name := range []string{"alice", "bob", "carol"} {
+		client.User.Create().SetName(name).SetAge(20).SaveX(ctx)
+	}
+	for _, e := range auditor.wait(t, 3) {
+		if e.PreValue != nil || e.PostValue == nil || e.TargetID == "" {
+			t.Fatalf("unexpected create entry: %+v", e)
+		}
+	}
+
+	// 批量更新：每个受影响的行一条日志，包含旧值与差异
+	client.User.Update().Where(user.AgeEQ(20)).SetAge(30).ExecX(ctx)
+	entries := auditor.wait(t, 3)
+	targets := map[string]bool{}
+	for _, e := range entries {
+		targets[e.TargetID] = true
+		if e.Operation != audit.Operation(ent.OpUpdate.String()) || e.PreValue == nil || e.PostValue == nil {
+			t.Fatalf("unexpected bulk update entry: %+v", e)
+		}
+		d := decodeDiff(t, e)
+		if len(d.Changed) != 1 {
+			t.Fatalf("expected only age to change, got %+v", d)
+		}
+		if c, ok := d.Changed["age"]; !ok || c.Old != float64(20) || c.New != float64(30) {
+			t.Fatalf("unexpected age change: %+v", d.Changed)
+		}
+	}
+	if len(targets) != 3 {
+		t.Fatalf("expected one entry per row, got targets %v", targets)
+	}
+
+	// UpdateOne：敏感字段在差异中保留但被脱敏
+	u := client.User.Query().Where(user.NameEQ("alice")).OnlyX(ctx)
+	client.User.UpdateOne(u).SetName("alice2").ExecX(ctx)
+	entries = auditor.wait(t, 1)
+	d := decodeDiff(t, entries[0])
+	if c, ok := d.Changed["name"]; !ok || c.Old != "********" || c.New != "********" {
+		t.Fatalf("expected masked name change, got %+v", d.Changed)
+	}
+	var pre map[string]any
+	_ = json.Unmarshal(entries[0].PreValue, &pre)
+	if pre["name"] != "********" || pre["age"] != float64(30) {
+		t.Fatalf("unexpected pre value: %v", pre)
+	}
+
+	// 批量删除：每行一条日志，只有 PreValue
+	client.User.Delete().Where(user.AgeEQ(30)).ExecX(ctx)
+	for _, e := range auditor.wait(t, 3) {
+		if e.PreValue == nil || e.PostValue != nil || e.Extra != nil {
+			t.Fatalf("unexpected delete entry: %+v", e)
+		}
+	}
+}
@@ -1,14 +1,24 @@
 package mixin
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
 	"entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
 	"entgo.io/ent/schema/mixin"
 
 	"github.com/tx7do/go-crud/entgo/interceptor"
+	"github.com/tx7do/go-crud/pagination/softdelete"
 )
 
 var _ ent.Mixin = (*SoftDelete)(nil)
 
+// SoftDelete 软删除 Mixin（deleted_at + uint32 的 deleted_by）：
+// 查询按 ctx 中的 softdelete.Mode 过滤，删除转换为更新 deleted_at 与 deleted_by，
+// 更新默认不作用于已软删除的记录；softdelete.HardDelete 的 ctx 执行物理删除。
 type SoftDelete struct {
 	mixin.Schema
 }
@@ -26,10 +36,17 @@ func (SoftDelete) Interceptors() []ent.Interceptor {
 	}
 }
 
+func (SoftDelete) Hooks() []ent.Hook {
+	return []ent.Hook{
+		SoftDeleteHook(func(uid uint64) any { return uint32(uid) }),
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 var _ ent.Mixin = (*SoftDelete64)(nil)
 
+// SoftDelete64 与 SoftDelete 相同，deleted_by 为 uint64
 type SoftDelete64 struct {
 	mixin.Schema
 }
@@ -46,3 +63,80 @@ func (SoftDelete64) Interceptors() []ent.Interceptor {
 		interceptor.SoftDeleteInterceptor(),
 	}
 }
+
+func (SoftDelete64) Hooks() []ent.Hook {
+	return []ent.Hook{
+		SoftDeleteHook(func(uid uint64) any { return uid }),
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+// softDeleteMutation 生成代码中 Mutation 支持软删除所需的方法
+type softDeleteMutation interface {
+	ent.Mutation
+	SetOp(ent.Op)
+	WhereP(...func(*sql.Selector))
+}
+
+// SoftDeleteHook 软删除钩子：
+//   - 删除转换为更新：写入 deleted_at，并在 ctx 中存在 Viewer 时通过 deletedBy 转换用户 ID 后写入 deleted_by（deletedBy 为 nil 时不写入）；
+//   - 删除与更新按 ctx 中的 softdelete.Mode 追加 deleted_at 条件，默认不作用于已软删除的记录；
+//   - softdelete.HardDelete 的 ctx 执行物理删除。
+func SoftDeleteHook(deletedBy func(uid uint64) any) ent.Hook {
+	return func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			op := m.Op()
+			if !op.Is(ent.OpUpdate | ent.OpUpdateOne | ent.OpDelete | ent.OpDeleteOne) {
+				return next.Mutate(ctx, m)
+			}
+
+			mx, ok := m.(softDeleteMutation)
+			if !ok {
+				return next.Mutate(ctx, m)
+			}
+
+			if p := interceptor.SoftDeletePredicate(softdelete.ModeFromContext(ctx), softdelete.DefaultDeletedAtColumn); p != nil {
+				mx.WhereP(p)
+			}
+
+			if !op.Is(ent.OpDelete|ent.OpDeleteOne) || softdelete.IsHardDelete(ctx) {
+				return next.Mutate(ctx, m)
+			}
+
+			if err := m.SetField(softdelete.DefaultDeletedAtColumn, time.Now()); err != nil {
+				return nil, err
+			}
+			if uid, ok := softdelete.DeletedBy(ctx); ok && deletedBy != nil {
+				if err := m.SetField(softdelete.DefaultDeletedByColumn, deletedBy(uid)); err != nil {
+					return nil, err
+				}
+			}
+
+			// 转换为批量更新后交给生成代码中的 Client 执行，DeleteOne 未命中时仍返回 NotFound
+			mx.SetOp(ent.OpUpdate)
+			return mutateWithClient(ctx, m)
+		})
+	}
+}
+
+// mutateWithClient 通过生成代码中的 m.Client().Mutate(ctx, m) 执行变更，事务中的变更会使用同一事务
+func mutateWithClient(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+	clientFn := reflect.ValueOf(m).MethodByName("Client")
+	if !clientFn.IsValid() || clientFn.Type().NumIn() != 0 || clientFn.Type().NumOut() != 1 {
+		return nil, fmt.Errorf("mutation %T has no Client method", m)
+	}
+
+	client := clientFn.Call(nil)[0]
+	mutate := client.MethodByName("Mutate")
+	if !mutate.IsValid() || mutate.Type().NumIn() != 2 || mutate.Type().NumOut() != 2 {
+		return nil, fmt.Errorf("client %s has no Mutate method", client.Type())
+	}
+
+	out := mutate.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(m)})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return nil, err
+	}
+	value, _ := out[0].Interface().(ent.Value)
+	return value, nil
+}
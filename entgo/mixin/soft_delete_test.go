@@ -0,0 +1,104 @@
+package mixin_test
+
+import (
+	"context"
+	"testing"
+
+	entgo "entgo.io/ent"
+	"entgo.io/ent/dialect/sql"
+
+	"github.com/tx7do/go-crud/entgo/mixin"
+	"github.com/tx7do/go-crud/pagination/softdelete"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+// fakeMutation 模拟生成代码中的 Mutation，只实现软删除钩子用到的方法
+type fakeMutation struct {
+	entgo.Mutation
+	op     entgo.Op
+	fields map[string]entgo.Value
+	preds  []func(*sql.Selector)
+	client *fakeClient
+}
+
+func (m *fakeMutation) Op() entgo.Op                     { return m.op }
+func (m *fakeMutation) SetOp(op entgo.Op)                { m.op = op }
+func (m *fakeMutation) WhereP(ps ...func(*sql.Selector)) { m.preds = append(m.preds, ps...) }
+func (m *fakeMutation) Client() *fakeClient              { return m.client }
+
+func (m *fakeMutation) SetField(name string, v entgo.Value) error {
+	m.fields[name] = v
+	return nil
+}
+
+// where 渲染 WhereP 注入的条件
+func (m *fakeMutation) where() string {
+	s := sql.Select("*").From(sql.Table("t"))
+	for _, p := range m.preds {
+		p(s)
+	}
+	query, _ := s.Query()
+	return query
+}
+
+type fakeClient struct{ ops []entgo.Op }
+
+func (c *fakeClient) Mutate(_ context.Context, m entgo.Mutation) (entgo.Value, error) {
+	c.ops = append(c.ops, m.Op())
+	return 1, nil
+}
+
+// deleterViewer 指定用户 ID 的 Viewer
+type deleterViewer struct{ viewer.Context }
+
+func (deleterViewer) UserID() uint64 { return 7 }
+
+func TestSoftDeleteHook(t *testing.T) {
+	var nextOps []entgo.Op
+	next := entgo.MutateFunc(func(_ context.Context, m entgo.Mutation) (entgo.Value, error) {
+		nextOps = append(nextOps, m.Op())
+		return 1, nil
+	})
+	mutator := mixin.SoftDelete{}.Hooks()[0](next)
+
+	newMutation := func(op entgo.Op) *fakeMutation {
+		return &fakeMutation{op: op, fields: map[string]entgo.Value{}, client: &fakeClient{}}
+	}
+	ctx := viewer.WithContext(context.Background(), deleterViewer{Context: viewer.NewNoopContext()})
+
+	// 删除转换为更新，写入删除时间与删除者，并排除已删除的记录
+	m := newMutation(entgo.OpDeleteOne)
+	if _, err := mutator.Mutate(ctx, m); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	if len(nextOps) != 0 || len(m.client.ops) != 1 || m.client.ops[0] != entgo.OpUpdate {
+		t.Fatalf("delete should be executed as update by client: next=%v client=%v", nextOps, m.client.ops)
+	}
+	if _, ok := m.fields["deleted_at"]; !ok || m.fields["deleted_by"] != uint32(7) {
+		t.Fatalf("deleted_at/deleted_by not stamped: %v", m.fields)
+	}
+	if got := m.where(); got != "SELECT * FROM `t` WHERE `t`.`deleted_at` IS NULL" {
+		t.Fatalf("unexpected where: %s", got)
+	}
+
+	// 物理删除
+	m = newMutation(entgo.OpDelete)
+	if _, err := mutator.Mutate(softdelete.HardDelete(softdelete.OnlyDeleted(ctx)), m); err != nil {
+		t.Fatalf("hard delete: %v", err)
+	}
+	if len(nextOps) != 1 || nextOps[0] != entgo.OpDelete || len(m.fields) != 0 || len(m.client.ops) != 0 {
+		t.Fatalf("hard delete should pass through: next=%v fields=%v", nextOps, m.fields)
+	}
+	if got := m.where(); got != "SELECT * FROM `t` WHERE `t`.`deleted_at` IS NOT NULL" {
+		t.Fatalf("unexpected where: %s", got)
+	}
+
+	// 更新按模式过滤
+	m = newMutation(entgo.OpUpdate)
+	if _, err := mutator.Mutate(softdelete.WithDeleted(ctx), m); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if len(m.preds) != 0 || len(nextOps) != 2 {
+		t.Fatalf("with deleted should not filter updates: %s", m.where())
+	}
+}
@@ -6,11 +6,10 @@ import (
 	"entgo.io/ent/schema/mixin"
 )
 
-// 注意：使用此 Mixin 时，需要在 Ent 客户端配置 Hook 或 Interceptor
-// 来在更新操作前自动检查并递增版本号。
-
 var _ ent.Mixin = (*Version)(nil)
 
+// Version 版本号/乐观锁 Mixin：通过 Repository 的 UpdateOne/UpdateX 更新时，
+// 若 DTO 携带了版本号，会自动追加 version = ? 条件并递增版本号，冲突时返回 crud.ErrVersionConflict。
 type Version struct{ mixin.Schema }
 
 func (Version) Fields() []ent.Field {
@@ -0,0 +1,151 @@
+package entgo
+
+import (
+	"reflect"
+	"strings"
+
+	"entgo.io/ent/dialect/sql"
+
+	crud "github.com/tx7do/go-crud"
+)
+
+// DefaultVersionField 乐观锁默认使用的版本字段，与 mixin.Version 一致
+const DefaultVersionField = "version"
+
+// WithVersionField 设置乐观锁使用的版本字段，为空时关闭乐观锁
+func (r *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) WithVersionField(name string) *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+] {
+	r.versionField = name
+	return r
+}
+
+// WithConflictRetry 设置乐观锁冲突时的重试：重新读取最新记录后交给 resolve 重新应用变更，最多重试 maxRetries 次。
+// builder 执行后无法复用，重试由 RepositoryAdapter.Update 完成，直接调用 UpdateOne/UpdateX 时不会重试。
+func (r *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) WithConflictRetry(maxRetries int, resolve crud.ConflictResolver[DTO]) *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+] {
+	r.conflictRetries = maxRetries
+	r.conflictResolver = resolve
+	return r
+}
+
+// versionLock 返回乐观锁的版本条件与版本号递增修饰函数；
+// 未启用乐观锁、实体不包含版本字段或 dto 未携带版本号（零值）时 locked 为 false
+func (r *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) versionLock(dto *DTO) (where PREDICATE, bump func(*sql.UpdateBuilder), locked bool, err error) {
+	if r.versionField == "" || dto == nil {
+		return where, nil, false, nil
+	}
+	if _, ok := structField(reflect.ValueOf(new(ENTITY)), r.versionField); !ok {
+		return where, nil, false, nil
+	}
+
+	version, ok := uintField(reflect.ValueOf(dto), r.versionField)
+	if !ok {
+		return where, nil, false, nil
+	}
+
+	if where, err = selectorPredicate[PREDICATE](versionEQ(r.versionField, version)); err != nil {
+		return where, nil, false, err
+	}
+	return where, versionIncr(r.versionField), true, nil
+}
+
+// versionEQ 版本条件：column = version
+func versionEQ(column string, version uint64) func(*sql.Selector) {
+	return func(s *sql.Selector) {
+		s.Where(sql.EQ(s.C(column), version))
+	}
+}
+
+// versionIncr 将版本号加 1，覆盖 builder 中已设置的版本号
+func versionIncr(column string) func(*sql.UpdateBuilder) {
+	return func(u *sql.UpdateBuilder) {
+		u.Set(column, sql.ExprFunc(func(b *sql.Builder) {
+			b.Ident(column).WriteString(" + 1")
+		}))
+	}
+}
+
+// structField 按 json 标签或字段名（忽略大小写与下划线）查找结构体字段
+func structField(v reflect.Value, name string) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	want := strings.ReplaceAll(strings.ToLower(name), "_", "")
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if tag == name || strings.ReplaceAll(strings.ToLower(sf.Name), "_", "") == want {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// uintField 读取结构体中的整数字段（可为指针），字段不存在或为零值时返回 false
+func uintField(v reflect.Value, name string) (uint64, bool) {
+	f, ok := structField(v, name)
+	if !ok {
+		return 0, false
+	}
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return 0, false
+		}
+		f = f.Elem()
+	}
+
+	var n uint64
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = uint64(f.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = f.Uint()
+	default:
+		return 0, false
+	}
+	return n, n != 0
+}
+
+// isNotFound 判断生成代码中的 NotFoundError（泛型代码无法引用生成的类型，按错误信息判断）
+func isNotFound(err error) bool {
+	return err != nil && strings.HasSuffix(err.Error(), " not found")
+}
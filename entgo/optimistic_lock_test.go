@@ -0,0 +1,73 @@
+package entgo
+
+import (
+	"testing"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/tx7do/go-utils/mapper"
+
+	"github.com/tx7do/go-crud/entgo/ent"
+	"github.com/tx7do/go-crud/entgo/ent/predicate"
+	"github.com/tx7do/go-crud/entgo/ent/user"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+func TestRepository_VersionLock(t *testing.T) {
+	cli := createTestEntClient(t)
+	defer cli.Close()
+
+	ctx := viewer.WithContext(t.Context(), testContext{})
+	created, err := cli.Client().User.Create().SetName("lock_a").SetAge(3).Save(ctx)
+	if err != nil {
+		t.Fatalf("failed creating user: %v", err)
+	}
+
+	// 以 age 作为版本字段
+	repo := NewRepository[
+		ent.UserQuery, ent.UserSelect,
+		ent.UserCreate, ent.UserCreateBulk,
+		ent.UserUpdate, ent.UserUpdateOne,
+		ent.UserDelete,
+		predicate.User, ent.User, ent.User,
+	](mapper.NewCopierMapper[ent.User, ent.User]()).WithVersionField("age")
+
+	if _, _, locked, _ := repo.versionLock(&ent.User{Name: "x"}); locked {
+		t.Fatal("dto without version should not be locked")
+	}
+
+	where, bump, locked, err := repo.versionLock(&ent.User{Age: 3})
+	if err != nil || !locked {
+		t.Fatalf("version lock: %v, %v", locked, err)
+	}
+
+	u := sql.Update("users").Set("name", "x").Set("age", 3)
+	bump(u)
+	if query, _ := u.Query(); query != "UPDATE `users` SET `name` = ?, `age` = `age` + 1" {
+		t.Fatalf("unexpected update: %s", query)
+	}
+
+	// 版本一致时更新成功并递增版本号
+	affected, err := cli.Client().User.Update().Where(user.NameEQ("lock_a"), where).Modify(bump).SetName("lock_b").Save(ctx)
+	if err != nil || affected != 1 {
+		t.Fatalf("update: %d, %v", affected, err)
+	}
+	got, err := cli.Client().User.Get(ctx, created.ID)
+	if err != nil || got.Age != 4 || got.Name != "lock_b" {
+		t.Fatalf("updated user: %+v, %v", got, err)
+	}
+
+	// 过期版本号不更新任何记录，UpdateOne 返回 NotFound
+	affected, err = cli.Client().User.Update().Where(user.IDEQ(created.ID), where).Modify(bump).SetName("lock_c").Save(ctx)
+	if err != nil || affected != 0 {
+		t.Fatalf("stale update: %d, %v", affected, err)
+	}
+	_, err = cli.Client().User.UpdateOneID(created.ID).Where(where).Modify(bump).SetName("lock_c").Save(ctx)
+	if !isNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	repo.WithVersionField("")
+	if _, _, locked, _ = repo.versionLock(&ent.User{Age: 3}); locked {
+		t.Fatal("empty version field should disable locking")
+	}
+}
@@ -0,0 +1,187 @@
+package entgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"entgo.io/ent"
+
+	"github.com/tx7do/go-crud/outbox"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+// OutboxHookOption OutboxHook 的选项
+type OutboxHookOption func(h *outboxHook)
+
+// WithOutboxEventType 设置事件类型的生成函数，默认为 outbox.EventType（<Type>.<operation>，如 User.created）
+func WithOutboxEventType(fn func(aggregate string, op outbox.Operation) string) OutboxHookOption {
+	return func(h *outboxHook) {
+		if fn != nil {
+			h.eventType = fn
+		}
+	}
+}
+
+type outboxHook struct {
+	store     *OutboxStore
+	drv       *TxDriver
+	eventType func(aggregate string, op outbox.Operation) string
+}
+
+// outboxMutationKey ctx 中正在生成事件的 Mutation，避免软删除等钩子转交 Client 重新执行时重复生成事件
+type outboxMutationKey struct{}
+
+// OutboxHook 事务性发件箱钩子，通过 client.Use 或 client.<Type>.Use 注册：
+//   - 在 RunInTx 中执行变更，并以同一事务写入事件（Aggregate 为 Mutation 类型，AggregateID 为 ID）；
+//     ctx 中已存在 RunInTx 的事务时加入该事务，写入失败时整体回滚；
+//   - 创建与单条更新的 Payload 为实体的 JSON，批量更新为本次设置的字段，删除不含 Payload；
+//   - store 须基于创建 client 的同一个 TxDriver，事务请使用 RunInTx 而不是生成代码中的 client.Tx。
+func OutboxHook(store *OutboxStore, opts ...OutboxHookOption) ent.Hook {
+	h := &outboxHook{store: store, eventType: outbox.EventType}
+	if store != nil {
+		h.drv, _ = store.drv.(*TxDriver)
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(h)
+		}
+	}
+
+	return func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			op := m.Op()
+			if !op.Is(ent.OpCreate|ent.OpUpdate|ent.OpUpdateOne|ent.OpDelete|ent.OpDeleteOne) ||
+				ctx.Value(outboxMutationKey{}) == m {
+				return next.Mutate(ctx, m)
+			}
+			if h.drv == nil {
+				return nil, errors.New("outbox hook requires an OutboxStore created with a TxDriver")
+			}
+
+			var value ent.Value
+			err := RunInTx(ctx, h.drv, func(ctx context.Context) error {
+				ctx = context.WithValue(ctx, outboxMutationKey{}, m)
+
+				var ids []any
+				if !op.Is(ent.OpCreate) {
+					var err error
+					if ids, err = mutationIDs(ctx, m); err != nil {
+						return err
+					}
+				}
+
+				var err error
+				if value, err = next.Mutate(ctx, m); err != nil {
+					return err
+				}
+
+				// 软删除等钩子可能改写 m.Op()，按变更前的操作生成事件
+				events, err := h.events(ctx, m, op, value, ids)
+				if err != nil {
+					return err
+				}
+				if err = h.store.Enqueue(ctx, events...); err != nil {
+					return fmt.Errorf("outbox: enqueue events: %w", err)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			return value, nil
+		})
+	}
+}
+
+// events 根据变更结果生成事件，op 与 ids 为变更前的操作与受影响的 ID
+func (h *outboxHook) events(ctx context.Context, m ent.Mutation, op ent.Op, value ent.Value, ids []any) ([]*outbox.Event, error) {
+	switch {
+	case op.Is(ent.OpCreate):
+		e, err := h.newEvent(ctx, m, outbox.OperationCreated, entityID(value), value)
+		if err != nil {
+			return nil, err
+		}
+		return []*outbox.Event{e}, nil
+
+	case op.Is(ent.OpUpdateOne):
+		e, err := h.newEvent(ctx, m, outbox.OperationUpdated, entityID(value), value)
+		if err != nil {
+			return nil, err
+		}
+		return []*outbox.Event{e}, nil
+	}
+
+	action, payload := outbox.OperationDeleted, any(nil)
+	if op.Is(ent.OpUpdate) {
+		changes := make(map[string]any, len(m.Fields()))
+		for _, name := range m.Fields() {
+			changes[name], _ = m.Field(name)
+		}
+		action, payload = outbox.OperationUpdated, changes
+	}
+
+	events := make([]*outbox.Event, 0, len(ids))
+	for _, id := range ids {
+		e, err := h.newEvent(ctx, m, action, fmt.Sprintf("%v", id), payload)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (h *outboxHook) newEvent(ctx context.Context, m ent.Mutation, op outbox.Operation, id string, payload any) (*outbox.Event, error) {
+	e := &outbox.Event{
+		Aggregate:   m.Type(),
+		AggregateID: id,
+		Type:        h.eventType(m.Type(), op),
+	}
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("outbox: marshal payload: %w", err)
+		}
+		e.Payload = b
+	}
+	if vc, ok := viewer.FromContext(ctx); ok {
+		e.TenantID = vc.TenantID()
+		e.TraceID = vc.TraceID()
+	}
+	return e, nil
+}
+
+// mutationIDs 调用生成代码中的 IDs(ctx) 获取受影响记录的 ID
+func mutationIDs(ctx context.Context, m ent.Mutation) ([]any, error) {
+	method := reflect.ValueOf(m).MethodByName("IDs")
+	if !method.IsValid() || method.Type().NumIn() != 1 || method.Type().NumOut() != 2 {
+		return nil, fmt.Errorf("mutation %T has no IDs method", m)
+	}
+
+	out := method.Call([]reflect.Value{reflect.ValueOf(ctx)})
+	if err, _ := out[1].Interface().(error); err != nil {
+		return nil, err
+	}
+
+	ids := out[0]
+	if ids.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("unexpected IDs result %s on %T", ids.Type(), m)
+	}
+	res := make([]any, ids.Len())
+	for i := range res {
+		res[i] = ids.Index(i).Interface()
+	}
+	return res, nil
+}
+
+// entityID 读取实体的 ID 字段
+func entityID(value ent.Value) string {
+	f, ok := structField(reflect.ValueOf(value), "id")
+	if !ok || !f.CanInterface() {
+		return ""
+	}
+	return fmt.Sprintf("%v", f.Interface())
+}
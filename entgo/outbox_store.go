@@ -0,0 +1,270 @@
+package entgo
+
+import (
+	"context"
+	stdsql "database/sql"
+	"errors"
+	"time"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+	"entgo.io/ent/dialect/sql/schema"
+	"entgo.io/ent/schema/field"
+
+	"github.com/tx7do/go-crud/outbox"
+)
+
+// outboxColumns 发件箱表的列，顺序与 scanOutboxEvent 一致
+var outboxColumns = []string{
+	"id", "aggregate", "aggregate_id", "type", "payload",
+	"tenant_id", "trace_id",
+	"status", "attempts", "next_attempt_at", "last_error",
+	"created_at", "published_at",
+}
+
+// OutboxTable 返回发件箱表的结构定义，可加入生成代码中的 migrate.Tables 一并迁移
+func OutboxTable(name string) *schema.Table {
+	if name == "" {
+		name = outbox.DefaultTable
+	}
+
+	id := &schema.Column{Name: "id", Type: field.TypeUint64, Increment: true}
+	status := &schema.Column{Name: "status", Type: field.TypeInt32, Default: int32(outbox.StatusPending)}
+
+	t := schema.NewTable(name).
+		AddPrimary(id).
+		AddColumn(&schema.Column{Name: "aggregate", Type: field.TypeString, Size: 128}).
+		AddColumn(&schema.Column{Name: "aggregate_id", Type: field.TypeString, Size: 128}).
+		AddColumn(&schema.Column{Name: "type", Type: field.TypeString, Size: 255}).
+		AddColumn(&schema.Column{Name: "payload", Type: field.TypeString, Size: 2147483647, Nullable: true}).
+		AddColumn(&schema.Column{Name: "tenant_id", Type: field.TypeUint64, Default: 0}).
+		AddColumn(&schema.Column{Name: "trace_id", Type: field.TypeString, Size: 64, Default: ""}).
+		AddColumn(status).
+		AddColumn(&schema.Column{Name: "attempts", Type: field.TypeInt32, Default: 0}).
+		AddColumn(&schema.Column{Name: "next_attempt_at", Type: field.TypeTime, Nullable: true}).
+		AddColumn(&schema.Column{Name: "last_error", Type: field.TypeString, Size: 2147483647, Nullable: true}).
+		AddColumn(&schema.Column{Name: "created_at", Type: field.TypeTime}).
+		AddColumn(&schema.Column{Name: "published_at", Type: field.TypeTime, Nullable: true})
+	t.Indexes = append(t.Indexes, &schema.Index{
+		Name:    name + "_status_id",
+		Columns: []*schema.Column{status, id},
+	})
+	return t
+}
+
+// OutboxStore 基于 Ent 驱动的发件箱存储，与 outbox.NewRelay 配合使用。
+// 使用 TxDriver 时，在 RunInTx 中调用 Enqueue 会与业务写入使用同一事务。
+type OutboxStore struct {
+	drv   dialect.Driver
+	table string
+}
+
+var _ outbox.Store = (*OutboxStore)(nil)
+
+// NewOutboxStore 创建发件箱存储，table 为空时使用 outbox.DefaultTable
+func NewOutboxStore(drv dialect.Driver, table string) *OutboxStore {
+	if table == "" {
+		table = outbox.DefaultTable
+	}
+	return &OutboxStore{drv: drv, table: table}
+}
+
+// Migrate 创建或更新发件箱表
+func (s *OutboxStore) Migrate(ctx context.Context) error {
+	if s.drv == nil {
+		return errors.New("driver is nil")
+	}
+	m, err := schema.NewMigrate(s.drv)
+	if err != nil {
+		return err
+	}
+	return m.Create(ctx, OutboxTable(s.table))
+}
+
+// Enqueue 写入待投递事件，并回填自增 ID
+func (s *OutboxStore) Enqueue(ctx context.Context, events ...*outbox.Event) error {
+	if s.drv == nil {
+		return errors.New("driver is nil")
+	}
+
+	for _, e := range events {
+		if e == nil {
+			continue
+		}
+		if e.CreatedAt.IsZero() {
+			e.CreatedAt = time.Now()
+		}
+
+		var nextAttemptAt *time.Time
+		if !e.NextAttemptAt.IsZero() {
+			nextAttemptAt = &e.NextAttemptAt
+		}
+		builder := sql.Dialect(s.drv.Dialect()).
+			Insert(s.table).
+			Columns(outboxColumns[1:]...).
+			Values(
+				e.Aggregate, e.AggregateID, e.Type, string(e.Payload),
+				e.TenantID, e.TraceID,
+				int32(e.Status), int32(e.Attempts), nextAttemptAt, e.LastError,
+				e.CreatedAt, e.PublishedAt,
+			)
+
+		id, err := s.insert(ctx, builder)
+		if err != nil {
+			return err
+		}
+		e.ID = id
+	}
+	return nil
+}
+
+// insert 执行插入并返回自增 ID：PostgreSQL 使用 RETURNING，其它方言使用 LastInsertId
+func (s *OutboxStore) insert(ctx context.Context, builder *sql.InsertBuilder) (uint64, error) {
+	if s.drv.Dialect() == dialect.Postgres {
+		query, args := builder.Returning("id").Query()
+		rows := &sql.Rows{}
+		if err := s.drv.Query(ctx, query, args, rows); err != nil {
+			return 0, err
+		}
+		defer rows.Close()
+
+		var id uint64
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return 0, err
+			}
+			return 0, errors.New("insert outbox event returned no id")
+		}
+		if err := rows.Scan(&id); err != nil {
+			return 0, err
+		}
+		return id, rows.Close()
+	}
+
+	query, args := builder.Query()
+	var res stdsql.Result
+	if err := s.drv.Exec(ctx, query, args, &res); err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+
+func (s *OutboxStore) Fetch(ctx context.Context, now time.Time, limit int) ([]*outbox.Event, error) {
+	if s.drv == nil {
+		return nil, errors.New("driver is nil")
+	}
+
+	d := sql.Dialect(s.drv.Dialect())
+	pending := int32(outbox.StatusPending)
+	o := d.Table(s.table).As("o")
+	b := d.Table(s.table).As("b")
+
+	// 同一聚合中存在未到期（ID 不大于当前事件）的待投递事件时，当前事件不可投递
+	notDue := d.Select().AppendSelectExpr(sql.Expr("1")).From(b).Where(sql.And(
+		sql.EQ(b.C("status"), pending),
+		sql.ColumnsEQ(b.C("aggregate"), o.C("aggregate")),
+		sql.ColumnsEQ(b.C("aggregate_id"), o.C("aggregate_id")),
+		sql.ColumnsLTE(b.C("id"), o.C("id")),
+		sql.GT(b.C("next_attempt_at"), now),
+	))
+
+	columns := make([]string, 0, len(outboxColumns))
+	for _, c := range outboxColumns {
+		columns = append(columns, o.C(c))
+	}
+	query, args := d.Select(columns...).
+		From(o).
+		Where(sql.And(
+			sql.EQ(o.C("status"), pending),
+			sql.NotExists(notDue),
+		)).
+		OrderBy(o.C("id")).
+		Limit(limit).
+		Query()
+
+	rows := &sql.Rows{}
+	if err := s.drv.Query(ctx, query, args, rows); err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*outbox.Event
+	for rows.Next() {
+		e, err := scanOutboxEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// scanOutboxEvent 按 outboxColumns 的顺序读取一行
+func scanOutboxEvent(rows *sql.Rows) (*outbox.Event, error) {
+	var (
+		e                          outbox.Event
+		payload, lastError         stdsql.NullString
+		status, attempts           int32
+		nextAttemptAt, publishedAt stdsql.NullTime
+	)
+	if err := rows.Scan(
+		&e.ID, &e.Aggregate, &e.AggregateID, &e.Type, &payload,
+		&e.TenantID, &e.TraceID,
+		&status, &attempts, &nextAttemptAt, &lastError,
+		&e.CreatedAt, &publishedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if payload.String != "" {
+		e.Payload = []byte(payload.String)
+	}
+	e.LastError = lastError.String
+	e.Status = outbox.Status(status)
+	e.Attempts = int(attempts)
+	if nextAttemptAt.Valid {
+		e.NextAttemptAt = nextAttemptAt.Time
+	}
+	if publishedAt.Valid {
+		e.PublishedAt = &publishedAt.Time
+	}
+	return &e, nil
+}
+
+func (s *OutboxStore) MarkPublished(ctx context.Context, id uint64) error {
+	return s.update(ctx, id, func(u *sql.UpdateBuilder) {
+		u.Set("status", int32(outbox.StatusPublished)).Set("published_at", time.Now())
+	})
+}
+
+func (s *OutboxStore) MarkFailed(ctx context.Context, id uint64, attempts int, nextAttemptAt time.Time, lastErr string) error {
+	return s.update(ctx, id, func(u *sql.UpdateBuilder) {
+		u.Set("attempts", int32(attempts)).Set("next_attempt_at", nextAttemptAt).Set("last_error", lastErr)
+	})
+}
+
+func (s *OutboxStore) MarkDead(ctx context.Context, id uint64, attempts int, lastErr string) error {
+	return s.update(ctx, id, func(u *sql.UpdateBuilder) {
+		u.Set("status", int32(outbox.StatusDead)).Set("attempts", int32(attempts)).Set("last_error", lastErr)
+	})
+}
+
+func (s *OutboxStore) update(ctx context.Context, id uint64, set func(u *sql.UpdateBuilder)) error {
+	if s.drv == nil {
+		return errors.New("driver is nil")
+	}
+
+	u := sql.Dialect(s.drv.Dialect()).Update(s.table)
+	set(u)
+	query, args := u.Where(sql.EQ("id", id)).Query()
+
+	var res stdsql.Result
+	return s.drv.Exec(ctx, query, args, &res)
+}
@@ -0,0 +1,114 @@
+package entgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tx7do/go-crud/entgo/ent"
+	"github.com/tx7do/go-crud/entgo/ent/migrate"
+	"github.com/tx7do/go-crud/entgo/ent/user"
+	"github.com/tx7do/go-crud/outbox"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+func TestOutboxHook(t *testing.T) {
+	drv, err := CreateDriver("sqlite3", "file:ent_outbox?mode=memory&cache=shared&_fk=1", false, false)
+	if err != nil {
+		t.Fatalf("failed opening connection to db: %v", err)
+	}
+	drv.DB().SetMaxOpenConns(1)
+
+	txDrv := NewTxDriver(drv)
+	client := ent.NewClient(ent.Driver(txDrv))
+	defer client.Close()
+
+	ctx := viewer.WithContext(t.Context(), testContext{})
+	if err = client.Schema.Create(ctx, migrate.WithForeignKeys(true)); err != nil {
+		t.Fatalf("failed creating schema resources: %v", err)
+	}
+	store := NewOutboxStore(txDrv, "")
+	if err = store.Migrate(ctx); err != nil {
+		t.Fatalf("migrate outbox: %v", err)
+	}
+	client.User.Use(OutboxHook(store))
+
+	// 写入与事件在同一事务中提交
+	u, err := client.User.Create().SetName("outbox_a").SetAge(1).Save(ctx)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err = client.User.UpdateOne(u).SetAge(2).Save(ctx); err != nil {
+		t.Fatalf("update one: %v", err)
+	}
+	if _, err = client.User.Update().Where(user.NameEQ("outbox_a")).SetAge(3).Save(ctx); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err = client.User.DeleteOne(u).Exec(ctx); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	// 业务事务回滚时事件一并回滚
+	errBoom := errors.New("boom")
+	err = RunInTx(ctx, txDrv, func(ctx context.Context) error {
+		if _, err := client.User.Create().SetName("outbox_rollback").Save(ctx); err != nil {
+			return err
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected boom, got %v", err)
+	}
+
+	events, err := store.Fetch(ctx, time.Now(), 10)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Type)
+		if e.AggregateID != "1" || e.TenantID != 1 {
+			t.Fatalf("unexpected event: %+v", e)
+		}
+	}
+	want := []string{"User.created", "User.updated", "User.updated", "User.deleted"}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected events: %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("event %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	var payload map[string]any
+	if err = json.Unmarshal(events[2].Payload, &payload); err != nil || payload["age"] != float64(3) {
+		t.Fatalf("unexpected update payload: %s, %v", events[2].Payload, err)
+	}
+
+	// 等待重试的事件及同一聚合的后续事件不返回
+	now := time.Now()
+	if err = store.MarkFailed(ctx, events[0].ID, 1, now.Add(time.Minute), "broker unavailable"); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	if due, err := store.Fetch(ctx, now, 10); err != nil || len(due) != 0 {
+		t.Fatalf("events behind a pending retry should not be due: %d, %v", len(due), err)
+	}
+	if err = store.MarkFailed(ctx, events[0].ID, 1, now, "broker unavailable"); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+
+	// Relay 投递并标记完成
+	ch := outbox.NewChannelPublisher(len(events))
+	if _, err = outbox.NewRelay(store, ch).Process(ctx); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if len(ch.Events()) != len(events) {
+		t.Fatalf("published %d events, want %d", len(ch.Events()), len(events))
+	}
+	if pending, err := store.Fetch(ctx, time.Now(), 10); err != nil || len(pending) != 0 {
+		t.Fatalf("events should be published: %d, %v", len(pending), err)
+	}
+}
@@ -1,63 +1,114 @@
 package pagination
 
 import (
-	"encoding/base64"
-
 	"entgo.io/ent/dialect/sql"
-	"github.com/tx7do/go-crud/pagination"
-
-	"github.com/go-kratos/kratos/v2/encoding"
-	_ "github.com/go-kratos/kratos/v2/encoding/json"
 
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/keyset"
 	"github.com/tx7do/go-crud/pagination/paginator"
 )
 
-// TokenPaginator 基于 Token 的分页器
+// TokenPaginator 基于 Token 的 keyset（seek）分页器
+// token 中记录了上一页边界行在所有排序列（含 tiebreaker）上的值
 type TokenPaginator struct {
-	impl  pagination.Paginator
-	codec encoding.Codec
+	impl       pagination.Paginator
+	tiebreaker string
+	codec      pagination.CursorCodec
 }
 
 func NewTokenPaginator() *TokenPaginator {
 	return &TokenPaginator{
-		impl:  paginator.NewTokenPaginatorWithDefault(),
-		codec: encoding.GetCodec("json"),
+		impl:       paginator.NewTokenPaginatorWithDefault(),
+		tiebreaker: keyset.DefaultTiebreaker,
+		codec:      pagination.DefaultCursorCodec(),
 	}
 }
 
-func (p *TokenPaginator) BuildSelector(token string, pageSize int) func(*sql.Selector) {
+// WithTiebreaker 设置保证排序唯一性的列（默认 id）
+func (p *TokenPaginator) WithTiebreaker(column string) *TokenPaginator {
+	p.tiebreaker = column
+	return p
+}
+
+// WithCursorCodec 设置 token 的编解码器（签名/加密），为 nil 时使用默认编解码器
+func (p *TokenPaginator) WithCursorCodec(codec pagination.CursorCodec) *TokenPaginator {
+	if codec == nil {
+		codec = pagination.DefaultCursorCodec()
+	}
+	p.codec = codec
+	return p
+}
+
+// BuildSeek 根据传入 token/pageSize/排序更新状态并解析出本次查询的 keyset 状态。
+// scope 为 token 绑定的查询范围（见 pagination.CursorScope），在其他查询中复用 token 将返回错误。
+func (p *TokenPaginator) BuildSeek(token string, pageSize int, sortings []*paginationV1.Sorting, scope []byte) (*keyset.Seek, error) {
 	p.impl.
 		WithToken(token).
-		WithPage(pageSize)
+		WithSize(pageSize)
 
-	type cursor struct {
-		LastID int64 `json:"last_id"`
-	}
+	return keyset.NewSeek(token, p.impl.Size(), sortings, p.tiebreaker,
+		keyset.WithCodec(p.codec),
+		keyset.WithScope(scope),
+	)
+}
 
-	// 无 token 或解码失败时只应用 pageSize
-	if token == "" {
-		return func(s *sql.Selector) {
-			s.Limit(p.impl.Size())
+// BuildSelector 返回应用 keyset 条件、排序与 limit 的选择器（多取一条用于判断是否存在下一页）
+func (p *TokenPaginator) BuildSelector(seek *keyset.Seek) func(*sql.Selector) {
+	return func(s *sql.Selector) {
+		if seek == nil {
+			return
+		}
+
+		if pred := buildSeekPredicate(s, seek); pred != nil {
+			s.Where(pred)
 		}
-	}
 
-	b, err := base64.StdEncoding.DecodeString(token)
-	if err != nil {
-		return func(s *sql.Selector) {
-			s.Limit(p.impl.Size())
+		for _, c := range seek.OrderBy() {
+			if c.Desc {
+				s.OrderBy(sql.Desc(s.C(c.Field)))
+			} else {
+				s.OrderBy(sql.Asc(s.C(c.Field)))
+			}
 		}
+
+		s.Limit(seek.Limit())
 	}
+}
 
-	var c cursor
-	if err = p.codec.Unmarshal(b, &c); err != nil {
-		return func(s *sql.Selector) {
-			s.Limit(p.impl.Size())
+// buildSeekPredicate 构建 keyset 条件：方向一致时使用行值比较，否则展开为 OR 形式
+func buildSeekPredicate(s *sql.Selector, seek *keyset.Seek) *sql.Predicate {
+	if seek.Cursor == nil {
+		return nil
+	}
+
+	if seek.Uniform() {
+		order := seek.OrderBy()
+		columns := make([]string, 0, len(order))
+		for _, c := range order {
+			columns = append(columns, s.C(c.Field))
+		}
+		if order[0].Desc {
+			return sql.CompositeLT(columns, seek.Cursor.Values...)
 		}
+		return sql.CompositeGT(columns, seek.Cursor.Values...)
 	}
 
-	lastID := c.LastID
-	return func(s *sql.Selector) {
-		s.Where(sql.GT("id", lastID))
-		s.Limit(p.impl.Size())
+	groups := seek.Conditions()
+	ors := make([]*sql.Predicate, 0, len(groups))
+	for _, group := range groups {
+		ands := make([]*sql.Predicate, 0, len(group))
+		for _, t := range group {
+			switch t.Op {
+			case keyset.OpGT:
+				ands = append(ands, sql.GT(s.C(t.Field), t.Value))
+			case keyset.OpLT:
+				ands = append(ands, sql.LT(s.C(t.Field), t.Value))
+			default:
+				ands = append(ands, sql.EQ(s.C(t.Field), t.Value))
+			}
+		}
+		ors = append(ors, sql.And(ands...))
 	}
+	return sql.Or(ors...)
 }
@@ -0,0 +1,87 @@
+package pagination
+
+import (
+	"testing"
+
+	"entgo.io/ent/dialect/sql"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination/keyset"
+)
+
+func TestTokenPaginator_FirstPage(t *testing.T) {
+	p := NewTokenPaginator()
+	seek, err := p.BuildSeek("", 10, []*paginationV1.Sorting{{Field: "name", Direction: paginationV1.Sorting_DESC}}, nil)
+	if err != nil {
+		t.Fatalf("build seek: %v", err)
+	}
+
+	s := sql.Select("*").From(sql.Table("users"))
+	p.BuildSelector(seek)(s)
+	query, args := s.Query()
+
+	want := "SELECT * FROM `users` ORDER BY `users`.`name` DESC, `users`.`id` ASC LIMIT 11"
+	if query != want {
+		t.Fatalf("unexpected sql:\n got %s\nwant %s", query, want)
+	}
+	if len(args) != 0 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestTokenPaginator_UniformDirections(t *testing.T) {
+	sortings := []*paginationV1.Sorting{{Field: "name"}}
+	cols, _ := keyset.Columns(sortings, "id")
+	token, _ := keyset.Encode(cols, []any{"bob", 7}, false)
+
+	p := NewTokenPaginator()
+	seek, err := p.BuildSeek(token, 5, sortings, nil)
+	if err != nil {
+		t.Fatalf("build seek: %v", err)
+	}
+
+	s := sql.Select("*").From(sql.Table("users"))
+	p.BuildSelector(seek)(s)
+	query, args := s.Query()
+
+	want := "SELECT * FROM `users` WHERE (`users`.`name`, `users`.`id`) > (?, ?) ORDER BY `users`.`name` ASC, `users`.`id` ASC LIMIT 6"
+	if query != want {
+		t.Fatalf("unexpected sql:\n got %s\nwant %s", query, want)
+	}
+	if len(args) != 2 || args[0] != "bob" || args[1] != int64(7) {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestTokenPaginator_MixedDirectionsBackward(t *testing.T) {
+	sortings := []*paginationV1.Sorting{{Field: "score", Direction: paginationV1.Sorting_DESC}}
+	cols, _ := keyset.Columns(sortings, "id")
+	token, _ := keyset.Encode(cols, []any{10, 3}, true)
+
+	p := NewTokenPaginator()
+	seek, err := p.BuildSeek(token, 5, sortings, nil)
+	if err != nil {
+		t.Fatalf("build seek: %v", err)
+	}
+
+	s := sql.Select("*").From(sql.Table("users"))
+	s.Where(sql.EQ(s.C("status"), "on"))
+	p.BuildSelector(seek)(s)
+	query, args := s.Query()
+
+	// 向前翻页：score 升序、id 降序；OR 条件需与已有条件正确组合
+	want := "SELECT * FROM `users` WHERE `users`.`status` = ? AND (`users`.`score` > ? OR (`users`.`score` = ? AND `users`.`id` < ?)) ORDER BY `users`.`score` ASC, `users`.`id` DESC LIMIT 6"
+	if query != want {
+		t.Fatalf("unexpected sql:\n got %s\nwant %s", query, want)
+	}
+	if len(args) != 4 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestTokenPaginator_InvalidToken(t *testing.T) {
+	p := NewTokenPaginator()
+	if _, err := p.BuildSeek("not-a-token", 5, nil, nil); err == nil {
+		t.Fatal("expected error for invalid token")
+	}
+}
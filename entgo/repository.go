@@ -14,13 +14,19 @@ import (
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
+	crud "github.com/tx7do/go-crud"
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
 	"github.com/tx7do/go-crud/entgo/field"
 	"github.com/tx7do/go-crud/entgo/filter"
 	paging "github.com/tx7do/go-crud/entgo/pagination"
 	"github.com/tx7do/go-crud/entgo/sorting"
 	"github.com/tx7do/go-crud/entgo/update"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
 	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
+	"github.com/tx7do/go-crud/pagination/keyset"
+	"github.com/tx7do/go-crud/pagination/paginator"
+	"github.com/tx7do/go-crud/pagination/softdelete"
 	paginationSorting "github.com/tx7do/go-crud/pagination/sorting"
 )
 
@@ -44,6 +50,13 @@ type Repository[
 	orderByStringConverter *paginationSorting.OrderByStringConverter
 
 	fieldSelector *field.Selector
+
+	deletedAtColumn string
+	deletedByColumn string
+
+	versionField     string
+	conflictRetries  int
+	conflictResolver crud.ConflictResolver[DTO]
 }
 
 func NewRepository[
@@ -78,15 +91,74 @@ func NewRepository[
 
 		structuredSorting:      sorting.NewStructuredSorting(),
 		orderByStringConverter: paginationSorting.NewOrderByStringConverter(),
+
+		deletedAtColumn: softdelete.DefaultDeletedAtColumn,
+		deletedByColumn: softdelete.DefaultDeletedByColumn,
+
+		versionField: DefaultVersionField,
 	}
 }
 
-// PagingResult 是通用的分页返回结构，包含 items 和 total 字段
-type PagingResult[E any] struct {
-	Items []*E   `json:"items"`
-	Total uint64 `json:"total"`
+// WithCursorCodec 设置 token 分页使用的游标编解码器，生产环境建议使用 pagination.SignedCursorCodec 防止 token 被伪造
+func (r *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) WithCursorCodec(codec pagination.CursorCodec) *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+] {
+	r.tokenPaginator.WithCursorCodec(codec)
+	return r
+}
+
+// WithFilterPolicy 设置过滤与排序的字段策略（白名单、列名映射、操作符限制等），违反策略的列表查询返回 paginationFilter.ErrPolicyViolation
+func (r *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) WithFilterPolicy(policy *paginationFilter.FilterPolicy) *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+] {
+	r.structuredFilter.WithPolicy(policy)
+	r.structuredSorting.WithPolicy(policy)
+	return r
 }
 
+// WithFieldMapping 设置 API 字段名到列名的映射，过滤、排序与字段选择均按映射解析列名
+func (r *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) WithFieldMapping(mapping *fieldmap.Mapping) *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+] {
+	r.structuredFilter.WithFieldMapping(mapping)
+	r.structuredSorting.WithFieldMapping(mapping)
+	r.fieldSelector.WithFieldMapping(mapping)
+	return r
+}
+
+// PagingResult 通用分页返回（含完整的分页元数据），见 pagination.PagingResult
+type PagingResult[E any] = pagination.PagingResult[E]
+
 // Count 计算符合条件的记录数
 func (r *Repository[
 	ENT_QUERY, ENT_SELECT,
@@ -166,7 +238,7 @@ func (r *Repository[
 		return nil, errors.New("query builder is nil")
 	}
 
-	whereSelectors, _, err := r.BuildListSelectorWithPaging(builder, req)
+	whereSelectors, _, pager, seek, err := r.buildListSelectorWithPaging(builder, req)
 	if err != nil {
 		return nil, err
 	}
@@ -177,6 +249,19 @@ func (r *Repository[
 		return nil, errors.New("query list failed")
 	}
 
+	if seek != nil {
+		var nextToken, prevToken string
+		if entities, nextToken, prevToken, err = keyset.Page(seek, entities); err != nil {
+			log.Errorf("build keyset token failed: %s", err.Error())
+			return nil, err
+		}
+		pager.SetNextToken(nextToken)
+		pager.SetPrevToken(prevToken)
+
+		_, extra := seek.Select(req.GetFieldMask().GetPaths(), r.fieldSelector.Resolve)
+		keyset.Clear(entities, extra)
+	}
+
 	dtos := make([]*DTO, 0, len(entities))
 	for _, entity := range entities {
 		dto := r.mapper.ToDTO(entity)
@@ -195,10 +280,7 @@ func (r *Repository[
 		}
 	}
 
-	res := &PagingResult[DTO]{
-		Items: dtos,
-		Total: uint64(count),
-	}
+	res := pagination.NewPagingResult(dtos, int64(count), pager)
 
 	return res, nil
 }
@@ -224,7 +306,7 @@ func (r *Repository[
 		return nil, errors.New("query builder is nil")
 	}
 
-	whereSelectors, _, err := r.BuildListSelectorWithPaging(builder, req)
+	whereSelectors, _, pager, seek, err := r.buildListSelectorWithPaging(builder, req)
 	if err != nil {
 		return nil, err
 	}
@@ -235,6 +317,17 @@ func (r *Repository[
 		return nil, errors.New("query list failed")
 	}
 
+	if seek != nil {
+		var nextToken, prevToken string
+		if entities, nextToken, prevToken, err = keyset.Page(seek, entities); err != nil {
+			log.Errorf("build keyset token failed: %s", err.Error())
+			return nil, err
+		}
+		pager.SetNextToken(nextToken)
+		pager.SetPrevToken(prevToken)
+		// 组装树形结构依赖 id，补充选择的排序列不做清除
+	}
+
 	// 先把所有 ENTITY 映射为 DTO 列表
 	allDTOs := make([]*DTO, 0, len(entities))
 	for _, entity := range entities {
@@ -282,10 +375,7 @@ func (r *Repository[
 		}
 	}
 
-	res := &PagingResult[DTO]{
-		Items: roots,
-		Total: uint64(count),
-	}
+	res := pagination.NewPagingResult(roots, int64(count), pager)
 
 	return res, nil
 }
@@ -301,12 +391,27 @@ func (r *Repository[
 	builder ListBuilder[ENT_QUERY, ENT_SELECT, ENTITY],
 	req *paginationV1.PagingRequest,
 ) (whereSelectors []func(s *sql.Selector), querySelectors []func(s *sql.Selector), err error) {
+	whereSelectors, querySelectors, _, _, err = r.buildListSelectorWithPaging(builder, req)
+	return whereSelectors, querySelectors, err
+}
+
+// buildListSelectorWithPaging 构建分页查询选择器，额外返回记录分页状态的分页器，Token 分页时还返回 keyset 状态
+func (r *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) buildListSelectorWithPaging(
+	builder ListBuilder[ENT_QUERY, ENT_SELECT, ENTITY],
+	req *paginationV1.PagingRequest,
+) (whereSelectors []func(s *sql.Selector), querySelectors []func(s *sql.Selector), pager pagination.Paginator, seek *keyset.Seek, err error) {
 	if req == nil {
-		return nil, nil, errors.New("paging request is nil")
+		return nil, nil, nil, nil, errors.New("paging request is nil")
 	}
 
 	if builder == nil {
-		return nil, nil, errors.New("query builder is nil")
+		return nil, nil, nil, nil, errors.New("query builder is nil")
 	}
 
 	var sortingSelector func(s *sql.Selector)
@@ -321,56 +426,73 @@ func (r *Repository[
 	whereSelectors, err = r.structuredFilter.BuildSelectors(filterExpr)
 	if err != nil {
 		log.Errorf("build structured filter selectors failed: %s", err.Error())
+		if errors.Is(err, paginationFilter.ErrPolicyViolation) {
+			return nil, nil, nil, nil, err
+		}
 	}
 
 	if whereSelectors != nil {
 		querySelectors = append(querySelectors, whereSelectors...)
 	}
 
-	// select fields
-	if req.FieldMask != nil && len(req.GetFieldMask().Paths) > 0 {
-		selectSelector, err = r.fieldSelector.BuildSelector(req.GetFieldMask().GetPaths())
-		if err != nil {
-			log.Errorf("build field select selector failed: %s", err.Error())
-		}
-	}
-	if selectSelector != nil {
-		querySelectors = append(querySelectors, selectSelector)
-	}
-
 	// order by
+	var sortings []*paginationV1.Sorting
 	if len(req.GetSorting()) > 0 {
-		sortingSelector, err = r.structuredSorting.BuildSelector(req.GetSorting())
-		if err != nil {
-			log.Errorf("build structured sorting selector failed: %s", err.Error())
-		}
+		sortings = req.GetSorting()
 	} else if len(req.GetOrderBy()) > 0 {
-		var sortings []*paginationV1.Sorting
 		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
 		if err != nil {
 			log.Errorf("convert order by string to sorting failed: %s", err.Error())
-			return nil, nil, err
+			return nil, nil, nil, nil, err
 		}
-
+	}
+	// 按字段策略校验排序字段，keyset 分页使用映射后的列名
+	var sortColumns []*paginationV1.Sorting
+	if sortColumns, err = r.structuredSorting.Validate(sortings); err != nil {
+		log.Errorf("validate sorting failed: %s", err.Error())
+		return nil, nil, nil, nil, err
+	}
+	if len(sortings) > 0 {
 		sortingSelector, err = r.structuredSorting.BuildSelector(sortings)
 		if err != nil {
-			log.Errorf("build query string sorting selector failed: %s", err.Error())
+			log.Errorf("build structured sorting selector failed: %s", err.Error())
 		}
 	}
-	if sortingSelector != nil {
-		querySelectors = append(querySelectors, sortingSelector)
-	}
 
 	// pagination
 	if !req.GetNoPaging() {
 		if req.Page != nil && req.PageSize != nil {
+			pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPage())).WithSize(int(req.GetPageSize()))
 			pagingSelector = r.pagePaginator.BuildSelector(int(req.GetPage()), int(req.GetPageSize()))
 		} else if req.Offset != nil && req.Limit != nil {
+			pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffset())).WithLimit(int(req.GetLimit()))
 			pagingSelector = r.offsetPaginator.BuildSelector(int(req.GetOffset()), int(req.GetLimit()))
 		} else if req.Token != nil && req.Offset != nil {
-			pagingSelector = r.tokenPaginator.BuildSelector(req.GetToken(), int(req.GetOffset()))
+			if seek, err = r.tokenPaginator.BuildSeek(req.GetToken(), int(req.GetOffset()), sortColumns, pagination.CursorScopeOf(req)); err != nil {
+				log.Errorf("build keyset seek failed: %s", err.Error())
+				return nil, nil, nil, nil, err
+			}
+			pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetToken()).WithSize(seek.Size)
+			// keyset 分页自行处理排序（含 tiebreaker 以及向前翻页时的反转）
+			sortingSelector = nil
+			pagingSelector = r.tokenPaginator.BuildSelector(seek)
 		}
 	}
+
+	// select fields（keyset 分页需要排序列的值生成游标，未请求的排序列由调用方在返回前清除）
+	if req.FieldMask != nil && len(req.GetFieldMask().Paths) > 0 {
+		paths, _ := seek.Select(req.GetFieldMask().GetPaths(), r.fieldSelector.Resolve)
+		selectSelector, err = r.fieldSelector.BuildSelector(paths)
+		if err != nil {
+			log.Errorf("build field select selector failed: %s", err.Error())
+		}
+	}
+	if selectSelector != nil {
+		querySelectors = append(querySelectors, selectSelector)
+	}
+	if sortingSelector != nil {
+		querySelectors = append(querySelectors, sortingSelector)
+	}
 	if pagingSelector != nil {
 		querySelectors = append(querySelectors, pagingSelector)
 	}
@@ -379,7 +501,7 @@ func (r *Repository[
 		builder.Modify(querySelectors...)
 	}
 
-	return whereSelectors, querySelectors, nil
+	return whereSelectors, querySelectors, pager, seek, nil
 }
 
 // ListWithPagination 使用通用的分页请求参数进行列表查询
@@ -403,7 +525,7 @@ func (r *Repository[
 		return nil, errors.New("query builder is nil")
 	}
 
-	whereSelectors, _, err := r.BuildListSelectorWithPagination(builder, req)
+	whereSelectors, _, pager, seek, err := r.buildListSelectorWithPagination(builder, req)
 	if err != nil {
 		return nil, err
 	}
@@ -414,6 +536,19 @@ func (r *Repository[
 		return nil, errors.New("query list failed")
 	}
 
+	if seek != nil {
+		var nextToken, prevToken string
+		if entities, nextToken, prevToken, err = keyset.Page(seek, entities); err != nil {
+			log.Errorf("build keyset token failed: %s", err.Error())
+			return nil, err
+		}
+		pager.SetNextToken(nextToken)
+		pager.SetPrevToken(prevToken)
+
+		_, extra := seek.Select(req.GetFieldMask().GetPaths(), r.fieldSelector.Resolve)
+		keyset.Clear(entities, extra)
+	}
+
 	dtos := make([]*DTO, 0, len(entities))
 	for _, entity := range entities {
 		dto := r.mapper.ToDTO(entity)
@@ -432,10 +567,7 @@ func (r *Repository[
 		}
 	}
 
-	res := &PagingResult[DTO]{
-		Items: dtos,
-		Total: uint64(count),
-	}
+	res := pagination.NewPagingResult(dtos, int64(count), pager)
 
 	return res, nil
 }
@@ -461,7 +593,7 @@ func (r *Repository[
 		return nil, errors.New("query builder is nil")
 	}
 
-	whereSelectors, _, err := r.BuildListSelectorWithPagination(builder, req)
+	whereSelectors, _, pager, seek, err := r.buildListSelectorWithPagination(builder, req)
 	if err != nil {
 		return nil, err
 	}
@@ -472,6 +604,17 @@ func (r *Repository[
 		return nil, errors.New("query list failed")
 	}
 
+	if seek != nil {
+		var nextToken, prevToken string
+		if entities, nextToken, prevToken, err = keyset.Page(seek, entities); err != nil {
+			log.Errorf("build keyset token failed: %s", err.Error())
+			return nil, err
+		}
+		pager.SetNextToken(nextToken)
+		pager.SetPrevToken(prevToken)
+		// 组装树形结构依赖 id，补充选择的排序列不做清除
+	}
+
 	// 先把所有 ENTITY 映射为 DTO 列表
 	allDTOs := make([]*DTO, 0, len(entities))
 	for _, entity := range entities {
@@ -519,10 +662,7 @@ func (r *Repository[
 		}
 	}
 
-	res := &PagingResult[DTO]{
-		Items: roots,
-		Total: uint64(count),
-	}
+	res := pagination.NewPagingResult(roots, int64(count), pager)
 
 	return res, nil
 }
@@ -538,12 +678,27 @@ func (r *Repository[
 	builder ListBuilder[ENT_QUERY, ENT_SELECT, ENTITY],
 	req *paginationV1.PaginationRequest,
 ) (whereSelectors []func(s *sql.Selector), querySelectors []func(s *sql.Selector), err error) {
+	whereSelectors, querySelectors, _, _, err = r.buildListSelectorWithPagination(builder, req)
+	return whereSelectors, querySelectors, err
+}
+
+// buildListSelectorWithPagination 构建分页查询选择器，额外返回记录分页状态的分页器，Token 分页时还返回 keyset 状态
+func (r *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) buildListSelectorWithPagination(
+	builder ListBuilder[ENT_QUERY, ENT_SELECT, ENTITY],
+	req *paginationV1.PaginationRequest,
+) (whereSelectors []func(s *sql.Selector), querySelectors []func(s *sql.Selector), pager pagination.Paginator, seek *keyset.Seek, err error) {
 	if req == nil {
-		return nil, nil, errors.New("paginationV1 request is nil")
+		return nil, nil, nil, nil, errors.New("paginationV1 request is nil")
 	}
 
 	if builder == nil {
-		return nil, nil, errors.New("query builder is nil")
+		return nil, nil, nil, nil, errors.New("query builder is nil")
 	}
 
 	var sortingSelector func(s *sql.Selector)
@@ -558,50 +713,67 @@ func (r *Repository[
 	whereSelectors, err = r.structuredFilter.BuildSelectors(filterExpr)
 	if err != nil {
 		log.Errorf("build structured filter selectors failed: %s", err.Error())
-	}
-
-	// select fields
-	if req.FieldMask != nil && len(req.GetFieldMask().Paths) > 0 {
-		selectSelector, err = r.fieldSelector.BuildSelector(req.GetFieldMask().GetPaths())
-		if err != nil {
-			log.Errorf("build field select selector failed: %s", err.Error())
+		if errors.Is(err, paginationFilter.ErrPolicyViolation) {
+			return nil, nil, nil, nil, err
 		}
 	}
-	if selectSelector != nil {
-		querySelectors = append(querySelectors, selectSelector)
-	}
 
 	// order by
+	var sortings []*paginationV1.Sorting
 	if len(req.GetSorting()) > 0 {
-		sortingSelector, err = r.structuredSorting.BuildSelector(req.GetSorting())
-		if err != nil {
-			log.Errorf("build structured sorting selector failed: %s", err.Error())
-		}
+		sortings = req.GetSorting()
 	} else if len(req.GetOrderBy()) > 0 {
-		var sortings []*paginationV1.Sorting
 		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
 		if err != nil {
 			log.Errorf("convert order by string to sorting failed: %s", err.Error())
-			return nil, nil, err
+			return nil, nil, nil, nil, err
 		}
-
+	}
+	// 按字段策略校验排序字段，keyset 分页使用映射后的列名
+	var sortColumns []*paginationV1.Sorting
+	if sortColumns, err = r.structuredSorting.Validate(sortings); err != nil {
+		log.Errorf("validate sorting failed: %s", err.Error())
+		return nil, nil, nil, nil, err
+	}
+	if len(sortings) > 0 {
 		sortingSelector, err = r.structuredSorting.BuildSelector(sortings)
 		if err != nil {
-			log.Errorf("build query string sorting selector failed: %s", err.Error())
+			log.Errorf("build structured sorting selector failed: %s", err.Error())
 		}
 	}
-	if sortingSelector != nil {
-		querySelectors = append(querySelectors, sortingSelector)
-	}
 
 	// pagination
 	switch req.GetPaginationType().(type) {
 	case *paginationV1.PaginationRequest_OffsetBased:
+		pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffsetBased().GetOffset())).WithLimit(int(req.GetOffsetBased().GetLimit()))
 		pagingSelector = r.offsetPaginator.BuildSelector(int(req.GetOffsetBased().GetOffset()), int(req.GetOffsetBased().GetLimit()))
 	case *paginationV1.PaginationRequest_PageBased:
+		pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPageBased().GetPage())).WithSize(int(req.GetPageBased().GetPageSize()))
 		pagingSelector = r.pagePaginator.BuildSelector(int(req.GetPageBased().GetPage()), int(req.GetPageBased().GetPageSize()))
 	case *paginationV1.PaginationRequest_TokenBased:
-		pagingSelector = r.tokenPaginator.BuildSelector(req.GetTokenBased().GetToken(), int(req.GetTokenBased().GetPageSize()))
+		if seek, err = r.tokenPaginator.BuildSeek(req.GetTokenBased().GetToken(), int(req.GetTokenBased().GetPageSize()), sortColumns, pagination.CursorScopeOf(req)); err != nil {
+			log.Errorf("build keyset seek failed: %s", err.Error())
+			return nil, nil, nil, nil, err
+		}
+		pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetTokenBased().GetToken()).WithSize(seek.Size)
+		// keyset 分页自行处理排序（含 tiebreaker 以及向前翻页时的反转）
+		sortingSelector = nil
+		pagingSelector = r.tokenPaginator.BuildSelector(seek)
+	}
+
+	// select fields（keyset 分页需要排序列的值生成游标，未请求的排序列由调用方在返回前清除）
+	if req.FieldMask != nil && len(req.GetFieldMask().Paths) > 0 {
+		paths, _ := seek.Select(req.GetFieldMask().GetPaths(), r.fieldSelector.Resolve)
+		selectSelector, err = r.fieldSelector.BuildSelector(paths)
+		if err != nil {
+			log.Errorf("build field select selector failed: %s", err.Error())
+		}
+	}
+	if selectSelector != nil {
+		querySelectors = append(querySelectors, selectSelector)
+	}
+	if sortingSelector != nil {
+		querySelectors = append(querySelectors, sortingSelector)
 	}
 	if pagingSelector != nil {
 		querySelectors = append(querySelectors, pagingSelector)
@@ -611,7 +783,7 @@ func (r *Repository[
 		builder.Modify(querySelectors...)
 	}
 
-	return whereSelectors, querySelectors, nil
+	return whereSelectors, querySelectors, pager, seek, nil
 }
 
 // Get 根据查询条件获取单条记录
@@ -814,7 +986,8 @@ func (r *Repository[
 	return res, nil
 }
 
-// UpdateOne 根据查询条件更新单条记录，返回更新后的 DTO
+// UpdateOne 根据查询条件更新单条记录，返回更新后的 DTO；
+// 实体包含版本字段且 dto 携带版本号时启用乐观锁，版本不一致时返回 crud.ErrVersionConflict
 func (r *Repository[
 	ENT_QUERY, ENT_SELECT,
 	ENT_CREATE, ENT_CREATE_BULK,
@@ -841,6 +1014,16 @@ func (r *Repository[
 		builder.Where(predicates...)
 	}
 
+	// 乐观锁（在按字段掩码过滤前读取版本号）
+	versionWhere, versionBump, locked, err := r.versionLock(dto)
+	if err != nil {
+		return nil, err
+	}
+	if locked {
+		builder.Where(versionWhere)
+		builder.Modify(versionBump)
+	}
+
 	field.NormalizeFieldMaskPaths(updateMask)
 
 	var dtoAny any = dto
@@ -859,9 +1042,12 @@ func (r *Repository[
 
 	r.applyUpdateOneNilFieldMask(dtoProto, updateMask, builder)
 
-	var err error
 	var entity *ENTITY
 	if entity, err = builder.Save(ctx); err != nil {
+		// 启用乐观锁时版本不一致与记录不存在均表现为 NotFound
+		if locked && isNotFound(err) {
+			return nil, crud.ErrVersionConflict
+		}
 		log.Errorf("update one data failed: %s", err.Error())
 		return nil, err
 	}
@@ -925,7 +1111,8 @@ func (r *Repository[
 	}
 }
 
-// UpdateX 仅执行更新操作，不返回更新后的数据
+// UpdateX 仅执行更新操作，不返回更新后的数据；
+// 实体包含版本字段且 dto 携带版本号时启用乐观锁，未更新任何记录时返回 crud.ErrVersionConflict
 func (r *Repository[
 	ENT_QUERY, ENT_SELECT,
 	ENT_CREATE, ENT_CREATE_BULK,
@@ -952,6 +1139,16 @@ func (r *Repository[
 		builder.Where(predicates...)
 	}
 
+	// 乐观锁（在按字段掩码过滤前读取版本号）
+	versionWhere, versionBump, locked, err := r.versionLock(dto)
+	if err != nil {
+		return err
+	}
+	if locked {
+		builder.Where(versionWhere)
+		builder.Modify(versionBump)
+	}
+
 	field.NormalizeFieldMaskPaths(updateMask)
 
 	var dtoAny any = dto
@@ -970,10 +1167,14 @@ func (r *Repository[
 
 	r.applyUpdateNilFieldMask(dtoProto, updateMask, builder)
 
-	if err := builder.Exec(ctx); err != nil {
+	affected, err := builder.Save(ctx)
+	if err != nil {
 		log.Errorf("update one data failed: %s", err.Error())
 		return err
 	}
+	if locked && affected == 0 {
+		return crud.ErrVersionConflict
+	}
 
 	return nil
 }
@@ -0,0 +1,370 @@
+package rule
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"entgo.io/ent/entql"
+
+	"github.com/tx7do/go-crud/pagination/datascope"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+// FieldType 数据权限字段在 schema 中的类型，用于转换谓词中的 ID 值
+type FieldType int
+
+const (
+	FieldTypeUint64 FieldType = iota
+	FieldTypeUint32
+	FieldTypeInt64
+	FieldTypeInt32
+	FieldTypeInt
+	FieldTypeString
+)
+
+const (
+	// DefaultOwnerField 创建者（归属者）字段的默认名称
+	DefaultOwnerField = datascope.DefaultOwnerColumn
+	// DefaultUnitField 组织单元字段的默认名称
+	DefaultUnitField = datascope.DefaultOrgUnitColumn
+)
+
+// ScopeHandler 自定义数据权限范围的处理函数，返回该范围对应的谓词；返回 nil 谓词表示该范围不产生条件
+type ScopeHandler func(ctx context.Context, vc viewer.Context, scope viewer.DataScope, rule *PermissionRuleBuilder) (entql.P, error)
+
+var (
+	scopeHandlersMu sync.RWMutex
+	scopeHandlers   = map[viewer.ScopeType]ScopeHandler{}
+)
+
+// RegisterScopeType 注册全局的自定义数据权限范围类型，对所有 PermissionRuleBuilder 生效；
+// 内置类型（SELF/UNIT/USER/ALL/NONE）不可覆盖。
+func RegisterScopeType(scopeType viewer.ScopeType, handler ScopeHandler) {
+	scopeHandlersMu.Lock()
+	defer scopeHandlersMu.Unlock()
+
+	if handler == nil {
+		delete(scopeHandlers, scopeType)
+		return
+	}
+	scopeHandlers[scopeType] = handler
+}
+
+func lookupScopeHandler(scopeType viewer.ScopeType) (ScopeHandler, bool) {
+	scopeHandlersMu.RLock()
+	defer scopeHandlersMu.RUnlock()
+
+	h, ok := scopeHandlers[scopeType]
+	return h, ok
+}
+
+// PermissionRuleBuilder 可配置的数据权限规则，按 schema 指定创建者、组织单元、租户字段的名称与类型：
+//   - SELF 与 USER 合并为创建者字段上的一个 IN 谓词，UNIT 为组织单元字段上的一个 IN 谓词，多个范围取并集（OR）；
+//   - 任一范围为 ALL 时不过滤，任一范围为 NONE 时拒绝访问；
+//   - UNIT_TREE 的根组织并入组织单元字段的 IN 谓词，设置了组织路径字段时，额外以 path LIKE '%/{根组织ID}/%' 匹配下级组织，
+//     设置了 WithUnitPathResolver 时改为以根组织路径前缀匹配（path LIKE '/1/5/%'）；
+//   - 设置了租户字段时，额外以 AND 追加当前租户条件；
+//   - 平台视图与系统视图不过滤，缺少 Viewer 或未定义任何范围时拒绝访问；
+//   - 自定义范围类型通过 WithScopeHandler 或全局的 RegisterScopeType 注册，未注册的类型被忽略。
+type PermissionRuleBuilder struct {
+	ownerField    string
+	unitField     string
+	unitPathField string
+	tenantField   string
+	fieldType     FieldType
+
+	unitPathResolver datascope.PathResolver
+
+	handlers map[viewer.ScopeType]ScopeHandler
+}
+
+// NewPermissionRule 创建使用默认字段（created_by、org_unit_id，uint64）的数据权限规则
+func NewPermissionRule() *PermissionRuleBuilder {
+	return &PermissionRuleBuilder{
+		ownerField: DefaultOwnerField,
+		unitField:  DefaultUnitField,
+		fieldType:  FieldTypeUint64,
+	}
+}
+
+// WithOwnerField 设置创建者（归属者）字段名
+func (b *PermissionRuleBuilder) WithOwnerField(name string) *PermissionRuleBuilder {
+	if name != "" {
+		b.ownerField = name
+	}
+	return b
+}
+
+// WithUnitField 设置组织单元字段名
+func (b *PermissionRuleBuilder) WithUnitField(name string) *PermissionRuleBuilder {
+	if name != "" {
+		b.unitField = name
+	}
+	return b
+}
+
+// WithUnitPathField 设置组织单元树路径字段名，字段值为所属组织的树路径（格式同 mixin.TreePath 的 path 字段，如 "/1/5/"）；
+// 对使用 mixin.TreePath 的组织实体本身，可配合 WithUnitField("id") 使用 "path"。为空时 UNIT_TREE 只匹配根组织本身
+func (b *PermissionRuleBuilder) WithUnitPathField(name string) *PermissionRuleBuilder {
+	b.unitPathField = name
+	return b
+}
+
+// WithUnitPathResolver 设置根组织树路径的解析函数，语义同 datascope.Policy.WithOrgUnitPathResolver：
+// 设置后下级组织以前缀匹配（可使用路径字段上的索引），未设置或未解析出路径的根组织以包含匹配（全表扫描）
+func (b *PermissionRuleBuilder) WithUnitPathResolver(resolver datascope.PathResolver) *PermissionRuleBuilder {
+	b.unitPathResolver = resolver
+	return b
+}
+
+// WithTenantField 设置租户字段名，为空时不追加租户条件
+func (b *PermissionRuleBuilder) WithTenantField(name string) *PermissionRuleBuilder {
+	b.tenantField = name
+	return b
+}
+
+// WithFieldType 设置数据权限字段的类型，超出该类型取值范围的 ID 会被忽略
+func (b *PermissionRuleBuilder) WithFieldType(t FieldType) *PermissionRuleBuilder {
+	b.fieldType = t
+	return b
+}
+
+// WithScopeHandler 为当前规则注册自定义数据权限范围类型，优先于全局注册的处理函数
+func (b *PermissionRuleBuilder) WithScopeHandler(scopeType viewer.ScopeType, handler ScopeHandler) *PermissionRuleBuilder {
+	if b.handlers == nil {
+		b.handlers = make(map[viewer.ScopeType]ScopeHandler)
+	}
+	b.handlers[scopeType] = handler
+	return b
+}
+
+// OwnerField 返回创建者（归属者）字段名
+func (b *PermissionRuleBuilder) OwnerField() string {
+	return b.ownerField
+}
+
+// UnitField 返回组织单元字段名
+func (b *PermissionRuleBuilder) UnitField() string {
+	return b.unitField
+}
+
+// UnitPathField 返回组织单元树路径字段名
+func (b *PermissionRuleBuilder) UnitPathField() string {
+	return b.unitPathField
+}
+
+// TenantField 返回租户字段名
+func (b *PermissionRuleBuilder) TenantField() string {
+	return b.tenantField
+}
+
+// In 构造 field IN (ids) 谓词，ID 按字段类型转换并去重；没有有效 ID 时返回 nil
+func (b *PermissionRuleBuilder) In(field string, ids []uint64) entql.P {
+	seen := make(map[uint64]struct{}, len(ids))
+	vs := make([]any, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		if v, ok := b.convert(id); ok {
+			vs = append(vs, v)
+		}
+	}
+	if len(vs) == 0 {
+		return nil
+	}
+	return entql.FieldIn(field, vs...)
+}
+
+// TreePath 构造 field LIKE '%/id/%' 谓词，匹配树路径中包含指定组织的下级组织；ID 去重，顺序与输入一致
+func (b *PermissionRuleBuilder) TreePath(field string, ids []uint64) []entql.P {
+	seen := make(map[uint64]struct{}, len(ids))
+	ps := make([]entql.P, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ps = append(ps, entql.FieldContains(field, datascope.TreePathSegment(id)))
+	}
+	return ps
+}
+
+// treePath 构造根组织下级的树路径谓词：设置了解析函数且解析出路径的根组织使用前缀匹配，其余同 TreePath
+func (b *PermissionRuleBuilder) treePath(ctx context.Context, roots []uint64) ([]entql.P, error) {
+	if b.unitPathResolver == nil {
+		return b.TreePath(b.unitPathField, roots), nil
+	}
+
+	seen := make(map[uint64]struct{}, len(roots))
+	ids := make([]uint64, 0, len(roots))
+	for _, id := range roots {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	paths, err := b.unitPathResolver(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	ps := make([]entql.P, 0, len(ids))
+	for _, id := range ids {
+		path, ok := paths[id]
+		if !ok || !strings.HasPrefix(path, "/") {
+			ps = append(ps, entql.FieldContains(b.unitPathField, datascope.TreePathSegment(id)))
+			continue
+		}
+		// 路径以 "/" 结尾，避免 "/1/5" 误匹配 "/1/55/"
+		if !strings.HasSuffix(path, "/") {
+			path += "/"
+		}
+		ps = append(ps, entql.FieldHasPrefix(b.unitPathField, path))
+	}
+	return ps, nil
+}
+
+// convert 将 ID 转换为字段类型的值，超出取值范围时返回 false
+func (b *PermissionRuleBuilder) convert(id uint64) (any, bool) {
+	switch b.fieldType {
+	case FieldTypeUint32:
+		return uint32(id), id <= math.MaxUint32
+	case FieldTypeInt64:
+		return int64(id), id <= math.MaxInt64
+	case FieldTypeInt32:
+		return int32(id), id <= math.MaxInt32
+	case FieldTypeInt:
+		return int(id), id <= math.MaxInt
+	case FieldTypeString:
+		return strconv.FormatUint(id, 10), true
+	default:
+		return id, true
+	}
+}
+
+// Predicate 根据 ctx 中的 Viewer 生成数据权限谓词；返回 nil 表示不需要过滤
+func (b *PermissionRuleBuilder) Predicate(ctx context.Context) (entql.P, error) {
+	vc, exist := viewer.FromContext(ctx)
+	// 如果身份丢失，安全起见应直接拒绝操作（Deny），而不是跳过
+	if !exist || vc == nil {
+		return nil, datascope.ErrMissingViewer
+	}
+
+	// 平台管理视图/系统视图放行：允许查看全量数据
+	if vc.IsPlatformContext() || vc.IsSystemContext() {
+		return nil, nil
+	}
+
+	// 获取数据范围列表
+	scopes := vc.DataScope()
+	if len(scopes) == 0 {
+		// 如果没有任何定义的 scope，默认应拒绝访问（安全兜底）
+		return nil, datascope.ErrNoDataScope
+	}
+
+	var owners, units, roots []uint64
+	var predicates []entql.P
+	for _, s := range scopes {
+		switch s.ScopeType {
+		case viewer.ScopeTypeAll:
+			return nil, nil // 只要有一个 scope 是 All，直接放行
+
+		case viewer.ScopeTypeNone:
+			// 显式禁止访问
+			return nil, datascope.ErrAccessDenied
+
+		case viewer.ScopeTypeSelf:
+			owners = append(owners, vc.UserID())
+
+		case viewer.ScopeTypeUser:
+			owners = append(owners, s.TargetIDs...)
+
+		case viewer.ScopeTypeUnit:
+			units = append(units, s.TargetIDs...)
+
+		case viewer.ScopeTypeUnitTree:
+			roots = append(roots, s.TargetIDs...)
+
+		default:
+			h, ok := b.handlers[s.ScopeType]
+			if !ok {
+				h, ok = lookupScopeHandler(s.ScopeType)
+			}
+			if !ok || h == nil {
+				// 未知的 scope 类型，忽略处理
+				continue
+			}
+			p, err := h(ctx, vc, s, b)
+			if err != nil {
+				return nil, err
+			}
+			if p != nil {
+				predicates = append(predicates, p)
+			}
+		}
+	}
+
+	if p := b.In(b.ownerField, owners); p != nil {
+		predicates = append([]entql.P{p}, predicates...)
+	}
+	// 根组织本身按组织单元字段匹配，下级组织按树路径匹配
+	if p := b.In(b.unitField, append(units, roots...)); p != nil {
+		predicates = append(predicates, p)
+	}
+	if b.unitPathField != "" && len(roots) > 0 {
+		ps, err := b.treePath(ctx, roots)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, ps...)
+	}
+
+	var p entql.P
+	switch len(predicates) {
+	case 0:
+		// 有 scope 但没解析出有效谓词，防御性拒绝
+		return nil, datascope.ErrInvalidDataScope
+	case 1:
+		p = predicates[0]
+	default:
+		p = entql.Or(predicates[0], predicates[1], predicates[2:]...)
+	}
+
+	if b.tenantField != "" {
+		tid, ok := b.convert(vc.TenantID())
+		if !ok {
+			return nil, datascope.ErrInvalidDataScope
+		}
+		p = entql.And(entql.FieldEQ(b.tenantField, tid), p)
+	}
+
+	return p, nil
+}
+
+// Eval 将数据权限谓词注入 Filter，可直接作为 privacy.FilterFunc 的实现
+func (b *PermissionRuleBuilder) Eval(ctx context.Context, f Filter) error {
+	p, err := b.Predicate(ctx)
+	if err != nil {
+		return err
+	}
+	if p != nil {
+		f.Where(p)
+	}
+	return nil
+}
+
+var defaultPermissionRule = NewPermissionRule()
+
+// PermissionRule 是一个通用的数据权限过滤规则，用于在查询时注入基于数据权限范围的过滤条件。
+// 该规则会根据当前 ViewerContext 中的数据权限信息，动态添加过滤谓词，确保数据访问符合权限要求。
+// 适用于包含 org_unit_id 和 created_by 字段的实体查询；字段名或类型不同时使用 NewPermissionRule 构建。
+func PermissionRule(ctx context.Context, f Filter) error {
+	return defaultPermissionRule.Eval(ctx, f)
+}
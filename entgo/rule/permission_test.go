@@ -0,0 +1,191 @@
+package rule
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"entgo.io/ent/entql"
+
+	"github.com/tx7do/go-crud/pagination/datascope"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+// scopeViewer 指定用户、租户与数据权限范围的 Viewer
+type scopeViewer struct {
+	viewer.Context
+	uid      uint64
+	tid      uint64
+	scopes   []viewer.DataScope
+	platform bool
+}
+
+func (v scopeViewer) UserID() uint64                { return v.uid }
+func (v scopeViewer) TenantID() uint64              { return v.tid }
+func (v scopeViewer) DataScope() []viewer.DataScope { return v.scopes }
+func (v scopeViewer) IsPlatformContext() bool       { return v.platform }
+
+// captureFilter 记录注入的谓词
+type captureFilter struct {
+	ps []entql.P
+}
+
+func (f *captureFilter) Where(p entql.P) { f.ps = append(f.ps, p) }
+
+func TestPermissionRuleBuilder(t *testing.T) {
+	const scopeProject viewer.ScopeType = "PROJECT"
+
+	b := NewPermissionRule().
+		WithOwnerField("owner_id").
+		WithUnitField("dept_id").
+		WithFieldType(FieldTypeUint32).
+		WithScopeHandler(scopeProject, func(_ context.Context, _ viewer.Context, s viewer.DataScope, r *PermissionRuleBuilder) (entql.P, error) {
+			return r.In("project_id", s.TargetIDs), nil
+		})
+
+	ctxOf := func(v scopeViewer) context.Context {
+		v.Context = viewer.NewNoopContext()
+		return viewer.WithContext(context.Background(), v)
+	}
+
+	tests := []struct {
+		name    string
+		rule    *PermissionRuleBuilder
+		vc      scopeViewer
+		want    string
+		wantErr error
+	}{
+		{
+			name: "platform",
+			rule: b,
+			vc:   scopeViewer{platform: true},
+		},
+		{
+			name: "all",
+			rule: b,
+			vc:   scopeViewer{scopes: []viewer.DataScope{{ScopeType: viewer.ScopeTypeSelf}, {ScopeType: viewer.ScopeTypeAll}}},
+		},
+		{
+			name:    "none",
+			rule:    b,
+			vc:      scopeViewer{scopes: []viewer.DataScope{{ScopeType: viewer.ScopeTypeNone}}},
+			wantErr: datascope.ErrAccessDenied,
+		},
+		{
+			name:    "no scope",
+			rule:    b,
+			vc:      scopeViewer{uid: 1},
+			wantErr: datascope.ErrNoDataScope,
+		},
+		{
+			name: "self and users merged into one IN",
+			rule: b,
+			vc: scopeViewer{uid: 7, scopes: []viewer.DataScope{
+				{ScopeType: viewer.ScopeTypeSelf},
+				{ScopeType: viewer.ScopeTypeUser, TargetIDs: []uint64{7, 8, 1 << 40}},
+			}},
+			want: `owner_id in [7,8]`,
+		},
+		{
+			name: "units and custom scope",
+			rule: b,
+			vc: scopeViewer{uid: 7, scopes: []viewer.DataScope{
+				{ScopeType: viewer.ScopeTypeUnit, TargetIDs: []uint64{1, 2, 3}},
+				{ScopeType: scopeProject, TargetIDs: []uint64{9}},
+				{ScopeType: "UNKNOWN", TargetIDs: []uint64{9}},
+			}},
+			want: `project_id in [9] || dept_id in [1,2,3]`,
+		},
+		{
+			name:    "unknown only",
+			rule:    b,
+			vc:      scopeViewer{scopes: []viewer.DataScope{{ScopeType: "UNKNOWN"}}},
+			wantErr: datascope.ErrInvalidDataScope,
+		},
+		{
+			name: "unit tree without path field",
+			rule: NewPermissionRule(),
+			vc: scopeViewer{uid: 7, scopes: []viewer.DataScope{
+				{ScopeType: viewer.ScopeTypeUnit, TargetIDs: []uint64{3}},
+				{ScopeType: viewer.ScopeTypeUnitTree, TargetIDs: []uint64{5}},
+			}},
+			want: `org_unit_id in [3,5]`,
+		},
+		{
+			name: "unit tree",
+			rule: NewPermissionRule().WithUnitField("id").WithUnitPathField("path"),
+			vc: scopeViewer{uid: 7, scopes: []viewer.DataScope{
+				{ScopeType: viewer.ScopeTypeUnitTree, TargetIDs: []uint64{5, 9, 5}},
+			}},
+			want: `(id in [5,9] || contains(path, "/5/") || contains(path, "/9/"))`,
+		},
+		{
+			name: "unit tree with path resolver",
+			rule: NewPermissionRule().WithUnitField("id").WithUnitPathField("path").
+				WithUnitPathResolver(func(_ context.Context, ids []uint64) (map[uint64]string, error) {
+					return map[uint64]string{5: "/1/5"}, nil
+				}),
+			vc: scopeViewer{uid: 7, scopes: []viewer.DataScope{
+				{ScopeType: viewer.ScopeTypeUnitTree, TargetIDs: []uint64{5, 9, 5}},
+			}},
+			want: `(id in [5,9] || has_prefix(path, "/1/5/") || contains(path, "/9/"))`,
+		},
+		{
+			name: "tenant",
+			rule: NewPermissionRule().WithTenantField("tenant_id"),
+			vc:   scopeViewer{uid: 7, tid: 3, scopes: []viewer.DataScope{{ScopeType: viewer.ScopeTypeSelf}}},
+			want: `tenant_id == 3 && created_by in [7]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &captureFilter{}
+			err := tt.rule.Eval(ctxOf(tt.vc), f)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.want == "" {
+				if len(f.ps) != 0 {
+					t.Fatalf("expected no predicate, got %v", f.ps)
+				}
+				return
+			}
+			if len(f.ps) != 1 || f.ps[0].String() != tt.want {
+				t.Fatalf("expected %q, got %v", tt.want, f.ps)
+			}
+		})
+	}
+
+	if err := PermissionRule(context.Background(), &captureFilter{}); !errors.Is(err, datascope.ErrMissingViewer) {
+		t.Fatalf("expected ErrMissingViewer, got %v", err)
+	}
+}
+
+func TestRegisterScopeType(t *testing.T) {
+	const scopeRegion viewer.ScopeType = "REGION"
+
+	RegisterScopeType(scopeRegion, func(_ context.Context, _ viewer.Context, s viewer.DataScope, r *PermissionRuleBuilder) (entql.P, error) {
+		return r.In("region_id", s.TargetIDs), nil
+	})
+	defer RegisterScopeType(scopeRegion, nil)
+
+	ctx := viewer.WithContext(context.Background(), scopeViewer{
+		Context: viewer.NewNoopContext(),
+		scopes:  []viewer.DataScope{{ScopeType: scopeRegion, TargetIDs: []uint64{5, 5}}},
+	})
+
+	f := &captureFilter{}
+	if err := PermissionRule(ctx, f); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.ps) != 1 || f.ps[0].String() != `region_id in [5]` {
+		t.Fatalf("unexpected predicate: %v", f.ps)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"entgo.io/ent/entql"
 	"entgo.io/ent/privacy"
 
+	"github.com/tx7do/go-crud/pagination/softdelete"
 	"github.com/tx7do/go-crud/viewer"
 )
 
@@ -36,87 +37,8 @@ func OwnerOnlyRule(ctx context.Context, f Filter) error {
 	return nil
 }
 
-// PermissionRule 是一个通用的数据权限过滤规则，用于在查询时注入基于数据权限范围的过滤条件。
-// 该规则会根据当前 ViewerContext 中的数据权限信息，动态添加过滤谓词，确保数据访问符合权限要求。
-// 适用于包含 org_unit_id 和 created_by 字段的实体查询。
-func PermissionRule(ctx context.Context, f Filter) error {
-	vc, exist := viewer.FromContext(ctx)
-	// 如果身份丢失，安全起见应直接拒绝操作（Deny），而不是跳过
-	if !exist {
-		return fmt.Errorf("security: missing ViewerContext in context")
-	}
-
-	// 平台管理视图/系统视图放行：允许查看全量数据
-	if vc.IsPlatformContext() || vc.IsSystemContext() {
-		return nil // 在 Privacy 逻辑中 nil 相当于 Skip
-	}
-
-	// 获取数据范围列表
-	scopes := vc.DataScope()
-	if len(scopes) == 0 {
-		// 如果没有任何定义的 scope，默认应拒绝访问（安全兜底）
-		return fmt.Errorf("security: no data scope defined for current user")
-	}
-
-	// 构建并集谓词 (OR 逻辑)
-	var predicates []entql.P
-	for _, s := range scopes {
-		switch s.ScopeType {
-		case viewer.ScopeTypeAll:
-			return nil // 只要有一个 scope 是 All，直接放行
-
-		case viewer.ScopeTypeSelf:
-			uid := vc.UserID()
-			// 仅限本人：匹配 created_by 字段
-			predicates = append(predicates, entql.Or(
-				entql.Uint64EQ(uid).Field("created_by"),
-				entql.Uint32EQ(uint32(uid)).Field("created_by"),
-			))
-
-		case viewer.ScopeTypeUnit:
-			// 匹配组织单元 ID
-			for _, id := range s.TargetIDs {
-				predicates = append(predicates, entql.Or(
-					entql.Uint64EQ(id).Field("org_unit_id"),
-					entql.Uint32EQ(uint32(id)).Field("org_unit_id"),
-				))
-			}
-
-		case viewer.ScopeTypeUser:
-			// 匹配指定用户 ID
-			for _, id := range s.TargetIDs {
-				predicates = append(predicates, entql.Or(
-					entql.Uint64EQ(id).Field("created_by"),
-					entql.Uint32EQ(uint32(id)).Field("created_by"),
-				))
-			}
-
-		case viewer.ScopeTypeNone:
-			// 显式禁止访问：如果命中 None，直接返回错误或在该 Case 下清空谓词并 Deny
-			return fmt.Errorf("security: data access is explicitly denied by policy")
-
-		default:
-			// 未知的 scope 类型，忽略处理
-			continue
-		}
-	}
-
-	// 5. 将所有 scope 逻辑通过 OR 连接，注入 Where 子句
-	if len(predicates) > 0 {
-		p := predicates[0]
-		for i := 1; i < len(predicates); i++ {
-			p = entql.Or(p, predicates[i])
-		}
-		f.Where(p)
-	} else {
-		// 有 scope 但没解析出有效谓词，防御性拒绝
-		return fmt.Errorf("security: invalid data scope configuration")
-	}
-
-	return nil
-}
-
-// SoftDeleteRule 注入软删除过滤规则，隐藏已软删除的数据记录
+// SoftDeleteRule 注入软删除过滤规则，默认隐藏已软删除的数据记录；
+// 按 ctx 中的 softdelete.Mode 处理：WithDeleted 不过滤，OnlyDeleted 仅保留已软删除的记录
 func SoftDeleteRule(ctx context.Context, f Filter) error {
 	vc, exist := viewer.FromContext(ctx)
 	// 如果身份丢失，安全起见应直接拒绝操作（Deny），而不是跳过
@@ -130,9 +52,14 @@ func SoftDeleteRule(ctx context.Context, f Filter) error {
 	}
 
 	// 注入软删除过滤谓词
-	f.Where(
-		entql.FieldNil("deleted_at"),
-	)
+	switch softdelete.ModeFromContext(ctx) {
+	case softdelete.ModeWithDeleted:
+		// 包含已软删除的记录，不注入过滤谓词
+	case softdelete.ModeOnlyDeleted:
+		f.Where(entql.FieldNotNil(softdelete.DefaultDeletedAtColumn))
+	default:
+		f.Where(entql.FieldNil(softdelete.DefaultDeletedAtColumn))
+	}
 
 	return nil
 }
@@ -0,0 +1,131 @@
+package entgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/tx7do/go-crud/pagination/softdelete"
+)
+
+// WithSoftDeleteColumns 设置 Restore 与 Purge 使用的删除时间、删除者列名：
+// deletedAt 为空时使用 softdelete.DefaultDeletedAtColumn，deletedBy 为空表示实体不包含删除者字段。
+// 默认与 mixin.SoftDelete 一致（deleted_at、deleted_by）。
+func (r *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) WithSoftDeleteColumns(deletedAt, deletedBy string) *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+] {
+	if deletedAt == "" {
+		deletedAt = softdelete.DefaultDeletedAtColumn
+	}
+	r.deletedAtColumn = deletedAt
+	r.deletedByColumn = deletedBy
+	return r
+}
+
+// Restore 恢复符合条件的已软删除记录：将删除时间与删除者置为 NULL，返回受影响的行数
+func (r *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) Restore(
+	ctx context.Context,
+	builder UpdateBuilder[ENT_UPDATE, PREDICATE],
+	predicates ...PREDICATE,
+) (int, error) {
+	if builder == nil {
+		return 0, errors.New("query builder is nil")
+	}
+
+	if len(predicates) > 0 {
+		builder.Where(predicates...)
+	}
+
+	deletedAt, deletedBy := r.deletedAtColumn, r.deletedByColumn
+	builder.Modify(func(u *sql.UpdateBuilder) {
+		u.SetNull(deletedAt)
+		if deletedBy != "" {
+			u.SetNull(deletedBy)
+		}
+		u.Where(sql.NotNull(deletedAt))
+	})
+
+	affected, err := builder.Save(softdelete.OnlyDeleted(ctx))
+	if err != nil {
+		log.Errorf("restore failed: %s", err.Error())
+		return 0, errors.New("restore failed")
+	}
+
+	return affected, nil
+}
+
+// Purge 物理删除符合条件、且删除时间早于 olderThan 之前的已软删除记录，olderThan <= 0 时清理全部已软删除记录；
+// 返回受影响的行数
+func (r *Repository[
+	ENT_QUERY, ENT_SELECT,
+	ENT_CREATE, ENT_CREATE_BULK,
+	ENT_UPDATE, ENT_UPDATE_ONE,
+	ENT_DELETE,
+	PREDICATE, DTO, ENTITY,
+]) Purge(
+	ctx context.Context,
+	builder DeleteBuilder[ENT_DELETE, PREDICATE],
+	olderThan time.Duration,
+	predicates ...PREDICATE,
+) (int, error) {
+	if builder == nil {
+		return 0, errors.New("query builder is nil")
+	}
+
+	deletedAt := r.deletedAtColumn
+	cutoff := softdelete.Cutoff(olderThan)
+	purged, err := selectorPredicate[PREDICATE](func(s *sql.Selector) {
+		s.Where(sql.And(
+			sql.NotNull(s.C(deletedAt)),
+			sql.LT(s.C(deletedAt), cutoff),
+		))
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(predicates) > 0 {
+		builder.Where(predicates...)
+	}
+	builder.Where(purged)
+
+	affected, err := builder.Exec(softdelete.HardDelete(softdelete.OnlyDeleted(ctx)))
+	if err != nil {
+		log.Errorf("purge failed: %s", err.Error())
+		return 0, errors.New("purge failed")
+	}
+
+	return affected, nil
+}
+
+// selectorPredicate 将 selector 函数转换为生成代码中的谓词类型（如 predicate.User）
+func selectorPredicate[PREDICATE any](fn func(*sql.Selector)) (PREDICATE, error) {
+	var p PREDICATE
+	pt := reflect.TypeOf(&p).Elem()
+	fv := reflect.ValueOf(fn)
+	if !fv.Type().ConvertibleTo(pt) {
+		return p, fmt.Errorf("predicate type %s is not a selector function", pt)
+	}
+	return fv.Convert(pt).Interface().(PREDICATE), nil
+}
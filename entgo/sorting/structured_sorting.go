@@ -3,20 +3,47 @@ package sorting
 import (
 	"entgo.io/ent/dialect/sql"
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
 )
 
 type StructuredSorting struct {
+	policy  *paginationFilter.FilterPolicy
+	mapping *fieldmap.Mapping
 }
 
 func NewStructuredSorting() *StructuredSorting {
 	return &StructuredSorting{}
 }
 
+// WithPolicy 设置字段策略，排序字段需通过策略校验，字段按策略映射为列名
+func (ss *StructuredSorting) WithPolicy(policy *paginationFilter.FilterPolicy) *StructuredSorting {
+	ss.policy = policy
+	return ss
+}
+
+// WithFieldMapping 设置字段映射，排序字段按映射替换为列名
+func (ss *StructuredSorting) WithFieldMapping(mapping *fieldmap.Mapping) *StructuredSorting {
+	ss.mapping = mapping
+	return ss
+}
+
+// Validate 按字段策略校验排序字段，返回按策略与字段映射替换为列名后的排序；未设置策略与映射时原样返回
+func (ss StructuredSorting) Validate(orders []*paginationV1.Sorting) ([]*paginationV1.Sorting, error) {
+	orders, err := ss.policy.CheckSorting(orders)
+	return ss.mapping.Sorting(orders), err
+}
+
 func (ss StructuredSorting) BuildSelector(orders []*paginationV1.Sorting) (func(s *sql.Selector), error) {
 	if len(orders) == 0 {
 		return nil, nil
 	}
 
+	orders, err := ss.Validate(orders)
+	if err != nil {
+		return nil, err
+	}
+
 	return func(s *sql.Selector) {
 		for _, order := range orders {
 			if order == nil || order.GetField() == "" {
@@ -1,12 +1,14 @@
 package sorting
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
 	"entgo.io/ent/dialect/sql"
 
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
 )
 
 func TestStructuredSorting_BuildSelector_Empty(t *testing.T) {
@@ -94,3 +96,24 @@ func TestStructuredSorting_BuildSelectorWithDefaultField(t *testing.T) {
 		t.Fatalf("expected ORDER BY score DESC, got: %s", sqlStr2)
 	}
 }
+
+func TestStructuredSorting_BuildSelector_Policy(t *testing.T) {
+	ss := NewStructuredSorting().WithPolicy(paginationFilter.NewFilterPolicy().
+		Field("createdAt", paginationFilter.FieldRule{Column: "created_at"}))
+
+	selFunc, err := ss.BuildSelector([]*paginationV1.Sorting{{Field: "createdAt", Direction: paginationV1.Sorting_DESC}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := sql.Select("t.*").From(sql.Table("t"))
+	selFunc(s)
+	sqlStr, _ := s.Query()
+	if !strings.Contains(sqlStr, "created_at") || strings.Contains(sqlStr, "createdAt") {
+		t.Fatalf("expected ordering by mapped column, got: %s", sqlStr)
+	}
+
+	if _, err = ss.BuildSelector([]*paginationV1.Sorting{{Field: "password"}}); !errors.Is(err, paginationFilter.ErrPolicyViolation) {
+		t.Fatalf("expected ErrPolicyViolation, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package entgo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"entgo.io/ent/dialect"
+
+	"github.com/tx7do/go-crud/pagination/transaction"
+)
+
+var _ dialect.Driver = (*TxDriver)(nil)
+
+// TxDriver 包装 Ent 的 dialect.Driver：ctx 中存在 RunInTx 开启的事务时，查询与执行自动使用该事务。
+// 创建 client 时使用该驱动，生成代码中的 builder 与 Repository 即可感知事务：
+//
+//	client := ent.NewClient(ent.Driver(entgo.NewTxDriver(drv)))
+type TxDriver struct {
+	dialect.Driver
+}
+
+// NewTxDriver 创建感知 ctx 事务的驱动
+func NewTxDriver(drv dialect.Driver) *TxDriver {
+	return &TxDriver{Driver: drv}
+}
+
+type txKey struct{}
+
+// txState ctx 中的事务状态，depth 为保存点的嵌套深度
+type txState struct {
+	drv   *TxDriver
+	tx    dialect.Tx
+	depth int
+}
+
+// txFromContext 返回 ctx 中由该驱动开启的事务
+func (d *TxDriver) txFromContext(ctx context.Context) *txState {
+	if ctx == nil {
+		return nil
+	}
+	if st, ok := ctx.Value(txKey{}).(*txState); ok && st.drv == d {
+		return st
+	}
+	return nil
+}
+
+// Exec ctx 中存在事务时在事务中执行
+func (d *TxDriver) Exec(ctx context.Context, query string, args, v any) error {
+	if st := d.txFromContext(ctx); st != nil {
+		return st.tx.Exec(ctx, query, args, v)
+	}
+	return d.Driver.Exec(ctx, query, args, v)
+}
+
+// Query ctx 中存在事务时在事务中查询
+func (d *TxDriver) Query(ctx context.Context, query string, args, v any) error {
+	if st := d.txFromContext(ctx); st != nil {
+		return st.tx.Query(ctx, query, args, v)
+	}
+	return d.Driver.Query(ctx, query, args, v)
+}
+
+// Tx ctx 中存在事务时返回提交与回滚均为空操作的事务，由 RunInTx 统一提交
+func (d *TxDriver) Tx(ctx context.Context) (dialect.Tx, error) {
+	return d.BeginTx(ctx, nil)
+}
+
+// BeginTx 按选项开启事务，ctx 中存在事务时返回提交与回滚均为空操作的事务
+func (d *TxDriver) BeginTx(ctx context.Context, opts *sql.TxOptions) (dialect.Tx, error) {
+	if st := d.txFromContext(ctx); st != nil {
+		return nopTx{Tx: st.tx}, nil
+	}
+	if b, ok := d.Driver.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (dialect.Tx, error)
+	}); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return d.Driver.Tx(ctx)
+}
+
+// nopTx 提交与回滚均为空操作的事务
+type nopTx struct {
+	dialect.Tx
+}
+
+func (nopTx) Commit() error   { return nil }
+func (nopTx) Rollback() error { return nil }
+
+// RunInTx 在事务中执行 fn：事务保存在传给 fn 的 ctx 中，使用 TxDriver 创建的 client 执行的操作会自动加入该事务。
+// 嵌套调用使用保存点（SAVEPOINT），fn 返回错误时只回滚到保存点；
+// 最外层事务遇到可重试的错误（序列化失败、死锁）时整体重试，opts 可设置隔离级别、只读与重试策略。
+func RunInTx(ctx context.Context, drv *TxDriver, fn func(ctx context.Context) error, opts ...transaction.Option) error {
+	if drv == nil {
+		return errors.New("driver is nil")
+	}
+	if fn == nil {
+		return errors.New("transaction func is nil")
+	}
+
+	// 嵌套事务：使用保存点
+	if st := drv.txFromContext(ctx); st != nil {
+		return runInSavepoint(ctx, st, fn)
+	}
+
+	o := transaction.NewOptions(opts...)
+	return o.Retry(ctx, func() (err error) {
+		tx, err := drv.BeginTx(ctx, o.TxOptions())
+		if err != nil {
+			return fmt.Errorf("begin transaction failed: %w", err)
+		}
+		defer MakeTxCleanup(tx, &err)()
+
+		return fn(context.WithValue(ctx, txKey{}, &txState{drv: drv, tx: tx}))
+	})
+}
+
+// runInSavepoint 在保存点中执行 fn，出错或 panic 时回滚到保存点
+func runInSavepoint(ctx context.Context, st *txState, fn func(ctx context.Context) error) (err error) {
+	inner := &txState{drv: st.drv, tx: st.tx, depth: st.depth + 1}
+	name := fmt.Sprintf("sp_%d", inner.depth)
+
+	if err = st.tx.Exec(ctx, "SAVEPOINT "+name, []any{}, nil); err != nil {
+		return fmt.Errorf("create savepoint failed: %w", err)
+	}
+
+	panicked := true
+	defer func() {
+		if !panicked && err == nil {
+			if rErr := st.tx.Exec(ctx, "RELEASE SAVEPOINT "+name, []any{}, nil); rErr != nil {
+				err = fmt.Errorf("release savepoint failed: %w", rErr)
+			}
+			return
+		}
+		rErr := st.tx.Exec(ctx, "ROLLBACK TO SAVEPOINT "+name, []any{}, nil)
+		if rErr != nil && err != nil {
+			err = fmt.Errorf("%w: rollback to savepoint failed: %v", err, rErr)
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{}, inner))
+	panicked = false
+	return err
+}
@@ -0,0 +1,107 @@
+package entgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tx7do/go-crud/entgo/ent"
+	"github.com/tx7do/go-crud/entgo/ent/migrate"
+	"github.com/tx7do/go-crud/entgo/ent/user"
+	"github.com/tx7do/go-crud/pagination/transaction"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+// retryableError 模拟序列化失败
+type retryableError struct{}
+
+func (retryableError) Error() string    { return "could not serialize access" }
+func (retryableError) SQLState() string { return "40001" }
+
+func TestRunInTx(t *testing.T) {
+	drv, err := CreateDriver("sqlite3", "file:ent_tx?mode=memory&cache=shared&_fk=1", false, false)
+	if err != nil {
+		t.Fatalf("failed opening connection to db: %v", err)
+	}
+	// 单连接：事务外的查询会阻塞，确保事务内的操作都经过事务
+	drv.DB().SetMaxOpenConns(1)
+
+	txDrv := NewTxDriver(drv)
+	client := ent.NewClient(ent.Driver(txDrv))
+	defer client.Close()
+
+	ctx := viewer.WithContext(t.Context(), testContext{})
+	if err = client.Schema.Create(ctx, migrate.WithForeignKeys(true)); err != nil {
+		t.Fatalf("failed creating schema resources: %v", err)
+	}
+	countOf := func() int {
+		n, err := client.User.Query().Where(user.NameHasPrefix("tx_")).Count(ctx)
+		if err != nil {
+			t.Fatalf("count: %v", err)
+		}
+		return n
+	}
+
+	// 出错时整体回滚
+	errBoom := errors.New("boom")
+	err = RunInTx(ctx, txDrv, func(ctx context.Context) error {
+		if _, err := client.User.Create().SetName("tx_a").Save(ctx); err != nil {
+			return err
+		}
+		if n, err := client.User.Query().Where(user.NameEQ("tx_a")).Count(ctx); err != nil || n != 1 {
+			t.Fatalf("count inside tx: %d, %v", n, err)
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) || countOf() != 0 {
+		t.Fatalf("rollback: %v, %d", err, countOf())
+	}
+
+	// 嵌套事务使用保存点，内层失败只回滚内层；生成代码中的 client.Tx 加入外层事务
+	err = RunInTx(ctx, txDrv, func(ctx context.Context) error {
+		tx, err := client.Tx(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err = tx.User.Create().SetName("tx_outer").Save(ctx); err != nil {
+			return err
+		}
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+
+		innerErr := RunInTx(ctx, txDrv, func(ctx context.Context) error {
+			if _, err := client.User.Create().SetName("tx_inner").Save(ctx); err != nil {
+				return err
+			}
+			return errBoom
+		})
+		if !errors.Is(innerErr, errBoom) {
+			t.Fatalf("inner: %v", innerErr)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("outer: %v", err)
+	}
+	names, err := client.User.Query().Where(user.NameHasPrefix("tx_")).Select(user.FieldName).Strings(ctx)
+	if err != nil || len(names) != 1 || names[0] != "tx_outer" {
+		t.Fatalf("savepoint rollback: %v, %v", names, err)
+	}
+
+	// 可重试的错误整体重试
+	attempts := 0
+	err = RunInTx(ctx, txDrv, func(ctx context.Context) error {
+		attempts++
+		if _, err := client.User.Create().SetName("tx_retry").Save(ctx); err != nil {
+			return err
+		}
+		if attempts < 2 {
+			return retryableError{}
+		}
+		return nil
+	}, transaction.WithRetryBackoff(0))
+	if err != nil || attempts != 2 || countOf() != 2 {
+		t.Fatalf("retry: %v, %d, %d", err, attempts, countOf())
+	}
+}
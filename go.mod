@@ -1,3 +1,19 @@
 module github.com/tx7do/go-crud
 
-go 1.24.6
+go 1.24.11
+
+replace github.com/tx7do/go-crud/api => ./api
+
+replace github.com/tx7do/go-crud/pagination => ./pagination
+
+require (
+	github.com/tx7do/go-crud/api v0.0.7
+	github.com/tx7do/go-crud/pagination v0.0.11
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	github.com/google/gnostic v0.7.1 // indirect
+	github.com/google/gnostic-models v0.7.1 // indirect
+	go.yaml.in/yaml/v3 v3.0.4 // indirect
+)
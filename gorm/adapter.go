@@ -0,0 +1,119 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"gorm.io/gorm"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+var _ crud.Repository[struct{}] = (*RepositoryAdapter[struct{}, struct{}])(nil)
+var _ crud.SoftDeleteRepository = (*RepositoryAdapter[struct{}, struct{}])(nil)
+
+// RepositoryAdapter 将 GORM Repository 适配为通用的 crud.Repository 接口
+type RepositoryAdapter[DTO any, ENTITY any] struct {
+	repo *Repository[DTO, ENTITY]
+	db   *gorm.DB
+}
+
+// NewRepositoryAdapter 创建 GORM 仓库适配器，db 为执行所有操作时使用的连接
+func NewRepositoryAdapter[DTO any, ENTITY any](repo *Repository[DTO, ENTITY], db *gorm.DB) *RepositoryAdapter[DTO, ENTITY] {
+	return &RepositoryAdapter[DTO, ENTITY]{
+		repo: repo,
+		db:   db,
+	}
+}
+
+// whereSelectors 将 FilterExpr 编译为 GORM where scopes
+func (a *RepositoryAdapter[DTO, ENTITY]) whereSelectors(filter *paginationV1.FilterExpr) ([]func(*gorm.DB) *gorm.DB, error) {
+	if filter == nil {
+		return nil, nil
+	}
+	return a.repo.structuredFilter.BuildSelectors(filter)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) List(ctx context.Context, req *paginationV1.PaginationRequest) (*crud.PagingResult[DTO], error) {
+	return a.repo.ListWithPagination(ctx, a.db, req)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Get(ctx context.Context, filter *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	whereSelectors, err := a.whereSelectors(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	dto, err := a.repo.GetWithFilters(ctx, a.db, whereSelectors, viewMask)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, crud.ErrNotFound
+	}
+	return dto, err
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Create(ctx context.Context, dto *DTO, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	return a.repo.Create(ctx, a.db, dto, viewMask)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Update(ctx context.Context, filter *paginationV1.FilterExpr, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return nil, err
+	}
+
+	whereSelectors, err := a.whereSelectors(filter)
+	if err != nil {
+		return nil, err
+	}
+	return a.repo.UpdateWithFilters(ctx, a.db, whereSelectors, dto, updateMask)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Upsert(ctx context.Context, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	return a.repo.Upsert(ctx, a.db, dto, updateMask)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Delete(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return 0, err
+	}
+
+	whereSelectors, err := a.whereSelectors(filter)
+	if err != nil {
+		return 0, err
+	}
+	return a.repo.DeleteWithFilters(ctx, a.db, whereSelectors)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Count(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	whereSelectors, err := a.whereSelectors(filter)
+	if err != nil {
+		return 0, err
+	}
+	return a.repo.Count(ctx, a.db, whereSelectors)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Exists(ctx context.Context, filter *paginationV1.FilterExpr) (bool, error) {
+	whereSelectors, err := a.whereSelectors(filter)
+	if err != nil {
+		return false, err
+	}
+	return a.repo.ExistsWithFilters(ctx, a.db, whereSelectors)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Restore(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	whereSelectors, err := a.whereSelectors(filter)
+	if err != nil {
+		return 0, err
+	}
+	return a.repo.Restore(ctx, a.db, whereSelectors)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Purge(ctx context.Context, filter *paginationV1.FilterExpr, olderThan time.Duration) (int64, error) {
+	whereSelectors, err := a.whereSelectors(filter)
+	if err != nil {
+		return 0, err
+	}
+	return a.repo.Purge(ctx, a.db, olderThan, whereSelectors)
+}
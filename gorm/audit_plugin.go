@@ -0,0 +1,472 @@
+package gorm
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/tx7do/go-crud/audit"
+	"github.com/tx7do/go-crud/outbox"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+// DefaultAuditMaxRows 批量更新/删除时默认加载旧值的最大行数
+const DefaultAuditMaxRows = 1000
+
+const auditStateKey = "crud:audit:state"
+
+// AuditPluginOption AuditPlugin 的选项
+type AuditPluginOption func(p *AuditPlugin)
+
+// WithAuditSensitiveFields 追加需要脱敏的字段（按包含关系匹配列名）
+func WithAuditSensitiveFields(fields ...string) AuditPluginOption {
+	return func(p *AuditPlugin) {
+		p.sensitive.Fields = append(p.sensitive.Fields, fields...)
+	}
+}
+
+// WithAuditNonSensitiveFields 设置不需要脱敏的字段（精确匹配列名）
+func WithAuditNonSensitiveFields(fields ...string) AuditPluginOption {
+	return func(p *AuditPlugin) {
+		p.sensitive.NonFields = append(p.sensitive.NonFields, fields...)
+	}
+}
+
+// WithAuditSkipTables 设置不需要审计的表，默认跳过 DefaultAuditTable
+func WithAuditSkipTables(tables ...string) AuditPluginOption {
+	return func(p *AuditPlugin) {
+		for _, t := range tables {
+			p.skipTables[t] = struct{}{}
+		}
+	}
+}
+
+// WithAuditDisablePreValue 关闭更新/删除前旧值的加载
+func WithAuditDisablePreValue() AuditPluginOption {
+	return func(p *AuditPlugin) {
+		p.disablePreValue = true
+	}
+}
+
+// WithAuditMaxRows 设置批量更新/删除时加载旧值的最大行数
+func WithAuditMaxRows(n int) AuditPluginOption {
+	return func(p *AuditPlugin) {
+		if n > 0 {
+			p.maxRows = n
+		}
+	}
+}
+
+// AuditPlugin 与 entgo 审计 Mixin 等价的 GORM 插件：
+//   - 在 create/update/delete 回调中，当 viewer.Context.ShouldAudit() 为 true 且 context 中存在 Auditor 时记录审计日志；
+//   - 更新/删除前按语句的 WHERE 条件与主键加载受影响的行，填充 PreValue，并为更新计算字段级差异；
+//   - 批量操作按行生成审计日志，通过 audit.DispatcherFromContext 投递。
+type AuditPlugin struct {
+	sensitive       audit.SensitiveMatcher
+	skipTables      map[string]struct{}
+	disablePreValue bool
+	maxRows         int
+}
+
+var _ gorm.Plugin = (*AuditPlugin)(nil)
+
+// NewAuditPlugin 创建审计插件，通过 db.Use 或 Client.Use(AuditMixin(...)) 注册
+func NewAuditPlugin(opts ...AuditPluginOption) *AuditPlugin {
+	p := &AuditPlugin{
+		skipTables: map[string]struct{}{DefaultAuditTable: {}, outbox.DefaultTable: {}},
+		maxRows:    DefaultAuditMaxRows,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+	return p
+}
+
+// AuditMixin 将审计插件包装为 Mixin，供 Client.Use / WithMixin 使用
+func AuditMixin(opts ...AuditPluginOption) Mixin {
+	return func(db *gorm.DB) error {
+		return db.Use(NewAuditPlugin(opts...))
+	}
+}
+
+func (p *AuditPlugin) Name() string {
+	return "crud:audit"
+}
+
+func (p *AuditPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("crud:audit:before_create", p.before(false)); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("crud:audit:after_create", p.afterCreate); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("crud:audit:before_update", p.before(true)); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("crud:audit:after_update", p.afterUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("crud:audit:before_delete", p.before(true)); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Register("crud:audit:after_delete", p.afterDelete)
+}
+
+// auditState 同一语句在 before/after 回调之间传递的状态
+type auditState struct {
+	viewer  viewer.Context
+	auditor audit.Auditor
+	start   time.Time
+	pre     []map[string]any
+}
+
+// before 判断是否需要审计，并在需要时加载变更前的行
+func (p *AuditPlugin) before(loadPre bool) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.Statement.Context == nil {
+			return
+		}
+		if _, skip := p.skipTables[db.Statement.Table]; skip {
+			return
+		}
+
+		ctx := db.Statement.Context
+		vc, ok := viewer.FromContext(ctx)
+		if !ok || !vc.ShouldAudit() {
+			return
+		}
+		ac, ok := audit.FromContext(ctx)
+		if !ok || ac == nil {
+			log.Printf("[Audit][WARN] missing AuditContext, Trace=%s, User=%d, Resource=%s", vc.TraceID(), vc.UserID(), db.Statement.Table)
+			return
+		}
+
+		state := &auditState{viewer: vc, auditor: ac, start: time.Now()}
+		if loadPre && !p.disablePreValue {
+			rows, err := p.loadRows(db, nil)
+			if err != nil {
+				log.Printf("[Audit][WARN] load pre values failed, Resource=%s: %v", db.Statement.Table, err)
+			}
+			state.pre = rows
+		}
+
+		db.InstanceSet(auditStateKey, state)
+	}
+}
+
+func (p *AuditPlugin) state(db *gorm.DB) *auditState {
+	if db.Error != nil {
+		return nil
+	}
+	v, ok := db.InstanceGet(auditStateKey)
+	if !ok {
+		return nil
+	}
+	s, _ := v.(*auditState)
+	return s
+}
+
+func (p *AuditPlugin) afterCreate(db *gorm.DB) {
+	s := p.state(db)
+	if s == nil {
+		return
+	}
+
+	op := audit.OpInsert
+	if _, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		op = audit.OpUpsert
+	}
+
+	rows := createdRows(db.Statement)
+	entries := make([]*audit.Entry, 0, len(rows))
+	for _, row := range rows {
+		entry := p.newEntry(db, s, op, row)
+		p.setValues(entry, nil, row, false)
+		entries = append(entries, entry)
+	}
+	p.dispatch(db, s, entries)
+}
+
+func (p *AuditPlugin) afterUpdate(db *gorm.DB) {
+	s := p.state(db)
+	if s == nil {
+		return
+	}
+
+	// 未能加载旧值时，只记录本次更新的字段
+	if len(s.pre) == 0 {
+		entry := p.newEntry(db, s, audit.OpUpdate, nil)
+		p.setValues(entry, nil, updatedValues(db.Statement), false)
+		p.dispatch(db, s, []*audit.Entry{entry})
+		return
+	}
+
+	// 按主键重新加载，得到完整的变更后数据
+	pk := primaryKeyColumn(db.Statement)
+	posts := map[string]map[string]any{}
+	if pk != "" {
+		ids := make([]any, 0, len(s.pre))
+		for _, row := range s.pre {
+			ids = append(ids, row[pk])
+		}
+		rows, err := p.loadRows(db, &clause.IN{Column: clause.Column{Name: pk}, Values: ids})
+		if err != nil {
+			log.Printf("[Audit][WARN] load post values failed, Resource=%s: %v", db.Statement.Table, err)
+		}
+		for _, row := range rows {
+			posts[fmt.Sprintf("%v", row[pk])] = row
+		}
+	}
+
+	entries := make([]*audit.Entry, 0, len(s.pre))
+	for _, pre := range s.pre {
+		post, ok := posts[fmt.Sprintf("%v", pre[pk])]
+		if !ok {
+			post = mergeValues(pre, updatedValues(db.Statement))
+		}
+		entry := p.newEntry(db, s, audit.OpUpdate, pre)
+		p.setValues(entry, pre, post, true)
+		entries = append(entries, entry)
+	}
+	p.dispatch(db, s, entries)
+}
+
+func (p *AuditPlugin) afterDelete(db *gorm.DB) {
+	s := p.state(db)
+	if s == nil {
+		return
+	}
+
+	if len(s.pre) == 0 {
+		p.dispatch(db, s, []*audit.Entry{p.newEntry(db, s, audit.OpDelete, nil)})
+		return
+	}
+
+	entries := make([]*audit.Entry, 0, len(s.pre))
+	for _, pre := range s.pre {
+		entry := p.newEntry(db, s, audit.OpDelete, pre)
+		p.setValues(entry, pre, nil, false)
+		entries = append(entries, entry)
+	}
+	p.dispatch(db, s, entries)
+}
+
+// newEntry 根据 Viewer 与语句构造审计日志，row 用于提取 TargetID
+func (p *AuditPlugin) newEntry(db *gorm.DB, s *auditState, op audit.Operation, row map[string]any) *audit.Entry {
+	entry := &audit.Entry{
+		TraceID:   s.viewer.TraceID(),
+		TenantID:  s.viewer.TenantID(),
+		UserID:    s.viewer.UserID(),
+		Timestamp: time.Now(),
+		Resource:  db.Statement.Table,
+		Operation: op,
+		Status:    audit.StatusOK,
+		CostMS:    time.Since(s.start).Milliseconds(),
+	}
+	if pk := primaryKeyColumn(db.Statement); pk != "" && row != nil {
+		if id, ok := row[pk]; ok && id != nil {
+			entry.TargetID = fmt.Sprintf("%v", id)
+		}
+	}
+	return entry
+}
+
+// setValues 计算差异并对敏感字段脱敏后写入 PreValue、PostValue 与 Extra
+func (p *AuditPlugin) setValues(entry *audit.Entry, pre, post map[string]any, withDiff bool) {
+	if withDiff && pre != nil && post != nil {
+		diff := audit.ComputeDiff(pre, post)
+		diff.Mask(p.sensitive.Match, audit.MaskedValue)
+		entry.Extra = map[string]any{audit.ExtraKeyDiff: diff}
+	}
+	if len(pre) > 0 {
+		_ = entry.SetPreValue(p.sensitive.Mask(pre))
+	}
+	if len(post) > 0 {
+		_ = entry.SetPostValue(p.sensitive.Mask(post))
+	}
+}
+
+func (p *AuditPlugin) dispatch(db *gorm.DB, s *auditState, entries []*audit.Entry) {
+	ctx := db.Statement.Context
+	dispatcher := audit.DispatcherFromContext(ctx)
+	for _, entry := range entries {
+		if err := dispatcher.Dispatch(ctx, s.auditor, entry); err != nil {
+			log.Printf("[Audit][ERROR] dispatch failed: %v", err)
+		}
+	}
+}
+
+// loadRows 在同一连接（事务）中按语句的 WHERE 条件与主键查询受影响的行；
+// extra 不为空时替代原语句条件（用于变更后按主键重新加载）
+func (p *AuditPlugin) loadRows(db *gorm.DB, extra clause.Expression) ([]map[string]any, error) {
+	return loadStatementRows(db, extra, p.maxRows)
+}
+
+// loadStatementRows 在同一连接（事务）中按语句的 WHERE 条件与主键查询受影响的行，最多 limit 行（limit <= 0 不限制）；
+// extra 不为空时替代原语句条件
+func loadStatementRows(db *gorm.DB, extra clause.Expression, limit int) ([]map[string]any, error) {
+	stmt := db.Statement
+
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true, Context: stmt.Context})
+	if stmt.Schema != nil {
+		tx = tx.Model(reflect.New(stmt.Schema.ModelType).Interface())
+	}
+	tx = tx.Table(stmt.Table)
+
+	if extra != nil {
+		tx = tx.Clauses(clause.Where{Exprs: []clause.Expression{extra}})
+	} else {
+		conds := statementConditions(stmt)
+		if len(conds) == 0 {
+			// 无条件的全表更新/删除，不加载旧值
+			return nil, nil
+		}
+		tx = tx.Clauses(clause.Where{Exprs: conds})
+	}
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+
+	var rows []map[string]any
+	if err := tx.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// statementConditions 汇总语句中已有的 WHERE 条件，以及 gorm 在执行时才会追加的主键条件
+func statementConditions(stmt *gorm.Statement) []clause.Expression {
+	var conds []clause.Expression
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			conds = append(conds, where.Exprs...)
+		}
+	}
+	if stmt.Schema == nil || len(stmt.Schema.PrimaryFields) == 0 {
+		return conds
+	}
+
+	addPrimaryKeys := func(rv reflect.Value) {
+		rv = reflect.Indirect(rv)
+		if !rv.IsValid() || (rv.Kind() != reflect.Struct && rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+			return
+		}
+		if rv.Kind() == reflect.Struct && rv.Type() != stmt.Schema.ModelType {
+			return
+		}
+		_, values := schema.GetIdentityFieldValuesMap(stmt.Context, rv, stmt.Schema.PrimaryFields)
+		column, queryValues := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, values)
+		if len(queryValues) > 0 {
+			conds = append(conds, clause.IN{Column: column, Values: queryValues})
+		}
+	}
+
+	addPrimaryKeys(stmt.ReflectValue)
+	if stmt.Model != nil && stmt.Dest != stmt.Model {
+		addPrimaryKeys(reflect.ValueOf(stmt.Model))
+	}
+	return conds
+}
+
+// primaryKeyColumn 返回主键列名，无 schema 时回退为 id
+func primaryKeyColumn(stmt *gorm.Statement) string {
+	if stmt.Schema != nil {
+		if f := stmt.Schema.PrioritizedPrimaryField; f != nil {
+			return f.DBName
+		}
+		return ""
+	}
+	return "id"
+}
+
+// createdRows 将创建语句中的实体（单个或切片）转换为以列名为键的 map
+func createdRows(stmt *gorm.Statement) []map[string]any {
+	switch dest := stmt.Dest.(type) {
+	case map[string]any:
+		return []map[string]any{dest}
+	case *map[string]any:
+		return []map[string]any{*dest}
+	case []map[string]any:
+		return dest
+	case *[]map[string]any:
+		return *dest
+	}
+
+	if stmt.Schema == nil {
+		return nil
+	}
+
+	rv := reflect.Indirect(stmt.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Struct:
+		return []map[string]any{structValues(stmt, rv)}
+	case reflect.Slice, reflect.Array:
+		rows := make([]map[string]any, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			rows = append(rows, structValues(stmt, reflect.Indirect(rv.Index(i))))
+		}
+		return rows
+	}
+	return nil
+}
+
+// structValues 按 schema 读取实体的列值
+func structValues(stmt *gorm.Statement, rv reflect.Value) map[string]any {
+	row := make(map[string]any, len(stmt.Schema.DBNames))
+	for _, name := range stmt.Schema.DBNames {
+		f := stmt.Schema.LookUpField(name)
+		if f == nil {
+			continue
+		}
+		v, _ := f.ValueOf(stmt.Context, rv)
+		row[name] = v
+	}
+	return row
+}
+
+// updatedValues 提取更新语句中设置的列值
+func updatedValues(stmt *gorm.Statement) map[string]any {
+	values := map[string]any{}
+
+	if c, ok := stmt.Clauses["SET"]; ok {
+		if set, ok := c.Expression.(clause.Set); ok {
+			for _, a := range set {
+				values[a.Column.Name] = a.Value
+			}
+			return values
+		}
+	}
+
+	switch dest := stmt.Dest.(type) {
+	case map[string]any:
+		for k, v := range dest {
+			values[k] = v
+		}
+	case *map[string]any:
+		for k, v := range *dest {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+// mergeValues 将变更字段覆盖到旧值上，作为无法重新加载时的变更后数据
+func mergeValues(pre, changes map[string]any) map[string]any {
+	merged := make(map[string]any, len(pre)+len(changes))
+	for k, v := range pre {
+		merged[k] = v
+	}
+	for k, v := range changes {
+		merged[k] = v
+	}
+	return merged
+}
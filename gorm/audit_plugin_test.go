@@ -0,0 +1,157 @@
+package gorm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+
+	"github.com/tx7do/go-crud/audit"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+type auditTestAccount struct {
+	ID       uint `gorm:"primarykey"`
+	Name     string
+	Age      int
+	Password string
+}
+
+// auditViewer 需要审计的 Viewer
+type auditViewer struct{ viewer.Context }
+
+func (auditViewer) UserID() uint64    { return 7 }
+func (auditViewer) TenantID() uint64  { return 3 }
+func (auditViewer) TraceID() string   { return "trace-gorm" }
+func (auditViewer) ShouldAudit() bool { return true }
+
+// memoryAuditor 收集审计日志
+type memoryAuditor struct {
+	mu      sync.Mutex
+	entries []*audit.Entry
+}
+
+func (a *memoryAuditor) Record(_ context.Context, e *audit.Entry) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, e)
+	return nil
+}
+
+func (a *memoryAuditor) Flush(context.Context) error { return nil }
+
+func TestAuditPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&auditTestAccount{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err = db.Use(NewAuditPlugin()); err != nil {
+		t.Fatalf("use plugin: %v", err)
+	}
+
+	auditor := &memoryAuditor{}
+
+	// run 在独立的 Dispatcher 下执行 fn，关闭后返回收集到的审计日志
+	run := func(fn func(tx *gorm.DB)) []*audit.Entry {
+		t.Helper()
+		d := audit.NewDispatcher()
+		ctx := viewer.WithContext(context.Background(), auditViewer{Context: viewer.NewNoopContext()})
+		ctx = audit.WithDispatcher(audit.WithAuditor(ctx, auditor), d)
+		fn(db.WithContext(ctx))
+		if err := d.Close(context.Background()); err != nil {
+			t.Fatalf("close dispatcher: %v", err)
+		}
+		auditor.mu.Lock()
+		defer auditor.mu.Unlock()
+		entries := auditor.entries
+		auditor.entries = nil
+		return entries
+	}
+
+	// 批量创建：每行一条
+	entries := run(func(tx *gorm.DB) {
+		tx.Create(&[]auditTestAccount{
+			{Name: "alice", Age: 20, Password: "p1"},
+			{Name: "bob", Age: 20, Password: "p2"},
+		})
+	})
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 create entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.Operation != audit.OpInsert || e.TargetID == "" || e.PreValue != nil || e.UserID != 7 || e.TenantID != 3 {
+			t.Fatalf("unexpected create entry: %+v", e)
+		}
+		var post map[string]any
+		_ = json.Unmarshal(e.PostValue, &post)
+		if post["password"] != audit.MaskedValue {
+			t.Fatalf("password should be masked: %v", post)
+		}
+	}
+
+	// 批量更新：每行一条，带旧值与差异
+	entries = run(func(tx *gorm.DB) {
+		tx.Model(&auditTestAccount{}).Where("age = ?", 20).Update("age", 21)
+	})
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 update entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.Operation != audit.OpUpdate || e.PreValue == nil || e.PostValue == nil {
+			t.Fatalf("unexpected update entry: %+v", e)
+		}
+		diff, _ := e.Extra[audit.ExtraKeyDiff].(*audit.Diff)
+		if diff == nil || len(diff.Changed) != 1 {
+			t.Fatalf("expected only age to change, got %+v", diff)
+		}
+		if c := diff.Changed["age"]; fmt.Sprint(c.Old) != "20" || fmt.Sprint(c.New) != "21" {
+			t.Fatalf("unexpected age change: %+v", c)
+		}
+	}
+
+	// 按主键更新单个实体
+	var alice auditTestAccount
+	db.Where("name = ?", "alice").First(&alice)
+	entries = run(func(tx *gorm.DB) {
+		tx.Model(&alice).Updates(map[string]any{"name": "alice2"})
+	})
+	if len(entries) != 1 || entries[0].TargetID != "1" {
+		t.Fatalf("expected a single entry for alice, got %+v", entries)
+	}
+
+	// Upsert
+	entries = run(func(tx *gorm.DB) {
+		tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&auditTestAccount{ID: alice.ID, Name: "alice3"})
+	})
+	if len(entries) != 1 || entries[0].Operation != audit.OpUpsert {
+		t.Fatalf("expected an upsert entry, got %+v", entries)
+	}
+
+	// 删除：只有旧值
+	entries = run(func(tx *gorm.DB) {
+		tx.Where("age = ?", 21).Delete(&auditTestAccount{})
+	})
+	if len(entries) != 1 || entries[0].Operation != audit.OpDelete || entries[0].PreValue == nil || entries[0].PostValue != nil {
+		t.Fatalf("unexpected delete entries: %+v", entries)
+	}
+
+	// 未开启审计的 Viewer 不产生日志
+	d := audit.NewDispatcher()
+	ctx := audit.WithDispatcher(audit.WithAuditor(context.Background(), auditor), d)
+	db.WithContext(ctx).Create(&auditTestAccount{Name: "carol"})
+	_ = d.Close(context.Background())
+	if len(auditor.entries) != 0 {
+		t.Fatalf("expected no entries without viewer, got %d", len(auditor.entries))
+	}
+}
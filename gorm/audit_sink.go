@@ -0,0 +1,118 @@
+package gorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tx7do/go-crud/audit"
+)
+
+// DefaultAuditTable 审计日志表的默认表名
+const DefaultAuditTable = "audit_logs"
+
+// AuditLog 审计日志表结构
+type AuditLog struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement"`
+	TraceID   string    `gorm:"column:trace_id;size:64;index"`
+	Timestamp time.Time `gorm:"column:timestamp;index"`
+
+	UserID    uint64 `gorm:"column:user_id;index"`
+	TenantID  uint64 `gorm:"column:tenant_id;index"`
+	Username  string `gorm:"column:username;size:128"`
+	UserIP    string `gorm:"column:user_ip;size:64"`
+	UserAgent string `gorm:"column:user_agent;size:512"`
+
+	Service  string `gorm:"column:service;size:128"`
+	Module   string `gorm:"column:module;size:128"`
+	Action   string `gorm:"column:action;size:128"`
+	Resource string `gorm:"column:resource;size:255"`
+
+	Operation string `gorm:"column:operation;size:16"`
+	TargetID  string `gorm:"column:target_id;size:128;index"`
+	PreValue  string `gorm:"column:pre_value;type:text"`
+	PostValue string `gorm:"column:post_value;type:text"`
+
+	Status       int32  `gorm:"column:status"`
+	ErrorMessage string `gorm:"column:error_message;type:text"`
+	CostMS       int64  `gorm:"column:cost_ms"`
+
+	Extra string `gorm:"column:extra;type:text"`
+}
+
+// NewAuditLog 将审计日志条目转换为表记录
+func NewAuditLog(e *audit.Entry) *AuditLog {
+	row := &AuditLog{
+		TraceID:      e.TraceID,
+		Timestamp:    e.Timestamp,
+		UserID:       e.UserID,
+		TenantID:     e.TenantID,
+		Username:     e.Username,
+		UserIP:       e.UserIP,
+		UserAgent:    e.UserAgent,
+		Service:      e.Service,
+		Module:       e.Module,
+		Action:       e.Action,
+		Resource:     e.Resource,
+		Operation:    string(e.Operation),
+		TargetID:     e.TargetID,
+		PreValue:     string(e.PreValue),
+		PostValue:    string(e.PostValue),
+		Status:       int32(e.Status),
+		ErrorMessage: e.ErrorMessage,
+		CostMS:       e.CostMS,
+	}
+	if len(e.Extra) > 0 {
+		if b, err := json.Marshal(e.Extra); err == nil {
+			row.Extra = string(b)
+		}
+	}
+	return row
+}
+
+// AuditSink 将审计日志批量写入数据库表，可与 audit.NewBufferedAuditor 配合使用
+type AuditSink struct {
+	db    *gorm.DB
+	table string
+}
+
+var _ audit.Sink = (*AuditSink)(nil)
+
+// NewAuditSink 创建数据库审计 Sink，table 为空时使用 DefaultAuditTable
+func NewAuditSink(db *gorm.DB, table string) *AuditSink {
+	if table == "" {
+		table = DefaultAuditTable
+	}
+	return &AuditSink{db: db, table: table}
+}
+
+// Migrate 创建或更新审计日志表
+func (s *AuditSink) Migrate(ctx context.Context) error {
+	if s.db == nil {
+		return errors.New("db is nil")
+	}
+	return s.db.WithContext(ctx).Table(s.table).AutoMigrate(&AuditLog{})
+}
+
+func (s *AuditSink) Write(ctx context.Context, entries []*audit.Entry) error {
+	if s.db == nil {
+		return errors.New("db is nil")
+	}
+
+	rows := make([]*AuditLog, 0, len(entries))
+	for _, e := range entries {
+		if e != nil {
+			rows = append(rows, NewAuditLog(e))
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return s.db.WithContext(ctx).Table(s.table).CreateInBatches(rows, len(rows)).Error
+}
+
+func (s *AuditSink) Close() error { return nil }
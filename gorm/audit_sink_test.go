@@ -0,0 +1,51 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/tx7do/go-crud/audit"
+)
+
+func TestAuditSink_Write(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+
+	ctx := context.Background()
+	sink := NewAuditSink(db, "")
+	if err = sink.Migrate(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	a := audit.NewBufferedAuditor(sink, audit.WithBatchSize(2))
+	for i := 0; i < 3; i++ {
+		_ = a.Record(ctx, &audit.Entry{
+			TenantID:  1,
+			Action:    "create",
+			Operation: audit.OpInsert,
+			Extra:     map[string]any{"i": i},
+		})
+	}
+	if err = a.Close(ctx); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	var rows []AuditLog
+	if err = db.Table(DefaultAuditTable).Find(&rows).Error; err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 audit rows, got %d", len(rows))
+	}
+	if rows[0].Operation != string(audit.OpInsert) || rows[0].Extra == "" {
+		t.Fatalf("unexpected row: %+v", rows[0])
+	}
+}
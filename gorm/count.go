@@ -0,0 +1,204 @@
+package gorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tx7do/go-crud/pagination"
+)
+
+// countByStrategy 按 context 中的统计策略计算列表总数
+func (r *Repository[DTO, ENTITY]) countByStrategy(ctx context.Context, db *gorm.DB, whereSelectors []func(*gorm.DB) *gorm.DB) (pagination.Count, error) {
+	strategy := pagination.CountStrategyFromContext(ctx)
+
+	switch strategy.Mode {
+	case pagination.CountModeSkip:
+		return pagination.SkippedCount(), nil
+
+	case pagination.CountModeCapped:
+		limit := strategy.Limit()
+		n, err := r.CountCapped(ctx, db, whereSelectors, limit)
+		if err != nil {
+			return pagination.Count{}, err
+		}
+		return pagination.CappedCount(n, limit), nil
+
+	case pagination.CountModeEstimated:
+		n, ok, err := r.CountEstimated(ctx, db, whereSelectors)
+		if err != nil {
+			return pagination.Count{}, err
+		}
+		if ok {
+			return pagination.EstimatedCount(n), nil
+		}
+	}
+
+	total, err := r.Count(ctx, db, whereSelectors)
+	if err != nil {
+		return pagination.Count{}, err
+	}
+	return pagination.ExactCount(total), nil
+}
+
+// newWhereDB 构造应用了 whereSelectors 的查询 DB
+func (r *Repository[DTO, ENTITY]) newWhereDB(ctx context.Context, db *gorm.DB, whereSelectors []func(*gorm.DB) *gorm.DB) *gorm.DB {
+	whereDB := withTx(ctx, db).Model(new(ENTITY))
+	for _, s := range whereSelectors {
+		if s != nil {
+			whereDB = s(whereDB)
+		}
+	}
+	return whereDB
+}
+
+// CountCapped 最多统计到 limit+1 条记录：SELECT COUNT(*) FROM (SELECT 1 ... LIMIT limit+1) AS t
+func (r *Repository[DTO, ENTITY]) CountCapped(ctx context.Context, db *gorm.DB, whereSelectors []func(*gorm.DB) *gorm.DB, limit int64) (int64, error) {
+	if db == nil {
+		return 0, errors.New("db is nil")
+	}
+	if limit <= 0 {
+		limit = pagination.DefaultCountCap
+	}
+
+	whereSelectors, err := r.withDataScope(ctx, whereSelectors)
+	if err != nil {
+		return 0, err
+	}
+
+	sub := r.newWhereDB(ctx, db, whereSelectors).Select("1").Limit(int(limit + 1))
+
+	var cnt int64
+	if err = withTx(ctx, db).Table("(?) AS t", sub).Count(&cnt).Error; err != nil {
+		log.Errorf("query capped count failed: %s", err.Error())
+		return 0, fmt.Errorf("query capped count failed: %w", err)
+	}
+	return cnt, nil
+}
+
+// CountEstimated 使用数据库的统计信息估算记录数，ok 为 false 表示当前数据库不支持估算。
+// PostgreSQL：无过滤条件时读取 pg_class.reltuples，有过滤条件时读取 EXPLAIN 的 Plan Rows；
+// MySQL：仅支持无过滤条件时读取 information_schema.TABLES.TABLE_ROWS。
+// 过滤条件包括 whereSelectors、db 上已有的条件以及插件（租户、软删除等）追加的条件。
+func (r *Repository[DTO, ENTITY]) CountEstimated(ctx context.Context, db *gorm.DB, whereSelectors []func(*gorm.DB) *gorm.DB) (total int64, ok bool, err error) {
+	if db == nil {
+		return 0, false, errors.New("db is nil")
+	}
+
+	if whereSelectors, err = r.withDataScope(ctx, whereSelectors); err != nil {
+		return 0, false, err
+	}
+
+	switch db.Dialector.Name() {
+	case "postgres", "mysql":
+	default:
+		return 0, false, nil
+	}
+
+	stmt, err := r.dryRunQuery(ctx, db, whereSelectors)
+	if err != nil {
+		return 0, false, err
+	}
+	filtered := false
+	if c, exist := stmt.Clauses["WHERE"]; exist {
+		if where, isWhere := c.Expression.(clause.Where); !isWhere || len(where.Exprs) > 0 {
+			filtered = true
+		}
+	}
+
+	switch db.Dialector.Name() {
+	case "postgres":
+		if filtered {
+			return r.explainRowsPostgres(ctx, db, stmt)
+		}
+		return r.tableRowsPostgres(ctx, db)
+
+	default:
+		if filtered {
+			return 0, false, nil
+		}
+		return r.tableRowsMySQL(ctx, db)
+	}
+}
+
+// dryRunQuery 以 DryRun 方式构造列表查询语句，语句中包含插件回调追加的条件，SQL 使用占位符
+func (r *Repository[DTO, ENTITY]) dryRunQuery(ctx context.Context, db *gorm.DB, whereSelectors []func(*gorm.DB) *gorm.DB) (*gorm.Statement, error) {
+	var entities []*ENTITY
+	tx := r.newWhereDB(ctx, db, whereSelectors).Session(&gorm.Session{DryRun: true}).Find(&entities)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tx.Statement, nil
+}
+
+// tableName 解析 ENTITY 对应的表名
+func (r *Repository[DTO, ENTITY]) tableName(db *gorm.DB) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(ENTITY)); err != nil {
+		return "", err
+	}
+	return stmt.Schema.Table, nil
+}
+
+func (r *Repository[DTO, ENTITY]) tableRowsPostgres(ctx context.Context, db *gorm.DB) (int64, bool, error) {
+	table, err := r.tableName(db)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var rows float64
+	if err = withTx(ctx, db).
+		Raw("SELECT reltuples FROM pg_class WHERE oid = to_regclass(?)", table).
+		Scan(&rows).Error; err != nil {
+		log.Errorf("query estimated count failed: %s", err.Error())
+		return 0, false, fmt.Errorf("query estimated count failed: %w", err)
+	}
+
+	// 从未 ANALYZE 过的表 reltuples 为 -1，此时退化为精确统计
+	if rows < 0 {
+		return 0, false, nil
+	}
+	return int64(rows), true, nil
+}
+
+func (r *Repository[DTO, ENTITY]) explainRowsPostgres(ctx context.Context, db *gorm.DB, stmt *gorm.Statement) (int64, bool, error) {
+	var plan string
+	if err := withTx(ctx, db).Raw("EXPLAIN (FORMAT JSON) "+stmt.SQL.String(), stmt.Vars...).Scan(&plan).Error; err != nil {
+		log.Errorf("query estimated count failed: %s", err.Error())
+		return 0, false, fmt.Errorf("query estimated count failed: %w", err)
+	}
+
+	var explain []struct {
+		Plan struct {
+			PlanRows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &explain); err != nil || len(explain) == 0 {
+		return 0, false, nil
+	}
+	return int64(explain[0].Plan.PlanRows), true, nil
+}
+
+func (r *Repository[DTO, ENTITY]) tableRowsMySQL(ctx context.Context, db *gorm.DB) (int64, bool, error) {
+	table, err := r.tableName(db)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var rows *int64
+	if err = withTx(ctx, db).
+		Raw("SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?", table).
+		Scan(&rows).Error; err != nil {
+		log.Errorf("query estimated count failed: %s", err.Error())
+		return 0, false, fmt.Errorf("query estimated count failed: %w", err)
+	}
+	if rows == nil {
+		return 0, false, nil
+	}
+	return *rows, true, nil
+}
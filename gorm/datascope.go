@@ -0,0 +1,99 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tx7do/go-crud/gorm/filter"
+	"github.com/tx7do/go-crud/pagination/datascope"
+)
+
+// WithDataScope 设置数据权限（行级权限）策略，查询、计数、更新、删除以及 Upsert 的冲突更新均按 ctx 中的 Viewer 追加数据权限条件；
+// 数据权限条件不受 WithFilterPolicy 与 WithFieldMapping 的影响，列名以策略配置为准。
+// 创建（包括 Upsert 的插入）不做数据权限校验。
+func (r *Repository[DTO, ENTITY]) WithDataScope(policy *datascope.Policy) *Repository[DTO, ENTITY] {
+	r.dataScope = policy
+	if policy != nil {
+		r.dataScopeFilter = filter.NewStructuredFilter().WithFieldMapping(policy.Mapping())
+	} else {
+		r.dataScopeFilter = nil
+	}
+	return r
+}
+
+// dataScopeSelectors 根据 ctx 中的 Viewer 构造数据权限 where selectors，未设置策略或无需过滤时返回 nil
+func (r *Repository[DTO, ENTITY]) dataScopeSelectors(ctx context.Context) ([]func(*gorm.DB) *gorm.DB, error) {
+	if r.dataScope == nil {
+		return nil, nil
+	}
+
+	expr, err := r.dataScope.Build(ctx)
+	if err != nil {
+		log.Errorf("build data scope failed: %s", err.Error())
+		return nil, err
+	}
+	if expr == nil {
+		return nil, nil
+	}
+
+	selectors, err := r.dataScopeFilter.BuildSelectors(expr)
+	if err != nil {
+		log.Errorf("build data scope selectors failed: %s", err.Error())
+		return nil, err
+	}
+	return selectors, nil
+}
+
+// withDataScope 返回追加了数据权限条件的 whereSelectors（不修改入参）
+func (r *Repository[DTO, ENTITY]) withDataScope(ctx context.Context, whereSelectors []func(*gorm.DB) *gorm.DB) ([]func(*gorm.DB) *gorm.DB, error) {
+	scopes, err := r.dataScopeSelectors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(scopes) == 0 {
+		return whereSelectors, nil
+	}
+
+	out := make([]func(*gorm.DB) *gorm.DB, 0, len(whereSelectors)+len(scopes))
+	out = append(out, whereSelectors...)
+	return append(out, scopes...), nil
+}
+
+// dataScopeExprs 将数据权限条件转换为 where 表达式，用于 Upsert 的冲突更新条件；未设置策略或无需过滤时返回 nil
+func (r *Repository[DTO, ENTITY]) dataScopeExprs(ctx context.Context, db *gorm.DB) ([]clause.Expression, error) {
+	scopes, err := r.dataScopeSelectors(ctx)
+	if err != nil || len(scopes) == 0 {
+		return nil, err
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true})
+	for _, s := range scopes {
+		if s != nil {
+			tx = s(tx)
+		}
+	}
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if where, ok := tx.Statement.Clauses["WHERE"].Expression.(clause.Where); ok {
+		return where.Exprs, nil
+	}
+	return nil, nil
+}
+
+// applyDataScope 将数据权限条件应用到 db
+func (r *Repository[DTO, ENTITY]) applyDataScope(ctx context.Context, db *gorm.DB) (*gorm.DB, error) {
+	scopes, err := r.dataScopeSelectors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range scopes {
+		if s != nil {
+			db = s(db)
+		}
+	}
+	return db, nil
+}
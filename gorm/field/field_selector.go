@@ -4,20 +4,35 @@ import (
 	"strings"
 
 	"gorm.io/gorm"
+
+	"github.com/tx7do/go-crud/pagination/fieldmap"
 )
 
 // Selector 字段选择器，用于构建 GORM 查询中的字段列表。
-type Selector struct{}
+type Selector struct {
+	mapping *fieldmap.Mapping
+}
 
 // NewFieldSelector 返回一个新的 Selector。
 func NewFieldSelector() *Selector { return &Selector{} }
 
+// WithFieldMapping 设置字段映射，字段路径按映射替换为列名。
+func (fs *Selector) WithFieldMapping(mapping *fieldmap.Mapping) *Selector {
+	fs.mapping = mapping
+	return fs
+}
+
+// Resolve 返回字段路径按映射替换后的列名，未设置映射时原样返回。
+func (fs Selector) Resolve(field string) string {
+	return fs.mapping.Resolve(field)
+}
+
 // BuildSelect 将 fields 应用到传入的 *gorm.DB，并返回修改后的 *gorm.DB。
 func (fs Selector) BuildSelect(db *gorm.DB, fields []string) *gorm.DB {
 	if db == nil || len(fields) == 0 {
 		return db
 	}
-	fields = NormalizePaths(fields)
+	fields = NormalizePaths(fs.mapping.Paths(fields))
 	// 使用逗号连接作为 Select 参数
 	return db.Select(strings.Join(fields, ", "))
 }
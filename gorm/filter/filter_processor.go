@@ -36,8 +36,11 @@ func (poc Processor) Process(db *gorm.DB, op paginationV1.Operator, field, value
 		return db
 	}
 	// 将 field 转为 snake_case（与 DB 列风格一致）
-	field = stringcase.ToSnakeCase(field)
+	return poc.process(db, op, stringcase.ToSnakeCase(field), value, values)
+}
 
+// process 与 Process 相同，但 field 作为列名原样使用
+func (poc Processor) process(db *gorm.DB, op paginationV1.Operator, field, value string, values []string) *gorm.DB {
 	switch op {
 	case paginationV1.Operator_EQ:
 		return poc.Equal(db, field, value)
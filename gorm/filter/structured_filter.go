@@ -12,12 +12,16 @@ import (
 	"github.com/tx7do/go-utils/stringcase"
 
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
 )
 
 // StructuredFilter 基于 FilterExpr 的 GORM 过滤器
 type StructuredFilter struct {
 	codec     encoding.Codec
 	processor *Processor
+	policy    *paginationFilter.FilterPolicy
+	mapping   *fieldmap.Mapping
 }
 
 func NewStructuredFilter() *StructuredFilter {
@@ -27,6 +31,18 @@ func NewStructuredFilter() *StructuredFilter {
 	}
 }
 
+// WithPolicy 设置字段策略，过滤条件需通过策略校验，字段按策略映射为列名
+func (sf *StructuredFilter) WithPolicy(policy *paginationFilter.FilterPolicy) *StructuredFilter {
+	sf.policy = policy
+	return sf
+}
+
+// WithFieldMapping 设置字段映射，已登记的字段直接使用映射的列名（可为关联表的列，如 org.name）
+func (sf *StructuredFilter) WithFieldMapping(mapping *fieldmap.Mapping) *StructuredFilter {
+	sf.mapping = mapping
+	return sf
+}
+
 // BuildSelectors 将 FilterExpr 转为一组可应用于 *gorm.DB 的闭包
 func (sf StructuredFilter) BuildSelectors(expr *paginationV1.FilterExpr) ([]func(*gorm.DB) *gorm.DB, error) {
 	var sels []func(*gorm.DB) *gorm.DB
@@ -36,6 +52,11 @@ func (sf StructuredFilter) BuildSelectors(expr *paginationV1.FilterExpr) ([]func
 		return sels, nil
 	}
 
+	expr, err := sf.policy.CheckFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
 	// 未指定类型视为跳过（测试期望返回 nil）
 	if expr.GetType() == paginationV1.ExprType_EXPR_TYPE_UNSPECIFIED {
 		log.Warn("Skipping unspecified FilterExpr")
@@ -75,10 +96,14 @@ func (sf StructuredFilter) buildFilterSelector(expr *paginationV1.FilterExpr) (f
 		default:
 		}
 
+		if col, ok := sf.mapping.Lookup(cond.GetField()); ok {
+			return sf.processor.process(db, cond.GetOp(), col, val, cond.GetValues())
+		}
+
 		// 支持 JSON 字段 (e.g. preferences.daily_email)
 		if strings.Contains(cond.GetField(), ".") {
 			parts := strings.SplitN(cond.GetField(), ".", 2)
-			col := stringcase.ToSnakeCase(parts[0])
+			col := sf.mapping.Column(parts[0])
 			jsonKey := parts[1]
 			// 在运行时根据 db 方言生成表达式
 			exprStr, _ := sf.processor.JsonbFieldExpr(db, jsonKey, col)
@@ -119,45 +144,39 @@ func (sf StructuredFilter) buildFilterSelector(expr *paginationV1.FilterExpr) (f
 			return db
 
 		case paginationV1.ExprType_OR:
-			// 为 OR，把所有条件和子组合并为一个 WHERE 子表达式，内部使用 Or 组合
-			db = db.Where(func(tx *gorm.DB) *gorm.DB {
-				first := true
-				// 条件集合
-				for _, cond := range expr.GetConditions() {
-					if first {
-						tx = applyCond(tx, cond)
-						first = false
-					} else {
-						// 每个后续项作为 OR 子句加入
-						c := cond // capture
-						tx = tx.Or(func(t2 *gorm.DB) *gorm.DB {
-							return applyCond(t2, c)
-						})
-					}
+			// 为 OR，把所有条件和子组合并为一个分组 WHERE 子表达式，内部使用 Or 组合；
+			// 分组需基于新的 Session 构建，gorm 会将其作为括号内的条件组
+			var group *gorm.DB
+			appendGroup := func(part *gorm.DB) {
+				if group == nil {
+					group = part
+				} else {
+					group = group.Or(part)
 				}
-				// 子组集合
-				for _, g := range expr.GetGroups() {
-					subSel, err := sf.buildFilterSelector(g)
-					if err != nil {
-						log.Errorf("buildFilterSelector sub-group error: %v", err)
-						continue
-					}
-					if subSel == nil {
-						continue
-					}
-					if first {
-						tx = subSel(tx)
-						first = false
-					} else {
-						s := subSel // capture
-						tx = tx.Or(func(t2 *gorm.DB) *gorm.DB {
-							return s(t2)
-						})
-					}
+			}
+			newSession := func() *gorm.DB {
+				return db.Session(&gorm.Session{NewDB: true})
+			}
+			// 条件集合
+			for _, cond := range expr.GetConditions() {
+				appendGroup(applyCond(newSession(), cond))
+			}
+			// 子组集合
+			for _, g := range expr.GetGroups() {
+				subSel, err := sf.buildFilterSelector(g)
+				if err != nil {
+					log.Errorf("buildFilterSelector sub-group error: %v", err)
+					continue
 				}
-				return tx
-			})
-			return db
+				if subSel == nil {
+					continue
+				}
+				appendGroup(subSel(newSession()))
+			}
+			if group == nil {
+				return db
+			}
+			return db.Where(group)
 		default:
 			// 未知类型，直接返回原 db
 			return db
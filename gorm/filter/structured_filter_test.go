@@ -5,8 +5,10 @@ import (
 	"testing"
 
 	"google.golang.org/protobuf/encoding/protojson"
+	"gorm.io/gorm"
 
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
 )
 
 func mustMarshal(fe *paginationV1.FilterExpr) string {
@@ -202,3 +204,68 @@ func TestStructuredFilter_JSONField_SQL(t *testing.T) {
 		t.Fatalf("expected json key or json extract operator in sql, got: %q", sql)
 	}
 }
+
+func TestStructuredFilter_FieldMapping_SQL(t *testing.T) {
+	sf := NewStructuredFilter().WithFieldMapping(fieldmap.New().
+		Map("createdAt", "create_time").
+		Map("orgName", "org.name").
+		Map("prefs", "preferences"))
+	db := openTestDB(t)
+
+	expr := &paginationV1.FilterExpr{
+		Type: paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{
+			{Field: "createdAt", Op: paginationV1.Operator_GT, ValueOneof: &paginationV1.FilterCondition_Value{Value: "2024-01-01"}},
+			{Field: "orgName", Op: paginationV1.Operator_EQ, ValueOneof: &paginationV1.FilterCondition_Value{Value: "acme"}},
+			{Field: "prefs.daily_email", Op: paginationV1.Operator_EQ, ValueOneof: &paginationV1.FilterCondition_Value{Value: "true"}},
+			{Field: "realName", Op: paginationV1.Operator_EQ, ValueOneof: &paginationV1.FilterCondition_Value{Value: "tom"}},
+		},
+	}
+
+	sels, err := sf.BuildSelectors(expr)
+	if err != nil {
+		t.Fatalf("BuildSelectors error: %v", err)
+	}
+	if len(sels) != 1 {
+		t.Fatalf("expected 1 selector, got %d", len(sels))
+	}
+
+	sql := strings.ToLower(sqlFor(t, db, sels[0]))
+	for _, want := range []string{"create_time >", "org.name =", "preferences", "real_name ="} {
+		if !strings.Contains(sql, want) {
+			t.Fatalf("expected sql to contain %q, got: %q", want, sql)
+		}
+	}
+	if strings.Contains(sql, "org_name") || strings.Contains(sql, "prefs") {
+		t.Fatalf("unexpected unmapped column in sql: %q", sql)
+	}
+}
+
+func TestStructuredFilter_OrGroup_SQL(t *testing.T) {
+	sf := NewStructuredFilter()
+	db := openTestDB(t)
+
+	expr := &paginationV1.FilterExpr{
+		Type: paginationV1.ExprType_OR,
+		Conditions: []*paginationV1.FilterCondition{
+			{Field: "created_by", Op: paginationV1.Operator_IN, ValueOneof: &paginationV1.FilterCondition_Value{Value: "[7]"}},
+			{Field: "org_unit_id", Op: paginationV1.Operator_IN, ValueOneof: &paginationV1.FilterCondition_Value{Value: "[3,4]"}},
+		},
+	}
+
+	sels, err := sf.BuildSelectors(expr)
+	if err != nil {
+		t.Fatalf("BuildSelectors error: %v", err)
+	}
+	if len(sels) != 1 {
+		t.Fatalf("expected 1 selector, got %d", len(sels))
+	}
+
+	// OR 条件组需整体加括号，不能被当作主键条件或与其他条件平铺
+	sql := strings.ToLower(sqlFor(t, db, func(tx *gorm.DB) *gorm.DB {
+		return sels[0](tx.Where("id > ?", 1))
+	}))
+	if !strings.Contains(sql, "id > ? and (created_by in (?) or org_unit_id in (?,?))") {
+		t.Fatalf("unexpected or group sql: %q", sql)
+	}
+}
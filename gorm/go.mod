@@ -2,16 +2,28 @@ module github.com/tx7do/go-crud/gorm
 
 go 1.25.4
 
+replace github.com/tx7do/go-crud => ../
+
 replace github.com/tx7do/go-crud/api => ../api
 
 replace github.com/tx7do/go-crud/pagination => ../pagination
 
+replace github.com/tx7do/go-crud/audit => ../audit
+
+replace github.com/tx7do/go-crud/outbox => ../outbox
+
+replace github.com/tx7do/go-crud/viewer => ../viewer
+
 require (
 	github.com/glebarez/sqlite v1.11.0
 	github.com/go-kratos/kratos/v2 v2.9.2
 	github.com/google/uuid v1.6.0
+	github.com/tx7do/go-crud v0.0.1
 	github.com/tx7do/go-crud/api v0.0.7
+	github.com/tx7do/go-crud/audit v0.0.2
+	github.com/tx7do/go-crud/outbox v0.0.1
 	github.com/tx7do/go-crud/pagination v0.0.11
+	github.com/tx7do/go-crud/viewer v0.0.5
 	github.com/tx7do/go-utils v1.1.34
 	github.com/tx7do/go-utils/id v0.0.2
 	github.com/tx7do/go-utils/mapper v0.0.3
@@ -96,6 +108,7 @@ require (
 	github.com/sony/sonyflake v1.3.0 // indirect
 	github.com/tjfoc/gmsm v1.4.1 // indirect
 	github.com/zeebo/xxh3 v1.0.2 // indirect
+	go.einride.tech/aip v0.79.0 // indirect
 	go.mongodb.org/mongo-driver v1.17.6 // indirect
 	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
 	go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc v0.64.0 // indirect
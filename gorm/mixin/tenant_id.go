@@ -5,6 +5,7 @@ import "gorm.io/gorm"
 // TenantID 是 GORM 可复用的 mixin，表示租户 ID（可为空）。
 // 使用指针以支持 nullable，并在数据库中建立索引。
 // 不在钩子中强制不可变性（ent 的 Immutable 在 GORM 中需在业务层或更复杂的钩子中处理）。
+// 租户过滤与创建时写入租户由 gorm.TenantPlugin 完成。
 type TenantID struct {
 	TenantID *uint32 `gorm:"column:tenant_id;type:int unsigned;index" json:"tenant_id,omitempty"`
 }
@@ -1,14 +1,14 @@
 package mixin
 
 import (
-	"errors"
-
 	"gorm.io/gorm"
+
+	crud "github.com/tx7do/go-crud"
 )
 
 // Version 是 GORM 可复用的 mixin，表示版本号/乐观锁。
-// 注意：钩子只负责在创建时设置初始版本。乐观锁的检查与递增
-// 需要在更新操作时在仓储/业务层使用带 WHERE version = ? 的更新语句来保证原子性。
+// 钩子只负责在创建时设置初始版本；通过 Repository 的 Update/Upsert 更新时，
+// 若 DTO 携带了版本号，会自动追加 WHERE version = ? 并递增版本号，冲突时返回 crud.ErrVersionConflict。
 type Version struct {
 	Version uint32 `gorm:"column:version;type:int unsigned;default:1;not null;index" json:"version"`
 }
@@ -22,14 +22,14 @@ func (m *Version) BeforeCreate(tx *gorm.DB) (err error) {
 
 // OptimisticUpdate 是一个简单的辅助函数示例：在单个事务内
 // 使用 WHERE version = oldVersion 执行更新并检查 RowsAffected，
-// 若为 0 则表示版本冲突（需要重试或返回错误）。
+// 若为 0 则表示版本冲突（返回 crud.ErrVersionConflict）。
 func OptimisticUpdate(tx *gorm.DB, model interface{}, oldVersion uint32, updates map[string]interface{}) error {
 	res := tx.Model(model).Where("version = ?", oldVersion).Updates(updates)
 	if res.Error != nil {
 		return res.Error
 	}
 	if res.RowsAffected == 0 {
-		return errors.New("optimistic lock: version mismatch")
+		return crud.ErrVersionConflict
 	}
 	return nil
 }
@@ -0,0 +1,199 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	crud "github.com/tx7do/go-crud"
+	"github.com/tx7do/go-crud/pagination/datascope"
+)
+
+// DefaultVersionColumn 乐观锁默认使用的版本列，与 mixin.Version 一致
+const DefaultVersionColumn = "version"
+
+// WithVersionColumn 设置乐观锁使用的版本列，为空时关闭乐观锁
+func (r *Repository[DTO, ENTITY]) WithVersionColumn(column string) *Repository[DTO, ENTITY] {
+	r.versionColumn = column
+	return r
+}
+
+// WithConflictRetry 设置乐观锁冲突时的重试：重新读取最新记录后交给 resolve 重新应用变更，最多重试 maxRetries 次
+func (r *Repository[DTO, ENTITY]) WithConflictRetry(maxRetries int, resolve crud.ConflictResolver[DTO]) *Repository[DTO, ENTITY] {
+	r.conflictRetries = maxRetries
+	r.conflictResolver = resolve
+	return r
+}
+
+// versionField 返回实体的版本字段，未启用乐观锁或实体不包含版本字段时返回 nil
+func (r *Repository[DTO, ENTITY]) versionField(db *gorm.DB) (*schema.Field, error) {
+	if r.versionColumn == "" {
+		return nil, nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(ENTITY)); err != nil {
+		return nil, err
+	}
+	return stmt.Schema.LookUpField(r.versionColumn), nil
+}
+
+// bumpVersion 读取实体携带的版本号并将实体的版本号加 1，返回原版本号；未携带版本号（零值）时不启用乐观锁
+func bumpVersion[ENTITY any](ctx context.Context, vf *schema.Field, ent *ENTITY) (uint64, bool, error) {
+	if vf == nil || ent == nil {
+		return 0, false, nil
+	}
+
+	rv := reflect.ValueOf(ent)
+	value, zero := vf.ValueOf(ctx, rv)
+	if zero {
+		return 0, false, nil
+	}
+
+	var version uint64
+	switch v := reflect.Indirect(reflect.ValueOf(value)); v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		version = uint64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		version = v.Uint()
+	default:
+		return 0, false, nil
+	}
+
+	if err := vf.Set(ctx, rv, version+1); err != nil {
+		return 0, false, err
+	}
+	return version, true, nil
+}
+
+// updates 执行更新并返回受影响行数。实体包含版本字段且 dto 携带版本号时启用乐观锁：
+// 追加 WHERE version = ? 并将版本号加 1，未更新任何记录时返回 crud.ErrVersionConflict；
+// 设置了 WithConflictRetry 时重新读取最新记录，交给回调重新应用变更后重试。
+func (r *Repository[DTO, ENTITY]) updates(ctx context.Context, qdb *gorm.DB, dto *DTO, updateMask *fieldmaskpb.FieldMask) (int64, error) {
+	vf, err := r.versionField(qdb)
+	if err != nil {
+		return 0, err
+	}
+
+	for retries := 0; ; retries++ {
+		ent := r.mapper.ToEntity(dto)
+
+		version, locked, err := bumpVersion(ctx, vf, ent)
+		if err != nil {
+			return 0, err
+		}
+
+		tx := qdb.Session(&gorm.Session{})
+
+		// 指定更新字段，启用乐观锁时同时更新版本号
+		if paths := updateMask.GetPaths(); len(paths) > 0 {
+			if locked {
+				paths = append(slices.Clone(paths), vf.DBName)
+			}
+			tx = tx.Select(paths)
+		}
+		if locked {
+			tx = tx.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: vf.DBName}, Value: version})
+		}
+
+		res := tx.Updates(ent)
+		if res.Error != nil {
+			log.Errorf("update failed: %s", res.Error.Error())
+			return 0, fmt.Errorf("update failed: %w", res.Error)
+		}
+		if !locked || res.RowsAffected > 0 {
+			return res.RowsAffected, nil
+		}
+
+		// 版本冲突：重新读取最新记录并交给回调重新应用变更
+		if r.conflictResolver == nil || retries >= r.conflictRetries {
+			return 0, crud.ErrVersionConflict
+		}
+
+		var latest ENTITY
+		if err = qdb.Session(&gorm.Session{}).Select("*").First(&latest).Error; err != nil {
+			return 0, err
+		}
+		if dto, err = r.conflictResolver(ctx, r.mapper.ToDTO(&latest), dto); err != nil {
+			return 0, err
+		}
+		if dto == nil {
+			return 0, crud.ErrVersionConflict
+		}
+	}
+}
+
+// upsert 执行插入或冲突更新，返回写入后的实体与受影响行数。实体包含版本字段且 dto 携带版本号时启用乐观锁：
+// 冲突更新追加 WHERE version = ? 并写入加 1 后的版本号，未写入任何记录时返回 crud.ErrVersionConflict；
+// 设置了数据权限策略时冲突更新同样追加数据权限条件，记录存在但不在权限范围内时返回 datascope.ErrAccessDenied。
+// 以上条件需数据库支持 ON CONFLICT ... DO UPDATE ... WHERE（如 PostgreSQL、SQLite），其它数据库返回 crud.ErrNotSupported。
+func (r *Repository[DTO, ENTITY]) upsert(ctx context.Context, qdb *gorm.DB, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*ENTITY, int64, error) {
+	vf, err := r.versionField(qdb)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ent := r.mapper.ToEntity(dto)
+
+	version, locked, err := bumpVersion(ctx, vf, ent)
+	if err != nil {
+		return nil, 0, err
+	}
+	scopeExprs, err := r.dataScopeExprs(ctx, qdb)
+	if err != nil {
+		return nil, 0, err
+	}
+	scoped := len(scopeExprs) > 0
+
+	// MySQL 的 ON DUPLICATE KEY UPDATE 会忽略冲突更新的条件，无法检测版本冲突与数据权限
+	if locked && !supportsConflictWhere(qdb) {
+		return nil, 0, fmt.Errorf("versioned upsert on %s: %w", qdb.Dialector.Name(), crud.ErrNotSupported)
+	}
+	if scoped && !supportsConflictWhere(qdb) {
+		return nil, 0, fmt.Errorf("data scoped upsert on %s: %w", qdb.Dialector.Name(), crud.ErrNotSupported)
+	}
+
+	// 构造 OnConflict 子句：若提供了 updateMask 则仅在冲突时更新指定列，否则更新所有列
+	var onConflict clause.OnConflict
+	if paths := updateMask.GetPaths(); len(paths) > 0 {
+		if locked {
+			paths = append(slices.Clone(paths), vf.DBName)
+		}
+		onConflict = clause.OnConflict{
+			DoUpdates: clause.AssignmentColumns(paths),
+		}
+	} else {
+		onConflict = clause.OnConflict{
+			UpdateAll: true,
+		}
+	}
+	if locked {
+		onConflict.Where.Exprs = append(onConflict.Where.Exprs,
+			clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: vf.DBName}, Value: version},
+		)
+	}
+	onConflict.Where.Exprs = append(onConflict.Where.Exprs, scopeExprs...)
+
+	res := qdb.Clauses(onConflict).Create(ent)
+	if res.Error != nil {
+		log.Errorf("upsert failed: %s", res.Error.Error())
+		return nil, 0, fmt.Errorf("upsert failed: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		if locked {
+			return nil, 0, crud.ErrVersionConflict
+		}
+		if scoped {
+			return nil, 0, datascope.ErrAccessDenied
+		}
+	}
+
+	return ent, res.RowsAffected, nil
+}
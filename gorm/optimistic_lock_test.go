@@ -0,0 +1,99 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/tx7do/go-utils/mapper"
+
+	crud "github.com/tx7do/go-crud"
+	"github.com/tx7do/go-crud/gorm/mixin"
+)
+
+type versionedDoc struct {
+	ID    uint `gorm:"primarykey"`
+	Name  string
+	Title string
+	mixin.Version
+}
+
+func TestRepository_OptimisticLock(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&versionedDoc{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	ctx := context.Background()
+	repo := NewRepository[versionedDoc, versionedDoc](mapper.NewCopierMapper[versionedDoc, versionedDoc]())
+
+	created, err := repo.Create(ctx, db, &versionedDoc{Name: "a"}, nil)
+	if err != nil || created.Version.Version != 1 {
+		t.Fatalf("create: %+v, %v", created, err)
+	}
+	byID := db.Where("id = ?", created.ID)
+
+	// 携带当前版本号时更新成功并递增版本号
+	updated, err := repo.Update(ctx, byID, &versionedDoc{Name: "b", Version: mixin.Version{Version: 1}}, nil)
+	if err != nil || updated.Name != "b" || updated.Version.Version != 2 {
+		t.Fatalf("update: %+v, %v", updated, err)
+	}
+
+	// 过期版本号返回版本冲突，记录保持不变
+	if _, err = repo.Update(ctx, byID, &versionedDoc{Name: "c", Version: mixin.Version{Version: 1}}, nil); !errors.Is(err, crud.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict, got %v", err)
+	}
+	if _, err = repo.UpdateX(ctx, byID, &versionedDoc{Name: "c", Version: mixin.Version{Version: 1}}, nil); !errors.Is(err, crud.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict from UpdateX, got %v", err)
+	}
+
+	// 未携带版本号时不做检查
+	if rows, err := repo.UpdateX(ctx, byID, &versionedDoc{Title: "t"}, nil); err != nil || rows != 1 {
+		t.Fatalf("update without version: %d, %v", rows, err)
+	}
+
+	// 冲突时重新读取最新记录并重新应用变更
+	var latestVersions []uint32
+	repo.WithConflictRetry(1, func(_ context.Context, latest *versionedDoc, dto *versionedDoc) (*versionedDoc, error) {
+		latestVersions = append(latestVersions, latest.Version.Version)
+		dto.Version = latest.Version
+		return dto, nil
+	})
+	updated, err = repo.Update(ctx, byID, &versionedDoc{Name: "d", Version: mixin.Version{Version: 1}}, nil)
+	if err != nil || updated.Name != "d" || updated.Version.Version != 3 || len(latestVersions) != 1 || latestVersions[0] != 2 {
+		t.Fatalf("retry: %+v, %v, %v", updated, err, latestVersions)
+	}
+
+	// upsert 冲突更新时同样检查版本号
+	if _, err = repo.Upsert(ctx, db, &versionedDoc{ID: created.ID, Name: "e", Version: mixin.Version{Version: 1}}, nil); !errors.Is(err, crud.ErrVersionConflict) {
+		t.Fatalf("expected ErrVersionConflict from upsert, got %v", err)
+	}
+	upserted, err := repo.Upsert(ctx, db, &versionedDoc{ID: created.ID, Name: "e", Version: mixin.Version{Version: 3}}, nil)
+	if err != nil || upserted.Version.Version != 4 {
+		t.Fatalf("upsert: %+v, %v", upserted, err)
+	}
+
+	// 不支持 ON CONFLICT ... WHERE 的数据库无法检查版本号，拒绝带版本号的 upsert
+	mdb, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open mysql: %v", err)
+	}
+	if _, err = repo.Upsert(ctx, mdb, &versionedDoc{ID: created.ID, Name: "f", Version: mixin.Version{Version: 4}}, nil); !errors.Is(err, crud.ErrNotSupported) {
+		t.Fatalf("expected ErrNotSupported from mysql upsert, got %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+package gorm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tx7do/go-crud/outbox"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+const outboxStateKey = "crud:outbox:state"
+
+// OutboxPluginOption OutboxPlugin 的选项
+type OutboxPluginOption func(p *OutboxPlugin)
+
+// WithOutboxTable 设置发件箱表名，默认为 outbox.DefaultTable
+func WithOutboxTable(table string) OutboxPluginOption {
+	return func(p *OutboxPlugin) {
+		if table != "" {
+			p.table = table
+		}
+	}
+}
+
+// WithOutboxTables 设置产生事件的业务表，为空时除发件箱表与审计日志表外的全部表都产生事件
+func WithOutboxTables(tables ...string) OutboxPluginOption {
+	return func(p *OutboxPlugin) {
+		for _, t := range tables {
+			p.tables[t] = struct{}{}
+		}
+	}
+}
+
+// WithOutboxEventType 设置事件类型的生成函数，默认为 outbox.EventType（<table>.<operation>）
+func WithOutboxEventType(fn func(aggregate string, op outbox.Operation) string) OutboxPluginOption {
+	return func(p *OutboxPlugin) {
+		if fn != nil {
+			p.eventType = fn
+		}
+	}
+}
+
+// OutboxPlugin 事务性发件箱插件：
+//   - 在 create/update/delete 提交前，以受影响的行生成事件（Aggregate 为表名，AggregateID 为主键，Payload 为行的 JSON），
+//     使用同一连接写入发件箱表，写入失败时整个语句回滚；
+//   - 更新/删除前按语句的 WHERE 条件与主键加载受影响的行，更新后按主键重新加载得到变更后的数据；
+//   - 语句需运行在事务中（默认事务或 RunInTx），关闭 SkipDefaultTransaction 时才能保证与业务写入原子提交；
+//   - 事件由 outbox.NewRelay(NewOutboxStore(...), publisher) 异步投递。
+type OutboxPlugin struct {
+	table     string
+	tables    map[string]struct{}
+	eventType func(aggregate string, op outbox.Operation) string
+}
+
+var _ gorm.Plugin = (*OutboxPlugin)(nil)
+
+// NewOutboxPlugin 创建发件箱插件，通过 db.Use 或 Client.Use(OutboxMixin(...)) 注册
+func NewOutboxPlugin(opts ...OutboxPluginOption) *OutboxPlugin {
+	p := &OutboxPlugin{
+		table:     outbox.DefaultTable,
+		tables:    map[string]struct{}{},
+		eventType: outbox.EventType,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+	return p
+}
+
+// OutboxMixin 将发件箱插件包装为 Mixin，供 Client.Use / WithMixin 使用
+func OutboxMixin(opts ...OutboxPluginOption) Mixin {
+	return func(db *gorm.DB) error {
+		return db.Use(NewOutboxPlugin(opts...))
+	}
+}
+
+func (p *OutboxPlugin) Name() string {
+	return "crud:outbox"
+}
+
+func (p *OutboxPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	// 事件需在事务提交前写入
+	const commit = "gorm:commit_or_rollback_transaction"
+
+	if err := cb.Create().Before(commit).Register("crud:outbox:after_create", p.afterCreate); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("crud:outbox:before_update", p.before); err != nil {
+		return err
+	}
+	if err := cb.Update().Before(commit).Register("crud:outbox:after_update", p.afterUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("crud:outbox:before_delete", p.before); err != nil {
+		return err
+	}
+	return cb.Delete().Before(commit).Register("crud:outbox:after_delete", p.afterDelete)
+}
+
+// enabled 判断语句所在的表是否产生事件
+func (p *OutboxPlugin) enabled(db *gorm.DB) bool {
+	if db.Error != nil || db.Statement.Context == nil {
+		return false
+	}
+
+	table := db.Statement.Table
+	if table == "" || table == p.table || table == DefaultAuditTable {
+		return false
+	}
+	if len(p.tables) == 0 {
+		return true
+	}
+	_, ok := p.tables[table]
+	return ok
+}
+
+// before 加载更新/删除前受影响的行
+func (p *OutboxPlugin) before(db *gorm.DB) {
+	if !p.enabled(db) {
+		return
+	}
+
+	rows, err := loadStatementRows(db, nil, 0)
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("outbox: load affected rows: %w", err))
+		return
+	}
+	db.InstanceSet(outboxStateKey, rows)
+}
+
+// preRows 返回 before 中加载的行，未加载（如全表操作）时返回 false
+func (p *OutboxPlugin) preRows(db *gorm.DB) ([]map[string]any, bool) {
+	v, ok := db.InstanceGet(outboxStateKey)
+	if !ok {
+		return nil, false
+	}
+	rows, _ := v.([]map[string]any)
+	return rows, rows != nil
+}
+
+func (p *OutboxPlugin) afterCreate(db *gorm.DB) {
+	if !p.enabled(db) {
+		return
+	}
+
+	rows := createdRows(db.Statement)
+	events := make([]*outbox.Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, p.newEvent(db, outbox.OperationCreated, row))
+	}
+	p.enqueue(db, events)
+}
+
+func (p *OutboxPlugin) afterUpdate(db *gorm.DB) {
+	if !p.enabled(db) || db.RowsAffected == 0 {
+		return
+	}
+
+	pres, ok := p.preRows(db)
+	pk := primaryKeyColumn(db.Statement)
+	if !ok || pk == "" {
+		// 无法定位受影响的行时，以本次更新的字段生成一条批量事件
+		p.enqueue(db, []*outbox.Event{p.newEvent(db, outbox.OperationUpdated, updatedValues(db.Statement))})
+		return
+	}
+	if len(pres) == 0 {
+		return
+	}
+
+	ids := make([]any, 0, len(pres))
+	for _, row := range pres {
+		ids = append(ids, row[pk])
+	}
+	posts, err := loadStatementRows(db, &clause.IN{Column: clause.Column{Name: pk}, Values: ids}, 0)
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("outbox: load updated rows: %w", err))
+		return
+	}
+
+	events := make([]*outbox.Event, 0, len(posts))
+	for _, row := range posts {
+		events = append(events, p.newEvent(db, outbox.OperationUpdated, row))
+	}
+	p.enqueue(db, events)
+}
+
+func (p *OutboxPlugin) afterDelete(db *gorm.DB) {
+	if !p.enabled(db) || db.RowsAffected == 0 {
+		return
+	}
+
+	pres, ok := p.preRows(db)
+	if !ok {
+		p.enqueue(db, []*outbox.Event{p.newEvent(db, outbox.OperationDeleted, nil)})
+		return
+	}
+
+	events := make([]*outbox.Event, 0, len(pres))
+	for _, row := range pres {
+		events = append(events, p.newEvent(db, outbox.OperationDeleted, row))
+	}
+	p.enqueue(db, events)
+}
+
+// newEvent 根据语句与行构造事件，row 为空时 AggregateID 为空（批量事件）
+func (p *OutboxPlugin) newEvent(db *gorm.DB, op outbox.Operation, row map[string]any) *outbox.Event {
+	table := db.Statement.Table
+	e := &outbox.Event{
+		Aggregate: table,
+		Type:      p.eventType(table, op),
+	}
+	if pk := primaryKeyColumn(db.Statement); pk != "" && row != nil {
+		if id, ok := row[pk]; ok && id != nil {
+			e.AggregateID = fmt.Sprintf("%v", id)
+		}
+	}
+	if len(row) > 0 {
+		e.Payload, _ = json.Marshal(row)
+	}
+	if vc, ok := viewer.FromContext(db.Statement.Context); ok {
+		e.TenantID = vc.TenantID()
+		e.TraceID = vc.TraceID()
+	}
+	return e
+}
+
+// enqueue 使用语句所在的连接（事务）写入事件，失败时将错误加入语句使其回滚
+func (p *OutboxPlugin) enqueue(db *gorm.DB, events []*outbox.Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true, Context: db.Statement.Context})
+	if err := insertOutboxEvents(tx, p.table, events); err != nil {
+		_ = db.AddError(fmt.Errorf("outbox: enqueue events: %w", err))
+	}
+}
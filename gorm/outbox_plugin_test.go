@@ -0,0 +1,165 @@
+package gorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/tx7do/go-crud/outbox"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+type outboxTestOrder struct {
+	ID     uint `gorm:"primarykey"`
+	Status string
+}
+
+func TestOutboxPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:outbox_test?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	store := NewOutboxStore(db, "")
+	if err = store.Migrate(ctx); err != nil {
+		t.Fatalf("migrate outbox: %v", err)
+	}
+	if err = db.AutoMigrate(&outboxTestOrder{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err = db.Use(NewOutboxPlugin()); err != nil {
+		t.Fatalf("use plugin: %v", err)
+	}
+
+	// 写入与事件在同一事务中提交
+	if err = db.Create(&[]outboxTestOrder{{Status: "new"}, {Status: "new"}}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err = db.Model(&outboxTestOrder{}).Where("id = ?", 1).Update("status", "paid").Error; err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err = db.Delete(&outboxTestOrder{}, 2).Error; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	// 业务事务回滚时事件一并回滚
+	errBoom := errors.New("boom")
+	err = RunInTx(ctx, db, func(ctx context.Context) error {
+		if err := withTx(ctx, db).Create(&outboxTestOrder{Status: "rolled back"}).Error; err != nil {
+			return err
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("expected boom, got %v", err)
+	}
+
+	// 手动写入的领域事件
+	err = RunInTx(ctx, db, func(ctx context.Context) error {
+		return store.Enqueue(ctx, &outbox.Event{Aggregate: "outbox_test_orders", AggregateID: "1", Type: "order.shipped"})
+	})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	events, err := store.Fetch(ctx, time.Now(), 10)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Key()+":"+e.Type)
+	}
+	want := []string{
+		"outbox_test_orders/1:outbox_test_orders.created",
+		"outbox_test_orders/2:outbox_test_orders.created",
+		"outbox_test_orders/1:outbox_test_orders.updated",
+		"outbox_test_orders/2:outbox_test_orders.deleted",
+		"outbox_test_orders/1:order.shipped",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected events: %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("event %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	var payload map[string]any
+	if err = json.Unmarshal(events[2].Payload, &payload); err != nil || payload["status"] != "paid" {
+		t.Fatalf("unexpected update payload: %s, %v", events[2].Payload, err)
+	}
+
+	// Relay 投递并标记完成
+	ch := outbox.NewChannelPublisher(len(events))
+	if _, err = outbox.NewRelay(store, ch).Process(ctx); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if len(ch.Events()) != len(events) {
+		t.Fatalf("published %d events, want %d", len(ch.Events()), len(events))
+	}
+	if pending, err := store.Fetch(ctx, time.Now(), 10); err != nil || len(pending) != 0 {
+		t.Fatalf("events should be published: %d, %v", len(pending), err)
+	}
+}
+
+func TestOutboxStore_Fetch(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:outbox_fetch_test?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.Use(NewTenantPlugin()); err != nil {
+		t.Fatalf("use plugin: %v", err)
+	}
+
+	store := NewOutboxStore(db, "")
+	if err = store.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrate outbox: %v", err)
+	}
+
+	tenantCtx := viewer.WithContext(context.Background(), tenantViewer{Context: viewer.NewNoopContext(), tenantID: 1})
+	if err = store.Enqueue(tenantCtx,
+		&outbox.Event{Aggregate: "orders", AggregateID: "1", Type: "orders.created"},
+		&outbox.Event{Aggregate: "orders", AggregateID: "1", Type: "orders.updated"},
+		&outbox.Event{Aggregate: "orders", AggregateID: "2", Type: "orders.created"},
+	); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	// Relay 没有 Viewer，以系统视图读取与标记事件
+	ctx := context.Background()
+	now := time.Now()
+	events, err := store.Fetch(ctx, now, 10)
+	if err != nil || len(events) != 3 {
+		t.Fatalf("fetch: %d, %v", len(events), err)
+	}
+	if events[0].TenantID != 1 {
+		t.Fatalf("tenant not recorded: %+v", events[0])
+	}
+
+	// 等待重试的事件及同一聚合的后续事件不返回，也不占用批次
+	if err = store.MarkFailed(ctx, events[0].ID, 1, now.Add(time.Minute), "broker unavailable"); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	events, err = store.Fetch(ctx, now, 1)
+	if err != nil || len(events) != 1 || events[0].Key() != "orders/2" {
+		t.Fatalf("unexpected due events: %v, %v", events, err)
+	}
+	if events, err = store.Fetch(ctx, now.Add(time.Minute), 10); err != nil || len(events) != 3 {
+		t.Fatalf("events should be due after backoff: %d, %v", len(events), err)
+	}
+}
@@ -0,0 +1,213 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tx7do/go-crud/outbox"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+// OutboxEvent 发件箱表结构
+type OutboxEvent struct {
+	ID          uint64 `gorm:"column:id;primaryKey;autoIncrement"`
+	Aggregate   string `gorm:"column:aggregate;size:128"`
+	AggregateID string `gorm:"column:aggregate_id;size:128"`
+	Type        string `gorm:"column:type;size:255"`
+	Payload     string `gorm:"column:payload;type:text"`
+
+	TenantID uint64 `gorm:"column:tenant_id"`
+	TraceID  string `gorm:"column:trace_id;size:64"`
+
+	Status        int32      `gorm:"column:status;index"`
+	Attempts      int32      `gorm:"column:attempts"`
+	NextAttemptAt *time.Time `gorm:"column:next_attempt_at"`
+	LastError     string     `gorm:"column:last_error;type:text"`
+
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	PublishedAt *time.Time `gorm:"column:published_at"`
+}
+
+// NewOutboxEvent 将发件箱事件转换为表记录
+func NewOutboxEvent(e *outbox.Event) *OutboxEvent {
+	row := &OutboxEvent{
+		ID:          e.ID,
+		Aggregate:   e.Aggregate,
+		AggregateID: e.AggregateID,
+		Type:        e.Type,
+		Payload:     string(e.Payload),
+		TenantID:    e.TenantID,
+		TraceID:     e.TraceID,
+		Status:      int32(e.Status),
+		Attempts:    int32(e.Attempts),
+		LastError:   e.LastError,
+		CreatedAt:   e.CreatedAt,
+		PublishedAt: e.PublishedAt,
+	}
+	if !e.NextAttemptAt.IsZero() {
+		next := e.NextAttemptAt
+		row.NextAttemptAt = &next
+	}
+	if row.CreatedAt.IsZero() {
+		row.CreatedAt = time.Now()
+	}
+	return row
+}
+
+// ToEvent 将表记录转换为发件箱事件
+func (r *OutboxEvent) ToEvent() *outbox.Event {
+	e := &outbox.Event{
+		ID:          r.ID,
+		Aggregate:   r.Aggregate,
+		AggregateID: r.AggregateID,
+		Type:        r.Type,
+		TenantID:    r.TenantID,
+		TraceID:     r.TraceID,
+		Status:      outbox.Status(r.Status),
+		Attempts:    int(r.Attempts),
+		LastError:   r.LastError,
+		CreatedAt:   r.CreatedAt,
+		PublishedAt: r.PublishedAt,
+	}
+	if r.Payload != "" {
+		e.Payload = []byte(r.Payload)
+	}
+	if r.NextAttemptAt != nil {
+		e.NextAttemptAt = *r.NextAttemptAt
+	}
+	return e
+}
+
+// OutboxStore 基于数据库表的发件箱存储，与 outbox.NewRelay 配合使用。
+// Relay 使用的 Fetch/Mark* 以系统视图访问发件箱表，注册了 TenantPlugin 时不按租户过滤，也不要求 ctx 中存在 Viewer。
+type OutboxStore struct {
+	db    *gorm.DB
+	table string
+}
+
+var _ outbox.Store = (*OutboxStore)(nil)
+
+// NewOutboxStore 创建发件箱存储，table 为空时使用 outbox.DefaultTable
+func NewOutboxStore(db *gorm.DB, table string) *OutboxStore {
+	if table == "" {
+		table = outbox.DefaultTable
+	}
+	return &OutboxStore{db: db, table: table}
+}
+
+// Migrate 创建或更新发件箱表
+func (s *OutboxStore) Migrate(ctx context.Context) error {
+	if s.db == nil {
+		return errors.New("db is nil")
+	}
+	return s.db.WithContext(ctx).Table(s.table).AutoMigrate(&OutboxEvent{})
+}
+
+// Enqueue 写入待投递事件；在 RunInTx 中调用时与业务写入使用同一事务
+func (s *OutboxStore) Enqueue(ctx context.Context, events ...*outbox.Event) error {
+	if s.db == nil {
+		return errors.New("db is nil")
+	}
+	return insertOutboxEvents(withTx(ctx, s.db), s.table, events)
+}
+
+func (s *OutboxStore) Fetch(ctx context.Context, now time.Time, limit int) ([]*outbox.Event, error) {
+	if s.db == nil {
+		return nil, errors.New("db is nil")
+	}
+
+	pending := int32(outbox.StatusPending)
+
+	// 同一聚合中存在未到期（ID 不大于当前事件）的待投递事件时，当前事件不可投递
+	notDue := s.db.Session(&gorm.Session{NewDB: true}).
+		Table(s.table+" AS b").
+		Select("1").
+		Where("b.status = ? AND b.aggregate = o.aggregate AND b.aggregate_id = o.aggregate_id AND b.id <= o.id AND b.next_attempt_at > ?", pending, now)
+
+	var rows []*OutboxEvent
+	if err := s.db.WithContext(systemContext(ctx)).Table(s.table+" AS o").
+		Where("o.status = ?", pending).
+		Where("NOT EXISTS (?)", notDue).
+		Order("o.id").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	events := make([]*outbox.Event, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, row.ToEvent())
+	}
+	return events, nil
+}
+
+func (s *OutboxStore) MarkPublished(ctx context.Context, id uint64) error {
+	return s.update(ctx, id, map[string]any{
+		"status":       int32(outbox.StatusPublished),
+		"published_at": time.Now(),
+	})
+}
+
+func (s *OutboxStore) MarkFailed(ctx context.Context, id uint64, attempts int, nextAttemptAt time.Time, lastErr string) error {
+	return s.update(ctx, id, map[string]any{
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastErr,
+	})
+}
+
+func (s *OutboxStore) MarkDead(ctx context.Context, id uint64, attempts int, lastErr string) error {
+	return s.update(ctx, id, map[string]any{
+		"status":     int32(outbox.StatusDead),
+		"attempts":   attempts,
+		"last_error": lastErr,
+	})
+}
+
+func (s *OutboxStore) update(ctx context.Context, id uint64, values map[string]any) error {
+	if s.db == nil {
+		return errors.New("db is nil")
+	}
+	return s.db.WithContext(systemContext(ctx)).Table(s.table).Where("id = ?", id).Updates(values).Error
+}
+
+// systemViewer 发件箱投递使用的系统身份
+type systemViewer struct {
+	viewer.Context
+}
+
+func (systemViewer) IsSystemContext() bool { return true }
+
+// systemContext 以系统视图访问发件箱表，跳过租户过滤
+func systemContext(ctx context.Context) context.Context {
+	return viewer.WithContext(ctx, systemViewer{Context: viewer.NewNoopContext()})
+}
+
+// insertOutboxEvents 使用 tx 的连接写入事件，并回填自增 ID
+func insertOutboxEvents(tx *gorm.DB, table string, events []*outbox.Event) error {
+	rows := make([]*OutboxEvent, 0, len(events))
+	for _, e := range events {
+		if e != nil {
+			rows = append(rows, NewOutboxEvent(e))
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := tx.Table(table).Create(rows).Error; err != nil {
+		return err
+	}
+
+	i := 0
+	for _, e := range events {
+		if e != nil {
+			e.ID, e.CreatedAt = rows[i].ID, rows[i].CreatedAt
+			i++
+		}
+	}
+	return nil
+}
@@ -1,64 +1,80 @@
 package pagination
 
 import (
-	"encoding/base64"
+	"fmt"
 
-	"github.com/tx7do/go-crud/pagination"
 	"gorm.io/gorm"
 
-	"github.com/go-kratos/kratos/v2/encoding"
-	_ "github.com/go-kratos/kratos/v2/encoding/json"
-
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/keyset"
 	"github.com/tx7do/go-crud/pagination/paginator"
 )
 
-// TokenPaginator 基于 Token 的分页器
+// TokenPaginator 基于 Token 的 keyset（seek）分页器
+// token 中记录了上一页边界行在所有排序列（含 tiebreaker）上的值
 type TokenPaginator struct {
-	impl  pagination.Paginator
-	codec encoding.Codec
+	impl       pagination.Paginator
+	tiebreaker string
+	codec      pagination.CursorCodec
 }
 
 func NewTokenPaginator() *TokenPaginator {
 	return &TokenPaginator{
-		impl:  paginator.NewTokenPaginatorWithDefault(),
-		codec: encoding.GetCodec("json"),
+		impl:       paginator.NewTokenPaginatorWithDefault(),
+		tiebreaker: keyset.DefaultTiebreaker,
+		codec:      pagination.DefaultCursorCodec(),
 	}
 }
 
-// BuildDB 根据传入 token/size 更新状态并返回应用到 *gorm.DB 的闭包
-// 使用示例： db = paginator.BuildDB(token, size)(db)
-func (p *TokenPaginator) BuildDB(token string, pageSize int) func(*gorm.DB) *gorm.DB {
+// WithTiebreaker 设置保证排序唯一性的列（默认 id）
+func (p *TokenPaginator) WithTiebreaker(column string) *TokenPaginator {
+	p.tiebreaker = column
+	return p
+}
+
+// WithCursorCodec 设置 token 的编解码器（签名/加密），为 nil 时使用默认编解码器
+func (p *TokenPaginator) WithCursorCodec(codec pagination.CursorCodec) *TokenPaginator {
+	if codec == nil {
+		codec = pagination.DefaultCursorCodec()
+	}
+	p.codec = codec
+	return p
+}
+
+// BuildSeek 根据传入 token/pageSize/排序更新状态并解析出本次查询的 keyset 状态。
+// scope 为 token 绑定的查询范围（见 pagination.CursorScope），在其他查询中复用 token 将返回错误。
+func (p *TokenPaginator) BuildSeek(token string, pageSize int, sortings []*paginationV1.Sorting, scope []byte) (*keyset.Seek, error) {
 	p.impl.
 		WithToken(token).
-		WithPage(pageSize)
+		WithSize(pageSize)
 
-	type cursor struct {
-		LastID int64 `json:"last_id"`
-	}
+	return keyset.NewSeek(token, p.impl.Size(), sortings, p.tiebreaker,
+		keyset.WithCodec(p.codec),
+		keyset.WithScope(scope),
+	)
+}
 
+// BuildDB 返回应用 keyset 条件、排序与 limit 的闭包（多取一条用于判断是否存在下一页）
+// 使用示例： db = paginator.BuildDB(seek)(db)
+func (p *TokenPaginator) BuildDB(seek *keyset.Seek) func(*gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
-		if db == nil {
+		if db == nil || seek == nil {
 			return db
 		}
 
-		// 无 token 或解码失败时只应用 pageSize
-		if token == "" {
-			return db.Limit(p.impl.Size())
+		if cond, args := seek.SQL(nil); cond != "" {
+			db = db.Where(cond, args...)
 		}
 
-		b, err := base64.StdEncoding.DecodeString(token)
-		if err != nil {
-			return db.Limit(p.impl.Size())
+		for _, c := range seek.OrderBy() {
+			dir := "ASC"
+			if c.Desc {
+				dir = "DESC"
+			}
+			db = db.Order(fmt.Sprintf("%s %s", c.Field, dir))
 		}
 
-		var c cursor
-		if err = p.codec.Unmarshal(b, &c); err != nil {
-			return db.Limit(p.impl.Size())
-		}
-
-		lastID := c.LastID
-		db = db.Where("id > ?", lastID)
-
-		return db.Limit(p.impl.Size())
+		return db.Limit(seek.Limit())
 	}
 }
@@ -0,0 +1,167 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/keyset"
+)
+
+type tokenTestEntity struct {
+	ID    uint `gorm:"primarykey"`
+	Name  string
+	Score int
+}
+
+func openTokenTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&tokenTestEntity{}); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+
+	// score 存在重复值，依赖 tiebreaker 保证顺序稳定
+	rows := []tokenTestEntity{
+		{Name: "a", Score: 3}, {Name: "b", Score: 1}, {Name: "c", Score: 3},
+		{Name: "d", Score: 2}, {Name: "e", Score: 1}, {Name: "f", Score: 2},
+		{Name: "g", Score: 3},
+	}
+	if err = db.Create(&rows).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return db
+}
+
+func listPage(t *testing.T, db *gorm.DB, token string, sortings []*paginationV1.Sorting) ([]string, string, string) {
+	p := NewTokenPaginator()
+	seek, err := p.BuildSeek(token, 3, sortings, nil)
+	if err != nil {
+		t.Fatalf("build seek: %v", err)
+	}
+
+	var rows []*tokenTestEntity
+	if err = db.Model(&tokenTestEntity{}).Scopes(p.BuildDB(seek)).Find(&rows).Error; err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	rows, next, prev, err := keyset.Page(seek, rows)
+	if err != nil {
+		t.Fatalf("page: %v", err)
+	}
+
+	names := make([]string, 0, len(rows))
+	for _, r := range rows {
+		names = append(names, r.Name)
+	}
+	return names, next, prev
+}
+
+func TestTokenPaginator_MixedDirections(t *testing.T) {
+	db := openTokenTestDB(t)
+
+	// score DESC, name ASC, id ASC
+	sortings := []*paginationV1.Sorting{
+		{Field: "score", Direction: paginationV1.Sorting_DESC},
+		{Field: "name", Direction: paginationV1.Sorting_ASC},
+	}
+	want := [][]string{{"a", "c", "g"}, {"d", "f", "b"}, {"e"}}
+
+	var pageTokens []string
+	token := ""
+	for i, w := range want {
+		names, next, prev := listPage(t, db, token, sortings)
+		if len(names) != len(w) {
+			t.Fatalf("page %d: got %v, want %v", i, names, w)
+		}
+		for j := range w {
+			if names[j] != w[j] {
+				t.Fatalf("page %d: got %v, want %v", i, names, w)
+			}
+		}
+		if i == 0 && prev != "" {
+			t.Fatalf("first page should not have prev token")
+		}
+		if i == len(want)-1 && next != "" {
+			t.Fatalf("last page should not have next token")
+		}
+		pageTokens = append(pageTokens, prev)
+		token = next
+	}
+
+	// 从最后一页向前翻页
+	names, next, prev := listPage(t, db, pageTokens[2], sortings)
+	if len(names) != 3 || names[0] != "d" || names[2] != "b" {
+		t.Fatalf("backward page: got %v", names)
+	}
+	if next == "" || prev == "" {
+		t.Fatalf("middle page should have both tokens, next=%q prev=%q", next, prev)
+	}
+
+	names, _, prev = listPage(t, db, prev, sortings)
+	if len(names) != 3 || names[0] != "a" || prev != "" {
+		t.Fatalf("first page via prev: got %v, prev=%q", names, prev)
+	}
+}
+
+func TestTokenPaginator_CursorMismatch(t *testing.T) {
+	db := openTokenTestDB(t)
+
+	_, next, _ := listPage(t, db, "", []*paginationV1.Sorting{{Field: "score"}})
+
+	p := NewTokenPaginator()
+	if _, err := p.BuildSeek(next, 3, []*paginationV1.Sorting{{Field: "name"}}, nil); err == nil {
+		t.Fatal("expected error when sorting changes between pages")
+	}
+}
+
+func TestTokenPaginator_SignedCursor(t *testing.T) {
+	db := openTokenTestDB(t)
+
+	codec, err := pagination.NewSignedCursorCodec([]pagination.CursorKey{{ID: "k1", Secret: []byte("0123456789abcdef0123456789abcdef")}})
+	if err != nil {
+		t.Fatalf("new codec: %v", err)
+	}
+
+	sortings := []*paginationV1.Sorting{{Field: "score"}}
+	scope := pagination.CursorScope(nil, sortings, nil)
+
+	p := NewTokenPaginator().WithCursorCodec(codec)
+	seek, err := p.BuildSeek("", 3, sortings, scope)
+	if err != nil {
+		t.Fatalf("build seek: %v", err)
+	}
+	var rows []*tokenTestEntity
+	if err = db.Model(&tokenTestEntity{}).Scopes(p.BuildDB(seek)).Find(&rows).Error; err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	_, next, _, err := keyset.Page(seek, rows)
+	if err != nil || next == "" {
+		t.Fatalf("page: next=%q, %v", next, err)
+	}
+
+	if _, err = p.BuildSeek(next, 3, sortings, scope); err != nil {
+		t.Fatalf("signed token should be accepted: %v", err)
+	}
+
+	// 在其他过滤条件下复用 token
+	otherScope := pagination.CursorScope(&paginationV1.FilterExpr{Type: paginationV1.ExprType_OR}, sortings, nil)
+	if _, err = p.BuildSeek(next, 3, sortings, otherScope); !errors.Is(err, pagination.ErrCursorScopeMismatch) {
+		t.Fatalf("expected ErrCursorScopeMismatch, got %v", err)
+	}
+
+	// 未签名（可伪造）的 token
+	forged, _ := keyset.Encode([]keyset.Column{{Field: "score"}, {Field: "id"}}, []any{0, 0}, false, keyset.WithScope(scope))
+	if _, err = p.BuildSeek(forged, 3, sortings, scope); !errors.Is(err, pagination.ErrCursorMalformed) {
+		t.Fatalf("expected ErrCursorMalformed, got %v", err)
+	}
+}
@@ -3,30 +3,33 @@ package gorm
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 
 	"github.com/go-kratos/kratos/v2/log"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 
 	"github.com/tx7do/go-utils/mapper"
 
+	crud "github.com/tx7do/go-crud"
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
 	"github.com/tx7do/go-crud/gorm/field"
 	"github.com/tx7do/go-crud/gorm/filter"
 	paging "github.com/tx7do/go-crud/gorm/pagination"
 	"github.com/tx7do/go-crud/gorm/sorting"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/datascope"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
 	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
+	"github.com/tx7do/go-crud/pagination/keyset"
+	"github.com/tx7do/go-crud/pagination/paginator"
 	paginationSorting "github.com/tx7do/go-crud/pagination/sorting"
 )
 
-// PagingResult 通用分页返回
-type PagingResult[E any] struct {
-	Items []*E   `json:"items"`
-	Total uint64 `json:"total"`
-}
+// PagingResult 通用分页返回（含完整的分页元数据），见 pagination.PagingResult
+type PagingResult[E any] = pagination.PagingResult[E]
 
 // CountOptions 为扩展的计数选项
 type CountOptions struct {
@@ -52,6 +55,16 @@ type Repository[DTO any, ENTITY any] struct {
 	orderByStringConverter *paginationSorting.OrderByStringConverter
 
 	fieldSelector *field.Selector
+
+	dataScope       *datascope.Policy
+	dataScopeFilter *filter.StructuredFilter
+
+	deletedAtColumn string
+	deletedByColumn string
+
+	versionColumn    string
+	conflictRetries  int
+	conflictResolver crud.ConflictResolver[DTO]
 }
 
 func NewRepository[DTO any, ENTITY any](mapper *mapper.CopierMapper[DTO, ENTITY]) *Repository[DTO, ENTITY] {
@@ -69,16 +82,44 @@ func NewRepository[DTO any, ENTITY any](mapper *mapper.CopierMapper[DTO, ENTITY]
 		orderByStringConverter: paginationSorting.NewOrderByStringConverter(),
 
 		fieldSelector: field.NewFieldSelector(),
+
+		versionColumn: DefaultVersionColumn,
 	}
 }
 
+// WithCursorCodec 设置 token 分页使用的游标编解码器，生产环境建议使用 pagination.SignedCursorCodec 防止 token 被伪造
+func (r *Repository[DTO, ENTITY]) WithCursorCodec(codec pagination.CursorCodec) *Repository[DTO, ENTITY] {
+	r.tokenPaginator.WithCursorCodec(codec)
+	return r
+}
+
+// WithFilterPolicy 设置过滤与排序的字段策略（白名单、列名映射、操作符限制等），违反策略的列表查询返回 paginationFilter.ErrPolicyViolation
+func (r *Repository[DTO, ENTITY]) WithFilterPolicy(policy *paginationFilter.FilterPolicy) *Repository[DTO, ENTITY] {
+	r.structuredFilter.WithPolicy(policy)
+	r.structuredSorting.WithPolicy(policy)
+	return r
+}
+
+// WithFieldMapping 设置 API 字段名到列名的映射，过滤、排序与字段选择均按映射解析列名
+func (r *Repository[DTO, ENTITY]) WithFieldMapping(mapping *fieldmap.Mapping) *Repository[DTO, ENTITY] {
+	r.structuredFilter.WithFieldMapping(mapping)
+	r.structuredSorting.WithFieldMapping(mapping)
+	r.fieldSelector.WithFieldMapping(mapping)
+	return r
+}
+
 // Count 使用 whereSelectors 计算符合条件的记录数
 func (r *Repository[DTO, ENTITY]) Count(ctx context.Context, db *gorm.DB, whereSelectors []func(*gorm.DB) *gorm.DB) (int64, error) {
 	if db == nil {
 		return 0, errors.New("db is nil")
 	}
 
-	countDB := db.WithContext(ctx).Model(new(ENTITY))
+	whereSelectors, err := r.withDataScope(ctx, whereSelectors)
+	if err != nil {
+		return 0, err
+	}
+
+	countDB := withTx(ctx, db).Model(new(ENTITY))
 	for _, s := range whereSelectors {
 		if s != nil {
 			countDB = s(countDB)
@@ -88,7 +129,7 @@ func (r *Repository[DTO, ENTITY]) Count(ctx context.Context, db *gorm.DB, whereS
 	var cnt int64
 	if err := countDB.Count(&cnt).Error; err != nil {
 		log.Errorf("query count failed: %s", err.Error())
-		return 0, errors.New("query count failed")
+		return 0, fmt.Errorf("query count failed: %w", err)
 	}
 	return cnt, nil
 }
@@ -103,6 +144,11 @@ func (r *Repository[DTO, ENTITY]) CountWithOptions(ctx context.Context, db *gorm
 		opts = &CountOptions{}
 	}
 
+	whereSelectors, err := r.withDataScope(ctx, whereSelectors)
+	if err != nil {
+		return 0, err
+	}
+
 	// 支持超时
 	if opts.Timeout > 0 {
 		var cancel context.CancelFunc
@@ -110,7 +156,7 @@ func (r *Repository[DTO, ENTITY]) CountWithOptions(ctx context.Context, db *gorm
 		defer cancel()
 	}
 
-	countDB := db.WithContext(ctx).Model(new(ENTITY))
+	countDB := withTx(ctx, db).Model(new(ENTITY))
 
 	// 应用 where selectors
 	for _, s := range whereSelectors {
@@ -134,7 +180,7 @@ func (r *Repository[DTO, ENTITY]) CountWithOptions(ctx context.Context, db *gorm
 	var cnt int64
 	if err := countDB.Count(&cnt).Error; err != nil {
 		log.Errorf("query count failed: %s", err.Error())
-		return 0, errors.New("query count failed")
+		return 0, fmt.Errorf("query count failed: %w", err)
 	}
 	return cnt, nil
 }
@@ -153,6 +199,8 @@ func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, db *gorm.D
 	var selectSelector func(*gorm.DB) *gorm.DB
 	var sortingSelector func(*gorm.DB) *gorm.DB
 	var pagingSelector func(*gorm.DB) *gorm.DB
+	var seek *keyset.Seek
+	var pager pagination.Paginator
 
 	// apply filters
 	var filterExpr *paginationV1.FilterExpr
@@ -166,47 +214,73 @@ func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, db *gorm.D
 	whereSelectors, err = r.structuredFilter.BuildSelectors(req.GetFilterExpr())
 	if err != nil {
 		log.Errorf("build structured filter selectors failed: %s", err.Error())
-	}
-
-	// select fields
-	if req.GetFieldMask() != nil && len(req.GetFieldMask().Paths) > 0 {
-		selectSelector, err = r.fieldSelector.BuildSelector(req.GetFieldMask().GetPaths())
-		if err != nil {
-			log.Errorf("build field select selector failed: %s", err.Error())
+		if errors.Is(err, paginationFilter.ErrPolicyViolation) {
+			return nil, err
 		}
 	}
 
 	// order by
+	var sortings []*paginationV1.Sorting
 	if len(req.GetOrderBy()) > 0 {
-		var sortings []*paginationV1.Sorting
 		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
 		if err != nil {
 			log.Errorf("convert order by string to sorting failed: %s", err.Error())
 			return nil, err
 		}
-		sortingSelector = r.structuredSorting.BuildScope(sortings)
 	} else if len(req.GetSorting()) > 0 {
-		sortingSelector = r.structuredSorting.BuildScope(req.GetSorting())
+		sortings = req.GetSorting()
+	}
+	// 按字段策略校验排序字段，keyset 分页使用映射后的列名
+	var sortColumns []*paginationV1.Sorting
+	if sortColumns, err = r.structuredSorting.Validate(sortings); err != nil {
+		log.Errorf("validate sorting failed: %s", err.Error())
+		return nil, err
+	}
+	if len(sortings) > 0 {
+		sortingSelector = r.structuredSorting.BuildScope(sortings)
 	}
 
 	// pagination
 	if !req.GetNoPaging() {
 		if req.Page != nil && req.PageSize != nil {
+			pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPage())).WithSize(int(req.GetPageSize()))
 			pagingSelector = r.pagePaginator.BuildDB(int(req.GetPage()), int(req.GetPageSize()))
 		} else if req.Offset != nil && req.Limit != nil {
+			pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffset())).WithLimit(int(req.GetLimit()))
 			pagingSelector = r.offsetPaginator.BuildDB(int(req.GetOffset()), int(req.GetLimit()))
 		} else if req.Token != nil && req.Offset != nil {
-			pagingSelector = r.tokenPaginator.BuildDB(req.GetToken(), int(req.GetOffset()))
+			if seek, err = r.tokenPaginator.BuildSeek(req.GetToken(), int(req.GetOffset()), sortColumns, pagination.CursorScopeOf(req)); err != nil {
+				log.Errorf("build keyset seek failed: %s", err.Error())
+				return nil, err
+			}
+			pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetToken()).WithSize(seek.Size)
+			// keyset 分页自行处理排序（含 tiebreaker 以及向前翻页时的反转）
+			sortingSelector = nil
+			pagingSelector = r.tokenPaginator.BuildDB(seek)
+		}
+	}
+
+	// select fields（keyset 分页需要排序列的值生成游标，未请求的排序列在返回前清除）
+	var seekExtra []keyset.Column
+	if req.GetFieldMask() != nil && len(req.GetFieldMask().Paths) > 0 {
+		var paths []string
+		paths, seekExtra = seek.Select(req.GetFieldMask().GetPaths(), r.fieldSelector.Resolve)
+		selectSelector, err = r.fieldSelector.BuildSelector(paths)
+		if err != nil {
+			log.Errorf("build field select selector failed: %s", err.Error())
 		}
 	}
 
 	// 构造查询 DB 并应用 selectors
-	listDB := db.WithContext(ctx).Model(new(ENTITY))
+	listDB := withTx(ctx, db).Model(new(ENTITY))
 	for _, s := range whereSelectors {
 		if s != nil {
 			listDB = s(listDB)
 		}
 	}
+	if listDB, err = r.applyDataScope(ctx, listDB); err != nil {
+		return nil, err
+	}
 	if selectSelector != nil {
 		listDB = selectSelector(listDB)
 	}
@@ -221,7 +295,18 @@ func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, db *gorm.D
 	var entities []*ENTITY
 	if err = listDB.Find(&entities).Error; err != nil {
 		log.Errorf("query list failed: %s", err.Error())
-		return nil, errors.New("query list failed")
+		return nil, fmt.Errorf("query list failed: %w", err)
+	}
+
+	if seek != nil {
+		var nextToken, prevToken string
+		if entities, nextToken, prevToken, err = keyset.Page(seek, entities); err != nil {
+			log.Errorf("build keyset token failed: %s", err.Error())
+			return nil, err
+		}
+		pager.SetNextToken(nextToken)
+		pager.SetPrevToken(prevToken)
+		keyset.Clear(entities, seekExtra)
 	}
 
 	// map to DTOs
@@ -230,17 +315,14 @@ func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, db *gorm.D
 		dtos = append(dtos, r.mapper.ToDTO(e))
 	}
 
-	// 计数（只使用 whereSelectors）
-	total, err := r.Count(ctx, db, whereSelectors)
+	// 计数（只使用 whereSelectors 与数据权限条件，统计方式由 pagination.WithCountStrategy 指定）
+	count, err := r.countByStrategy(ctx, db, whereSelectors)
 	if err != nil {
 		log.Errorf("count query failed: %s", err.Error())
 		return nil, err
 	}
 
-	res := &PagingResult[DTO]{
-		Items: dtos,
-		Total: uint64(total),
-	}
+	res := pagination.NewPagingResultWithCount(dtos, count, pager)
 	return res, nil
 }
 
@@ -258,6 +340,8 @@ func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, db *go
 	var selectSelector func(*gorm.DB) *gorm.DB
 	var sortingSelector func(*gorm.DB) *gorm.DB
 	var pagingSelector func(*gorm.DB) *gorm.DB
+	var seek *keyset.Seek
+	var pager pagination.Paginator
 
 	// filters
 	var filterExpr *paginationV1.FilterExpr
@@ -271,46 +355,72 @@ func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, db *go
 	whereSelectors, err = r.structuredFilter.BuildSelectors(req.GetFilterExpr())
 	if err != nil {
 		log.Errorf("build structured filter selectors failed: %s", err.Error())
-	}
-
-	// select fields
-	if req.GetFieldMask() != nil && len(req.GetFieldMask().Paths) > 0 {
-		selectSelector, err = r.fieldSelector.BuildSelector(req.GetFieldMask().GetPaths())
-		if err != nil {
-			log.Errorf("build field select selector failed: %s", err.Error())
+		if errors.Is(err, paginationFilter.ErrPolicyViolation) {
+			return nil, err
 		}
 	}
 
 	// order by
+	var sortings []*paginationV1.Sorting
 	if len(req.GetOrderBy()) > 0 {
-		var sortings []*paginationV1.Sorting
 		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
 		if err != nil {
 			log.Errorf("convert order by string to sorting failed: %s", err.Error())
 			return nil, err
 		}
-		sortingSelector = r.structuredSorting.BuildScope(sortings)
 	} else if len(req.GetSorting()) > 0 {
-		sortingSelector = r.structuredSorting.BuildScope(req.GetSorting())
+		sortings = req.GetSorting()
+	}
+	// 按字段策略校验排序字段，keyset 分页使用映射后的列名
+	var sortColumns []*paginationV1.Sorting
+	if sortColumns, err = r.structuredSorting.Validate(sortings); err != nil {
+		log.Errorf("validate sorting failed: %s", err.Error())
+		return nil, err
+	}
+	if len(sortings) > 0 {
+		sortingSelector = r.structuredSorting.BuildScope(sortings)
 	}
 
 	// pagination types
 	switch req.GetPaginationType().(type) {
 	case *paginationV1.PaginationRequest_OffsetBased:
+		pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffsetBased().GetOffset())).WithLimit(int(req.GetOffsetBased().GetLimit()))
 		pagingSelector = r.offsetPaginator.BuildDB(int(req.GetOffsetBased().GetOffset()), int(req.GetOffsetBased().GetLimit()))
 	case *paginationV1.PaginationRequest_PageBased:
+		pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPageBased().GetPage())).WithSize(int(req.GetPageBased().GetPageSize()))
 		pagingSelector = r.pagePaginator.BuildDB(int(req.GetPageBased().GetPage()), int(req.GetPageBased().GetPageSize()))
 	case *paginationV1.PaginationRequest_TokenBased:
-		pagingSelector = r.tokenPaginator.BuildDB(req.GetTokenBased().GetToken(), int(req.GetTokenBased().GetPageSize()))
+		if seek, err = r.tokenPaginator.BuildSeek(req.GetTokenBased().GetToken(), int(req.GetTokenBased().GetPageSize()), sortColumns, pagination.CursorScopeOf(req)); err != nil {
+			log.Errorf("build keyset seek failed: %s", err.Error())
+			return nil, err
+		}
+		pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetTokenBased().GetToken()).WithSize(seek.Size)
+		// keyset 分页自行处理排序（含 tiebreaker 以及向前翻页时的反转）
+		sortingSelector = nil
+		pagingSelector = r.tokenPaginator.BuildDB(seek)
+	}
+
+	// select fields（keyset 分页需要排序列的值生成游标，未请求的排序列在返回前清除）
+	var seekExtra []keyset.Column
+	if req.GetFieldMask() != nil && len(req.GetFieldMask().Paths) > 0 {
+		var paths []string
+		paths, seekExtra = seek.Select(req.GetFieldMask().GetPaths(), r.fieldSelector.Resolve)
+		selectSelector, err = r.fieldSelector.BuildSelector(paths)
+		if err != nil {
+			log.Errorf("build field select selector failed: %s", err.Error())
+		}
 	}
 
 	// 构造查询 DB 并应用 selectors
-	listDB := db.WithContext(ctx).Model(new(ENTITY))
+	listDB := withTx(ctx, db).Model(new(ENTITY))
 	for _, s := range whereSelectors {
 		if s != nil {
 			listDB = s(listDB)
 		}
 	}
+	if listDB, err = r.applyDataScope(ctx, listDB); err != nil {
+		return nil, err
+	}
 	if selectSelector != nil {
 		listDB = selectSelector(listDB)
 	}
@@ -325,7 +435,18 @@ func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, db *go
 	var entities []*ENTITY
 	if err = listDB.Find(&entities).Error; err != nil {
 		log.Errorf("query list failed: %s", err.Error())
-		return nil, errors.New("query list failed")
+		return nil, fmt.Errorf("query list failed: %w", err)
+	}
+
+	if seek != nil {
+		var nextToken, prevToken string
+		if entities, nextToken, prevToken, err = keyset.Page(seek, entities); err != nil {
+			log.Errorf("build keyset token failed: %s", err.Error())
+			return nil, err
+		}
+		pager.SetNextToken(nextToken)
+		pager.SetPrevToken(prevToken)
+		keyset.Clear(entities, seekExtra)
 	}
 
 	// map to DTOs
@@ -334,17 +455,14 @@ func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, db *go
 		dtos = append(dtos, r.mapper.ToDTO(e))
 	}
 
-	// 计数
-	total, err := r.Count(ctx, db, whereSelectors)
+	// 计数（只使用 whereSelectors 与数据权限条件，统计方式由 pagination.WithCountStrategy 指定）
+	count, err := r.countByStrategy(ctx, db, whereSelectors)
 	if err != nil {
 		log.Errorf("count query failed: %s", err.Error())
 		return nil, err
 	}
 
-	res := &PagingResult[DTO]{
-		Items: dtos,
-		Total: uint64(total),
-	}
+	res := pagination.NewPagingResultWithCount(dtos, count, pager)
 	return res, nil
 }
 
@@ -357,7 +475,11 @@ func (r *Repository[DTO, ENTITY]) Get(ctx context.Context, db *gorm.DB, viewMask
 
 	field.NormalizeFieldMaskPaths(viewMask)
 
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
+	qdb := withTx(ctx, db).Model(new(ENTITY))
+	qdb, err := r.applyDataScope(ctx, qdb)
+	if err != nil {
+		return nil, err
+	}
 	if viewMask != nil && len(viewMask.Paths) > 0 {
 		qdb = qdb.Select(viewMask.GetPaths())
 	}
@@ -384,7 +506,11 @@ func (r *Repository[DTO, ENTITY]) GetWithFilters(ctx context.Context, db *gorm.D
 	field.NormalizeFieldMaskPaths(viewMask)
 
 	// 构造查询 DB 并应用 where selectors
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
+	qdb := withTx(ctx, db).Model(new(ENTITY))
+	qdb, err := r.applyDataScope(ctx, qdb)
+	if err != nil {
+		return nil, err
+	}
 	for _, s := range whereSelectors {
 		if s != nil {
 			qdb = s(qdb)
@@ -428,11 +554,11 @@ func (r *Repository[DTO, ENTITY]) Create(ctx context.Context, db *gorm.DB, dto *
 	ent := r.mapper.ToEntity(dto)
 
 	// 执行创建
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
+	qdb := withTx(ctx, db).Model(new(ENTITY))
 	res := qdb.Create(&ent)
 	if res.Error != nil {
 		log.Errorf("create failed: %s", res.Error.Error())
-		return nil, errors.New("create failed")
+		return nil, fmt.Errorf("create failed: %w", res.Error)
 	}
 
 	// 返回创建后的 DTO（ent 已由 GORM 填充自增等字段）
@@ -456,7 +582,7 @@ func (r *Repository[DTO, ENTITY]) CreateX(ctx context.Context, db *gorm.DB, dto
 	ent := r.mapper.ToEntity(dto)
 
 	// 构造 DB（传入的 db 可已包含 where/其他 scope）
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
+	qdb := withTx(ctx, db).Model(new(ENTITY))
 
 	// 指定插入字段（如果需要）
 	if viewMask != nil && len(viewMask.Paths) > 0 {
@@ -467,7 +593,7 @@ func (r *Repository[DTO, ENTITY]) CreateX(ctx context.Context, db *gorm.DB, dto
 	res := qdb.Create(&ent)
 	if res.Error != nil {
 		log.Errorf("create failed: %s", res.Error.Error())
-		return 0, errors.New("create failed")
+		return 0, fmt.Errorf("create failed: %w", res.Error)
 	}
 	return res.RowsAffected, nil
 }
@@ -489,7 +615,7 @@ func (r *Repository[DTO, ENTITY]) CreateXWithFilters(ctx context.Context, db *go
 	ent := r.mapper.ToEntity(dto)
 
 	// 构造查询 DB 并应用 where selectors（尽管 Create 常不依赖 where，但遵循项目风格）
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
+	qdb := withTx(ctx, db).Model(new(ENTITY))
 	for _, s := range whereSelectors {
 		if s != nil {
 			qdb = s(qdb)
@@ -505,7 +631,7 @@ func (r *Repository[DTO, ENTITY]) CreateXWithFilters(ctx context.Context, db *go
 	res := qdb.Create(&ent)
 	if res.Error != nil {
 		log.Errorf("create failed: %s", res.Error.Error())
-		return 0, errors.New("create failed")
+		return 0, fmt.Errorf("create failed: %w", res.Error)
 	}
 	return res.RowsAffected, nil
 }
@@ -533,7 +659,7 @@ func (r *Repository[DTO, ENTITY]) BatchCreate(ctx context.Context, db *gorm.DB,
 		ent := r.mapper.ToEntity(dto)
 
 		// 为每条记录构造独立的操作 DB（保留传入 db 的 scope）
-		qdb := db.WithContext(ctx).Model(new(ENTITY))
+		qdb := withTx(ctx, db).Model(new(ENTITY))
 		if viewMask != nil && len(viewMask.Paths) > 0 {
 			qdb = qdb.Select(viewMask.GetPaths())
 		}
@@ -541,7 +667,7 @@ func (r *Repository[DTO, ENTITY]) BatchCreate(ctx context.Context, db *gorm.DB,
 		createResult := qdb.Create(&ent)
 		if createResult.Error != nil {
 			log.Errorf("batch create failed: %s", createResult.Error.Error())
-			return nil, errors.New("batch create failed")
+			return nil, fmt.Errorf("batch create failed: %w", createResult.Error)
 		}
 
 		res = append(res, r.mapper.ToDTO(ent))
@@ -563,22 +689,16 @@ func (r *Repository[DTO, ENTITY]) Update(ctx context.Context, db *gorm.DB, dto *
 	// 规范 updateMask 路径
 	field.NormalizeFieldMaskPaths(updateMask)
 
-	// DTO -> ENTITY
-	ent := r.mapper.ToEntity(dto)
-
 	// 构造查询 DB（传入的 db 可已包含 where）
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
-
-	// 指定更新字段
-	if updateMask != nil && len(updateMask.Paths) > 0 {
-		qdb = qdb.Select(updateMask.GetPaths())
+	qdb := withTx(ctx, db).Model(new(ENTITY))
+	qdb, err := r.applyDataScope(ctx, qdb)
+	if err != nil {
+		return nil, err
 	}
 
-	// 执行更新
-	res := qdb.Updates(ent)
-	if res.Error != nil {
-		log.Errorf("update failed: %s", res.Error.Error())
-		return nil, errors.New("update failed")
+	// 执行更新（实体包含版本字段时启用乐观锁）
+	if _, err = r.updates(ctx, qdb, dto, updateMask); err != nil {
+		return nil, err
 	}
 
 	// 读取并返回更新后的实体
@@ -603,27 +723,21 @@ func (r *Repository[DTO, ENTITY]) UpdateWithFilters(ctx context.Context, db *gor
 	// 规范 updateMask 路径
 	field.NormalizeFieldMaskPaths(updateMask)
 
-	// DTO -> ENTITY
-	ent := r.mapper.ToEntity(dto)
-
 	// 构造查询 DB 并应用 where selectors
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
+	qdb := withTx(ctx, db).Model(new(ENTITY))
+	qdb, err := r.applyDataScope(ctx, qdb)
+	if err != nil {
+		return nil, err
+	}
 	for _, s := range whereSelectors {
 		if s != nil {
 			qdb = s(qdb)
 		}
 	}
 
-	// 指定更新字段
-	if updateMask != nil && len(updateMask.Paths) > 0 {
-		qdb = qdb.Select(updateMask.GetPaths())
-	}
-
-	// 执行更新
-	res := qdb.Updates(ent)
-	if res.Error != nil {
-		log.Errorf("update failed: %s", res.Error.Error())
-		return nil, errors.New("update failed")
+	// 执行更新（实体包含版本字段时启用乐观锁）
+	if _, err = r.updates(ctx, qdb, dto, updateMask); err != nil {
+		return nil, err
 	}
 
 	// 读取并返回更新后的实体
@@ -648,24 +762,15 @@ func (r *Repository[DTO, ENTITY]) UpdateX(ctx context.Context, db *gorm.DB, dto
 	// 规范 updateMask 路径
 	field.NormalizeFieldMaskPaths(updateMask)
 
-	// DTO -> ENTITY
-	ent := r.mapper.ToEntity(dto)
-
 	// 构造查询 DB（传入的 db 可已包含 where）
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
-
-	// 指定更新字段
-	if updateMask != nil && len(updateMask.Paths) > 0 {
-		qdb = qdb.Select(updateMask.GetPaths())
+	qdb := withTx(ctx, db).Model(new(ENTITY))
+	qdb, err := r.applyDataScope(ctx, qdb)
+	if err != nil {
+		return 0, err
 	}
 
-	// 执行更新
-	res := qdb.Updates(ent)
-	if res.Error != nil {
-		log.Errorf("update failed: %s", res.Error.Error())
-		return 0, errors.New("update failed")
-	}
-	return res.RowsAffected, nil
+	// 执行更新（实体包含版本字段时启用乐观锁）
+	return r.updates(ctx, qdb, dto, updateMask)
 }
 
 // UpdateXWithFilters 接受 whereSelectors 并在内部应用到查询 DB，然后执行更新，返回受影响行数
@@ -681,29 +786,20 @@ func (r *Repository[DTO, ENTITY]) UpdateXWithFilters(ctx context.Context, db *go
 	// 规范 updateMask 路径
 	field.NormalizeFieldMaskPaths(updateMask)
 
-	// DTO -> ENTITY
-	ent := r.mapper.ToEntity(dto)
-
 	// 构造查询 DB 并应用 where selectors
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
+	qdb := withTx(ctx, db).Model(new(ENTITY))
+	qdb, err := r.applyDataScope(ctx, qdb)
+	if err != nil {
+		return 0, err
+	}
 	for _, s := range whereSelectors {
 		if s != nil {
 			qdb = s(qdb)
 		}
 	}
 
-	// 指定更新字段
-	if updateMask != nil && len(updateMask.Paths) > 0 {
-		qdb = qdb.Select(updateMask.GetPaths())
-	}
-
-	// 执行更新
-	res := qdb.Updates(ent)
-	if res.Error != nil {
-		log.Errorf("update failed: %s", res.Error.Error())
-		return 0, errors.New("update failed")
-	}
-	return res.RowsAffected, nil
+	// 执行更新（实体包含版本字段时启用乐观锁）
+	return r.updates(ctx, qdb, dto, updateMask)
 }
 
 // Upsert 使用传入的 db（可包含 Where/其他 scope）执行插入或冲突更新，支持 updateMask 指定冲突时更新的字段
@@ -719,32 +815,14 @@ func (r *Repository[DTO, ENTITY]) Upsert(ctx context.Context, db *gorm.DB, dto *
 	// 规范 updateMask 路径
 	field.NormalizeFieldMaskPaths(updateMask)
 
-	// DTO -> ENTITY
-	ent := r.mapper.ToEntity(dto)
-
 	// 构造查询 DB（传入的 db 可已包含 where/其他 scope）
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
-
-	// 构造 OnConflict 子句：若提供了 updateMask 则仅在冲突时更新指定列，否则更新所有列
-	var onConflict clause.OnConflict
-	if updateMask != nil && len(updateMask.Paths) > 0 {
-		onConflict = clause.OnConflict{
-			DoUpdates: clause.AssignmentColumns(updateMask.GetPaths()),
-		}
-	} else {
-		onConflict = clause.OnConflict{
-			UpdateAll: true,
-		}
-	}
+	qdb := withTx(ctx, db).Model(new(ENTITY))
 
-	// 执行 upsert
-	res := qdb.Clauses(onConflict).Create(&ent)
-	if res.Error != nil {
-		log.Errorf("upsert failed: %s", res.Error.Error())
-		return nil, errors.New("upsert failed")
+	// 执行 upsert（实体包含版本字段时启用乐观锁），返回 upsert 后的 DTO（ent 已由 GORM 填充）
+	ent, _, err := r.upsert(ctx, qdb, dto, updateMask)
+	if err != nil {
+		return nil, err
 	}
-
-	// 返回 upsert 后的 DTO（ent 已由 GORM 填充）
 	return r.mapper.ToDTO(ent), nil
 }
 
@@ -761,36 +839,19 @@ func (r *Repository[DTO, ENTITY]) UpsertWithFilters(ctx context.Context, db *gor
 	// 规范 updateMask 路径
 	field.NormalizeFieldMaskPaths(updateMask)
 
-	// DTO -> ENTITY
-	ent := r.mapper.ToEntity(dto)
-
 	// 构造查询 DB 并应用 where selectors（遵循项目风格）
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
+	qdb := withTx(ctx, db).Model(new(ENTITY))
 	for _, s := range whereSelectors {
 		if s != nil {
 			qdb = s(qdb)
 		}
 	}
 
-	// 构造 OnConflict 子句
-	var onConflict clause.OnConflict
-	if updateMask != nil && len(updateMask.Paths) > 0 {
-		onConflict = clause.OnConflict{
-			DoUpdates: clause.AssignmentColumns(updateMask.GetPaths()),
-		}
-	} else {
-		onConflict = clause.OnConflict{
-			UpdateAll: true,
-		}
-	}
-
-	// 执行 upsert
-	res := qdb.Clauses(onConflict).Create(&ent)
-	if res.Error != nil {
-		log.Errorf("upsert failed: %s", res.Error.Error())
-		return nil, errors.New("upsert failed")
+	// 执行 upsert（实体包含版本字段时启用乐观锁），返回 upsert 后的 DTO（ent 已由 GORM 填充）
+	ent, _, err := r.upsert(ctx, qdb, dto, updateMask)
+	if err != nil {
+		return nil, err
 	}
-
 	return r.mapper.ToDTO(ent), nil
 }
 
@@ -807,32 +868,12 @@ func (r *Repository[DTO, ENTITY]) UpsertX(ctx context.Context, db *gorm.DB, dto
 	// 规范 updateMask 路径
 	field.NormalizeFieldMaskPaths(updateMask)
 
-	// DTO -> ENTITY
-	ent := r.mapper.ToEntity(dto)
-
 	// 构造查询 DB（传入的 db 可已包含 where/其他 scope）
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
-
-	// 构造 OnConflict 子句：若提供了 updateMask 则仅在冲突时更新指定列，否则更新所有列
-	var onConflict clause.OnConflict
-	if updateMask != nil && len(updateMask.Paths) > 0 {
-		onConflict = clause.OnConflict{
-			DoUpdates: clause.AssignmentColumns(updateMask.GetPaths()),
-		}
-	} else {
-		onConflict = clause.OnConflict{
-			UpdateAll: true,
-		}
-	}
-
-	// 执行 upsert
-	res := qdb.Clauses(onConflict).Create(&ent)
-	if res.Error != nil {
-		log.Errorf("upsert failed: %s", res.Error.Error())
-		return 0, errors.New("upsert failed")
-	}
+	qdb := withTx(ctx, db).Model(new(ENTITY))
 
-	return res.RowsAffected, nil
+	// 执行 upsert（实体包含版本字段时启用乐观锁）
+	_, rows, err := r.upsert(ctx, qdb, dto, updateMask)
+	return rows, err
 }
 
 // UpsertXWithFilters 接受 whereSelectors 并在内部应用到查询 DB，然后执行 upsert，支持 updateMask 指定冲突时更新的字段，返回受影响行数
@@ -848,37 +889,17 @@ func (r *Repository[DTO, ENTITY]) UpsertXWithFilters(ctx context.Context, db *go
 	// 规范 updateMask 路径
 	field.NormalizeFieldMaskPaths(updateMask)
 
-	// DTO -> ENTITY
-	ent := r.mapper.ToEntity(dto)
-
 	// 构造查询 DB 并应用 where selectors（遵循项目风格）
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
+	qdb := withTx(ctx, db).Model(new(ENTITY))
 	for _, s := range whereSelectors {
 		if s != nil {
 			qdb = s(qdb)
 		}
 	}
 
-	// 构造 OnConflict 子句
-	var onConflict clause.OnConflict
-	if updateMask != nil && len(updateMask.Paths) > 0 {
-		onConflict = clause.OnConflict{
-			DoUpdates: clause.AssignmentColumns(updateMask.GetPaths()),
-		}
-	} else {
-		onConflict = clause.OnConflict{
-			UpdateAll: true,
-		}
-	}
-
-	// 执行 upsert
-	res := qdb.Clauses(onConflict).Create(&ent)
-	if res.Error != nil {
-		log.Errorf("upsert failed: %s", res.Error.Error())
-		return 0, errors.New("upsert failed")
-	}
-
-	return res.RowsAffected, nil
+	// 执行 upsert（实体包含版本字段时启用乐观锁）
+	_, rows, err := r.upsert(ctx, qdb, dto, updateMask)
+	return rows, err
 }
 
 // Delete 使用传入的 db（可包含 Where）删除记录
@@ -888,7 +909,11 @@ func (r *Repository[DTO, ENTITY]) Delete(ctx context.Context, db *gorm.DB, notSo
 		return 0, errors.New("db is nil")
 	}
 
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
+	qdb := withTx(ctx, db).Model(new(ENTITY))
+	qdb, err := r.applyDataScope(ctx, qdb)
+	if err != nil {
+		return 0, err
+	}
 
 	if notSoftDelete {
 		qdb = qdb.Unscoped()
@@ -897,7 +922,7 @@ func (r *Repository[DTO, ENTITY]) Delete(ctx context.Context, db *gorm.DB, notSo
 	res := qdb.Delete(new(ENTITY))
 	if res.Error != nil {
 		log.Errorf("delete failed: %s", res.Error.Error())
-		return 0, errors.New("delete failed")
+		return 0, fmt.Errorf("delete failed: %w", res.Error)
 	}
 	return res.RowsAffected, nil
 }
@@ -909,7 +934,11 @@ func (r *Repository[DTO, ENTITY]) DeleteWithFilters(ctx context.Context, db *gor
 		return 0, errors.New("db is nil")
 	}
 
-	qdb := db.WithContext(ctx).Model(new(ENTITY))
+	qdb := withTx(ctx, db).Model(new(ENTITY))
+	qdb, err := r.applyDataScope(ctx, qdb)
+	if err != nil {
+		return 0, err
+	}
 	for _, s := range whereSelectors {
 		if s != nil {
 			qdb = s(qdb)
@@ -919,7 +948,7 @@ func (r *Repository[DTO, ENTITY]) DeleteWithFilters(ctx context.Context, db *gor
 	res := qdb.Delete(new(ENTITY))
 	if res.Error != nil {
 		log.Errorf("delete failed: %s", res.Error.Error())
-		return 0, errors.New("delete failed")
+		return 0, fmt.Errorf("delete failed: %w", res.Error)
 	}
 	return res.RowsAffected, nil
 }
@@ -939,7 +968,11 @@ func (r *Repository[DTO, ENTITY]) Exists(ctx context.Context, db *gorm.DB) (bool
 		return false, errors.New("db is nil")
 	}
 
-	qdb := db.WithContext(ctx).Model(new(ENTITY)).Limit(1)
+	qdb := withTx(ctx, db).Model(new(ENTITY)).Limit(1)
+	qdb, err := r.applyDataScope(ctx, qdb)
+	if err != nil {
+		return false, err
+	}
 
 	var ent ENTITY
 	if err := qdb.First(&ent).Error; err != nil {
@@ -947,7 +980,7 @@ func (r *Repository[DTO, ENTITY]) Exists(ctx context.Context, db *gorm.DB) (bool
 			return false, nil
 		}
 		log.Errorf("exists query failed: %s", err.Error())
-		return false, errors.New("exists query failed")
+		return false, fmt.Errorf("exists query failed: %w", err)
 	}
 	return true, nil
 }
@@ -959,7 +992,11 @@ func (r *Repository[DTO, ENTITY]) ExistsWithFilters(ctx context.Context, db *gor
 		return false, errors.New("db is nil")
 	}
 
-	qdb := db.WithContext(ctx).Model(new(ENTITY)).Limit(1)
+	qdb := withTx(ctx, db).Model(new(ENTITY)).Limit(1)
+	qdb, err := r.applyDataScope(ctx, qdb)
+	if err != nil {
+		return false, err
+	}
 	for _, s := range whereSelectors {
 		if s != nil {
 			qdb = s(qdb)
@@ -972,7 +1009,7 @@ func (r *Repository[DTO, ENTITY]) ExistsWithFilters(ctx context.Context, db *gor
 			return false, nil
 		}
 		log.Errorf("exists query failed: %s", err.Error())
-		return false, errors.New("exists query failed")
+		return false, fmt.Errorf("exists query failed: %w", err)
 	}
 	return true, nil
 }
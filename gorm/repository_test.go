@@ -1,11 +1,26 @@
 package gorm
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/glebarez/sqlite"
+	"github.com/tx7do/go-utils/mapper"
+	"github.com/tx7do/go-utils/trans"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/datascope"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
+	"github.com/tx7do/go-crud/viewer"
 )
 
 // 测试用实体与 DTO
@@ -402,3 +417,465 @@ func seedUsersForUpdater(t *testing.T, db *gorm.DB, users ...testUserEntity) {
 //		t.Fatalf("UpdateXWithFilters did not set age to 40, got %d", got2.Age)
 //	}
 //}
+
+func TestRepository_ListWithPagination_Meta(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&testUserEntity{}); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		seedUsers(t, db, testUserEntity{Name: name})
+	}
+
+	ctx := context.Background()
+	q := NewRepository[testUserEntity, testUserEntity](mapper.NewCopierMapper[testUserEntity, testUserEntity]())
+
+	// 页码分页
+	res, err := q.ListWithPagination(ctx, db, &paginationV1.PaginationRequest{
+		PaginationType: &paginationV1.PaginationRequest_PageBased{PageBased: &paginationV1.PageBasedPagination{Page: 2, PageSize: 2}},
+	})
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	m := res.Meta
+	if m.GetTotal().GetValue() != 5 || m.GetTotalPages().GetValue() != 3 || m.GetCurrentPage().GetValue() != 2 {
+		t.Fatalf("unexpected page meta: %v", m)
+	}
+	if m.GetPageSize() != 2 || m.GetCurrentSize() != 2 {
+		t.Fatalf("unexpected size meta: %v", m)
+	}
+
+	// Token 分页
+	res, err = q.ListWithPagination(ctx, db, &paginationV1.PaginationRequest{
+		PaginationType: &paginationV1.PaginationRequest_TokenBased{TokenBased: &paginationV1.TokenBasedPagination{PageSize: 3}},
+	})
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	m = res.Meta
+	if m.GetNextToken() == "" || m.GetNextToken() != res.NextToken || m.PrevToken != nil {
+		t.Fatalf("unexpected token meta: %v", m)
+	}
+	if m.GetPageSize() != 3 || m.GetCurrentSize() != 3 || m.GetTotal().GetValue() != 5 {
+		t.Fatalf("unexpected size meta: %v", m)
+	}
+}
+
+func TestRepository_ListWithPagination_TokenFieldMask(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&testUserEntity{}); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	for i, name := range []string{"a", "b", "c", "d", "e"} {
+		seedUsers(t, db, testUserEntity{Name: name, Age: 50 - i})
+	}
+
+	ctx := context.Background()
+	q := NewRepository[testUserEntity, testUserEntity](mapper.NewCopierMapper[testUserEntity, testUserEntity]())
+
+	// 字段掩码未包含排序列与 tiebreaker 时，游标仍需正确前进，额外选择的列不返回给调用方
+	var names []string
+	var token string
+	for page := 0; page < 5; page++ {
+		res, err := q.ListWithPaging(ctx, db, &paginationV1.PagingRequest{
+			Token:     &token,
+			Offset:    trans.Ptr(uint64(2)),
+			OrderBy:   trans.Ptr("age"),
+			FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+		})
+		if err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+		for _, item := range res.Items {
+			if item.ID != 0 || item.Age != 0 {
+				t.Fatalf("unexpected unrequested columns: %+v", item)
+			}
+			names = append(names, item.Name)
+		}
+		if token = res.NextToken; token == "" {
+			break
+		}
+	}
+	if strings.Join(names, ",") != "e,d,c,b,a" {
+		t.Fatalf("unexpected paged names: %v", names)
+	}
+
+	names = names[:0]
+	token = ""
+	for page := 0; page < 5; page++ {
+		res, err := q.ListWithPagination(ctx, db, &paginationV1.PaginationRequest{
+			PaginationType: &paginationV1.PaginationRequest_TokenBased{TokenBased: &paginationV1.TokenBasedPagination{Token: token, PageSize: 2}},
+			FieldMask:      &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+		})
+		if err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+		for _, item := range res.Items {
+			names = append(names, item.Name)
+		}
+		if token = res.NextToken; token == "" {
+			break
+		}
+	}
+	if strings.Join(names, ",") != "a,b,c,d,e" {
+		t.Fatalf("unexpected paged names: %v", names)
+	}
+}
+
+func TestRepository_ListWithPagination_CountStrategy(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&testUserEntity{}); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		seedUsers(t, db, testUserEntity{Name: name})
+	}
+
+	q := NewRepository[testUserEntity, testUserEntity](mapper.NewCopierMapper[testUserEntity, testUserEntity]())
+	list := func(strategy pagination.CountStrategy) *PagingResult[testUserEntity] {
+		ctx := pagination.WithCountStrategy(context.Background(), strategy)
+		res, err := q.ListWithPagination(ctx, db, &paginationV1.PaginationRequest{
+			PaginationType: &paginationV1.PaginationRequest_PageBased{PageBased: &paginationV1.PageBasedPagination{Page: 1, PageSize: 2}},
+		})
+		if err != nil {
+			t.Fatalf("list failed: %v", err)
+		}
+		return res
+	}
+
+	// 不统计总数
+	res := list(pagination.CountStrategy{Mode: pagination.CountModeSkip})
+	if len(res.Items) != 2 || res.CountMode != pagination.CountModeSkip || res.Meta.Total != nil || res.Meta.TotalPages != nil {
+		t.Fatalf("unexpected skip result: %+v", res)
+	}
+
+	// 限量统计：超过上限
+	res = list(pagination.CountStrategy{Mode: pagination.CountModeCapped, Cap: 3})
+	if res.Total != 3 || !res.IsEstimate || res.CountMode != pagination.CountModeCapped {
+		t.Fatalf("unexpected capped result: %+v", res)
+	}
+
+	// 限量统计：未超过上限
+	res = list(pagination.CountStrategy{Mode: pagination.CountModeCapped, Cap: 10})
+	if res.Total != 5 || res.IsEstimate {
+		t.Fatalf("unexpected capped result: %+v", res)
+	}
+
+	// sqlite 不支持估算，退化为精确统计
+	res = list(pagination.CountStrategy{Mode: pagination.CountModeEstimated})
+	if res.Total != 5 || res.IsEstimate || res.CountMode != pagination.CountModeExact {
+		t.Fatalf("unexpected estimated result: %+v", res)
+	}
+}
+
+func TestRepository_CountEstimated(t *testing.T) {
+	open := func(dialector gorm.Dialector) (*gorm.DB, *[]string) {
+		db, err := gorm.Open(dialector, &gorm.Config{
+			DryRun:                 true,
+			DisableAutomaticPing:   true,
+			SkipDefaultTransaction: true,
+			Logger:                 logger.Default.LogMode(logger.Silent),
+		})
+		if err != nil {
+			t.Fatalf("open db: %v", err)
+		}
+		if err = db.Use(NewTenantPlugin()); err != nil {
+			t.Fatalf("use plugin: %v", err)
+		}
+		// 记录执行的统计语句
+		var raws []string
+		_ = db.Callback().Row().After("gorm:row").Register("test:record", func(db *gorm.DB) {
+			raws = append(raws, db.Statement.SQL.String())
+		})
+		return db, &raws
+	}
+
+	q := NewRepository[tenantTestOrder, tenantTestOrder](mapper.NewCopierMapper[tenantTestOrder, tenantTestOrder]())
+	tenantCtx := viewer.WithContext(context.Background(), tenantViewer{Context: viewer.NewNoopContext(), tenantID: 1})
+	systemCtx := viewer.WithContext(context.Background(), tenantViewer{Context: viewer.NewNoopContext(), system: true})
+
+	// 租户插件追加的条件也视为过滤条件，MySQL 不读取全表统计
+	mdb, raws := open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}))
+	if _, ok, err := q.CountEstimated(tenantCtx, mdb, nil); err != nil || ok || len(*raws) != 0 {
+		t.Fatalf("filtered mysql estimate should fall back: %v, %v, %v", ok, err, *raws)
+	}
+	// DryRun 下统计语句只构造不执行，仅检查构造的语句
+	_, _, _ = q.CountEstimated(systemCtx, mdb, nil)
+	if len(*raws) != 1 || !strings.Contains((*raws)[0], "information_schema.TABLES") {
+		t.Fatalf("unfiltered mysql estimate should read table stats: %v", *raws)
+	}
+
+	// PostgreSQL 的 EXPLAIN 使用占位符传参
+	pdb, raws := open(postgres.New(postgres.Config{DSN: "host=127.0.0.1"}))
+	byName := func(db *gorm.DB) *gorm.DB { return db.Where("name = ?", "secret") }
+	_, _, _ = q.CountEstimated(systemCtx, pdb, []func(*gorm.DB) *gorm.DB{byName})
+	if len(*raws) != 1 {
+		t.Fatalf("postgres estimate should explain the query: %v", *raws)
+	}
+	if sql := (*raws)[0]; !strings.HasPrefix(sql, "EXPLAIN (FORMAT JSON) SELECT") || !strings.Contains(sql, "$1") || strings.Contains(sql, "secret") {
+		t.Fatalf("unexpected explain sql: %s", sql)
+	}
+}
+
+func TestRepository_ListWithPagination_FilterPolicy(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&testUserEntity{}); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	seedUsers(t, db, testUserEntity{Name: "a", Age: 10}, testUserEntity{Name: "b", Age: 20}, testUserEntity{Name: "c", Age: 30})
+
+	q := NewRepository[testUserEntity, testUserEntity](mapper.NewCopierMapper[testUserEntity, testUserEntity]()).
+		WithFilterPolicy(paginationFilter.NewFilterPolicy().
+			Allow("id").
+			Field("userName", paginationFilter.FieldRule{Column: "name", Operators: []paginationV1.Operator{paginationV1.Operator_EQ, paginationV1.Operator_IN}}))
+
+	ctx := context.Background()
+	cond := func(field string, op paginationV1.Operator, value string) *paginationV1.PaginationRequest {
+		return &paginationV1.PaginationRequest{
+			FilteringType: &paginationV1.PaginationRequest_FilterExpr{FilterExpr: &paginationV1.FilterExpr{
+				Type:       paginationV1.ExprType_AND,
+				Conditions: []*paginationV1.FilterCondition{{Field: field, Op: op, ValueOneof: &paginationV1.FilterCondition_Value{Value: value}}},
+			}},
+		}
+	}
+
+	// 外部字段名映射为列名
+	res, err := q.ListWithPagination(ctx, db, cond("userName", paginationV1.Operator_EQ, "b"))
+	if err != nil || len(res.Items) != 1 || res.Items[0].Name != "b" {
+		t.Fatalf("list failed: %+v, %v", res, err)
+	}
+
+	// 未登记的字段、未允许的操作符
+	for _, req := range []*paginationV1.PaginationRequest{
+		cond("age", paginationV1.Operator_GT, "10"),
+		cond("userName", paginationV1.Operator_CONTAINS, "b"),
+	} {
+		if _, err = q.ListWithPagination(ctx, db, req); !errors.Is(err, paginationFilter.ErrPolicyViolation) {
+			t.Fatalf("expected ErrPolicyViolation, got %v", err)
+		}
+	}
+
+	// 排序字段同样受策略约束
+	res, err = q.ListWithPagination(ctx, db, &paginationV1.PaginationRequest{
+		Sorting: []*paginationV1.Sorting{{Field: "userName", Direction: paginationV1.Sorting_DESC}},
+	})
+	if err != nil || len(res.Items) != 3 || res.Items[0].Name != "c" {
+		t.Fatalf("sorted list failed: %+v, %v", res, err)
+	}
+	if _, err = q.ListWithPagination(ctx, db, &paginationV1.PaginationRequest{
+		Sorting: []*paginationV1.Sorting{{Field: "age"}},
+	}); !errors.Is(err, paginationFilter.ErrPolicyViolation) {
+		t.Fatalf("expected ErrPolicyViolation, got %v", err)
+	}
+}
+
+type dataScopeTestDoc struct {
+	ID        uint `gorm:"primarykey"`
+	Title     string
+	CreatedBy uint64
+	OrgUnitID uint64
+}
+
+// scopeViewer 指定数据权限范围的 Viewer
+type scopeViewer struct {
+	viewer.Context
+	uid    uint64
+	scopes []viewer.DataScope
+}
+
+func (v scopeViewer) UserID() uint64                { return v.uid }
+func (v scopeViewer) DataScope() []viewer.DataScope { return v.scopes }
+
+func TestRepository_DataScope(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&dataScopeTestDoc{}); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	db.Create(&[]dataScopeTestDoc{
+		{Title: "a", CreatedBy: 1, OrgUnitID: 10},
+		{Title: "b", CreatedBy: 2, OrgUnitID: 10},
+		{Title: "c", CreatedBy: 3, OrgUnitID: 20},
+	})
+
+	// 数据权限条件不受字段策略约束
+	q := NewRepository[dataScopeTestDoc, dataScopeTestDoc](mapper.NewCopierMapper[dataScopeTestDoc, dataScopeTestDoc]()).
+		WithFilterPolicy(paginationFilter.NewFilterPolicy().Allow("title")).
+		WithDataScope(datascope.NewPolicy())
+
+	ctxOf := func(uid uint64, scopes ...viewer.DataScope) context.Context {
+		return viewer.WithContext(context.Background(), scopeViewer{Context: viewer.NewNoopContext(), uid: uid, scopes: scopes})
+	}
+	self := ctxOf(1, viewer.DataScope{ScopeType: viewer.ScopeTypeSelf})
+	unit := ctxOf(3, viewer.DataScope{ScopeType: viewer.ScopeTypeSelf}, viewer.DataScope{ScopeType: viewer.ScopeTypeUnit, TargetIDs: []uint64{10}})
+
+	res, err := q.ListWithPagination(self, db, &paginationV1.PaginationRequest{})
+	if err != nil || len(res.Items) != 1 || res.Items[0].Title != "a" || res.Total != 1 {
+		t.Fatalf("self list failed: %+v, %v", res, err)
+	}
+	res, err = q.ListWithPagination(unit, db, &paginationV1.PaginationRequest{})
+	if err != nil || len(res.Items) != 3 || res.Total != 3 {
+		t.Fatalf("unit list failed: %+v, %v", res, err)
+	}
+
+	if cnt, err := q.Count(self, db, nil); err != nil || cnt != 1 {
+		t.Fatalf("self count failed: %d, %v", cnt, err)
+	}
+	if _, err = q.Get(self, db.Where("title = ?", "b"), nil); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected ErrRecordNotFound, got %v", err)
+	}
+	if ok, err := q.Exists(self, db.Where("title = ?", "c")); err != nil || ok {
+		t.Fatalf("expected not exists: %v, %v", ok, err)
+	}
+
+	// 越权的更新与删除不生效
+	if n, err := q.UpdateX(self, db.Where("title = ?", "b"), &dataScopeTestDoc{Title: "x"}, nil); err != nil || n != 0 {
+		t.Fatalf("out of scope update should affect nothing: %d, %v", n, err)
+	}
+	if n, err := q.Delete(self, db.Where("title = ?", "c"), true); err != nil || n != 0 {
+		t.Fatalf("out of scope delete should affect nothing: %d, %v", n, err)
+	}
+	if n, err := q.Delete(unit, db.Where("title = ?", "b"), true); err != nil || n != 1 {
+		t.Fatalf("in scope delete should affect 1 row: %d, %v", n, err)
+	}
+
+	// Upsert 的冲突更新同样受数据权限约束
+	if _, err = q.Upsert(self, db, &dataScopeTestDoc{ID: 3, Title: "hijack", CreatedBy: 1}, nil); !errors.Is(err, datascope.ErrAccessDenied) {
+		t.Fatalf("out of scope upsert should be denied, got %v", err)
+	}
+	var doc dataScopeTestDoc
+	if db.First(&doc, 3); doc.Title != "c" || doc.CreatedBy != 3 {
+		t.Fatalf("row overwritten by out of scope upsert: %+v", doc)
+	}
+	if _, err = q.Upsert(self, db, &dataScopeTestDoc{ID: 1, Title: "a2", CreatedBy: 1}, nil); err != nil {
+		t.Fatalf("in scope upsert: %v", err)
+	}
+	var upserted dataScopeTestDoc
+	if err = db.First(&upserted, 1).Error; err != nil || upserted.Title != "a2" {
+		t.Fatalf("in scope upsert not applied: %+v, %v", upserted, err)
+	}
+	if _, err = q.Upsert(self, db, &dataScopeTestDoc{ID: 4, Title: "d", CreatedBy: 1}, nil); err != nil {
+		t.Fatalf("upsert insert: %v", err)
+	}
+
+	// 缺少 Viewer 或显式禁止时拒绝访问
+	if _, err = q.ListWithPagination(context.Background(), db, &paginationV1.PaginationRequest{}); !errors.Is(err, datascope.ErrMissingViewer) {
+		t.Fatalf("expected ErrMissingViewer, got %v", err)
+	}
+	if _, err = q.Count(ctxOf(1, viewer.DataScope{ScopeType: viewer.ScopeTypeNone}), db, nil); !errors.Is(err, datascope.ErrAccessDenied) {
+		t.Fatalf("expected ErrAccessDenied, got %v", err)
+	}
+}
+
+func TestRepositoryAdapter(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&testUserEntity{}); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+
+	ctx := context.Background()
+	var repo crud.Repository[testUserEntity] = NewRepositoryAdapter(
+		NewRepository[testUserEntity, testUserEntity](mapper.NewCopierMapper[testUserEntity, testUserEntity]()), db,
+	)
+
+	for _, u := range []testUserEntity{{Name: "alice", Age: 20}, {Name: "bob", Age: 30}, {Name: "carol", Age: 40}} {
+		if _, err = repo.Create(ctx, &u, nil); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+
+	byName := func(name string) *paginationV1.FilterExpr {
+		return &paginationV1.FilterExpr{
+			Type:       paginationV1.ExprType_AND,
+			Conditions: []*paginationV1.FilterCondition{{Field: "name", Op: paginationV1.Operator_EQ, ValueOneof: &paginationV1.FilterCondition_Value{Value: name}}},
+		}
+	}
+
+	cnt, err := repo.Count(ctx, nil)
+	if err != nil || cnt != 3 {
+		t.Fatalf("count: %d, %v", cnt, err)
+	}
+
+	got, err := repo.Get(ctx, byName("bob"), nil)
+	if err != nil || got.Age != 30 {
+		t.Fatalf("get: %+v, %v", got, err)
+	}
+	if _, err = repo.Get(ctx, byName("nobody"), nil); !errors.Is(err, crud.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	updated, err := repo.Update(ctx, byName("bob"), &testUserEntity{Age: 31}, &fieldmaskpb.FieldMask{Paths: []string{"age"}})
+	if err != nil || updated.Age != 31 || updated.Name != "bob" {
+		t.Fatalf("update: %+v, %v", updated, err)
+	}
+
+	if _, err = repo.Upsert(ctx, &testUserEntity{ID: got.ID, Name: "bob", Age: 50}, nil); err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+	if got, _ = repo.Get(ctx, byName("bob"), nil); got == nil || got.Age != 50 {
+		t.Fatalf("upsert did not update: %+v", got)
+	}
+
+	if _, err = repo.Delete(ctx, nil); !errors.Is(err, crud.ErrEmptyFilter) {
+		t.Fatalf("expected ErrEmptyFilter, got %v", err)
+	}
+
+	// 未指定组合类型的表达式在编译时被跳过，不能据此更新、删除全表
+	untyped := byName("alice")
+	untyped.Type = paginationV1.ExprType_EXPR_TYPE_UNSPECIFIED
+	if _, err = repo.Delete(ctx, untyped); !errors.Is(err, crud.ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter, got %v", err)
+	}
+	if _, err = repo.Update(ctx, untyped, &testUserEntity{Age: 1}, &fieldmaskpb.FieldMask{Paths: []string{"age"}}); !errors.Is(err, crud.ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter, got %v", err)
+	}
+	nested := &paginationV1.FilterExpr{Type: paginationV1.ExprType_AND, Groups: []*paginationV1.FilterExpr{untyped}}
+	if _, err = repo.Delete(ctx, nested); !errors.Is(err, crud.ErrInvalidFilter) {
+		t.Fatalf("expected ErrInvalidFilter for nested group, got %v", err)
+	}
+	if cnt, _ = repo.Count(ctx, nil); cnt != 3 {
+		t.Fatalf("untyped filter should not touch any row, count: %d", cnt)
+	}
+
+	rows, err := repo.Delete(ctx, byName("alice"))
+	if err != nil || rows != 1 {
+		t.Fatalf("delete: %d, %v", rows, err)
+	}
+	if ok, _ := repo.Exists(ctx, byName("alice")); ok {
+		t.Fatal("alice should be deleted")
+	}
+
+	res, err := repo.List(ctx, &paginationV1.PaginationRequest{})
+	if err != nil || len(res.Items) != 2 || res.Total != 2 {
+		t.Fatalf("list: %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,127 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tx7do/go-crud/pagination/softdelete"
+)
+
+// ErrSoftDeleteNotSupported 实体不包含删除时间字段，无法恢复或清理
+var ErrSoftDeleteNotSupported = errors.New("soft delete not supported: deleted_at field not found on entity")
+
+// WithSoftDeleteColumns 设置 Restore 与 Purge 使用的删除时间、删除者列名，为空时使用 softdelete 的默认列名；
+// 需与 SoftDeletePlugin 的配置保持一致。
+func (r *Repository[DTO, ENTITY]) WithSoftDeleteColumns(deletedAt, deletedBy string) *Repository[DTO, ENTITY] {
+	r.deletedAtColumn = deletedAt
+	r.deletedByColumn = deletedBy
+	return r
+}
+
+// softDeleteColumns 返回实体实际的删除时间、删除者列名，实体不包含删除者字段时 deletedBy 为空
+func (r *Repository[DTO, ENTITY]) softDeleteColumns(db *gorm.DB) (deletedAt, deletedBy string, err error) {
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(new(ENTITY)); err != nil {
+		return "", "", err
+	}
+
+	atName, byName := r.deletedAtColumn, r.deletedByColumn
+	if atName == "" {
+		atName = softdelete.DefaultDeletedAtColumn
+	}
+	if byName == "" {
+		byName = softdelete.DefaultDeletedByColumn
+	}
+
+	atField := stmt.Schema.LookUpField(atName)
+	if atField == nil {
+		return "", "", ErrSoftDeleteNotSupported
+	}
+	if byField := stmt.Schema.LookUpField(byName); byField != nil {
+		deletedBy = byField.DBName
+	}
+	return atField.DBName, deletedBy, nil
+}
+
+// Restore 恢复符合条件的已软删除记录：将删除时间与删除者置为 NULL，返回受影响的行数
+func (r *Repository[DTO, ENTITY]) Restore(ctx context.Context, db *gorm.DB, whereSelectors []func(*gorm.DB) *gorm.DB) (int64, error) {
+	if db == nil {
+		return 0, errors.New("db is nil")
+	}
+
+	deletedAt, deletedBy, err := r.softDeleteColumns(db)
+	if err != nil {
+		return 0, err
+	}
+
+	qdb := withTx(softdelete.OnlyDeleted(ctx), db).Model(new(ENTITY))
+	qdb, err = r.applyDataScope(ctx, qdb)
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range whereSelectors {
+		if s != nil {
+			qdb = s(qdb)
+		}
+	}
+
+	values := map[string]any{deletedAt: nil}
+	if deletedBy != "" {
+		values[deletedBy] = nil
+	}
+
+	// 显式追加已删除条件并跳过插件与 GORM 内置的软删除过滤
+	res := qdb.Unscoped().
+		Clauses(clause.Where{Exprs: []clause.Expression{
+			clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: deletedAt}, Value: nil},
+		}}).
+		Updates(values)
+	if res.Error != nil {
+		log.Errorf("restore failed: %s", res.Error.Error())
+		return 0, fmt.Errorf("restore failed: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// Purge 物理删除符合条件、且删除时间早于 olderThan 之前的已软删除记录，olderThan <= 0 时清理全部已软删除记录；
+// 返回受影响的行数
+func (r *Repository[DTO, ENTITY]) Purge(ctx context.Context, db *gorm.DB, olderThan time.Duration, whereSelectors []func(*gorm.DB) *gorm.DB) (int64, error) {
+	if db == nil {
+		return 0, errors.New("db is nil")
+	}
+
+	deletedAt, _, err := r.softDeleteColumns(db)
+	if err != nil {
+		return 0, err
+	}
+
+	qdb := withTx(softdelete.HardDelete(softdelete.OnlyDeleted(ctx)), db).Model(new(ENTITY))
+	qdb, err = r.applyDataScope(ctx, qdb)
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range whereSelectors {
+		if s != nil {
+			qdb = s(qdb)
+		}
+	}
+
+	col := clause.Column{Table: clause.CurrentTable, Name: deletedAt}
+	res := qdb.Unscoped().
+		Clauses(clause.Where{Exprs: []clause.Expression{
+			clause.Neq{Column: col, Value: nil},
+			clause.Lt{Column: col, Value: softdelete.Cutoff(olderThan)},
+		}}).
+		Delete(new(ENTITY))
+	if res.Error != nil {
+		log.Errorf("purge failed: %s", res.Error.Error())
+		return 0, fmt.Errorf("purge failed: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
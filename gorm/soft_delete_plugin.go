@@ -0,0 +1,170 @@
+package gorm
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/tx7do/go-crud/pagination/softdelete"
+)
+
+// SoftDeletePluginOption SoftDeletePlugin 的选项
+type SoftDeletePluginOption func(p *SoftDeletePlugin)
+
+// WithDeletedAtColumn 设置删除时间的列名
+func WithDeletedAtColumn(column string) SoftDeletePluginOption {
+	return func(p *SoftDeletePlugin) {
+		if column != "" {
+			p.deletedAtColumn = column
+		}
+	}
+}
+
+// WithDeletedByColumn 设置删除者的列名
+func WithDeletedByColumn(column string) SoftDeletePluginOption {
+	return func(p *SoftDeletePlugin) {
+		if column != "" {
+			p.deletedByColumn = column
+		}
+	}
+}
+
+// SoftDeletePlugin 软删除插件，对包含删除时间字段（如嵌入 mixin.SoftDelete / mixin.DeletedAt）的模型：
+//   - 查询、更新、删除按 ctx 中的 softdelete.Mode 过滤，默认排除已软删除的记录；
+//   - 删除转换为 UPDATE ... SET deleted_at = now()，存在删除者字段时同时写入 ctx 中 Viewer 的用户 ID；
+//   - Unscoped 或 softdelete.HardDelete 的 ctx 执行物理删除，Unscoped 同时跳过查询过滤；
+//   - 删除时间字段为 gorm.DeletedAt 的模型由 GORM 内置的软删除处理，本插件不做处理。
+type SoftDeletePlugin struct {
+	deletedAtColumn string
+	deletedByColumn string
+}
+
+var _ gorm.Plugin = (*SoftDeletePlugin)(nil)
+
+// NewSoftDeletePlugin 创建软删除插件，通过 db.Use 或 Client.Use(SoftDeleteMixin(...)) 注册
+func NewSoftDeletePlugin(opts ...SoftDeletePluginOption) *SoftDeletePlugin {
+	p := &SoftDeletePlugin{
+		deletedAtColumn: softdelete.DefaultDeletedAtColumn,
+		deletedByColumn: softdelete.DefaultDeletedByColumn,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+	return p
+}
+
+// SoftDeleteMixin 将软删除插件包装为 Mixin，供 Client.Use / WithMixin 使用
+func SoftDeleteMixin(opts ...SoftDeletePluginOption) Mixin {
+	return func(db *gorm.DB) error {
+		return db.Use(NewSoftDeletePlugin(opts...))
+	}
+}
+
+func (p *SoftDeletePlugin) Name() string {
+	return "crud:soft_delete"
+}
+
+func (p *SoftDeletePlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Query().Before("gorm:query").Register("crud:soft_delete:query", p.filterDeleted); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("crud:soft_delete:row", p.filterDeleted); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("crud:soft_delete:update", p.filterDeleted); err != nil {
+		return err
+	}
+	// 在租户插件之后执行，保证改写为 UPDATE 时已包含租户条件
+	return cb.Delete().Before("gorm:delete").After("crud:tenant:delete").Register("crud:soft_delete:delete", p.softDelete)
+}
+
+var gormDeletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+
+// deletedAtField 返回模型的删除时间字段，模型不包含该字段或使用 gorm.DeletedAt 时返回 nil
+func (p *SoftDeletePlugin) deletedAtField(db *gorm.DB) *schema.Field {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	f := db.Statement.Schema.LookUpField(p.deletedAtColumn)
+	if f == nil || f.FieldType == gormDeletedAtType {
+		return nil
+	}
+	return f
+}
+
+// filterDeleted 按 ctx 中的软删除模式追加过滤条件
+func (p *SoftDeletePlugin) filterDeleted(db *gorm.DB) {
+	field := p.deletedAtField(db)
+	if field == nil || db.Statement.Unscoped {
+		return
+	}
+	p.addModeClause(db, field)
+}
+
+func (p *SoftDeletePlugin) addModeClause(db *gorm.DB, field *schema.Field) {
+	col := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+	switch softdelete.ModeFromContext(db.Statement.Context) {
+	case softdelete.ModeWithDeleted:
+		return
+	case softdelete.ModeOnlyDeleted:
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Neq{Column: col, Value: nil}}})
+	default:
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Eq{Column: col, Value: nil}}})
+	}
+}
+
+// softDelete 将删除改写为更新删除时间与删除者，物理删除时只追加过滤条件
+func (p *SoftDeletePlugin) softDelete(db *gorm.DB) {
+	field := p.deletedAtField(db)
+	if field == nil || db.Statement.Unscoped || db.Statement.SQL.Len() > 0 {
+		return
+	}
+
+	stmt := db.Statement
+	if softdelete.IsHardDelete(stmt.Context) {
+		p.addModeClause(db, field)
+		return
+	}
+
+	// 与 gorm:delete 一致，按 Dest/Model 中的主键追加条件
+	if stmt.Schema != nil {
+		_, queryValues := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		column, values := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+		if len(values) > 0 {
+			stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+		}
+
+		if stmt.ReflectValue.CanAddr() && stmt.Dest != stmt.Model && stmt.Model != nil {
+			_, queryValues = schema.GetIdentityFieldValuesMap(stmt.Context, reflect.ValueOf(stmt.Model), stmt.Schema.PrimaryFields)
+			column, values = schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, queryValues)
+			if len(values) > 0 {
+				stmt.AddClause(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: values}}})
+			}
+		}
+	}
+
+	// 软删除条件会使 WHERE 非空，需先检查是否缺少删除条件
+	if _, ok := stmt.Clauses["WHERE"]; !ok && !db.AllowGlobalUpdate {
+		_ = db.AddError(gorm.ErrMissingWhereClause)
+		return
+	}
+
+	set := clause.Set{{Column: clause.Column{Name: field.DBName}, Value: db.NowFunc()}}
+	if byField := stmt.Schema.LookUpField(p.deletedByColumn); byField != nil {
+		if uid, ok := softdelete.DeletedBy(stmt.Context); ok {
+			set = append(set, clause.Assignment{Column: clause.Column{Name: byField.DBName}, Value: uid})
+		}
+	}
+	stmt.AddClause(set)
+
+	p.addModeClause(db, field)
+
+	stmt.AddClauseIfNotExists(clause.Update{})
+	stmt.Build(db.Callback().Update().Clauses...)
+}
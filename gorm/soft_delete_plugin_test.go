@@ -0,0 +1,135 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/tx7do/go-utils/mapper"
+
+	"github.com/tx7do/go-crud/gorm/mixin"
+	"github.com/tx7do/go-crud/pagination/softdelete"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+type softDeleteTestDoc struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+	mixin.SoftDelete
+}
+
+// deleterViewer 指定用户 ID 的 Viewer
+type deleterViewer struct {
+	viewer.Context
+	uid uint64
+}
+
+func (v deleterViewer) UserID() uint64 { return v.uid }
+
+func TestSoftDeletePlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&softDeleteTestDoc{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err = db.Use(NewSoftDeletePlugin()); err != nil {
+		t.Fatalf("use plugin: %v", err)
+	}
+
+	ctx := viewer.WithContext(context.Background(), deleterViewer{Context: viewer.NewNoopContext(), uid: 7})
+	tx := db.WithContext(ctx)
+	docs := []softDeleteTestDoc{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	if err = tx.Create(&docs).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	countOf := func(ctx context.Context) int64 {
+		var n int64
+		if err := db.WithContext(ctx).Model(&softDeleteTestDoc{}).Count(&n).Error; err != nil {
+			t.Fatalf("count: %v", err)
+		}
+		return n
+	}
+
+	// 删除转换为更新删除时间与删除者
+	if res := tx.Where("name = ?", "a").Delete(&softDeleteTestDoc{}); res.Error != nil || res.RowsAffected != 1 {
+		t.Fatalf("soft delete: %v, %d", res.Error, res.RowsAffected)
+	}
+	if res := tx.Delete(&docs[1]); res.Error != nil || res.RowsAffected != 1 {
+		t.Fatalf("soft delete by primary key: %v, %d", res.Error, res.RowsAffected)
+	}
+	// 已删除的记录不会被再次删除或更新
+	if res := tx.Where("name = ?", "a").Delete(&softDeleteTestDoc{}); res.RowsAffected != 0 {
+		t.Fatalf("deleted row should not be deleted again, got %d", res.RowsAffected)
+	}
+	if res := tx.Model(&softDeleteTestDoc{}).Where("name = ?", "a").Update("name", "x"); res.RowsAffected != 0 {
+		t.Fatalf("deleted row should not be updated, got %d", res.RowsAffected)
+	}
+	if err = tx.Delete(&softDeleteTestDoc{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Fatalf("expected ErrMissingWhereClause, got %v", err)
+	}
+
+	var deleted softDeleteTestDoc
+	if err = db.WithContext(softdelete.OnlyDeleted(ctx)).Where("name = ?", "a").First(&deleted).Error; err != nil {
+		t.Fatalf("query deleted: %v", err)
+	}
+	if deleted.DeletedAt.DeletedAt == nil || deleted.DeletedBy.DeletedBy == nil || *deleted.DeletedBy.DeletedBy != 7 {
+		t.Fatalf("deleted_at/deleted_by not stamped: %+v", deleted)
+	}
+
+	if n := countOf(ctx); n != 1 {
+		t.Fatalf("default mode should hide deleted rows, got %d", n)
+	}
+	if n := countOf(softdelete.WithDeleted(ctx)); n != 3 {
+		t.Fatalf("with deleted should see all rows, got %d", n)
+	}
+	if n := countOf(softdelete.OnlyDeleted(ctx)); n != 2 {
+		t.Fatalf("only deleted should see 2 rows, got %d", n)
+	}
+
+	repo := NewRepository[softDeleteTestDoc, softDeleteTestDoc](mapper.NewCopierMapper[softDeleteTestDoc, softDeleteTestDoc]())
+
+	// 恢复清空删除时间与删除者
+	if n, err := repo.Restore(ctx, db.Where("name = ?", "a"), nil); err != nil || n != 1 {
+		t.Fatalf("restore: %d, %v", n, err)
+	}
+	var restored softDeleteTestDoc
+	if err = tx.Where("name = ?", "a").First(&restored).Error; err != nil || restored.DeletedBy.DeletedBy != nil {
+		t.Fatalf("restored row: %+v, %v", restored, err)
+	}
+
+	// 清理只删除早于截止时间的已删除记录
+	if n, err := repo.Purge(ctx, db, time.Hour, nil); err != nil || n != 0 {
+		t.Fatalf("purge recent: %d, %v", n, err)
+	}
+	if n, err := repo.Purge(ctx, db, 0, nil); err != nil || n != 1 {
+		t.Fatalf("purge: %d, %v", n, err)
+	}
+	if n := countOf(softdelete.WithDeleted(ctx)); n != 2 {
+		t.Fatalf("purged row should be removed, got %d", n)
+	}
+
+	// Unscoped 执行物理删除
+	if res := tx.Unscoped().Where("name = ?", "c").Delete(&softDeleteTestDoc{}); res.RowsAffected != 1 {
+		t.Fatalf("hard delete: %v, %d", res.Error, res.RowsAffected)
+	}
+	if n := countOf(softdelete.WithDeleted(ctx)); n != 1 {
+		t.Fatalf("hard deleted row should be removed, got %d", n)
+	}
+
+	type noSoftDelete struct {
+		ID uint `gorm:"primarykey"`
+	}
+	if _, err = NewRepository[noSoftDelete, noSoftDelete](mapper.NewCopierMapper[noSoftDelete, noSoftDelete]()).Restore(ctx, db, nil); !errors.Is(err, ErrSoftDeleteNotSupported) {
+		t.Fatalf("expected ErrSoftDeleteNotSupported, got %v", err)
+	}
+}
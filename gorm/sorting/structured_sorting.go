@@ -7,19 +7,47 @@ import (
 	"gorm.io/gorm"
 
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
 )
 
 // StructuredSorting 用于把结构化的排序指令转换为 GORM 的 order scope
-type StructuredSorting struct{}
+type StructuredSorting struct {
+	policy  *paginationFilter.FilterPolicy
+	mapping *fieldmap.Mapping
+}
 
 // NewStructuredSorting 创建实例
 func NewStructuredSorting() *StructuredSorting {
 	return &StructuredSorting{}
 }
 
-// BuildScope 根据 orders 构建 GORM scope（可与 db.Scopes 一起使用）
+// WithPolicy 设置字段策略，排序字段需通过策略校验，字段按策略映射为列名
+func (ss *StructuredSorting) WithPolicy(policy *paginationFilter.FilterPolicy) *StructuredSorting {
+	ss.policy = policy
+	return ss
+}
+
+// WithFieldMapping 设置字段映射，排序字段按映射替换为列名
+func (ss *StructuredSorting) WithFieldMapping(mapping *fieldmap.Mapping) *StructuredSorting {
+	ss.mapping = mapping
+	return ss
+}
+
+// Validate 按字段策略校验排序字段，返回按策略与字段映射替换为列名后的排序；未设置策略与映射时原样返回
+func (ss StructuredSorting) Validate(orders []*paginationV1.Sorting) ([]*paginationV1.Sorting, error) {
+	orders, err := ss.policy.CheckSorting(orders)
+	return ss.mapping.Sorting(orders), err
+}
+
+// BuildScope 根据 orders 构建 GORM scope（可与 db.Scopes 一起使用），违反字段策略时向 db 添加错误
 func (ss StructuredSorting) BuildScope(orders []*paginationV1.Sorting) func(*gorm.DB) *gorm.DB {
+	orders, policyErr := ss.Validate(orders)
 	return func(db *gorm.DB) *gorm.DB {
+		if policyErr != nil {
+			_ = db.AddError(policyErr)
+			return db
+		}
 		if len(orders) == 0 {
 			return db
 		}
@@ -0,0 +1,279 @@
+package gorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	crud "github.com/tx7do/go-crud"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+// DefaultTenantColumn 租户字段的默认列名，与 mixin.TenantID 一致
+const DefaultTenantColumn = "tenant_id"
+
+// ErrMissingViewer context 中缺少 ViewerContext，租户插件拒绝访问
+var ErrMissingViewer = errors.New("security: missing ViewerContext in context")
+
+// TenantPluginOption TenantPlugin 的选项
+type TenantPluginOption func(p *TenantPlugin)
+
+// WithTenantColumn 设置租户字段的列名
+func WithTenantColumn(column string) TenantPluginOption {
+	return func(p *TenantPlugin) {
+		if column != "" {
+			p.column = column
+		}
+	}
+}
+
+// TenantPlugin 基于 viewer.Context 的多租户隔离插件，对包含租户字段（如嵌入 mixin.TenantID）的模型：
+//   - 查询、更新、删除自动追加 tenant_id = ? 条件；
+//   - 创建时强制写入当前租户，平台视图下若已显式设置租户则保留；
+//   - 冲突更新（Upsert）只更新当前租户的记录且不改写租户字段，不支持 ON CONFLICT ... WHERE 的数据库（如 MySQL）拒绝执行；
+//   - 平台视图与系统视图跳过过滤；context 中缺少 Viewer 时拒绝访问（返回 ErrMissingViewer）。
+type TenantPlugin struct {
+	column string
+}
+
+var _ gorm.Plugin = (*TenantPlugin)(nil)
+
+// NewTenantPlugin 创建租户插件，通过 db.Use 或 Client.Use(TenantMixin(...)) 注册
+func NewTenantPlugin(opts ...TenantPluginOption) *TenantPlugin {
+	p := &TenantPlugin{column: DefaultTenantColumn}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+	return p
+}
+
+// TenantMixin 将租户插件包装为 Mixin，供 Client.Use / WithMixin 使用
+func TenantMixin(opts ...TenantPluginOption) Mixin {
+	return func(db *gorm.DB) error {
+		return db.Use(NewTenantPlugin(opts...))
+	}
+}
+
+func (p *TenantPlugin) Name() string {
+	return "crud:tenant"
+}
+
+func (p *TenantPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("crud:tenant:create", p.stampTenant); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("crud:tenant:query", p.filterTenant); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("crud:tenant:row", p.filterTenant); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("crud:tenant:update", p.filterTenant); err != nil {
+		return err
+	}
+	return cb.Delete().Before("gorm:delete").Register("crud:tenant:delete", p.filterTenant)
+}
+
+// tenantField 返回模型的租户字段，模型不包含租户字段时返回 nil
+func (p *TenantPlugin) tenantField(db *gorm.DB) *schema.Field {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField(p.column)
+}
+
+// viewer 读取 Viewer；缺失时记录错误，返回 ok=false
+func (p *TenantPlugin) viewer(db *gorm.DB) (viewer.Context, bool) {
+	if db.Statement.Context == nil {
+		_ = db.AddError(ErrMissingViewer)
+		return nil, false
+	}
+	vc, exist := viewer.FromContext(db.Statement.Context)
+	if !exist || vc == nil {
+		_ = db.AddError(ErrMissingViewer)
+		return nil, false
+	}
+	return vc, true
+}
+
+// filterTenant 为查询、更新、删除追加租户过滤条件
+func (p *TenantPlugin) filterTenant(db *gorm.DB) {
+	field := p.tenantField(db)
+	if field == nil {
+		return
+	}
+
+	vc, ok := p.viewer(db)
+	if !ok {
+		return
+	}
+
+	// 平台管理视图/系统视图放行：允许操作全量数据
+	if vc.IsPlatformContext() || vc.IsSystemContext() {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: vc.TenantID()},
+	}})
+}
+
+// stampTenant 创建时写入当前租户
+func (p *TenantPlugin) stampTenant(db *gorm.DB) {
+	field := p.tenantField(db)
+	if field == nil {
+		return
+	}
+
+	vc, ok := p.viewer(db)
+	if !ok || vc.IsSystemContext() {
+		return
+	}
+
+	tid := vc.TenantID()
+	platform := vc.IsPlatformContext()
+
+	if !platform {
+		p.guardUpsert(db, field, tid)
+		if db.Error != nil {
+			return
+		}
+	}
+
+	stamp := func(rv reflect.Value) {
+		rv = reflect.Indirect(rv)
+		if !rv.IsValid() || rv.Kind() != reflect.Struct {
+			return
+		}
+		// 平台视图下尊重显式设置的租户
+		if platform {
+			if _, isZero := field.ValueOf(db.Statement.Context, rv); !isZero {
+				return
+			}
+		}
+		if err := field.Set(db.Statement.Context, rv, tid); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+
+	switch dest := db.Statement.Dest.(type) {
+	case map[string]any:
+		p.stampMap(dest, field.DBName, tid, platform)
+		return
+	case *map[string]any:
+		p.stampMap(*dest, field.DBName, tid, platform)
+		return
+	case []map[string]any:
+		for _, m := range dest {
+			p.stampMap(m, field.DBName, tid, platform)
+		}
+		return
+	case *[]map[string]any:
+		for _, m := range *dest {
+			p.stampMap(m, field.DBName, tid, platform)
+		}
+		return
+	}
+
+	rv := reflect.Indirect(db.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Struct:
+		stamp(rv)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			stamp(rv.Index(i))
+		}
+	}
+}
+
+func (p *TenantPlugin) stampMap(m map[string]any, column string, tid uint64, platform bool) {
+	if platform {
+		if v, ok := m[column]; ok && v != nil {
+			return
+		}
+	}
+	m[column] = tid
+}
+
+// guardUpsert 将冲突更新限定在当前租户的记录上：追加 tenant_id = ? 条件，并且不更新租户字段，
+// 避免携带其他租户记录主键的 Upsert 覆盖该记录
+func (p *TenantPlugin) guardUpsert(db *gorm.DB, field *schema.Field, tid uint64) {
+	c, ok := db.Statement.Clauses["ON CONFLICT"]
+	if !ok {
+		return
+	}
+	onConflict, ok := c.Expression.(clause.OnConflict)
+	if !ok || onConflict.DoNothing {
+		return
+	}
+	if !supportsConflictWhere(db) {
+		_ = db.AddError(fmt.Errorf("tenant: upsert on %s: %w", db.Dialector.Name(), crud.ErrNotSupported))
+		return
+	}
+
+	// UpdateAll 在 gorm:create 中才展开为更新列，此处提前展开以便排除租户字段
+	if onConflict.UpdateAll {
+		onConflict.UpdateAll = false
+		onConflict.DoUpdates = append(onConflict.DoUpdates, clause.AssignmentColumns(updateAllColumns(db.Statement))...)
+	}
+	onConflict.DoUpdates = slices.DeleteFunc(onConflict.DoUpdates, func(a clause.Assignment) bool {
+		return a.Column.Name == field.DBName
+	})
+	if len(onConflict.Columns) == 0 && onConflict.OnConstraint == "" {
+		for _, pf := range db.Statement.Schema.PrimaryFields {
+			onConflict.Columns = append(onConflict.Columns, clause.Column{Name: pf.DBName})
+		}
+	}
+
+	if len(onConflict.DoUpdates) == 0 {
+		onConflict.DoNothing = true
+		onConflict.Where = clause.Where{}
+	} else {
+		onConflict.Where.Exprs = append(slices.Clone(onConflict.Where.Exprs),
+			clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tid},
+		)
+	}
+	db.Statement.AddClause(onConflict)
+}
+
+// updateAllColumns 按 gorm 展开 OnConflict.UpdateAll 的规则返回冲突时更新的列：
+// 排除主键、仅在创建时写入的字段以及由数据库生成默认值的字段，并遵循 Select/Omit
+func updateAllColumns(stmt *gorm.Statement) []string {
+	selectColumns, restricted := stmt.SelectAndOmitColumns(true, true)
+
+	var columns []string
+	for _, name := range stmt.Schema.DBNames {
+		f := stmt.Schema.FieldsByDBName[name]
+		if f == nil || !f.Creatable || f.PrimaryKey || f.AutoCreateTime > 0 {
+			continue
+		}
+		if f.HasDefaultValue && f.DefaultValueInterface == nil && !strings.EqualFold(f.DefaultValue, "NULL") {
+			continue
+		}
+		if v, ok := selectColumns[name]; (ok && v) || (!ok && !restricted) {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+// supportsConflictWhere 数据库是否支持 ON CONFLICT ... DO UPDATE ... WHERE；
+// MySQL 的 ON DUPLICATE KEY UPDATE 与 SQL Server 的 MERGE 会忽略该条件
+func supportsConflictWhere(db *gorm.DB) bool {
+	switch db.Dialector.Name() {
+	case "postgres", "sqlite":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,198 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+
+	crud "github.com/tx7do/go-crud"
+	"github.com/tx7do/go-crud/gorm/mixin"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+type tenantTestOrder struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+	mixin.TenantID
+}
+
+type tenantTestGlobal struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+}
+
+// tenantViewer 指定租户与视图类型的 Viewer
+type tenantViewer struct {
+	viewer.Context
+	tenantID uint64
+	platform bool
+	system   bool
+}
+
+func (v tenantViewer) TenantID() uint64        { return v.tenantID }
+func (v tenantViewer) IsPlatformContext() bool { return v.platform }
+func (v tenantViewer) IsSystemContext() bool   { return v.system }
+
+func TestTenantPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&tenantTestOrder{}, &tenantTestGlobal{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err = db.Use(NewTenantPlugin()); err != nil {
+		t.Fatalf("use plugin: %v", err)
+	}
+
+	ctxOf := func(v tenantViewer) context.Context {
+		v.Context = viewer.NewNoopContext()
+		return viewer.WithContext(context.Background(), v)
+	}
+	tenant1 := db.WithContext(ctxOf(tenantViewer{tenantID: 1}))
+	tenant2 := db.WithContext(ctxOf(tenantViewer{tenantID: 2}))
+	platform := db.WithContext(ctxOf(tenantViewer{platform: true}))
+	system := db.WithContext(ctxOf(tenantViewer{system: true}))
+
+	// 创建时强制写入当前租户，即使显式设置了其他租户
+	other := uint32(2)
+	if err = tenant1.Create(&[]tenantTestOrder{{Name: "a"}, {Name: "b", TenantID: mixin.TenantID{TenantID: &other}}}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err = tenant2.Create(&tenantTestOrder{Name: "c"}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// 平台视图保留显式设置的租户
+	three := uint32(3)
+	if err = platform.Create(&tenantTestOrder{Name: "d", TenantID: mixin.TenantID{TenantID: &three}}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var orders []tenantTestOrder
+	tenant1.Find(&orders)
+	if len(orders) != 2 {
+		t.Fatalf("tenant 1 should see 2 orders, got %d", len(orders))
+	}
+	for _, o := range orders {
+		if o.TenantID.TenantID == nil || *o.TenantID.TenantID != 1 {
+			t.Fatalf("unexpected tenant: %+v", o)
+		}
+	}
+
+	var count int64
+	platform.Model(&tenantTestOrder{}).Count(&count)
+	if count != 4 {
+		t.Fatalf("platform should see all orders, got %d", count)
+	}
+	system.Model(&tenantTestOrder{}).Count(&count)
+	if count != 4 {
+		t.Fatalf("system should see all orders, got %d", count)
+	}
+
+	// 跨租户的更新与删除不生效
+	if res := tenant2.Model(&tenantTestOrder{}).Where("name = ?", "a").Update("name", "x"); res.Error != nil || res.RowsAffected != 0 {
+		t.Fatalf("cross-tenant update should affect nothing: %v, %d", res.Error, res.RowsAffected)
+	}
+	if res := tenant2.Where("name = ?", "a").Delete(&tenantTestOrder{}); res.Error != nil || res.RowsAffected != 0 {
+		t.Fatalf("cross-tenant delete should affect nothing: %v, %d", res.Error, res.RowsAffected)
+	}
+	if res := tenant1.Where("name = ?", "a").Delete(&tenantTestOrder{}); res.RowsAffected != 1 {
+		t.Fatalf("own delete should affect 1 row, got %d", res.RowsAffected)
+	}
+
+	// 缺少 Viewer 时拒绝访问
+	if err = db.Find(&orders).Error; !errors.Is(err, ErrMissingViewer) {
+		t.Fatalf("expected ErrMissingViewer, got %v", err)
+	}
+	if err = db.Create(&tenantTestOrder{Name: "e"}).Error; !errors.Is(err, ErrMissingViewer) {
+		t.Fatalf("expected ErrMissingViewer on create, got %v", err)
+	}
+
+	// 不含租户字段的模型不受影响
+	if err = db.Create(&tenantTestGlobal{Name: "g"}).Error; err != nil {
+		t.Fatalf("create global: %v", err)
+	}
+	var globals []tenantTestGlobal
+	if err = db.Find(&globals).Error; err != nil || len(globals) != 1 {
+		t.Fatalf("query global: %v, %d", err, len(globals))
+	}
+}
+
+func TestTenantPluginUpsert(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err = db.AutoMigrate(&tenantTestOrder{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err = db.Use(NewTenantPlugin()); err != nil {
+		t.Fatalf("use plugin: %v", err)
+	}
+
+	ctxOf := func(v tenantViewer) context.Context {
+		v.Context = viewer.NewNoopContext()
+		return viewer.WithContext(context.Background(), v)
+	}
+	tenant1 := db.WithContext(ctxOf(tenantViewer{tenantID: 1}))
+	tenant2 := db.WithContext(ctxOf(tenantViewer{tenantID: 2}))
+
+	order := tenantTestOrder{Name: "a"}
+	if err = tenant1.Create(&order).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// 携带其他租户记录主键的 Upsert 不覆盖该记录
+	for _, onConflict := range []clause.OnConflict{
+		{UpdateAll: true},
+		{DoUpdates: clause.AssignmentColumns([]string{"name", "tenant_id"})},
+	} {
+		res := tenant2.Clauses(onConflict).Create(&tenantTestOrder{ID: order.ID, Name: "hijack"})
+		if res.Error != nil || res.RowsAffected != 0 {
+			t.Fatalf("cross-tenant upsert should affect nothing: %v, %d", res.Error, res.RowsAffected)
+		}
+	}
+	var got tenantTestOrder
+	if err = tenant1.First(&got, order.ID).Error; err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Name != "a" || got.TenantID.TenantID == nil || *got.TenantID.TenantID != 1 {
+		t.Fatalf("row overwritten by other tenant: %+v", got)
+	}
+
+	// 本租户的 Upsert 正常更新
+	if res := tenant1.Clauses(clause.OnConflict{UpdateAll: true}).Create(&tenantTestOrder{ID: order.ID, Name: "b"}); res.Error != nil || res.RowsAffected != 1 {
+		t.Fatalf("own upsert: %v, %d", res.Error, res.RowsAffected)
+	}
+	if err = tenant1.First(&got, order.ID).Error; err != nil || got.Name != "b" {
+		t.Fatalf("own upsert not applied: %+v, %v", got, err)
+	}
+
+	// 不支持 ON CONFLICT ... WHERE 的数据库拒绝 Upsert
+	mdb, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open mysql: %v", err)
+	}
+	if err = mdb.Use(NewTenantPlugin()); err != nil {
+		t.Fatalf("use plugin: %v", err)
+	}
+	err = mdb.WithContext(ctxOf(tenantViewer{tenantID: 2})).Clauses(clause.OnConflict{UpdateAll: true}).Create(&tenantTestOrder{ID: order.ID}).Error
+	if !errors.Is(err, crud.ErrNotSupported) {
+		t.Fatalf("expected ErrNotSupported on mysql, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package gorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/tx7do/go-crud/pagination/transaction"
+)
+
+type txKey struct{}
+
+// RunInTx 在事务中执行 fn：事务保存在传给 fn 的 ctx 中，Repository 的方法会自动使用该事务，无需逐个传入 *gorm.DB。
+// 嵌套调用使用保存点（SAVEPOINT），fn 返回错误时只回滚到保存点；
+// 最外层事务遇到可重试的错误（序列化失败、死锁）时整体重试，opts 可设置隔离级别、只读与重试策略。
+func RunInTx(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error, opts ...transaction.Option) error {
+	if fn == nil {
+		return errors.New("transaction func is nil")
+	}
+
+	// 嵌套事务：使用保存点
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+	}
+
+	if db == nil {
+		return errors.New("db is nil")
+	}
+
+	o := transaction.NewOptions(opts...)
+	var txOpts []*sql.TxOptions
+	if txo := o.TxOptions(); txo != nil {
+		txOpts = append(txOpts, txo)
+	}
+
+	return o.Retry(ctx, func() error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		}, txOpts...)
+	})
+}
+
+// TxFromContext 返回 ctx 中由 RunInTx 开启的事务
+func TxFromContext(ctx context.Context) (*gorm.DB, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// withTx 返回绑定 ctx 的 db；ctx 中存在事务时保留 db 上的条件，改用事务的连接执行
+func withTx(ctx context.Context, db *gorm.DB) *gorm.DB {
+	db = db.WithContext(ctx)
+	if tx, ok := TxFromContext(ctx); ok {
+		db.Statement.ConnPool = tx.Statement.ConnPool
+	}
+	return db
+}
@@ -0,0 +1,135 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/tx7do/go-utils/mapper"
+
+	"github.com/tx7do/go-crud/pagination/transaction"
+)
+
+type txTestDoc struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+}
+
+// retryableError 模拟序列化失败
+type retryableError struct{}
+
+func (retryableError) Error() string    { return "could not serialize access" }
+func (retryableError) SQLState() string { return "40001" }
+
+func TestRunInTx(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:tx_test?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err = db.AutoMigrate(&txTestDoc{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	ctx := context.Background()
+	repo := NewRepository[txTestDoc, txTestDoc](mapper.NewCopierMapper[txTestDoc, txTestDoc]())
+	countOf := func() int64 {
+		var n int64
+		if err := db.Model(&txTestDoc{}).Count(&n).Error; err != nil {
+			t.Fatalf("count: %v", err)
+		}
+		return n
+	}
+
+	// 仓库方法自动使用 ctx 中的事务，出错时整体回滚
+	errBoom := errors.New("boom")
+	err = RunInTx(ctx, db, func(ctx context.Context) error {
+		if _, err := repo.Create(ctx, db, &txTestDoc{Name: "a"}, nil); err != nil {
+			return err
+		}
+		if n, err := repo.Count(ctx, db, nil); err != nil || n != 1 {
+			t.Fatalf("count inside tx: %d, %v", n, err)
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) || countOf() != 0 {
+		t.Fatalf("rollback: %v, %d", err, countOf())
+	}
+
+	// 嵌套事务使用保存点，内层失败只回滚内层
+	err = RunInTx(ctx, db, func(ctx context.Context) error {
+		if _, err := repo.Create(ctx, db, &txTestDoc{Name: "outer"}, nil); err != nil {
+			return err
+		}
+		innerErr := RunInTx(ctx, db, func(ctx context.Context) error {
+			if _, err := repo.Create(ctx, db, &txTestDoc{Name: "inner"}, nil); err != nil {
+				return err
+			}
+			return errBoom
+		})
+		if !errors.Is(innerErr, errBoom) {
+			t.Fatalf("inner: %v", innerErr)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("outer: %v", err)
+	}
+	var names []string
+	db.Model(&txTestDoc{}).Pluck("name", &names)
+	if len(names) != 1 || names[0] != "outer" {
+		t.Fatalf("savepoint rollback: %v", names)
+	}
+
+	// 可重试的错误整体重试
+	attempts := 0
+	err = RunInTx(ctx, db, func(ctx context.Context) error {
+		attempts++
+		if _, err := repo.Create(ctx, db, &txTestDoc{Name: "retry"}, nil); err != nil {
+			return err
+		}
+		if attempts < 2 {
+			return retryableError{}
+		}
+		return nil
+	}, transaction.WithRetryBackoff(0))
+	if err != nil || attempts != 2 || countOf() != 2 {
+		t.Fatalf("retry: %v, %d, %d", err, attempts, countOf())
+	}
+
+	attempts = 0
+	err = RunInTx(ctx, db, func(ctx context.Context) error {
+		attempts++
+		return retryableError{}
+	}, transaction.WithMaxRetries(0))
+	if err == nil || attempts != 1 {
+		t.Fatalf("no retry: %v, %d", err, attempts)
+	}
+
+	// 仓库方法内部返回的可重试错误同样触发重试
+	failNext := true
+	if err = db.Callback().Create().Before("gorm:create").Register("test:serialization_failure", func(tx *gorm.DB) {
+		if failNext {
+			failNext = false
+			_ = tx.AddError(retryableError{})
+		}
+	}); err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	attempts = 0
+	err = RunInTx(ctx, db, func(ctx context.Context) error {
+		attempts++
+		_, err := repo.Create(ctx, db, &txTestDoc{Name: "serialized"}, nil)
+		return err
+	}, transaction.WithRetryBackoff(0))
+	if err != nil || attempts != 2 || countOf() != 3 {
+		t.Fatalf("retry repository error: %v, %d, %d", err, attempts, countOf())
+	}
+}
@@ -0,0 +1,126 @@
+package influxdb
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/influxdb/query"
+)
+
+var _ crud.Repository[struct{}] = (*RepositoryAdapter[struct{}, struct{}])(nil)
+
+// RepositoryAdapter 将 InfluxDB Repository 适配为通用的 crud.Repository 接口。
+// 时序数据不支持按条件更新、删除；写入需要通过 WithPointMapper 设置 Point 转换器，
+// 写入相同 measurement、tag 与时间戳的 Point 会覆盖原值，因此 Upsert 等同于 Create。
+type RepositoryAdapter[DTO any, ENTITY any] struct {
+	repo *Repository[DTO, ENTITY]
+}
+
+// NewRepositoryAdapter 创建 InfluxDB 仓库适配器
+func NewRepositoryAdapter[DTO any, ENTITY any](repo *Repository[DTO, ENTITY]) *RepositoryAdapter[DTO, ENTITY] {
+	return &RepositoryAdapter[DTO, ENTITY]{
+		repo: repo,
+	}
+}
+
+// newQuery 将 FilterExpr 编译为查询构建器
+func (a *RepositoryAdapter[DTO, ENTITY]) newQuery(filter *paginationV1.FilterExpr) (*query.Builder, error) {
+	if a.repo.client == nil {
+		return nil, errors.New("influxdb database is nil")
+	}
+	if a.repo.collection == "" {
+		return nil, errors.New("collection is empty")
+	}
+
+	qb := query.NewQueryBuilder(a.repo.collection)
+	if filter == nil {
+		return qb, nil
+	}
+	if _, err := a.repo.structuredFilter.BuildSelectors(qb, filter); err != nil {
+		return nil, err
+	}
+	return qb, nil
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) List(ctx context.Context, req *paginationV1.PaginationRequest) (*crud.PagingResult[DTO], error) {
+	return a.repo.ListWithPagination(ctx, req)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Get(ctx context.Context, filter *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	qb, err := a.newQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(viewMask.GetPaths()) > 0 {
+		if _, err = a.repo.fieldSelector.BuildSelector(qb, viewMask.GetPaths()); err != nil {
+			a.repo.log.Errorf("field selector build error: %v", err)
+		}
+	}
+	qb.Limit(1)
+
+	entities, err := a.repo.query(ctx, qb.Build())
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 {
+		return nil, crud.ErrNotFound
+	}
+	return a.repo.mapper.ToDTO(entities[0]), nil
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Create(ctx context.Context, dto *DTO, _ *fieldmaskpb.FieldMask) (*DTO, error) {
+	if a.repo.pointMapper == nil {
+		return nil, crud.ErrNotSupported
+	}
+	if a.repo.client == nil {
+		return nil, errors.New("influxdb database is nil")
+	}
+	if dto == nil {
+		return nil, errors.New("dto is nil")
+	}
+
+	ent := a.repo.mapper.ToEntity(dto)
+	if err := Insert(ctx, a.repo.client, ent, a.repo.pointMapper); err != nil {
+		a.repo.log.Errorf("insert failed: %v", err)
+		return nil, err
+	}
+	return a.repo.mapper.ToDTO(ent), nil
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Update(_ context.Context, filter *paginationV1.FilterExpr, _ *DTO, _ *fieldmaskpb.FieldMask) (*DTO, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return nil, err
+	}
+	return nil, crud.ErrNotSupported
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Upsert(ctx context.Context, dto *DTO, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	return a.Create(ctx, dto, viewMask)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Delete(_ context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return 0, err
+	}
+	return 0, crud.ErrNotSupported
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Count(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	qb, err := a.newQuery(filter)
+	if err != nil {
+		return 0, err
+	}
+	return a.repo.client.Count(ctx, qb.BuildCount())
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Exists(ctx context.Context, filter *paginationV1.FilterExpr) (bool, error) {
+	qb, err := a.newQuery(filter)
+	if err != nil {
+		return false, err
+	}
+	return a.repo.client.Exist(ctx, qb.Limit(1).Build())
+}
@@ -0,0 +1,33 @@
+package influxdb
+
+import (
+	"context"
+
+	"github.com/tx7do/go-crud/influxdb/query"
+	"github.com/tx7do/go-crud/pagination"
+)
+
+// countByStrategy 按 context 中的统计策略计算列表总数。
+// InfluxDB 没有可用的行数统计信息，CountModeEstimated 退化为精确统计。
+func (r *Repository[DTO, ENTITY]) countByStrategy(ctx context.Context, qb *query.Builder) (pagination.Count, error) {
+	strategy := pagination.CountStrategyFromContext(ctx)
+
+	switch strategy.Mode {
+	case pagination.CountModeSkip:
+		return pagination.SkippedCount(), nil
+
+	case pagination.CountModeCapped:
+		limit := strategy.Limit()
+		n, err := r.client.Count(ctx, qb.BuildCappedCount(limit))
+		if err != nil {
+			return pagination.Count{}, err
+		}
+		return pagination.CappedCount(n, limit), nil
+	}
+
+	total, err := r.client.Count(ctx, qb.BuildCount())
+	if err != nil {
+		return pagination.Count{}, err
+	}
+	return pagination.ExactCount(total), nil
+}
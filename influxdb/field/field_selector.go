@@ -5,17 +5,26 @@ import (
 	"strings"
 
 	"github.com/tx7do/go-crud/influxdb/query"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
 	"github.com/tx7do/go-utils/stringcase"
 )
 
 var fieldNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
 
 // Selector 用于构建 InfluxDB 查询中的 SELECT 列表
-type Selector struct{}
+type Selector struct {
+	mapping *fieldmap.Mapping
+}
 
 // NewFieldSelector 返回一个新的 Selector。
 func NewFieldSelector() *Selector { return &Selector{} }
 
+// WithFieldMapping 设置字段映射，字段路径按映射替换为列名。
+func (fs *Selector) WithFieldMapping(mapping *fieldmap.Mapping) *Selector {
+	fs.mapping = mapping
+	return fs
+}
+
 // BuildSelector 为给定的 builder 构建 SELECT 列表并设置到 builder 中。
 // 当 fields 为空或无有效字段时返回原 builder 和 nil 错误。
 // 支持 "*" 表示全选（会调用 builder.Select(nil)）。
@@ -27,7 +36,7 @@ func (fs Selector) BuildSelector(builder *query.Builder, fields []string) (*quer
 		return builder, nil
 	}
 
-	fields = NormalizePaths(fields)
+	fields = NormalizePaths(fs.mapping.Paths(fields))
 	if len(fields) == 0 {
 		return builder, nil
 	}
@@ -7,12 +7,16 @@ import (
 
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
 	"github.com/tx7do/go-crud/influxdb/query"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
 )
 
 // StructuredFilter 将 FilterExpr 转为基于 InfluxDB 的 查询条件，使用 Processor 在 *query.Builder 上追加 WHERE 子句
 type StructuredFilter struct {
 	codec     encoding.Codec
 	processor *Processor
+	policy    *paginationFilter.FilterPolicy
+	mapping   *fieldmap.Mapping
 }
 
 // NewStructuredFilter 创建 InfluxDB 用的 StructuredFilter
@@ -23,6 +27,18 @@ func NewStructuredFilter() *StructuredFilter {
 	}
 }
 
+// WithPolicy 设置字段策略，过滤条件需通过策略校验，字段按策略映射为列名
+func (sf *StructuredFilter) WithPolicy(policy *paginationFilter.FilterPolicy) *StructuredFilter {
+	sf.policy = policy
+	return sf
+}
+
+// WithFieldMapping 设置字段映射，条件字段按映射替换为列名
+func (sf *StructuredFilter) WithFieldMapping(mapping *fieldmap.Mapping) *StructuredFilter {
+	sf.mapping = mapping
+	return sf
+}
+
 // BuildSelectors 将 expr 的条件应用到 builder 上；若 builder 为 nil 则新建一个。
 // AND 类型会把所有子条件逐一通过 Processor.Process 添加（AND 语义）。
 // OR 类型仅在组内只有单个条件或单个子组时处理该单项，复杂 OR 跳过（query.Builder 不支持复杂 OR）。
@@ -34,6 +50,12 @@ func (sf StructuredFilter) BuildSelectors(builder *query.Builder, expr *paginati
 		return builder, nil
 	}
 
+	expr, err := sf.policy.CheckFilter(expr)
+	if err != nil {
+		return builder, err
+	}
+	expr = sf.mapping.Filter(expr)
+
 	// helper: 处理单个 Condition，返回是否成功处理（用于判断 OR 单项）
 	processCond := func(b *query.Builder, cond *paginationV1.FilterCondition) bool {
 		if cond == nil {
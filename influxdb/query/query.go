@@ -319,6 +319,36 @@ func (qb *Builder) Build() string {
 	return sb.String()
 }
 
+// BuildCount 生成与当前条件对应的 COUNT 查询（忽略字段选择、排序与分页）
+func (qb *Builder) BuildCount() string {
+	sb := strings.Builder{}
+	sb.WriteString("SELECT COUNT(*) FROM ")
+	sb.WriteString(qb.table)
+
+	if len(qb.where) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(qb.where, " AND "))
+	}
+
+	return sb.String()
+}
+
+// BuildCappedCount 生成最多统计到 limit+1 条的 COUNT 查询（忽略字段选择、排序与分页）
+func (qb *Builder) BuildCappedCount(limit int64) string {
+	sb := strings.Builder{}
+	sb.WriteString("SELECT COUNT(*) FROM (SELECT * FROM ")
+	sb.WriteString(qb.table)
+
+	if len(qb.where) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(qb.where, " AND "))
+	}
+
+	sb.WriteString(fmt.Sprintf(" LIMIT %d)", limit+1))
+
+	return sb.String()
+}
+
 // BuildQueryWithParams 兼容现有 client.go 的调用签名
 func BuildQueryWithParams(
 	table string,
@@ -57,6 +57,31 @@ func TestBuilder_GroupOrderLimitOffset(t *testing.T) {
 	}
 }
 
+func TestBuilder_BuildCount(t *testing.T) {
+	q := NewQueryBuilder("metrics").
+		Select([]string{"value"}).
+		WhereFromRaw("host = 'server1'").
+		OrderBy("time", true).
+		Limit(10).
+		Offset(5).
+		BuildCount()
+	want := "SELECT COUNT(*) FROM metrics WHERE host = 'server1'"
+	if q != want {
+		t.Fatalf("got %q, want %q", q, want)
+	}
+}
+
+func TestBuilder_BuildCappedCount(t *testing.T) {
+	q := NewQueryBuilder("metrics").
+		WhereFromRaw("host = 'server1'").
+		Limit(10).
+		BuildCappedCount(100)
+	want := "SELECT COUNT(*) FROM (SELECT * FROM metrics WHERE host = 'server1' LIMIT 101)"
+	if q != want {
+		t.Fatalf("got %q, want %q", q, want)
+	}
+}
+
 func TestBuildQueryWithParams_Helper(t *testing.T) {
 	filters := map[string]interface{}{
 		"a": 1,
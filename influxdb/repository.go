@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/tx7do/go-utils/mapper"
 
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
 
@@ -15,12 +16,21 @@ import (
 	"github.com/tx7do/go-crud/influxdb/query"
 	"github.com/tx7do/go-crud/influxdb/sorting"
 
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
 	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
+	"github.com/tx7do/go-crud/pagination/paginator"
 	paginationSorting "github.com/tx7do/go-crud/pagination/sorting"
 )
 
-// Repository MongoDB 版仓库（泛型）
+// PagingResult 通用分页返回（含完整的分页元数据），见 pagination.PagingResult
+type PagingResult[E any] = pagination.PagingResult[E]
+
+// Repository InfluxDB 版仓库（泛型）
 type Repository[DTO any, ENTITY any] struct {
+	mapper      *mapper.CopierMapper[DTO, ENTITY]
+	pointMapper Mapper[ENTITY]
+
 	offsetPaginator *paging.OffsetPaginator
 	pagePaginator   *paging.PagePaginator
 	tokenPaginator  *paging.TokenPaginator
@@ -37,12 +47,13 @@ type Repository[DTO any, ENTITY any] struct {
 	log        *log.Helper
 }
 
-func NewRepository[DTO any, ENTITY any](client *Client, collection string, logger *log.Helper) *Repository[DTO, ENTITY] {
+func NewRepository[DTO any, ENTITY any](client *Client, collection string, mapper *mapper.CopierMapper[DTO, ENTITY], logger *log.Helper) *Repository[DTO, ENTITY] {
 	return &Repository[DTO, ENTITY]{
 		client:     client,
 		collection: collection,
 
-		log: logger,
+		mapper: mapper,
+		log:    logger,
 
 		structuredSorting: sorting.NewStructuredSorting(),
 
@@ -58,13 +69,34 @@ func NewRepository[DTO any, ENTITY any](client *Client, collection string, logge
 	}
 }
 
+// WithPointMapper 设置 Point 与 ENTITY 之间的转换器；未设置时通过 PointToStruct 按 influx tag 反射解码
+func (r *Repository[DTO, ENTITY]) WithPointMapper(pointMapper Mapper[ENTITY]) *Repository[DTO, ENTITY] {
+	r.pointMapper = pointMapper
+	return r
+}
+
+// WithFilterPolicy 设置过滤与排序的字段策略（白名单、列名映射、操作符限制等），违反策略的查询返回 paginationFilter.ErrPolicyViolation
+func (r *Repository[DTO, ENTITY]) WithFilterPolicy(policy *paginationFilter.FilterPolicy) *Repository[DTO, ENTITY] {
+	r.structuredFilter.WithPolicy(policy)
+	r.structuredSorting.WithPolicy(policy)
+	return r
+}
+
+// WithFieldMapping 设置 API 字段名到列名的映射，过滤、排序与字段选择均按映射解析列名
+func (r *Repository[DTO, ENTITY]) WithFieldMapping(mapping *fieldmap.Mapping) *Repository[DTO, ENTITY] {
+	r.structuredFilter.WithFieldMapping(mapping)
+	r.structuredSorting.WithFieldMapping(mapping)
+	r.fieldSelector.WithFieldMapping(mapping)
+	return r
+}
+
 // ListWithPaging 针对 paginationV1.PagingRequest 的列表查询（兼容 Query/OrQuery/FilterExpr）
-func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, req *paginationV1.PagingRequest) ([]*DTO, int64, error) {
+func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, req *paginationV1.PagingRequest) (*PagingResult[DTO], error) {
 	if r.client == nil {
-		return nil, 0, errors.New("influxdb database is nil")
+		return nil, errors.New("influxdb database is nil")
 	}
 	if r.collection == "" {
-		return nil, 0, errors.New("collection is empty")
+		return nil, errors.New("collection is empty")
 	}
 
 	qb := query.NewQueryBuilder(r.collection)
@@ -76,12 +108,12 @@ func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, req *pagin
 	filterExpr, err = paginationFilter.ConvertFilterByPagingRequest(req)
 	if err != nil {
 		log.Errorf("convert filter string to filter expr failed: %s", err.Error())
-		return nil, 0, err
+		return nil, err
 	}
 	req.FilteringType = &paginationV1.PagingRequest_FilterExpr{FilterExpr: filterExpr}
 
 	if _, err = r.structuredFilter.BuildSelectors(qb, req.GetFilterExpr()); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
 	// select fields
@@ -92,45 +124,65 @@ func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, req *pagin
 	}
 
 	// sorting
+	var sortings []*paginationV1.Sorting
 	if len(req.GetSorting()) > 0 {
-		_ = r.structuredSorting.BuildOrderClause(qb, req.GetSorting())
+		sortings = req.GetSorting()
 	} else if len(req.GetOrderBy()) > 0 {
-		var sortings []*paginationV1.Sorting
 		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
 		if err != nil {
 			log.Errorf("convert order by string to sorting failed: %s", err.Error())
-			return nil, 0, err
+			return nil, err
 		}
+	}
+	if _, err = r.structuredSorting.Validate(sortings); err != nil {
+		log.Errorf("validate sorting failed: %s", err.Error())
+		return nil, err
+	}
+	if len(sortings) > 0 {
 		_ = r.structuredSorting.BuildOrderClause(qb, sortings)
 	}
 
 	// pagination
+	var pager pagination.Paginator
 	if !req.GetNoPaging() {
 		if req.Page != nil && req.PageSize != nil {
+			pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPage())).WithSize(int(req.GetPageSize()))
 			_ = r.pagePaginator.BuildClause(qb, int(req.GetPage()), int(req.GetPageSize()))
 		} else if req.Offset != nil && req.Limit != nil {
+			pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffset())).WithLimit(int(req.GetLimit()))
 			_ = r.offsetPaginator.BuildClause(qb, int(req.GetOffset()), int(req.GetLimit()))
 		} else if req.Token != nil && req.Offset != nil {
+			pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetToken()).WithSize(int(req.GetOffset()))
 			_ = r.tokenPaginator.BuildClause(qb, req.GetToken(), int(req.GetOffset()))
 		}
 	}
 
-	// 计数
-	total, err := r.client.Count(ctx, qb.Build())
+	// 计数（统计方式由 pagination.WithCountStrategy 指定）
+	count, err := r.countByStrategy(ctx, qb)
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := r.query(ctx, qb.Build())
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+
+	dtos := make([]*DTO, 0, len(entities))
+	for _, ent := range entities {
+		dtos = append(dtos, r.mapper.ToDTO(ent))
 	}
 
-	return nil, total, nil
+	return pagination.NewPagingResultWithCount(dtos, count, pager), nil
 }
 
 // ListWithPagination 针对 paginationV1.PaginationRequest 的列表查询
-func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, req *paginationV1.PaginationRequest) ([]*DTO, int64, error) {
+func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, req *paginationV1.PaginationRequest) (*PagingResult[DTO], error) {
 	if r.client == nil {
-		return nil, 0, errors.New("influxdb database is nil")
+		return nil, errors.New("influxdb database is nil")
 	}
 	if r.collection == "" {
-		return nil, 0, errors.New("collection is empty")
+		return nil, errors.New("collection is empty")
 	}
 
 	qb := query.NewQueryBuilder(r.collection)
@@ -142,12 +194,12 @@ func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, req *p
 	filterExpr, err = paginationFilter.ConvertFilterByPaginationRequest(req)
 	if err != nil {
 		log.Errorf("convert filter string to filter expr failed: %s", err.Error())
-		return nil, 0, err
+		return nil, err
 	}
 	req.FilteringType = &paginationV1.PaginationRequest_FilterExpr{FilterExpr: filterExpr}
 
 	if _, err = r.structuredFilter.BuildSelectors(qb, req.GetFilterExpr()); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
 	// select fields
@@ -158,35 +210,100 @@ func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, req *p
 	}
 
 	// sorting
+	var sortings []*paginationV1.Sorting
 	if len(req.GetSorting()) > 0 {
-		_ = r.structuredSorting.BuildOrderClause(qb, req.GetSorting())
+		sortings = req.GetSorting()
 	} else if len(req.GetOrderBy()) > 0 {
-		var sortings []*paginationV1.Sorting
 		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
 		if err != nil {
 			log.Errorf("convert order by string to sorting failed: %s", err.Error())
-			return nil, 0, err
+			return nil, err
 		}
+	}
+	if _, err = r.structuredSorting.Validate(sortings); err != nil {
+		log.Errorf("validate sorting failed: %s", err.Error())
+		return nil, err
+	}
+	if len(sortings) > 0 {
 		_ = r.structuredSorting.BuildOrderClause(qb, sortings)
 	}
 
 	// pagination
+	var pager pagination.Paginator
 	switch req.GetPaginationType().(type) {
 	case *paginationV1.PaginationRequest_OffsetBased:
+		pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffsetBased().GetOffset())).WithLimit(int(req.GetOffsetBased().GetLimit()))
 		_ = r.offsetPaginator.BuildClause(qb, int(req.GetOffsetBased().GetOffset()), int(req.GetOffsetBased().GetLimit()))
 	case *paginationV1.PaginationRequest_PageBased:
+		pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPageBased().GetPage())).WithSize(int(req.GetPageBased().GetPageSize()))
 		_ = r.pagePaginator.BuildClause(qb, int(req.GetPageBased().GetPage()), int(req.GetPageBased().GetPageSize()))
 	case *paginationV1.PaginationRequest_TokenBased:
+		pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetTokenBased().GetToken()).WithSize(int(req.GetTokenBased().GetPageSize()))
 		_ = r.tokenPaginator.BuildClause(qb, req.GetTokenBased().GetToken(), int(req.GetTokenBased().GetPageSize()))
 	}
 
-	// 计数
-	total, err := r.client.Count(ctx, qb.Build())
+	// 计数（统计方式由 pagination.WithCountStrategy 指定）
+	count, err := r.countByStrategy(ctx, qb)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+
+	entities, err := r.query(ctx, qb.Build())
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]*DTO, 0, len(entities))
+	for _, ent := range entities {
+		dtos = append(dtos, r.mapper.ToDTO(ent))
+	}
+
+	return pagination.NewPagingResultWithCount(dtos, count, pager), nil
+}
+
+// query 执行查询并将每一行解码为 ENTITY
+func (r *Repository[DTO, ENTITY]) query(ctx context.Context, aSql string) ([]*ENTITY, error) {
+	it, err := r.client.Query(ctx, aSql)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]*ENTITY, 0)
+	for it.Next() {
+		pv := it.AsPoints()
+		if pv.GetMeasurement() == "" {
+			pv.SetMeasurement(r.collection)
+		}
+
+		point, err := pv.AsPoint()
+		if err != nil {
+			r.log.Errorf("convert row to point failed: %v", err)
+			return nil, ErrInvalidPoint
+		}
+
+		var ent *ENTITY
+		if r.pointMapper != nil {
+			ent = r.pointMapper.ToData(point)
+		} else {
+			ent = new(ENTITY)
+			if err = PointToStruct(point, ent); err != nil {
+				r.log.Errorf("decode point failed: %v", err)
+				return nil, ErrInvalidPoint
+			}
+		}
+		if ent == nil {
+			continue
+		}
+
+		entities = append(entities, ent)
+	}
+
+	if err = it.Err(); err != nil {
+		r.log.Errorf("query iterator error: %v", err)
+		return nil, ErrInfluxDBQueryFailed
 	}
 
-	return nil, total, nil
+	return entities, nil
 }
 
 // Create 插入一条记录
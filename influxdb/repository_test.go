@@ -24,13 +24,13 @@ package influxdb
 //
 //	// 1. ListWithPaging: db 为 nil -> 错误
 //	repoNilDB := NewRepository[NoDeleted, NoDeleted](nil, "tmp", noDelMapper, logger)
-//	_, _, err := repoNilDB.ListWithPaging(ctx, &paginationV1.PagingRequest{})
+//	_, err := repoNilDB.ListWithPaging(ctx, &paginationV1.PagingRequest{})
 //	assert.Error(t, err)
 //	assert.Equal(t, "mongodb database is nil", err.Error())
 //
 //	// 2. ListWithPaging: collection 为空 -> 错误
 //	repoEmptyColl := NewRepository[NoDeleted, NoDeleted](&mongoV2.Database{}, "", noDelMapper, logger)
-//	_, _, err = repoEmptyColl.ListWithPaging(ctx, &paginationV1.PagingRequest{})
+//	_, err = repoEmptyColl.ListWithPaging(ctx, &paginationV1.PagingRequest{})
 //	assert.Error(t, err)
 //	assert.Equal(t, "collection is empty", err.Error())
 //
@@ -336,6 +336,126 @@ func StructToPoint(s interface{}) (*influxdb3.Point, error) {
 	return influxdb3.NewPoint(measurement, tags, fields, timestamp), nil
 }
 
+// PointToStruct 通用转换函数：StructToPoint 的逆过程，将 influxdb3.Point 按 influx tag 回填到 struct
+// 参数：point 查询得到的点；out 必须是 struct 指针
+// 规则与 StructToPoint 一致：tag/field 的键为字段名的 snake_case，time 对应点的时间戳，measurement 对应测量名。
+// 查询结果缺少列类型元数据时 tag 会以 field 的形式返回，因此 tag 也会尝试从 field 中读取。
+func PointToStruct(point *influxdb3.Point, out interface{}) error {
+	if point == nil || point.Values == nil {
+		return fmt.Errorf("point is nil")
+	}
+
+	val := reflect.ValueOf(out)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("输出必须是 struct 指针")
+	}
+	val = val.Elem()
+
+	typ := val.Type()
+	for i := 0; i < val.NumField(); i++ {
+		fieldVal := val.Field(i)
+		fieldTyp := typ.Field(i)
+		if !fieldVal.CanSet() {
+			continue
+		}
+
+		key := stringcase.ToSnakeCase(fieldTyp.Name)
+
+		switch fieldTyp.Tag.Get("influx") {
+		case "measurement":
+			if fieldVal.Kind() == reflect.String {
+				fieldVal.SetString(point.GetMeasurement())
+			}
+		case "tag":
+			if fieldVal.Kind() != reflect.String {
+				return fmt.Errorf("字段 %s 标记为 tag，但类型不是 string", fieldTyp.Name)
+			}
+			if v, ok := point.GetTag(key); ok {
+				fieldVal.SetString(v)
+			} else if v, ok := point.GetField(key).(string); ok {
+				fieldVal.SetString(v)
+			}
+		case "field":
+			v := point.GetField(key)
+			if v == nil {
+				continue
+			}
+			if err := setFieldValue(fieldVal, v); err != nil {
+				return fmt.Errorf("字段 %s: %w", fieldTyp.Name, err)
+			}
+		case "time":
+			if fieldVal.Type() != reflect.TypeOf(time.Time{}) {
+				return fmt.Errorf("字段 %s 标记为 time，但类型不是 time.Time", fieldTyp.Name)
+			}
+			fieldVal.Set(reflect.ValueOf(point.Values.Timestamp))
+		}
+	}
+
+	return nil
+}
+
+// setFieldValue 将查询得到的 field 值赋给 struct 字段，支持数值类型之间的转换；
+// time.Time 字段支持从纳秒时间戳（StructToPoint 的写入格式）还原。
+func setFieldValue(fieldVal reflect.Value, v any) error {
+	switch fieldVal.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := numericToInt64(v)
+		if !ok {
+			return fmt.Errorf("无法将 %T 转换为 %s", v, fieldVal.Kind())
+		}
+		fieldVal.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch t := v.(type) {
+		case uint64:
+			fieldVal.SetUint(t)
+		default:
+			n, ok := numericToInt64(v)
+			if !ok || n < 0 {
+				return fmt.Errorf("无法将 %T 转换为 %s", v, fieldVal.Kind())
+			}
+			fieldVal.SetUint(uint64(n))
+		}
+	case reflect.Float32, reflect.Float64:
+		switch t := v.(type) {
+		case float64:
+			fieldVal.SetFloat(t)
+		case float32:
+			fieldVal.SetFloat(float64(t))
+		default:
+			n, ok := numericToInt64(v)
+			if !ok {
+				return fmt.Errorf("无法将 %T 转换为 %s", v, fieldVal.Kind())
+			}
+			fieldVal.SetFloat(float64(n))
+		}
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return fmt.Errorf("无法将 %T 转换为 bool", v)
+		}
+		fieldVal.SetBool(b)
+	case reflect.String:
+		fieldVal.SetString(fmt.Sprintf("%v", v))
+	case reflect.Struct:
+		if fieldVal.Type() != reflect.TypeOf(time.Time{}) {
+			return fmt.Errorf("不支持 struct 类型 %s", fieldVal.Type())
+		}
+		switch t := v.(type) {
+		case time.Time:
+			fieldVal.Set(reflect.ValueOf(t))
+		default:
+			n, ok := numericToInt64(v)
+			if !ok {
+				return fmt.Errorf("无法将 %T 转换为 time.Time", v)
+			}
+			fieldVal.Set(reflect.ValueOf(time.Unix(0, n).UTC()))
+		}
+	default:
+		return fmt.Errorf("不支持类型 %s", fieldVal.Kind())
+	}
+	return nil
+}
+
 // ProtoMessageToPoint 将 protobuf message 转为 influxdb3.Point。
 // 参数 overrides 可选：map[key]=role，key 为 protobuf 字段的 JSON 名称（例如 "deviceId"），role 为 "measurement"/"tag"/"field"/"time"。
 // 约定识别规则（在无 overrides 时）：
@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/InfluxCommunity/influxdb3-go/v2/influxdb3"
 )
 
 func TestBuildQuery(t *testing.T) {
@@ -242,3 +244,81 @@ func TestStructToPoint_SensorData_MissingMeasurement(t *testing.T) {
 		t.Fatalf("expected error when measurement is empty")
 	}
 }
+
+func TestPointToStruct_SensorData_RoundTrip(t *testing.T) {
+	now := time.Now().UTC()
+	sd := &SensorData{
+		Measurement: "sensors",
+		DeviceID:    "dev-1",
+		Location:    "room1",
+		Temperature: 23.5,
+		Humidity:    55.2,
+		Battery:     95,
+		IsOnline:    true,
+		Timestamp:   now,
+	}
+
+	pt, err := StructToPoint(sd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got SensorData
+	if err = PointToStruct(pt, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(sd, &got) {
+		t.Fatalf("round trip mismatch:\n got: %+v\nwant: %+v", got, *sd)
+	}
+}
+
+func TestPointToStruct_QueryRow(t *testing.T) {
+	now := time.Now().UTC()
+
+	// 查询结果缺少列类型元数据时，tag 以 field 形式返回，数值可能为其它数值类型
+	pv := influxdb3.NewPointValues("sensors").
+		SetTimestamp(now).
+		SetField("device_id", "dev-2").
+		SetTag("location", "room2").
+		SetField("temperature", int64(20)).
+		SetField("battery", float64(80)).
+		SetField("is_online", false)
+	pt, err := pv.AsPoint()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got SensorData
+	if err = PointToStruct(pt, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := SensorData{
+		Measurement: "sensors",
+		DeviceID:    "dev-2",
+		Location:    "room2",
+		Temperature: 20,
+		Battery:     80,
+		Timestamp:   now,
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestPointToStruct_InvalidInput(t *testing.T) {
+	pt, _ := influxdb3.NewPointValues("sensors").AsPoint()
+
+	if err := PointToStruct(nil, &SensorData{}); err == nil {
+		t.Fatalf("expected error for nil point")
+	}
+	if err := PointToStruct(pt, SensorData{}); err == nil {
+		t.Fatalf("expected error for non-pointer output")
+	}
+
+	pt.SetField("is_online", "yes")
+	if err := PointToStruct(pt, &SensorData{}); err == nil {
+		t.Fatalf("expected error for mismatched field type")
+	}
+}
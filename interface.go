@@ -1 +1,128 @@
 package go_crud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination"
+)
+
+var (
+	// ErrNotFound 记录不存在
+	ErrNotFound = errors.New("record not found")
+
+	// ErrNotSupported 当前数据库不支持该操作
+	ErrNotSupported = errors.New("operation not supported by this backend")
+
+	// ErrEmptyFilter Update/Delete 未提供任何过滤条件
+	ErrEmptyFilter = errors.New("filter is empty")
+
+	// ErrInvalidFilter 过滤表达式无效：包含条件或子组却未指定组合类型
+	ErrInvalidFilter = errors.New("filter is invalid")
+
+	// ErrVersionConflict 乐观锁版本冲突：记录已被其他操作修改
+	ErrVersionConflict = errors.New("optimistic lock: version conflict")
+)
+
+// PagingResult 通用分页返回，见 pagination.PagingResult
+type PagingResult[DTO any] = pagination.PagingResult[DTO]
+
+// Repository 跨数据库的通用仓库接口。
+// 各数据库模块提供 NewRepositoryAdapter 将自身的 Repository 适配为该接口，
+// 业务层只依赖该接口即可在 GORM / Ent / MongoDB / ClickHouse 等之间切换。
+//
+// 过滤条件统一使用 FilterExpr，filter 为 nil 时表示不附加条件；
+// 为避免误操作全表，Update/Delete 的过滤条件为空时返回 ErrEmptyFilter，包含未指定组合类型的表达式时返回 ErrInvalidFilter。
+// 数据库不支持的操作返回 ErrNotSupported，Get 未找到记录时返回 ErrNotFound；
+// 实体包含版本字段时 Update/Upsert 启用乐观锁，版本不一致时返回 ErrVersionConflict。
+type Repository[DTO any] interface {
+	// List 按分页请求查询列表
+	List(ctx context.Context, req *paginationV1.PaginationRequest) (*PagingResult[DTO], error)
+
+	// Get 获取符合过滤条件的第一条记录
+	Get(ctx context.Context, filter *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error)
+
+	// Create 创建一条记录，返回创建后的 DTO
+	Create(ctx context.Context, dto *DTO, viewMask *fieldmaskpb.FieldMask) (*DTO, error)
+
+	// Update 更新符合过滤条件的记录，updateMask 指定要更新的字段，返回更新后的 DTO
+	Update(ctx context.Context, filter *paginationV1.FilterExpr, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error)
+
+	// Upsert 插入或冲突时更新，updateMask 指定冲突时要更新的字段
+	Upsert(ctx context.Context, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error)
+
+	// Delete 删除符合过滤条件的记录，返回受影响的行数
+	Delete(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error)
+
+	// Count 统计符合过滤条件的记录数
+	Count(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error)
+
+	// Exists 判断是否存在符合过滤条件的记录
+	Exists(ctx context.Context, filter *paginationV1.FilterExpr) (bool, error)
+}
+
+// SoftDeleteRepository 支持软删除生命周期的仓库（可选接口），由实体包含删除时间字段的 GORM / Ent / ClickHouse 适配器实现。
+// 查询已软删除的记录时，通过 softdelete.WithDeleted / softdelete.OnlyDeleted 设置 ctx 的查询模式；
+// Restore/Purge 的过滤条件为空时作用于全部已软删除的记录。
+type SoftDeleteRepository interface {
+	// Restore 恢复符合过滤条件的已软删除记录，返回受影响的行数
+	Restore(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error)
+
+	// Purge 物理删除符合过滤条件、且删除时间早于 olderThan 之前的已软删除记录，返回受影响的行数
+	Purge(ctx context.Context, filter *paginationV1.FilterExpr, olderThan time.Duration) (int64, error)
+}
+
+// ConflictResolver 乐观锁冲突时的重试回调：latest 为重新读取的最新记录，dto 为本次要应用的变更，
+// 返回基于 latest 重新合并后的 DTO（需携带 latest 的版本号）；返回 nil 表示放弃重试。
+type ConflictResolver[DTO any] func(ctx context.Context, latest *DTO, dto *DTO) (*DTO, error)
+
+// IsEmptyFilter 判断过滤表达式是否不包含任何条件。
+// 各后端编译时跳过未指定组合类型（EXPR_TYPE_UNSPECIFIED）的表达式，这类表达式同样视为空。
+func IsEmptyFilter(filter *paginationV1.FilterExpr) bool {
+	if filter == nil || filter.GetType() == paginationV1.ExprType_EXPR_TYPE_UNSPECIFIED {
+		return true
+	}
+	if len(filter.GetConditions()) > 0 {
+		return false
+	}
+	for _, g := range filter.GetGroups() {
+		if !IsEmptyFilter(g) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckMutationFilter 校验 Update/Delete 使用的过滤表达式：
+// 任一层表达式包含条件或子组却未指定组合类型时返回 ErrInvalidFilter（该层会在编译时被跳过，作用范围随之扩大），
+// 不包含任何条件时返回 ErrEmptyFilter。
+func CheckMutationFilter(filter *paginationV1.FilterExpr) error {
+	if err := checkFilterType(filter); err != nil {
+		return err
+	}
+	if IsEmptyFilter(filter) {
+		return ErrEmptyFilter
+	}
+	return nil
+}
+
+func checkFilterType(filter *paginationV1.FilterExpr) error {
+	if filter == nil {
+		return nil
+	}
+	if filter.GetType() == paginationV1.ExprType_EXPR_TYPE_UNSPECIFIED &&
+		(len(filter.GetConditions()) > 0 || len(filter.GetGroups()) > 0) {
+		return fmt.Errorf("%w: expression type is unspecified", ErrInvalidFilter)
+	}
+	for _, g := range filter.GetGroups() {
+		if err := checkFilterType(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
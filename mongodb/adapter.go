@@ -0,0 +1,229 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/tx7do/go-utils/stringcase"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	bsonV2 "go.mongodb.org/mongo-driver/v2/bson"
+	mongoV2 "go.mongodb.org/mongo-driver/v2/mongo"
+	optionsV2 "go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/mongodb/query"
+	"github.com/tx7do/go-crud/pagination/datascope"
+)
+
+var _ crud.Repository[struct{}] = (*RepositoryAdapter[struct{}, struct{}])(nil)
+
+// RepositoryAdapter 将 MongoDB Repository 适配为通用的 crud.Repository 接口
+type RepositoryAdapter[DTO any, ENTITY any] struct {
+	repo *Repository[DTO, ENTITY]
+}
+
+// NewRepositoryAdapter 创建 MongoDB 仓库适配器
+func NewRepositoryAdapter[DTO any, ENTITY any](repo *Repository[DTO, ENTITY]) *RepositoryAdapter[DTO, ENTITY] {
+	return &RepositoryAdapter[DTO, ENTITY]{
+		repo: repo,
+	}
+}
+
+// newQuery 将 FilterExpr 编译为查询构建器
+func (a *RepositoryAdapter[DTO, ENTITY]) newQuery(filter *paginationV1.FilterExpr) (*query.Builder, error) {
+	qb := query.NewQueryBuilder()
+	if filter == nil {
+		return qb, nil
+	}
+	if _, err := a.repo.structuredFilter.BuildSelectors(qb, filter); err != nil {
+		return nil, err
+	}
+	return qb, nil
+}
+
+// document 将 DTO 转换为 bson 文档（不含 _id），并返回 _id 的值（未设置时为 nil）
+func (a *RepositoryAdapter[DTO, ENTITY]) document(dto *DTO) (bsonV2.M, any, error) {
+	if dto == nil {
+		return nil, nil, errors.New("dto is nil")
+	}
+
+	raw, err := bsonV2.Marshal(a.repo.mapper.ToEntity(dto))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var doc bsonV2.M
+	if err = bsonV2.Unmarshal(raw, &doc); err != nil {
+		return nil, nil, err
+	}
+
+	// 零值 _id 视为未设置
+	id := doc["_id"]
+	delete(doc, "_id")
+	if id != nil && reflect.ValueOf(id).IsZero() {
+		id = nil
+	}
+	return doc, id, nil
+}
+
+// splitByMask 按 updateMask 将文档拆分为需要更新的字段与其余字段，updateMask 为空时全部字段均需更新
+func splitByMask(doc bsonV2.M, updateMask *fieldmaskpb.FieldMask) (set bsonV2.M, rest bsonV2.M) {
+	if updateMask == nil || len(updateMask.GetPaths()) == 0 {
+		return doc, bsonV2.M{}
+	}
+
+	set = bsonV2.M{}
+	rest = bsonV2.M{}
+	for _, p := range updateMask.GetPaths() {
+		key := stringcase.ToSnakeCase(p)
+		if v, ok := doc[key]; ok {
+			set[key] = v
+		}
+	}
+	for k, v := range doc {
+		if _, ok := set[k]; !ok {
+			rest[k] = v
+		}
+	}
+	return set, rest
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) List(ctx context.Context, req *paginationV1.PaginationRequest) (*crud.PagingResult[DTO], error) {
+	return a.repo.ListWithPagination(ctx, req)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Get(ctx context.Context, filter *paginationV1.FilterExpr, _ *fieldmaskpb.FieldMask) (*DTO, error) {
+	qb, err := a.newQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	dto, err := a.repo.Get(ctx, qb)
+	if errors.Is(err, mongoV2.ErrNoDocuments) {
+		return nil, crud.ErrNotFound
+	}
+	return dto, err
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Create(ctx context.Context, dto *DTO, _ *fieldmaskpb.FieldMask) (*DTO, error) {
+	return a.repo.Create(ctx, dto)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Update(ctx context.Context, filter *paginationV1.FilterExpr, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return nil, err
+	}
+
+	qb, err := a.newQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, _, err := a.document(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	set, _ := splitByMask(doc, updateMask)
+	if len(set) == 0 {
+		return nil, errors.New("nothing to update")
+	}
+
+	dto, err = a.repo.Update(ctx, qb, bsonV2.M{"$set": set})
+	if errors.Is(err, mongoV2.ErrNoDocuments) {
+		return nil, crud.ErrNotFound
+	}
+	return dto, err
+}
+
+// Upsert 按 _id 执行插入或更新：updateMask 中的字段通过 $set 更新，其余字段仅在插入时写入。
+// DTO 未携带 _id 时等同于 Create。设置了数据权限策略时更新同样追加数据权限条件，
+// 记录存在但不在权限范围内时返回 datascope.ErrAccessDenied。
+func (a *RepositoryAdapter[DTO, ENTITY]) Upsert(ctx context.Context, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	doc, id, err := a.document(dto)
+	if err != nil {
+		return nil, err
+	}
+	if id == nil {
+		return a.repo.Create(ctx, dto)
+	}
+
+	set, rest := splitByMask(doc, updateMask)
+	updateDoc := bsonV2.M{}
+	if len(set) > 0 {
+		updateDoc["$set"] = set
+	}
+	if len(rest) > 0 {
+		updateDoc["$setOnInsert"] = rest
+	}
+	if len(updateDoc) == 0 {
+		updateDoc["$setOnInsert"] = bsonV2.M{"_id": id}
+	}
+
+	if a.repo.client == nil {
+		return nil, errors.New("mongodb database is nil")
+	}
+
+	filterDoc, scoped, err := a.upsertFilter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var ent ENTITY
+	if err = a.repo.client.FindOneAndUpdate(ctx, a.repo.collection,
+		filterDoc, updateDoc,
+		&ent,
+		optionsV2.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(optionsV2.After),
+	); err != nil {
+		// 记录存在但不满足数据权限条件时，upsert 转为插入并触发主键冲突
+		if scoped && mongoV2.IsDuplicateKeyError(err) {
+			return nil, datascope.ErrAccessDenied
+		}
+		a.repo.log.Errorf("upsert one failed: %v", err)
+		return nil, err
+	}
+
+	return a.repo.mapper.ToDTO(&ent), nil
+}
+
+// upsertFilter 返回 Upsert 使用的过滤条件：按 _id 匹配并追加数据权限条件，scoped 表示是否追加了数据权限条件
+func (a *RepositoryAdapter[DTO, ENTITY]) upsertFilter(ctx context.Context, id any) (any, bool, error) {
+	filterDoc, err := a.repo.scopedFilter(ctx, bsonV2.M{"_id": id})
+	if err != nil {
+		return nil, false, err
+	}
+	m, _ := filterDoc.(bsonV2.M)
+	_, scoped := m["$and"]
+	return filterDoc, scoped, nil
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Delete(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	if err := crud.CheckMutationFilter(filter); err != nil {
+		return 0, err
+	}
+
+	qb, err := a.newQuery(filter)
+	if err != nil {
+		return 0, err
+	}
+	return a.repo.Delete(ctx, qb)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Count(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	qb, err := a.newQuery(filter)
+	if err != nil {
+		return 0, err
+	}
+	return a.repo.Count(ctx, qb)
+}
+
+func (a *RepositoryAdapter[DTO, ENTITY]) Exists(ctx context.Context, filter *paginationV1.FilterExpr) (bool, error) {
+	qb, err := a.newQuery(filter)
+	if err != nil {
+		return false, err
+	}
+	return a.repo.Exists(ctx, qb)
+}
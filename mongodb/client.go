@@ -103,7 +103,7 @@ func (c *Client) FindOne(ctx context.Context, collection string, filter interfac
 }
 
 // Find 查询多个文档
-func (c *Client) Find(ctx context.Context, collection string, filter interface{}, results interface{}) error {
+func (c *Client) Find(ctx context.Context, collection string, filter interface{}, results interface{}, opts ...optionsV2.Lister[optionsV2.FindOptions]) error {
 	if c.cli == nil {
 		c.log.Errorf("mongodb client is not initialized")
 		return mongoV2.ErrClientDisconnected
@@ -112,7 +112,7 @@ func (c *Client) Find(ctx context.Context, collection string, filter interface{}
 	ctx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
-	cursor, err := c.cli.Database(c.database).Collection(collection).Find(ctx, filter)
+	cursor, err := c.cli.Database(c.database).Collection(collection).Find(ctx, filter, opts...)
 	if err != nil {
 		c.log.Errorf("failed to find documents in collection %s: %v", collection, err)
 		return err
@@ -0,0 +1,53 @@
+package mongodb
+
+import (
+	"context"
+
+	bsonV2 "go.mongodb.org/mongo-driver/v2/bson"
+
+	"github.com/tx7do/go-crud/mongodb/filter"
+	"github.com/tx7do/go-crud/mongodb/query"
+	"github.com/tx7do/go-crud/pagination/datascope"
+)
+
+// WithDataScope 设置数据权限（行级权限）策略，查询、计数、更新、删除以及 RepositoryAdapter.Upsert 的更新
+// 均按 ctx 中的 Viewer 以 $and 追加数据权限条件；
+// 数据权限条件不受 WithFilterPolicy 与 WithFieldMapping 的影响，字段名以策略配置为准。
+// 创建（包括 Upsert 的插入）不做数据权限校验。
+func (r *Repository[DTO, ENTITY]) WithDataScope(policy *datascope.Policy) *Repository[DTO, ENTITY] {
+	r.dataScope = policy
+	if policy != nil {
+		r.dataScopeFilter = filter.NewStructuredFilter().WithFieldMapping(policy.Mapping())
+	} else {
+		r.dataScopeFilter = nil
+	}
+	return r
+}
+
+// scopedFilter 以 $and 组合 filterDoc 与数据权限条件，未设置策略或无需过滤时原样返回
+func (r *Repository[DTO, ENTITY]) scopedFilter(ctx context.Context, filterDoc interface{}) (interface{}, error) {
+	if r.dataScope == nil {
+		return filterDoc, nil
+	}
+
+	expr, err := r.dataScope.Build(ctx)
+	if err != nil {
+		r.log.Errorf("build data scope failed: %v", err)
+		return nil, err
+	}
+	if expr == nil {
+		return filterDoc, nil
+	}
+
+	qb := query.NewQueryBuilder()
+	if _, err = r.dataScopeFilter.BuildSelectors(qb, expr); err != nil {
+		r.log.Errorf("build data scope filter failed: %v", err)
+		return nil, err
+	}
+	scope, _ := qb.Build()
+
+	if m, ok := filterDoc.(bsonV2.M); filterDoc == nil || (ok && len(m) == 0) {
+		return scope, nil
+	}
+	return bsonV2.M{"$and": bsonV2.A{filterDoc, scope}}, nil
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/tx7do/go-crud/mongodb/query"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
 	"github.com/tx7do/go-utils/stringcase"
 	bsonV2 "go.mongodb.org/mongo-driver/v2/bson"
 )
@@ -13,11 +14,24 @@ var fieldNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
 
 // Selector 字段选择器，用于构建 MongoDB 查询中的 projection（投影）
 // 将传入的字段路径规范化、校验并转换为 mongo projection 文档。
-type Selector struct{}
+type Selector struct {
+	mapping *fieldmap.Mapping
+}
 
 // NewFieldSelector 返回一个新的 Selector。
 func NewFieldSelector() *Selector { return &Selector{} }
 
+// WithFieldMapping 设置字段映射，字段路径按映射替换为列名。
+func (fs *Selector) WithFieldMapping(mapping *fieldmap.Mapping) *Selector {
+	fs.mapping = mapping
+	return fs
+}
+
+// Resolve 返回字段路径按映射替换后的列名，未设置映射时原样返回。
+func (fs Selector) Resolve(field string) string {
+	return fs.mapping.Resolve(field)
+}
+
 // BuildSelector 为给定的 builder 构建 projection 并设置到 builder 中。
 // 当 fields 为空或无有效字段时返回原 builder 和 nil 错误。
 func (fs Selector) BuildSelector(builder *query.Builder, fields []string) (*query.Builder, error) {
@@ -28,7 +42,7 @@ func (fs Selector) BuildSelector(builder *query.Builder, fields []string) (*quer
 		return builder, nil
 	}
 
-	fields = NormalizePaths(fields)
+	fields = NormalizePaths(fs.mapping.Paths(fields))
 	if len(fields) == 0 {
 		return builder, nil
 	}
@@ -8,12 +8,16 @@ import (
 
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
 	"github.com/tx7do/go-crud/mongodb/query"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
 )
 
 // StructuredFilter 将 FilterExpr 转为 MongoDB BSON filter 并应用到 *query.Builder
 type StructuredFilter struct {
 	codec     encoding.Codec
 	processor *Processor
+	policy    *paginationFilter.FilterPolicy
+	mapping   *fieldmap.Mapping
 }
 
 func NewStructuredFilter() *StructuredFilter {
@@ -23,6 +27,18 @@ func NewStructuredFilter() *StructuredFilter {
 	}
 }
 
+// WithPolicy 设置字段策略，过滤条件需通过策略校验，字段按策略映射为列名
+func (sf *StructuredFilter) WithPolicy(policy *paginationFilter.FilterPolicy) *StructuredFilter {
+	sf.policy = policy
+	return sf
+}
+
+// WithFieldMapping 设置字段映射，条件字段按映射替换为列名
+func (sf *StructuredFilter) WithFieldMapping(mapping *fieldmap.Mapping) *StructuredFilter {
+	sf.mapping = mapping
+	return sf
+}
+
 // BuildSelectors 将 expr 转为 BSON 过滤器并通过 builder.SetFilter 应用。
 // 若 builder 为 nil 会新建一个。
 func (sf StructuredFilter) BuildSelectors(builder *query.Builder, expr *paginationV1.FilterExpr) (*query.Builder, error) {
@@ -33,6 +49,12 @@ func (sf StructuredFilter) BuildSelectors(builder *query.Builder, expr *paginati
 		return builder, nil
 	}
 
+	expr, err := sf.policy.CheckFilter(expr)
+	if err != nil {
+		return builder, err
+	}
+	expr = sf.mapping.Filter(expr)
+
 	// 递归将 expr 转为单个 bsonV2.M 过滤器（可能包含 $and/$or）
 	var buildParts func(e *paginationV1.FilterExpr) bsonV2.M
 	buildParts = func(e *paginationV1.FilterExpr) bsonV2.M {
@@ -1,12 +1,16 @@
 package filter
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/tx7do/go-crud/mongodb/query"
 	"google.golang.org/protobuf/encoding/protojson"
 
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
 )
 
 func mustMarshal(fe *paginationV1.FilterExpr) string {
@@ -83,6 +87,39 @@ func TestBuildFilterSelectors_SimpleAnd(t *testing.T) {
 	}
 }
 
+func TestBuildFilterSelectors_Policy(t *testing.T) {
+	sf := NewStructuredFilter().WithPolicy(paginationFilter.NewFilterPolicy().
+		Field("userName", paginationFilter.FieldRule{Column: "user_name"}).
+		DenyOperators(paginationV1.Operator_REGEXP))
+
+	builder := &query.Builder{}
+	_, err := sf.BuildSelectors(builder, &paginationV1.FilterExpr{
+		Type: paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{
+			{Field: "userName", Op: paginationV1.Operator_EQ, ValueOneof: &paginationV1.FilterCondition_Value{Value: "alice"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildSelectors error: %v", err)
+	}
+	filterDoc, _, _ := builder.BuildFind()
+	if js, _ := json.Marshal(filterDoc); !strings.Contains(string(js), "user_name") {
+		t.Fatalf("expected mapped column in filter, got %s", js)
+	}
+
+	_, err = sf.BuildSelectors(&query.Builder{}, &paginationV1.FilterExpr{
+		Type: paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{
+			{Field: "password", Op: paginationV1.Operator_EQ, ValueOneof: &paginationV1.FilterCondition_Value{Value: "x"}},
+			{Field: "userName", Op: paginationV1.Operator_REGEXP, ValueOneof: &paginationV1.FilterCondition_Value{Value: ".*"}},
+		},
+	})
+	var pe *paginationFilter.PolicyError
+	if !errors.As(err, &pe) || len(pe.Violations) != 2 {
+		t.Fatalf("expected 2 policy violations, got %v", err)
+	}
+}
+
 func TestStructuredFilter_SupportedOperators_CreateSelectors(t *testing.T) {
 	sf := NewStructuredFilter()
 
@@ -1,66 +1,143 @@
 package pagination
 
 import (
-	"encoding/base64"
+	"strings"
 
-	"github.com/go-kratos/kratos/v2/encoding"
-	_ "github.com/go-kratos/kratos/v2/encoding/json"
-	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-utils/stringcase"
 	bsonV2 "go.mongodb.org/mongo-driver/v2/bson"
 
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
 	"github.com/tx7do/go-crud/mongodb/query"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/keyset"
 	"github.com/tx7do/go-crud/pagination/paginator"
 )
 
-// TokenPaginator 基于 Token 的分页器（MongoDB 版）
+// DefaultTiebreaker MongoDB 默认使用 _id 保证排序唯一性
+const DefaultTiebreaker = "_id"
+
+// TokenPaginator 基于 Token 的 keyset（seek）分页器（MongoDB 版）
+// token 中记录了上一页边界行在所有排序列（含 tiebreaker）上的值
 type TokenPaginator struct {
-	impl  pagination.Paginator
-	codec encoding.Codec
+	impl       pagination.Paginator
+	tiebreaker string
+	codec      pagination.CursorCodec
 }
 
 func NewTokenPaginator() *TokenPaginator {
 	return &TokenPaginator{
-		impl:  paginator.NewTokenPaginatorWithDefault(),
-		codec: encoding.GetCodec("json"),
+		impl:       paginator.NewTokenPaginatorWithDefault(),
+		tiebreaker: DefaultTiebreaker,
+		codec:      pagination.DefaultCursorCodec(),
 	}
 }
 
-// BuildClause 根据传入 token/pageSize 更新状态并将 filter/limit 设置到 query.Builder。
-// 若 pageSize <= 0 则返回原 builder。若 token 无法解析则仅设置 limit。
-func (p *TokenPaginator) BuildClause(builder *query.Builder, token string, pageSize int) *query.Builder {
+// WithTiebreaker 设置保证排序唯一性的字段（默认 _id）
+func (p *TokenPaginator) WithTiebreaker(field string) *TokenPaginator {
+	p.tiebreaker = field
+	return p
+}
+
+// WithCursorCodec 设置 token 的编解码器（签名/加密），为 nil 时使用默认编解码器
+func (p *TokenPaginator) WithCursorCodec(codec pagination.CursorCodec) *TokenPaginator {
+	if codec == nil {
+		codec = pagination.DefaultCursorCodec()
+	}
+	p.codec = codec
+	return p
+}
+
+// BuildSeek 根据传入 token/pageSize/排序更新状态并解析出本次查询的 keyset 状态。
+// scope 为 token 绑定的查询范围（见 pagination.CursorScope），在其他查询中复用 token 将返回错误。
+func (p *TokenPaginator) BuildSeek(token string, pageSize int, sortings []*paginationV1.Sorting, scope []byte) (*keyset.Seek, error) {
 	p.impl.
 		WithToken(token).
-		WithPage(pageSize)
+		WithSize(pageSize)
+
+	return keyset.NewSeek(token, p.impl.Size(), sortings, p.tiebreaker,
+		keyset.WithCodec(p.codec),
+		keyset.WithScope(scope),
+	)
+}
 
-	size := p.impl.Size()
-	if size <= 0 {
+// BuildClause 将 keyset 条件（与已有 filter 以 $and 组合）、排序与 limit 设置到 query.Builder。
+// limit 为 pageSize+1，多取一条用于判断是否存在下一页。
+func (p *TokenPaginator) BuildClause(builder *query.Builder, seek *keyset.Seek) *query.Builder {
+	if builder == nil || seek == nil {
 		return builder
 	}
 
-	// 无 token 或无法解码时仅设置 limit
-	if token == "" {
-		builder.SetLimit(int64(size))
-		return builder
+	if cond := buildSeekFilter(seek); cond != nil {
+		filter, _ := builder.Build()
+		if len(filter) == 0 {
+			builder.SetFilter(cond)
+		} else {
+			builder.SetFilter(bsonV2.M{query.OperatorAnd: []bsonV2.M{filter, cond}})
+		}
 	}
 
-	b, err := base64.StdEncoding.DecodeString(token)
-	if err != nil {
-		builder.SetLimit(int64(size))
-		return builder
+	sortFields := make([]bsonV2.E, 0, len(seek.Columns))
+	for _, c := range seek.OrderBy() {
+		dir := int32(1)
+		if c.Desc {
+			dir = -1
+		}
+		sortFields = append(sortFields, bsonV2.E{Key: fieldKey(c.Field), Value: dir})
 	}
+	builder.SetSortWithPriority(sortFields)
+	builder.SetLimit(int64(seek.Limit()))
 
-	var c struct {
-		LastID int64 `json:"last_id"`
+	return builder
+}
+
+// buildSeekFilter 将 keyset 条件转换为 $or 过滤条件，无游标时返回 nil
+func buildSeekFilter(seek *keyset.Seek) bsonV2.M {
+	groups := seek.Conditions()
+	if len(groups) == 0 {
+		return nil
 	}
-	if err = p.codec.Unmarshal(b, &c); err != nil {
-		builder.SetLimit(int64(size))
-		return builder
+
+	ors := make([]bsonV2.M, 0, len(groups))
+	for _, group := range groups {
+		and := bsonV2.M{}
+		for _, t := range group {
+			key := fieldKey(t.Field)
+			value := fieldValue(key, t.Value)
+			switch t.Op {
+			case keyset.OpGT:
+				and[key] = bsonV2.M{query.OperatorGt: value}
+			case keyset.OpLT:
+				and[key] = bsonV2.M{query.OperatorLt: value}
+			default:
+				and[key] = value
+			}
+		}
+		ors = append(ors, and)
+	}
+
+	if len(ors) == 1 {
+		return ors[0]
 	}
+	return bsonV2.M{query.OperatorOr: ors}
+}
 
-	// 为 MongoDB 设置过滤条件 id > last_id，并设置 limit
-	filter := bsonV2.M{"id": bsonV2.M{"$gt": c.LastID}}
-	builder.SetFilter(filter)
-	builder.SetLimit(int64(size))
+// fieldKey 字段名转换为 snake_case，_id 等以下划线开头的字段保持原样
+func fieldKey(field string) string {
+	if strings.HasPrefix(field, "_") {
+		return field
+	}
+	return stringcase.ToSnakeCase(field)
+}
 
-	return builder
+// fieldValue 还原 _id 的 ObjectID 类型（游标中以十六进制字符串保存）
+func fieldValue(key string, value any) any {
+	if key != "_id" {
+		return value
+	}
+	if s, ok := value.(string); ok {
+		if oid, err := bsonV2.ObjectIDFromHex(s); err == nil {
+			return oid
+		}
+	}
+	return value
 }
@@ -0,0 +1,98 @@
+package pagination
+
+import (
+	"reflect"
+	"testing"
+
+	bsonV2 "go.mongodb.org/mongo-driver/v2/bson"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/mongodb/query"
+	"github.com/tx7do/go-crud/pagination/keyset"
+)
+
+type tokenTestDoc struct {
+	ID    bsonV2.ObjectID `bson:"_id"`
+	Score int64           `bson:"score"`
+}
+
+func TestTokenPaginator_FirstPage(t *testing.T) {
+	p := NewTokenPaginator()
+	seek, err := p.BuildSeek("", 10, []*paginationV1.Sorting{{Field: "createdAt", Direction: paginationV1.Sorting_DESC}}, nil)
+	if err != nil {
+		t.Fatalf("build seek: %v", err)
+	}
+
+	qb := p.BuildClause(query.NewQueryBuilder(), seek)
+	filter, opts := qb.Build()
+
+	if len(filter) != 0 {
+		t.Fatalf("expected empty filter, got %v", filter)
+	}
+	wantSort := bsonV2.D{{Key: "created_at", Value: int32(-1)}, {Key: "_id", Value: int32(1)}}
+	if !reflect.DeepEqual(opts.Sort, wantSort) {
+		t.Fatalf("unexpected sort: %v", opts.Sort)
+	}
+	if opts.Limit == nil || *opts.Limit != 11 {
+		t.Fatalf("unexpected limit: %v", opts.Limit)
+	}
+}
+
+func TestTokenPaginator_NextPage(t *testing.T) {
+	oid := bsonV2.NewObjectID()
+	sortings := []*paginationV1.Sorting{{Field: "score", Direction: paginationV1.Sorting_DESC}}
+	cols, _ := keyset.Columns(sortings, DefaultTiebreaker)
+
+	// 由查询结果生成游标，_id 以十六进制字符串保存
+	values, err := keyset.ValuesOf(&tokenTestDoc{ID: oid, Score: 80}, cols)
+	if err != nil {
+		t.Fatalf("values of: %v", err)
+	}
+	token, err := keyset.Encode(cols, values, false)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	p := NewTokenPaginator()
+	seek, err := p.BuildSeek(token, 10, sortings, nil)
+	if err != nil {
+		t.Fatalf("build seek: %v", err)
+	}
+
+	qb := query.NewQueryBuilder().Where(bsonV2.M{"status": "on"})
+	filter, _ := p.BuildClause(qb, seek).Build()
+
+	want := bsonV2.M{
+		query.OperatorAnd: []bsonV2.M{
+			{"status": "on"},
+			{query.OperatorOr: []bsonV2.M{
+				{"score": bsonV2.M{query.OperatorLt: int64(80)}},
+				{"score": int64(80), "_id": bsonV2.M{query.OperatorGt: oid}},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(filter, want) {
+		t.Fatalf("unexpected filter:\n got %v\nwant %v", filter, want)
+	}
+}
+
+func TestTokenPaginator_Backward(t *testing.T) {
+	cols, _ := keyset.Columns(nil, DefaultTiebreaker)
+	token, _ := keyset.Encode(cols, []any{"not-an-object-id"}, true)
+
+	p := NewTokenPaginator()
+	seek, err := p.BuildSeek(token, 5, nil, nil)
+	if err != nil {
+		t.Fatalf("build seek: %v", err)
+	}
+
+	filter, opts := p.BuildClause(query.NewQueryBuilder(), seek).Build()
+
+	want := bsonV2.M{"_id": bsonV2.M{query.OperatorLt: "not-an-object-id"}}
+	if !reflect.DeepEqual(filter, want) {
+		t.Fatalf("unexpected filter: %v", filter)
+	}
+	if !reflect.DeepEqual(opts.Sort, bsonV2.D{{Key: "_id", Value: int32(-1)}}) {
+		t.Fatalf("unexpected sort: %v", opts.Sort)
+	}
+}
@@ -13,13 +13,21 @@ import (
 	paging "github.com/tx7do/go-crud/mongodb/pagination"
 	"github.com/tx7do/go-crud/mongodb/query"
 	"github.com/tx7do/go-crud/mongodb/sorting"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/datascope"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
 	paginationFilter "github.com/tx7do/go-crud/pagination/filter"
+	"github.com/tx7do/go-crud/pagination/keyset"
+	"github.com/tx7do/go-crud/pagination/paginator"
 	paginationSorting "github.com/tx7do/go-crud/pagination/sorting"
 
 	bsonV2 "go.mongodb.org/mongo-driver/v2/bson"
 	optionsV2 "go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
+// PagingResult 通用分页返回（含完整的分页元数据），见 pagination.PagingResult
+type PagingResult[E any] = pagination.PagingResult[E]
+
 // Repository MongoDB 版仓库（泛型）
 type Repository[DTO any, ENTITY any] struct {
 	mapper *mapper.CopierMapper[DTO, ENTITY]
@@ -35,6 +43,9 @@ type Repository[DTO any, ENTITY any] struct {
 
 	fieldSelector *field.Selector
 
+	dataScope       *datascope.Policy
+	dataScopeFilter *filter.StructuredFilter
+
 	client     *Client
 	collection string
 	log        *log.Helper
@@ -62,13 +73,34 @@ func NewRepository[DTO any, ENTITY any](client *Client, collection string, mappe
 	}
 }
 
+// WithCursorCodec 设置 token 分页使用的游标编解码器，生产环境建议使用 pagination.SignedCursorCodec 防止 token 被伪造
+func (r *Repository[DTO, ENTITY]) WithCursorCodec(codec pagination.CursorCodec) *Repository[DTO, ENTITY] {
+	r.tokenPaginator.WithCursorCodec(codec)
+	return r
+}
+
+// WithFilterPolicy 设置过滤与排序的字段策略（白名单、列名映射、操作符限制等），违反策略的查询返回 paginationFilter.ErrPolicyViolation
+func (r *Repository[DTO, ENTITY]) WithFilterPolicy(policy *paginationFilter.FilterPolicy) *Repository[DTO, ENTITY] {
+	r.structuredFilter.WithPolicy(policy)
+	r.structuredSorting.WithPolicy(policy)
+	return r
+}
+
+// WithFieldMapping 设置 API 字段名到列名的映射，过滤、排序与字段选择均按映射解析列名
+func (r *Repository[DTO, ENTITY]) WithFieldMapping(mapping *fieldmap.Mapping) *Repository[DTO, ENTITY] {
+	r.structuredFilter.WithFieldMapping(mapping)
+	r.structuredSorting.WithFieldMapping(mapping)
+	r.fieldSelector.WithFieldMapping(mapping)
+	return r
+}
+
 // ListWithPaging 针对 paginationV1.PagingRequest 的列表查询（兼容 Query/OrQuery/FilterExpr）
-func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, req *paginationV1.PagingRequest) ([]*DTO, int64, error) {
+func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, req *paginationV1.PagingRequest) (*PagingResult[DTO], error) {
 	if r.client == nil {
-		return nil, 0, errors.New("mongodb database is nil")
+		return nil, errors.New("mongodb database is nil")
 	}
 	if r.collection == "" {
-		return nil, 0, errors.New("collection is empty")
+		return nil, errors.New("collection is empty")
 	}
 
 	qb := query.NewQueryBuilder()
@@ -80,64 +112,102 @@ func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, req *pagin
 	filterExpr, err = paginationFilter.ConvertFilterByPagingRequest(req)
 	if err != nil {
 		log.Errorf("convert filter string to filter expr failed: %s", err.Error())
-		return nil, 0, err
+		return nil, err
 	}
 	req.FilteringType = &paginationV1.PagingRequest_FilterExpr{FilterExpr: filterExpr}
 
 	if _, err = r.structuredFilter.BuildSelectors(qb, req.GetFilterExpr()); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	// select fields
-	if req.FieldMask != nil && len(req.GetFieldMask().Paths) > 0 {
-		if _, err := r.fieldSelector.BuildSelector(qb, req.GetFieldMask().GetPaths()); err != nil {
-			r.log.Errorf("field selector build error: %v", err)
-		}
+	// 计数（在应用 keyset 条件之前）
+	total, err := r.Count(ctx, qb)
+	if err != nil {
+		return nil, err
 	}
 
 	// sorting
+	var sortings []*paginationV1.Sorting
 	if len(req.GetOrderBy()) > 0 {
-		var sortings []*paginationV1.Sorting
 		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
 		if err != nil {
 			log.Errorf("convert order by string to sorting failed: %s", err.Error())
-			return nil, 0, err
+			return nil, err
 		}
-		_ = r.structuredSorting.BuildOrderClause(qb, sortings)
 	} else if len(req.GetSorting()) > 0 {
-		_ = r.structuredSorting.BuildOrderClause(qb, req.GetSorting())
+		sortings = req.GetSorting()
+	}
+
+	// 按字段策略校验排序字段，keyset 分页使用映射后的列名
+	var sortColumns []*paginationV1.Sorting
+	if sortColumns, err = r.structuredSorting.Validate(sortings); err != nil {
+		r.log.Errorf("validate sorting failed: %v", err)
+		return nil, err
 	}
 
 	// pagination
+	var seek *keyset.Seek
+	var pager pagination.Paginator
 	if !req.GetNoPaging() {
 		if req.Page != nil && req.PageSize != nil {
+			pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPage())).WithSize(int(req.GetPageSize()))
 			_ = r.pagePaginator.BuildClause(qb, int(req.GetPage()), int(req.GetPageSize()))
 		} else if req.Offset != nil && req.Limit != nil {
+			pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffset())).WithLimit(int(req.GetLimit()))
 			_ = r.offsetPaginator.BuildClause(qb, int(req.GetOffset()), int(req.GetLimit()))
 		} else if req.Token != nil && req.Offset != nil {
-			_ = r.tokenPaginator.BuildClause(qb, req.GetToken(), int(req.GetOffset()))
+			if seek, err = r.tokenPaginator.BuildSeek(req.GetToken(), int(req.GetOffset()), sortColumns, pagination.CursorScopeOf(req)); err != nil {
+				r.log.Errorf("build keyset seek failed: %v", err)
+				return nil, err
+			}
+			pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetToken()).WithSize(seek.Size)
+			_ = r.tokenPaginator.BuildClause(qb, seek)
 		}
 	}
 
-	// 计数
-	total, err := r.Count(ctx, qb)
-	if err != nil {
-		return nil, 0, err
+	// keyset 分页自行处理排序（含 tiebreaker 以及向前翻页时的反转）
+	if seek == nil && len(sortings) > 0 {
+		_ = r.structuredSorting.BuildOrderClause(qb, sortings)
+	}
+
+	// select fields（keyset 分页需要排序列的值生成游标，未请求的排序列在返回前清除）
+	var seekExtra []keyset.Column
+	if req.FieldMask != nil && len(req.GetFieldMask().Paths) > 0 {
+		var paths []string
+		paths, seekExtra = seek.Select(req.GetFieldMask().GetPaths(), r.fieldSelector.Resolve)
+		if _, err := r.fieldSelector.BuildSelector(qb, paths); err != nil {
+			r.log.Errorf("field selector build error: %v", err)
+		}
 	}
 
 	// 执行查询
-	filterDoc, _, err := qb.BuildFind()
+	filterDoc, findOpts, err := qb.BuildFind()
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	if filterDoc == nil {
 		filterDoc = bsonV2.M{}
 	}
+	// 数据权限（计数时由 Count 单独追加）
+	if filterDoc, err = r.scopedFilter(ctx, filterDoc); err != nil {
+		return nil, err
+	}
 
 	var results []*ENTITY
-	if err = r.client.Find(ctx, r.collection, filterDoc, &results); err != nil {
+	if err = r.client.Find(ctx, r.collection, filterDoc, &results, findOpts); err != nil {
 		r.log.Errorf("find failed: %v", err)
-		return nil, 0, err
+		return nil, err
+	}
+
+	if seek != nil {
+		var nextToken, prevToken string
+		if results, nextToken, prevToken, err = keyset.Page(seek, results); err != nil {
+			r.log.Errorf("build keyset token failed: %v", err)
+			return nil, err
+		}
+		pager.SetNextToken(nextToken)
+		pager.SetPrevToken(prevToken)
+		keyset.Clear(results, seekExtra)
 	}
 
 	// 转换为 DTO
@@ -145,16 +215,17 @@ func (r *Repository[DTO, ENTITY]) ListWithPaging(ctx context.Context, req *pagin
 	for _, ent := range results {
 		dtos = append(dtos, r.mapper.ToDTO(ent))
 	}
-	return dtos, total, nil
+
+	return pagination.NewPagingResult(dtos, total, pager), nil
 }
 
 // ListWithPagination 针对 paginationV1.PaginationRequest 的列表查询
-func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, req *paginationV1.PaginationRequest) ([]*DTO, int64, error) {
+func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, req *paginationV1.PaginationRequest) (*PagingResult[DTO], error) {
 	if r.client == nil {
-		return nil, 0, errors.New("mongodb database is nil")
+		return nil, errors.New("mongodb database is nil")
 	}
 	if r.collection == "" {
-		return nil, 0, errors.New("collection is empty")
+		return nil, errors.New("collection is empty")
 	}
 
 	qb := query.NewQueryBuilder()
@@ -166,63 +237,101 @@ func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, req *p
 	filterExpr, err = paginationFilter.ConvertFilterByPaginationRequest(req)
 	if err != nil {
 		log.Errorf("convert filter string to filter expr failed: %s", err.Error())
-		return nil, 0, err
+		return nil, err
 	}
 	req.FilteringType = &paginationV1.PaginationRequest_FilterExpr{FilterExpr: filterExpr}
 
 	if _, err = r.structuredFilter.BuildSelectors(qb, req.GetFilterExpr()); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	// select fields
-	if req.FieldMask != nil && len(req.GetFieldMask().Paths) > 0 {
-		if _, err := r.fieldSelector.BuildSelector(qb, req.GetFieldMask().GetPaths()); err != nil {
-			r.log.Errorf("field selector build error: %v", err)
-		}
+	// 计数（在应用 keyset 条件之前）
+	total, err := r.Count(ctx, qb)
+	if err != nil {
+		return nil, err
 	}
 
 	// sorting
+	var sortings []*paginationV1.Sorting
 	if len(req.GetOrderBy()) > 0 {
-		var sortings []*paginationV1.Sorting
 		sortings, err = r.orderByStringConverter.Convert(req.GetOrderBy())
 		if err != nil {
 			log.Errorf("convert order by string to sorting failed: %s", err.Error())
-			return nil, 0, err
+			return nil, err
 		}
-		_ = r.structuredSorting.BuildOrderClause(qb, sortings)
 	} else if len(req.GetSorting()) > 0 {
-		_ = r.structuredSorting.BuildOrderClause(qb, req.GetSorting())
+		sortings = req.GetSorting()
+	}
+
+	// 按字段策略校验排序字段，keyset 分页使用映射后的列名
+	var sortColumns []*paginationV1.Sorting
+	if sortColumns, err = r.structuredSorting.Validate(sortings); err != nil {
+		r.log.Errorf("validate sorting failed: %v", err)
+		return nil, err
 	}
 
 	// pagination
+	var seek *keyset.Seek
+	var pager pagination.Paginator
 	switch req.GetPaginationType().(type) {
 	case *paginationV1.PaginationRequest_OffsetBased:
+		pager = paginator.NewOffsetPaginatorWithDefault().WithOffset(int(req.GetOffsetBased().GetOffset())).WithLimit(int(req.GetOffsetBased().GetLimit()))
 		_ = r.offsetPaginator.BuildClause(qb, int(req.GetOffsetBased().GetOffset()), int(req.GetOffsetBased().GetLimit()))
 	case *paginationV1.PaginationRequest_PageBased:
+		pager = paginator.NewPagePaginatorWithDefault().WithPage(int(req.GetPageBased().GetPage())).WithSize(int(req.GetPageBased().GetPageSize()))
 		_ = r.pagePaginator.BuildClause(qb, int(req.GetPageBased().GetPage()), int(req.GetPageBased().GetPageSize()))
 	case *paginationV1.PaginationRequest_TokenBased:
-		_ = r.tokenPaginator.BuildClause(qb, req.GetTokenBased().GetToken(), int(req.GetTokenBased().GetPageSize()))
+		if seek, err = r.tokenPaginator.BuildSeek(req.GetTokenBased().GetToken(), int(req.GetTokenBased().GetPageSize()), sortColumns, pagination.CursorScopeOf(req)); err != nil {
+			r.log.Errorf("build keyset seek failed: %v", err)
+			return nil, err
+		}
+		pager = paginator.NewTokenPaginatorWithDefault().WithToken(req.GetTokenBased().GetToken()).WithSize(seek.Size)
+		_ = r.tokenPaginator.BuildClause(qb, seek)
 	}
 
-	// 计数
-	total, err := r.Count(ctx, qb)
-	if err != nil {
-		return nil, 0, err
+	// keyset 分页自行处理排序（含 tiebreaker 以及向前翻页时的反转）
+	if seek == nil && len(sortings) > 0 {
+		_ = r.structuredSorting.BuildOrderClause(qb, sortings)
+	}
+
+	// select fields（keyset 分页需要排序列的值生成游标，未请求的排序列在返回前清除）
+	var seekExtra []keyset.Column
+	if req.FieldMask != nil && len(req.GetFieldMask().Paths) > 0 {
+		var paths []string
+		paths, seekExtra = seek.Select(req.GetFieldMask().GetPaths(), r.fieldSelector.Resolve)
+		if _, err := r.fieldSelector.BuildSelector(qb, paths); err != nil {
+			r.log.Errorf("field selector build error: %v", err)
+		}
 	}
 
 	// 执行查询
-	filterDoc, _, err := qb.BuildFind()
+	filterDoc, findOpts, err := qb.BuildFind()
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	if filterDoc == nil {
 		filterDoc = bsonV2.M{}
 	}
+	// 数据权限（计数时由 Count 单独追加）
+	if filterDoc, err = r.scopedFilter(ctx, filterDoc); err != nil {
+		return nil, err
+	}
 
 	var results []*ENTITY
-	if err = r.client.Find(ctx, r.collection, filterDoc, &results); err != nil {
+	if err = r.client.Find(ctx, r.collection, filterDoc, &results, findOpts); err != nil {
 		r.log.Errorf("find failed: %v", err)
-		return nil, 0, err
+		return nil, err
+	}
+
+	if seek != nil {
+		var nextToken, prevToken string
+		if results, nextToken, prevToken, err = keyset.Page(seek, results); err != nil {
+			r.log.Errorf("build keyset token failed: %v", err)
+			return nil, err
+		}
+		pager.SetNextToken(nextToken)
+		pager.SetPrevToken(prevToken)
+		keyset.Clear(results, seekExtra)
 	}
 
 	// 转换为 DTO
@@ -230,7 +339,8 @@ func (r *Repository[DTO, ENTITY]) ListWithPagination(ctx context.Context, req *p
 	for _, ent := range results {
 		dtos = append(dtos, r.mapper.ToDTO(ent))
 	}
-	return dtos, total, nil
+
+	return pagination.NewPagingResult(dtos, total, pager), nil
 }
 
 // Get 根据过滤条件返回单条记录（使用 FilterExpr 或 Query/OrQuery 前置构建 qb）
@@ -252,6 +362,9 @@ func (r *Repository[DTO, ENTITY]) Get(ctx context.Context, qb *query.Builder) (*
 	if filterDoc == nil {
 		filterDoc = bsonV2.M{}
 	}
+	if filterDoc, err = r.scopedFilter(ctx, filterDoc); err != nil {
+		return nil, err
+	}
 
 	var ent ENTITY
 	if err = r.client.FindOne(ctx, r.collection, filterDoc, &ent); err != nil {
@@ -336,6 +449,9 @@ func (r *Repository[DTO, ENTITY]) Update(ctx context.Context, qb *query.Builder,
 	if filterDoc == nil {
 		return nil, errors.New("empty filter for update")
 	}
+	if filterDoc, err = r.scopedFilter(ctx, filterDoc); err != nil {
+		return nil, err
+	}
 
 	var ent ENTITY
 	err = r.client.FindOneAndUpdate(ctx, r.collection,
@@ -370,6 +486,9 @@ func (r *Repository[DTO, ENTITY]) Delete(ctx context.Context, qb *query.Builder)
 	if filterDoc == nil {
 		filterDoc = bsonV2.M{}
 	}
+	if filterDoc, err = r.scopedFilter(ctx, filterDoc); err != nil {
+		return 0, err
+	}
 
 	res, err := r.client.DeleteMany(ctx, r.collection, filterDoc)
 	if err != nil {
@@ -396,6 +515,9 @@ func (r *Repository[DTO, ENTITY]) Count(ctx context.Context, qb *query.Builder)
 	if err != nil {
 		return 0, err
 	}
+	if filterDoc, err = r.scopedFilter(ctx, filterDoc); err != nil {
+		return 0, err
+	}
 
 	count, err := r.client.Count(ctx, r.collection, filterDoc)
 	if err != nil {
@@ -422,6 +544,9 @@ func (r *Repository[DTO, ENTITY]) Exists(ctx context.Context, qb *query.Builder)
 	if err != nil {
 		return false, err
 	}
+	if filterDoc, err = r.scopedFilter(ctx, filterDoc); err != nil {
+		return false, err
+	}
 
 	exist, err := r.client.Exist(ctx, r.collection, filterDoc)
 	if err != nil {
@@ -12,6 +12,8 @@ import (
 	bsonV2 "go.mongodb.org/mongo-driver/v2/bson"
 
 	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination/datascope"
+	"github.com/tx7do/go-crud/viewer"
 )
 
 // 简单实体类型用于测试
@@ -29,13 +31,13 @@ func TestRepository_ErrorBranches(t *testing.T) {
 
 	// 1. ListWithPaging: db 为 nil -> 错误
 	repoNilDB := NewRepository[NoDeleted, NoDeleted](nil, "tmp", noDelMapper, logger)
-	_, _, err := repoNilDB.ListWithPaging(ctx, &paginationV1.PagingRequest{})
+	_, err := repoNilDB.ListWithPaging(ctx, &paginationV1.PagingRequest{})
 	assert.Error(t, err)
 	assert.Equal(t, "mongodb database is nil", err.Error())
 
 	// 2. ListWithPaging: collection 为空 -> 错误
 	repoEmptyColl := NewRepository[NoDeleted, NoDeleted](client, "", noDelMapper, logger)
-	_, _, err = repoEmptyColl.ListWithPaging(ctx, &paginationV1.PagingRequest{})
+	_, err = repoEmptyColl.ListWithPaging(ctx, &paginationV1.PagingRequest{})
 	assert.Error(t, err)
 	assert.Equal(t, "collection is empty", err.Error())
 
@@ -111,11 +113,11 @@ func TestRepository_CRUDAndList(t *testing.T) {
 	assert.NoError(t, err)
 
 	// List all
-	all, _, err := repo.ListWithPaging(ctx, &paginationV1.PagingRequest{})
+	all, err := repo.ListWithPaging(ctx, &paginationV1.PagingRequest{})
 	assert.NoError(t, err)
-	assert.Len(t, all, 3)
+	assert.Len(t, all.Items, 3)
 	ids := map[int]bool{}
-	for _, d := range all {
+	for _, d := range all.Items {
 		ids[d.ID] = true
 	}
 	assert.True(t, ids[1] && ids[2] && ids[3])
@@ -142,11 +144,11 @@ func TestRepository_CRUDAndList(t *testing.T) {
 	assert.EqualValues(t, 1, delCount)
 
 	// Final list expect 2 items (1 and 3)
-	final, _, err := repo.ListWithPaging(ctx, &paginationV1.PagingRequest{})
+	final, err := repo.ListWithPaging(ctx, &paginationV1.PagingRequest{})
 	assert.NoError(t, err)
-	assert.Len(t, final, 2)
+	assert.Len(t, final.Items, 2)
 	finalIDs := map[int]bool{}
-	for _, d := range final {
+	for _, d := range final.Items {
 		finalIDs[d.ID] = true
 	}
 	assert.True(t, finalIDs[1] && finalIDs[3])
@@ -179,9 +181,66 @@ func TestRepository_ListWithPaging_ConditionQuery(t *testing.T) {
 		NoPaging: trans.Ptr(true),
 	}
 
-	results, total, err := repo.ListWithPaging(ctx, req)
+	res, err := repo.ListWithPaging(ctx, req)
 	assert.NoError(t, err)
-	assert.EqualValues(t, int64(1), total)
-	assert.Len(t, results, 1)
-	assert.Equal(t, 2, results[0].ID)
+	assert.EqualValues(t, 1, res.Total)
+	assert.Len(t, res.Items, 1)
+	assert.Equal(t, 2, res.Items[0].ID)
+}
+
+// scopeViewer 指定数据权限范围的 Viewer
+type scopeViewer struct {
+	viewer.Context
+	uid    uint64
+	scopes []viewer.DataScope
+}
+
+func (v scopeViewer) UserID() uint64                { return v.uid }
+func (v scopeViewer) DataScope() []viewer.DataScope { return v.scopes }
+
+func TestRepository_DataScope_Filter(t *testing.T) {
+	logger := log.NewHelper(log.DefaultLogger)
+	repo := NewRepository[NoDeleted, NoDeleted](nil, "test", mapper.NewCopierMapper[NoDeleted, NoDeleted](), logger).
+		WithDataScope(datascope.NewPolicy())
+
+	ctx := viewer.WithContext(context.Background(), scopeViewer{
+		Context: viewer.NewNoopContext(),
+		uid:     7,
+		scopes:  []viewer.DataScope{{ScopeType: viewer.ScopeTypeSelf}},
+	})
+
+	scope := bsonV2.M{datascope.DefaultOwnerColumn: bsonV2.M{"$in": []interface{}{float64(7)}}}
+
+	doc, err := repo.scopedFilter(ctx, bsonV2.M{})
+	assert.NoError(t, err)
+	assert.Equal(t, scope, doc)
+
+	doc, err = repo.scopedFilter(ctx, bsonV2.M{"name": "tom"})
+	assert.NoError(t, err)
+	assert.Equal(t, bsonV2.M{"$and": bsonV2.A{bsonV2.M{"name": "tom"}, scope}}, doc)
+
+	// 缺少 Viewer 时拒绝访问，且不会访问数据库
+	_, err = repo.scopedFilter(context.Background(), bsonV2.M{})
+	assert.ErrorIs(t, err, datascope.ErrMissingViewer)
+
+	// 全部数据权限不追加条件
+	all := viewer.WithContext(context.Background(), scopeViewer{
+		Context: viewer.NewNoopContext(),
+		scopes:  []viewer.DataScope{{ScopeType: viewer.ScopeTypeAll}},
+	})
+	doc, err = repo.scopedFilter(all, bsonV2.M{"name": "tom"})
+	assert.NoError(t, err)
+	assert.Equal(t, bsonV2.M{"name": "tom"}, doc)
+
+	// Upsert 的更新同样追加数据权限条件
+	adapter := NewRepositoryAdapter(repo)
+	doc, scoped, err := adapter.upsertFilter(ctx, "id-1")
+	assert.NoError(t, err)
+	assert.True(t, scoped)
+	assert.Equal(t, bsonV2.M{"$and": bsonV2.A{bsonV2.M{"_id": "id-1"}, scope}}, doc)
+
+	doc, scoped, err = adapter.upsertFilter(all, "id-1")
+	assert.NoError(t, err)
+	assert.False(t, scoped)
+	assert.Equal(t, bsonV2.M{"_id": "id-1"}, doc)
 }
@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"time"
+)
+
+// DefaultTable 发件箱表的默认表名
+const DefaultTable = "outbox_events"
+
+// Status 事件的投递状态
+type Status int
+
+const (
+	// StatusPending 待投递（包括等待重试）
+	StatusPending Status = iota
+
+	// StatusPublished 已投递
+	StatusPublished
+
+	// StatusDead 超过最大重试次数，不再投递
+	StatusDead
+)
+
+// Operation 触发事件的写操作
+type Operation string
+
+const (
+	OperationCreated Operation = "created"
+	OperationUpdated Operation = "updated"
+	OperationDeleted Operation = "deleted"
+)
+
+// EventType 返回默认的事件类型：<aggregate>.<operation>，如 users.created
+func EventType(aggregate string, op Operation) string {
+	return aggregate + "." + string(op)
+}
+
+// Event 发件箱事件，与业务写入在同一事务中入库，由 Relay 异步投递
+type Event struct {
+	ID uint64 `json:"id"` // 自增 ID，同一聚合内按 ID 顺序投递
+
+	Aggregate   string `json:"aggregate"`              // 聚合类型，如表名
+	AggregateID string `json:"aggregate_id,omitempty"` // 聚合 ID，如主键；为空表示批量操作
+	Type        string `json:"type"`                   // 事件类型，如 users.created
+	Payload     []byte `json:"payload,omitempty"`      // 事件内容（JSON）
+
+	TenantID uint64 `json:"tenant_id,omitempty"`
+	TraceID  string `json:"trace_id,omitempty"`
+
+	Status        Status    `json:"status"`
+	Attempts      int       `json:"attempts"`                  // 已失败的投递次数
+	NextAttemptAt time.Time `json:"next_attempt_at,omitempty"` // 下次可投递的时间，零值表示立即投递
+	LastError     string    `json:"last_error,omitempty"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// Key 返回事件的排序键，同一聚合的事件按入库顺序投递
+func (e *Event) Key() string {
+	return e.Aggregate + "/" + e.AggregateID
+}
@@ -0,0 +1,5 @@
+module github.com/tx7do/go-crud/outbox
+
+go 1.24.6
+
+require github.com/go-kratos/kratos/v2 v2.9.2
@@ -0,0 +1,46 @@
+package outbox
+
+import (
+	"context"
+)
+
+// Publisher 事件发布器，如消息队列的生产者；返回错误时 Relay 按退避策略重试
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// PublisherFunc 函数形式的 Publisher
+type PublisherFunc func(ctx context.Context, event *Event) error
+
+func (f PublisherFunc) Publish(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// ChannelPublisher 将事件发送到进程内 channel，用于测试与单进程场景；channel 已满时阻塞直到 ctx 结束
+type ChannelPublisher struct {
+	ch chan *Event
+}
+
+var _ Publisher = (*ChannelPublisher)(nil)
+
+// NewChannelPublisher 创建容量为 size 的 ChannelPublisher
+func NewChannelPublisher(size int) *ChannelPublisher {
+	if size < 0 {
+		size = 0
+	}
+	return &ChannelPublisher{ch: make(chan *Event, size)}
+}
+
+func (p *ChannelPublisher) Publish(ctx context.Context, event *Event) error {
+	select {
+	case p.ch <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Events 返回接收事件的 channel
+func (p *ChannelPublisher) Events() <-chan *Event {
+	return p.ch
+}
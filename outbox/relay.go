@@ -0,0 +1,206 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	DefaultBatchSize    = 100
+	DefaultPollInterval = time.Second
+	DefaultBackoff      = time.Second
+	DefaultMaxBackoff   = 5 * time.Minute
+	DefaultMaxAttempts  = 10
+)
+
+// RelayOption Relay 的选项
+type RelayOption func(r *Relay)
+
+// WithBatchSize 设置单次轮询读取的最大事件数
+func WithBatchSize(n int) RelayOption {
+	return func(r *Relay) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
+}
+
+// WithPollInterval 设置轮询间隔
+func WithPollInterval(d time.Duration) RelayOption {
+	return func(r *Relay) {
+		if d > 0 {
+			r.pollInterval = d
+		}
+	}
+}
+
+// WithRetryBackoff 设置投递失败后的指数退避：第 n 次失败后等待 base * 2^(n-1)，不超过 max
+func WithRetryBackoff(base, max time.Duration) RelayOption {
+	return func(r *Relay) {
+		if base > 0 {
+			r.backoff = base
+		}
+		if max > 0 {
+			r.maxBackoff = max
+		}
+	}
+}
+
+// WithMaxAttempts 设置最大投递次数，达到后标记为 StatusDead；n <= 0 表示不限次数
+func WithMaxAttempts(n int) RelayOption {
+	return func(r *Relay) {
+		r.maxAttempts = n
+	}
+}
+
+// WithRelayLogger 设置 Relay 使用的日志
+func WithRelayLogger(logger log.Logger) RelayOption {
+	return func(r *Relay) {
+		if logger != nil {
+			r.log = log.NewHelper(log.With(logger, "module", "outbox"))
+		}
+	}
+}
+
+// Relay 轮询 Store 中的待投递事件，通过 Publisher 投递后标记为已投递：
+//   - 同一聚合（Aggregate + AggregateID）的事件按 ID 顺序投递，前一条失败或等待重试时，后续事件不会越过它投递；
+//   - 投递失败按指数退避重试，达到最大次数后标记为 StatusDead，同一聚合的后续事件继续投递；
+//   - 投递语义为至少一次，消费方需按事件 ID 去重；多个 Relay 实例同时轮询同一张表时可能重复投递。
+type Relay struct {
+	store     Store
+	publisher Publisher
+
+	batchSize    int
+	pollInterval time.Duration
+	backoff      time.Duration
+	maxBackoff   time.Duration
+	maxAttempts  int
+
+	now func() time.Time
+	log *log.Helper
+}
+
+// NewRelay 创建 Relay
+func NewRelay(store Store, publisher Publisher, opts ...RelayOption) *Relay {
+	r := &Relay{
+		store:        store,
+		publisher:    publisher,
+		batchSize:    DefaultBatchSize,
+		pollInterval: DefaultPollInterval,
+		backoff:      DefaultBackoff,
+		maxBackoff:   DefaultMaxBackoff,
+		maxAttempts:  DefaultMaxAttempts,
+		now:          time.Now,
+		log:          log.NewHelper(log.With(log.GetLogger(), "module", "outbox")),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(r)
+		}
+	}
+	return r
+}
+
+// Run 按轮询间隔持续投递，直到 ctx 结束；单次轮询的错误只记录日志
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := r.Process(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				r.log.Errorf("process outbox events failed: %v", err)
+				break
+			}
+			// 处理满一批时立即继续，避免积压
+			if n < r.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Process 执行一次轮询，返回本次投递（成功或失败）的事件数，因同一聚合的前序事件失败而跳过的事件不计入
+func (r *Relay) Process(ctx context.Context) (int, error) {
+	if r.store == nil {
+		return 0, errors.New("outbox store is nil")
+	}
+	if r.publisher == nil {
+		return 0, errors.New("outbox publisher is nil")
+	}
+
+	now := r.now()
+	events, err := r.store.Fetch(ctx, now, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	blocked := make(map[string]struct{})
+	for _, e := range events {
+		if err = ctx.Err(); err != nil {
+			return processed, err
+		}
+
+		key := e.Key()
+		if _, ok := blocked[key]; ok {
+			continue
+		}
+		if e.NextAttemptAt.After(now) {
+			blocked[key] = struct{}{}
+			continue
+		}
+
+		processed++
+		if pubErr := r.publisher.Publish(ctx, e); pubErr != nil {
+			blocked[key] = struct{}{}
+			if err = r.fail(ctx, e, now, pubErr); err != nil {
+				return processed, err
+			}
+			continue
+		}
+
+		if err = r.store.MarkPublished(ctx, e.ID); err != nil {
+			return processed, err
+		}
+	}
+
+	return processed, nil
+}
+
+// fail 记录投递失败，达到最大次数时标记为 StatusDead
+func (r *Relay) fail(ctx context.Context, e *Event, now time.Time, pubErr error) error {
+	attempts := e.Attempts + 1
+	if r.maxAttempts > 0 && attempts >= r.maxAttempts {
+		r.log.Errorf("outbox event %d (%s) dead after %d attempts: %v", e.ID, e.Type, attempts, pubErr)
+		return r.store.MarkDead(ctx, e.ID, attempts, pubErr.Error())
+	}
+
+	r.log.Warnf("publish outbox event %d (%s) failed, attempt %d: %v", e.ID, e.Type, attempts, pubErr)
+	return r.store.MarkFailed(ctx, e.ID, attempts, now.Add(r.retryDelay(attempts)), pubErr.Error())
+}
+
+// retryDelay 返回第 attempts 次失败后的等待时间
+func (r *Relay) retryDelay(attempts int) time.Duration {
+	d := r.backoff
+	for i := 1; i < attempts && d < r.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > r.maxBackoff {
+		d = r.maxBackoff
+	}
+	return d
+}
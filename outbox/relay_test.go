@@ -0,0 +1,261 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStore 内存中的 Store
+type memoryStore struct {
+	mu     sync.Mutex
+	nextID uint64
+	events map[uint64]*Event
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{events: map[uint64]*Event{}}
+}
+
+func (s *memoryStore) Enqueue(_ context.Context, events ...*Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range events {
+		s.nextID++
+		e.ID = s.nextID
+		s.events[e.ID] = e
+	}
+	return nil
+}
+
+func (s *memoryStore) Fetch(_ context.Context, now time.Time, limit int) ([]*Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pending []*Event
+	for _, e := range s.events {
+		if e.Status == StatusPending {
+			cp := *e
+			pending = append(pending, &cp)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].ID < pending[j].ID })
+
+	// 跳过未到期的事件及同一聚合中排在其后的事件
+	var out []*Event
+	blocked := map[string]struct{}{}
+	for _, e := range pending {
+		if _, ok := blocked[e.Key()]; ok {
+			continue
+		}
+		if e.NextAttemptAt.After(now) {
+			blocked[e.Key()] = struct{}{}
+			continue
+		}
+		out = append(out, e)
+	}
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *memoryStore) MarkPublished(_ context.Context, id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[id].Status = StatusPublished
+	return nil
+}
+
+func (s *memoryStore) MarkFailed(_ context.Context, id uint64, attempts int, next time.Time, lastErr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.events[id]
+	e.Attempts, e.NextAttemptAt, e.LastError = attempts, next, lastErr
+	return nil
+}
+
+func (s *memoryStore) MarkDead(_ context.Context, id uint64, attempts int, lastErr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.events[id]
+	e.Status, e.Attempts, e.LastError = StatusDead, attempts, lastErr
+	return nil
+}
+
+func drain(p *ChannelPublisher) []string {
+	var out []string
+	for {
+		select {
+		case e := <-p.Events():
+			out = append(out, e.Key()+":"+e.Type)
+		default:
+			return out
+		}
+	}
+}
+
+func TestRelay_OrderingAndRetry(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	_ = store.Enqueue(ctx,
+		&Event{Aggregate: "users", AggregateID: "1", Type: "users.created"},
+		&Event{Aggregate: "users", AggregateID: "2", Type: "users.created"},
+		&Event{Aggregate: "users", AggregateID: "1", Type: "users.updated"},
+	)
+
+	ch := NewChannelPublisher(10)
+	failed := false
+	pub := PublisherFunc(func(ctx context.Context, e *Event) error {
+		if e.AggregateID == "1" && !failed {
+			failed = true
+			return errors.New("broker unavailable")
+		}
+		return ch.Publish(ctx, e)
+	})
+
+	now := time.Unix(1000, 0)
+	r := NewRelay(store, pub, WithRetryBackoff(time.Second, time.Minute))
+	r.now = func() time.Time { return now }
+
+	// 聚合 users/1 的首条事件失败，后续事件不得越过它；其它聚合不受影响
+	if _, err := r.Process(ctx); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if got := drain(ch); len(got) != 1 || got[0] != "users/2:users.created" {
+		t.Fatalf("unexpected published events: %v", got)
+	}
+	if e := store.events[1]; e.Attempts != 1 || !e.NextAttemptAt.Equal(now.Add(time.Second)) || e.LastError == "" {
+		t.Fatalf("failure not recorded: %+v", e)
+	}
+
+	// 未到重试时间
+	if _, err := r.Process(ctx); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if got := drain(ch); len(got) != 0 {
+		t.Fatalf("event published before backoff elapsed: %v", got)
+	}
+
+	now = now.Add(time.Second)
+	if _, err := r.Process(ctx); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	if got := drain(ch); len(got) != 2 || got[0] != "users/1:users.created" || got[1] != "users/1:users.updated" {
+		t.Fatalf("unexpected order after retry: %v", got)
+	}
+	for id, e := range store.events {
+		if e.Status != StatusPublished {
+			t.Fatalf("event %d not published: %+v", id, e)
+		}
+	}
+}
+
+func TestRelay_BackoffDoesNotStarve(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	for i := 0; i < 3; i++ {
+		_ = store.Enqueue(ctx, &Event{Aggregate: "users", AggregateID: "1", Type: "users.updated"})
+	}
+	_ = store.Enqueue(ctx, &Event{Aggregate: "users", AggregateID: "2", Type: "users.created"})
+
+	ch := NewChannelPublisher(10)
+	pub := PublisherFunc(func(ctx context.Context, e *Event) error {
+		if e.AggregateID == "1" {
+			return errors.New("broker unavailable")
+		}
+		return ch.Publish(ctx, e)
+	})
+
+	now := time.Unix(1000, 0)
+	r := NewRelay(store, pub, WithBatchSize(2), WithRetryBackoff(time.Minute, time.Minute))
+	r.now = func() time.Time { return now }
+
+	// 首条事件失败，同一聚合的后续事件被跳过
+	if n, err := r.Process(ctx); err != nil || n != 1 {
+		t.Fatalf("process: %d, %v", n, err)
+	}
+
+	// 等待重试的聚合不占用批次，其它聚合的事件得以投递
+	if n, err := r.Process(ctx); err != nil || n != 1 {
+		t.Fatalf("process: %d, %v", n, err)
+	}
+	if got := drain(ch); len(got) != 1 || got[0] != "users/2:users.created" {
+		t.Fatalf("unexpected published events: %v", got)
+	}
+
+	// 没有到期事件时不再处理任何事件，Run 不会空转
+	if n, err := r.Process(ctx); err != nil || n != 0 {
+		t.Fatalf("process: %d, %v", n, err)
+	}
+}
+
+func TestRelay_MaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	_ = store.Enqueue(ctx,
+		&Event{Aggregate: "orders", AggregateID: "9", Type: "bad"},
+		&Event{Aggregate: "orders", AggregateID: "9", Type: "good"},
+	)
+
+	ch := NewChannelPublisher(10)
+	pub := PublisherFunc(func(ctx context.Context, e *Event) error {
+		if e.Type == "bad" {
+			return errors.New("rejected")
+		}
+		return ch.Publish(ctx, e)
+	})
+
+	now := time.Unix(1000, 0)
+	r := NewRelay(store, pub, WithMaxAttempts(2), WithRetryBackoff(time.Second, time.Second))
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := r.Process(ctx); err != nil {
+			t.Fatalf("process: %v", err)
+		}
+		now = now.Add(time.Second)
+	}
+
+	if e := store.events[1]; e.Status != StatusDead || e.Attempts != 2 {
+		t.Fatalf("event should be dead: %+v", e)
+	}
+	if got := drain(ch); len(got) != 1 || got[0] != "orders/9:good" {
+		t.Fatalf("following event should be published after dead letter: %v", got)
+	}
+}
+
+func TestRelay_RetryDelay(t *testing.T) {
+	r := NewRelay(nil, nil, WithRetryBackoff(time.Second, 10*time.Second))
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 5: 10 * time.Second} {
+		if got := r.retryDelay(attempts); got != want {
+			t.Fatalf("retryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestRelay_Run(t *testing.T) {
+	store := newMemoryStore()
+	_ = store.Enqueue(context.Background(), &Event{Aggregate: "users", AggregateID: "1", Type: "users.deleted"})
+
+	ch := NewChannelPublisher(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- NewRelay(store, ch, WithPollInterval(10*time.Millisecond)).Run(ctx) }()
+
+	select {
+	case e := <-ch.Events():
+		if e.Type != "users.deleted" {
+			t.Fatalf("unexpected event: %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not published")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
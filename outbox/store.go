@@ -0,0 +1,25 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// Store 发件箱存储，由各 ORM 实现（如 gorm.OutboxStore、entgo.OutboxStore）
+type Store interface {
+	// Enqueue 写入待投递事件；ctx 中存在事务时应使用同一事务
+	Enqueue(ctx context.Context, events ...*Event) error
+
+	// Fetch 按 ID 升序返回最多 limit 条到期的待投递事件：不返回 NextAttemptAt 晚于 now 的事件，
+	// 也不返回同一聚合中排在这类事件之后的事件，未到期的事件不占用批次
+	Fetch(ctx context.Context, now time.Time, limit int) ([]*Event, error)
+
+	// MarkPublished 标记事件已投递
+	MarkPublished(ctx context.Context, id uint64) error
+
+	// MarkFailed 记录投递失败，事件保持待投递状态，到 nextAttemptAt 后重试
+	MarkFailed(ctx context.Context, id uint64, attempts int, nextAttemptAt time.Time, lastErr string) error
+
+	// MarkDead 标记事件不再投递
+	MarkDead(ctx context.Context, id uint64, attempts int, lastErr string) error
+}
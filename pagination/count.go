@@ -0,0 +1,129 @@
+package pagination
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CountMode 列表查询的总数统计方式
+type CountMode int32
+
+const (
+	// CountModeExact 精确统计（默认），执行 COUNT(*)
+	CountModeExact CountMode = iota
+
+	// CountModeSkip 不统计总数，分页元数据中不返回 total/total_pages
+	CountModeSkip
+
+	// CountModeEstimated 使用数据库的统计信息估算总数，数据库不支持时退化为精确统计
+	CountModeEstimated
+
+	// CountModeCapped 最多统计到 Cap 条（LIMIT Cap+1），超过上限时返回 Cap 并标记为估算值，表示“至少 Cap 条”
+	CountModeCapped
+)
+
+// DefaultCountCap CountModeCapped 未指定上限时使用的默认值
+const DefaultCountCap int64 = 10000
+
+var countModeNames = map[CountMode]string{
+	CountModeExact:     "exact",
+	CountModeSkip:      "skip",
+	CountModeEstimated: "estimated",
+	CountModeCapped:    "capped",
+}
+
+func (m CountMode) String() string {
+	if s, ok := countModeNames[m]; ok {
+		return s
+	}
+	return fmt.Sprintf("CountMode(%d)", int32(m))
+}
+
+// MarshalText 以名称形式序列化
+func (m CountMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText 从名称解析统计方式
+func (m *CountMode) UnmarshalText(text []byte) error {
+	s := strings.ToLower(strings.TrimSpace(string(text)))
+	for k, v := range countModeNames {
+		if v == s {
+			*m = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown count mode %q", s)
+}
+
+// CountStrategy 列表查询的总数统计策略
+type CountStrategy struct {
+	Mode CountMode
+
+	// Cap CountModeCapped 的统计上限，<= 0 时使用 DefaultCountCap
+	Cap int64
+}
+
+// Limit 返回 CountModeCapped 实际使用的统计上限
+func (s CountStrategy) Limit() int64 {
+	if s.Cap <= 0 {
+		return DefaultCountCap
+	}
+	return s.Cap
+}
+
+type countStrategyKey struct{}
+
+// WithCountStrategy 在 context 中设置本次列表查询的总数统计策略
+func WithCountStrategy(ctx context.Context, strategy CountStrategy) context.Context {
+	return context.WithValue(ctx, countStrategyKey{}, strategy)
+}
+
+// CountStrategyFromContext 返回 context 中的总数统计策略，未设置时为精确统计
+func CountStrategyFromContext(ctx context.Context) CountStrategy {
+	if ctx == nil {
+		return CountStrategy{}
+	}
+	if s, ok := ctx.Value(countStrategyKey{}).(CountStrategy); ok {
+		return s
+	}
+	return CountStrategy{}
+}
+
+// Count 总数统计结果
+type Count struct {
+	Total int64
+
+	// Mode 实际使用的统计方式（估算不可用时会退化为 CountModeExact）
+	Mode CountMode
+
+	// IsEstimate Total 是否为估算值或下限
+	IsEstimate bool
+}
+
+// ExactCount 精确统计结果
+func ExactCount(total int64) Count {
+	return Count{Total: total, Mode: CountModeExact}
+}
+
+// SkippedCount 未统计总数的结果
+func SkippedCount() Count {
+	return Count{Mode: CountModeSkip}
+}
+
+// EstimatedCount 估算的统计结果
+func EstimatedCount(total int64) Count {
+	if total < 0 {
+		total = 0
+	}
+	return Count{Total: total, Mode: CountModeEstimated, IsEstimate: true}
+}
+
+// CappedCount 根据 LIMIT limit+1 子查询统计出的条数 n 生成统计结果，n 超过 limit 时返回 limit 并标记为估算值
+func CappedCount(n, limit int64) Count {
+	if n > limit {
+		return Count{Total: limit, Mode: CountModeCapped, IsEstimate: true}
+	}
+	return Count{Total: n, Mode: CountModeCapped}
+}
@@ -0,0 +1,70 @@
+package pagination_test
+
+import (
+	"context"
+	"testing"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/paginator"
+)
+
+func TestCountStrategyFromContext(t *testing.T) {
+	if s := pagination.CountStrategyFromContext(context.Background()); s.Mode != pagination.CountModeExact {
+		t.Fatalf("default mode should be exact, got %v", s.Mode)
+	}
+
+	ctx := pagination.WithCountStrategy(context.Background(), pagination.CountStrategy{Mode: pagination.CountModeCapped})
+	s := pagination.CountStrategyFromContext(ctx)
+	if s.Mode != pagination.CountModeCapped || s.Limit() != pagination.DefaultCountCap {
+		t.Fatalf("unexpected strategy: %+v", s)
+	}
+}
+
+func TestCountMode_Text(t *testing.T) {
+	var m pagination.CountMode
+	if err := m.UnmarshalText([]byte("Estimated")); err != nil || m != pagination.CountModeEstimated {
+		t.Fatalf("unmarshal: %v, %v", m, err)
+	}
+	if b, _ := pagination.CountModeSkip.MarshalText(); string(b) != "skip" {
+		t.Fatalf("marshal: %s", b)
+	}
+	if err := m.UnmarshalText([]byte("bogus")); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+}
+
+func TestCappedCount(t *testing.T) {
+	if c := pagination.CappedCount(101, 100); c.Total != 100 || !c.IsEstimate {
+		t.Fatalf("over cap: %+v", c)
+	}
+	if c := pagination.CappedCount(42, 100); c.Total != 42 || c.IsEstimate || c.Mode != pagination.CountModeCapped {
+		t.Fatalf("under cap: %+v", c)
+	}
+}
+
+func TestNewPagingResultWithCount_Skip(t *testing.T) {
+	items := []*paginationV1.Sorting{{Field: "a"}, {Field: "b"}}
+	res := pagination.NewPagingResultWithCount(items, pagination.SkippedCount(), paginator.NewPagePaginator(2, 2))
+
+	if res.CountMode != pagination.CountModeSkip || res.Total != 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	m := res.Meta
+	if m.Total != nil || m.TotalPages != nil {
+		t.Fatalf("total fields should not be set: %v", m)
+	}
+	if m.GetCurrentPage().GetValue() != 2 || m.GetPageSize() != 2 || m.GetCurrentSize() != 2 {
+		t.Fatalf("unexpected page meta: %v", m)
+	}
+}
+
+func TestNewPagingResultWithCount_Estimated(t *testing.T) {
+	res := pagination.NewPagingResultWithCount([]*paginationV1.Sorting{}, pagination.EstimatedCount(1000), paginator.NewOffsetPaginator(0, 10))
+	if !res.IsEstimate || res.CountMode != pagination.CountModeEstimated || res.Total != 1000 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if res.Meta.GetTotal().GetValue() != 1000 || res.Meta.GetTotalPages().GetValue() != 100 {
+		t.Fatalf("unexpected meta: %v", res.Meta)
+	}
+}
@@ -0,0 +1,348 @@
+package pagination
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+var (
+	// ErrCursorMalformed 游标格式错误（非本系统签发或已损坏）
+	ErrCursorMalformed = errors.New("pagination cursor is malformed")
+	// ErrCursorTampered 游标签名校验失败（内容被篡改）
+	ErrCursorTampered = errors.New("pagination cursor signature mismatch")
+	// ErrCursorExpired 游标已过期
+	ErrCursorExpired = errors.New("pagination cursor has expired")
+	// ErrCursorScopeMismatch 游标签发时的过滤/排序/字段掩码与当前请求不一致
+	ErrCursorScopeMismatch = errors.New("pagination cursor was issued for a different query")
+	// ErrCursorUnknownKey 游标使用了未知（或已下线）的密钥签名
+	ErrCursorUnknownKey = errors.New("pagination cursor signed with unknown key")
+)
+
+// CursorCodec 游标编解码器，负责将分页器内部的游标数据与对外的不透明 token 互相转换。
+// scope 为游标绑定的查询范围（见 CursorScope），解码时 scope 不一致应返回 ErrCursorScopeMismatch。
+type CursorCodec interface {
+	Encode(payload, scope []byte) (string, error)
+	Decode(token string, scope []byte) ([]byte, error)
+}
+
+// CursorScope 计算游标绑定的查询范围摘要（过滤条件、排序与字段掩码）
+func CursorScope(filter *paginationV1.FilterExpr, sortings []*paginationV1.Sorting, fieldMask *fieldmaskpb.FieldMask) []byte {
+	h := sha256.New()
+
+	if filter != nil {
+		b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(filter)
+		h.Write(b)
+	}
+	h.Write([]byte{0})
+
+	for _, s := range sortings {
+		if s == nil || s.GetField() == "" {
+			continue
+		}
+		_, _ = fmt.Fprintf(h, "%s:%d;", s.GetField(), s.GetDirection())
+	}
+	h.Write([]byte{0})
+
+	if paths := fieldMask.GetPaths(); len(paths) > 0 {
+		sorted := append([]string(nil), paths...)
+		sort.Strings(sorted)
+		h.Write([]byte(strings.Join(sorted, ",")))
+	}
+
+	return h.Sum(nil)
+}
+
+// CursorScopeRequest 可计算游标查询范围的分页请求（PagingRequest、PaginationRequest 均满足）
+type CursorScopeRequest interface {
+	GetQuery() string
+	GetFilter() string
+	GetFilterExpr() *paginationV1.FilterExpr
+	GetOrderBy() string
+	GetSorting() []*paginationV1.Sorting
+	GetFieldMask() *fieldmaskpb.FieldMask
+}
+
+// CursorScopeOf 计算分页请求的查询范围摘要，除 CursorScope 的内容外还包含字符串形式的过滤与排序条件
+func CursorScopeOf(req CursorScopeRequest) []byte {
+	if req == nil {
+		return CursorScope(nil, nil, nil)
+	}
+
+	h := sha256.New()
+	for _, s := range []string{req.GetQuery(), req.GetFilter(), req.GetOrderBy()} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	h.Write(CursorScope(req.GetFilterExpr(), req.GetSorting(), req.GetFieldMask()))
+	return h.Sum(nil)
+}
+
+const (
+	cursorVersionPlain  byte = 1
+	cursorVersionSigned byte = 2
+
+	cursorFlagEncrypted byte = 1 << 0
+
+	// cursorScopeSize token 中保存的 scope 摘要长度
+	cursorScopeSize = 16
+	// cursorMACSize HMAC-SHA256 签名长度
+	cursorMACSize = sha256.Size
+)
+
+var cursorEncoding = base64.RawURLEncoding
+
+// scopeDigest 截断的 scope 摘要
+func scopeDigest(scope []byte) []byte {
+	sum := sha256.Sum256(scope)
+	return sum[:cursorScopeSize]
+}
+
+var defaultCursorCodec CursorCodec = NewPlainCursorCodec()
+
+// DefaultCursorCodec 返回默认的游标编解码器（不签名）
+func DefaultCursorCodec() CursorCodec {
+	return defaultCursorCodec
+}
+
+// PlainCursorCodec 不签名的游标编解码器，仅做 base64 编码并校验 scope。
+// 客户端可以伪造其内容，生产环境应使用 SignedCursorCodec。
+type PlainCursorCodec struct{}
+
+func NewPlainCursorCodec() *PlainCursorCodec {
+	return &PlainCursorCodec{}
+}
+
+// Encode 格式：version(1) | scope(16) | payload
+func (c *PlainCursorCodec) Encode(payload, scope []byte) (string, error) {
+	buf := make([]byte, 0, 1+cursorScopeSize+len(payload))
+	buf = append(buf, cursorVersionPlain)
+	buf = append(buf, scopeDigest(scope)...)
+	buf = append(buf, payload...)
+	return cursorEncoding.EncodeToString(buf), nil
+}
+
+func (c *PlainCursorCodec) Decode(token string, scope []byte) ([]byte, error) {
+	b, err := cursorEncoding.DecodeString(token)
+	if err != nil || len(b) < 1+cursorScopeSize || b[0] != cursorVersionPlain {
+		return nil, ErrCursorMalformed
+	}
+	if !bytes.Equal(b[1:1+cursorScopeSize], scopeDigest(scope)) {
+		return nil, ErrCursorScopeMismatch
+	}
+	return b[1+cursorScopeSize:], nil
+}
+
+// CursorKey 游标签名密钥
+type CursorKey struct {
+	// ID 密钥标识，写入 token 用于轮换时选择验证密钥（不超过 255 字节）
+	ID string
+	// Secret 密钥内容，建议至少 32 字节
+	Secret []byte
+}
+
+type derivedCursorKey struct {
+	id      string
+	signKey []byte
+	encKey  []byte
+}
+
+func deriveCursorKey(k CursorKey) derivedCursorKey {
+	derive := func(label string) []byte {
+		m := hmac.New(sha256.New, k.Secret)
+		m.Write([]byte(label))
+		return m.Sum(nil)
+	}
+	return derivedCursorKey{
+		id:      k.ID,
+		signKey: derive("go-crud/cursor/sign"),
+		encKey:  derive("go-crud/cursor/encrypt"),
+	}
+}
+
+// SignedCursorOption SignedCursorCodec 的可选配置
+type SignedCursorOption func(*SignedCursorCodec)
+
+// WithCursorTTL 设置游标有效期，<= 0 表示永不过期
+func WithCursorTTL(ttl time.Duration) SignedCursorOption {
+	return func(c *SignedCursorCodec) {
+		c.ttl = ttl
+	}
+}
+
+// WithCursorEncryption 启用 AES-GCM 加密，客户端无法读取游标内容
+func WithCursorEncryption() SignedCursorOption {
+	return func(c *SignedCursorCodec) {
+		c.encrypt = true
+	}
+}
+
+// WithCursorClock 设置时钟（用于测试）
+func WithCursorClock(now func() time.Time) SignedCursorOption {
+	return func(c *SignedCursorCodec) {
+		if now != nil {
+			c.now = now
+		}
+	}
+}
+
+// SignedCursorCodec 使用 HMAC-SHA256 签名（可选 AES-GCM 加密）的游标编解码器。
+// keys 中第一个为当前签名密钥，其余仅用于验证，以支持密钥轮换。
+type SignedCursorCodec struct {
+	keys    []derivedCursorKey
+	ttl     time.Duration
+	encrypt bool
+	now     func() time.Time
+}
+
+func NewSignedCursorCodec(keys []CursorKey, opts ...SignedCursorOption) (*SignedCursorCodec, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one cursor key is required")
+	}
+
+	c := &SignedCursorCodec{
+		now: time.Now,
+	}
+	seen := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		if len(k.Secret) == 0 {
+			return nil, fmt.Errorf("cursor key %q has empty secret", k.ID)
+		}
+		if len(k.ID) > 255 {
+			return nil, fmt.Errorf("cursor key id %q is too long", k.ID)
+		}
+		if _, ok := seen[k.ID]; ok {
+			return nil, fmt.Errorf("duplicate cursor key id %q", k.ID)
+		}
+		seen[k.ID] = struct{}{}
+		c.keys = append(c.keys, deriveCursorKey(k))
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// Encode 格式：version(1) | flags(1) | len(kid)(1) | kid | issued_at(8) | scope(16) | body | hmac(32)
+// 加密时 body 为 nonce | AES-GCM 密文，附加数据为 body 之前的头部。
+func (c *SignedCursorCodec) Encode(payload, scope []byte) (string, error) {
+	key := c.keys[0]
+
+	var flags byte
+	if c.encrypt {
+		flags |= cursorFlagEncrypted
+	}
+
+	header := make([]byte, 0, 3+len(key.id)+8+cursorScopeSize)
+	header = append(header, cursorVersionSigned, flags, byte(len(key.id)))
+	header = append(header, key.id...)
+	header = binary.BigEndian.AppendUint64(header, uint64(c.now().Unix()))
+	header = append(header, scopeDigest(scope)...)
+
+	body := payload
+	if c.encrypt {
+		aead, err := newCursorAEAD(key.encKey)
+		if err != nil {
+			return "", err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+			return "", err
+		}
+		body = aead.Seal(nonce, nonce, payload, header)
+	}
+
+	buf := append(header, body...)
+	m := hmac.New(sha256.New, key.signKey)
+	m.Write(buf)
+	buf = m.Sum(buf)
+
+	return cursorEncoding.EncodeToString(buf), nil
+}
+
+func (c *SignedCursorCodec) Decode(token string, scope []byte) ([]byte, error) {
+	b, err := cursorEncoding.DecodeString(token)
+	if err != nil || len(b) < 3 || b[0] != cursorVersionSigned {
+		return nil, ErrCursorMalformed
+	}
+
+	flags := b[1]
+	kidLen := int(b[2])
+	headerLen := 3 + kidLen + 8 + cursorScopeSize
+	if len(b) < headerLen+cursorMACSize {
+		return nil, ErrCursorMalformed
+	}
+
+	kid := string(b[3 : 3+kidLen])
+	var key *derivedCursorKey
+	for i := range c.keys {
+		if c.keys[i].id == kid {
+			key = &c.keys[i]
+			break
+		}
+	}
+	if key == nil {
+		return nil, ErrCursorUnknownKey
+	}
+
+	signed, mac := b[:len(b)-cursorMACSize], b[len(b)-cursorMACSize:]
+	m := hmac.New(sha256.New, key.signKey)
+	m.Write(signed)
+	if !hmac.Equal(mac, m.Sum(nil)) {
+		return nil, ErrCursorTampered
+	}
+
+	header := signed[:headerLen]
+	issuedAt := time.Unix(int64(binary.BigEndian.Uint64(header[3+kidLen:])), 0)
+	if c.ttl > 0 && c.now().After(issuedAt.Add(c.ttl)) {
+		return nil, ErrCursorExpired
+	}
+
+	if !bytes.Equal(header[3+kidLen+8:], scopeDigest(scope)) {
+		return nil, ErrCursorScopeMismatch
+	}
+
+	body := signed[headerLen:]
+	if flags&cursorFlagEncrypted == 0 {
+		return body, nil
+	}
+
+	aead, err := newCursorAEAD(key.encKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) < aead.NonceSize() {
+		return nil, ErrCursorMalformed
+	}
+	payload, err := aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], header)
+	if err != nil {
+		return nil, ErrCursorTampered
+	}
+	return payload, nil
+}
+
+func newCursorAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,189 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+var (
+	testCursorKey1 = CursorKey{ID: "k1", Secret: []byte("0123456789abcdef0123456789abcdef")}
+	testCursorKey2 = CursorKey{ID: "k2", Secret: []byte("fedcba9876543210fedcba9876543210")}
+)
+
+func TestCursorScope(t *testing.T) {
+	filter := &paginationV1.FilterExpr{
+		Type:       paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{{Field: "status", Op: paginationV1.Operator_EQ}},
+	}
+	sortings := []*paginationV1.Sorting{{Field: "id", Direction: paginationV1.Sorting_DESC}}
+
+	a := CursorScope(filter, sortings, &fieldmaskpb.FieldMask{Paths: []string{"name", "id"}})
+	b := CursorScope(filter, sortings, &fieldmaskpb.FieldMask{Paths: []string{"id", "name"}})
+	if !bytes.Equal(a, b) {
+		t.Fatal("field mask order should not affect scope")
+	}
+
+	if bytes.Equal(a, CursorScope(filter, []*paginationV1.Sorting{{Field: "id"}}, &fieldmaskpb.FieldMask{Paths: []string{"id", "name"}})) {
+		t.Fatal("sorting direction should affect scope")
+	}
+	if bytes.Equal(a, CursorScope(nil, sortings, &fieldmaskpb.FieldMask{Paths: []string{"id", "name"}})) {
+		t.Fatal("filter should affect scope")
+	}
+}
+
+func TestCursorScopeOf(t *testing.T) {
+	orderBy := "-id"
+	a := CursorScopeOf(&paginationV1.PagingRequest{FilteringType: &paginationV1.PagingRequest_Query{Query: `{"status":"on"}`}})
+	b := CursorScopeOf(&paginationV1.PaginationRequest{FilteringType: &paginationV1.PaginationRequest_Query{Query: `{"status":"on"}`}})
+	if !bytes.Equal(a, b) {
+		t.Fatal("same query should produce same scope for both request types")
+	}
+
+	if bytes.Equal(a, CursorScopeOf(&paginationV1.PagingRequest{FilteringType: &paginationV1.PagingRequest_Query{Query: `{"status":"off"}`}})) {
+		t.Fatal("query should affect scope")
+	}
+	if bytes.Equal(a, CursorScopeOf(&paginationV1.PagingRequest{FilteringType: &paginationV1.PagingRequest_Query{Query: `{"status":"on"}`}, OrderBy: &orderBy})) {
+		t.Fatal("order by should affect scope")
+	}
+}
+
+func TestPlainCursorCodec(t *testing.T) {
+	c := NewPlainCursorCodec()
+	scope := []byte("scope")
+
+	token, err := c.Encode([]byte("payload"), scope)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	got, err := c.Decode(token, scope)
+	if err != nil || string(got) != "payload" {
+		t.Fatalf("decode failed: %q, %v", got, err)
+	}
+
+	if _, err = c.Decode(token, []byte("other")); !errors.Is(err, ErrCursorScopeMismatch) {
+		t.Fatalf("expected ErrCursorScopeMismatch, got %v", err)
+	}
+	if _, err = c.Decode("%%%", scope); !errors.Is(err, ErrCursorMalformed) {
+		t.Fatalf("expected ErrCursorMalformed, got %v", err)
+	}
+}
+
+func TestSignedCursorCodec_RoundTrip(t *testing.T) {
+	for _, encrypt := range []bool{false, true} {
+		var opts []SignedCursorOption
+		if encrypt {
+			opts = append(opts, WithCursorEncryption())
+		}
+		c, err := NewSignedCursorCodec([]CursorKey{testCursorKey1}, opts...)
+		if err != nil {
+			t.Fatalf("new codec failed: %v", err)
+		}
+
+		token, err := c.Encode([]byte(`{"v":[1]}`), []byte("scope"))
+		if err != nil {
+			t.Fatalf("encode failed: %v", err)
+		}
+
+		raw, _ := base64.RawURLEncoding.DecodeString(token)
+		if bytes.Contains(raw, []byte(`{"v":[1]}`)) == encrypt {
+			t.Fatalf("encrypt=%v: unexpected payload visibility", encrypt)
+		}
+
+		got, err := c.Decode(token, []byte("scope"))
+		if err != nil || string(got) != `{"v":[1]}` {
+			t.Fatalf("encrypt=%v: decode failed: %q, %v", encrypt, got, err)
+		}
+
+		if _, err = c.Decode(token, []byte("other")); !errors.Is(err, ErrCursorScopeMismatch) {
+			t.Fatalf("encrypt=%v: expected ErrCursorScopeMismatch, got %v", encrypt, err)
+		}
+	}
+}
+
+func TestSignedCursorCodec_Tampered(t *testing.T) {
+	c, _ := NewSignedCursorCodec([]CursorKey{testCursorKey1})
+
+	token, _ := c.Encode([]byte(`{"v":[1]}`), nil)
+	raw, _ := base64.RawURLEncoding.DecodeString(token)
+
+	// 修改 payload 中的一个字节
+	raw[len(raw)-cursorMACSize-2] ^= 0xff
+	if _, err := c.Decode(base64.RawURLEncoding.EncodeToString(raw), nil); !errors.Is(err, ErrCursorTampered) {
+		t.Fatalf("expected ErrCursorTampered, got %v", err)
+	}
+
+	// 其他密钥签发的 token
+	forged, _ := NewSignedCursorCodec([]CursorKey{{ID: "k1", Secret: []byte("guess")}})
+	token, _ = forged.Encode([]byte(`{"v":[1]}`), nil)
+	if _, err := c.Decode(token, nil); !errors.Is(err, ErrCursorTampered) {
+		t.Fatalf("expected ErrCursorTampered, got %v", err)
+	}
+
+	// 未签名的 token
+	token, _ = NewPlainCursorCodec().Encode([]byte(`{"v":[1]}`), nil)
+	if _, err := c.Decode(token, nil); !errors.Is(err, ErrCursorMalformed) {
+		t.Fatalf("expected ErrCursorMalformed, got %v", err)
+	}
+}
+
+func TestSignedCursorCodec_Expired(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c, _ := NewSignedCursorCodec([]CursorKey{testCursorKey1},
+		WithCursorTTL(time.Minute),
+		WithCursorClock(func() time.Time { return now }),
+	)
+
+	token, _ := c.Encode([]byte("p"), nil)
+
+	now = now.Add(30 * time.Second)
+	if _, err := c.Decode(token, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := c.Decode(token, nil); !errors.Is(err, ErrCursorExpired) {
+		t.Fatalf("expected ErrCursorExpired, got %v", err)
+	}
+}
+
+func TestSignedCursorCodec_KeyRotation(t *testing.T) {
+	oldCodec, _ := NewSignedCursorCodec([]CursorKey{testCursorKey1})
+	token, _ := oldCodec.Encode([]byte("p"), nil)
+
+	// 新密钥签名，旧密钥仍可验证
+	rotated, _ := NewSignedCursorCodec([]CursorKey{testCursorKey2, testCursorKey1})
+	if got, err := rotated.Decode(token, nil); err != nil || string(got) != "p" {
+		t.Fatalf("old token should still verify: %q, %v", got, err)
+	}
+
+	newToken, _ := rotated.Encode([]byte("p"), nil)
+	if _, err := oldCodec.Decode(newToken, nil); !errors.Is(err, ErrCursorUnknownKey) {
+		t.Fatalf("expected ErrCursorUnknownKey, got %v", err)
+	}
+
+	// 旧密钥下线
+	retired, _ := NewSignedCursorCodec([]CursorKey{testCursorKey2})
+	if _, err := retired.Decode(token, nil); !errors.Is(err, ErrCursorUnknownKey) {
+		t.Fatalf("expected ErrCursorUnknownKey, got %v", err)
+	}
+}
+
+func TestNewSignedCursorCodec_InvalidKeys(t *testing.T) {
+	if _, err := NewSignedCursorCodec(nil); err == nil {
+		t.Fatal("expected error for empty keys")
+	}
+	if _, err := NewSignedCursorCodec([]CursorKey{{ID: "k"}}); err == nil {
+		t.Fatal("expected error for empty secret")
+	}
+	if _, err := NewSignedCursorCodec([]CursorKey{testCursorKey1, testCursorKey1}); err == nil {
+		t.Fatal("expected error for duplicate key id")
+	}
+}
@@ -0,0 +1,295 @@
+package datascope
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination/fieldmap"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+const (
+	// DefaultOwnerColumn 记录创建者（归属者）的默认列名
+	DefaultOwnerColumn = "created_by"
+
+	// DefaultOrgUnitColumn 记录所属组织单元的默认列名
+	DefaultOrgUnitColumn = "org_unit_id"
+)
+
+var (
+	// ErrMissingViewer context 中缺少 ViewerContext
+	ErrMissingViewer = errors.New("security: missing ViewerContext in context")
+
+	// ErrNoDataScope 当前用户没有定义任何数据权限范围
+	ErrNoDataScope = errors.New("security: no data scope defined for current user")
+
+	// ErrAccessDenied 数据权限策略显式禁止访问（ScopeTypeNone）
+	ErrAccessDenied = errors.New("security: data access is explicitly denied by policy")
+
+	// ErrInvalidDataScope 数据权限范围无法解析出有效条件
+	ErrInvalidDataScope = errors.New("security: invalid data scope configuration")
+)
+
+// Policy 与后端无关的数据权限（行级权限）策略，将 viewer.DataScope 转换为 FilterExpr，语义与 entgo rule.PermissionRule 一致：
+//   - 平台视图与系统视图不过滤；
+//   - SELF 匹配 owner 列等于当前用户，USER 匹配 owner 列属于指定用户，UNIT 匹配 org unit 列属于指定组织；
+//   - UNIT_TREE 匹配 org unit 列属于指定根组织，或 org unit path 列以根组织的树路径开头（即根组织的下级，见 WithOrgUnitPathResolver）；
+//     未设置 org unit path 列时只匹配根组织本身；
+//   - 多个范围取并集（OR）；任一范围为 ALL 时不过滤，任一范围为 NONE 时拒绝访问；
+//   - 缺少 Viewer 或未定义任何范围时拒绝访问。
+//
+// 列名可按实体配置，生成的条件字段即为列名，后端应直接使用而不再做字段映射。
+type Policy struct {
+	ownerColumn       string
+	orgUnitColumn     string
+	orgUnitPathColumn string
+	pathResolver      PathResolver
+}
+
+// PathResolver 查询组织的树路径（格式同 TreePath 的 path 列，如 "/1/5/"），结果中缺少的组织按未解析处理
+type PathResolver func(ctx context.Context, ids []uint64) (map[uint64]string, error)
+
+// NewPolicy 创建使用默认列名的数据权限策略
+func NewPolicy() *Policy {
+	return &Policy{
+		ownerColumn:   DefaultOwnerColumn,
+		orgUnitColumn: DefaultOrgUnitColumn,
+	}
+}
+
+// WithOwnerColumn 设置创建者（归属者）列名
+func (p *Policy) WithOwnerColumn(column string) *Policy {
+	if column != "" {
+		p.ownerColumn = column
+	}
+	return p
+}
+
+// WithOrgUnitColumn 设置组织单元列名
+func (p *Policy) WithOrgUnitColumn(column string) *Policy {
+	if column != "" {
+		p.orgUnitColumn = column
+	}
+	return p
+}
+
+// WithOrgUnitPathColumn 设置组织单元树路径列名，列值为所属组织的树路径（格式同 TreePath 的 path 列，如 "/1/5/"）；
+// 为空时 UNIT_TREE 只匹配根组织本身
+func (p *Policy) WithOrgUnitPathColumn(column string) *Policy {
+	p.orgUnitPathColumn = column
+	return p
+}
+
+// WithOrgUnitPathResolver 设置根组织树路径的解析函数。
+// 设置后 UNIT_TREE 的下级组织以前缀匹配 path LIKE '/1/5/%'（STARTS_WITH），可以使用 path 列上的索引，代价是每次构建条件时解析一次根组织路径；
+// 未设置时以 path LIKE '%/5/%'（CONTAINS）匹配，无需额外查询但无法使用索引，大表上会退化为全表扫描。
+// 解析结果中缺少的根组织同样退回 CONTAINS 匹配。
+func (p *Policy) WithOrgUnitPathResolver(resolver PathResolver) *Policy {
+	p.pathResolver = resolver
+	return p
+}
+
+// OwnerColumn 返回创建者（归属者）列名
+func (p *Policy) OwnerColumn() string {
+	return p.ownerColumn
+}
+
+// OrgUnitColumn 返回组织单元列名
+func (p *Policy) OrgUnitColumn() string {
+	return p.orgUnitColumn
+}
+
+// OrgUnitPathColumn 返回组织单元树路径列名
+func (p *Policy) OrgUnitPathColumn() string {
+	return p.orgUnitPathColumn
+}
+
+// Mapping 返回策略列名到自身的映射，供后端构建条件时跳过 snake_case 转换等字段处理
+func (p *Policy) Mapping() *fieldmap.Mapping {
+	return fieldmap.New().
+		Map(p.ownerColumn, p.ownerColumn).
+		Map(p.orgUnitColumn, p.orgUnitColumn).
+		Map(p.orgUnitPathColumn, p.orgUnitPathColumn)
+}
+
+// Build 根据 ctx 中的 Viewer 生成数据权限过滤表达式；返回 nil 表示不需要过滤
+func (p *Policy) Build(ctx context.Context) (*paginationV1.FilterExpr, error) {
+	if p == nil {
+		return nil, nil
+	}
+	if ctx == nil {
+		return nil, ErrMissingViewer
+	}
+	vc, exist := viewer.FromContext(ctx)
+	if !exist || vc == nil {
+		return nil, ErrMissingViewer
+	}
+	return p.build(ctx, vc)
+}
+
+// BuildForViewer 根据 Viewer 生成数据权限过滤表达式；返回 nil 表示不需要过滤。
+// 设置了 PathResolver 时解析函数收到的是 context.Background()，需要请求 ctx 时应使用 Build。
+func (p *Policy) BuildForViewer(vc viewer.Context) (*paginationV1.FilterExpr, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return p.build(context.Background(), vc)
+}
+
+func (p *Policy) build(ctx context.Context, vc viewer.Context) (*paginationV1.FilterExpr, error) {
+	if vc == nil {
+		return nil, ErrMissingViewer
+	}
+
+	// 平台管理视图/系统视图放行：允许访问全量数据
+	if vc.IsPlatformContext() || vc.IsSystemContext() {
+		return nil, nil
+	}
+
+	scopes := vc.DataScope()
+	if len(scopes) == 0 {
+		return nil, ErrNoDataScope
+	}
+
+	var owners, units, roots []uint64
+	for _, s := range scopes {
+		switch s.ScopeType {
+		case viewer.ScopeTypeAll:
+			return nil, nil
+		case viewer.ScopeTypeNone:
+			return nil, ErrAccessDenied
+		case viewer.ScopeTypeSelf:
+			owners = append(owners, vc.UserID())
+		case viewer.ScopeTypeUser:
+			owners = append(owners, s.TargetIDs...)
+		case viewer.ScopeTypeUnit:
+			units = append(units, s.TargetIDs...)
+		case viewer.ScopeTypeUnitTree:
+			roots = append(roots, s.TargetIDs...)
+		default:
+			// 未知的 scope 类型，忽略处理
+			continue
+		}
+	}
+
+	expr := &paginationV1.FilterExpr{Type: paginationV1.ExprType_OR}
+	if cond := inCondition(p.ownerColumn, owners); cond != nil {
+		expr.Conditions = append(expr.Conditions, cond)
+	}
+	// 根组织本身按 org unit 列匹配，与 UNIT 的组织合并为一个 IN 条件
+	if cond := inCondition(p.orgUnitColumn, append(units, roots...)); cond != nil {
+		expr.Conditions = append(expr.Conditions, cond)
+	}
+	if p.orgUnitPathColumn != "" && len(roots) > 0 {
+		conds, err := p.pathConditions(ctx, dedup(roots))
+		if err != nil {
+			return nil, err
+		}
+		expr.Conditions = append(expr.Conditions, conds...)
+	}
+	if len(expr.Conditions) == 0 {
+		return nil, ErrInvalidDataScope
+	}
+	return expr, nil
+}
+
+// inCondition 构造 column IN (ids) 条件，值以 JSON 数字数组传递，便于各后端按数值比较
+func inCondition(column string, ids []uint64) *paginationV1.FilterCondition {
+	ids = dedup(ids)
+	if len(ids) == 0 {
+		return nil
+	}
+	b, _ := json.Marshal(ids)
+	return &paginationV1.FilterCondition{
+		Field:      column,
+		Op:         paginationV1.Operator_IN,
+		ValueOneof: &paginationV1.FilterCondition_Value{Value: string(b)},
+	}
+}
+
+// pathConditions 构造根组织下级的树路径条件：已解析出路径的根组织使用前缀匹配，其余使用包含匹配
+func (p *Policy) pathConditions(ctx context.Context, roots []uint64) ([]*paginationV1.FilterCondition, error) {
+	var paths map[uint64]string
+	if p.pathResolver != nil {
+		var err error
+		if paths, err = p.pathResolver(ctx, roots); err != nil {
+			return nil, err
+		}
+	}
+
+	conds := make([]*paginationV1.FilterCondition, 0, len(roots))
+	for _, id := range roots {
+		if path, ok := paths[id]; ok && strings.HasPrefix(path, "/") {
+			conds = append(conds, prefixCondition(p.orgUnitPathColumn, path))
+			continue
+		}
+		conds = append(conds, pathCondition(p.orgUnitPathColumn, id))
+	}
+	return conds, nil
+}
+
+// prefixCondition 构造 column LIKE 'path%' 条件，匹配树路径以根组织路径开头的组织（含根组织本身）
+func prefixCondition(column, path string) *paginationV1.FilterCondition {
+	// 路径以 "/" 结尾，避免 "/1/5" 误匹配 "/1/55/"
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return &paginationV1.FilterCondition{
+		Field:      column,
+		Op:         paginationV1.Operator_STARTS_WITH,
+		ValueOneof: &paginationV1.FilterCondition_Value{Value: path},
+	}
+}
+
+// pathCondition 构造 column LIKE '%/id/%' 条件，匹配树路径中包含该组织的下级组织
+func pathCondition(column string, id uint64) *paginationV1.FilterCondition {
+	return &paginationV1.FilterCondition{
+		Field:      column,
+		Op:         paginationV1.Operator_CONTAINS,
+		ValueOneof: &paginationV1.FilterCondition_Value{Value: TreePathSegment(id)},
+	}
+}
+
+// TreePathSegment 返回组织 ID 在树路径中的片段 "/{id}/"
+func TreePathSegment(id uint64) string {
+	return "/" + strconv.FormatUint(id, 10) + "/"
+}
+
+func dedup(ids []uint64) []uint64 {
+	if len(ids) < 2 {
+		return ids
+	}
+	seen := make(map[uint64]struct{}, len(ids))
+	out := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+// And 以 AND 组合多个过滤表达式，忽略 nil 与未指定类型的表达式；只有一个有效表达式时直接返回它
+func And(exprs ...*paginationV1.FilterExpr) *paginationV1.FilterExpr {
+	var groups []*paginationV1.FilterExpr
+	for _, e := range exprs {
+		if e == nil || e.GetType() == paginationV1.ExprType_EXPR_TYPE_UNSPECIFIED {
+			continue
+		}
+		groups = append(groups, e)
+	}
+	switch len(groups) {
+	case 0:
+		return nil
+	case 1:
+		return groups[0]
+	default:
+		return &paginationV1.FilterExpr{Type: paginationV1.ExprType_AND, Groups: groups}
+	}
+}
@@ -0,0 +1,221 @@
+package datascope
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/viewer"
+)
+
+type testViewer struct {
+	viewer.Context
+	uid      uint64
+	scopes   []viewer.DataScope
+	platform bool
+}
+
+func (v testViewer) UserID() uint64                { return v.uid }
+func (v testViewer) DataScope() []viewer.DataScope { return v.scopes }
+func (v testViewer) IsPlatformContext() bool       { return v.platform }
+
+func newViewer(uid uint64, scopes ...viewer.DataScope) testViewer {
+	return testViewer{Context: viewer.NewNoopContext(), uid: uid, scopes: scopes}
+}
+
+func TestPolicy_Build(t *testing.T) {
+	p := NewPolicy().WithOwnerColumn("owner_id")
+
+	tests := []struct {
+		name    string
+		vc      viewer.Context
+		want    map[string]string
+		wantNil bool
+		wantErr error
+	}{
+		{
+			name:    "platform",
+			vc:      testViewer{Context: viewer.NewNoopContext(), platform: true},
+			wantNil: true,
+		},
+		{
+			name:    "no scope",
+			vc:      newViewer(1),
+			wantErr: ErrNoDataScope,
+		},
+		{
+			name:    "all",
+			vc:      newViewer(1, viewer.DataScope{ScopeType: viewer.ScopeTypeSelf}, viewer.DataScope{ScopeType: viewer.ScopeTypeAll}),
+			wantNil: true,
+		},
+		{
+			name:    "none",
+			vc:      newViewer(1, viewer.DataScope{ScopeType: viewer.ScopeTypeSelf}, viewer.DataScope{ScopeType: viewer.ScopeTypeNone}),
+			wantErr: ErrAccessDenied,
+		},
+		{
+			name:    "empty unit",
+			vc:      newViewer(1, viewer.DataScope{ScopeType: viewer.ScopeTypeUnit}),
+			wantErr: ErrInvalidDataScope,
+		},
+		{
+			name: "self and users",
+			vc: newViewer(7,
+				viewer.DataScope{ScopeType: viewer.ScopeTypeSelf},
+				viewer.DataScope{ScopeType: viewer.ScopeTypeUser, TargetIDs: []uint64{7, 8}},
+			),
+			want: map[string]string{"owner_id": "[7,8]"},
+		},
+		{
+			name: "self and units",
+			vc: newViewer(7,
+				viewer.DataScope{ScopeType: viewer.ScopeTypeSelf},
+				viewer.DataScope{ScopeType: viewer.ScopeTypeUnit, TargetIDs: []uint64{10, 11}},
+			),
+			want: map[string]string{"owner_id": "[7]", DefaultOrgUnitColumn: "[10,11]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := p.Build(viewer.WithContext(context.Background(), tt.vc))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantNil {
+				if expr != nil {
+					t.Fatalf("expected nil expr, got %v", expr)
+				}
+				return
+			}
+
+			if expr.GetType() != paginationV1.ExprType_OR || len(expr.GetConditions()) != len(tt.want) {
+				t.Fatalf("unexpected expr: %v", expr)
+			}
+			for _, c := range expr.GetConditions() {
+				if c.GetOp() != paginationV1.Operator_IN || tt.want[c.GetField()] != c.GetValue() {
+					t.Fatalf("unexpected condition: %v", c)
+				}
+			}
+		})
+	}
+
+	if _, err := p.Build(context.Background()); !errors.Is(err, ErrMissingViewer) {
+		t.Fatalf("expected ErrMissingViewer, got %v", err)
+	}
+
+	var nilPolicy *Policy
+	if expr, err := nilPolicy.Build(context.Background()); expr != nil || err != nil {
+		t.Fatalf("nil policy should not filter: %v, %v", expr, err)
+	}
+}
+
+func TestPolicy_Build_UnitTree(t *testing.T) {
+	ctx := viewer.WithContext(context.Background(), newViewer(7,
+		viewer.DataScope{ScopeType: viewer.ScopeTypeUnit, TargetIDs: []uint64{3}},
+		viewer.DataScope{ScopeType: viewer.ScopeTypeUnitTree, TargetIDs: []uint64{5, 9, 5}},
+	))
+
+	// 未设置路径列时只匹配根组织本身
+	expr, err := NewPolicy().Build(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(expr.GetConditions()) != 1 || expr.GetConditions()[0].GetValue() != "[3,5,9]" {
+		t.Fatalf("unexpected expr: %v", expr)
+	}
+
+	p := NewPolicy().WithOrgUnitPathColumn("org_unit_path")
+	if _, ok := p.Mapping().Lookup("org_unit_path"); !ok {
+		t.Fatal("path column should be mapped")
+	}
+
+	expr, err = p.Build(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []struct {
+		field string
+		op    paginationV1.Operator
+		value string
+	}{
+		{DefaultOrgUnitColumn, paginationV1.Operator_IN, "[3,5,9]"},
+		{"org_unit_path", paginationV1.Operator_CONTAINS, "/5/"},
+		{"org_unit_path", paginationV1.Operator_CONTAINS, "/9/"},
+	}
+	if expr.GetType() != paginationV1.ExprType_OR || len(expr.GetConditions()) != len(want) {
+		t.Fatalf("unexpected expr: %v", expr)
+	}
+	for i, c := range expr.GetConditions() {
+		if c.GetField() != want[i].field || c.GetOp() != want[i].op || c.GetValue() != want[i].value {
+			t.Fatalf("unexpected condition %d: %v", i, c)
+		}
+	}
+}
+
+func TestPolicy_Build_UnitTreePrefix(t *testing.T) {
+	ctx := viewer.WithContext(context.Background(), newViewer(7,
+		viewer.DataScope{ScopeType: viewer.ScopeTypeUnitTree, TargetIDs: []uint64{5, 9, 5}},
+	))
+
+	var resolved []uint64
+	p := NewPolicy().WithOrgUnitPathColumn("org_unit_path").
+		WithOrgUnitPathResolver(func(_ context.Context, ids []uint64) (map[uint64]string, error) {
+			resolved = ids
+			// 9 未找到路径，退回包含匹配
+			return map[uint64]string{5: "/1/5"}, nil
+		})
+
+	expr, err := p.Build(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resolved) != 2 {
+		t.Fatalf("roots should be deduplicated before resolving: %v", resolved)
+	}
+	want := []struct {
+		field string
+		op    paginationV1.Operator
+		value string
+	}{
+		{DefaultOrgUnitColumn, paginationV1.Operator_IN, "[5,9]"},
+		{"org_unit_path", paginationV1.Operator_STARTS_WITH, "/1/5/"},
+		{"org_unit_path", paginationV1.Operator_CONTAINS, "/9/"},
+	}
+	if len(expr.GetConditions()) != len(want) {
+		t.Fatalf("unexpected expr: %v", expr)
+	}
+	for i, c := range expr.GetConditions() {
+		if c.GetField() != want[i].field || c.GetOp() != want[i].op || c.GetValue() != want[i].value {
+			t.Fatalf("unexpected condition %d: %v", i, c)
+		}
+	}
+
+	boom := errors.New("boom")
+	p.WithOrgUnitPathResolver(func(context.Context, []uint64) (map[uint64]string, error) { return nil, boom })
+	if _, err = p.Build(ctx); !errors.Is(err, boom) {
+		t.Fatalf("expected resolver error, got %v", err)
+	}
+}
+
+func TestAnd(t *testing.T) {
+	a := &paginationV1.FilterExpr{Type: paginationV1.ExprType_AND}
+	b := &paginationV1.FilterExpr{Type: paginationV1.ExprType_OR}
+
+	if And(nil, &paginationV1.FilterExpr{}) != nil {
+		t.Fatal("expected nil for empty input")
+	}
+	if And(a, nil) != a {
+		t.Fatal("single expr should be returned as is")
+	}
+	if got := And(a, b); got.GetType() != paginationV1.ExprType_AND || len(got.GetGroups()) != 2 {
+		t.Fatalf("unexpected combined expr: %v", got)
+	}
+}
@@ -0,0 +1,257 @@
+package fieldmap
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/tx7do/go-utils/stringcase"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+// Mapping API 字段名到存储列名的映射表，供各后端的过滤、排序与字段选择器共用。
+//   - 已登记的字段使用登记的列名（可以是关联表的列，如 "org.name"），后端不再做 snake_case 转换或 JSON 路径拆分；
+//   - 未登记的字段保持原有行为（转换为 snake_case）；
+//   - 带点号的字段（如 JSON 字段 "prefs.dailyEmail"）在完整名称未登记时，按第一个点号之前的部分映射。
+//
+// 所有方法对 nil 安全，nil 表示不做映射。
+type Mapping struct {
+	columns map[string]string
+}
+
+// New 创建空的映射表
+func New() *Mapping {
+	return &Mapping{columns: make(map[string]string)}
+}
+
+// Map 登记字段到列名的映射
+func (m *Mapping) Map(field, column string) *Mapping {
+	field, column = strings.TrimSpace(field), strings.TrimSpace(column)
+	if field == "" || column == "" {
+		return m
+	}
+	m.columns[field] = column
+	return m
+}
+
+// Merge 合并另一个映射表，同名字段以 other 为准
+func (m *Mapping) Merge(other *Mapping) *Mapping {
+	if other == nil {
+		return m
+	}
+	for k, v := range other.columns {
+		m.columns[k] = v
+	}
+	return m
+}
+
+// Len 返回已登记的字段数
+func (m *Mapping) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.columns)
+}
+
+// Lookup 精确查找字段登记的列名
+func (m *Mapping) Lookup(field string) (string, bool) {
+	if m == nil || len(m.columns) == 0 {
+		return "", false
+	}
+	column, ok := m.columns[strings.TrimSpace(field)]
+	return column, ok
+}
+
+// Column 返回字段对应的列名，未登记时转换为 snake_case
+func (m *Mapping) Column(field string) string {
+	if column, ok := m.Lookup(field); ok {
+		return column
+	}
+	return stringcase.ToSnakeCase(field)
+}
+
+// Resolve 返回字段映射后的名称：已登记时返回列名；带点号且第一段已登记时替换第一段；否则原样返回
+func (m *Mapping) Resolve(field string) string {
+	if m == nil || len(m.columns) == 0 {
+		return field
+	}
+	name := strings.TrimSpace(field)
+	if column, ok := m.columns[name]; ok {
+		return column
+	}
+	if i := strings.Index(name, "."); i > 0 {
+		if column, ok := m.columns[name[:i]]; ok {
+			return column + name[i:]
+		}
+	}
+	return field
+}
+
+// Paths 返回按映射表替换后的字段路径副本
+func (m *Mapping) Paths(paths []string) []string {
+	if m.Len() == 0 || len(paths) == 0 {
+		return paths
+	}
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		out[i] = m.Resolve(p)
+	}
+	return out
+}
+
+// Sorting 返回字段按映射表替换后的排序副本
+func (m *Mapping) Sorting(orders []*paginationV1.Sorting) []*paginationV1.Sorting {
+	if m.Len() == 0 || len(orders) == 0 {
+		return orders
+	}
+	out := make([]*paginationV1.Sorting, 0, len(orders))
+	for _, o := range orders {
+		if o == nil {
+			continue
+		}
+		out = append(out, &paginationV1.Sorting{Field: m.Resolve(o.GetField()), Direction: o.GetDirection()})
+	}
+	return out
+}
+
+// Filter 返回条件字段按映射表替换后的过滤表达式副本
+func (m *Mapping) Filter(expr *paginationV1.FilterExpr) *paginationV1.FilterExpr {
+	if m.Len() == 0 || expr == nil {
+		return expr
+	}
+	out := proto.Clone(expr).(*paginationV1.FilterExpr)
+	m.mapExpr(out)
+	return out
+}
+
+func (m *Mapping) mapExpr(expr *paginationV1.FilterExpr) {
+	if expr == nil {
+		return
+	}
+	for _, cond := range expr.GetConditions() {
+		if cond != nil {
+			cond.Field = m.Resolve(cond.GetField())
+		}
+	}
+	for _, g := range expr.GetGroups() {
+		m.mapExpr(g)
+	}
+}
+
+// FromProto 从 proto 消息描述生成映射表：字段的 JSON 名称（如 createdAt）与 proto 名称均映射到 proto 字段名（如 created_at）
+func FromProto(md protoreflect.MessageDescriptor) *Mapping {
+	m := New()
+	if md == nil {
+		return m
+	}
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		column := string(fd.Name())
+		m.Map(column, column)
+		if fd.JSONName() != column {
+			m.Map(fd.JSONName(), column)
+		}
+	}
+	return m
+}
+
+// FromMessage 从 proto 消息生成映射表，见 FromProto
+func FromMessage(msg proto.Message) *Mapping {
+	if msg == nil {
+		return New()
+	}
+	return FromProto(msg.ProtoReflect().Descriptor())
+}
+
+// FromStruct 从结构体标签生成映射表：字段名取 json 标签（缺省为 Go 字段名），
+// 列名取 columnTag 指定的标签（gorm 标签取其中的 column，其余取逗号前的部分），缺省为 Go 字段名的 snake_case。
+// 匿名嵌入的结构体会被展开。
+func FromStruct(v any, columnTag string) *Mapping {
+	m := New()
+
+	rt := reflect.TypeOf(v)
+	for rt != nil && rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return m
+	}
+
+	m.addStruct(rt, columnTag)
+	return m
+}
+
+func (m *Mapping) addStruct(rt reflect.Type, columnTag string) {
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+
+		if sf.Anonymous {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				m.addStruct(ft, columnTag)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		field := sf.Name
+		if tag, ok := sf.Tag.Lookup("json"); ok {
+			name, _, _ := strings.Cut(tag, ",")
+			if name == "-" {
+				continue
+			}
+			if name != "" {
+				field = name
+			}
+		}
+
+		column, skip := tagColumn(sf, columnTag)
+		if skip {
+			continue
+		}
+		if column == "" {
+			column = stringcase.ToSnakeCase(sf.Name)
+		}
+
+		m.Map(field, column)
+	}
+}
+
+// tagColumn 读取结构体字段标签中的列名，标签值为 "-" 时 skip 为 true
+func tagColumn(sf reflect.StructField, columnTag string) (column string, skip bool) {
+	if columnTag == "" {
+		return "", false
+	}
+	tag, ok := sf.Tag.Lookup(columnTag)
+	if !ok {
+		return "", false
+	}
+
+	if columnTag == "gorm" {
+		for _, part := range strings.Split(tag, ";") {
+			part = strings.TrimSpace(part)
+			if part == "-" {
+				return "", true
+			}
+			if k, v, found := strings.Cut(part, ":"); found && strings.EqualFold(strings.TrimSpace(k), "column") {
+				column = strings.TrimSpace(v)
+			}
+		}
+		return column, false
+	}
+
+	column, _, _ = strings.Cut(tag, ",")
+	if column == "-" {
+		return "", true
+	}
+	return strings.TrimSpace(column), false
+}
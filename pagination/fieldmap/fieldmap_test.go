@@ -0,0 +1,141 @@
+package fieldmap
+
+import (
+	"testing"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+func TestMapping_Resolve(t *testing.T) {
+	m := New().
+		Map("createdAt", "create_time").
+		Map("orgName", "org.name").
+		Map("prefs", "preferences")
+
+	cases := map[string]string{
+		"createdAt":        "create_time",
+		"orgName":          "org.name",
+		"prefs.dailyEmail": "preferences.dailyEmail",
+		"realName":         "realName",
+		"other.key":        "other.key",
+	}
+	for in, want := range cases {
+		if got := m.Resolve(in); got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if got := m.Column("realName"); got != "real_name" {
+		t.Errorf("Column(realName) = %q, want real_name", got)
+	}
+	if got := m.Column("orgName"); got != "org.name" {
+		t.Errorf("Column(orgName) = %q, want org.name", got)
+	}
+	if _, ok := m.Lookup("prefs.dailyEmail"); ok {
+		t.Error("Lookup should only match registered fields exactly")
+	}
+}
+
+func TestMapping_Nil(t *testing.T) {
+	var m *Mapping
+
+	if got := m.Resolve("createdAt"); got != "createdAt" {
+		t.Errorf("nil Resolve = %q", got)
+	}
+	if got := m.Column("createdAt"); got != "created_at" {
+		t.Errorf("nil Column = %q", got)
+	}
+	expr := &paginationV1.FilterExpr{Type: paginationV1.ExprType_AND}
+	if m.Filter(expr) != expr {
+		t.Error("nil Filter should return the same expression")
+	}
+}
+
+func TestMapping_FilterAndSorting(t *testing.T) {
+	m := New().Map("createdAt", "create_time").Map("orgName", "org.name")
+
+	expr := &paginationV1.FilterExpr{
+		Type: paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{
+			{Field: "createdAt", Op: paginationV1.Operator_GT, ValueOneof: &paginationV1.FilterCondition_Value{Value: "2024-01-01"}},
+		},
+		Groups: []*paginationV1.FilterExpr{{
+			Type:       paginationV1.ExprType_OR,
+			Conditions: []*paginationV1.FilterCondition{{Field: "orgName", Op: paginationV1.Operator_EQ}},
+		}},
+	}
+
+	out := m.Filter(expr)
+	if out.GetConditions()[0].GetField() != "create_time" || out.GetGroups()[0].GetConditions()[0].GetField() != "org.name" {
+		t.Fatalf("unexpected mapped filter: %v", out)
+	}
+	if expr.GetConditions()[0].GetField() != "createdAt" {
+		t.Fatal("Filter must not modify the input expression")
+	}
+
+	orders := m.Sorting([]*paginationV1.Sorting{
+		{Field: "createdAt", Direction: paginationV1.Sorting_DESC},
+		nil,
+		{Field: "id"},
+	})
+	if len(orders) != 2 || orders[0].GetField() != "create_time" || orders[0].GetDirection() != paginationV1.Sorting_DESC || orders[1].GetField() != "id" {
+		t.Fatalf("unexpected mapped sorting: %v", orders)
+	}
+
+	paths := m.Paths([]string{"orgName", "id"})
+	if paths[0] != "org.name" || paths[1] != "id" {
+		t.Fatalf("unexpected mapped paths: %v", paths)
+	}
+}
+
+func TestFromProto(t *testing.T) {
+	m := FromMessage(&paginationV1.PagingRequest{})
+
+	if got, ok := m.Lookup("pageSize"); !ok || got != "page_size" {
+		t.Errorf("pageSize -> %q, %v", got, ok)
+	}
+	if got, ok := m.Lookup("page_size"); !ok || got != "page_size" {
+		t.Errorf("page_size -> %q, %v", got, ok)
+	}
+	if got, ok := m.Lookup("filterExpr"); !ok || got != "filter_expr" {
+		t.Errorf("filterExpr -> %q, %v", got, ok)
+	}
+}
+
+type baseModel struct {
+	ID        uint64 `json:"id" gorm:"column:id;primaryKey"`
+	CreatedAt int64  `json:"createdAt" gorm:"column:create_time"`
+}
+
+type userModel struct {
+	baseModel
+	RealName string `json:"realName"`
+	OrgName  string `json:"orgName" gorm:"column:org_name" bson:"org.name"`
+	Secret   string `json:"-"`
+	Ignored  string `json:"ignored" gorm:"-"`
+}
+
+func TestFromStruct(t *testing.T) {
+	m := FromStruct(&userModel{}, "gorm")
+
+	cases := map[string]string{
+		"id":        "id",
+		"createdAt": "create_time",
+		"realName":  "real_name",
+		"orgName":   "org_name",
+	}
+	for in, want := range cases {
+		if got, ok := m.Lookup(in); !ok || got != want {
+			t.Errorf("Lookup(%q) = %q, %v, want %q", in, got, ok, want)
+		}
+	}
+	for _, name := range []string{"Secret", "ignored"} {
+		if _, ok := m.Lookup(name); ok {
+			t.Errorf("%s should not be mapped", name)
+		}
+	}
+
+	if got, _ := FromStruct(userModel{}, "bson").Lookup("orgName"); got != "org.name" {
+		t.Errorf("bson orgName = %q, want org.name", got)
+	}
+}
@@ -0,0 +1,329 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/encoding"
+	_ "github.com/go-kratos/kratos/v2/encoding/json"
+	"google.golang.org/protobuf/proto"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+// ErrPolicyViolation 过滤/排序条件违反字段策略，可通过 errors.Is 判断
+var ErrPolicyViolation = errors.New("filter policy violation")
+
+// Violation 单条策略违规信息
+type Violation struct {
+	// Field 请求中的字段名
+	Field string `json:"field,omitempty"`
+
+	// Op 违规的操作符，字段级违规时为 OPERATOR_UNSPECIFIED
+	Op paginationV1.Operator `json:"op,omitempty"`
+
+	// Reason 违规原因
+	Reason string `json:"reason"`
+}
+
+func (v Violation) String() string {
+	switch {
+	case v.Field == "":
+		return v.Reason
+	case v.Op != paginationV1.Operator_OPERATOR_UNSPECIFIED:
+		return fmt.Sprintf("field %q operator %s: %s", v.Field, v.Op, v.Reason)
+	default:
+		return fmt.Sprintf("field %q: %s", v.Field, v.Reason)
+	}
+}
+
+// PolicyError 字段策略校验失败，列出全部违规项
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.String())
+	}
+	return ErrPolicyViolation.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *PolicyError) Unwrap() error {
+	return ErrPolicyViolation
+}
+
+// FieldRule 单个字段的策略
+type FieldRule struct {
+	// Column 实际的列名/文档字段名，为空时与外部字段名相同
+	Column string
+
+	// Operators 允许的操作符，为空时允许全部（仍受 FilterPolicy.DenyOperators 限制）
+	Operators []paginationV1.Operator
+
+	// MaxInValues IN/NIN 的值个数上限，<= 0 时使用 FilterPolicy 的全局上限
+	MaxInValues int
+
+	// Unsortable 是否禁止按该字段排序
+	Unsortable bool
+}
+
+// FilterPolicy 声明式的过滤/排序字段策略：
+//   - 通过 Allow/Field 登记字段后进入白名单模式，未登记的字段将被拒绝；未登记任何字段时允许全部字段；
+//   - Deny 的字段始终被拒绝；
+//   - 字段可映射到不同的列名，并限制可用的操作符；
+//   - 可限制 IN 列表长度与条件组的嵌套深度。
+//
+// 带点号的字段（如 JSON 字段 "preferences.daily_email"）在完整名称未登记时按第一个点号之前的部分匹配。
+type FilterPolicy struct {
+	fields map[string]FieldRule
+	denied map[string]struct{}
+
+	deniedOps map[paginationV1.Operator]struct{}
+
+	maxInValues int
+	maxDepth    int
+
+	codec encoding.Codec
+}
+
+// NewFilterPolicy 创建字段策略
+func NewFilterPolicy() *FilterPolicy {
+	return &FilterPolicy{
+		fields:    make(map[string]FieldRule),
+		denied:    make(map[string]struct{}),
+		deniedOps: make(map[paginationV1.Operator]struct{}),
+		codec:     encoding.GetCodec("json"),
+	}
+}
+
+// Allow 登记允许的字段（列名与字段名相同，不限制操作符）
+func (p *FilterPolicy) Allow(fields ...string) *FilterPolicy {
+	for _, f := range fields {
+		if _, ok := p.fields[f]; !ok {
+			p.fields[f] = FieldRule{}
+		}
+	}
+	return p
+}
+
+// Field 登记字段及其策略
+func (p *FilterPolicy) Field(field string, rule FieldRule) *FilterPolicy {
+	p.fields[field] = rule
+	return p
+}
+
+// Deny 登记禁止过滤与排序的字段
+func (p *FilterPolicy) Deny(fields ...string) *FilterPolicy {
+	for _, f := range fields {
+		p.denied[f] = struct{}{}
+	}
+	return p
+}
+
+// DenyOperators 全局禁止的操作符（如 REGEXP）
+func (p *FilterPolicy) DenyOperators(ops ...paginationV1.Operator) *FilterPolicy {
+	for _, op := range ops {
+		p.deniedOps[op] = struct{}{}
+	}
+	return p
+}
+
+// WithMaxInValues 设置 IN/NIN 的值个数上限，<= 0 表示不限制
+func (p *FilterPolicy) WithMaxInValues(n int) *FilterPolicy {
+	p.maxInValues = n
+	return p
+}
+
+// WithMaxDepth 设置条件组的最大嵌套深度（根表达式为第 1 层），<= 0 表示不限制
+func (p *FilterPolicy) WithMaxDepth(n int) *FilterPolicy {
+	p.maxDepth = n
+	return p
+}
+
+// Column 返回字段映射后的列名，字段被拒绝时 ok 为 false
+func (p *FilterPolicy) Column(field string) (column string, ok bool) {
+	column, _, reason := p.resolve(field)
+	return column, reason == ""
+}
+
+// resolve 解析字段对应的规则与列名，reason 非空表示字段被拒绝
+func (p *FilterPolicy) resolve(field string) (column string, rule FieldRule, reason string) {
+	if p == nil {
+		return field, FieldRule{}, ""
+	}
+
+	name := strings.TrimSpace(field)
+	if name == "" {
+		return "", FieldRule{}, "field is empty"
+	}
+
+	base, rest := name, ""
+	if i := strings.Index(name, "."); i > 0 {
+		base, rest = name[:i], name[i:]
+	}
+
+	if _, ok := p.denied[name]; ok {
+		return "", FieldRule{}, "field is denied"
+	}
+	if _, ok := p.denied[base]; ok {
+		return "", FieldRule{}, "field is denied"
+	}
+
+	if r, ok := p.fields[name]; ok {
+		if r.Column == "" {
+			return name, r, ""
+		}
+		return r.Column, r, ""
+	}
+	if rest != "" {
+		if r, ok := p.fields[base]; ok {
+			if r.Column == "" {
+				return name, r, ""
+			}
+			return r.Column + rest, r, ""
+		}
+	}
+
+	if len(p.fields) > 0 {
+		return "", FieldRule{}, "field is not allowed"
+	}
+	return name, FieldRule{}, ""
+}
+
+// CheckFilter 按策略校验过滤表达式，返回字段映射为列名后的副本；存在违规时返回 *PolicyError。
+// 策略为 nil 时原样返回。
+func (p *FilterPolicy) CheckFilter(expr *paginationV1.FilterExpr) (*paginationV1.FilterExpr, error) {
+	if p == nil || expr == nil {
+		return expr, nil
+	}
+
+	out := proto.Clone(expr).(*paginationV1.FilterExpr)
+
+	var violations []Violation
+	p.checkExpr(out, 1, &violations)
+	if len(violations) > 0 {
+		return nil, &PolicyError{Violations: violations}
+	}
+	return out, nil
+}
+
+func (p *FilterPolicy) checkExpr(expr *paginationV1.FilterExpr, depth int, violations *[]Violation) {
+	if expr == nil {
+		return
+	}
+
+	if p.maxDepth > 0 && depth > p.maxDepth {
+		*violations = append(*violations, Violation{Reason: fmt.Sprintf("filter nesting depth exceeds %d", p.maxDepth)})
+		return
+	}
+
+	for _, cond := range expr.GetConditions() {
+		p.checkCondition(cond, violations)
+	}
+	for _, g := range expr.GetGroups() {
+		p.checkExpr(g, depth+1, violations)
+	}
+}
+
+func (p *FilterPolicy) checkCondition(cond *paginationV1.FilterCondition, violations *[]Violation) {
+	if cond == nil {
+		return
+	}
+
+	field, op := cond.GetField(), cond.GetOp()
+
+	column, rule, reason := p.resolve(field)
+	if reason != "" {
+		*violations = append(*violations, Violation{Field: field, Reason: reason})
+		return
+	}
+
+	if _, ok := p.deniedOps[op]; ok {
+		*violations = append(*violations, Violation{Field: field, Op: op, Reason: "operator is denied"})
+	} else if len(rule.Operators) > 0 && !containsOperator(rule.Operators, op) {
+		*violations = append(*violations, Violation{Field: field, Op: op, Reason: "operator is not allowed"})
+	}
+
+	if op == paginationV1.Operator_IN || op == paginationV1.Operator_NIN {
+		limit := rule.MaxInValues
+		if limit <= 0 {
+			limit = p.maxInValues
+		}
+		if n := p.inValuesCount(cond); limit > 0 && n > limit {
+			*violations = append(*violations, Violation{Field: field, Op: op, Reason: fmt.Sprintf("%d values exceed the limit of %d", n, limit)})
+		}
+	}
+
+	cond.Field = column
+}
+
+// inValuesCount 统计 IN/NIN 的值个数，与各后端的解析方式一致：优先使用 Values，
+// 否则尝试将 Value 解析为 JSON 数组，再按逗号分隔统计非空项
+func (p *FilterPolicy) inValuesCount(cond *paginationV1.FilterCondition) int {
+	if len(cond.GetValues()) > 0 {
+		return len(cond.GetValues())
+	}
+
+	v := strings.TrimSpace(cond.GetValue())
+	if v == "" {
+		return 0
+	}
+	if strings.HasPrefix(v, "[") && p.codec != nil {
+		var arr []any
+		if err := p.codec.Unmarshal([]byte(v), &arr); err == nil {
+			return len(arr)
+		}
+	}
+
+	n := 0
+	for _, part := range strings.Split(v, ",") {
+		if strings.TrimSpace(part) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// CheckSorting 按策略校验排序字段，返回字段映射为列名后的副本；
+// 存在违规时返回 *PolicyError，同时返回去除违规项后的排序。策略为 nil 时原样返回。
+func (p *FilterPolicy) CheckSorting(orders []*paginationV1.Sorting) ([]*paginationV1.Sorting, error) {
+	if p == nil || len(orders) == 0 {
+		return orders, nil
+	}
+
+	var violations []Violation
+	out := make([]*paginationV1.Sorting, 0, len(orders))
+	for _, o := range orders {
+		if o == nil || strings.TrimSpace(o.GetField()) == "" {
+			continue
+		}
+
+		column, rule, reason := p.resolve(o.GetField())
+		if reason == "" && rule.Unsortable {
+			reason = "field is not sortable"
+		}
+		if reason != "" {
+			violations = append(violations, Violation{Field: o.GetField(), Reason: reason})
+			continue
+		}
+
+		out = append(out, &paginationV1.Sorting{Field: column, Direction: o.GetDirection()})
+	}
+
+	if len(violations) > 0 {
+		return out, &PolicyError{Violations: violations}
+	}
+	return out, nil
+}
+
+func containsOperator(ops []paginationV1.Operator, op paginationV1.Operator) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
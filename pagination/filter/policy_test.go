@@ -0,0 +1,150 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+func newPolicyCond(field string, op paginationV1.Operator, value string, values ...string) *paginationV1.FilterCondition {
+	return &paginationV1.FilterCondition{
+		Field:      field,
+		Op:         op,
+		ValueOneof: &paginationV1.FilterCondition_Value{Value: value},
+		Values:     values,
+	}
+}
+
+func TestFilterPolicy_CheckFilter_MapsColumns(t *testing.T) {
+	p := NewFilterPolicy().
+		Allow("id").
+		Field("userName", FieldRule{Column: "user_name", Operators: []paginationV1.Operator{paginationV1.Operator_EQ, paginationV1.Operator_CONTAINS}}).
+		Field("preferences", FieldRule{Column: "prefs"})
+
+	expr := &paginationV1.FilterExpr{
+		Type: paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{
+			newPolicyCond("userName", paginationV1.Operator_CONTAINS, "tom"),
+			newPolicyCond("preferences.daily_email", paginationV1.Operator_EQ, "true"),
+		},
+		Groups: []*paginationV1.FilterExpr{{
+			Type:       paginationV1.ExprType_OR,
+			Conditions: []*paginationV1.FilterCondition{newPolicyCond("id", paginationV1.Operator_GT, "1")},
+		}},
+	}
+
+	out, err := p.CheckFilter(expr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Conditions[0].Field != "user_name" || out.Conditions[1].Field != "prefs.daily_email" || out.Groups[0].Conditions[0].Field != "id" {
+		t.Fatalf("unexpected mapped fields: %v", out)
+	}
+	// 原表达式不应被修改
+	if expr.Conditions[0].Field != "userName" {
+		t.Fatalf("input expr was modified: %v", expr)
+	}
+}
+
+func TestFilterPolicy_CheckFilter_Violations(t *testing.T) {
+	p := NewFilterPolicy().
+		Allow("id", "bio").
+		Field("status", FieldRule{Operators: []paginationV1.Operator{paginationV1.Operator_EQ, paginationV1.Operator_IN}, MaxInValues: 2}).
+		Deny("password").
+		DenyOperators(paginationV1.Operator_REGEXP).
+		WithMaxInValues(3).
+		WithMaxDepth(2)
+
+	expr := &paginationV1.FilterExpr{
+		Type: paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{
+			newPolicyCond("password", paginationV1.Operator_EQ, "x"),
+			newPolicyCond("email", paginationV1.Operator_EQ, "x"),
+			newPolicyCond("bio", paginationV1.Operator_REGEXP, ".*"),
+			newPolicyCond("status", paginationV1.Operator_GT, "1"),
+			newPolicyCond("status", paginationV1.Operator_IN, `["a","b","c"]`),
+			newPolicyCond("id", paginationV1.Operator_IN, "", "1", "2", "3", "4"),
+		},
+		Groups: []*paginationV1.FilterExpr{{
+			Type: paginationV1.ExprType_AND,
+			Groups: []*paginationV1.FilterExpr{{
+				Type:       paginationV1.ExprType_AND,
+				Conditions: []*paginationV1.FilterCondition{newPolicyCond("id", paginationV1.Operator_EQ, "1")},
+			}},
+		}},
+	}
+
+	_, err := p.CheckFilter(expr)
+	if !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("expected ErrPolicyViolation, got %v", err)
+	}
+
+	var pe *PolicyError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected *PolicyError, got %T", err)
+	}
+	if len(pe.Violations) != 7 {
+		t.Fatalf("expected 7 violations, got %d: %v", len(pe.Violations), err)
+	}
+}
+
+func TestFilterPolicy_CheckFilter_CommaInValues(t *testing.T) {
+	p := NewFilterPolicy().
+		Field("status", FieldRule{MaxInValues: 2}).
+		Allow("id").
+		WithMaxInValues(3)
+
+	check := func(field, value string) error {
+		_, err := p.CheckFilter(&paginationV1.FilterExpr{
+			Type:       paginationV1.ExprType_AND,
+			Conditions: []*paginationV1.FilterCondition{newPolicyCond(field, paginationV1.Operator_IN, value)},
+		})
+		return err
+	}
+
+	// 逗号分隔的值与各后端的解析方式一致地计数
+	if err := check("id", "1,2,3,4"); !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("comma list should exceed the global limit, got %v", err)
+	}
+	if err := check("status", "a, b ,c"); !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("comma list should exceed the field limit, got %v", err)
+	}
+	if err := check("id", "1,2,,3,"); err != nil {
+		t.Fatalf("empty items should not be counted, got %v", err)
+	}
+	if err := check("id", "1"); err != nil {
+		t.Fatalf("single value should pass, got %v", err)
+	}
+}
+
+func TestFilterPolicy_CheckSorting(t *testing.T) {
+	p := NewFilterPolicy().
+		Field("createdAt", FieldRule{Column: "created_at"}).
+		Field("bio", FieldRule{Unsortable: true})
+
+	out, err := p.CheckSorting([]*paginationV1.Sorting{
+		{Field: "createdAt", Direction: paginationV1.Sorting_DESC},
+		{Field: "bio"},
+		{Field: "password"},
+	})
+	var pe *PolicyError
+	if !errors.As(err, &pe) || len(pe.Violations) != 2 {
+		t.Fatalf("expected 2 violations, got %v", err)
+	}
+	if len(out) != 1 || out[0].Field != "created_at" || out[0].Direction != paginationV1.Sorting_DESC {
+		t.Fatalf("unexpected sorting: %v", out)
+	}
+}
+
+func TestFilterPolicy_Nil(t *testing.T) {
+	var p *FilterPolicy
+
+	expr := &paginationV1.FilterExpr{Type: paginationV1.ExprType_AND}
+	if out, err := p.CheckFilter(expr); err != nil || out != expr {
+		t.Fatalf("nil policy should pass through: %v, %v", out, err)
+	}
+	if col, ok := p.Column("any"); !ok || col != "any" {
+		t.Fatalf("nil policy column: %s, %v", col, ok)
+	}
+}
@@ -0,0 +1,157 @@
+package keyset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/tx7do/go-crud/pagination"
+)
+
+// timeKey 时间值在游标中的标记键
+const timeKey = "$t"
+
+// Cursor keyset 游标，记录边界行在每个排序列上的取值
+type Cursor struct {
+	// Signature 排序列签名
+	Signature string `json:"s"`
+	// Values 边界行在各排序列上的值，与排序列一一对应
+	Values []any `json:"v"`
+	// Backward 是否为向前翻页游标
+	Backward bool `json:"b,omitempty"`
+}
+
+// Option keyset 游标编解码选项
+type Option func(*options)
+
+type options struct {
+	codec pagination.CursorCodec
+	scope []byte
+}
+
+// WithCodec 设置游标编解码器（签名/加密），为 nil 时使用 pagination.DefaultCursorCodec
+func WithCodec(codec pagination.CursorCodec) Option {
+	return func(o *options) {
+		if codec != nil {
+			o.codec = codec
+		}
+	}
+}
+
+// WithScope 设置游标绑定的查询范围（见 pagination.CursorScope）
+func WithScope(scope []byte) Option {
+	return func(o *options) {
+		o.scope = scope
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{codec: pagination.DefaultCursorCodec()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Encode 将排序列的取值编码为不透明的 token
+func Encode(columns []Column, values []any, backward bool, opts ...Option) (string, error) {
+	return newOptions(opts).encode(columns, values, backward)
+}
+
+// Decode 解码 token 并校验其与排序列是否一致
+func Decode(token string, columns []Column, opts ...Option) (*Cursor, error) {
+	return newOptions(opts).decode(token, columns)
+}
+
+func (o *options) encode(columns []Column, values []any, backward bool) (string, error) {
+	if len(values) != len(columns) {
+		return "", fmt.Errorf("%w: expect %d values, got %d", ErrInvalidCursor, len(columns), len(values))
+	}
+
+	c := Cursor{
+		Signature: signature(columns),
+		Values:    make([]any, 0, len(values)),
+		Backward:  backward,
+	}
+	for _, v := range values {
+		c.Values = append(c.Values, encodeValue(v))
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return o.codec.Encode(b, o.scope)
+}
+
+func (o *options) decode(token string, columns []Column) (*Cursor, error) {
+	b, err := o.codec.Decode(token, o.scope)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var c Cursor
+	if err = dec.Decode(&c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if c.Signature != signature(columns) {
+		return nil, ErrCursorMismatch
+	}
+	if len(c.Values) != len(columns) {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, v := range c.Values {
+		c.Values[i] = decodeValue(v)
+	}
+
+	return &c, nil
+}
+
+// encodeValue 规范化取值：解引用指针，时间值转换为带标记的 RFC3339Nano 字符串
+func encodeValue(v any) any {
+	rv := reflect.ValueOf(v)
+	for rv.IsValid() && rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+
+	if t, ok := rv.Interface().(time.Time); ok {
+		return map[string]any{timeKey: t.Format(time.RFC3339Nano)}
+	}
+	return rv.Interface()
+}
+
+// decodeValue 还原取值：整数还原为 int64，其他数字为 float64，带标记的时间还原为 time.Time
+func decodeValue(v any) any {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+		return t.String()
+	case map[string]any:
+		if s, ok := t[timeKey].(string); ok && len(t) == 1 {
+			if tm, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return tm
+			}
+		}
+		return t
+	default:
+		return v
+	}
+}
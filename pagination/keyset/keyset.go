@@ -0,0 +1,327 @@
+package keyset
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+// DefaultTiebreaker 默认的唯一排序列，保证排序的全序性
+const DefaultTiebreaker = "id"
+
+var (
+	// ErrInvalidCursor 游标无法解码
+	ErrInvalidCursor = errors.New("invalid keyset cursor")
+	// ErrCursorMismatch 游标与当前排序条件不一致
+	ErrCursorMismatch = errors.New("keyset cursor does not match sorting")
+	// ErrInvalidColumn 非法的排序列
+	ErrInvalidColumn = errors.New("invalid keyset column")
+)
+
+// columnRegexp 允许的列名（不支持 JSON 子字段）
+var columnRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Column 参与 keyset 比较的排序列
+type Column struct {
+	Field string
+	Desc  bool
+}
+
+// Columns 根据 Sorting 生成排序列，若其中不包含 tiebreaker 则以升序追加到末尾
+func Columns(sortings []*paginationV1.Sorting, tiebreaker string) ([]Column, error) {
+	tiebreaker = strings.TrimSpace(tiebreaker)
+	if tiebreaker == "" {
+		tiebreaker = DefaultTiebreaker
+	}
+
+	columns := make([]Column, 0, len(sortings)+1)
+	seen := make(map[string]struct{}, len(sortings)+1)
+	for _, s := range sortings {
+		if s == nil {
+			continue
+		}
+		f := strings.TrimSpace(s.GetField())
+		if f == "" {
+			continue
+		}
+		if !columnRegexp.MatchString(f) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidColumn, f)
+		}
+		if _, ok := seen[f]; ok {
+			continue
+		}
+		seen[f] = struct{}{}
+		columns = append(columns, Column{Field: f, Desc: s.GetDirection() == paginationV1.Sorting_DESC})
+	}
+
+	if _, ok := seen[tiebreaker]; !ok {
+		columns = append(columns, Column{Field: tiebreaker})
+	}
+
+	return columns, nil
+}
+
+// signature 排序列签名，用于校验游标与排序条件是否一致
+func signature(columns []Column) string {
+	parts := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if c.Desc {
+			parts = append(parts, "-"+c.Field)
+		} else {
+			parts = append(parts, c.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Op 比较运算符
+type Op int
+
+const (
+	OpEQ Op = iota
+	OpGT
+	OpLT
+)
+
+// String 返回 SQL 形式的运算符
+func (o Op) String() string {
+	switch o {
+	case OpGT:
+		return ">"
+	case OpLT:
+		return "<"
+	default:
+		return "="
+	}
+}
+
+// Term 单个比较条件：Field Op Value
+type Term struct {
+	Field string
+	Op    Op
+	Value any
+}
+
+// Seek 一次 keyset 分页请求的状态
+type Seek struct {
+	Columns []Column
+	Cursor  *Cursor
+	Size    int
+
+	opts *options
+}
+
+// NewSeek 解析 token 并生成 Seek，token 为空时表示第一页。
+// opts 中的编解码器与 scope 同时用于解析 token 和生成 next/prev token。
+func NewSeek(token string, size int, sortings []*paginationV1.Sorting, tiebreaker string, opts ...Option) (*Seek, error) {
+	columns, err := Columns(sortings, tiebreaker)
+	if err != nil {
+		return nil, err
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	s := &Seek{
+		Columns: columns,
+		Size:    size,
+		opts:    newOptions(opts),
+	}
+
+	if token != "" {
+		if s.Cursor, err = s.opts.decode(token, columns); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Backward 是否向前翻页（使用 prev token）
+func (s *Seek) Backward() bool {
+	return s.Cursor != nil && s.Cursor.Backward
+}
+
+// Limit 查询条数，多取一条用于判断是否还有更多数据
+func (s *Seek) Limit() int {
+	return s.Size + 1
+}
+
+// OrderBy 返回实际查询使用的排序列，向前翻页时方向取反
+func (s *Seek) OrderBy() []Column {
+	if !s.Backward() {
+		return s.Columns
+	}
+	out := make([]Column, len(s.Columns))
+	for i, c := range s.Columns {
+		out[i] = Column{Field: c.Field, Desc: !c.Desc}
+	}
+	return out
+}
+
+// Uniform 所有排序列方向是否一致（一致时可使用行值比较）
+func (s *Seek) Uniform() bool {
+	for _, c := range s.Columns[1:] {
+		if c.Desc != s.Columns[0].Desc {
+			return false
+		}
+	}
+	return true
+}
+
+// Conditions 返回展开后的 keyset 条件（外层为 OR，内层为 AND），无游标时返回 nil。
+// 对于排序列 (a, b, c)，向后翻页时生成：
+//
+//	a > ? OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?)
+//
+// 其中每一列的比较方向由其排序方向决定。
+func (s *Seek) Conditions() [][]Term {
+	if s.Cursor == nil {
+		return nil
+	}
+
+	order := s.OrderBy()
+	groups := make([][]Term, 0, len(order))
+	for i, c := range order {
+		group := make([]Term, 0, i+1)
+		for j := 0; j < i; j++ {
+			group = append(group, Term{Field: order[j].Field, Op: OpEQ, Value: s.Cursor.Values[j]})
+		}
+		op := OpGT
+		if c.Desc {
+			op = OpLT
+		}
+		group = append(group, Term{Field: c.Field, Op: op, Value: s.Cursor.Values[i]})
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// SQL 生成参数化的 keyset 条件（使用 ? 占位符），无游标时返回空字符串。
+// 排序方向一致时使用行值比较 (a, b) > (?, ?)，否则使用展开的 OR 形式。
+// quote 用于转换列名，为 nil 时原样输出。
+func (s *Seek) SQL(quote func(string) string) (string, []any) {
+	if s.Cursor == nil {
+		return "", nil
+	}
+	if quote == nil {
+		quote = func(f string) string { return f }
+	}
+
+	if s.Uniform() {
+		order := s.OrderBy()
+		cols := make([]string, 0, len(order))
+		holders := make([]string, 0, len(order))
+		for _, c := range order {
+			cols = append(cols, quote(c.Field))
+			holders = append(holders, "?")
+		}
+		op := OpGT
+		if order[0].Desc {
+			op = OpLT
+		}
+		if len(cols) == 1 {
+			return fmt.Sprintf("%s %s ?", cols[0], op), append([]any(nil), s.Cursor.Values...)
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(cols, ", "), op, strings.Join(holders, ", ")),
+			append([]any(nil), s.Cursor.Values...)
+	}
+
+	var args []any
+	ors := make([]string, 0, len(s.Columns))
+	for _, group := range s.Conditions() {
+		ands := make([]string, 0, len(group))
+		for _, t := range group {
+			ands = append(ands, fmt.Sprintf("%s %s ?", quote(t.Field), t.Op))
+			args = append(args, t.Value)
+		}
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// Select 返回补充了排序列的字段选择路径，以及调用方未请求、需要在结果中清除的排序列（见 Clear）。
+// 生成游标需要读取边界行在每个排序列上的值，字段掩码未包含这些列时只能取到零值，
+// next token 将始终指向同一位置。paths 为空或包含 "*" 时表示选择全部字段，原样返回。
+// resolve 用于将字段路径转换为列名（如 fieldmap.Mapping.Resolve），为 nil 时原样比较。
+func (s *Seek) Select(paths []string, resolve func(string) string) ([]string, []Column) {
+	if s == nil || len(paths) == 0 {
+		return paths, nil
+	}
+	if resolve == nil {
+		resolve = func(f string) string { return f }
+	}
+
+	selected := make(map[string]struct{}, len(paths))
+	for _, p := range paths {
+		p = strings.TrimSpace(resolve(p))
+		if p == "*" {
+			return paths, nil
+		}
+		selected[normalizeColumn(p)] = struct{}{}
+	}
+
+	out := append(make([]string, 0, len(paths)+len(s.Columns)), paths...)
+	var extra []Column
+	for _, c := range s.Columns {
+		if _, ok := selected[normalizeColumn(c.Field)]; ok {
+			continue
+		}
+		out = append(out, c.Field)
+		extra = append(extra, c)
+	}
+	return out, extra
+}
+
+// Page 截取查询结果并生成 next/prev token。
+// rows 为按 OrderBy 查询并多取一条的结果，返回的 items 始终按原始排序方向排列。
+func Page[T any](s *Seek, rows []*T) (items []*T, nextToken, prevToken string, err error) {
+	hasMore := len(rows) > s.Size
+	if hasMore {
+		rows = rows[:s.Size]
+	}
+
+	backward := s.Backward()
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	if len(rows) == 0 {
+		return rows, "", "", nil
+	}
+
+	// 向后翻页：还有更多数据时才有下一页；带游标时一定存在上一页
+	// 向前翻页：一定存在下一页；还有更多数据时才有上一页
+	withNext := hasMore
+	withPrev := s.Cursor != nil
+	if backward {
+		withNext = true
+		withPrev = hasMore
+	}
+
+	if withNext {
+		if nextToken, err = s.tokenOf(rows[len(rows)-1], false); err != nil {
+			return nil, "", "", err
+		}
+	}
+	if withPrev {
+		if prevToken, err = s.tokenOf(rows[0], true); err != nil {
+			return nil, "", "", err
+		}
+	}
+
+	return rows, nextToken, prevToken, nil
+}
+
+func (s *Seek) tokenOf(row any, backward bool) (string, error) {
+	values, err := ValuesOf(row, s.Columns)
+	if err != nil {
+		return "", err
+	}
+	return s.opts.encode(s.Columns, values, backward)
+}
@@ -0,0 +1,307 @@
+package keyset
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination"
+)
+
+type testRow struct {
+	ID        int64     `json:"id"`
+	Name      string    `gorm:"column:name"`
+	Score     float64   `json:"score"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func TestColumns_AppendTiebreaker(t *testing.T) {
+	cols, err := Columns([]*paginationV1.Sorting{
+		{Field: "name", Direction: paginationV1.Sorting_DESC},
+		nil,
+		{Field: ""},
+		{Field: "name"},
+	}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Column{{Field: "name", Desc: true}, {Field: "id"}}
+	if !reflect.DeepEqual(cols, want) {
+		t.Fatalf("got %v, want %v", cols, want)
+	}
+
+	cols, err = Columns([]*paginationV1.Sorting{{Field: "id", Direction: paginationV1.Sorting_DESC}}, "id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cols) != 1 || !cols[0].Desc {
+		t.Fatalf("tiebreaker already present should not be appended, got %v", cols)
+	}
+
+	if _, err = Columns([]*paginationV1.Sorting{{Field: "name; drop"}}, ""); !errors.Is(err, ErrInvalidColumn) {
+		t.Fatalf("expected ErrInvalidColumn, got %v", err)
+	}
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cols := []Column{{Field: "created_at", Desc: true}, {Field: "score"}, {Field: "name"}, {Field: "id"}}
+	ts := time.Date(2025, 3, 4, 5, 6, 7, 890, time.UTC)
+	name := "alice"
+
+	token, err := Encode(cols, []any{ts, 1.5, &name, int64(9007199254740993)}, true)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	c, err := Decode(token, cols)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if !c.Backward {
+		t.Fatal("expected backward cursor")
+	}
+	if got, ok := c.Values[0].(time.Time); !ok || !got.Equal(ts) {
+		t.Fatalf("time value mismatch: %#v", c.Values[0])
+	}
+	if c.Values[1] != 1.5 {
+		t.Fatalf("float value mismatch: %#v", c.Values[1])
+	}
+	if c.Values[2] != "alice" {
+		t.Fatalf("string value mismatch: %#v", c.Values[2])
+	}
+	if c.Values[3] != int64(9007199254740993) {
+		t.Fatalf("int64 value lost precision: %#v", c.Values[3])
+	}
+}
+
+func TestCursor_Errors(t *testing.T) {
+	cols := []Column{{Field: "name"}, {Field: "id"}}
+	token, err := Encode(cols, []any{"a", 1}, false)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	if _, err = Decode(token, []Column{{Field: "name", Desc: true}, {Field: "id"}}); !errors.Is(err, ErrCursorMismatch) {
+		t.Fatalf("expected ErrCursorMismatch, got %v", err)
+	}
+	if _, err = Decode("%%%", cols); !errors.Is(err, pagination.ErrCursorMalformed) {
+		t.Fatalf("expected ErrCursorMalformed, got %v", err)
+	}
+	if _, err = Encode(cols, []any{"a"}, false); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestCursor_SignedCodecAndScope(t *testing.T) {
+	codec, err := pagination.NewSignedCursorCodec([]pagination.CursorKey{{ID: "k1", Secret: []byte("0123456789abcdef0123456789abcdef")}})
+	if err != nil {
+		t.Fatalf("new codec failed: %v", err)
+	}
+
+	sortings := []*paginationV1.Sorting{{Field: "name"}}
+	scope := pagination.CursorScope(nil, sortings, nil)
+	cols, _ := Columns(sortings, "")
+
+	token, err := Encode(cols, []any{"bob", 1}, false, WithCodec(codec), WithScope(scope))
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	seek, err := NewSeek(token, 10, sortings, "", WithCodec(codec), WithScope(scope))
+	if err != nil {
+		t.Fatalf("new seek failed: %v", err)
+	}
+	if seek.Cursor.Values[0] != "bob" {
+		t.Fatalf("unexpected cursor values: %#v", seek.Cursor.Values)
+	}
+
+	// 生成的 prev token 同样经过签名
+	_, _, prev, err := Page(seek, []*testRow{{ID: 2, Name: "c"}, {ID: 3, Name: "d"}})
+	if err != nil {
+		t.Fatalf("page failed: %v", err)
+	}
+	if c, err := Decode(prev, cols, WithCodec(codec), WithScope(scope)); err != nil || !c.Backward {
+		t.Fatalf("decode prev failed: %v", err)
+	}
+
+	// 其他查询范围下复用 token
+	otherScope := pagination.CursorScope(&paginationV1.FilterExpr{Type: paginationV1.ExprType_AND}, sortings, nil)
+	if _, err = NewSeek(token, 10, sortings, "", WithCodec(codec), WithScope(otherScope)); !errors.Is(err, pagination.ErrCursorScopeMismatch) {
+		t.Fatalf("expected ErrCursorScopeMismatch, got %v", err)
+	}
+
+	// 未签名的 token
+	plain, _ := Encode(cols, []any{"bob", 1}, false, WithScope(scope))
+	if _, err = NewSeek(plain, 10, sortings, "", WithCodec(codec), WithScope(scope)); !errors.Is(err, pagination.ErrCursorMalformed) {
+		t.Fatalf("expected ErrCursorMalformed, got %v", err)
+	}
+}
+
+func TestSeek_SQL(t *testing.T) {
+	cols := []Column{{Field: "name"}, {Field: "id"}}
+
+	// 方向一致时使用行值比较
+	token, _ := Encode(cols, []any{"bob", 7}, false)
+	seek, err := NewSeek(token, 10, []*paginationV1.Sorting{{Field: "name"}}, "id")
+	if err != nil {
+		t.Fatalf("new seek failed: %v", err)
+	}
+	sqlStr, args := seek.SQL(nil)
+	if sqlStr != "(name, id) > (?, ?)" {
+		t.Fatalf("unexpected sql: %s", sqlStr)
+	}
+	if !reflect.DeepEqual(args, []any{"bob", int64(7)}) {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	// 向前翻页时比较与排序方向均取反
+	token, _ = Encode(cols, []any{"bob", 7}, true)
+	seek, _ = NewSeek(token, 10, []*paginationV1.Sorting{{Field: "name"}}, "id")
+	sqlStr, _ = seek.SQL(nil)
+	if sqlStr != "(name, id) < (?, ?)" {
+		t.Fatalf("unexpected backward sql: %s", sqlStr)
+	}
+	if order := seek.OrderBy(); !order[0].Desc || !order[1].Desc {
+		t.Fatalf("expected reversed order, got %v", order)
+	}
+
+	// 混合方向时展开为 OR 形式
+	mixed := []*paginationV1.Sorting{{Field: "score", Direction: paginationV1.Sorting_DESC}, {Field: "name"}}
+	mixedCols, _ := Columns(mixed, "id")
+	token, _ = Encode(mixedCols, []any{3.5, "bob", 7}, false)
+	seek, err = NewSeek(token, 10, mixed, "id")
+	if err != nil {
+		t.Fatalf("new seek failed: %v", err)
+	}
+	sqlStr, args = seek.SQL(func(f string) string { return "`" + f + "`" })
+	want := "((`score` < ?) OR (`score` = ? AND `name` > ?) OR (`score` = ? AND `name` = ? AND `id` > ?))"
+	if sqlStr != want {
+		t.Fatalf("unexpected mixed sql:\n got %s\nwant %s", sqlStr, want)
+	}
+	if len(args) != 6 {
+		t.Fatalf("unexpected args: %#v", args)
+	}
+
+	// 无游标时没有条件
+	seek, _ = NewSeek("", 10, mixed, "id")
+	if sqlStr, _ = seek.SQL(nil); sqlStr != "" || seek.Conditions() != nil {
+		t.Fatalf("expected no condition for first page, got %q", sqlStr)
+	}
+}
+
+func TestPage_Tokens(t *testing.T) {
+	sortings := []*paginationV1.Sorting{{Field: "score", Direction: paginationV1.Sorting_DESC}}
+	rows := []*testRow{
+		{ID: 1, Score: 9},
+		{ID: 2, Score: 8},
+		{ID: 3, Score: 7},
+	}
+
+	// 第一页：多取到一条，存在下一页，不存在上一页
+	seek, _ := NewSeek("", 2, sortings, "")
+	items, next, prev, err := Page(seek, rows)
+	if err != nil {
+		t.Fatalf("page failed: %v", err)
+	}
+	if len(items) != 2 || next == "" || prev != "" {
+		t.Fatalf("unexpected first page: %d items, next=%q prev=%q", len(items), next, prev)
+	}
+
+	// 下一页游标指向最后一行
+	seek, err = NewSeek(next, 2, sortings, "")
+	if err != nil {
+		t.Fatalf("decode next failed: %v", err)
+	}
+	if !reflect.DeepEqual(seek.Cursor.Values, []any{int64(8), int64(2)}) {
+		t.Fatalf("unexpected cursor values: %#v", seek.Cursor.Values)
+	}
+	items, next, prev, _ = Page(seek, rows[2:])
+	if len(items) != 1 || next != "" || prev == "" {
+		t.Fatalf("unexpected last page: %d items, next=%q prev=%q", len(items), next, prev)
+	}
+
+	// 向前翻页：查询结果为逆序，输出需还原顺序
+	seek, _ = NewSeek(prev, 2, sortings, "")
+	if !seek.Backward() {
+		t.Fatal("expected backward seek")
+	}
+	items, next, prev, _ = Page(seek, []*testRow{rows[1], rows[0]})
+	if len(items) != 2 || items[0].ID != 1 || items[1].ID != 2 {
+		t.Fatalf("unexpected backward items: %v", items)
+	}
+	if next == "" || prev != "" {
+		t.Fatalf("unexpected backward tokens: next=%q prev=%q", next, prev)
+	}
+}
+
+func TestValuesOf(t *testing.T) {
+	ts := time.Now()
+	row := &testRow{ID: 5, Name: "n", Score: 1.25, CreatedAt: ts}
+	cols := []Column{{Field: "createdAt"}, {Field: "name"}, {Field: "score"}, {Field: "id"}}
+
+	values, err := ValuesOf(row, cols)
+	if err != nil {
+		t.Fatalf("values of failed: %v", err)
+	}
+	if !reflect.DeepEqual(values, []any{ts, "n", 1.25, int64(5)}) {
+		t.Fatalf("unexpected values: %#v", values)
+	}
+
+	type mongoRow struct {
+		ID string `bson:"_id"`
+	}
+	values, err = ValuesOf(mongoRow{ID: "abc"}, []Column{{Field: "_id"}})
+	if err != nil || values[0] != "abc" {
+		t.Fatalf("unexpected _id value: %#v, %v", values, err)
+	}
+
+	values, err = ValuesOf(map[string]any{"user_id": 3}, []Column{{Field: "userId"}})
+	if err != nil || values[0] != 3 {
+		t.Fatalf("unexpected map value: %#v, %v", values, err)
+	}
+
+	if _, err = ValuesOf(row, []Column{{Field: "missing"}}); !errors.Is(err, ErrInvalidColumn) {
+		t.Fatalf("expected ErrInvalidColumn, got %v", err)
+	}
+}
+
+func TestSeek_SelectAndClear(t *testing.T) {
+	sortings := []*paginationV1.Sorting{{Field: "created_at", Direction: paginationV1.Sorting_DESC}}
+	seek, _ := NewSeek("", 2, sortings, "")
+
+	paths, extra := seek.Select([]string{"name"}, nil)
+	if !reflect.DeepEqual(paths, []string{"name", "created_at", "id"}) {
+		t.Fatalf("unexpected paths: %v", paths)
+	}
+	if !reflect.DeepEqual(extra, []Column{{Field: "created_at", Desc: true}, {Field: "id"}}) {
+		t.Fatalf("unexpected extra columns: %v", extra)
+	}
+
+	// 已请求的排序列（含映射后的字段名）不重复追加
+	resolve := func(f string) string {
+		if f == "ts" {
+			return "created_at"
+		}
+		return f
+	}
+	paths, extra = seek.Select([]string{"ts", "ID"}, resolve)
+	if !reflect.DeepEqual(paths, []string{"ts", "ID"}) || extra != nil {
+		t.Fatalf("unexpected select: %v, %v", paths, extra)
+	}
+
+	// 选择全部字段时原样返回
+	if paths, extra = seek.Select(nil, nil); paths != nil || extra != nil {
+		t.Fatalf("unexpected select for empty mask: %v, %v", paths, extra)
+	}
+	if paths, extra = seek.Select([]string{"*"}, nil); len(paths) != 1 || extra != nil {
+		t.Fatalf("unexpected select for wildcard: %v, %v", paths, extra)
+	}
+
+	rows := []*testRow{{ID: 1, Name: "a", CreatedAt: time.Now()}, nil}
+	Clear(rows, []Column{{Field: "created_at"}, {Field: "id"}})
+	if rows[0].ID != 0 || !rows[0].CreatedAt.IsZero() || rows[0].Name != "a" {
+		t.Fatalf("unexpected cleared row: %+v", rows[0])
+	}
+}
@@ -0,0 +1,145 @@
+package keyset
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/tx7do/go-utils/stringcase"
+)
+
+// tagKeys 用于匹配列名的结构体标签，按优先级排列
+var tagKeys = []string{"gorm", "ch", "bson", "db", "sql", "json"}
+
+// ValuesOf 从结构体（或其指针、map[string]any）中按列名取出排序列的值。
+// 列名依次与 gorm column、ch、bson、db、sql、json 标签及字段名的 snake_case 形式匹配。
+func ValuesOf(row any, columns []Column) ([]any, error) {
+	rv := reflect.ValueOf(row)
+	for rv.IsValid() && rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("%w: nil row", ErrInvalidColumn)
+		}
+		rv = rv.Elem()
+	}
+
+	values := make([]any, 0, len(columns))
+	switch rv.Kind() {
+	case reflect.Map:
+		keyType := rv.Type().Key()
+		if keyType.Kind() != reflect.String {
+			return nil, fmt.Errorf("%w: unsupported row type %T", ErrInvalidColumn, row)
+		}
+		for _, c := range columns {
+			v := rv.MapIndex(reflect.ValueOf(c.Field).Convert(keyType))
+			if !v.IsValid() {
+				v = rv.MapIndex(reflect.ValueOf(normalizeColumn(c.Field)).Convert(keyType))
+			}
+			if !v.IsValid() {
+				return nil, fmt.Errorf("%w: %s not found", ErrInvalidColumn, c.Field)
+			}
+			values = append(values, v.Interface())
+		}
+	case reflect.Struct:
+		for _, c := range columns {
+			v, ok := fieldByColumn(rv, normalizeColumn(c.Field))
+			if !ok {
+				return nil, fmt.Errorf("%w: %s not found", ErrInvalidColumn, c.Field)
+			}
+			values = append(values, v.Interface())
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported row type %T", ErrInvalidColumn, row)
+	}
+
+	return values, nil
+}
+
+// Clear 将各行在指定列上的字段置为零值，用于移除仅为生成游标而额外选择的排序列（见 Seek.Select）
+func Clear[T any](rows []*T, columns []Column) {
+	if len(columns) == 0 {
+		return
+	}
+	for _, row := range rows {
+		if row == nil {
+			continue
+		}
+		rv := reflect.ValueOf(row).Elem()
+		if rv.Kind() != reflect.Struct {
+			continue
+		}
+		for _, c := range columns {
+			if v, ok := fieldByColumn(rv, normalizeColumn(c.Field)); ok && v.CanSet() {
+				v.Set(reflect.Zero(v.Type()))
+			}
+		}
+	}
+}
+
+// fieldByColumn 在结构体（含匿名嵌入结构体）中查找与列名匹配的字段
+func fieldByColumn(rv reflect.Value, column string) (reflect.Value, bool) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		if columnName(sf) == column {
+			return rv.Field(i), true
+		}
+	}
+
+	// 匿名嵌入结构体
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.Anonymous {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() != reflect.Struct {
+			continue
+		}
+		if v, ok := fieldByColumn(fv, column); ok {
+			return v, true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// columnName 返回结构体字段对应的列名（snake_case）
+func columnName(sf reflect.StructField) string {
+	for _, key := range tagKeys {
+		tag, ok := sf.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+
+		var name string
+		if key == "gorm" {
+			for _, part := range strings.Split(tag, ";") {
+				if k, v, found := strings.Cut(strings.TrimSpace(part), ":"); found && strings.EqualFold(k, "column") {
+					name = v
+				}
+			}
+		} else {
+			name, _, _ = strings.Cut(tag, ",")
+		}
+
+		if name != "" && name != "-" {
+			return normalizeColumn(name)
+		}
+	}
+	return normalizeColumn(sf.Name)
+}
+
+// normalizeColumn 将列名转换为 snake_case，保留前导下划线（如 MongoDB 的 _id）
+func normalizeColumn(name string) string {
+	trimmed := strings.TrimLeft(name, "_")
+	return name[:len(name)-len(trimmed)] + stringcase.ToSnakeCase(trimmed)
+}
@@ -0,0 +1,141 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+// PagingResult 通用分页返回，各数据库仓库的列表查询均返回该结构
+type PagingResult[E any] struct {
+	Items []*E   `json:"items"`
+	Total uint64 `json:"total"`
+
+	// NextToken/PrevToken 仅 Token 分页有效
+	NextToken string `json:"next_token,omitempty"`
+	PrevToken string `json:"prev_token,omitempty"`
+
+	// CountMode 实际使用的总数统计方式，IsEstimate 为 true 时 Total 为估算值或下限
+	CountMode  CountMode `json:"count_mode"`
+	IsEstimate bool      `json:"is_estimate,omitempty"`
+
+	// Meta 完整的分页元数据
+	Meta *paginationV1.PaginationResponseMeta `json:"meta,omitempty"`
+}
+
+// NewPagingResult 根据分页器状态生成分页结果，pager 为 nil 表示未分页。
+// total 会写回 pager，Token 分页时调用方应先通过 SetNextToken/SetPrevToken 设置 token。
+func NewPagingResult[E any](items []*E, total int64, pager Paginator) *PagingResult[E] {
+	return NewPagingResultWithCount(items, ExactCount(total), pager)
+}
+
+// NewPagingResultWithCount 与 NewPagingResult 相同，但使用指定统计方式得到的总数
+func NewPagingResultWithCount[E any](items []*E, count Count, pager Paginator) *PagingResult[E] {
+	if count.Total < 0 {
+		count.Total = 0
+	}
+	if pager != nil && count.Mode != CountModeSkip {
+		pager.SetTotal(count.Total)
+	}
+
+	res := &PagingResult[E]{
+		Items:      items,
+		Total:      uint64(count.Total),
+		CountMode:  count.Mode,
+		IsEstimate: count.IsEstimate,
+		Meta:       buildResponseMeta(pager, count, len(items)),
+	}
+	if pager != nil {
+		res.NextToken = pager.NextToken()
+		res.PrevToken = pager.PrevToken()
+	}
+	return res
+}
+
+// BuildResponseMeta 根据分页器状态生成 PaginationResponseMeta，pager 为 nil 表示未分页。
+// currentSize 为本页实际返回的条数。
+func BuildResponseMeta(pager Paginator, total int64, currentSize int) *paginationV1.PaginationResponseMeta {
+	return buildResponseMeta(pager, ExactCount(total), currentSize)
+}
+
+// buildResponseMeta 生成 PaginationResponseMeta，未统计总数时不返回 total/total_pages
+func buildResponseMeta(pager Paginator, count Count, currentSize int) *paginationV1.PaginationResponseMeta {
+	total := count.Total
+	if total < 0 {
+		total = 0
+	}
+	counted := count.Mode != CountModeSkip
+
+	meta := &paginationV1.PaginationResponseMeta{
+		CurrentSize: proto.Uint32(uint32(currentSize)),
+	}
+	if counted {
+		meta.Total = wrapperspb.UInt64(uint64(total))
+	}
+
+	if pager == nil {
+		meta.PageSize = proto.Uint32(uint32(currentSize))
+		return meta
+	}
+
+	meta.PageSize = proto.Uint32(uint32(pager.Size()))
+
+	switch pager.Mode() {
+	case ModePage:
+		if counted {
+			meta.TotalPages = wrapperspb.UInt32(uint32(pager.TotalPages()))
+		}
+		meta.CurrentPage = wrapperspb.UInt32(uint32(pager.Page()))
+	case ModeOffset:
+		if counted {
+			meta.TotalPages = wrapperspb.UInt32(uint32(pager.TotalPages()))
+		}
+		meta.CurrentOffset = wrapperspb.UInt64(uint64(pager.Offset()))
+	case ModeToken:
+		if t := pager.NextToken(); t != "" {
+			meta.NextToken = proto.String(t)
+		}
+		if t := pager.PrevToken(); t != "" {
+			meta.PrevToken = proto.String(t)
+		}
+	}
+
+	return meta
+}
+
+// NewPaginationResponse 将分页结果转换为 PaginationResponse，items 打包为 Any，供 gRPC 接口直接返回
+func NewPaginationResponse[E any, PE interface {
+	*E
+	proto.Message
+}](res *PagingResult[E]) (*paginationV1.PaginationResponse, error) {
+	if res == nil {
+		return nil, errors.New("paging result is nil")
+	}
+
+	data := make([]*anypb.Any, 0, len(res.Items))
+	for i, item := range res.Items {
+		if item == nil {
+			continue
+		}
+		a, err := anypb.New(PE(item))
+		if err != nil {
+			return nil, fmt.Errorf("pack item %d failed: %w", i, err)
+		}
+		data = append(data, a)
+	}
+
+	meta := res.Meta
+	if meta == nil {
+		meta = buildResponseMeta(nil, Count{Total: int64(res.Total), Mode: res.CountMode, IsEstimate: res.IsEstimate}, len(res.Items))
+	}
+
+	return &paginationV1.PaginationResponse{
+		Meta: meta,
+		Data: data,
+	}, nil
+}
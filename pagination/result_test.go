@@ -0,0 +1,100 @@
+package pagination_test
+
+import (
+	"testing"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+	"github.com/tx7do/go-crud/pagination"
+	"github.com/tx7do/go-crud/pagination/paginator"
+)
+
+func TestNewPagingResult_Page(t *testing.T) {
+	items := []*paginationV1.Sorting{{Field: "a"}, {Field: "b"}}
+	res := pagination.NewPagingResult(items, 25, paginator.NewPagePaginator(3, 10))
+
+	if res.Total != 25 || len(res.Items) != 2 {
+		t.Fatalf("unexpected result: total=%d items=%d", res.Total, len(res.Items))
+	}
+
+	m := res.Meta
+	if m.GetTotal().GetValue() != 25 || m.GetTotalPages().GetValue() != 3 || m.GetCurrentPage().GetValue() != 3 {
+		t.Fatalf("unexpected page meta: %v", m)
+	}
+	if m.GetPageSize() != 10 || m.GetCurrentSize() != 2 {
+		t.Fatalf("unexpected size meta: %v", m)
+	}
+	if m.CurrentOffset != nil || m.NextToken != nil {
+		t.Fatalf("offset/token fields should not be set: %v", m)
+	}
+}
+
+func TestNewPagingResult_Offset(t *testing.T) {
+	res := pagination.NewPagingResult([]*paginationV1.Sorting{{Field: "a"}}, 7, paginator.NewOffsetPaginator(5, 5))
+
+	m := res.Meta
+	if m.GetCurrentOffset().GetValue() != 5 || m.GetTotalPages().GetValue() != 2 || m.GetPageSize() != 5 {
+		t.Fatalf("unexpected offset meta: %v", m)
+	}
+	if m.CurrentPage != nil {
+		t.Fatalf("current page should not be set: %v", m)
+	}
+}
+
+func TestNewPagingResult_Token(t *testing.T) {
+	pager := paginator.NewTokenPaginator("t1", 2)
+	pager.SetNextToken("t2")
+
+	res := pagination.NewPagingResult([]*paginationV1.Sorting{{Field: "a"}, {Field: "b"}}, 9, pager)
+	if res.NextToken != "t2" || res.PrevToken != "" {
+		t.Fatalf("unexpected tokens: next=%q prev=%q", res.NextToken, res.PrevToken)
+	}
+
+	m := res.Meta
+	if m.GetNextToken() != "t2" || m.PrevToken != nil || m.GetPageSize() != 2 {
+		t.Fatalf("unexpected token meta: %v", m)
+	}
+	if m.TotalPages != nil || m.CurrentPage != nil {
+		t.Fatalf("page fields should not be set: %v", m)
+	}
+}
+
+func TestNewPagingResult_NoPaging(t *testing.T) {
+	res := pagination.NewPagingResult([]*paginationV1.Sorting{{Field: "a"}}, 1, nil)
+
+	m := res.Meta
+	if m.GetTotal().GetValue() != 1 || m.GetPageSize() != 1 || m.GetCurrentSize() != 1 {
+		t.Fatalf("unexpected meta: %v", m)
+	}
+}
+
+func TestNewPaginationResponse(t *testing.T) {
+	items := []*paginationV1.Sorting{
+		{Field: "a", Direction: paginationV1.Sorting_DESC},
+		nil,
+		{Field: "b"},
+	}
+	res := pagination.NewPagingResult(items, 2, paginator.NewPagePaginator(1, 10))
+
+	resp, err := pagination.NewPaginationResponse(res)
+	if err != nil {
+		t.Fatalf("new pagination response failed: %v", err)
+	}
+	if resp.GetMeta() != res.Meta {
+		t.Fatal("meta should be reused")
+	}
+	if len(resp.GetData()) != 2 {
+		t.Fatalf("expected 2 packed items, got %d", len(resp.GetData()))
+	}
+
+	var s paginationV1.Sorting
+	if err = resp.GetData()[0].UnmarshalTo(&s); err != nil {
+		t.Fatalf("unpack failed: %v", err)
+	}
+	if s.GetField() != "a" || s.GetDirection() != paginationV1.Sorting_DESC {
+		t.Fatalf("unexpected unpacked item: %v", &s)
+	}
+
+	if _, err = pagination.NewPaginationResponse[paginationV1.Sorting](nil); err == nil {
+		t.Fatal("expected error for nil result")
+	}
+}
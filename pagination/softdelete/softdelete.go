@@ -0,0 +1,100 @@
+package softdelete
+
+import (
+	"context"
+	"time"
+
+	"github.com/tx7do/go-crud/viewer"
+)
+
+const (
+	// DefaultDeletedAtColumn 记录删除时间的默认列名，列值为 NULL 表示未删除
+	DefaultDeletedAtColumn = "deleted_at"
+
+	// DefaultDeletedByColumn 记录删除者的默认列名
+	DefaultDeletedByColumn = "deleted_by"
+)
+
+// Mode 查询时对已软删除记录的处理方式
+type Mode int
+
+const (
+	// ModeExcludeDeleted 默认模式：排除已软删除的记录
+	ModeExcludeDeleted Mode = iota
+
+	// ModeWithDeleted 包含已软删除的记录
+	ModeWithDeleted
+
+	// ModeOnlyDeleted 仅返回已软删除的记录
+	ModeOnlyDeleted
+)
+
+type modeKey struct{}
+
+type hardDeleteKey struct{}
+
+// WithDeleted 返回包含已软删除记录的 ctx，适用于查询、计数、更新与删除
+func WithDeleted(ctx context.Context) context.Context {
+	return WithMode(ctx, ModeWithDeleted)
+}
+
+// OnlyDeleted 返回仅匹配已软删除记录的 ctx，适用于回收站列表、恢复与清理
+func OnlyDeleted(ctx context.Context) context.Context {
+	return WithMode(ctx, ModeOnlyDeleted)
+}
+
+// WithMode 在 ctx 中设置软删除的查询模式
+func WithMode(ctx context.Context, mode Mode) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, modeKey{}, mode)
+}
+
+// ModeFromContext 读取 ctx 中的软删除查询模式，未设置时返回 ModeExcludeDeleted
+func ModeFromContext(ctx context.Context) Mode {
+	if ctx == nil {
+		return ModeExcludeDeleted
+	}
+	if mode, ok := ctx.Value(modeKey{}).(Mode); ok {
+		return mode
+	}
+	return ModeExcludeDeleted
+}
+
+// HardDelete 返回执行物理删除的 ctx：删除操作不再转换为更新 deleted_at
+func HardDelete(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, hardDeleteKey{}, true)
+}
+
+// IsHardDelete 判断 ctx 是否要求物理删除
+func IsHardDelete(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	v, _ := ctx.Value(hardDeleteKey{}).(bool)
+	return v
+}
+
+// DeletedBy 从 ctx 中的 Viewer 读取删除者 ID；缺少 Viewer 或用户 ID 为 0 时返回 false
+func DeletedBy(ctx context.Context) (uint64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	vc, ok := viewer.FromContext(ctx)
+	if !ok || vc == nil || vc.UserID() == 0 {
+		return 0, false
+	}
+	return vc.UserID(), true
+}
+
+// Cutoff 返回清理的截止时间：删除时间早于该时间的记录会被物理删除；olderThan <= 0 时返回当前时间
+func Cutoff(olderThan time.Duration) time.Time {
+	if olderThan <= 0 {
+		return time.Now()
+	}
+	return time.Now().Add(-olderThan)
+}
@@ -0,0 +1,59 @@
+package softdelete
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tx7do/go-crud/viewer"
+)
+
+type testViewer struct {
+	viewer.Context
+	uid uint64
+}
+
+func (v testViewer) UserID() uint64 { return v.uid }
+
+func TestMode(t *testing.T) {
+	ctx := context.Background()
+	if ModeFromContext(ctx) != ModeExcludeDeleted {
+		t.Fatal("default mode should exclude deleted rows")
+	}
+	if ModeFromContext(WithDeleted(ctx)) != ModeWithDeleted {
+		t.Fatal("expected ModeWithDeleted")
+	}
+	if ModeFromContext(OnlyDeleted(WithDeleted(ctx))) != ModeOnlyDeleted {
+		t.Fatal("the innermost mode should win")
+	}
+
+	if IsHardDelete(ctx) || !IsHardDelete(HardDelete(ctx)) {
+		t.Fatal("unexpected hard delete flag")
+	}
+}
+
+func TestDeletedBy(t *testing.T) {
+	if _, ok := DeletedBy(context.Background()); ok {
+		t.Fatal("missing viewer should not yield a deleter")
+	}
+
+	ctx := viewer.WithContext(context.Background(), testViewer{Context: viewer.NewNoopContext(), uid: 7})
+	if uid, ok := DeletedBy(ctx); !ok || uid != 7 {
+		t.Fatalf("unexpected deleter: %d, %v", uid, ok)
+	}
+
+	ctx = viewer.WithContext(context.Background(), testViewer{Context: viewer.NewNoopContext()})
+	if _, ok := DeletedBy(ctx); ok {
+		t.Fatal("zero user id should not yield a deleter")
+	}
+}
+
+func TestCutoff(t *testing.T) {
+	now := time.Now()
+	if c := Cutoff(time.Hour); c.After(now.Add(-time.Hour + time.Minute)) {
+		t.Fatalf("unexpected cutoff: %v", c)
+	}
+	if c := Cutoff(0); c.Before(now) {
+		t.Fatalf("non-positive duration should cut off at now: %v", c)
+	}
+}
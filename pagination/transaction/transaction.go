@@ -0,0 +1,161 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries 遇到可重试错误时默认的最大重试次数
+	DefaultMaxRetries = 3
+
+	// DefaultRetryBackoff 默认的首次重试等待时间，之后每次翻倍
+	DefaultRetryBackoff = 10 * time.Millisecond
+)
+
+// Options 事务选项
+type Options struct {
+	// Isolation 隔离级别，默认使用数据库的默认隔离级别
+	Isolation sql.IsolationLevel
+	// ReadOnly 只读事务
+	ReadOnly bool
+	// MaxRetries 最外层事务遇到可重试错误时的最大重试次数，0 表示不重试
+	MaxRetries int
+	// RetryBackoff 首次重试前的等待时间，之后每次翻倍
+	RetryBackoff time.Duration
+	// Retryable 判断错误是否可重试，默认使用 IsRetryable
+	Retryable func(err error) bool
+}
+
+// Option 事务选项设置函数
+type Option func(o *Options)
+
+// WithIsolation 设置隔离级别
+func WithIsolation(level sql.IsolationLevel) Option {
+	return func(o *Options) {
+		o.Isolation = level
+	}
+}
+
+// WithReadOnly 设置为只读事务
+func WithReadOnly() Option {
+	return func(o *Options) {
+		o.ReadOnly = true
+	}
+}
+
+// WithMaxRetries 设置最大重试次数，0 表示不重试
+func WithMaxRetries(n int) Option {
+	return func(o *Options) {
+		if n >= 0 {
+			o.MaxRetries = n
+		}
+	}
+}
+
+// WithRetryBackoff 设置首次重试前的等待时间
+func WithRetryBackoff(d time.Duration) Option {
+	return func(o *Options) {
+		if d >= 0 {
+			o.RetryBackoff = d
+		}
+	}
+}
+
+// WithRetryable 设置判断错误是否可重试的函数
+func WithRetryable(fn func(err error) bool) Option {
+	return func(o *Options) {
+		if fn != nil {
+			o.Retryable = fn
+		}
+	}
+}
+
+// NewOptions 创建事务选项，默认重试 DefaultMaxRetries 次
+func NewOptions(opts ...Option) *Options {
+	o := &Options{
+		MaxRetries:   DefaultMaxRetries,
+		RetryBackoff: DefaultRetryBackoff,
+		Retryable:    IsRetryable,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// TxOptions 转换为 database/sql 的事务选项，未设置隔离级别且非只读时返回 nil
+func (o *Options) TxOptions() *sql.TxOptions {
+	if o == nil || (o.Isolation == sql.LevelDefault && !o.ReadOnly) {
+		return nil
+	}
+	return &sql.TxOptions{Isolation: o.Isolation, ReadOnly: o.ReadOnly}
+}
+
+// Retry 执行 attempt，遇到可重试错误时按退避时间重试，ctx 取消时返回 ctx 的错误
+func (o *Options) Retry(ctx context.Context, attempt func() error) error {
+	if o == nil {
+		o = NewOptions()
+	}
+
+	backoff := o.RetryBackoff
+	for retries := 0; ; retries++ {
+		err := attempt()
+		if err == nil || retries >= o.MaxRetries || o.Retryable == nil || !o.Retryable(err) {
+			return err
+		}
+
+		if backoff > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return errors.Join(err, ctx.Err())
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+	}
+}
+
+// 可重试的 SQLSTATE：序列化失败、死锁
+var retryableSQLStates = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected (PostgreSQL)
+}
+
+// 可重试的 MySQL 错误码
+var retryableMySQLCodes = map[int]bool{
+	1205: true, // ER_LOCK_WAIT_TIMEOUT
+	1213: true, // ER_LOCK_DEADLOCK
+}
+
+// mysqlErrorRe 匹配 go-sql-driver/mysql 的错误信息，如 "Error 1213 (40001): Deadlock found ..."
+var mysqlErrorRe = regexp.MustCompile(`^Error (\d+)(?: \(([0-9A-Z]{5})\))?:`)
+
+// IsRetryable 判断错误是否为可重试的事务冲突：SQLSTATE 40001/40P01（pgx、lib/pq 等实现了 SQLState 方法的错误），
+// 以及 MySQL 错误码 1205/1213
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) && retryableSQLStates[stateErr.SQLState()] {
+		return true
+	}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if m := mysqlErrorRe.FindStringSubmatch(e.Error()); m != nil {
+			code, _ := strconv.Atoi(m[1])
+			return retryableMySQLCodes[code] || retryableSQLStates[m[2]]
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package transaction
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type stateError struct{ state string }
+
+func (e stateError) Error() string    { return "pg error " + e.state }
+func (e stateError) SQLState() string { return e.state }
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("boom"), false},
+		{stateError{"40001"}, true},
+		{fmt.Errorf("commit: %w", stateError{"40P01"}), true},
+		{stateError{"23505"}, false},
+		{errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), true},
+		{fmt.Errorf("exec: %w", errors.New("Error 1205 (HY000): Lock wait timeout exceeded")), true},
+		{errors.New("Error 1062 (23000): Duplicate entry"), false},
+	}
+	for _, c := range cases {
+		if got := IsRetryable(c.err); got != c.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestOptions_Retry(t *testing.T) {
+	o := NewOptions(WithMaxRetries(2), WithRetryBackoff(0))
+
+	attempts := 0
+	err := o.Retry(context.Background(), func() error {
+		attempts++
+		if attempts < 3 {
+			return stateError{"40001"}
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Fatalf("retry: %d, %v", attempts, err)
+	}
+
+	// 超过最大重试次数返回最后一次的错误
+	attempts = 0
+	err = o.Retry(context.Background(), func() error {
+		attempts++
+		return stateError{"40001"}
+	})
+	if err == nil || attempts != 3 {
+		t.Fatalf("exhausted: %d, %v", attempts, err)
+	}
+
+	// 不可重试的错误直接返回
+	attempts = 0
+	_ = o.Retry(context.Background(), func() error {
+		attempts++
+		return errors.New("boom")
+	})
+	if attempts != 1 {
+		t.Fatalf("non retryable error should not retry, got %d attempts", attempts)
+	}
+
+	if got := NewOptions().TxOptions(); got != nil {
+		t.Fatalf("default options should not set tx options: %+v", got)
+	}
+	if got := NewOptions(WithIsolation(sql.LevelSerializable), WithReadOnly()).TxOptions(); got.Isolation != sql.LevelSerializable || !got.ReadOnly {
+		t.Fatalf("unexpected tx options: %+v", got)
+	}
+}
@@ -1,7 +1,12 @@
+git tag v0.0.1 --force
+
 git tag api/v0.0.7 --force
 git tag pagination/v0.0.11 --force
 git tag viewer/v0.0.5 --force
 git tag audit/v0.0.2 --force
+git tag outbox/v0.0.1 --force
+git tag cache/v0.0.1 --force
+git tag telemetry/v0.0.1 --force
 
 git tag entgo/v0.0.39 --force
 git tag gorm/v0.0.18 --force
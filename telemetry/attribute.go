@@ -0,0 +1,117 @@
+package telemetry
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+// 仓库层的自定义属性，数据库相关属性使用 semconv
+const (
+	// FilterShapeKey 过滤条件的结构（字段与操作符，不包含值），如 AND(id EQ,OR(name LIKE,age GT))
+	FilterShapeKey = attribute.Key("crud.filter.shape")
+
+	// FilterConditionsKey 过滤条件的数量（包括子表达式中的条件）
+	FilterConditionsKey = attribute.Key("crud.filter.conditions")
+
+	// PaginationModeKey 分页方式：page / offset / token / none
+	PaginationModeKey = attribute.Key("crud.pagination.mode")
+
+	// RowsAffectedKey 写操作影响的行数
+	RowsAffectedKey = attribute.Key("crud.rows_affected")
+
+	// TotalKey 列表查询的总数
+	TotalKey = attribute.Key("crud.total")
+)
+
+// DBSystem 将驱动或方言名称映射为 semconv 的 db.system.name 属性
+func DBSystem(name string) attribute.KeyValue {
+	switch strings.ToLower(name) {
+	case "mysql":
+		return semconv.DBSystemNameMySQL
+	case "mariadb":
+		return semconv.DBSystemNameMariaDB
+	case "postgres", "postgresql", "pgx":
+		return semconv.DBSystemNamePostgreSQL
+	case "sqlite", "sqlite3":
+		return semconv.DBSystemNameSqlite
+	case "sqlserver", "mssql":
+		return semconv.DBSystemNameMicrosoftSQLServer
+	case "clickhouse":
+		return semconv.DBSystemNameClickhouse
+	case "mongodb", "mongo":
+		return semconv.DBSystemNameMongoDB
+	case "influxdb", "influx":
+		return semconv.DBSystemNameInfluxdb
+	case "elasticsearch", "elastic":
+		return semconv.DBSystemNameElasticsearch
+	case "cassandra":
+		return semconv.DBSystemNameCassandra
+	default:
+		return semconv.DBSystemNameKey.String(name)
+	}
+}
+
+// FilterShape 返回过滤条件的结构与条件数量，不包含条件的值，可安全地写入 Span
+func FilterShape(filter *paginationV1.FilterExpr) (string, int) {
+	if filter == nil {
+		return "", 0
+	}
+	var sb strings.Builder
+	n := writeFilterShape(&sb, filter)
+	return sb.String(), n
+}
+
+func writeFilterShape(sb *strings.Builder, filter *paginationV1.FilterExpr) int {
+	op := "AND"
+	if filter.GetType() == paginationV1.ExprType_OR {
+		op = "OR"
+	}
+
+	n := 0
+	sb.WriteString(op)
+	sb.WriteByte('(')
+	for i, c := range filter.GetConditions() {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(c.GetField())
+		sb.WriteByte(' ')
+		sb.WriteString(c.GetOp().String())
+		n++
+	}
+	for i, g := range filter.GetGroups() {
+		if i > 0 || len(filter.GetConditions()) > 0 {
+			sb.WriteByte(',')
+		}
+		n += writeFilterShape(sb, g)
+	}
+	sb.WriteByte(')')
+	return n
+}
+
+// filterAttributes 返回过滤条件的 Span 属性，无条件时返回 nil
+func filterAttributes(filter *paginationV1.FilterExpr) []attribute.KeyValue {
+	shape, n := FilterShape(filter)
+	if n == 0 {
+		return nil
+	}
+	return []attribute.KeyValue{FilterShapeKey.String(shape), FilterConditionsKey.Int(n)}
+}
+
+// PaginationMode 返回分页请求的分页方式
+func PaginationMode(req *paginationV1.PaginationRequest) string {
+	switch req.GetPaginationType().(type) {
+	case *paginationV1.PaginationRequest_PageBased:
+		return "page"
+	case *paginationV1.PaginationRequest_OffsetBased:
+		return "offset"
+	case *paginationV1.PaginationRequest_TokenBased:
+		return "token"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,35 @@
+module github.com/tx7do/go-crud/telemetry
+
+go 1.24.11
+
+replace github.com/tx7do/go-crud => ../
+
+replace github.com/tx7do/go-crud/api => ../api
+
+replace github.com/tx7do/go-crud/pagination => ../pagination
+
+replace github.com/tx7do/go-crud/viewer => ../viewer
+
+require (
+	github.com/tx7do/go-crud v0.0.1
+	github.com/tx7do/go-crud/api v0.0.7
+	go.opentelemetry.io/otel v1.39.0
+	go.opentelemetry.io/otel/metric v1.39.0
+	go.opentelemetry.io/otel/sdk v1.39.0
+	go.opentelemetry.io/otel/sdk/metric v1.39.0
+	go.opentelemetry.io/otel/trace v1.39.0
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/gnostic v0.7.1 // indirect
+	github.com/google/gnostic-models v0.7.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/tx7do/go-crud/pagination v0.0.11 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.yaml.in/yaml/v3 v3.0.4 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+)
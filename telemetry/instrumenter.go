@@ -0,0 +1,190 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+
+	crud "github.com/tx7do/go-crud"
+)
+
+// InstrumentationName Tracer 与 Meter 的名称
+const InstrumentationName = "github.com/tx7do/go-crud"
+
+// Option Instrumenter 的选项
+type Option func(i *Instrumenter)
+
+// WithTracerProvider 设置 TracerProvider，默认使用 otel.GetTracerProvider()
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(i *Instrumenter) {
+		if provider != nil {
+			i.tracerProvider = provider
+		}
+	}
+}
+
+// WithMeterProvider 设置 MeterProvider，默认使用 otel.GetMeterProvider()
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(i *Instrumenter) {
+		if provider != nil {
+			i.meterProvider = provider
+		}
+	}
+}
+
+// WithDBSystem 设置数据库类型（驱动或方言名称，见 DBSystem），覆盖各数据库模块的默认值
+func WithDBSystem(name string) Option {
+	return func(i *Instrumenter) {
+		if name != "" {
+			i.system = DBSystem(name)
+		}
+	}
+}
+
+// WithAttributes 设置附加到所有 Span 与指标上的属性
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(i *Instrumenter) {
+		i.attrs = append(i.attrs, attrs...)
+	}
+}
+
+// WithoutMetrics 关闭指标
+func WithoutMetrics() Option {
+	return func(i *Instrumenter) {
+		i.disableMetrics = true
+	}
+}
+
+// Instrumenter 仓库层的 OpenTelemetry 埋点：每个操作生成一个 Span，
+// 并记录 db.client.operation.duration 与 db.client.response.returned_rows 两个直方图
+type Instrumenter struct {
+	system attribute.KeyValue
+	entity string
+	attrs  []attribute.KeyValue
+
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	disableMetrics bool
+
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	rows     metric.Int64Histogram
+}
+
+// NewInstrumenter 创建埋点，system 为驱动或方言名称（见 DBSystem），entity 为表、集合或索引名
+func NewInstrumenter(system, entity string, opts ...Option) *Instrumenter {
+	i := &Instrumenter{
+		system:         DBSystem(system),
+		entity:         entity,
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(i)
+		}
+	}
+
+	i.tracer = i.tracerProvider.Tracer(InstrumentationName)
+	if !i.disableMetrics {
+		meter := i.meterProvider.Meter(InstrumentationName)
+		i.duration, _ = meter.Float64Histogram(
+			semconv.DBClientOperationDurationName,
+			metric.WithUnit(semconv.DBClientOperationDurationUnit),
+			metric.WithDescription(semconv.DBClientOperationDurationDescription),
+		)
+		i.rows, _ = meter.Int64Histogram(
+			semconv.DBClientResponseReturnedRowsName,
+			metric.WithUnit(semconv.DBClientResponseReturnedRowsUnit),
+			metric.WithDescription(semconv.DBClientResponseReturnedRowsDescription),
+		)
+	}
+	return i
+}
+
+// Operation 一次正在执行的仓库操作
+type Operation struct {
+	ctx   context.Context
+	inst  *Instrumenter
+	span  trace.Span
+	start time.Time
+	attrs []attribute.KeyValue
+}
+
+// Start 开始一个操作，op 为操作名（如 get、list、update），返回携带 Span 的 ctx
+func (i *Instrumenter) Start(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, *Operation) {
+	base := make([]attribute.KeyValue, 0, len(i.attrs)+3)
+	base = append(base, i.system, semconv.DBOperationNameKey.String(op))
+	if i.entity != "" {
+		base = append(base, semconv.DBCollectionNameKey.String(i.entity))
+	}
+	base = append(base, i.attrs...)
+
+	name := op
+	if i.entity != "" {
+		name = op + " " + i.entity
+	}
+	ctx, span := i.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(base...),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx, &Operation{ctx: ctx, inst: i, span: span, start: time.Now(), attrs: base}
+}
+
+// SetAttributes 设置 Span 属性
+func (o *Operation) SetAttributes(attrs ...attribute.KeyValue) {
+	o.span.SetAttributes(attrs...)
+}
+
+// End 结束操作：rows >= 0 时记录返回的行数；err 不为空时记录错误（crud.ErrNotFound 不视为错误）
+func (o *Operation) End(rows int64, err error) {
+	attrs := o.attrs
+	if err != nil && !errors.Is(err, crud.ErrNotFound) {
+		errType := semconv.ErrorTypeKey.String(errorType(err))
+		attrs = append(attrs[:len(attrs):len(attrs)], errType)
+		o.span.SetAttributes(errType)
+		o.span.RecordError(err)
+		o.span.SetStatus(codes.Error, err.Error())
+	}
+	if rows >= 0 {
+		o.span.SetAttributes(semconv.DBResponseReturnedRows(int(rows)))
+	}
+	o.span.End()
+
+	if o.inst.disableMetrics {
+		return
+	}
+	set := metric.WithAttributes(attrs...)
+	o.inst.duration.Record(o.ctx, time.Since(o.start).Seconds(), set)
+	if rows >= 0 {
+		o.inst.rows.Record(o.ctx, rows, set)
+	}
+}
+
+// errorType 返回 error.type 属性值：已知的错误使用固定的名称，其它错误为 _OTHER
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, crud.ErrVersionConflict):
+		return "version_conflict"
+	case errors.Is(err, crud.ErrEmptyFilter):
+		return "empty_filter"
+	case errors.Is(err, crud.ErrInvalidFilter):
+		return "invalid_filter"
+	case errors.Is(err, crud.ErrNotSupported):
+		return "not_supported"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "_OTHER"
+	}
+}
@@ -0,0 +1,144 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+var _ crud.Repository[struct{}] = (*Repository[struct{}])(nil)
+var _ crud.SoftDeleteRepository = (*Repository[struct{}])(nil)
+
+// Repository 为 crud.Repository 的每个方法增加 Span 与指标的装饰器：
+//   - Span 名称为 "<操作> <实体>"，包含 db.system.name、db.collection.name、db.operation.name，
+//     以及过滤条件的结构（不含值）、分页方式、返回行数（db.response.returned_rows）或影响行数（crud.rows_affected）；
+//   - 所有操作记录 db.client.operation.duration，读操作（list/get/exists）记录 db.client.response.returned_rows。
+//
+// 可包装 gorm / entgo / clickhouse / mongodb / influxdb / elasticsearch 等模块的 RepositoryAdapter，
+// system 传入驱动或方言名称，由 DBSystem 映射为 db.system.name（entgo.CreateDriver 的驱动级埋点使用同一映射）。
+//
+// 示例：
+//
+//	users := telemetry.NewRepository[userV1.User](gorm.NewRepositoryAdapter(repo, db), db.Dialector.Name(), "users")
+type Repository[DTO any] struct {
+	inner crud.Repository[DTO]
+	inst  *Instrumenter
+}
+
+// NewRepository 创建埋点装饰器，system 为驱动或方言名称（见 DBSystem），entity 为表、集合或索引名
+func NewRepository[DTO any](inner crud.Repository[DTO], system, entity string, opts ...Option) *Repository[DTO] {
+	return &Repository[DTO]{
+		inner: inner,
+		inst:  NewInstrumenter(system, entity, opts...),
+	}
+}
+
+func (r *Repository[DTO]) List(ctx context.Context, req *paginationV1.PaginationRequest) (*crud.PagingResult[DTO], error) {
+	ctx, op := r.inst.Start(ctx, "list", PaginationModeKey.String(PaginationMode(req)))
+	if expr := req.GetFilterExpr(); expr != nil {
+		op.SetAttributes(filterAttributes(expr)...)
+	}
+
+	res, err := r.inner.List(ctx, req)
+	rows := int64(-1)
+	if res != nil {
+		rows = int64(len(res.Items))
+		op.SetAttributes(TotalKey.Int64(int64(res.Total)))
+	}
+	op.End(rows, err)
+	return res, err
+}
+
+func (r *Repository[DTO]) Get(ctx context.Context, filter *paginationV1.FilterExpr, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	ctx, op := r.inst.Start(ctx, "get", filterAttributes(filter)...)
+	dto, err := r.inner.Get(ctx, filter, viewMask)
+	op.End(found(dto), err)
+	return dto, err
+}
+
+func (r *Repository[DTO]) Create(ctx context.Context, dto *DTO, viewMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	ctx, op := r.inst.Start(ctx, "create")
+	res, err := r.inner.Create(ctx, dto, viewMask)
+	op.SetAttributes(RowsAffectedKey.Int64(found(res)))
+	op.End(-1, err)
+	return res, err
+}
+
+func (r *Repository[DTO]) Update(ctx context.Context, filter *paginationV1.FilterExpr, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	ctx, op := r.inst.Start(ctx, "update", filterAttributes(filter)...)
+	res, err := r.inner.Update(ctx, filter, dto, updateMask)
+	op.SetAttributes(RowsAffectedKey.Int64(found(res)))
+	op.End(-1, err)
+	return res, err
+}
+
+func (r *Repository[DTO]) Upsert(ctx context.Context, dto *DTO, updateMask *fieldmaskpb.FieldMask) (*DTO, error) {
+	ctx, op := r.inst.Start(ctx, "upsert")
+	res, err := r.inner.Upsert(ctx, dto, updateMask)
+	op.SetAttributes(RowsAffectedKey.Int64(found(res)))
+	op.End(-1, err)
+	return res, err
+}
+
+func (r *Repository[DTO]) Delete(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	ctx, op := r.inst.Start(ctx, "delete", filterAttributes(filter)...)
+	n, err := r.inner.Delete(ctx, filter)
+	op.SetAttributes(RowsAffectedKey.Int64(n))
+	op.End(-1, err)
+	return n, err
+}
+
+func (r *Repository[DTO]) Count(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	ctx, op := r.inst.Start(ctx, "count", filterAttributes(filter)...)
+	n, err := r.inner.Count(ctx, filter)
+	op.SetAttributes(TotalKey.Int64(n))
+	op.End(-1, err)
+	return n, err
+}
+
+func (r *Repository[DTO]) Exists(ctx context.Context, filter *paginationV1.FilterExpr) (bool, error) {
+	ctx, op := r.inst.Start(ctx, "exists", filterAttributes(filter)...)
+	ok, err := r.inner.Exists(ctx, filter)
+	rows := int64(0)
+	if ok {
+		rows = 1
+	}
+	op.End(rows, err)
+	return ok, err
+}
+
+// Restore 被包装的仓库实现 crud.SoftDeleteRepository 时恢复记录，否则返回 crud.ErrNotSupported
+func (r *Repository[DTO]) Restore(ctx context.Context, filter *paginationV1.FilterExpr) (int64, error) {
+	ctx, op := r.inst.Start(ctx, "restore", filterAttributes(filter)...)
+	n, err := int64(0), crud.ErrNotSupported
+	if sd, ok := r.inner.(crud.SoftDeleteRepository); ok {
+		n, err = sd.Restore(ctx, filter)
+	}
+	op.SetAttributes(RowsAffectedKey.Int64(n))
+	op.End(-1, err)
+	return n, err
+}
+
+// Purge 被包装的仓库实现 crud.SoftDeleteRepository 时清理记录，否则返回 crud.ErrNotSupported
+func (r *Repository[DTO]) Purge(ctx context.Context, filter *paginationV1.FilterExpr, olderThan time.Duration) (int64, error) {
+	ctx, op := r.inst.Start(ctx, "purge", filterAttributes(filter)...)
+	n, err := int64(0), crud.ErrNotSupported
+	if sd, ok := r.inner.(crud.SoftDeleteRepository); ok {
+		n, err = sd.Purge(ctx, filter, olderThan)
+	}
+	op.SetAttributes(RowsAffectedKey.Int64(n))
+	op.End(-1, err)
+	return n, err
+}
+
+// found 返回单条结果的行数，nil 为 0
+func found[DTO any](dto *DTO) int64 {
+	if dto == nil {
+		return 0
+	}
+	return 1
+}
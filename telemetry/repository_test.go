@@ -0,0 +1,140 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	crud "github.com/tx7do/go-crud"
+	paginationV1 "github.com/tx7do/go-crud/api/gen/go/pagination/v1"
+)
+
+type testUser struct {
+	ID uint64
+}
+
+// fakeRepo 返回固定结果的仓库
+type fakeRepo struct {
+	crud.Repository[testUser]
+}
+
+func (fakeRepo) List(context.Context, *paginationV1.PaginationRequest) (*crud.PagingResult[testUser], error) {
+	return &crud.PagingResult[testUser]{Items: []*testUser{{ID: 1}, {ID: 2}}, Total: 10}, nil
+}
+
+func (fakeRepo) Get(context.Context, *paginationV1.FilterExpr, *fieldmaskpb.FieldMask) (*testUser, error) {
+	return nil, crud.ErrNotFound
+}
+
+func (fakeRepo) Delete(context.Context, *paginationV1.FilterExpr) (int64, error) {
+	return 0, crud.ErrEmptyFilter
+}
+
+func TestFilterShape(t *testing.T) {
+	filter := &paginationV1.FilterExpr{
+		Type: paginationV1.ExprType_AND,
+		Conditions: []*paginationV1.FilterCondition{
+			{Field: "id", Op: paginationV1.Operator_EQ, ValueOneof: &paginationV1.FilterCondition_Value{Value: "secret"}},
+		},
+		Groups: []*paginationV1.FilterExpr{{
+			Type: paginationV1.ExprType_OR,
+			Conditions: []*paginationV1.FilterCondition{
+				{Field: "name", Op: paginationV1.Operator_LIKE},
+				{Field: "age", Op: paginationV1.Operator_GT},
+			},
+		}},
+	}
+	shape, n := FilterShape(filter)
+	if shape != "AND(id EQ,OR(name LIKE,age GT))" || n != 3 {
+		t.Fatalf("unexpected shape: %s, %d", shape, n)
+	}
+}
+
+func TestRepository(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	repo := NewRepository[testUser](fakeRepo{}, "postgres", "users",
+		WithTracerProvider(tp), WithMeterProvider(mp))
+	ctx := context.Background()
+
+	req := &paginationV1.PaginationRequest{
+		PaginationType: &paginationV1.PaginationRequest_PageBased{PageBased: &paginationV1.PageBasedPagination{}},
+		FilteringType: &paginationV1.PaginationRequest_FilterExpr{FilterExpr: &paginationV1.FilterExpr{
+			Conditions: []*paginationV1.FilterCondition{{Field: "id", Op: paginationV1.Operator_IN}},
+		}},
+	}
+	if _, err := repo.List(ctx, req); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if _, err := repo.Get(ctx, nil, nil); err != crud.ErrNotFound {
+		t.Fatalf("get: %v", err)
+	}
+	if _, err := repo.Delete(ctx, nil); err != crud.ErrEmptyFilter {
+		t.Fatalf("delete: %v", err)
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(ended))
+	}
+
+	attrs := func(s sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+		m := map[attribute.Key]attribute.Value{}
+		for _, kv := range s.Attributes() {
+			m[kv.Key] = kv.Value
+		}
+		return m
+	}
+
+	list := attrs(ended[0])
+	if ended[0].Name() != "list users" ||
+		list["db.system.name"].AsString() != "postgresql" ||
+		list["db.operation.name"].AsString() != "list" ||
+		list[PaginationModeKey].AsString() != "page" ||
+		list[FilterShapeKey].AsString() != "AND(id IN)" ||
+		list["db.response.returned_rows"].AsInt64() != 2 ||
+		list[TotalKey].AsInt64() != 10 {
+		t.Fatalf("unexpected list span: %s %v", ended[0].Name(), list)
+	}
+
+	// 未找到不视为错误
+	if ended[1].Status().Code == codes.Error {
+		t.Fatalf("not found should not be an error: %v", ended[1].Status())
+	}
+	if del := attrs(ended[2]); ended[2].Status().Code != codes.Error || del["error.type"].AsString() != "empty_filter" {
+		t.Fatalf("unexpected delete span: %v %v", ended[2].Status(), del)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	counts := map[string]uint64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					counts[m.Name] += dp.Count
+				}
+			case metricdata.Histogram[int64]:
+				for _, dp := range data.DataPoints {
+					counts[m.Name] += dp.Count
+				}
+			}
+		}
+	}
+	if counts["db.client.operation.duration"] != 3 || counts["db.client.response.returned_rows"] != 2 {
+		t.Fatalf("unexpected metrics: %v", counts)
+	}
+}
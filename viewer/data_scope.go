@@ -13,6 +13,11 @@ const (
 	// 如果是“仅本部门”，TargetIDs 只存放当前部门 ID。
 	ScopeTypeUnit ScopeType = "UNIT"
 
+	// ScopeTypeUnitTree 组织树维度隔离（本部门及下级）。
+	// 逻辑：TargetIDs 只存放授权的根组织 ID，下级组织通过树路径（如 TreePath 的 path 列，"/1/5/"）匹配，
+	// 无需在每次请求中携带展开后的全部子 ID。
+	ScopeTypeUnitTree ScopeType = "UNIT_TREE"
+
 	// ScopeTypeUser 指定的用户列表 (created_by IN [...user_ids])
 	ScopeTypeUser ScopeType = "USER"
 